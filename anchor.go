@@ -0,0 +1,101 @@
+package graw
+
+// Anchor is a fixed connection point of an edge on the perimeter of
+// its source or target shape.
+type Anchor struct {
+	// X and Y give the position relative to the shape bounds, from
+	// 0 (left, top) to 1 (right, bottom).
+	X float64
+	Y float64
+	// Dx and Dy are absolute offsets added to the position.
+	Dx float64
+	Dy float64
+	// Perimeter projects the point onto the shape perimeter, which
+	// is the draw.io default.
+	Perimeter bool
+	// Spacing is the distance kept between the edge end and the
+	// shape.
+	Spacing float64
+}
+
+// ExitAnchor returns the point where the edge c leaves its source,
+// stored in the exitX/exitY style keys. It reports false when the
+// edge floats freely around the source.
+func (c *Cell) ExitAnchor() (Anchor, bool) {
+	return c.Style.anchor("exit", "sourcePerimeterSpacing")
+}
+
+// SetExitAnchor fixes the point where the edge c leaves its source.
+func (c *Cell) SetExitAnchor(a Anchor) *Cell {
+	c.Style.setAnchor("exit", "sourcePerimeterSpacing", a)
+	return c
+}
+
+// ClearExitAnchor lets the edge c float freely around its source.
+func (c *Cell) ClearExitAnchor() *Cell {
+	c.Style.clearAnchor("exit", "sourcePerimeterSpacing")
+	return c
+}
+
+// EntryAnchor returns the point where the edge c enters its target,
+// stored in the entryX/entryY style keys. It reports false when the
+// edge floats freely around the target.
+func (c *Cell) EntryAnchor() (Anchor, bool) {
+	return c.Style.anchor("entry", "targetPerimeterSpacing")
+}
+
+// SetEntryAnchor fixes the point where the edge c enters its target.
+func (c *Cell) SetEntryAnchor(a Anchor) *Cell {
+	c.Style.setAnchor("entry", "targetPerimeterSpacing", a)
+	return c
+}
+
+// ClearEntryAnchor lets the edge c float freely around its target.
+func (c *Cell) ClearEntryAnchor() *Cell {
+	c.Style.clearAnchor("entry", "targetPerimeterSpacing")
+	return c
+}
+
+// anchor reads the anchor stored under the style keys starting with
+// prefix ("exit" or "entry").
+func (a Style) anchor(prefix, spacingKey string) (Anchor, bool) {
+	x, okX := a.float(prefix + "X")
+	y, okY := a.float(prefix + "Y")
+	if !okX || !okY {
+		return Anchor{}, false
+	}
+	an := Anchor{X: x, Y: y, Perimeter: a.Attributes[prefix+"Perimeter"] != "0"}
+	an.Dx, _ = a.float(prefix + "Dx")
+	an.Dy, _ = a.float(prefix + "Dy")
+	an.Spacing, _ = a.float(spacingKey)
+	return an, true
+}
+
+// setAnchor stores an under the style keys starting with prefix,
+// omitting values which equal the draw.io defaults.
+func (a *Style) setAnchor(prefix, spacingKey string, an Anchor) {
+	a.clearAnchor(prefix, spacingKey)
+	a.setFloat(prefix+"X", an.X)
+	a.setFloat(prefix+"Y", an.Y)
+	if an.Dx != 0 {
+		a.setFloat(prefix+"Dx", an.Dx)
+	}
+	if an.Dy != 0 {
+		a.setFloat(prefix+"Dy", an.Dy)
+	}
+	if !an.Perimeter {
+		a.set(prefix+"Perimeter", "0")
+	}
+	if an.Spacing != 0 {
+		a.setFloat(spacingKey, an.Spacing)
+	}
+}
+
+// clearAnchor removes the anchor stored under the style keys starting
+// with prefix.
+func (a *Style) clearAnchor(prefix, spacingKey string) {
+	for _, k := range []string{"X", "Y", "Dx", "Dy", "Perimeter"} {
+		delete(a.Attributes, prefix+k)
+	}
+	delete(a.Attributes, spacingKey)
+}
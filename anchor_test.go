@@ -0,0 +1,88 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		style   string
+		exit    Anchor
+		exitOK  bool
+		entry   Anchor
+		entryOK bool
+	}{
+		{
+			name:    "both",
+			style:   "edgeStyle=orthogonalEdgeStyle;exitX=1;exitY=0.5;exitDx=0;entryX=0;entryY=0.25;entryPerimeter=0;targetPerimeterSpacing=4;",
+			exit:    Anchor{X: 1, Y: 0.5, Perimeter: true},
+			exitOK:  true,
+			entry:   Anchor{X: 0, Y: 0.25, Spacing: 4},
+			entryOK: true,
+		},
+		{
+			name:   "offsets",
+			style:  "exitX=0.5;exitY=1;exitDx=-5;exitDy=2.5;sourcePerimeterSpacing=3;",
+			exit:   Anchor{X: 0.5, Y: 1, Dx: -5, Dy: 2.5, Perimeter: true, Spacing: 3},
+			exitOK: true,
+		},
+		{
+			name:  "floating",
+			style: "edgeStyle=orthogonalEdgeStyle;",
+		},
+		{
+			name:  "half set",
+			style: "exitX=1;entryY=0;",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Cell{Style: parseStyle(test.style)}
+			if got, ok := c.ExitAnchor(); got != test.exit || ok != test.exitOK {
+				t.Errorf("got exit %+v %v, want %+v %v", got, ok, test.exit, test.exitOK)
+			}
+			if got, ok := c.EntryAnchor(); got != test.entry || ok != test.entryOK {
+				t.Errorf("got entry %+v %v, want %+v %v", got, ok, test.entry, test.entryOK)
+			}
+		})
+	}
+}
+
+func TestSetAnchors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		a    Anchor
+		want map[string]string
+	}{
+		{"defaults omitted", Anchor{X: 0.5, Y: 1, Perimeter: true}, map[string]string{"exitX": "0.5", "exitY": "1"}},
+		{"all set", Anchor{X: 1, Y: 0, Dx: 2, Dy: -3, Spacing: 4}, map[string]string{
+			"exitX": "1", "exitY": "0", "exitDx": "2", "exitDy": "-3", "exitPerimeter": "0", "sourcePerimeterSpacing": "4"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Cell{Style: parseStyle("exitDx=9;exitPerimeter=0;rounded=1;")}
+			c.SetExitAnchor(test.a)
+			want := map[string]string{"rounded": "1"}
+			for k, v := range test.want {
+				want[k] = v
+			}
+			if !reflect.DeepEqual(c.Style.Attributes, want) {
+				t.Errorf("got %v, want %v", c.Style.Attributes, want)
+			}
+			if got, ok := c.ExitAnchor(); !ok || got != test.a {
+				t.Errorf("got %+v %v back, want %+v", got, ok, test.a)
+			}
+			c.ClearExitAnchor()
+			if !reflect.DeepEqual(c.Style.Attributes, map[string]string{"rounded": "1"}) {
+				t.Errorf("got %v after ClearExitAnchor", c.Style.Attributes)
+			}
+		})
+	}
+	c := (&Cell{}).SetEntryAnchor(Anchor{X: 0, Y: 0.5, Spacing: 2})
+	if want := map[string]string{"entryX": "0", "entryY": "0.5", "entryPerimeter": "0", "targetPerimeterSpacing": "2"}; !reflect.DeepEqual(c.Style.Attributes, want) {
+		t.Errorf("got entry style %v, want %v", c.Style.Attributes, want)
+	}
+	if c.ClearEntryAnchor(); len(c.Style.Attributes) != 0 {
+		t.Errorf("got %v after ClearEntryAnchor", c.Style.Attributes)
+	}
+}
@@ -0,0 +1,409 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"time"
+)
+
+// AnimationOptions configures RenderGIF and RenderAPNG.
+type AnimationOptions struct {
+	// Delay is how long each frame is shown, a second if zero.
+	Delay time.Duration
+	// Scale multiplies all coordinates; zero means 1.
+	Scale float64
+	// Padding is the margin around the diagrams, before scaling.
+	Padding int
+	// Background fills the frames, white if empty.
+	Background string
+	// Unpinned lets vertices move between frames as they are placed in
+	// each model. By default a vertex stays where it was in the first
+	// frame showing it, with the same parent, so that the layout only
+	// changes where the diagram does.
+	Unpinned bool
+	// Rasterize turns a frame, rendered by RenderSVG, into an image
+	// width by height pixels large, e.g. with an SVG library. When nil,
+	// a built-in rasterizer draws the shapes and edges of the frames
+	// but neither their labels nor images.
+	Rasterize func(svg []byte, width, height int) (image.Image, error)
+}
+
+// RenderGIF writes an animated GIF of frames, e.g. daily snapshots of
+// a topology, to w, showing how the diagram evolves. All frames share
+// the bounds of the union of the diagrams; the animation loops
+// forever. Colors are reduced to the Plan 9 palette.
+func RenderGIF(w io.Writer, frames []*GraphModel, opts AnimationOptions) error {
+	images, err := renderFrames(frames, opts)
+	if err != nil {
+		return err
+	}
+	anim := &gif.GIF{}
+	delay := int(animationDelay(opts) / (10 * time.Millisecond))
+	for _, img := range images {
+		p := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.Draw(p, p.Rect, img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, p)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// RenderAPNG writes an animated PNG of frames to w like RenderGIF, in
+// full color. Viewers without APNG support show the first frame.
+func RenderAPNG(w io.Writer, frames []*GraphModel, opts AnimationOptions) error {
+	images, err := renderFrames(frames, opts)
+	if err != nil {
+		return err
+	}
+	delay := animationDelay(opts).Milliseconds()
+
+	var out bytes.Buffer
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	seq := uint32(0)
+	for i, img := range images {
+		var b bytes.Buffer
+		if err := png.Encode(&b, img); err != nil {
+			return err
+		}
+		chunks, err := pngChunks(b.Bytes())
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			writePNGChunk(&out, "IHDR", chunks["IHDR"][0])
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl, uint32(len(images)))
+			writePNGChunk(&out, "acTL", actl)
+		}
+		size := img.Bounds().Size()
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+		binary.BigEndian.PutUint16(fctl[20:], uint16(delay))
+		binary.BigEndian.PutUint16(fctl[22:], 1000)
+		writePNGChunk(&out, "fcTL", fctl)
+		seq++
+		for _, data := range chunks["IDAT"] {
+			if i == 0 {
+				writePNGChunk(&out, "IDAT", data)
+				continue
+			}
+			fdat := make([]byte, 4, 4+len(data))
+			binary.BigEndian.PutUint32(fdat, seq)
+			writePNGChunk(&out, "fdAT", append(fdat, data...))
+			seq++
+		}
+	}
+	writePNGChunk(&out, "IEND", nil)
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// animationDelay returns the delay between the frames of opts, capped
+// to what GIF and APNG can hold.
+func animationDelay(opts AnimationOptions) time.Duration {
+	d := opts.Delay
+	if d <= 0 {
+		d = time.Second
+	}
+	if d > time.Minute {
+		d = time.Minute
+	}
+	return d
+}
+
+// pngChunks returns the data of the chunks of a PNG file by type.
+func pngChunks(data []byte) (map[string][][]byte, error) {
+	chunks := make(map[string][][]byte)
+	for p := 8; p+12 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[p:]))
+		if p+12+n > len(data) {
+			return nil, errors.New("graw: RenderAPNG: truncated PNG chunk")
+		}
+		kind := string(data[p+4 : p+8])
+		chunks[kind] = append(chunks[kind], data[p+8:p+8+n])
+		p += 12 + n
+	}
+	return chunks, nil
+}
+
+// writePNGChunk writes a PNG chunk of type kind holding data to b.
+func writePNGChunk(b *bytes.Buffer, kind string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	b.Write(n[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(kind))
+	crc.Write(data)
+	b.WriteString(kind)
+	b.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	b.Write(n[:])
+}
+
+// renderFrames returns the frames as opaque images of the same size,
+// the vertices pinned unless opts.Unpinned.
+func renderFrames(frames []*GraphModel, opts AnimationOptions) ([]*image.RGBA, error) {
+	if len(frames) == 0 {
+		return nil, errors.New("graw: no frames to animate")
+	}
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	background := opts.Background
+	if background == "" {
+		background = "#ffffff"
+	}
+
+	models := make([]*GraphModel, len(frames))
+	pinned := make(map[string]*Cell)
+	var canvas Rect
+	for i, f := range frames {
+		g := f.clone()
+		if g.Weights != nil {
+			g.Weights.apply(&g)
+			g.Weights = nil
+		}
+		for _, c := range g.Root {
+			if !c.isVertex() || c.Geometry == nil || c.Geometry.Relative == "1" {
+				continue
+			}
+			if p, ok := pinned[c.ID]; ok && !opts.Unpinned && p.ParentID == c.ParentID {
+				c.Geometry.X, c.Geometry.Y = p.Geometry.X, p.Geometry.Y
+			} else if !ok {
+				pinned[c.ID] = c
+			}
+		}
+		models[i] = &g
+		r := &svgRenderer{g: &g, opts: SVGOptions{Padding: opts.Padding}}
+		canvas = canvas.Union(r.bounds())
+	}
+	if canvas.Empty() {
+		canvas = Rect{0, 0, 1, 1}
+	}
+	width := int(math.Ceil(float64(canvas.Width) * scale))
+	height := int(math.Ceil(float64(canvas.Height) * scale))
+
+	images := make([]*image.RGBA, len(models))
+	for i, g := range models {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(img, img.Rect, image.NewUniform(rasterColor(background)), image.Point{}, draw.Src)
+		if opts.Rasterize != nil {
+			var b bytes.Buffer
+			if err := RenderSVG(&b, g, SVGOptions{Scale: scale, Region: canvas}); err != nil {
+				return nil, err
+			}
+			frame, err := opts.Rasterize(b.Bytes(), width, height)
+			if err != nil {
+				return nil, err
+			}
+			draw.Draw(img, img.Rect, frame, frame.Bounds().Min, draw.Over)
+		} else {
+			r := &rasterizer{svgRenderer: svgRenderer{g: g, opts: SVGOptions{Region: canvas}}, img: img, scale: scale}
+			r.draw()
+		}
+		images[i] = img
+	}
+	return images, nil
+}
+
+// rasterColor returns the opaque color of the #rrggbb color c.
+func rasterColor(c string) color.RGBA {
+	rgb := parseColor(c)
+	return color.RGBA{uint8(rgb[0]), uint8(rgb[1]), uint8(rgb[2]), 0xff}
+}
+
+// rasterizer draws the shapes and edges of a model into an image, the
+// built-in alternative to rasterizing the output of RenderSVG.
+type rasterizer struct {
+	svgRenderer
+	img   *image.RGBA
+	scale float64
+}
+
+// draw draws the included vertices and edges in document order.
+func (r *rasterizer) draw() {
+	for _, c := range r.g.Root {
+		if !r.included(c) {
+			continue
+		}
+		switch {
+		case c.isVertex() && c.Geometry != nil && !r.isEdgeLabel(c):
+			r.vertex(c)
+		case c.isEdge():
+			r.edge(c)
+		}
+	}
+}
+
+// pixel returns the position of the point p of the model in the image.
+func (r *rasterizer) pixel(p fpoint) fpoint {
+	region := r.opts.Region
+	return fpoint{(p.x - float64(region.X)) * r.scale, (p.y - float64(region.Y)) * r.scale}
+}
+
+// strokeWidth returns the stroke width of style in pixels.
+func (r *rasterizer) strokeWidth(style Style) float64 {
+	width := 1.0
+	if v, ok := style.float("strokeWidth"); ok {
+		width = v
+	}
+	return math.Max(width*r.scale, 1)
+}
+
+// rasterPaint returns the color of the style key of style, def if unset, and
+// whether it is painted at all.
+func rasterPaint(style Style, key, def string) (color.RGBA, bool) {
+	v, ok := style.Attributes[key]
+	if !ok {
+		v = def
+	}
+	if svgColor(v) == "none" {
+		return color.RGBA{}, false
+	}
+	return rasterColor(v), true
+}
+
+// vertex fills the shape of c and strokes its outline.
+func (r *rasterizer) vertex(c *Cell) {
+	if isShape(c.Style, "group") {
+		return
+	}
+	b := r.g.BoundsOf(c)
+	p := r.pixel(fpoint{float64(b.X), float64(b.Y)})
+	w, h := float64(b.Width)*r.scale, float64(b.Height)*r.scale
+	cx, cy, hw, hh := p.x+w/2, p.y+h/2, w/2, h/2
+	if hw <= 0 || hh <= 0 {
+		return
+	}
+
+	// inside reports whether (x, y) is in the shape shrunk by d.
+	var inside func(x, y, d float64) bool
+	switch {
+	case isShape(c.Style, "ellipse"):
+		inside = func(x, y, d float64) bool {
+			rx, ry := hw-d, hh-d
+			return rx > 0 && ry > 0 && math.Pow((x-cx)/rx, 2)+math.Pow((y-cy)/ry, 2) <= 1
+		}
+	case isShape(c.Style, "rhombus"):
+		inside = func(x, y, d float64) bool {
+			// d measured across the slanted sides.
+			k := d * math.Hypot(hw, hh) / (hw * hh)
+			return math.Abs(x-cx)/hw+math.Abs(y-cy)/hh <= 1-k
+		}
+	default:
+		inside = func(x, y, d float64) bool {
+			return math.Abs(x-cx) <= hw-d && math.Abs(y-cy) <= hh-d
+		}
+	}
+
+	fill, filled := rasterPaint(c.Style, "fillColor", "#ffffff")
+	if c.Style.Attributes["shape"] == "image" {
+		filled = false
+	}
+	stroke, stroked := rasterPaint(c.Style, "strokeColor", "#000000")
+	sw := r.strokeWidth(c.Style)
+	bounds := image.Rect(int(p.x), int(p.y), int(math.Ceil(p.x+w))+1, int(math.Ceil(p.y+h))+1).Intersect(r.img.Rect)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			if !inside(px, py, 0) {
+				continue
+			}
+			switch {
+			case stroked && !inside(px, py, sw):
+				r.img.SetRGBA(x, y, stroke)
+			case filled:
+				r.img.SetRGBA(x, y, fill)
+			}
+		}
+	}
+}
+
+// edge strokes the route of the edge c with an arrowhead at its end
+// unless endArrow is none.
+func (r *rasterizer) edge(c *Cell) {
+	pts := r.edgePoints(c)
+	if len(pts) < 2 {
+		return
+	}
+	stroke, ok := rasterPaint(c.Style, "strokeColor", "#000000")
+	if !ok {
+		return
+	}
+	for i := range pts {
+		pts[i] = r.pixel(pts[i])
+	}
+	sw := r.strokeWidth(c.Style)
+
+	end := len(pts) - 1
+	if kind, ok := c.Style.Attributes["endArrow"]; !ok || kind != "none" && kind != "" {
+		size := 6.0
+		if v, ok := c.Style.float("endSize"); ok {
+			size = v
+		}
+		size = (size + 4) * r.scale
+		tip, from := pts[end], pts[end-1]
+		base := towards(tip, from, size)
+		l := math.Hypot(tip.x-from.x, tip.y-from.y)
+		if l > 0 {
+			nx, ny := -(tip.y-from.y)/l*size*0.4, (tip.x-from.x)/l*size*0.4
+			r.triangle(tip, fpoint{base.x + nx, base.y + ny}, fpoint{base.x - nx, base.y - ny}, stroke)
+			pts[end] = base
+		}
+	}
+	for i := 1; i < len(pts); i++ {
+		r.segment(pts[i-1], pts[i], sw, stroke)
+	}
+}
+
+// segment strokes the line from a to b, width pixels wide.
+func (r *rasterizer) segment(a, b fpoint, width float64, col color.RGBA) {
+	h := width / 2
+	bounds := image.Rect(int(math.Min(a.x, b.x)-h), int(math.Min(a.y, b.y)-h),
+		int(math.Max(a.x, b.x)+h)+1, int(math.Max(a.y, b.y)+h)+1).Intersect(r.img.Rect)
+	dx, dy := b.x-a.x, b.y-a.y
+	l2 := dx*dx + dy*dy
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			t := 0.0
+			if l2 > 0 {
+				t = math.Max(0, math.Min(1, ((px-a.x)*dx+(py-a.y)*dy)/l2))
+			}
+			if math.Hypot(px-a.x-t*dx, py-a.y-t*dy) <= math.Max(h, 0.5) {
+				r.img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
+
+// triangle fills the triangle a, b, c.
+func (r *rasterizer) triangle(a, b, c fpoint, col color.RGBA) {
+	bounds := image.Rect(int(math.Min(a.x, math.Min(b.x, c.x))), int(math.Min(a.y, math.Min(b.y, c.y))),
+		int(math.Max(a.x, math.Max(b.x, c.x)))+1, int(math.Max(a.y, math.Max(b.y, c.y)))+1).Intersect(r.img.Rect)
+	side := func(p, q, s fpoint) float64 {
+		return (q.x-p.x)*(s.y-p.y) - (q.y-p.y)*(s.x-p.x)
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := fpoint{float64(x) + 0.5, float64(y) + 0.5}
+			d1, d2, d3 := side(a, b, p), side(b, c, p), side(c, a, p)
+			if !((d1 < 0 || d2 < 0 || d3 < 0) && (d1 > 0 || d2 > 0 || d3 > 0)) {
+				r.img.SetRGBA(x, y, col)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+)
+
+var redFill = map[string]string{"fillColor": "#ff0000"}
+
+// animationFrames returns a frame holding the shape "a" and a frame
+// where "a" moved to (x, 0) and the shape "b" at (200, 0) was added.
+func animationFrames(x int) []*GraphModel {
+	first := renderGraph(redFill, nil, 0, 0, nil)
+	second := renderGraph(redFill, redFill, 200, 0, nil)
+	second.FindByID("a").Geometry.X = x
+	return []*GraphModel{first, second}
+}
+
+func TestAnimationDelay(t *testing.T) {
+	tests := []struct {
+		delay, want time.Duration
+	}{
+		{0, time.Second},
+		{-time.Second, time.Second},
+		{500 * time.Millisecond, 500 * time.Millisecond},
+		{2 * time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := animationDelay(AnimationOptions{Delay: tt.delay}); got != tt.want {
+			t.Errorf("delay %v: got %v, want %v", tt.delay, got, tt.want)
+		}
+	}
+}
+
+func TestRenderGIF(t *testing.T) {
+	white, r := color.RGBA{0xff, 0xff, 0xff, 0xff}, color.RGBA{0xff, 0, 0, 0xff}
+	tests := []struct {
+		name   string
+		frames []*GraphModel
+		opts   AnimationOptions
+		size   image.Point
+		// pixels are the colors expected at points of each frame.
+		pixels []map[image.Point]color.RGBA
+	}{
+		{"pinned", animationFrames(100), AnimationOptions{}, image.Pt(300, 50), []map[image.Point]color.RGBA{
+			{image.Pt(50, 25): r, image.Pt(250, 25): white},
+			{image.Pt(50, 25): r, image.Pt(250, 25): r},
+		}},
+		{"unpinned", animationFrames(100), AnimationOptions{Unpinned: true}, image.Pt(300, 50), []map[image.Point]color.RGBA{
+			{image.Pt(50, 25): r, image.Pt(150, 25): white},
+			{image.Pt(50, 25): white, image.Pt(150, 25): r},
+		}},
+		{"scale and padding", animationFrames(0), AnimationOptions{Scale: 2, Padding: 10}, image.Pt(640, 140), []map[image.Point]color.RGBA{
+			{image.Pt(5, 5): white, image.Pt(120, 70): r},
+			{image.Pt(5, 5): white, image.Pt(520, 70): r},
+		}},
+		{"background", animationFrames(0), AnimationOptions{Background: "#0000ff", Padding: 10}, image.Pt(320, 70), []map[image.Point]color.RGBA{
+			{image.Pt(5, 5): {0, 0, 0xff, 0xff}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := RenderGIF(&b, tt.frames, tt.opts); err != nil {
+				t.Fatal(err)
+			}
+			anim, err := gif.DecodeAll(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(anim.Image) != len(tt.frames) {
+				t.Fatalf("got %d frames, want %d", len(anim.Image), len(tt.frames))
+			}
+			for i, img := range anim.Image {
+				if got := img.Bounds().Size(); got != tt.size {
+					t.Errorf("frame %d: got size %v, want %v", i, got, tt.size)
+				}
+				if anim.Delay[i] != 100 {
+					t.Errorf("frame %d: got delay %d, want 100", i, anim.Delay[i])
+				}
+				if i >= len(tt.pixels) {
+					continue
+				}
+				for p, want := range tt.pixels[i] {
+					if got := color.RGBAModel.Convert(img.At(p.X, p.Y)); got != want {
+						t.Errorf("frame %d: got %v at %v, want %v", i, got, p, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGIFErrors(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderGIF(&b, nil, AnimationOptions{}); err == nil {
+		t.Error("got no error without frames")
+	}
+	fail := errors.New("fail")
+	opts := AnimationOptions{Rasterize: func([]byte, int, int) (image.Image, error) { return nil, fail }}
+	if err := RenderGIF(&b, animationFrames(0), opts); !errors.Is(err, fail) {
+		t.Errorf("got %v, want the error of Rasterize", err)
+	}
+}
+
+func TestRenderGIFRasterize(t *testing.T) {
+	var sizes []image.Point
+	var svgs int
+	opts := AnimationOptions{Scale: 2, Rasterize: func(svg []byte, width, height int) (image.Image, error) {
+		if bytes.HasPrefix(svg, []byte("<svg")) {
+			svgs++
+		}
+		sizes = append(sizes, image.Pt(width, height))
+		return image.NewUniform(color.RGBA{0, 0xff, 0, 0xff}), nil
+	}}
+	var b bytes.Buffer
+	if err := RenderGIF(&b, animationFrames(0), opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := []image.Point{{600, 100}, {600, 100}}; len(sizes) != 2 || sizes[0] != want[0] || sizes[1] != want[1] || svgs != 2 {
+		t.Errorf("got %d SVG frames of sizes %v, want 2 of %v", svgs, sizes, want)
+	}
+	anim, err := gif.DecodeAll(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.RGBAModel.Convert(anim.Image[0].At(10, 10)); got != (color.RGBA{0, 0xff, 0, 0xff}) {
+		t.Errorf("got %v, want the rasterized frame", got)
+	}
+}
+
+func TestRenderAPNG(t *testing.T) {
+	var b bytes.Buffer
+	if err := RenderAPNG(&b, animationFrames(0), AnimationOptions{Delay: 250 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := pngChunks(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actl := chunks["acTL"]; len(actl) != 1 || binary.BigEndian.Uint32(actl[0]) != 2 {
+		t.Errorf("got acTL %v, want 2 frames", actl)
+	}
+	fctl := chunks["fcTL"]
+	if len(fctl) != 2 {
+		t.Fatalf("got %d fcTL chunks, want 2", len(fctl))
+	}
+	for i, c := range fctl {
+		if w, h := binary.BigEndian.Uint32(c[4:]), binary.BigEndian.Uint32(c[8:]); w != 300 || h != 50 {
+			t.Errorf("frame %d: got size %dx%d, want 300x50", i, w, h)
+		}
+		if d := binary.BigEndian.Uint16(c[20:]); d != 250 {
+			t.Errorf("frame %d: got delay %d ms, want 250", i, d)
+		}
+	}
+	if len(chunks["IDAT"]) == 0 || len(chunks["fdAT"]) == 0 {
+		t.Errorf("got %d IDAT and %d fdAT chunks, want both", len(chunks["IDAT"]), len(chunks["fdAT"]))
+	}
+	// Viewers without APNG support show the first frame.
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.RGBAModel.Convert(img.At(50, 25)); got != (color.RGBA{0xff, 0, 0, 0xff}) {
+		t.Errorf("got %v in the first frame, want the shape", got)
+	}
+}
@@ -0,0 +1,243 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// asyncOp is an operation of an application on a channel.
+type asyncOp struct {
+	app, channel, name string
+	// send is set when the application produces the messages and
+	// unset when it consumes them.
+	send     bool
+	messages []asyncMessage
+}
+
+// asyncMessage is a message with the properties of its payload.
+type asyncMessage struct {
+	name   string
+	fields []string
+}
+
+// FromAsyncAPI returns an event flow diagram of the given AsyncAPI
+// documents, in YAML or JSON, of version 2 or 3. Every document describes an
+// application, drawn as a rounded box, which sends messages to or
+// receives messages from channels, drawn as topics for Kafka servers
+// and queues otherwise. The messages of a channel are drawn as
+// documents listing the payload properties. Channels with the same
+// name are shared between documents, so passing the documents of
+// several services shows which services talk to each other.
+func FromAsyncAPI(docs ...io.Reader) (*GraphModel, error) {
+	var ops []asyncOp
+	kafka := make(map[string]bool)
+	for i, r := range docs {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("graw: FromAsyncAPI: document %d: %v", i, err)
+		}
+		values, err := decodeYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("graw: FromAsyncAPI: document %d: %v", i, err)
+		}
+		var root map[string]interface{}
+		if len(values) > 0 {
+			root = jsonObject(values[0])
+		}
+		version, _ := root["asyncapi"].(string)
+		if version == "" {
+			return nil, fmt.Errorf("graw: FromAsyncAPI: document %d: not an AsyncAPI document", i)
+		}
+
+		app := jsonString(jsonObject(root["info"]), "title")
+		if app == "" {
+			app = fmt.Sprintf("application %d", i+1)
+		}
+		isKafka := false
+		for _, server := range jsonObject(root["servers"]) {
+			protocol := jsonString(jsonObject(server), "protocol")
+			isKafka = isKafka || strings.HasPrefix(protocol, "kafka")
+		}
+
+		var docOps []asyncOp
+		if strings.HasPrefix(version, "2.") {
+			docOps = asyncOpsV2(root, app)
+		} else {
+			docOps = asyncOpsV3(root, app)
+		}
+		for _, op := range docOps {
+			kafka[op.channel] = kafka[op.channel] || isKafka
+		}
+		ops = append(ops, docOps...)
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	nodes := make(map[string]*Cell)
+	node := func(kind, name, shape string, column *[]*Cell) *Cell {
+		if c, ok := nodes[kind+"\x00"+name]; ok {
+			return c
+		}
+		c := NewShape(taken.unique(kind+"-"+name), rootCellID)
+		c.Value = name
+		c.Style = Style{Attributes: shapeStyle(shape)}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		nodes[kind+"\x00"+name] = c
+		*column = append(*column, c)
+		return c
+	}
+
+	var producers, channels, messages, consumers []*Cell
+	sends := make(map[string]bool)
+	for _, op := range ops {
+		sends[op.app] = sends[op.app] || op.send
+	}
+	edges := make(map[string]bool)
+	described := make(map[string]bool)
+	var cells []*Cell
+	for _, op := range ops {
+		column := &consumers
+		if sends[op.app] {
+			column = &producers
+		}
+		app := node("app", op.app, "rounded", column)
+		shape := "queue"
+		if kafka[op.channel] {
+			shape = "topic"
+		}
+		ch := node("channel", op.channel, shape, &channels)
+
+		from, to := app, ch
+		if !op.send {
+			from, to = ch, app
+		}
+		if key := from.ID + "\x00" + to.ID; !edges[key] {
+			edges[key] = true
+			e := newEdgeCell(taken.unique(from.ID+"-"+to.ID), rootCellID, from.ID, to.ID)
+			e.Value = op.name
+			cells = append(cells, e)
+		}
+
+		for _, m := range op.messages {
+			mc := node("message", m.name, "document", &messages)
+			if len(m.fields) > 0 && !described[mc.ID] {
+				described[mc.ID] = true
+				mc.Value = "<b>" + html.EscapeString(m.name) + "</b><br>" + htmlLines(m.fields)
+				mc.Style.set("align", "left")
+				mc.Style.set("spacingLeft", "4")
+				SizeToFit(mc, nil, defaultWidth, defaultHeight)
+			}
+			if key := ch.ID + "\x00" + mc.ID; !edges[key] {
+				edges[key] = true
+				e := newEdgeCell(taken.unique(ch.ID+"-"+mc.ID), rootCellID, ch.ID, mc.ID)
+				e.Style.set("dashed", "1")
+				e.Style.set("endArrow", "none")
+				cells = append(cells, e)
+			}
+		}
+	}
+
+	layoutColumns([][]*Cell{producers, channels, consumers, messages}, 10, 10, 80)
+	for _, column := range [][]*Cell{producers, channels, consumers, messages, cells} {
+		for _, c := range column {
+			g.Add(c)
+		}
+	}
+	return &g, nil
+}
+
+// asyncOpsV2 returns the operations of an AsyncAPI 2 document. In
+// version 2 a subscribe operation means the application sends the
+// messages and a publish operation that it receives them.
+func asyncOpsV2(root map[string]interface{}, app string) []asyncOp {
+	var ops []asyncOp
+	channels := jsonObject(root["channels"])
+	for _, name := range jsonKeys(channels) {
+		ch := jsonObject(jsonResolve(root, channels[name]))
+		for _, kind := range []string{"subscribe", "publish"} {
+			o := jsonObject(jsonResolve(root, ch[kind]))
+			if o == nil {
+				continue
+			}
+			op := asyncOp{app: app, channel: name, name: jsonString(o, "operationId"), send: kind == "subscribe"}
+			m := jsonObject(jsonResolve(root, o["message"]))
+			if alts, ok := m["oneOf"].([]interface{}); ok {
+				for _, alt := range alts {
+					op.messages = append(op.messages, asyncMessageOf(root, alt, name))
+				}
+			} else if m != nil {
+				op.messages = append(op.messages, asyncMessageOf(root, o["message"], name))
+			}
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// asyncOpsV3 returns the operations of an AsyncAPI 3 document.
+func asyncOpsV3(root map[string]interface{}, app string) []asyncOp {
+	var ops []asyncOp
+	channels := jsonObject(root["channels"])
+	operations := jsonObject(root["operations"])
+	for _, id := range jsonKeys(operations) {
+		o := jsonObject(jsonResolve(root, operations[id]))
+		ref := jsonString(jsonObject(o["channel"]), "$ref")
+		key := ref[strings.LastIndex(ref, "/")+1:]
+		ch := jsonObject(jsonResolve(root, channels[key]))
+		name := jsonString(ch, "address")
+		if name == "" {
+			name = key
+		}
+
+		op := asyncOp{app: app, channel: name, name: id, send: jsonString(o, "action") == "send"}
+		refs, _ := o["messages"].([]interface{})
+		if len(refs) == 0 {
+			msgs := jsonObject(ch["messages"])
+			for _, k := range jsonKeys(msgs) {
+				refs = append(refs, msgs[k])
+			}
+		}
+		for _, m := range refs {
+			op.messages = append(op.messages, asyncMessageOf(root, m, name))
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// asyncMessageOf returns the message v, which may be a reference.
+// Messages without a name are named after the reference or, failing
+// that, the channel.
+func asyncMessageOf(root map[string]interface{}, v interface{}, channel string) asyncMessage {
+	m := jsonObject(jsonResolve(root, v))
+	var msg asyncMessage
+	for _, k := range []string{"name", "title", "messageId"} {
+		if msg.name = jsonString(m, k); msg.name != "" {
+			break
+		}
+	}
+	if ref := jsonString(jsonObject(v), "$ref"); msg.name == "" && ref != "" {
+		msg.name = ref[strings.LastIndex(ref, "/")+1:]
+	}
+	if msg.name == "" {
+		msg.name = channel + " message"
+	}
+
+	payload := jsonObject(jsonResolve(root, m["payload"]))
+	if schema := jsonObject(payload["schema"]); schema != nil {
+		// a version 3 multi format schema
+		payload = jsonObject(jsonResolve(root, schema))
+	}
+	props := jsonObject(payload["properties"])
+	for _, k := range jsonKeys(props) {
+		p := jsonObject(jsonResolve(root, props[k]))
+		field := k
+		if t := jsonString(p, "type"); t != "" {
+			field += ": " + t
+		}
+		msg.fields = append(msg.fields, field)
+	}
+	return msg
+}
@@ -0,0 +1,84 @@
+package graw
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const asyncAPIJSON = `{"asyncapi":"2.6.0","info":{"title":"Account Service"},
+"servers":{"prod":{"url":"k:9092","protocol":"kafka"}},
+"channels":{"user/signedup":{"subscribe":{"operationId":"emitSignup","message":{"$ref":"#/components/messages/UserSignedUp"}}}},
+"components":{"messages":{"UserSignedUp":{"payload":{"type":"object","properties":{"email":{"type":"string"}}}}}}}`
+
+const asyncAPIYAML = `asyncapi: 2.6.0
+info:
+  title: Account Service
+servers:
+  prod:
+    url: k:9092
+    protocol: kafka
+channels:
+  user/signedup:
+    subscribe:
+      operationId: emitSignup
+      message:
+        $ref: '#/components/messages/UserSignedUp'
+components:
+  messages:
+    UserSignedUp:
+      payload:
+        type: object
+        properties:
+          email:
+            type: string
+`
+
+func TestFromAsyncAPI(t *testing.T) {
+	want, err := FromAsyncAPI(strings.NewReader(asyncAPIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want.Root) != 2+3+2 {
+		t.Errorf("got %d cells, want %d", len(want.Root), 2+3+2)
+	}
+	if iss := ValidateStyles(want); len(iss) > 0 {
+		t.Errorf("got style issues %v", iss)
+	}
+	wantXML, _ := xml.Marshal(want)
+	for _, test := range []struct {
+		name, in string
+	}{
+		{"yaml", asyncAPIYAML},
+		{"yaml document", "---\n" + asyncAPIYAML},
+		{"json with spaces", "\n  " + asyncAPIJSON + "\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := FromAsyncAPI(strings.NewReader(test.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := xml.Marshal(g); string(got) != string(wantXML) {
+				t.Errorf("got\n%s\nwant\n%s", got, wantXML)
+			}
+		})
+	}
+}
+
+func TestFromAsyncAPIErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, in, want string
+	}{
+		{"malformed json", `{"asyncapi":`, "graw: FromAsyncAPI: document 0: "},
+		{"malformed yaml", "asyncapi: [2.6.0\n", "graw: FromAsyncAPI: document 0: "},
+		{"empty", "", "graw: FromAsyncAPI: document 0: not an AsyncAPI document"},
+		{"openapi", "openapi: 3.0.0\n", "graw: FromAsyncAPI: document 0: not an AsyncAPI document"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromAsyncAPI(strings.NewReader(test.in))
+			if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package graw
+
+// Spacing used when laying out auto generated containers.
+const (
+	groupHeader  = 30
+	groupPadding = 20
+	groupGap     = 40
+)
+
+// AutoGroup wraps the vertices of each layer which share the same key
+// into a labeled container and lays the layer out again: members are
+// arranged in a grid inside their container, containers and the
+// remaining ungrouped vertices in a grid on the layer.
+//
+// keyFunc is called for every top level vertex; cells for which it
+// returns an empty string stay ungrouped. The key is used as the
+// container label. AutoGroup returns the IDs of the new containers in
+// the order they were created.
+func AutoGroup(g *GraphModel, keyFunc func(c *Cell) string) []string {
+	type group struct {
+		container *Cell
+		members   []*Cell
+	}
+
+	groups := make(map[string]*group)
+	var order []*group
+	var ids []string
+	taken := g.idSet()
+
+	// top level items of each layer, in document order
+	items := make(map[string][]*Cell)
+	var layers []string
+
+	for i := range g.Root {
+		c := g.Root[i]
+		if !c.isVertex() {
+			continue
+		}
+		layer := g.cell(c.ParentID)
+		if layer == nil || !layer.isLayer() {
+			continue
+		}
+		if _, ok := items[c.ParentID]; !ok {
+			layers = append(layers, c.ParentID)
+		}
+
+		key := keyFunc(c)
+		if key == "" {
+			items[c.ParentID] = append(items[c.ParentID], c)
+			continue
+		}
+
+		gk := c.ParentID + "\x00" + key
+		grp, ok := groups[gk]
+		if !ok {
+			id := taken.unique("group-" + key)
+			ids = append(ids, id)
+
+			grp = &group{container: newContainer(id, c.ParentID, key)}
+			groups[gk] = grp
+			order = append(order, grp)
+			items[c.ParentID] = append(items[c.ParentID], grp.container)
+		}
+		grp.members = append(grp.members, c)
+	}
+
+	for _, grp := range order {
+		w, h := layoutGrid(grp.members, groupPadding, groupHeader+groupPadding, groupPadding)
+		grp.container.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+		for _, m := range grp.members {
+			m.ParentID = grp.container.ID
+		}
+	}
+	for _, layer := range layers {
+		layoutGrid(items[layer], 10, 10, groupGap)
+	}
+
+	// Rebuild Root so that every container precedes its members.
+	member := make(map[string]*group)
+	for _, grp := range order {
+		for _, m := range grp.members {
+			member[m.ID] = grp
+		}
+	}
+	emitted := make(map[*group]bool)
+	root := make([]*Cell, 0, len(g.Root)+len(order))
+	for _, c := range g.Root {
+		if grp, ok := member[c.ID]; ok && !emitted[grp] {
+			root = append(root, grp.container)
+			emitted[grp] = true
+		}
+		root = append(root, c)
+	}
+	g.Root = root
+
+	return ids
+}
+
+// newContainer returns a new swimlane Vertex Cell labeled with title,
+// able to hold other cells.
+func newContainer(id, layerId, title string) *Cell {
+	c := NewShape(id, layerId)
+	c.Value = title
+	c.Style = Style{
+		Attributes: map[string]string{
+			"swimlane":   "",
+			"container":  "1",
+			"startSize":  "30",
+			"html":       "1",
+			"whiteSpace": "wrap",
+		},
+	}
+	return c
+}
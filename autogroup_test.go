@@ -0,0 +1,90 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAutoGroup(t *testing.T) {
+	prefix := func(c *Cell) string {
+		if i := strings.IndexByte(c.ID, '.'); i > 0 {
+			return c.ID[:i]
+		}
+		return ""
+	}
+	for _, test := range []struct {
+		name    string
+		ids     []string
+		want    []string
+		parents map[string]string
+	}{
+		{
+			name:    "by prefix",
+			ids:     []string{"a.x", "b.z", "a.y", "c"},
+			want:    []string{"group-a", "group-b"},
+			parents: map[string]string{"a.x": "group-a", "a.y": "group-a", "b.z": "group-b", "c": "1"},
+		},
+		{
+			name:    "none",
+			ids:     []string{"c", "d"},
+			parents: map[string]string{"c": "1", "d": "1"},
+		},
+		{
+			name:    "taken ID",
+			ids:     []string{"group-a", "a.x"},
+			want:    []string{"group-a-2"},
+			parents: map[string]string{"group-a": "1", "a.x": "group-a-2"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			for _, id := range test.ids {
+				g.Add(NewShape(id, "1"))
+			}
+			ids := AutoGroup(&g, prefix)
+			if !reflect.DeepEqual(ids, test.want) {
+				t.Errorf("got containers %v, want %v", ids, test.want)
+			}
+			seen := make(map[string]bool)
+			for _, c := range g.Root {
+				if c.ParentID != "" && !seen[c.ParentID] {
+					t.Errorf("got %s before its parent %s", c.ID, c.ParentID)
+				}
+				seen[c.ID] = true
+			}
+			for id, want := range test.parents {
+				c := g.FindByID(id)
+				if c.ParentID != want {
+					t.Errorf("got %s in %s, want %s", id, c.ParentID, want)
+				}
+				if p := g.FindByID(c.ParentID); p.isVertex() {
+					pw, ph := p.Geometry.size()
+					w, h := c.Geometry.size()
+					if c.Geometry.X < 0 || c.Geometry.Y < groupHeader || c.Geometry.X+w > pw || c.Geometry.Y+h > ph {
+						t.Errorf("got %s at %+v outside of its container %dx%d", id, *c.Geometry, pw, ph)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGeometrySize(t *testing.T) {
+	for _, test := range []struct {
+		width, height string
+		w, h          int
+	}{
+		{"80", "40", 80, 40},
+		{"80.5", "39.4", 81, 39},
+		{"", "", defaultWidth, defaultHeight},
+		{"wide", "0", defaultWidth, defaultHeight},
+		{"-10", "0.2", defaultWidth, defaultHeight},
+		{"NaN", "+Inf", defaultWidth, defaultHeight},
+	} {
+		geo := &Geometry{Width: test.width, Height: test.height}
+		if w, h := geo.size(); w != test.w || h != test.h {
+			t.Errorf("size of %q x %q: got %d x %d, want %d x %d", test.width, test.height, w, h, test.w, test.h)
+		}
+	}
+}
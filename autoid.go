@@ -0,0 +1,37 @@
+package graw
+
+import "strconv"
+
+// NextID returns an ID no cell of g uses: "cell-" followed by a number
+// which increases with every call, so that the IDs of generated
+// diagrams are stable from run to run. IDs are handed out once even
+// when the cells are never added to g. Use it in custom constructors;
+// NewShapeAuto, NewEdgeAuto and NewImageAuto call it for the common
+// cells.
+func (g *GraphModel) NextID() string {
+	for {
+		g.nextID++
+		id := "cell-" + strconv.Itoa(g.nextID)
+		if g.cellIndex(id) < 0 {
+			return id
+		}
+	}
+}
+
+// NewShapeAuto returns a new vertex Cell like NewShape, with an ID
+// from NextID. The cell is not added to g.
+func (g *GraphModel) NewShapeAuto(layerId string) *Cell {
+	return NewShape(g.NextID(), layerId)
+}
+
+// NewEdgeAuto returns a new edge Cell like NewEdge, with an ID from
+// NextID. The cell is not added to g.
+func (g *GraphModel) NewEdgeAuto(layerId, sourceID, targetID string) *Cell {
+	return NewEdge(g.NextID(), layerId, sourceID, targetID)
+}
+
+// NewImageAuto returns a new image Cell like NewImage, with an ID from
+// NextID. The cell is not added to g.
+func (g *GraphModel) NewImageAuto(layerId, url string) *Cell {
+	return NewImage(g.NextID(), layerId, url)
+}
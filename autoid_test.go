@@ -0,0 +1,83 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextID(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		want  []string
+	}{
+		{"empty", nil, []string{"cell-1", "cell-2", "cell-3"}},
+		{"taken", []string{"cell-1", "cell-3"}, []string{"cell-2", "cell-4", "cell-5"}},
+		{"other IDs", []string{"cell", "cell-01", "a"}, []string{"cell-1", "cell-2", "cell-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			for _, id := range tt.taken {
+				g.Add(NewShape(id, "1"))
+			}
+			var got []string
+			for range tt.want {
+				got = append(got, g.NextID())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewAuto(t *testing.T) {
+	g := NewGraph()
+	a := g.NewShapeAuto("1")
+	b := g.NewImageAuto("1", "https://example.com/a.png")
+	e := g.NewEdgeAuto("1", a.ID, b.ID)
+	tests := []struct {
+		cell   *Cell
+		id     string
+		vertex bool
+	}{
+		{a, "cell-1", true},
+		{b, "cell-2", true},
+		{e, "cell-3", false},
+	}
+	for _, tt := range tests {
+		if tt.cell.ID != tt.id || tt.cell.ParentID != "1" || tt.cell.isVertex() != tt.vertex || tt.cell.isEdge() == tt.vertex {
+			t.Errorf("got %+v, want %s", tt.cell, tt.id)
+		}
+	}
+	if b.Style.Attributes["image"] != "https://example.com/a.png" || e.Source != "cell-1" || e.Target != "cell-2" {
+		t.Errorf("got image %q and edge %s->%s", b.Style.Attributes["image"], e.Source, e.Target)
+	}
+	if len(g.Root) != 2 {
+		t.Errorf("got %d cells, want the new cells not added", len(g.Root))
+	}
+	// IDs are handed out once, even when the cells are not added.
+	if id := g.NextID(); id != "cell-4" {
+		t.Errorf("got %q, want cell-4", id)
+	}
+}
+
+func TestNextIDRollback(t *testing.T) {
+	g := NewGraph()
+	g.Add(g.NewShapeAuto("1"))
+	g.Begin()
+	kept := g.NewShapeAuto("1")
+	g.Add(kept)
+	if err := g.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if id := g.NextID(); id == kept.ID {
+		t.Errorf("got %q again after Rollback", id)
+	}
+	// Clones continue from the same number.
+	cp := g.clone()
+	if a, b := g.NextID(), cp.NextID(); a != b {
+		t.Errorf("got %q and %q from a clone", a, b)
+	}
+}
@@ -0,0 +1,75 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+)
+
+// boundaryLayerID is the ID of the layer holding the boundaries.
+const boundaryLayerID = "boundaries"
+
+// AddBoundary draws a labeled dashed boundary, such as a PCI zone, a
+// tier or a public or private network, around the vertices ids of g.
+// The boundary is sized from the bounding box of the vertices with
+// groupPadding pixels of padding and room for label above them, and
+// stroked in color, "#666666" if empty. Boundaries are kept on a layer
+// of their own, "boundaries", drawn below the other layers so that they
+// can be hidden or locked as a whole; the layer is created by the first
+// call. AddBoundary returns the ID of the new boundary.
+func AddBoundary(g *GraphModel, label string, ids []string, color string) (string, error) {
+	if len(ids) == 0 {
+		return "", fmt.Errorf("graw: AddBoundary: no cells")
+	}
+	if color == "" {
+		color = "#666666"
+	}
+	var bounds Rect
+	for _, id := range ids {
+		c := g.cell(id)
+		if c == nil {
+			return "", fmt.Errorf("graw: AddBoundary: no cell %q", id)
+		}
+		if !c.isVertex() || c.Geometry == nil {
+			return "", fmt.Errorf("graw: AddBoundary: cell %q is not a vertex", id)
+		}
+		bounds = bounds.Union(g.BoundsOf(c))
+	}
+
+	if g.cell(boundaryLayerID) == nil {
+		// Insert the layer first so that it is drawn below the others.
+		layer := NewLayer(boundaryLayerID, "Boundaries")
+		root := make([]*Cell, 0, len(g.Root)+1)
+		for i, c := range g.Root {
+			root = append(root, c)
+			if i == 0 {
+				root = append(root, layer)
+			}
+		}
+		if len(root) == len(g.Root) {
+			root = append(root, layer)
+		}
+		g.Root = root
+	}
+
+	b := NewShape(g.uniqueID("boundary-"+label), boundaryLayerID)
+	b.Value = html.EscapeString(label)
+	b.Style = Style{Attributes: shapeStyle("rounded")}
+	b.Style.set("arcSize", "4")
+	b.Style.set("absoluteArcSize", "1")
+	b.Style.set("dashed", "1")
+	b.Style.set("dashPattern", "8 4")
+	b.Style.set("fillColor", "none")
+	b.Style.set("strokeColor", color)
+	b.Style.set("strokeWidth", "2")
+	b.Style.set("fontColor", color)
+	b.Style.set("fontStyle", "1")
+	b.Style.set("verticalAlign", "top")
+	b.Style.set("align", "left")
+	b.Style.set("spacingLeft", "8")
+	bounds = bounds.Inset(-groupPadding)
+	bounds.Y -= groupHeader
+	bounds.Height += groupHeader
+	bounds.Apply(b.Geometry)
+	g.Add(b)
+	return b.ID, nil
+}
@@ -0,0 +1,95 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddBoundary(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"}, [2]string{"b", "c"})
+	g.FindByID("a").Geometry.X, g.FindByID("a").Geometry.Y = 100, 100
+	g.FindByID("b").Geometry.X, g.FindByID("b").Geometry.Y = 300, 150
+	box := newContainer("box", "1", "box")
+	box.Geometry.X, box.Geometry.Y = 500, 400
+	g.Add(box)
+	inner := NewShape("inner", "box")
+	inner.Geometry.X, inner.Geometry.Y = 20, 40
+	g.Add(inner)
+
+	tests := []struct {
+		name   string
+		label  string
+		ids    []string
+		color  string
+		id     string
+		bounds Rect
+		stroke string
+	}{
+		{
+			name: "vertices", label: "PCI", ids: []string{"a", "b"}, id: "boundary-PCI",
+			bounds: Rect{100 - groupPadding, 100 - groupPadding - groupHeader, 320 + 2*groupPadding, 110 + 2*groupPadding + groupHeader},
+			stroke: "#666666",
+		},
+		{
+			name: "nested", label: "PCI", ids: []string{"inner"}, color: "#b85450", id: "boundary-PCI-2",
+			bounds: Rect{520 - groupPadding, 440 - groupPadding - groupHeader, 120 + 2*groupPadding, 60 + 2*groupPadding + groupHeader},
+			stroke: "#b85450",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := AddBoundary(&g, tt.label, tt.ids, tt.color)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.id {
+				t.Errorf("got ID %q, want %q", id, tt.id)
+			}
+			b := g.FindByID(id)
+			if b.ParentID != boundaryLayerID || b.Value != tt.label {
+				t.Errorf("got parent %q, label %q", b.ParentID, b.Value)
+			}
+			if got := GeometryRect(b.Geometry); got != tt.bounds {
+				t.Errorf("got bounds %+v, want %+v", got, tt.bounds)
+			}
+			if got := b.Style.Attributes["strokeColor"]; got != tt.stroke {
+				t.Errorf("got strokeColor %q, want %q", got, tt.stroke)
+			}
+		})
+	}
+
+	// the layer is created once, below the others
+	var layers []string
+	for _, c := range g.Root {
+		if c.isLayer() {
+			layers = append(layers, c.ID)
+		}
+	}
+	if want := []string{boundaryLayerID, "1"}; !reflect.DeepEqual(layers, want) {
+		t.Errorf("got layers %q, want %q", layers, want)
+	}
+	if iss := ValidateStyles(&g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestAddBoundaryErrors(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	tests := []struct {
+		name string
+		ids  []string
+	}{
+		{"no cells", nil},
+		{"unknown", []string{"a", "x"}},
+		{"edge", []string{"a-b"}},
+		{"layer", []string{"1"}},
+	}
+	for _, tt := range tests {
+		if _, err := AddBoundary(&g, "zone", tt.ids, ""); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+	if g.FindByID(boundaryLayerID) != nil {
+		t.Error("got a boundaries layer after errors")
+	}
+}
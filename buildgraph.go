@@ -0,0 +1,392 @@
+package graw
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CycleStyle is the style applied to the targets and dependencies
+// taking part in a dependency cycle.
+var CycleStyle = map[string]string{
+	"strokeColor": "#ff0000",
+	"strokeWidth": "2",
+}
+
+// BuildGraphOptions controls the diagrams of build system target
+// graphs.
+type BuildGraphOptions struct {
+	// CollapsePackages draws a box per package, directory for make
+	// and package for Bazel, instead of a box per target. Edges
+	// between packages are labeled with the number of dependencies
+	// they stand for.
+	CollapsePackages bool
+}
+
+// buildGraph is a graph of build targets.
+type buildGraph struct {
+	targets []string
+	deps    map[string][]string
+	kind    map[string]string
+	pkg     func(target string) string
+}
+
+// add adds target t, unless it is known already.
+func (bg *buildGraph) add(t, kind string) {
+	if _, ok := bg.deps[t]; ok {
+		if kind != "" {
+			bg.kind[t] = kind
+		}
+		return
+	}
+	bg.targets = append(bg.targets, t)
+	bg.deps[t] = nil
+	if kind != "" {
+		bg.kind[t] = kind
+	}
+}
+
+var (
+	makeAssignment = regexp.MustCompile(`^\s*[^\s:=]+\s*(:{1,3}|\+|\?|!)?=`)
+	makeFileStats  = regexp.MustCompile(`^# (files hash-table stats|VPATH Search Paths|Pattern-specific Variable Values|Directories)`)
+)
+
+// FromMakeDatabase returns a dependency diagram of the targets listed
+// in the database printed by make -p (e.g. make -pnq). Every target
+// points to its prerequisites, phony targets are dashed and targets
+// taking part in a cycle get CycleStyle. Special targets such as
+// .PHONY and files which make found not to be targets are skipped.
+func FromMakeDatabase(r io.Reader, opts BuildGraphOptions) (*GraphModel, error) {
+	bg := &buildGraph{
+		deps: make(map[string][]string),
+		kind: make(map[string]string),
+		pkg: func(t string) string {
+			return path.Dir(t)
+		},
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	inFiles, notTarget := false, false
+	var last []string
+	for s.Scan() {
+		line := s.Text()
+		switch {
+		case line == "# Files":
+			inFiles = true
+			continue
+		case makeFileStats.MatchString(line):
+			inFiles = false
+			continue
+		case !inFiles:
+			continue
+		case line == "# Not a target:":
+			notTarget = true
+			continue
+		case strings.HasPrefix(line, "#  Phony target"):
+			for _, t := range last {
+				bg.kind[t] = "phony"
+			}
+			continue
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "\t"):
+			continue
+		}
+
+		colon := strings.Index(line, ":")
+		if colon <= 0 || notTarget {
+			notTarget = false
+			last = nil
+			continue
+		}
+		rest := strings.TrimLeft(line[colon+1:], ":")
+		if makeAssignment.MatchString(rest) {
+			// a target specific variable
+			continue
+		}
+		if i := strings.Index(rest, "|"); i >= 0 {
+			// order-only prerequisites are dependencies too
+			rest = rest[:i] + " " + rest[i+1:]
+		}
+
+		last = nil
+		for _, t := range strings.Fields(line[:colon]) {
+			if strings.HasPrefix(t, ".") && strings.ToUpper(t) == t || strings.Contains(t, "%") {
+				continue
+			}
+			bg.add(t, "")
+			for _, d := range strings.Fields(rest) {
+				bg.add(d, "")
+				bg.deps[t] = append(bg.deps[t], d)
+			}
+			last = append(last, t)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return bg.diagram(opts), nil
+}
+
+// bazelQuery mirrors the output of bazel query --output=xml.
+type bazelQuery struct {
+	Rules []struct {
+		Class  string `xml:"class,attr"`
+		Name   string `xml:"name,attr"`
+		Inputs []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"rule-input"`
+	} `xml:"rule"`
+	Generated []struct {
+		Name string `xml:"name,attr"`
+		Rule string `xml:"generating-rule,attr"`
+	} `xml:"generated-file"`
+}
+
+// FromBazelQuery returns a dependency diagram of the rules printed by
+// bazel query --output=xml, e.g. for the query deps(//...). Every rule
+// points to the rules it depends on, directly or through the files
+// they generate; source files are left out. Rules taking part in a
+// cycle get CycleStyle.
+func FromBazelQuery(r io.Reader, opts BuildGraphOptions) (*GraphModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("<?xml")) {
+		// bazel declares XML 1.1, which encoding/xml refuses
+		if i := bytes.Index(data, []byte("?>")); i >= 0 {
+			data = data[i+2:]
+		}
+	}
+	var q bazelQuery
+	if err := xml.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("graw: FromBazelQuery: %v", err)
+	}
+
+	bg := &buildGraph{
+		deps: make(map[string][]string),
+		kind: make(map[string]string),
+		pkg: func(t string) string {
+			if i := strings.LastIndex(t, ":"); i >= 0 {
+				return t[:i]
+			}
+			return t
+		},
+	}
+	generator := make(map[string]string, len(q.Generated))
+	for _, f := range q.Generated {
+		generator[f.Name] = f.Rule
+	}
+	for _, rule := range q.Rules {
+		bg.add(rule.Name, rule.Class)
+	}
+	for _, rule := range q.Rules {
+		for _, in := range rule.Inputs {
+			dep := in.Name
+			if gen, ok := generator[dep]; ok {
+				dep = gen
+			}
+			if _, ok := bg.deps[dep]; ok && dep != rule.Name {
+				bg.deps[rule.Name] = append(bg.deps[rule.Name], dep)
+			}
+		}
+	}
+	return bg.diagram(opts), nil
+}
+
+// diagram draws bg, arranging the targets in columns so that every
+// target is left of its dependencies, apart from cycles.
+func (bg *buildGraph) diagram(opts BuildGraphOptions) *GraphModel {
+	nodes := bg.targets
+	deps := bg.deps
+	counts := make(map[[2]string]int)
+	if opts.CollapsePackages {
+		nodes, deps = nil, make(map[string][]string)
+		for _, t := range bg.targets {
+			p := bg.pkg(t)
+			if _, ok := deps[p]; !ok {
+				nodes = append(nodes, p)
+				deps[p] = nil
+			}
+		}
+		for _, t := range bg.targets {
+			from := bg.pkg(t)
+			for _, d := range bg.deps[t] {
+				to := bg.pkg(d)
+				if from == to {
+					continue
+				}
+				if counts[[2]string{from, to}] == 0 {
+					deps[from] = append(deps[from], to)
+				}
+				counts[[2]string{from, to}]++
+			}
+		}
+	}
+
+	comp, cyclic, column := dependencyColumns(nodes, deps)
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string, len(nodes))
+	var columns [][]*Cell
+	for _, n := range nodes {
+		c := NewShape(taken.unique(n), rootCellID)
+		c.Value = n
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		if k := bg.kind[n]; k == "phony" {
+			c.Style.set("dashed", "1")
+		} else if k != "" && !opts.CollapsePackages {
+			c.Data = map[string]string{"tooltip": k}
+		}
+		if cyclic[comp[n]] {
+			for k, v := range CycleStyle {
+				c.Style.set(k, v)
+			}
+		}
+		SizeToFit(c, nil, defaultWidth, 40)
+		ids[n] = c.ID
+		for len(columns) <= column[n] {
+			columns = append(columns, nil)
+		}
+		columns[column[n]] = append(columns[column[n]], c)
+	}
+	layoutColumns(columns, 10, 10, 80)
+	for _, col := range columns {
+		for _, c := range col {
+			g.Add(c)
+		}
+	}
+
+	for _, n := range nodes {
+		for _, d := range deps[n] {
+			e := newEdgeCell(taken.unique(ids[n]+"-"+ids[d]), rootCellID, ids[n], ids[d])
+			if k := counts[[2]string{n, d}]; k > 1 {
+				e.Value = strconv.Itoa(k)
+			}
+			if comp[n] == comp[d] && cyclic[comp[n]] {
+				for k, v := range CycleStyle {
+					e.Style.set(k, v)
+				}
+			}
+			g.Add(e)
+		}
+	}
+	return &g
+}
+
+// dependencyColumns assigns the nodes of a dependency graph to
+// columns so that every node is left of its dependencies, apart from
+// those in a cycle with it. It returns the strongly connected
+// component of every node, the components forming a cycle and the
+// column of every node.
+func dependencyColumns(nodes []string, deps map[string][]string) (map[string]int, map[int]bool, map[string]int) {
+	comp := stronglyConnected(nodes, deps)
+	cyclic := make(map[int]bool)
+	size := make(map[int]int)
+	for _, n := range nodes {
+		size[comp[n]]++
+		for _, d := range deps[n] {
+			if d == n {
+				cyclic[comp[n]] = true
+			}
+		}
+	}
+	for c, s := range size {
+		if s > 1 {
+			cyclic[c] = true
+		}
+	}
+
+	// depth of a node: the longest chain of dependencies below it,
+	// counting a cycle as a single step
+	depth := make(map[int]int)
+	var visit func(n string) int
+	visited := make(map[string]bool)
+	visit = func(n string) int {
+		c := comp[n]
+		if visited[n] {
+			return depth[c]
+		}
+		visited[n] = true
+		for _, d := range deps[n] {
+			if comp[d] == c {
+				visit(d)
+				continue
+			}
+			if dd := visit(d) + 1; dd > depth[c] {
+				depth[c] = dd
+			}
+		}
+		return depth[c]
+	}
+	max := 0
+	for _, n := range nodes {
+		if d := visit(n); d > max {
+			max = d
+		}
+	}
+
+	column := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		column[n] = max - depth[comp[n]]
+	}
+	return comp, cyclic, column
+}
+
+// stronglyConnected returns the strongly connected component of every
+// node, numbered from 0, using Tarjan's algorithm.
+func stronglyConnected(nodes []string, adj map[string][]string) map[string]int {
+	index := make(map[string]int, len(nodes))
+	low := make(map[string]int, len(nodes))
+	onStack := make(map[string]bool)
+	comp := make(map[string]int, len(nodes))
+	var stack []string
+	next, count := 0, 0
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range adj[v] {
+			if _, ok := index[w]; !ok {
+				strongConnect(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] && index[w] < low[v] {
+				low[v] = index[w]
+			}
+		}
+		if low[v] == index[v] {
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				comp[w] = count
+				if w == v {
+					break
+				}
+			}
+			count++
+		}
+	}
+
+	sorted := append([]string(nil), nodes...)
+	sort.Strings(sorted)
+	for _, v := range sorted {
+		if _, ok := index[v]; !ok {
+			strongConnect(v)
+		}
+	}
+	return comp
+}
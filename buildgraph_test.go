@@ -0,0 +1,153 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMakeDatabase = `# GNU Make 4.3
+# Variables
+CC = cc
+# Files
+
+# Not a target:
+Makefile:
+#  Implicit rule search has been done.
+
+all: bin/app docs
+#  Phony target (prerequisite of .PHONY).
+
+bin/app: src/main.o src/util.o | bin
+	$(CC) -o $@ $^
+
+src/main.o: src/main.c src/util.h
+src/util.o: src/util.c src/util.h
+src/util.h: src/main.o
+docs: CFLAGS := -O2
+docs:
+#  Phony target (prerequisite of .PHONY).
+.PHONY: all docs
+%.o: %.c
+
+# files hash-table stats:
+foo: bar
+`
+
+const testBazelQuery = `<?xml version="1.1" encoding="UTF-8" standalone="no"?>
+<query version="2">
+<rule class="go_binary" location="a" name="//cmd:app">
+<rule-input name="//lib:lib"/>
+<rule-input name="//lib:gen.go"/>
+<rule-input name="//cmd:main.go"/>
+</rule>
+<source-file name="//cmd:main.go"/>
+<rule class="go_library" location="a" name="//lib:lib"><rule-input name="//lib:a.go"/></rule>
+<rule class="genrule" location="a" name="//lib:gen"><rule-input name="//tools:tool"/></rule>
+<generated-file generating-rule="//lib:gen" name="//lib:gen.go"/>
+<rule class="go_binary" name="//tools:tool"><rule-input name="//lib:lib"/></rule>
+</query>`
+
+func TestBuildGraphs(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		from     func(r *strings.Reader, opts BuildGraphOptions) (*GraphModel, error)
+		in       string
+		collapse bool
+		vertices []string
+		edges    []string
+		cyclic   []string
+	}{
+		{
+			name:     "make",
+			from:     func(r *strings.Reader, o BuildGraphOptions) (*GraphModel, error) { return FromMakeDatabase(r, o) },
+			in:       testMakeDatabase,
+			vertices: []string{"all", "bin/app", "src/util.o", "src/main.o", "src/util.h", "docs", "bin", "src/main.c", "src/util.c"},
+			edges: []string{"all->bin/app", "all->docs", "bin/app->src/main.o", "bin/app->src/util.o", "bin/app->bin",
+				"src/main.o->src/main.c", "src/main.o->src/util.h", "src/util.o->src/util.c", "src/util.o->src/util.h", "src/util.h->src/main.o"},
+			cyclic: []string{"src/main.o", "src/util.h", "src/main.o-src/util.h", "src/util.h-src/main.o"},
+		},
+		{
+			name:     "make packages",
+			from:     func(r *strings.Reader, o BuildGraphOptions) (*GraphModel, error) { return FromMakeDatabase(r, o) },
+			in:       testMakeDatabase,
+			collapse: true,
+			vertices: []string{".", "bin", "src"},
+			edges:    []string{".->bin", "bin->src:2", "bin->."},
+			cyclic:   []string{".", "bin", ".-bin", "bin-."},
+		},
+		{
+			name:     "bazel",
+			from:     func(r *strings.Reader, o BuildGraphOptions) (*GraphModel, error) { return FromBazelQuery(r, o) },
+			in:       testBazelQuery,
+			vertices: []string{"//cmd:app", "//lib:gen", "//tools:tool", "//lib:lib"},
+			edges:    []string{"//cmd:app->//lib:lib", "//cmd:app->//lib:gen", "//lib:gen->//tools:tool", "//tools:tool->//lib:lib"},
+		},
+		{
+			name:     "bazel packages",
+			from:     func(r *strings.Reader, o BuildGraphOptions) (*GraphModel, error) { return FromBazelQuery(r, o) },
+			in:       testBazelQuery,
+			collapse: true,
+			vertices: []string{"//cmd", "//lib", "//tools"},
+			edges:    []string{"//cmd->//lib:2", "//lib->//tools", "//tools->//lib"},
+			cyclic:   []string{"//lib", "//tools", "//lib-//tools", "//tools-//lib"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := test.from(strings.NewReader(test.in), BuildGraphOptions{CollapsePackages: test.collapse})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var vertices, cyclic []string
+			for _, c := range g.Root {
+				if c.isVertex() {
+					vertices = append(vertices, c.ID)
+				}
+				if c.Style.Attributes["strokeColor"] == CycleStyle["strokeColor"] {
+					cyclic = append(cyclic, c.ID)
+				}
+			}
+			if !reflect.DeepEqual(vertices, test.vertices) {
+				t.Errorf("got vertices %v, want %v", vertices, test.vertices)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, test.edges) {
+				t.Errorf("got edges %v, want %v", got, test.edges)
+			}
+			if !reflect.DeepEqual(cyclic, test.cyclic) {
+				t.Errorf("got cycle %v, want %v", cyclic, test.cyclic)
+			}
+			if issues := ValidateStyles(g); len(issues) > 0 {
+				t.Errorf("got style issues %v", issues)
+			}
+		})
+	}
+}
+
+func TestFromMakeDatabasePhony(t *testing.T) {
+	g, err := FromMakeDatabase(strings.NewReader(testMakeDatabase), BuildGraphOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		id    string
+		phony bool
+	}{
+		{"all", true}, {"docs", true}, {"bin/app", false},
+	} {
+		if got := g.FindByID(test.id).Style.Attributes["dashed"] == "1"; got != test.phony {
+			t.Errorf("got %s dashed %v, want %v", test.id, got, test.phony)
+		}
+	}
+}
+
+func TestDependencyColumns(t *testing.T) {
+	nodes := []string{"a", "b", "c", "d"}
+	deps := map[string][]string{"a": {"b", "c"}, "b": {"c"}, "c": {"d"}, "d": {"c"}}
+	comp, cyclic, column := dependencyColumns(nodes, deps)
+	if comp["c"] != comp["d"] || comp["a"] == comp["b"] || !cyclic[comp["c"]] || cyclic[comp["a"]] {
+		t.Errorf("got components %v, cycles %v", comp, cyclic)
+	}
+	if !(column["a"] < column["b"] && column["b"] < column["c"] && column["b"] < column["d"]) {
+		t.Errorf("got columns %v, want every node left of its dependencies", column)
+	}
+}
@@ -0,0 +1,182 @@
+package graw
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Hash returns a hash of the content of g, the SHA-256 of its XML
+// encoding in hex. Styles and custom data are encoded with sorted
+// keys, so equal models have equal hashes however they were built.
+func (g *GraphModel) Hash() string {
+	h := sha256.New()
+	if err := g.Encode(h); err != nil {
+		// Models always encode; hash the error to keep the result
+		// distinct should one not.
+		fmt.Fprint(h, err)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// A RenderStore keeps rendered images for a RenderCache.
+// Implementations must be safe for concurrent use.
+type RenderStore interface {
+	// Get returns the image stored under key and whether there is one.
+	Get(key string) ([]byte, bool, error)
+	// Put stores the image data under key.
+	Put(key string, data []byte) error
+}
+
+// RenderCache renders diagrams once per content and options, serving
+// repeated requests for an unchanged diagram, e.g. by an HTTP preview
+// endpoint, from its Store.
+type RenderCache struct {
+	Store RenderStore
+}
+
+// NewRenderCache returns a RenderCache keeping images in store.
+func NewRenderCache(store RenderStore) *RenderCache {
+	return &RenderCache{Store: store}
+}
+
+// RenderSVG writes the image RenderSVG renders of g with opts to w,
+// from the store when it holds one for the same content and options.
+// Errors reading the store count as misses; an error storing a new
+// image is returned after the image was written to w, so that callers
+// can log it.
+func (rc *RenderCache) RenderSVG(w io.Writer, g *GraphModel, opts SVGOptions) error {
+	key := svgCacheKey(g, opts)
+	if data, ok, err := rc.Store.Get(key); err == nil && ok {
+		_, err := w.Write(data)
+		return err
+	}
+	var buf bytes.Buffer
+	if err := RenderSVG(&buf, g, opts); err != nil {
+		return err
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := rc.Store.Put(key, buf.Bytes()); err != nil {
+		return fmt.Errorf("graw: RenderCache: %v", err)
+	}
+	return nil
+}
+
+// svgCacheKey returns the key of the image of g rendered with opts.
+func svgCacheKey(g *GraphModel, opts SVGOptions) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "svg\n%s\n%g %d %q\n", g.Hash(), opts.Scale, opts.Padding, opts.Background)
+	fmt.Fprintf(h, "%q %q %v\n", opts.Layer, opts.Group, opts.Region)
+	for _, f := range opts.Fonts {
+		fmt.Fprintf(h, "font %q %t %t %q %x\n", f.Family, f.Bold, f.Italic, f.Format, sha256.Sum256(f.Data))
+	}
+	for _, k := range sortedKeys(opts.FontSubstitutes) {
+		fmt.Fprintf(h, "sub %q %q\n", k, opts.FontSubstitutes[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MemoryStore is a RenderStore keeping the most recently used images
+// in memory.
+type MemoryStore struct {
+	max int
+
+	mu      sync.Mutex
+	order   *list.List // of *memoryEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+// memoryEntry is an image kept by a MemoryStore.
+type memoryEntry struct {
+	key  string
+	data []byte
+}
+
+// NewMemoryStore returns a MemoryStore holding up to max images,
+// dropping the least recently used ones; max <= 0 means no limit.
+func NewMemoryStore(max int) *MemoryStore {
+	return &MemoryStore{max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements RenderStore.
+func (s *MemoryStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	s.order.MoveToFront(e)
+	return e.Value.(*memoryEntry).data, true, nil
+}
+
+// Put implements RenderStore.
+func (s *MemoryStore) Put(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[key]; ok {
+		e.Value.(*memoryEntry).data = data
+		s.order.MoveToFront(e)
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, data: data})
+	for s.max > 0 && s.order.Len() > s.max {
+		last := s.order.Back()
+		s.order.Remove(last)
+		delete(s.entries, last.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// DiskStore is a RenderStore keeping images as files named by their
+// key in Dir, so that they survive restarts and can be shared by
+// processes. Old files are never removed.
+type DiskStore struct {
+	Dir string
+}
+
+// Get implements RenderStore.
+func (s DiskStore) Get(key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put implements RenderStore. The file is written under a temporary
+// name and renamed, so that readers never see a partial image.
+func (s DiskStore) Put(key string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), filepath.Join(s.Dir, key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package graw
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	build := func(edit func(g *GraphModel)) string {
+		g := newTestGraph([2]string{"a", "b"})
+		a := g.FindByID("a")
+		a.Style.Attributes = map[string]string{"rounded": "1", "fillColor": "#ffffff"}
+		a.SetData("owner", "ops").SetData("tier", "1")
+		if edit != nil {
+			edit(&g)
+		}
+		return g.Hash()
+	}
+	base := build(nil)
+	if len(base) != 64 || strings.Trim(base, "0123456789abcdef") != "" {
+		t.Fatalf("got hash %q, want 64 hex digits", base)
+	}
+	tests := []struct {
+		name  string
+		edit  func(g *GraphModel)
+		equal bool
+	}{
+		{"rebuilt", nil, true},
+		{"style order", func(g *GraphModel) {
+			g.FindByID("a").Style.Attributes = map[string]string{"fillColor": "#ffffff", "rounded": "1"}
+		}, true},
+		{"data order", func(g *GraphModel) {
+			a := g.FindByID("a")
+			a.Data = nil
+			a.SetData("tier", "1").SetData("owner", "ops")
+		}, true},
+		{"label", func(g *GraphModel) { g.FindByID("a").Value = "A" }, false},
+		{"position", func(g *GraphModel) { g.FindByID("b").Geometry.X++ }, false},
+		{"style", func(g *GraphModel) { g.FindByID("a").Style.Attributes["rounded"] = "0" }, false},
+		{"data", func(g *GraphModel) { g.FindByID("a").SetData("tier", "2") }, false},
+		{"edge", func(g *GraphModel) { g.FindByID("a-b").Target = "a" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := build(tt.edit); (got == base) != tt.equal {
+				t.Errorf("got hash %s for base %s, want equal %v", got, base, tt.equal)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		// ops are "put key" or "get key" in order.
+		ops  []string
+		kept string
+	}{
+		{"unlimited", 0, []string{"put a", "put b", "put c"}, "abc"},
+		{"evicts oldest", 2, []string{"put a", "put b", "put c"}, "bc"},
+		{"get refreshes", 2, []string{"put a", "put b", "get a", "put c"}, "ac"},
+		{"put refreshes", 2, []string{"put a", "put b", "put a", "put c"}, "ac"},
+		{"single", 1, []string{"put a", "put b"}, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore(tt.max)
+			for _, op := range tt.ops {
+				key := op[4:]
+				if strings.HasPrefix(op, "put") {
+					if err := s.Put(key, []byte("img "+key)); err != nil {
+						t.Fatal(err)
+					}
+				} else if _, ok, _ := s.Get(key); !ok {
+					t.Fatalf("%s: got miss", op)
+				}
+			}
+			var kept string
+			for _, key := range []string{"a", "b", "c"} {
+				data, ok, err := s.Get(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ok {
+					kept += key
+					if string(data) != "img "+key {
+						t.Errorf("got %q for %s", data, key)
+					}
+				}
+			}
+			// Reading in order refreshes the keys without evicting any.
+			if kept != tt.kept {
+				t.Errorf("got kept %q, want %q", kept, tt.kept)
+			}
+		})
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	s := DiskStore{Dir: filepath.Join(t.TempDir(), "cache")}
+	if _, ok, err := s.Get("k"); ok || err != nil {
+		t.Fatalf("got hit %v and error %v before Put, want a miss", ok, err)
+	}
+	for _, data := range []string{"first", "second"} {
+		if err := s.Put("k", []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		got, ok, err := s.Get("k")
+		if !ok || err != nil || string(got) != data {
+			t.Errorf("got %q, %v, %v, want %q", got, ok, err, data)
+		}
+	}
+	files, err := os.ReadDir(s.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "k" {
+		t.Errorf("got files %v, want only k", files)
+	}
+
+	// A directory in the way of the key is an error, not a miss.
+	if err := os.Mkdir(filepath.Join(s.Dir, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Get("dir"); err == nil {
+		t.Error("got no error reading a directory")
+	}
+}
+
+// countingStore is a RenderStore counting its calls, failing them with
+// the given errors.
+type countingStore struct {
+	RenderStore
+	gets, puts     int
+	getErr, putErr error
+}
+
+func (s *countingStore) Get(key string) ([]byte, bool, error) {
+	s.gets++
+	if s.getErr != nil {
+		return nil, false, s.getErr
+	}
+	return s.RenderStore.Get(key)
+}
+
+func (s *countingStore) Put(key string, data []byte) error {
+	s.puts++
+	if s.putErr != nil {
+		return s.putErr
+	}
+	return s.RenderStore.Put(key, data)
+}
+
+func TestRenderCache(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	tests := []struct {
+		name    string
+		store   *countingStore
+		renders []SVGOptions
+		puts    int
+		wantErr string
+	}{
+		{"hit", &countingStore{}, []SVGOptions{{}, {}, {}}, 1, ""},
+		{"options", &countingStore{}, []SVGOptions{{}, {Scale: 2}, {Padding: 1}, {Layer: "1"}, {Scale: 2}}, 4, ""},
+		{"fonts", &countingStore{}, []SVGOptions{{Fonts: []FontFace{{Family: "A", Data: []byte("1")}}}, {Fonts: []FontFace{{Family: "A", Data: []byte("2")}}}}, 2, ""},
+		{"substitutes", &countingStore{}, []SVGOptions{{}, {FontSubstitutes: map[string]string{"Helvetica": "Inter"}}}, 2, ""},
+		{"get error", &countingStore{getErr: errors.New("offline")}, []SVGOptions{{}, {}}, 2, ""},
+		{"put error", &countingStore{putErr: errors.New("disk full")}, []SVGOptions{{}}, 1, "graw: RenderCache: disk full"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.store.RenderStore = NewMemoryStore(0)
+			rc := NewRenderCache(tt.store)
+			for _, opts := range tt.renders {
+				var b strings.Builder
+				err := rc.RenderSVG(&b, &g, opts)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("got error %v, want %q", err, tt.wantErr)
+					}
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				var want strings.Builder
+				if err := RenderSVG(&want, &g, opts); err != nil {
+					t.Fatal(err)
+				}
+				if b.String() != want.String() {
+					t.Errorf("got\n%s\nwant\n%s", b.String(), want.String())
+				}
+			}
+			if tt.store.gets != len(tt.renders) || tt.store.puts != tt.puts {
+				t.Errorf("got %d gets and %d puts, want %d and %d", tt.store.gets, tt.store.puts, len(tt.renders), tt.puts)
+			}
+		})
+	}
+}
+
+func TestRenderCacheServesStore(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	store := NewMemoryStore(0)
+	store.Put(svgCacheKey(&g, SVGOptions{}), []byte("<svg/>"))
+	var b strings.Builder
+	if err := NewRenderCache(store).RenderSVG(&b, &g, SVGOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "<svg/>" {
+		t.Errorf("got %q, want the stored image", b.String())
+	}
+
+	// Changing the model changes the key.
+	g.FindByID("a").Value = "A"
+	b.Reset()
+	if err := NewRenderCache(store).RenderSVG(&b, &g, SVGOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), ">A</tspan>") {
+		t.Errorf("got %q, want a new image", b.String())
+	}
+}
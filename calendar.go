@@ -0,0 +1,164 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Dimensions of calendar grids.
+const (
+	calendarDayWidth  = 90
+	calendarDayHeight = 50
+	calendarLabelSize = 60
+	calendarHeader    = 30
+)
+
+// CalendarDay is the content of a day of a calendar grid.
+type CalendarDay struct {
+	// Label is shown below the date, e.g. the person on call or the
+	// release shipping that day.
+	Label string
+	// Value is the number the day is colored by, unless its label
+	// has a color.
+	Value float64
+}
+
+// CalendarOptions configures NewCalendar.
+type CalendarOptions struct {
+	// LowColor and HighColor are the #rrggbb fill colors of the days
+	// with the lowest and highest values, by default light and dark
+	// green; colors in between are interpolated.
+	LowColor, HighColor string
+	// LabelColors maps labels to the fill color of their days, e.g.
+	// a color per on-call engineer. Labels missing from it are colored
+	// by value, unless Categorical is set.
+	LabelColors map[string]string
+	// Categorical colors the days by label instead of by value, each
+	// label missing from LabelColors taking the next color of the
+	// draw.io palette.
+	Categorical bool
+}
+
+// NewCalendar returns a calendar grid of the days from from to to,
+// inclusive: a column per weekday starting on Monday and a row per
+// week, labeled with the ISO week number and the month it starts.
+// days holds the content of the days by date, formatted as
+// "2006-01-02"; days without content are left blank.
+func NewCalendar(from, to time.Time, days map[string]CalendarDay, opts CalendarOptions) (*GraphModel, error) {
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("graw: NewCalendar: %s is before %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+	if opts.LowColor == "" {
+		opts.LowColor = "#e6f5d0"
+	}
+	if opts.HighColor == "" {
+		opts.HighColor = "#1a7f37"
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	colors := make(map[string]string, len(opts.LabelColors))
+	for k, v := range opts.LabelColors {
+		colors[k] = v
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		cd, ok := days[d.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		if _, ok := colors[cd.Label]; ok {
+			continue
+		}
+		if opts.Categorical {
+			if cd.Label != "" {
+				colors[cd.Label] = ganttColors[len(colors)%len(ganttColors)]
+			}
+			continue
+		}
+		low, high = math.Min(low, cd.Value), math.Max(high, cd.Value)
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	x0, y0 := 10+calendarLabelSize, 10+calendarHeader
+	for i, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		c := NewShape(taken.unique("weekday-"+name), rootCellID)
+		c.Value = "<b>" + name + "</b>"
+		c.Style = Style{Attributes: shapeStyle("text")}
+		c.Style.set("align", "center")
+		c.Geometry.X, c.Geometry.Y = x0+i*calendarDayWidth, 10
+		c.Geometry.setSize(calendarDayWidth, calendarHeader)
+		g.Add(c)
+	}
+
+	monday := from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+	for row, week := 0, monday; !week.After(to); row, week = row+1, week.AddDate(0, 0, 7) {
+		y := y0 + row*calendarDayHeight
+		year, wn := week.ISOWeek()
+		label := NewShape(taken.unique(fmt.Sprintf("week-%d-%02d", year, wn)), rootCellID)
+		label.Value = "W" + strconv.Itoa(wn)
+		// months are named in the rows of their first day, and in the
+		// first row
+		for i := 0; i < 7; i++ {
+			d := week.AddDate(0, 0, i)
+			if !d.Before(from) && !d.After(to) && (d.Day() == 1 || d.Equal(from)) {
+				label.Value = "<b>" + d.Format("Jan") + "</b><br>" + label.Value
+				break
+			}
+		}
+		label.Style = Style{Attributes: shapeStyle("text")}
+		label.Style.set("align", "right")
+		label.Style.set("spacingRight", "8")
+		label.Geometry.X, label.Geometry.Y = 10, y
+		label.Geometry.setSize(calendarLabelSize, calendarDayHeight)
+		g.Add(label)
+
+		for i := 0; i < 7; i++ {
+			d := week.AddDate(0, 0, i)
+			if d.Before(from) || d.After(to) {
+				continue
+			}
+			key := d.Format("2006-01-02")
+			c := NewShape(taken.unique("day-"+key), rootCellID)
+			c.Value = strconv.Itoa(d.Day())
+			c.Style = Style{Attributes: shapeStyle("")}
+			c.Style.set("align", "left")
+			c.Style.set("verticalAlign", "top")
+			c.Style.set("spacingLeft", "4")
+			c.Style.set("strokeColor", "#cccccc")
+			if d.Day() == 1 {
+				c.Value = d.Format("Jan") + " 1"
+			}
+			if cd, ok := days[key]; ok {
+				if cd.Label != "" {
+					c.Value += "<br><b>" + html.EscapeString(cd.Label) + "</b>"
+				}
+				c.Data = map[string]string{"tooltip": key}
+				switch color, ok := colors[cd.Label]; {
+				case ok:
+					c.Style.set("fillColor", color)
+				case !opts.Categorical:
+					c.Data["tooltip"] += ": " + strconv.FormatFloat(cd.Value, 'f', -1, 64)
+					t := 1.0
+					if high > low {
+						t = (cd.Value - low) / (high - low)
+					}
+					c.Style.set("fillColor", mixColor(opts.LowColor, opts.HighColor, t))
+					if t > 0.6 {
+						c.Style.set("fontColor", "#ffffff")
+					}
+				}
+			}
+			c.Geometry.X, c.Geometry.Y = x0+i*calendarDayWidth, y
+			c.Geometry.setSize(calendarDayWidth, calendarDayHeight)
+			g.Add(c)
+		}
+	}
+	return &g, nil
+}
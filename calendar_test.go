@@ -0,0 +1,124 @@
+package graw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewCalendar(t *testing.T) {
+	from := time.Date(2024, 1, 25, 15, 0, 0, 0, time.UTC) // a Thursday
+	to := time.Date(2024, 2, 6, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		label, fill, tooltip string
+		column, row          int
+	}
+	tests := []struct {
+		name  string
+		days  map[string]CalendarDay
+		opts  CalendarOptions
+		cells map[string]want
+	}{
+		{
+			name: "values",
+			days: map[string]CalendarDay{
+				"2024-01-26": {Value: 1},
+				"2024-02-01": {Label: "v1.2", Value: 5},
+				"2024-02-02": {Value: 3},
+			},
+			cells: map[string]want{
+				"day-2024-01-25": {"25", "", "", 3, 0},
+				"day-2024-01-26": {"26", "#e6f5d0", "2024-01-26: 1", 4, 0},
+				"day-2024-02-01": {"Feb 1<br><b>v1.2</b>", "#1a7f37", "2024-02-01: 5", 3, 1},
+				"day-2024-02-02": {"2", mixColor("#e6f5d0", "#1a7f37", 0.5), "2024-02-02: 3", 4, 1},
+				"day-2024-02-06": {"6", "", "", 1, 2},
+			},
+		},
+		{
+			name: "label colors",
+			days: map[string]CalendarDay{
+				"2024-01-26": {Label: "alice", Value: 9},
+				"2024-01-27": {Label: "bob", Value: 2},
+				"2024-01-28": {Label: "carol", Value: 4},
+			},
+			opts: CalendarOptions{LowColor: "#ffffff", HighColor: "#000000", LabelColors: map[string]string{"alice": "#ff0000"}},
+			cells: map[string]want{
+				"day-2024-01-26": {"26<br><b>alice</b>", "#ff0000", "2024-01-26", 4, 0},
+				"day-2024-01-27": {"27<br><b>bob</b>", "#ffffff", "2024-01-27: 2", 5, 0},
+				"day-2024-01-28": {"28<br><b>carol</b>", "#000000", "2024-01-28: 4", 6, 0},
+			},
+		},
+		{
+			name: "categorical",
+			days: map[string]CalendarDay{
+				"2024-01-26": {Label: "alice"},
+				"2024-02-01": {Label: "bob"},
+				"2024-02-02": {Label: "alice"},
+				"2024-02-03": {},
+			},
+			opts: CalendarOptions{Categorical: true},
+			cells: map[string]want{
+				"day-2024-01-26": {"26<br><b>alice</b>", ganttColors[0], "2024-01-26", 4, 0},
+				"day-2024-02-01": {"Feb 1<br><b>bob</b>", ganttColors[1], "2024-02-01", 3, 1},
+				"day-2024-02-02": {"2<br><b>alice</b>", ganttColors[0], "2024-02-02", 4, 1},
+				"day-2024-02-03": {"3", "", "2024-02-03", 5, 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewCalendar(from, to, tt.days, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for id, w := range tt.cells {
+				c := g.FindByID(id)
+				if c == nil {
+					t.Errorf("%s: missing", id)
+					continue
+				}
+				if c.Value != w.label {
+					t.Errorf("%s: got label %q, want %q", id, c.Value, w.label)
+				}
+				if got := c.Style.Attributes["fillColor"]; got != w.fill {
+					t.Errorf("%s: got fillColor %q, want %q", id, got, w.fill)
+				}
+				if got := c.Data["tooltip"]; got != w.tooltip {
+					t.Errorf("%s: got tooltip %q, want %q", id, got, w.tooltip)
+				}
+				x := 10 + calendarLabelSize + w.column*calendarDayWidth
+				y := 10 + calendarHeader + w.row*calendarDayHeight
+				if c.Geometry.X != x || c.Geometry.Y != y {
+					t.Errorf("%s: got position %d,%d, want %d,%d", id, c.Geometry.X, c.Geometry.Y, x, y)
+				}
+			}
+			for _, id := range []string{"day-2024-01-24", "day-2024-02-07"} {
+				if g.FindByID(id) != nil {
+					t.Errorf("%s: got a day out of range", id)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewCalendarWeeks(t *testing.T) {
+	g, err := NewCalendar(time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 6, 0, 0, 0, 0, time.UTC), nil, CalendarOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{
+		"week-2024-04": "<b>Jan</b><br>W4",
+		"week-2024-05": "<b>Feb</b><br>W5",
+		"week-2024-06": "W6",
+	} {
+		if c := g.FindByID(id); c == nil || c.Value != want {
+			t.Errorf("%s: got %v, want label %q", id, c, want)
+		}
+	}
+	if _, err := NewCalendar(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil, CalendarOptions{}); err == nil {
+		t.Error("NewCalendar ending before it starts: got no error")
+	}
+}
@@ -0,0 +1,241 @@
+package graw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CallGraphOptions controls the diagrams of call graphs.
+type CallGraphOptions struct {
+	// Prefixes keeps only the functions of packages whose import
+	// path starts with one of the prefixes, e.g. "github.com/me/".
+	// All functions are kept when it is empty.
+	Prefixes []string
+	// CollapsePackages draws a box per package instead of a
+	// container holding a box per function.
+	CollapsePackages bool
+}
+
+// call is an edge of a call graph.
+type call struct {
+	caller, callee string
+	dynamic        bool
+}
+
+// FromCallGraph returns a call diagram of the output of the callgraph
+// tool of golang.org/x/tools, either in its default format or with
+// -format graphviz. Functions are grouped in a collapsible container
+// per package, the packages arranged so that callers are left of the
+// functions they call. Edges stand for the calls between functions,
+// labeled with the number of call sites when there are several, and
+// dynamic calls are dashed.
+//
+// With opts.CollapsePackages the diagram shows the calls between
+// packages only, mutual recursion between packages drawn in
+// CycleStyle.
+func FromCallGraph(r io.Reader, opts CallGraphOptions) (*GraphModel, error) {
+	calls, err := parseCallGraph(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := func(fn string) bool {
+		pkg := funcPackage(fn)
+		if pkg == "" {
+			return false
+		}
+		if len(opts.Prefixes) == 0 {
+			return true
+		}
+		for _, p := range opts.Prefixes {
+			if strings.HasPrefix(pkg, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	bg := &buildGraph{
+		deps: make(map[string][]string),
+		kind: make(map[string]string),
+		pkg:  funcPackage,
+	}
+	sites := make(map[[2]string]int)
+	dynamic := make(map[[2]string]bool)
+	for _, c := range calls {
+		if !keep(c.caller) || !keep(c.callee) {
+			continue
+		}
+		bg.add(c.caller, "")
+		bg.add(c.callee, "")
+		key := [2]string{c.caller, c.callee}
+		if sites[key] == 0 {
+			bg.deps[c.caller] = append(bg.deps[c.caller], c.callee)
+		}
+		sites[key]++
+		dynamic[key] = dynamic[key] || c.dynamic
+	}
+	if opts.CollapsePackages {
+		return bg.diagram(BuildGraphOptions{CollapsePackages: true}), nil
+	}
+
+	var pkgs []string
+	funcs := make(map[string][]string)
+	pkgDeps := make(map[string][]string)
+	for _, fn := range bg.targets {
+		pkg := funcPackage(fn)
+		if _, ok := funcs[pkg]; !ok {
+			pkgs = append(pkgs, pkg)
+		}
+		funcs[pkg] = append(funcs[pkg], fn)
+		for _, callee := range bg.deps[fn] {
+			pkgDeps[pkg] = append(pkgDeps[pkg], funcPackage(callee))
+		}
+	}
+	_, _, column := dependencyColumns(pkgs, pkgDeps)
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string, len(bg.targets))
+	var columns [][]*Cell
+	var children []*Cell
+	for _, pkg := range pkgs {
+		container := newContainer(taken.unique(pkg), rootCellID, pkg)
+		container.Style.set("collapsible", "1")
+		sort.Strings(funcs[pkg])
+		var cells []*Cell
+		for _, fn := range funcs[pkg] {
+			c := NewShape(taken.unique(fn), container.ID)
+			c.Value = strings.Replace(fn, pkg+".", "", 1)
+			c.Style = Style{Attributes: shapeStyle("rounded")}
+			SizeToFit(c, nil, defaultWidth, 40)
+			ids[fn] = c.ID
+			cells = append(cells, c)
+		}
+		w, h := layoutColumns([][]*Cell{cells}, groupPadding, groupHeader+groupPadding, groupGap)
+		container.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+		children = append(children, cells...)
+
+		for len(columns) <= column[pkg] {
+			columns = append(columns, nil)
+		}
+		columns[column[pkg]] = append(columns[column[pkg]], container)
+	}
+	layoutColumns(columns, 10, 10, 80)
+	for _, col := range append(columns, children) {
+		for _, c := range col {
+			g.Add(c)
+		}
+	}
+
+	for _, fn := range bg.targets {
+		for _, callee := range bg.deps[fn] {
+			key := [2]string{fn, callee}
+			e := newEdgeCell(taken.unique(ids[fn]+"-"+ids[callee]), rootCellID, ids[fn], ids[callee])
+			if sites[key] > 1 {
+				e.Value = strconv.Itoa(sites[key])
+			}
+			if dynamic[key] {
+				e.Style.set("dashed", "1")
+			}
+			g.Add(e)
+		}
+	}
+	return &g, nil
+}
+
+// parseCallGraph reads the calls printed by the callgraph tool, in
+// the default format:
+//
+//	main.main	--static-12:6-->	fmt.Println
+//
+// or in the graphviz format:
+//
+//	"main.main" -> "fmt.Println"
+func parseCallGraph(r io.Reader) ([]call, error) {
+	var calls []call
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	n := 0
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+		if fields := strings.Split(line, "\t"); len(fields) == 3 &&
+			strings.HasPrefix(fields[1], "--") && strings.HasSuffix(fields[1], "-->") {
+			calls = append(calls, call{
+				caller:  fields[0],
+				callee:  fields[2],
+				dynamic: strings.HasPrefix(fields[1], "--dynamic-"),
+			})
+			continue
+		}
+		if !strings.HasPrefix(line, `"`) || !strings.Contains(line, "->") {
+			continue
+		}
+		caller, rest, err := dotQuoted(line)
+		if err != nil {
+			return nil, fmt.Errorf("graw: FromCallGraph: line %d: %v", n, err)
+		}
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, "->") {
+			continue
+		}
+		callee, _, err := dotQuoted(strings.TrimSpace(rest[2:]))
+		if err != nil {
+			return nil, fmt.Errorf("graw: FromCallGraph: line %d: %v", n, err)
+		}
+		calls = append(calls, call{caller: caller, callee: callee})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("graw: FromCallGraph: no calls found")
+	}
+	return calls, nil
+}
+
+// dotQuoted returns the unquoted graphviz string s starts with and the
+// rest of s. Only quotes are unescaped: graphviz leaves the other
+// escapes, such as \n in labels, to the attribute using the string.
+func dotQuoted(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", fmt.Errorf("expected a quoted name")
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '"' {
+				i++
+			}
+			b.WriteByte(s[i])
+		case '"':
+			return b.String(), s[i+1:], nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// funcPackage returns the import path of the package of a function
+// named as by go/types, e.g. "net/http" for "(*net/http.Server).Serve",
+// or "" for synthetic functions such as "<root>".
+func funcPackage(fn string) string {
+	fn = strings.TrimLeft(fn, "(*")
+	if i := strings.IndexByte(fn, '['); i >= 0 {
+		// type arguments may hold other import paths
+		fn = fn[:i]
+	}
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 || strings.HasPrefix(fn, "<") {
+		return ""
+	}
+	return fn[:slash+1+dot]
+}
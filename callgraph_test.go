@@ -0,0 +1,173 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const callgraphOut = "main.main\t--static-12:6-->\tnet/http.ListenAndServe\n" +
+	"main.main\t--static-13:6-->\tnet/http.ListenAndServe\n" +
+	"net/http.ListenAndServe\t--static-3254:24-->\t(*net/http.Server).ListenAndServe\n" +
+	"(*net/http.Server).ListenAndServe\t--dynamic-10:3-->\tmain.handler\n" +
+	"main.handler\t--static-20:3-->\tgithub.com/me/x/util.Do[github.com/me/x/y.T]\n" +
+	"<root>\t--static-0:0-->\tmain.main\n"
+
+const callgraphDot = `digraph callgraph {
+  "main.main" -> "fmt.Println"
+  "main.main" -> "main.f$1" [label="x"]
+}`
+
+func TestFromCallGraph(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		opts    CallGraphOptions
+		parents map[string]string
+		edges   []string
+		cycle   []string
+	}{
+		{
+			name: "functions",
+			in:   callgraphOut,
+			parents: map[string]string{
+				"main":                              "1",
+				"net/http":                          "1",
+				"github.com/me/x/util":              "1",
+				"main.handler":                      "main",
+				"main.main":                         "main",
+				"(*net/http.Server).ListenAndServe": "net/http",
+				"net/http.ListenAndServe":           "net/http",
+				"github.com/me/x/util.Do[github.com/me/x/y.T]": "github.com/me/x/util",
+			},
+			edges: []string{
+				"main.main->net/http.ListenAndServe:2",
+				"net/http.ListenAndServe->(*net/http.Server).ListenAndServe",
+				"(*net/http.Server).ListenAndServe->main.handler",
+				"main.handler->github.com/me/x/util.Do[github.com/me/x/y.T]",
+			},
+		},
+		{
+			name: "packages",
+			in:   callgraphOut,
+			opts: CallGraphOptions{CollapsePackages: true},
+			parents: map[string]string{
+				"main":                 "1",
+				"net/http":             "1",
+				"github.com/me/x/util": "1",
+			},
+			edges: []string{
+				"main->net/http",
+				"main->github.com/me/x/util",
+				"net/http->main",
+			},
+			cycle: []string{"main", "net/http", "main-net/http", "net/http-main"},
+		},
+		{
+			name: "prefixes",
+			in:   callgraphOut,
+			opts: CallGraphOptions{Prefixes: []string{"main", "github.com/me/"}},
+			parents: map[string]string{
+				"main":                 "1",
+				"github.com/me/x/util": "1",
+				"main.handler":         "main",
+				"github.com/me/x/util.Do[github.com/me/x/y.T]": "github.com/me/x/util",
+			},
+			edges: []string{
+				"main.handler->github.com/me/x/util.Do[github.com/me/x/y.T]",
+			},
+		},
+		{
+			name: "graphviz",
+			in:   callgraphDot,
+			parents: map[string]string{
+				"main":        "1",
+				"fmt":         "1",
+				"main.f$1":    "main",
+				"main.main":   "main",
+				"fmt.Println": "fmt",
+			},
+			edges: []string{
+				"main.main->fmt.Println",
+				"main.main->main.f$1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromCallGraph(strings.NewReader(tt.in), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parents := make(map[string]string)
+			for _, c := range g.Root {
+				if c.isVertex() {
+					parents[c.ID] = c.ParentID
+				}
+			}
+			if !reflect.DeepEqual(parents, tt.parents) {
+				t.Errorf("got parents %v, want %v", parents, tt.parents)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %v, want %v", got, tt.edges)
+			}
+			for _, id := range tt.cycle {
+				if got := g.FindByID(id).Style.Attributes["strokeColor"]; got != CycleStyle["strokeColor"] {
+					t.Errorf("%s: got strokeColor %q, want %q", id, got, CycleStyle["strokeColor"])
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestDynamicCallsDashed(t *testing.T) {
+	g, err := FromCallGraph(strings.NewReader(callgraphOut), CallGraphOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range g.Root {
+		if !c.isEdge() {
+			continue
+		}
+		want := ""
+		if c.Target == "main.handler" {
+			want = "1"
+		}
+		if got := c.Style.Attributes["dashed"]; got != want {
+			t.Errorf("%s: got dashed %q, want %q", c.ID, got, want)
+		}
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	tests := []struct {
+		fn, want string
+	}{
+		{"main.main", "main"},
+		{"net/http.ListenAndServe", "net/http"},
+		{"(*net/http.Server).ListenAndServe", "net/http"},
+		{"github.com/me/x/util.Do[github.com/me/x/y.T]", "github.com/me/x/util"},
+		{"main.main$1", "main"},
+		{"<root>", ""},
+	}
+	for _, tt := range tests {
+		if got := funcPackage(tt.fn); got != tt.want {
+			t.Errorf("funcPackage(%q): got %q, want %q", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestFromCallGraphErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"no calls here\n",
+		`"main.main" -> "fmt.Println` + "\n",
+	} {
+		if _, err := FromCallGraph(strings.NewReader(in), CallGraphOptions{}); err == nil {
+			t.Errorf("FromCallGraph(%q): got no error", in)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package graw
+
+import "math"
+
+// DegreeCentrality returns, for every vertex of g, the number of
+// edges attached to it, scaled so that the highest score is 1.
+func DegreeCentrality(g *GraphModel) map[string]float64 {
+	nodes, adj := g.adjacency()
+	scores := make(map[string]float64, len(nodes))
+	for _, n := range nodes {
+		scores[n] += float64(len(adj[n]))
+		for _, m := range adj[n] {
+			scores[m]++
+		}
+	}
+	return scaleScores(scores)
+}
+
+// BetweennessCentrality returns, for every vertex of g, the fraction
+// of shortest directed paths between other vertices passing through
+// it, scaled so that the highest score is 1. It uses Brandes'
+// algorithm and treats every edge as having the same length.
+func BetweennessCentrality(g *GraphModel) map[string]float64 {
+	nodes, adj := g.adjacency()
+	scores := make(map[string]float64, len(nodes))
+	for _, n := range nodes {
+		scores[n] = 0
+	}
+
+	for _, s := range nodes {
+		var stack []string
+		pred := make(map[string][]string)
+		sigma := map[string]float64{s: 1}
+		dist := map[string]int{s: 0}
+
+		queue := []string{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range adj[v] {
+				if _, seen := dist[w]; !seen {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					pred[w] = append(pred[w], v)
+				}
+			}
+		}
+
+		delta := make(map[string]float64)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range pred[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				scores[w] += delta[w]
+			}
+		}
+	}
+
+	return scaleScores(scores)
+}
+
+// scaleScores divides every score by the highest one.
+func scaleScores(scores map[string]float64) map[string]float64 {
+	max := 0.0
+	for _, v := range scores {
+		max = math.Max(max, v)
+	}
+	if max > 0 {
+		for k, v := range scores {
+			scores[k] = v / max
+		}
+	}
+	return scores
+}
+
+// RankStyle describes how HighlightByRank maps a score between 0 and
+// 1 onto a vertex. Zero values leave the matching property untouched.
+type RankStyle struct {
+	// MinScale and MaxScale bound the factor applied to the vertex
+	// size; the vertex keeps its center. MinScale defaults to 1, and
+	// a vertex is never scaled below one pixel.
+	MinScale float64
+	MaxScale float64
+	// LowColor and HighColor are the #rrggbb fill colors for the
+	// lowest and highest scores; colors in between are interpolated.
+	LowColor  string
+	HighColor string
+}
+
+// HighlightByRank styles every vertex listed in scores according to
+// rs, so that the most central cells stand out. Scores are expected
+// in the range [0, 1], as returned by DegreeCentrality and
+// BetweennessCentrality.
+func HighlightByRank(g *GraphModel, scores map[string]float64, rs RankStyle) {
+	minScale := rs.MinScale
+	if minScale <= 0 {
+		minScale = 1
+	}
+	for i := range g.Root {
+		c := g.Root[i]
+		score, ok := scores[c.ID]
+		if !ok || !c.isVertex() {
+			continue
+		}
+		score = math.Min(math.Max(score, 0), 1)
+
+		if rs.MaxScale > 0 && c.Geometry != nil {
+			f := minScale + (rs.MaxScale-minScale)*score
+			w, h := c.Geometry.size()
+			nw, nh := maxInt(int(float64(w)*f), 1), maxInt(int(float64(h)*f), 1)
+			c.Geometry.X -= (nw - w) / 2
+			c.Geometry.Y -= (nh - h) / 2
+			c.Geometry.setSize(nw, nh)
+		}
+
+		if rs.LowColor != "" && rs.HighColor != "" {
+			c.Style.set("fillColor", mixColor(rs.LowColor, rs.HighColor, score))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package graw
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCentrality(t *testing.T) {
+	// b is the hub of a - b - {c, d}; c - e hangs off c.
+	g := newTestGraph([2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"b", "d"}, [2]string{"c", "e"})
+	for _, test := range []struct {
+		name   string
+		scores map[string]float64
+		want   map[string]float64
+	}{
+		{"degree", DegreeCentrality(&g), map[string]float64{"a": 1.0 / 3, "b": 1, "c": 2.0 / 3, "d": 1.0 / 3, "e": 1.0 / 3}},
+		// Paths through b: a-c, a-d, a-e; through c: a-e, b-e.
+		{"betweenness", BetweennessCentrality(&g), map[string]float64{"a": 0, "b": 1, "c": 2.0 / 3, "d": 0, "e": 0}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if len(test.scores) != len(test.want) {
+				t.Errorf("got %v, want %v", test.scores, test.want)
+			}
+			for id, want := range test.want {
+				if got := test.scores[id]; math.Abs(got-want) > 1e-9 {
+					t.Errorf("got %s %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHighlightByRank(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		score   float64
+		rs      RankStyle
+		fill    string
+		x, w, h int
+	}{
+		{"lowest", 0, RankStyle{MinScale: 1, MaxScale: 2, LowColor: "#ffffff", HighColor: "#ff0000"}, "#ffffff", 0, 120, 60},
+		{"highest", 1, RankStyle{MinScale: 1, MaxScale: 2, LowColor: "#ffffff", HighColor: "#ff0000"}, "#ff0000", -60, 240, 120},
+		{"middle", 0.5, RankStyle{MinScale: 1, MaxScale: 2, LowColor: "#fff", HighColor: "#f00"}, "#ff8080", -30, 180, 90},
+		{"clamped", 3, RankStyle{LowColor: "#000000", HighColor: "#0000ff"}, "#0000ff", 0, 120, 60},
+		{"color only", 1, RankStyle{}, "", 0, 120, 60},
+		{"zero min scale lowest", 0, RankStyle{MaxScale: 2}, "", 0, 120, 60},
+		{"zero min scale highest", 1, RankStyle{MaxScale: 2}, "", -60, 240, 120},
+		{"negative min scale", 0, RankStyle{MinScale: -1, MaxScale: 2}, "", 0, 120, 60},
+		{"tiny scale", 0, RankStyle{MinScale: 0.001, MaxScale: 0.001}, "", 59, 1, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			c := NewShape("a", "1").At(0, 0).Size(120, 60)
+			g.Add(c)
+			HighlightByRank(&g, map[string]float64{"a": test.score}, test.rs)
+			if got := c.Style.Attributes["fillColor"]; got != test.fill {
+				t.Errorf("got fillColor %q, want %q", got, test.fill)
+			}
+			if w, h := c.Geometry.size(); c.Geometry.X != test.x || w != test.w || h != test.h {
+				t.Errorf("got x %d size %dx%d, want x %d size %dx%d", c.Geometry.X, w, h, test.x, test.w, test.h)
+			}
+		})
+	}
+}
+
+func TestMixColor(t *testing.T) {
+	for _, test := range []struct {
+		from, to string
+		t        float64
+		want     string
+	}{
+		{"#000000", "#ffffff", 0, "#000000"},
+		{"#000000", "#ffffff", 1, "#ffffff"},
+		{"#000", "#fff", 0.5, "#808080"},
+		{"bogus", "#102030", 1, "#102030"},
+	} {
+		if got := mixColor(test.from, test.to, test.t); got != test.want {
+			t.Errorf("mixColor(%q, %q, %v): got %q, want %q", test.from, test.to, test.t, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+package graw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ciJob is a job of a CI pipeline.
+type ciJob struct {
+	id, name string
+	stage    string
+	needs    []string
+	// combos labels the instances of a job run for every
+	// combination of a matrix.
+	combos  []string
+	tooltip []string
+}
+
+// gitlabReserved are the top level keys of a GitLab CI configuration
+// which are not jobs.
+var gitlabReserved = map[string]bool{
+	"stages": true, "variables": true, "default": true, "include": true,
+	"workflow": true, "image": true, "services": true, "before_script": true,
+	"after_script": true, "cache": true,
+}
+
+// maxMatrixJobs is the number of jobs GitHub Actions runs at most for
+// a matrix, and maxParallelJobs the number GitLab CI runs at most for
+// a job with parallel.
+const (
+	maxMatrixJobs   = 256
+	maxParallelJobs = 200
+)
+
+// gitlabStages are the stages of a GitLab CI configuration which does
+// not list them.
+var gitlabStages = []string{".pre", "build", "test", "deploy", ".post"}
+
+// FromWorkflow returns a diagram of a CI pipeline read from a GitHub
+// Actions workflow or a GitLab CI configuration in YAML.
+//
+// Every job becomes a box, connected to the jobs it needs. Jobs run
+// for every combination of a matrix become a container holding a box
+// per combination. Metadata such as the runner, conditions and the
+// number of steps is shown in the tooltip. GitHub jobs are arranged in
+// columns by the length of their chain of needs, GitLab jobs in a
+// container per stage. ErrCycle is returned when jobs need each other,
+// and an error when a matrix expands to more jobs than the CI runs.
+func FromWorkflow(r io.Reader) (*GraphModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := decodeYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("graw: FromWorkflow: empty document")
+	}
+	root := jsonObject(docs[0])
+	if root == nil {
+		return nil, fmt.Errorf("graw: FromWorkflow: not a mapping")
+	}
+
+	if jobs := jsonObject(root["jobs"]); jobs != nil {
+		return githubWorkflow(jobs)
+	}
+	return gitlabPipeline(root)
+}
+
+// githubWorkflow returns the diagram of the jobs of a GitHub Actions
+// workflow.
+func githubWorkflow(jobs map[string]interface{}) (*GraphModel, error) {
+	byID := make(map[string]*ciJob)
+	var order []*ciJob
+	for _, id := range jsonKeys(jobs) {
+		spec := jsonObject(jobs[id])
+		j := &ciJob{id: id, name: id}
+		if name := jsonString(spec, "name"); name != "" {
+			j.name = name
+		}
+		j.needs = ciStrings(spec["needs"])
+
+		if v, ok := spec["runs-on"]; ok {
+			j.tooltip = append(j.tooltip, "runs-on: "+ciValue(v))
+		}
+		if v := jsonString(spec, "uses"); v != "" {
+			j.tooltip = append(j.tooltip, "uses: "+v)
+		}
+		if v, ok := spec["if"]; ok {
+			j.tooltip = append(j.tooltip, "if: "+ciValue(v))
+		}
+		if env, ok := spec["environment"]; ok {
+			if name := jsonString(jsonObject(env), "name"); name != "" {
+				env = name
+			}
+			j.tooltip = append(j.tooltip, "environment: "+ciValue(env))
+		}
+		if v, ok := spec["timeout-minutes"]; ok {
+			j.tooltip = append(j.tooltip, "timeout: "+ciValue(v)+" min")
+		}
+		if steps := jsonArray(spec["steps"]); len(steps) > 0 {
+			j.tooltip = append(j.tooltip, "steps: "+strconv.Itoa(len(steps)))
+		}
+
+		switch matrix := jsonObject(spec["strategy"])["matrix"].(type) {
+		case map[string]interface{}:
+			combos, err := githubMatrix(matrix)
+			if err != nil {
+				return nil, fmt.Errorf("graw: FromWorkflow: job %q: %v", id, err)
+			}
+			j.combos = combos
+		case string:
+			j.combos = []string{matrix}
+		}
+
+		byID[id] = j
+		order = append(order, j)
+	}
+
+	// column of a job: the length of its longest chain of needs
+	level := make(map[string]int)
+	visiting := make(map[string]bool)
+	var visit func(j *ciJob) (int, error)
+	visit = func(j *ciJob) (int, error) {
+		if l, ok := level[j.id]; ok {
+			return l, nil
+		}
+		if visiting[j.id] {
+			return 0, ErrCycle
+		}
+		visiting[j.id] = true
+		l := 0
+		for _, n := range j.needs {
+			if dep, ok := byID[n]; ok {
+				dl, err := visit(dep)
+				if err != nil {
+					return 0, err
+				}
+				if dl+1 > l {
+					l = dl + 1
+				}
+			}
+		}
+		level[j.id] = l
+		return l, nil
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	var columns [][]*Cell
+	var children []*Cell
+	ids := make(map[string]string)
+	for _, j := range order {
+		l, err := visit(j)
+		if err != nil {
+			return nil, err
+		}
+		for len(columns) <= l {
+			columns = append(columns, nil)
+		}
+		c, members := newCIJob(j, rootCellID, taken)
+		ids[j.id] = c.ID
+		columns[l] = append(columns[l], c)
+		children = append(children, members...)
+	}
+	layoutColumns(columns, 10, 10, 80)
+
+	for _, column := range columns {
+		for _, c := range column {
+			g.Add(c)
+		}
+	}
+	for _, c := range children {
+		g.Add(c)
+	}
+	addCIEdges(&g, order, ids, taken)
+	return &g, nil
+}
+
+// githubMatrix returns the combinations of a GitHub Actions matrix,
+// applying its exclude and include lists.
+func githubMatrix(matrix map[string]interface{}) ([]string, error) {
+	dims := make(map[string]interface{})
+	for k, v := range matrix {
+		if k != "include" && k != "exclude" {
+			dims[k] = v
+		}
+	}
+	combos, err := ciProduct(dims, maxMatrixJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []map[string]string
+	for _, combo := range combos {
+		excluded := false
+		for _, e := range jsonArray(matrix["exclude"]) {
+			if ciMatches(combo, jsonObject(e)) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, combo)
+		}
+	}
+
+	for _, inc := range jsonArray(matrix["include"]) {
+		extra := jsonObject(inc)
+		// an include entry extends the combinations whose original
+		// values it matches, or adds a combination of its own
+		orig := make(map[string]interface{})
+		for k, v := range extra {
+			if _, ok := dims[k]; ok {
+				orig[k] = v
+			}
+		}
+		extended := false
+		for _, combo := range kept {
+			if len(dims) > 0 && ciMatches(combo, orig) {
+				for k, v := range extra {
+					if _, ok := dims[k]; !ok {
+						combo[k] = ciValue(v)
+					}
+				}
+				extended = true
+			}
+		}
+		if !extended {
+			combo := make(map[string]string, len(extra))
+			for k, v := range extra {
+				combo[k] = ciValue(v)
+			}
+			kept = append(kept, combo)
+		}
+	}
+	if len(kept) > maxMatrixJobs {
+		return nil, fmt.Errorf("matrix of more than %d jobs", maxMatrixJobs)
+	}
+
+	labels := make([]string, len(kept))
+	for i, combo := range kept {
+		labels[i] = ciComboLabel(combo)
+	}
+	return labels, nil
+}
+
+// gitlabPipeline returns the diagram of the jobs of a GitLab CI
+// configuration.
+func gitlabPipeline(root map[string]interface{}) (*GraphModel, error) {
+	stages := ciStrings(root["stages"])
+	if len(stages) == 0 {
+		stages = gitlabStages
+	}
+	stageJobs := make(map[string][]*ciJob)
+
+	var order []*ciJob
+	for _, id := range jsonKeys(root) {
+		spec := jsonObject(root[id])
+		if gitlabReserved[id] || strings.HasPrefix(id, ".") || spec == nil {
+			continue
+		}
+		j := &ciJob{id: id, name: id, stage: jsonString(spec, "stage")}
+		if j.stage == "" {
+			j.stage = "test"
+		}
+		for _, n := range jsonArray(spec["needs"]) {
+			if name, ok := n.(string); ok {
+				j.needs = append(j.needs, name)
+			} else if name := jsonString(jsonObject(n), "job"); name != "" {
+				j.needs = append(j.needs, name)
+			}
+		}
+
+		if v, ok := spec["image"]; ok {
+			if name := jsonString(jsonObject(v), "name"); name != "" {
+				v = name
+			}
+			j.tooltip = append(j.tooltip, "image: "+ciValue(v))
+		}
+		if v, ok := spec["extends"]; ok {
+			j.tooltip = append(j.tooltip, "extends: "+strings.Join(ciStrings(v), ", "))
+		}
+		if v, ok := spec["when"]; ok {
+			j.tooltip = append(j.tooltip, "when: "+ciValue(v))
+		}
+		if rules := jsonArray(spec["rules"]); len(rules) > 0 {
+			j.tooltip = append(j.tooltip, "rules: "+strconv.Itoa(len(rules)))
+		}
+		if env, ok := spec["environment"]; ok {
+			if name := jsonString(jsonObject(env), "name"); name != "" {
+				env = name
+			}
+			j.tooltip = append(j.tooltip, "environment: "+ciValue(env))
+		}
+		if tags := ciStrings(spec["tags"]); len(tags) > 0 {
+			j.tooltip = append(j.tooltip, "tags: "+strings.Join(tags, ", "))
+		}
+		if v, ok := spec["allow_failure"]; ok {
+			j.tooltip = append(j.tooltip, "allow_failure: "+ciValue(v))
+		}
+		if script := jsonArray(spec["script"]); len(script) > 0 {
+			j.tooltip = append(j.tooltip, "script lines: "+strconv.Itoa(len(script)))
+		}
+
+		switch parallel := spec["parallel"].(type) {
+		case float64:
+			if parallel > maxParallelJobs {
+				return nil, fmt.Errorf("graw: FromWorkflow: job %q: parallel of more than %d jobs", id, maxParallelJobs)
+			}
+			for i := 1; i <= int(parallel); i++ {
+				j.combos = append(j.combos, fmt.Sprintf("%d/%d", i, int(parallel)))
+			}
+		case map[string]interface{}:
+			for _, entry := range jsonArray(parallel["matrix"]) {
+				combos, err := ciProduct(jsonObject(entry), maxParallelJobs-len(j.combos))
+				if err != nil {
+					return nil, fmt.Errorf("graw: FromWorkflow: job %q: parallel of more than %d jobs", id, maxParallelJobs)
+				}
+				for _, combo := range combos {
+					j.combos = append(j.combos, ciComboLabel(combo))
+				}
+			}
+		}
+
+		if _, ok := stageJobs[j.stage]; !ok {
+			known := false
+			for _, s := range stages {
+				known = known || s == j.stage
+			}
+			if !known {
+				stages = append(stages, j.stage)
+			}
+		}
+		stageJobs[j.stage] = append(stageJobs[j.stage], j)
+		order = append(order, j)
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string)
+	var lanes []*Cell
+	var cells []*Cell
+	for _, stage := range stages {
+		jobs := stageJobs[stage]
+		if len(jobs) == 0 {
+			continue
+		}
+		lane := newContainer(taken.unique("stage-"+stage), rootCellID, stage)
+		var members, nested []*Cell
+		for _, j := range jobs {
+			c, children := newCIJob(j, lane.ID, taken)
+			ids[j.id] = c.ID
+			members = append(members, c)
+			nested = append(nested, children...)
+		}
+		w, h := layoutColumns([][]*Cell{members}, groupPadding, groupHeader+groupPadding, groupPadding)
+		lane.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+		lanes = append(lanes, lane)
+		cells = append(cells, members...)
+		cells = append(cells, nested...)
+	}
+	lanesRow := make([][]*Cell, len(lanes))
+	for i, lane := range lanes {
+		lanesRow[i] = []*Cell{lane}
+	}
+	layoutColumns(lanesRow, 10, 10, groupGap)
+
+	for _, c := range lanes {
+		g.Add(c)
+	}
+	for _, c := range cells {
+		g.Add(c)
+	}
+	addCIEdges(&g, order, ids, taken)
+	return &g, nil
+}
+
+// newCIJob returns the cell of job j, child of parent, and the cells
+// of its matrix combinations.
+func newCIJob(j *ciJob, parent string, taken idSet) (*Cell, []*Cell) {
+	var c *Cell
+	var children []*Cell
+	if len(j.combos) == 0 {
+		c = NewShape(taken.unique("job-"+j.id), parent)
+		c.Value = j.name
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+	} else {
+		c = newContainer(taken.unique("job-"+j.id), parent, j.name)
+		for _, combo := range j.combos {
+			m := NewShape(taken.unique(c.ID+"-"+combo), c.ID)
+			m.Value = combo
+			m.Style = Style{Attributes: shapeStyle("rounded")}
+			m.Style.set("fontSize", "10")
+			SizeToFit(m, nil, defaultWidth, 30)
+			children = append(children, m)
+		}
+		w, h := layoutColumns([][]*Cell{children}, groupPadding, groupHeader+groupPadding, 10)
+		c.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+	}
+	if len(j.tooltip) > 0 {
+		c.Data = map[string]string{"tooltip": strings.Join(j.tooltip, "\n")}
+	}
+	return c, children
+}
+
+// addCIEdges connects the jobs to the jobs they need.
+func addCIEdges(g *GraphModel, jobs []*ciJob, ids map[string]string, taken idSet) {
+	for _, j := range jobs {
+		for _, n := range j.needs {
+			from, ok := ids[n]
+			if !ok {
+				continue
+			}
+			to := ids[j.id]
+			g.Add(newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to))
+		}
+	}
+}
+
+// ciProduct returns the cartesian product of the values of dims, in
+// which a list holds several values and anything else a single one.
+// It returns an error, without expanding dims, when the product holds
+// more than limit combinations.
+func ciProduct(dims map[string]interface{}, limit int) ([]map[string]string, error) {
+	keys := jsonKeys(dims)
+	values := make([][]interface{}, len(keys))
+	size := 1
+	for i, k := range keys {
+		values[i] = jsonArray(dims[k])
+		if values[i] == nil {
+			values[i] = []interface{}{dims[k]}
+		}
+		size *= len(values[i])
+		if size > limit {
+			return nil, fmt.Errorf("matrix of more than %d jobs", limit)
+		}
+	}
+
+	combos := []map[string]string{{}}
+	for i, k := range keys {
+		next := make([]map[string]string, 0, len(combos)*len(values[i]))
+		for _, combo := range combos {
+			for _, v := range values[i] {
+				c := make(map[string]string, len(combo)+1)
+				for ck, cv := range combo {
+					c[ck] = cv
+				}
+				c[k] = ciValue(v)
+				next = append(next, c)
+			}
+		}
+		combos = next
+	}
+	if len(combos) == 1 && len(combos[0]) == 0 {
+		return nil, nil
+	}
+	return combos, nil
+}
+
+// ciMatches tells whether combo holds all the values of filter.
+func ciMatches(combo map[string]string, filter map[string]interface{}) bool {
+	for k, v := range filter {
+		if combo[k] != ciValue(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ciComboLabel labels a matrix combination, e.g. "go=1.21, os=linux".
+func ciComboLabel(combo map[string]string) string {
+	parts := make([]string, 0, len(combo))
+	for _, k := range sortedKeys(combo) {
+		parts = append(parts, k+"="+combo[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ciStrings returns v, a string or a list of strings, as a list.
+func ciStrings(v interface{}) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return jsonStrings(v)
+}
+
+// ciValue formats a decoded YAML value for a label.
+func ciValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return jsonNumber(v)
+	case nil:
+		return ""
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = ciValue(e)
+		}
+		return strings.Join(parts, ", ")
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
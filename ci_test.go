@@ -0,0 +1,178 @@
+package graw
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFromWorkflow(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		src     string
+		parents map[string]string
+		edges   []string
+	}{
+		{
+			name: "github",
+			src: `name: CI
+on: [push]
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+  test:
+    needs: lint
+    strategy:
+      matrix:
+        os: [ubuntu, windows]
+        go: ["1.20", "1.21"]
+        exclude:
+          - os: windows
+            go: "1.20"
+        include:
+          - os: macos
+            go: "1.21"
+  deploy:
+    needs: [test, lint]
+    environment: {name: prod}
+    if: github.ref == 'refs/heads/main'
+`,
+			parents: map[string]string{
+				"job-lint":                     "1",
+				"job-test":                     "1",
+				"job-deploy":                   "1",
+				"job-test-go=1.20, os=ubuntu":  "job-test",
+				"job-test-go=1.21, os=ubuntu":  "job-test",
+				"job-test-go=1.21, os=windows": "job-test",
+				"job-test-go=1.21, os=macos":   "job-test",
+			},
+			edges: []string{"job-test->job-deploy", "job-lint->job-deploy", "job-lint->job-test"},
+		},
+		{
+			name: "gitlab",
+			src: `stages: [build, test, deploy]
+.base: &base
+  image: golang:1.21
+build:
+  <<: *base
+  stage: build
+unit:
+  stage: test
+  needs: [build]
+  parallel:
+    matrix:
+      - GO: ["1.20", "1.21"]
+        DB: postgres
+lint:
+  stage: test
+  needs: []
+deploy:
+  stage: deploy
+  environment: {name: prod}
+  needs: [{job: unit}, lint]
+  when: manual
+`,
+			parents: map[string]string{
+				"stage-build":                   "1",
+				"stage-test":                    "1",
+				"stage-deploy":                  "1",
+				"job-build":                     "stage-build",
+				"job-lint":                      "stage-test",
+				"job-unit":                      "stage-test",
+				"job-unit-DB=postgres, GO=1.20": "job-unit",
+				"job-unit-DB=postgres, GO=1.21": "job-unit",
+				"job-deploy":                    "stage-deploy",
+			},
+			edges: []string{"job-unit->job-deploy", "job-lint->job-deploy", "job-build->job-unit"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := FromWorkflow(strings.NewReader(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			parents := make(map[string]string)
+			for _, c := range g.Root {
+				if c.isVertex() {
+					parents[c.ID] = c.ParentID
+				}
+			}
+			if !reflect.DeepEqual(parents, test.parents) {
+				t.Errorf("got vertices %v, want %v", parents, test.parents)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, test.edges) {
+				t.Errorf("got edges %v, want %v", got, test.edges)
+			}
+			if issues := ValidateStyles(g); len(issues) > 0 {
+				t.Errorf("got style issues %v", issues)
+			}
+		})
+	}
+}
+
+func TestFromWorkflowErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, src string
+		want      string
+	}{
+		{"cycle", "jobs:\n  a:\n    needs: b\n  b:\n    needs: a\n", ErrCycle.Error()},
+		{"not a mapping", "- a\n", "graw: FromWorkflow: not a mapping"},
+		{"malformed", "jobs: [a\n", "graw: yaml: line 1"},
+		{"matrix over the cap", ciMatrix(16, 17, ""), `graw: FromWorkflow: job "a": matrix of more than 256 jobs`},
+		{"huge matrix", ciMatrix(1000, 1000, ""), `graw: FromWorkflow: job "a": matrix of more than 256 jobs`},
+		{"include over the cap", ciMatrix(16, 16, "        include:\n          - x: new\n"), `graw: FromWorkflow: job "a": matrix of more than 256 jobs`},
+		{"parallel over the cap", "a:\n  script: [make]\n  parallel: 201\n", `graw: FromWorkflow: job "a": parallel of more than 200 jobs`},
+		{"parallel matrix over the cap", "a:\n  script: [make]\n  parallel:\n    matrix:\n      - {x: " + ciList(10) + ", y: " + ciList(10) + "}\n      - {x: " + ciList(101) + "}\n",
+			`graw: FromWorkflow: job "a": parallel of more than 200 jobs`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := FromWorkflow(strings.NewReader(test.src))
+			if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+// ciList returns a YAML flow list of the numbers 1 to n.
+func ciList(n int) string {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = strconv.Itoa(i + 1)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// ciMatrix returns a GitHub workflow with the job "a" run for an x by y
+// matrix, followed by the lines of extra.
+func ciMatrix(x, y int, extra string) string {
+	return "jobs:\n  a:\n    strategy:\n      matrix:\n        x: " + ciList(x) + "\n        y: " + ciList(y) + "\n" + extra
+}
+
+func TestFromWorkflowMatrixCap(t *testing.T) {
+	for _, test := range []struct {
+		name, src string
+		want      int
+	}{
+		{"github at the cap", ciMatrix(16, 16, ""), 256},
+		{"gitlab parallel at the cap", "a:\n  script: [make]\n  parallel: 200\n", 200},
+		{"gitlab matrix at the cap", "a:\n  script: [make]\n  parallel:\n    matrix:\n      - {x: " + ciList(10) + ", y: " + ciList(10) + "}\n      - {x: " + ciList(100) + "}\n", 200},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := FromWorkflow(strings.NewReader(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			jobs := 0
+			for _, c := range g.Root {
+				if c.isVertex() && c.ParentID == "job-a" {
+					jobs++
+				}
+			}
+			if jobs != test.want {
+				t.Errorf("got %d jobs, want %d", jobs, test.want)
+			}
+		})
+	}
+}
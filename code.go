@@ -0,0 +1,114 @@
+package graw
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// codeFontFamily is the font of code snippets, one every draw.io
+// installation offers.
+const codeFontFamily = "Courier New"
+
+// CodeOptions configures SetCode.
+type CodeOptions struct {
+	// Title is shown in bold above the code, e.g. the name of the
+	// type or file the snippet is taken from.
+	Title string
+	// MaxLines and MaxColumns limit the size of the snippet; longer
+	// snippets and lines are cut, ending in "…". Zero means no limit.
+	MaxLines, MaxColumns int
+	// TabWidth is the number of columns of a tab, 4 if zero.
+	TabWidth int
+	// FontSize is the size of the code, 11 if zero.
+	FontSize float64
+}
+
+// SetCode makes the label of the vertex c a monospaced code snippet
+// and resizes c to fit it. The code is trimmed: tabs are expanded,
+// leading and trailing blank lines and trailing spaces dropped and
+// the indentation the lines share removed. The fill color of c is
+// kept when set, otherwise a light gray background is used.
+func SetCode(c *Cell, code string, opts CodeOptions) *Cell {
+	if opts.FontSize <= 0 {
+		opts.FontSize = 11
+	}
+	lines := trimCode(code, opts.TabWidth)
+	if opts.MaxColumns > 0 {
+		for i, l := range lines {
+			if utf8.RuneCountInString(l) > opts.MaxColumns {
+				lines[i] = string([]rune(l)[:opts.MaxColumns-1]) + "…"
+			}
+		}
+	}
+	if opts.MaxLines > 0 && len(lines) > opts.MaxLines {
+		lines = append(lines[:opts.MaxLines-1], "…")
+	}
+
+	c.Value = ""
+	if opts.Title != "" {
+		c.Value = "<div><b>" + html.EscapeString(opts.Title) + "</b></div>"
+	}
+	c.Value += `<pre style="margin:0">` + html.EscapeString(strings.Join(lines, "\n")) + "</pre>"
+	c.Style.set("html", "1")
+	c.Style.set("whiteSpace", "wrap")
+	c.Style.set("fontFamily", codeFontFamily)
+	c.Style.setFloat("fontSize", opts.FontSize)
+	c.Style.set("align", "left")
+	c.Style.set("verticalAlign", "top")
+	c.Style.set("spacingLeft", "6")
+	c.Style.set("spacingTop", "4")
+	if c.Style.Attributes["fillColor"] == "" {
+		c.Style.set("fillColor", "#f5f5f5")
+		c.Style.set("strokeColor", "#666666")
+	}
+	return SizeToFit(c, nil, 0, 0)
+}
+
+// trimCode returns the lines of code with tabs expanded to tabWidth
+// columns, 4 if zero, without the blank lines around the code, the
+// trailing spaces of lines and the indentation the lines share.
+func trimCode(code string, tabWidth int) []string {
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	code = strings.ReplaceAll(code, "\r\n", "\n")
+	var lines []string
+	for _, l := range strings.Split(code, "\n") {
+		var b strings.Builder
+		col := 0
+		for _, r := range l {
+			if r == '\t' {
+				n := tabWidth - col%tabWidth
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			b.WriteRune(r)
+			col++
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+		if n := len(l) - len(strings.TrimLeft(l, " ")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrimCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		tabWidth int
+		want     []string
+	}{
+		{"shared indentation", "  a\n    b\n\n  c", 0, []string{"a", "  b", "", "c"}},
+		{"blank lines around", "\n\n  a  \n\n", 0, []string{"a"}},
+		{"tabs", "\tif x {\n\t\ty()\n\t}", 0, []string{"if x {", "    y()", "}"}},
+		{"tab width", "\tif x {\n\t\ty()\n\t}", 2, []string{"if x {", "  y()", "}"}},
+		{"tab stops", "a\tb", 4, []string{"a   b"}},
+		{"crlf", "a\r\nb\r\n", 0, []string{"a", "b"}},
+		{"empty", " \n\t\n", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimCode(tt.code, tt.tabWidth); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCode(t *testing.T) {
+	const code = "\n\ttype User struct {\n\t\tName string\n\t\tAge  int\n\t}\n"
+	tests := []struct {
+		name  string
+		fill  string
+		opts  CodeOptions
+		label string
+		want  string
+	}{
+		{
+			name:  "plain",
+			label: `<pre style="margin:0">type User struct {` + "\n    Name string\n    Age  int\n}</pre>",
+			want:  "#f5f5f5",
+		},
+		{
+			name:  "title and limits",
+			opts:  CodeOptions{Title: "a <b>.go", MaxLines: 3, MaxColumns: 8},
+			label: `<div><b>a &lt;b&gt;.go</b></div><pre style="margin:0">type Us…` + "\n    Nam…\n…</pre>",
+			want:  "#f5f5f5",
+		},
+		{
+			name:  "fill kept",
+			fill:  "#dae8fc",
+			label: `<pre style="margin:0">type User struct {` + "\n    Name string\n    Age  int\n}</pre>",
+			want:  "#dae8fc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("c", rootCellID)
+			if tt.fill != "" {
+				c.Fill(tt.fill)
+			}
+			SetCode(c, code, tt.opts)
+			if c.Value != tt.label {
+				t.Errorf("got label %q, want %q", c.Value, tt.label)
+			}
+			if got := c.Style.Attributes["fillColor"]; got != tt.want {
+				t.Errorf("got fillColor %q, want %q", got, tt.want)
+			}
+			if got := c.Style.Attributes["fontFamily"]; got != codeFontFamily {
+				t.Errorf("got fontFamily %q, want %q", got, codeFontFamily)
+			}
+			if w, h := c.Geometry.size(); w <= 0 || h <= 0 {
+				t.Errorf("got size %dx%d, want it fitted", w, h)
+			}
+		})
+	}
+}
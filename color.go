@@ -0,0 +1,36 @@
+package graw
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// mixColor linearly interpolates between the #rrggbb colors from and
+// to. t=0 yields from, t=1 yields to.
+func mixColor(from, to string, t float64) string {
+	a, b := parseColor(from), parseColor(to)
+	var out [3]int
+	for i := range out {
+		out[i] = int(math.Round(float64(a[i]) + (float64(b[i])-float64(a[i]))*t))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", out[0], out[1], out[2])
+}
+
+// parseColor returns the red, green and blue components of a #rgb or
+// #rrggbb color. Malformed colors yield black.
+func parseColor(s string) [3]int {
+	s = normalizeColor(s)
+	var rgb [3]int
+	if len(s) != 7 {
+		return rgb
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(s[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return [3]int{}
+		}
+		rgb[i] = int(v)
+	}
+	return rgb
+}
@@ -0,0 +1,178 @@
+package graw
+
+import "strconv"
+
+// CommunityDetection finds communities among the vertices of g, sets
+// of vertices linked more densely with one another than with the rest
+// of the graph, using the Louvain method: vertices move to the
+// community of a neighbor as long as the modularity of the split
+// grows, then communities are merged into single vertices and the
+// process repeats. The direction of the edges is ignored.
+//
+// Unlike Partition, the number and the sizes of the communities follow
+// from the graph. The result maps the ID of every vertex to its
+// community, numbered from 0 in the order of their first vertex;
+// vertices without edges are communities of their own.
+func CommunityDetection(g *GraphModel) map[string]int {
+	nodes, adj := g.adjacency()
+	pos := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		pos[n] = i
+	}
+	// weights holds the edges of the current level in both directions,
+	// self-loops once.
+	weights := make([]map[int]float64, len(nodes))
+	for i := range weights {
+		weights[i] = make(map[int]float64)
+	}
+	for _, n := range nodes {
+		for _, m := range adj[n] {
+			if m != n {
+				weights[pos[n]][pos[m]]++
+				weights[pos[m]][pos[n]]++
+			}
+		}
+	}
+
+	// member maps the vertices of g to the vertices of the current
+	// level.
+	member := make([]int, len(nodes))
+	for i := range member {
+		member[i] = i
+	}
+	for {
+		comm, moved := louvainLevel(weights)
+		if !moved {
+			break
+		}
+		next := make([]map[int]float64, 0, len(weights))
+		for i, ws := range weights {
+			for len(next) <= comm[i] {
+				next = append(next, make(map[int]float64))
+			}
+			for j, w := range ws {
+				switch {
+				case i == j:
+					next[comm[i]][comm[i]] += w
+				case comm[i] == comm[j]:
+					// Counted from both ends.
+					next[comm[i]][comm[i]] += w / 2
+				default:
+					next[comm[i]][comm[j]] += w
+				}
+			}
+		}
+		for i := range member {
+			member[i] = comm[member[i]]
+		}
+		weights = next
+	}
+
+	out := make(map[string]int, len(nodes))
+	number := make(map[int]int)
+	for i, n := range nodes {
+		if _, ok := number[member[i]]; !ok {
+			number[member[i]] = len(number)
+		}
+		out[n] = number[member[i]]
+	}
+	return out
+}
+
+// louvainLevel moves the vertices of a level, given by the weights of
+// their edges, between communities while modularity grows. It returns
+// the community of every vertex, numbered from 0 in the order of
+// their first vertex, and whether any vertex moved.
+func louvainLevel(weights []map[int]float64) ([]int, bool) {
+	n := len(weights)
+	comm := make([]int, n)
+	degree := make([]float64, n)
+	total := make([]float64, n)
+	var m2 float64
+	for i, ws := range weights {
+		comm[i] = i
+		for j, w := range ws {
+			degree[i] += w
+			if i == j {
+				degree[i] += w
+			}
+		}
+		total[i] = degree[i]
+		m2 += degree[i]
+	}
+	if m2 == 0 {
+		return comm, false
+	}
+
+	moved := false
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n; i++ {
+			own := comm[i]
+			total[own] -= degree[i]
+			links := make(map[int]float64)
+			for j, w := range weights[i] {
+				if j != i {
+					links[comm[j]] += w
+				}
+			}
+			// The gain in modularity of joining c, up to a constant
+			// factor.
+			gain := func(c int) float64 {
+				return links[c] - total[c]*degree[i]/m2
+			}
+			// Ties go to the lowest community, which keeps the
+			// result independent of the map order.
+			best, bestGain := own, gain(own)
+			for c := range links {
+				if c == own {
+					continue
+				}
+				if d := gain(c); d > bestGain || d == bestGain && best != own && c < best {
+					best, bestGain = c, d
+				}
+			}
+			comm[i] = best
+			total[best] += degree[i]
+			if best != own {
+				moved, improved = true, true
+			}
+		}
+	}
+
+	number := make(map[int]int)
+	for i, c := range comm {
+		if _, ok := number[c]; !ok {
+			number[c] = len(number)
+		}
+		comm[i] = number[c]
+	}
+	return comm, moved
+}
+
+// GroupCommunities wraps the vertices of each community of g found by
+// CommunityDetection into a container, labelled "Community 1",
+// "Community 2" and so on, and lays the layers out again like
+// AutoGroup, bringing structure to flat graphs, e.g. imported ones
+// without any grouping data. Vertices alone in their community stay
+// ungrouped. It returns the IDs of the containers.
+func GroupCommunities(g *GraphModel) []string {
+	comm := CommunityDetection(g)
+	sizes := make(map[int]int)
+	for _, c := range comm {
+		sizes[c]++
+	}
+	// Number the communities which get a container.
+	labels := make(map[int]string)
+	for _, c := range g.Root {
+		if k, ok := comm[c.ID]; ok && sizes[k] > 1 && labels[k] == "" {
+			labels[k] = "Community " + strconv.Itoa(len(labels)+1)
+		}
+	}
+	return AutoGroup(g, func(c *Cell) string {
+		if k, ok := comm[c.ID]; ok {
+			return labels[k]
+		}
+		return ""
+	})
+}
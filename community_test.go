@@ -0,0 +1,56 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommunityDetection(t *testing.T) {
+	tests := []struct {
+		name string
+		g    GraphModel
+		want map[string]int
+	}{
+		{"two triangles", twoTriangles(), map[string]int{"a": 0, "b": 0, "c": 0, "d": 1, "e": 1, "f": 1}},
+		{"isolated vertex", func() GraphModel {
+			g := newTestGraph([2]string{"a", "b"})
+			g.Add(NewShape("x", "1"))
+			return g
+		}(), map[string]int{"a": 0, "b": 0, "x": 1}},
+		{"self-loop", newTestGraph([2]string{"a", "a"}, [2]string{"b", "c"}), map[string]int{"a": 0, "b": 1, "c": 1}},
+		{"no edges", func() GraphModel {
+			g := NewGraph()
+			g.Add(NewShape("x", "1"))
+			g.Add(NewShape("y", "1"))
+			return g
+		}(), map[string]int{"x": 0, "y": 1}},
+		{"empty", NewGraph(), map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommunityDetection(&tt.g); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupCommunities(t *testing.T) {
+	g := twoTriangles()
+	g.Add(NewShape("x", "1"))
+	groups := GroupCommunities(&g)
+	if want := []string{"group-Community 1", "group-Community 2"}; !reflect.DeepEqual(groups, want) {
+		t.Fatalf("got %v, want %v", groups, want)
+	}
+	parents := map[string]string{"a": groups[0], "c": groups[0], "d": groups[1], "f": groups[1], "x": "1"}
+	for id, want := range parents {
+		if got := g.FindByID(id).ParentID; got != want {
+			t.Errorf("got %s in %q, want %q", id, got, want)
+		}
+	}
+	for i, id := range groups {
+		if got, want := g.FindByID(id).Value, []string{"Community 1", "Community 2"}[i]; got != want {
+			t.Errorf("got label %q, want %q", got, want)
+		}
+	}
+}
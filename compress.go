@@ -0,0 +1,103 @@
+package graw
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// DecodeLimits bounds the work of decoding a diagram, protecting
+// servers reading uploaded files from compression bombs: a few
+// kilobytes of compressed text can inflate to gigabytes. Zero values
+// leave the respective limit off.
+type DecodeLimits struct {
+	// MaxInflatedSize is the number of bytes a compressed diagram may
+	// inflate to.
+	MaxInflatedSize int
+	// MaxElements is the number of XML elements a diagram may have.
+	MaxElements int
+}
+
+// DefaultDecodeLimits are the limits used when none are given.
+var DefaultDecodeLimits = DecodeLimits{
+	MaxInflatedSize: 64 << 20,
+	MaxElements:     1000000,
+}
+
+// DecodeCompressed decodes text, the content of a diagram element of a
+// file saved by draw.io in its default compressed format: the XML of
+// an mxGraphModel, URL encoded, deflated and base64 encoded. Errors
+// for diagrams breaking l wrap ErrLimit.
+func DecodeCompressed(text string, l DecodeLimits) (*GraphModel, error) {
+	o := DefaultParseOptions
+	o.DecodeLimits = l
+	g, err := decodeCompressed(text, o)
+	if err != nil {
+		return nil, fmt.Errorf("graw: DecodeCompressed: %w", err)
+	}
+	return g, nil
+}
+
+// decodeCompressed decodes the compressed diagram text within o.
+func decodeCompressed(text string, o ParseOptions) (*GraphModel, error) {
+	data, err := inflateDiagram(text, o.DecodeLimits)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.check(data); err != nil {
+		return nil, err
+	}
+	return decodeModel(data, true, o)
+}
+
+// EncodeCompressed returns g in the compressed format draw.io saves
+// diagrams in by default: the XML of g, URL encoded, deflated and
+// base64 encoded, to be written as the content of a diagram element.
+func EncodeCompressed(g *GraphModel) (string, error) {
+	data, err := xml.Marshal(g)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	w, err := flate.NewWriter(&b, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, url.PathEscape(string(data))); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes()), nil
+}
+
+// inflateDiagram returns the XML of the compressed diagram text,
+// reading at most l.MaxInflatedSize bytes.
+func inflateDiagram(text string, l DecodeLimits) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %v", err)
+	}
+	var r io.Reader = flate.NewReader(bytes.NewReader(raw))
+	if l.MaxInflatedSize > 0 {
+		r = io.LimitReader(r, int64(l.MaxInflatedSize)+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deflate data: %v", err)
+	}
+	if l.MaxInflatedSize > 0 && len(data) > l.MaxInflatedSize {
+		return nil, fmt.Errorf("%w: diagram inflates to more than %d bytes", ErrLimit, l.MaxInflatedSize)
+	}
+	s, err := url.PathUnescape(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL encoding: %v", err)
+	}
+	return []byte(s), nil
+}
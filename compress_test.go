@@ -0,0 +1,165 @@
+package graw
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// compress returns s URL encoded, deflated and base64 encoded.
+func compress(t *testing.T, s string) string {
+	return deflate(t, url.PathEscape(s))
+}
+
+// deflate returns s deflated and base64 encoded.
+func deflate(t *testing.T, s string) string {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := flate.NewWriter(&b, flate.BestSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+func TestEncodeCompressed(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	g.FindByID("a").Value = "a & ü + 100%"
+	text, err := EncodeCompressed(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := DecodeCompressed(text, DefaultDecodeLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.FindByID("a").Value; got != "a & ü + 100%" {
+		t.Errorf("got label %q", got)
+	}
+	if got, want := edgeList(h), edgeList(&g); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got edges %q, want %q", got, want)
+	}
+}
+
+func TestDecodeCompressed(t *testing.T) {
+	model := `<mxGraphModel><root><mxCell id="0"/><mxCell id="1" parent="0"/><mxCell id="a" value="x" parent="1" vertex="1"><mxGeometry width="10" height="10" as="geometry"/></mxCell></root></mxGraphModel>`
+	tests := []struct {
+		name string
+		text string
+		l    DecodeLimits
+		err  error
+		want string
+	}{
+		{name: "model", text: compress(t, model), want: "x"},
+		{name: "surrounding space", text: "\n  " + compress(t, model) + "\n", want: "x"},
+		{name: "within limits", text: compress(t, model), l: DecodeLimits{MaxInflatedSize: len(url.PathEscape(model)), MaxElements: 6}, want: "x"},
+		{name: "inflates too much", text: compress(t, model), l: DecodeLimits{MaxInflatedSize: len(url.PathEscape(model)) - 1}, err: ErrLimit},
+		{name: "too many elements", text: compress(t, model), l: DecodeLimits{MaxElements: 5}, err: ErrLimit},
+		{name: "bomb", text: deflate(t, "<mxGraphModel>"+strings.Repeat(" ", 10<<20)+"</mxGraphModel>"), l: DecodeLimits{MaxInflatedSize: 1 << 20}, err: ErrLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := DecodeCompressed(tt.text, tt.l)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("got %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.FindByID("a").Value != tt.want {
+				t.Errorf("got label %q, want %q", g.FindByID("a").Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCompressedErrors(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"base64", "not base64!", "graw: DecodeCompressed: invalid base64: "},
+		{"deflate", base64.StdEncoding.EncodeToString([]byte("plain")), "graw: DecodeCompressed: invalid deflate data: "},
+		{"url encoding", deflate(t, "<mxGraphModel>%zz</mxGraphModel>"), "graw: DecodeCompressed: invalid URL encoding: "},
+		{"xml", compress(t, "<mxGraphModel><root>"), "graw: DecodeCompressed: "},
+	}
+	for _, tt := range tests {
+		_, err := DecodeCompressed(tt.text, DefaultDecodeLimits)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParseCompressedPages(t *testing.T) {
+	page := func(label string) string {
+		g := NewGraph()
+		g.Add(NewShape("a", "1").Label(label))
+		text, err := EncodeCompressed(&g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return text
+	}
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"compressed", `<mxfile><diagram name="p">` + page("one") + `</diagram></mxfile>`, "one"},
+		{"indented", "<mxfile>\n  <diagram name=\"p\">\n    " + page("one") + "\n  </diagram>\n</mxfile>", "one"},
+		{"first of pages", `<mxfile><diagram>` + page("one") + `</diagram><diagram>` + page("two") + `</diagram></mxfile>`, "one"},
+		{"compressed after plain", `<mxfile><diagram><mxGraphModel><root><mxCell id="0"/><mxCell id="1" parent="0"/><mxCell id="a" value="plain" parent="1" vertex="1"/></root></mxGraphModel></diagram><diagram>` + page("two") + `</diagram></mxfile>`, "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Parse(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := g.FindByID("a").Value; got != tt.want {
+				t.Errorf("got label %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	_, err := ParseWithOptions(strings.NewReader(`<mxfile><diagram>`+page("one")+`</diagram></mxfile>`), ParseOptions{DecodeLimits: DecodeLimits{MaxElements: 2}})
+	if !errors.Is(err, ErrLimit) {
+		t.Errorf("got %v, want ErrLimit", err)
+	}
+}
+
+func TestDocumentCompressed(t *testing.T) {
+	d := NewDocument()
+	d.Compressed = true
+	g := NewGraph()
+	g.Add(NewShape("a", "1").Label("x"))
+	d.AddPage("p", &g)
+	var b strings.Builder
+	if err := xml.NewEncoder(&b).Encode(d); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "<mxGraphModel") {
+		t.Errorf("got an uncompressed page: %s", b.String())
+	}
+	text, err := EncodeCompressed(&g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<diagram id="page-1" name="p">` + text + `</diagram>`; !strings.Contains(b.String(), want) {
+		t.Errorf("%s does not contain %s", b.String(), want)
+	}
+}
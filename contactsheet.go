@@ -0,0 +1,73 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"math"
+)
+
+// ContactSheetOptions configures ContactSheet.
+type ContactSheetOptions struct {
+	// Columns is the number of thumbnails per row, 4 if zero.
+	Columns int
+	// Width and Height bound the size of a thumbnail, 200 by 150 if
+	// zero. Thumbnails keep the aspect ratio of their page.
+	Width, Height int
+	// Render configures the rendering of the pages, e.g. their
+	// Background; its Scale is ignored.
+	Render SVGOptions
+}
+
+// ContactSheet returns an index diagram of the pages of d: a grid of
+// thumbnails, SVG images of the pages labeled with their names, each
+// linking to its page. Add it to d as a page to browse large generated
+// documents in draw.io, or render it with RenderSVG to get one index
+// image. Pages without a model are skipped.
+func ContactSheet(d *Document, opts ContactSheetOptions) (*GraphModel, error) {
+	if opts.Columns <= 0 {
+		opts.Columns = 4
+	}
+	if opts.Width <= 0 || opts.Height <= 0 {
+		opts.Width, opts.Height = 200, 150
+	}
+	// Room for the page name below the thumbnails.
+	const labelHeight = 30
+
+	g := NewGraph()
+	taken := g.idSet()
+	var n int
+	for _, p := range d.Pages() {
+		if p.Model == nil {
+			continue
+		}
+		ro := opts.Render
+		ro.Scale = 1
+		bounds := (&svgRenderer{g: p.Model, opts: ro}).bounds()
+		w, h := opts.Width, opts.Height
+		if !bounds.Empty() {
+			ro.Scale = math.Min(float64(opts.Width)/float64(bounds.Width), float64(opts.Height)/float64(bounds.Height))
+			w, h = int(float64(bounds.Width)*ro.Scale), int(float64(bounds.Height)*ro.Scale)
+		}
+		var buf bytes.Buffer
+		if err := RenderSVG(&buf, p.Model, ro); err != nil {
+			return nil, fmt.Errorf("graw: ContactSheet: page %q: %v", p.Name, err)
+		}
+
+		c := NewImage(taken.unique("thumb-"+p.ID), rootCellID, "data:image/svg+xml,"+base64.StdEncoding.EncodeToString(buf.Bytes()))
+		c.Value = html.EscapeString(p.Name)
+		c.Style.set("html", "1")
+		c.Style.set("imageBorder", "#cccccc")
+		c.Style.set("verticalLabelPosition", "bottom")
+		c.Style.set("verticalAlign", "top")
+		col, row := n%opts.Columns, n/opts.Columns
+		c.Geometry.X = 10 + col*(opts.Width+groupGap) + (opts.Width-w)/2
+		c.Geometry.Y = 10 + row*(opts.Height+labelHeight+groupGap) + (opts.Height-h)/2
+		c.Geometry.setSize(maxInt(w, 1), maxInt(h, 1))
+		c.SetPageLink(p)
+		g.Add(c)
+		n++
+	}
+	return &g, nil
+}
@@ -0,0 +1,120 @@
+package graw
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestContactSheet(t *testing.T) {
+	wide := NewGraph()
+	wide.Add(NewShape("a", "1"))
+	tall := NewGraph()
+	s := NewShape("a", "1")
+	s.Geometry.setSize(60, 300)
+	tall.Add(s)
+	empty := NewGraph()
+
+	d := NewDocument()
+	d.AddPage("R&D", &wide)
+	d.AddPage("No model", nil)
+	d.AddPage("Tall", &tall)
+	d.AddPage("Empty", &empty)
+
+	g, err := ContactSheet(d, ContactSheetOptions{Columns: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id    string
+		label string
+		link  string
+		want  Rect
+		image string
+	}{
+		{"thumb-page-1", "R&amp;D", "data:page/id,page-1", Rect{10, 35, 200, 100}, `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100" viewBox="10 10 120 60">`},
+		{"thumb-page-3", "Tall", "data:page/id,page-3", Rect{335, 10, 30, 150}, `<svg xmlns="http://www.w3.org/2000/svg" width="30" height="150" viewBox="10 10 60 300">`},
+		{"thumb-page-4", "Empty", "data:page/id,page-4", Rect{10, 230, 200, 150}, `<svg xmlns="http://www.w3.org/2000/svg" width="0" height="0" viewBox="0 0 0 0">`},
+	}
+	if n := len(g.Root) - 2; n != len(tests) {
+		t.Errorf("got %d thumbnails, want %d", n, len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			c := g.FindByID(tt.id)
+			if c == nil {
+				t.Fatal("missing")
+			}
+			if c.Value != tt.label || c.Link() != tt.link {
+				t.Errorf("got label %q and link %q, want %q and %q", c.Value, c.Link(), tt.label, tt.link)
+			}
+			if got := GeometryRect(c.Geometry); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			const prefix = "data:image/svg+xml,"
+			img := c.Style.Attributes["image"]
+			if !strings.HasPrefix(img, prefix) {
+				t.Fatalf("got image %.40q, want an SVG data URI", img)
+			}
+			svg, err := base64.StdEncoding.DecodeString(img[len(prefix):])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(svg), tt.image) {
+				t.Errorf("got image\n%s\nwant prefix\n%s", svg, tt.image)
+			}
+		})
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("got style issues %v", iss)
+	}
+}
+
+func TestContactSheetOptions(t *testing.T) {
+	d := NewDocument()
+	for i := 0; i < 5; i++ {
+		g := NewGraph()
+		g.Add(NewShape("a", "1"))
+		d.AddPage("", &g)
+	}
+	tests := []struct {
+		name string
+		opts ContactSheetOptions
+		// last is the position of the thumbnail of the last page.
+		last Rect
+	}{
+		{"defaults", ContactSheetOptions{}, Rect{10, 255, 200, 100}},
+		{"columns", ContactSheetOptions{Columns: 5}, Rect{970, 35, 200, 100}},
+		{"size", ContactSheetOptions{Columns: 2, Width: 60, Height: 60}, Rect{10, 285, 60, 30}},
+		{"size without height", ContactSheetOptions{Width: 60}, Rect{10, 255, 200, 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ContactSheet(d, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := g.FindByID("thumb-page-5")
+			if c == nil {
+				t.Fatal("missing the last thumbnail")
+			}
+			if got := GeometryRect(c.Geometry); got != tt.last {
+				t.Errorf("got %v, want %v", got, tt.last)
+			}
+			if c.Value != "Page-5" {
+				t.Errorf("got label %q", c.Value)
+			}
+		})
+	}
+}
+
+func TestRenderSVGLabelBelow(t *testing.T) {
+	g := renderGraph(map[string]string{"verticalLabelPosition": "bottom", "verticalAlign": "top"}, nil, 0, 0, nil)
+	g.FindByID("a").Value = "Name"
+	out := renderString(t, g, SVGOptions{})
+	for _, s := range []string{`width="100" height="73" viewBox="0 0 100 73">`, `<text x="50" y="54" `} {
+		if !strings.Contains(out, s) {
+			t.Errorf("got\n%s\nwant it to contain\n%s", out, s)
+		}
+	}
+}
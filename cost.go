@@ -0,0 +1,223 @@
+package graw
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// costPanelRow is the height of the rows of the top cost panel.
+const costPanelRow = 24
+
+// costColumns maps the lower case headers of billing export CSV files
+// of AWS, Google Cloud and Azure to the columns they hold.
+var costColumns = map[string]string{
+	"resource":                 "resource",
+	"resource id":              "resource",
+	"resource_id":              "resource",
+	"resourceid":               "resource",
+	"lineitem/resourceid":      "resource",
+	"line_item_resource_id":    "resource",
+	"resource.name":            "resource",
+	"resource.global_name":     "resource",
+	"resource_name":            "resource",
+	"instanceid":               "resource",
+	"cost":                     "cost",
+	"lineitem/unblendedcost":   "cost",
+	"line_item_unblended_cost": "cost",
+	"costinbillingcurrency":    "cost",
+	"pretaxcost":               "cost",
+	"cost_in_billing_currency": "cost",
+	"monthly cost":             "cost",
+	"monthly_cost":             "cost",
+}
+
+// ReadCosts returns the cost of the resources of a billing export CSV
+// file whose first row names the columns: AWS cost and usage reports,
+// Google Cloud and Azure cost exports, or any file with resource ID
+// and cost columns. The costs of the rows of a resource are summed;
+// rows without resource are ignored.
+func ReadCosts(r io.Reader) (map[string]float64, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("graw: ReadCosts: %v", err)
+	}
+	resource, cost := -1, -1
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		switch costColumns[h] {
+		case "resource":
+			if resource < 0 {
+				resource = i
+			}
+		case "cost":
+			if cost < 0 {
+				cost = i
+			}
+		}
+	}
+	if resource < 0 || cost < 0 {
+		return nil, fmt.Errorf("graw: ReadCosts: no resource and cost columns")
+	}
+
+	costs := make(map[string]float64)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graw: ReadCosts: %v", err)
+		}
+		if resource >= len(rec) || cost >= len(rec) || strings.TrimSpace(rec[resource]) == "" {
+			continue
+		}
+		v := strings.TrimSpace(rec[cost])
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("graw: ReadCosts: line %d: bad cost %q", line, v)
+		}
+		costs[strings.TrimSpace(rec[resource])] += f
+	}
+	return costs, nil
+}
+
+// CostOptions controls OverlayCosts.
+type CostOptions struct {
+	// Key returns the resource ID of a vertex, the ID of the vertex
+	// when nil.
+	Key func(c *Cell) string
+	// Currency prefixes the costs, "$" if empty.
+	Currency string
+	// Scale grows the vertices by their share of the highest cost, up
+	// to twice their size; they keep their center.
+	Scale bool
+	// Top is the number of vertices listed in the top cost panel, 5 if
+	// zero; negative values leave the panel out.
+	Top int
+}
+
+// OverlayCosts joins costs, the monthly cost of resources as read by
+// ReadCosts, onto the vertices of g: every vertex with a cost has it
+// appended to its label and, with opts.Scale, is sized by it. A panel
+// right of the diagram lists the costliest vertices and the total.
+// OverlayCosts returns the IDs of the vertices with a cost, the
+// costliest first.
+func OverlayCosts(g *GraphModel, costs map[string]float64, opts CostOptions) []string {
+	if opts.Currency == "" {
+		opts.Currency = "$"
+	}
+	if opts.Top == 0 {
+		opts.Top = 5
+	}
+	type costed struct {
+		id, label string
+		cost      float64
+	}
+	var items []costed
+	max, total := 0.0, 0.0
+	for i := range g.Root {
+		c := g.Root[i]
+		if !c.isVertex() {
+			continue
+		}
+		key := c.ID
+		if opts.Key != nil {
+			key = opts.Key(c)
+		}
+		cost, ok := costs[key]
+		if !ok {
+			continue
+		}
+		label := labelText(c)
+		if label == "" {
+			label = key
+		}
+		items = append(items, costed{c.ID, label, cost})
+		max, total = math.Max(max, cost), total+cost
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].cost > items[j].cost })
+
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.id
+		c := g.cell(it.id)
+		if c.Value != "" {
+			c.Value += "<br>"
+		}
+		c.Value += "<b>" + html.EscapeString(formatCost(it.cost, opts.Currency)) + "/mo</b>"
+		c.Style.set("html", "1")
+		if opts.Scale && max > 0 && c.Geometry != nil {
+			f := 1 + math.Sqrt(math.Max(it.cost, 0)/max)
+			w, h := c.Geometry.size()
+			nw, nh := int(float64(w)*f), int(float64(h)*f)
+			c.Geometry.X -= (nw - w) / 2
+			c.Geometry.Y -= (nh - h) / 2
+			c.Geometry.setSize(nw, nh)
+		}
+	}
+	if len(items) == 0 || opts.Top < 0 {
+		return ids
+	}
+
+	layer, right, top := g.rightOfDiagram()
+	taken := g.idSet()
+	n := minInt(opts.Top, len(items))
+	panel := newContainer(taken.unique("top-costs"), layer, "Top costs")
+	panel.Style.set("collapsible", "0")
+	panel.Style.set("fillColor", "#f8cecc")
+	panel.Style.set("swimlaneFillColor", "#ffffff")
+	panel.Geometry.X, panel.Geometry.Y = right+groupGap, top
+	rows := make([]string, n+1)
+	width := 0
+	for i := 0; i < n; i++ {
+		rows[i] = strconv.Itoa(i+1) + ". " + items[i].label + ": " + formatCost(items[i].cost, opts.Currency)
+	}
+	rows[n] = "Total: " + formatCost(total, opts.Currency)
+	for _, r := range rows {
+		w, _ := DefaultMeasurer.Measure(r, Font{Size: defaultFontSize})
+		width = maxInt(width, int(w))
+	}
+	panel.Geometry.setSize(width+2*groupPadding, groupHeader+len(rows)*costPanelRow+groupPadding)
+	g.Add(panel)
+	for i, r := range rows {
+		c := NewShape(taken.unique(panel.ID+"-"+strconv.Itoa(i+1)), panel.ID)
+		c.Value = html.EscapeString(r)
+		if i == n {
+			c.Value = "<b>" + c.Value + "</b>"
+		}
+		c.Style = Style{Attributes: shapeStyle("text")}
+		c.Style.set("align", "left")
+		c.Geometry.X, c.Geometry.Y = groupPadding/2, groupHeader+groupPadding/2+i*costPanelRow
+		c.Geometry.setSize(width+groupPadding, costPanelRow)
+		g.Add(c)
+	}
+	return ids
+}
+
+// formatCost formats the amount v in currency, with cents below 100
+// and thousands separators above, e.g. "$12.50" or "$1,234".
+func formatCost(v float64, currency string) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if v < 100 {
+		return sign + currency + strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	s := strconv.FormatFloat(math.Round(v), 'f', 0, 64)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return sign + currency + s
+}
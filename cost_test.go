@@ -0,0 +1,181 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCosts(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]float64
+	}{
+		{
+			name: "aws",
+			in: `identity/LineItemId,lineItem/ResourceId,lineItem/UnblendedCost
+1,i-123,1.5
+2,i-123,2.25
+3,,9
+4,vol-9,0.5
+`,
+			want: map[string]float64{"i-123": 3.75, "vol-9": 0.5},
+		},
+		{
+			name: "gcp",
+			in:   "service,resource.name,resource.global_name,cost\ncompute,vm-1,//compute/vm-1,10\ncompute,vm-2,,\n",
+			want: map[string]float64{"vm-1": 10},
+		},
+		{
+			name: "azure",
+			in:   "\ufeffResourceId,CostInBillingCurrency\n/subscriptions/x/vm,12.5\nshort\n",
+			want: map[string]float64{"/subscriptions/x/vm": 12.5},
+		},
+		{
+			name: "plain",
+			in:   "Resource, Monthly Cost\n db , 100 \n",
+			want: map[string]float64{"db": 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCosts(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCostsErrors(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"empty", "", "graw: ReadCosts: EOF"},
+		{"no cost column", "resource,amount\na,1\n", "graw: ReadCosts: no resource and cost columns"},
+		{"bad cost", "resource,cost\na,1\nb,x\n", `graw: ReadCosts: line 3: bad cost "x"`},
+		{"quotes", "resource,cost\n\"a,1\n", "graw: ReadCosts: "},
+	}
+	for _, tt := range tests {
+		_, err := ReadCosts(strings.NewReader(tt.in))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "$0.00"},
+		{12.5, "$12.50"},
+		{99.99, "$99.99"},
+		{100, "$100"},
+		{1234.4, "$1,234"},
+		{1234567, "$1,234,567"},
+		{-1500, "-$1,500"},
+		{-3.2, "-$3.20"},
+	}
+	for _, tt := range tests {
+		if got := formatCost(tt.v, "$"); got != tt.want {
+			t.Errorf("formatCost(%v): got %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestOverlayCosts(t *testing.T) {
+	costs := map[string]float64{"a": 400, "b": 100, "c": 1500, "d": 25}
+	tests := []struct {
+		name   string
+		opts   CostOptions
+		ids    []string
+		labels map[string]string
+		panel  []string
+		sizes  map[string][4]int
+	}{
+		{
+			name: "defaults",
+			ids:  []string{"c", "a", "b"},
+			labels: map[string]string{
+				"a": "A<br><b>$400/mo</b>",
+				"c": "<b>$1,500/mo</b>",
+				"x": "X",
+			},
+			panel: []string{"1. c: $1,500", "2. A: $400", "3. b: $100", "<b>Total: $2,000</b>"},
+			sizes: map[string][4]int{"a": {10, 10, 120, 60}},
+		},
+		{
+			name:  "top and currency",
+			opts:  CostOptions{Top: 1, Currency: "€"},
+			ids:   []string{"c", "a", "b"},
+			panel: []string{"1. c: €1,500", "<b>Total: €2,000</b>"},
+		},
+		{
+			name:   "no panel",
+			opts:   CostOptions{Top: -1},
+			ids:    []string{"c", "a", "b"},
+			labels: map[string]string{"b": "<b>$100/mo</b>"},
+		},
+		{
+			name: "key",
+			opts: CostOptions{Key: func(c *Cell) string { return map[string]string{"x": "d"}[c.ID] }},
+			ids:  []string{"x"},
+			labels: map[string]string{
+				"x": "X<br><b>$25.00/mo</b>",
+				"a": "A",
+			},
+			panel: []string{"1. X: $25.00", "<b>Total: $25.00</b>"},
+		},
+		{
+			name: "scale",
+			opts: CostOptions{Scale: true, Top: -1},
+			ids:  []string{"c", "a", "b"},
+			sizes: map[string][4]int{
+				"c": {-50, -20, 240, 120},
+				"a": {-20, -5, 181, 90},
+				"b": {-5, 3, 150, 75},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraph([2]string{"a", "b"}, [2]string{"c", "x"})
+			g.FindByID("a").Value = "A"
+			g.FindByID("x").Value = "X"
+			ids := OverlayCosts(&g, costs, tt.opts)
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("got %q, want %q", ids, tt.ids)
+			}
+			for id, want := range tt.labels {
+				if got := g.FindByID(id).Value; got != want {
+					t.Errorf("%s: got label %q, want %q", id, got, want)
+				}
+			}
+			var panel []string
+			for _, c := range g.Root {
+				if c.ParentID == "top-costs" {
+					panel = append(panel, c.Value)
+				}
+			}
+			if !reflect.DeepEqual(panel, tt.panel) {
+				t.Errorf("got panel %q, want %q", panel, tt.panel)
+			}
+			for id, want := range tt.sizes {
+				c := g.FindByID(id)
+				w, h := c.Geometry.size()
+				if got := [4]int{c.Geometry.X, c.Geometry.Y, w, h}; got != want {
+					t.Errorf("%s: got geometry %v, want %v", id, got, want)
+				}
+			}
+			if iss := ValidateStyles(&g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
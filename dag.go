@@ -0,0 +1,117 @@
+package graw
+
+import (
+	"html"
+	"strings"
+)
+
+// dagTask is a task of a workflow DAG.
+type dagTask struct {
+	id, name string
+	upstream []string
+	tooltip  []string
+}
+
+// dagStateColors are the fill colors of tasks by state, covering the
+// states of Airflow task instances and the phases of Argo nodes.
+var dagStateColors = map[string]string{
+	"success":           "#d5e8d4",
+	"succeeded":         "#d5e8d4",
+	"running":           "#dae8fc",
+	"queued":            "#fff2cc",
+	"scheduled":         "#fff2cc",
+	"pending":           "#fff2cc",
+	"deferred":          "#fff2cc",
+	"up_for_retry":      "#ffe6cc",
+	"up_for_reschedule": "#ffe6cc",
+	"restarting":        "#ffe6cc",
+	"failed":            "#f8cecc",
+	"error":             "#f8cecc",
+	"upstream_failed":   "#f8cecc",
+	"skipped":           "#f5f5f5",
+	"omitted":           "#f5f5f5",
+	"removed":           "#f5f5f5",
+}
+
+// newDAGDiagram returns the diagram of the workflow DAG of tasks: a
+// box per task in columns by the length of their chain of upstream
+// tasks, connected to the tasks depending on them. Tasks with a state
+// in status are colored by it. ErrCycle is returned when tasks depend
+// on each other.
+func newDAGDiagram(tasks []*dagTask, status map[string]string) (*GraphModel, error) {
+	byID := make(map[string]*dagTask, len(tasks))
+	for _, t := range tasks {
+		byID[t.id] = t
+	}
+	level := make(map[string]int)
+	visiting := make(map[string]bool)
+	var visit func(t *dagTask) (int, error)
+	visit = func(t *dagTask) (int, error) {
+		if l, ok := level[t.id]; ok {
+			return l, nil
+		}
+		if visiting[t.id] {
+			return 0, ErrCycle
+		}
+		visiting[t.id] = true
+		l := 0
+		for _, u := range t.upstream {
+			if up, ok := byID[u]; ok {
+				ul, err := visit(up)
+				if err != nil {
+					return 0, err
+				}
+				l = maxInt(l, ul+1)
+			}
+		}
+		level[t.id] = l
+		return l, nil
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	var columns [][]*Cell
+	ids := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		l, err := visit(t)
+		if err != nil {
+			return nil, err
+		}
+		for len(columns) <= l {
+			columns = append(columns, nil)
+		}
+		c := NewShape(taken.unique("task-"+t.id), rootCellID)
+		c.Value = html.EscapeString(t.name)
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		tooltip := t.tooltip
+		if state := strings.ToLower(status[t.id]); state != "" {
+			if color, ok := dagStateColors[state]; ok {
+				c.Style.set("fillColor", color)
+			}
+			tooltip = append([]string{"state: " + state}, tooltip...)
+		}
+		if len(tooltip) > 0 {
+			c.Data = map[string]string{"tooltip": strings.Join(tooltip, "\n")}
+		}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		ids[t.id] = c.ID
+		columns[l] = append(columns[l], c)
+	}
+	layoutColumns(columns, 10, 10, 80)
+	for _, column := range columns {
+		for _, c := range column {
+			g.Add(c)
+		}
+	}
+	for _, t := range tasks {
+		for _, u := range t.upstream {
+			from, ok := ids[u]
+			if !ok {
+				continue
+			}
+			to := ids[t.id]
+			g.Add(newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to))
+		}
+	}
+	return &g, nil
+}
@@ -0,0 +1,100 @@
+package graw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// FromAirflow returns a diagram of an Airflow DAG read from the JSON
+// the Airflow REST API returns for its tasks, GET
+// /api/v1/dags/{dag_id}/tasks, or from a bare list of those tasks.
+//
+// Every task becomes a box, laid out in columns by the length of its
+// chain of upstream tasks and connected to its downstream tasks; its
+// operator, owner, trigger rule and retries are shown in the tooltip.
+// status maps task IDs to their state, e.g. from AirflowStatus, and
+// colors the tasks; it may be nil. ErrCycle is returned when tasks
+// depend on each other.
+func FromAirflow(r io.Reader, status map[string]string) (*GraphModel, error) {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("graw: FromAirflow: %v", err)
+	}
+	list := jsonArray(doc)
+	if list == nil {
+		list = jsonArray(jsonObject(doc)["tasks"])
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("graw: FromAirflow: no tasks")
+	}
+
+	var tasks []*dagTask
+	byID := make(map[string]*dagTask)
+	downstream := make(map[string][]string)
+	for _, v := range list {
+		spec := jsonObject(v)
+		t := &dagTask{id: jsonString(spec, "task_id")}
+		if t.id == "" {
+			return nil, fmt.Errorf("graw: FromAirflow: task without task_id")
+		}
+		if _, ok := byID[t.id]; ok {
+			return nil, fmt.Errorf("graw: FromAirflow: duplicate task %q", t.id)
+		}
+		t.name = t.id
+		if name := jsonString(spec, "task_display_name"); name != "" {
+			t.name = name
+		}
+		operator := jsonString(spec, "operator_name")
+		if operator == "" {
+			operator = jsonString(jsonObject(spec["class_ref"]), "class_name")
+		}
+		if operator != "" {
+			t.tooltip = append(t.tooltip, "operator: "+operator)
+		}
+		if owner := jsonString(spec, "owner"); owner != "" {
+			t.tooltip = append(t.tooltip, "owner: "+owner)
+		}
+		if rule := jsonString(spec, "trigger_rule"); rule != "" && rule != "all_success" {
+			t.tooltip = append(t.tooltip, "trigger rule: "+rule)
+		}
+		if retries, ok := spec["retries"].(float64); ok && retries > 0 {
+			t.tooltip = append(t.tooltip, "retries: "+strconv.Itoa(int(retries)))
+		}
+		downstream[t.id] = jsonStrings(spec["downstream_task_ids"])
+		byID[t.id] = t
+		tasks = append(tasks, t)
+	}
+	for _, t := range tasks {
+		for _, d := range downstream[t.id] {
+			if dt, ok := byID[d]; ok {
+				dt.upstream = append(dt.upstream, t.id)
+			}
+		}
+	}
+	return newDAGDiagram(tasks, status)
+}
+
+// AirflowStatus returns the states of the task instances of a DAG run
+// by task ID, read from the JSON the Airflow REST API returns for
+// them, GET /api/v1/dags/{dag_id}/dagRuns/{dag_run_id}/taskInstances.
+// Mapped tasks take the state of their last instance.
+func AirflowStatus(r io.Reader) (map[string]string, error) {
+	var doc interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("graw: AirflowStatus: %v", err)
+	}
+	list := jsonArray(doc)
+	if list == nil {
+		list = jsonArray(jsonObject(doc)["task_instances"])
+	}
+	status := make(map[string]string, len(list))
+	for _, v := range list {
+		ti := jsonObject(v)
+		if id := jsonString(ti, "task_id"); id != "" {
+			status[id] = jsonString(ti, "state")
+		}
+	}
+	return status, nil
+}
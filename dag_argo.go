@@ -0,0 +1,133 @@
+package graw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// FromArgo returns a diagram of an Argo Workflow read from its YAML
+// manifest, of kind Workflow, WorkflowTemplate or CronWorkflow.
+//
+// The tasks of the DAG or the steps of the entrypoint template become
+// boxes, laid out in columns and connected to the tasks depending on
+// them; steps depend on all the steps of the previous group. The
+// template a task runs is shown in its tooltip. status maps task names
+// to their phase and colors the tasks; when nil, the phases of the
+// nodes recorded in the status of the manifest, as kubectl get -o yaml
+// prints it, are used. ErrCycle is returned when tasks depend on each
+// other.
+func FromArgo(r io.Reader, status map[string]string) (*GraphModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := decodeYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("graw: FromArgo: empty document")
+	}
+	root := jsonObject(docs[0])
+	spec := jsonObject(root["spec"])
+	if wf := jsonObject(spec["workflowSpec"]); wf != nil {
+		// a CronWorkflow
+		spec = wf
+	}
+	entry := jsonString(spec, "entrypoint")
+	var template map[string]interface{}
+	for _, t := range jsonArray(spec["templates"]) {
+		if t := jsonObject(t); jsonString(t, "name") == entry || entry == "" && (t["dag"] != nil || t["steps"] != nil) {
+			template = t
+			break
+		}
+	}
+	if template == nil {
+		return nil, fmt.Errorf("graw: FromArgo: no entrypoint template %q", entry)
+	}
+
+	var tasks []*dagTask
+	if dag := jsonObject(template["dag"]); dag != nil {
+		for _, v := range jsonArray(dag["tasks"]) {
+			spec := jsonObject(v)
+			t := &dagTask{id: jsonString(spec, "name")}
+			t.name = t.id
+			t.upstream = jsonStrings(spec["dependencies"])
+			if depends := jsonString(spec, "depends"); depends != "" {
+				t.upstream = append(t.upstream, argoDepends(depends)...)
+				t.tooltip = append(t.tooltip, "depends: "+depends)
+			}
+			tasks = append(tasks, argoTask(t, spec))
+		}
+	} else {
+		var previous []string
+		for _, group := range jsonArray(template["steps"]) {
+			var names []string
+			for _, v := range jsonArray(group) {
+				spec := jsonObject(v)
+				t := &dagTask{id: jsonString(spec, "name"), upstream: previous}
+				t.name = t.id
+				tasks = append(tasks, argoTask(t, spec))
+				names = append(names, t.id)
+			}
+			previous = names
+		}
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("graw: FromArgo: template %q has no tasks", jsonString(template, "name"))
+	}
+	seen := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		if t.id == "" {
+			return nil, fmt.Errorf("graw: FromArgo: task without name")
+		}
+		if seen[t.id] {
+			return nil, fmt.Errorf("graw: FromArgo: duplicate task %q", t.id)
+		}
+		seen[t.id] = true
+	}
+
+	if status == nil {
+		status = make(map[string]string)
+		nodes := jsonObject(jsonObject(root["status"])["nodes"])
+		for _, k := range jsonKeys(nodes) {
+			n := jsonObject(nodes[k])
+			if name := jsonString(n, "displayName"); seen[name] {
+				status[name] = jsonString(n, "phase")
+			}
+		}
+	}
+	return newDAGDiagram(tasks, status)
+}
+
+// argoTask completes t with the template and conditions of its spec.
+func argoTask(t *dagTask, spec map[string]interface{}) *dagTask {
+	if tmpl := jsonString(spec, "template"); tmpl != "" {
+		t.tooltip = append(t.tooltip, "template: "+tmpl)
+	} else if ref := jsonObject(spec["templateRef"]); ref != nil {
+		t.tooltip = append(t.tooltip, "template: "+jsonString(ref, "name")+"/"+jsonString(ref, "template"))
+	}
+	if when := jsonString(spec, "when"); when != "" {
+		t.tooltip = append(t.tooltip, "when: "+when)
+	}
+	return t
+}
+
+// argoDepends returns the names of the tasks an enhanced depends
+// expression such as "A && (B.Succeeded || C.Failed)" refers to.
+func argoDepends(expr string) []string {
+	var names []string
+	for _, tok := range strings.FieldsFunc(expr, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("&|!()", r)
+	}) {
+		if i := strings.IndexByte(tok, '.'); i >= 0 {
+			tok = tok[:i]
+		}
+		if tok != "" && !containsString(names, tok) {
+			names = append(names, tok)
+		}
+	}
+	return names
+}
@@ -0,0 +1,244 @@
+package graw
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const airflowTasks = `{"tasks": [
+  {"task_id": "extract", "owner": "data", "operator_name": "PythonOperator", "retries": 2,
+   "downstream_task_ids": ["transform", "audit"]},
+  {"task_id": "transform", "task_display_name": "Transform & load",
+   "class_ref": {"class_name": "BashOperator"}, "trigger_rule": "all_success",
+   "downstream_task_ids": ["report"]},
+  {"task_id": "audit", "trigger_rule": "all_done", "downstream_task_ids": ["report", "gone"]},
+  {"task_id": "report", "downstream_task_ids": []}
+], "total_entries": 4}`
+
+func TestFromAirflow(t *testing.T) {
+	status, err := AirflowStatus(strings.NewReader(`{"task_instances": [
+	  {"task_id": "extract", "state": "success"},
+	  {"task_id": "transform", "state": "FAILED"},
+	  {"task_id": "audit", "state": null}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		status   map[string]string
+		colors   map[string]string
+		tooltips map[string]string
+	}{
+		{
+			name: "tasks",
+			tooltips: map[string]string{
+				"task-extract":   "operator: PythonOperator\nowner: data\nretries: 2",
+				"task-transform": "operator: BashOperator",
+				"task-audit":     "trigger rule: all_done",
+				"task-report":    "",
+			},
+		},
+		{
+			name:   "status",
+			status: status,
+			colors: map[string]string{
+				"task-extract":   "#d5e8d4",
+				"task-transform": "#f8cecc",
+				"task-audit":     "",
+				"task-report":    "",
+			},
+			tooltips: map[string]string{
+				"task-extract":   "state: success\noperator: PythonOperator\nowner: data\nretries: 2",
+				"task-transform": "state: failed\noperator: BashOperator",
+				"task-audit":     "trigger rule: all_done",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromAirflow(strings.NewReader(airflowTasks), tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{"task-extract->task-transform", "task-extract->task-audit", "task-transform->task-report", "task-audit->task-report"}
+			if got := edgeList(g); !reflect.DeepEqual(got, want) {
+				t.Errorf("got edges %q, want %q", got, want)
+			}
+			if got := g.FindByID("task-transform").Value; got != "Transform &amp; load" {
+				t.Errorf("got label %q", got)
+			}
+			for id, want := range tt.tooltips {
+				if got := g.FindByID(id).Data["tooltip"]; got != want {
+					t.Errorf("%s: got tooltip %q, want %q", id, got, want)
+				}
+			}
+			for id, want := range tt.colors {
+				if got := g.FindByID(id).Style.Attributes["fillColor"]; got != want {
+					t.Errorf("%s: got fillColor %q, want %q", id, got, want)
+				}
+			}
+			// tasks are in columns by their chain of upstream tasks
+			x := func(id string) int { return g.FindByID(id).Geometry.X }
+			if !(x("task-extract") < x("task-transform") && x("task-transform") == x("task-audit") && x("task-audit") < x("task-report")) {
+				t.Errorf("got columns %d, %d, %d, %d", x("task-extract"), x("task-transform"), x("task-audit"), x("task-report"))
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestFromAirflowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"json", `{`, nil},
+		{"no tasks", `{"tasks": []}`, nil},
+		{"no task_id", `[{"owner": "a"}]`, nil},
+		{"duplicate", `[{"task_id": "a"}, {"task_id": "a"}]`, nil},
+		{"cycle", `[{"task_id": "a", "downstream_task_ids": ["b"]}, {"task_id": "b", "downstream_task_ids": ["a"]}]`, ErrCycle},
+	}
+	for _, tt := range tests {
+		_, err := FromAirflow(strings.NewReader(tt.in), nil)
+		if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestFromArgo(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		status   map[string]string
+		edges    []string
+		tooltips map[string]string
+		colors   map[string]string
+	}{
+		{
+			name: "dag",
+			in: `apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+spec:
+  entrypoint: main
+  templates:
+  - name: echo
+    container: {image: alpine}
+  - name: main
+    dag:
+      tasks:
+      - {name: A, template: echo}
+      - {name: B, template: echo, dependencies: [A]}
+      - name: C
+        templateRef: {name: lib, template: step}
+        depends: "A && (B.Succeeded || B.Failed)"
+      - {name: D, template: echo, dependencies: [C], when: "{{tasks.C.outputs.result}} == yes"}
+status:
+  nodes:
+    wf-1: {displayName: wf, phase: Running}
+    wf-2: {displayName: A, phase: Succeeded}
+    wf-3: {displayName: B, phase: Failed}
+`,
+			edges: []string{"task-A->task-B", "task-A->task-C", "task-B->task-C", "task-C->task-D"},
+			tooltips: map[string]string{
+				"task-A": "state: succeeded\ntemplate: echo",
+				"task-C": "depends: A && (B.Succeeded || B.Failed)\ntemplate: lib/step",
+				"task-D": "template: echo\nwhen: {{tasks.C.outputs.result}} == yes",
+			},
+			colors: map[string]string{"task-A": "#d5e8d4", "task-B": "#f8cecc", "task-C": ""},
+		},
+		{
+			name: "cron steps",
+			in: `kind: CronWorkflow
+spec:
+  workflowSpec:
+    entrypoint: main
+    templates:
+    - name: main
+      steps:
+      - - {name: fetch, template: t}
+      - - {name: left, template: t}
+        - {name: right, template: t}
+      - - {name: merge, template: t}
+status:
+  nodes:
+    x: {displayName: fetch, phase: Succeeded}
+`,
+			status: map[string]string{"left": "Running"},
+			edges:  []string{"task-fetch->task-left", "task-fetch->task-right", "task-left->task-merge", "task-right->task-merge"},
+			tooltips: map[string]string{
+				"task-fetch": "template: t",
+				"task-left":  "state: running\ntemplate: t",
+			},
+			colors: map[string]string{"task-fetch": "", "task-left": "#dae8fc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromArgo(strings.NewReader(tt.in), tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %q, want %q", got, tt.edges)
+			}
+			for id, want := range tt.tooltips {
+				if got := g.FindByID(id).Data["tooltip"]; got != want {
+					t.Errorf("%s: got tooltip %q, want %q", id, got, want)
+				}
+			}
+			for id, want := range tt.colors {
+				if got := g.FindByID(id).Style.Attributes["fillColor"]; got != want {
+					t.Errorf("%s: got fillColor %q, want %q", id, got, want)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestFromArgoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"empty", ``, nil},
+		{"no entrypoint", "spec:\n  entrypoint: main\n  templates:\n  - name: other\n", nil},
+		{"no tasks", "spec:\n  entrypoint: main\n  templates:\n  - name: main\n    dag: {tasks: []}\n", nil},
+		{"no name", "spec:\n  templates:\n  - name: main\n    dag:\n      tasks:\n      - {template: t}\n", nil},
+		{"duplicate", "spec:\n  templates:\n  - name: main\n    dag:\n      tasks:\n      - {name: a}\n      - {name: a}\n", nil},
+		{"cycle", "spec:\n  templates:\n  - name: main\n    dag:\n      tasks:\n      - {name: a, dependencies: [b]}\n      - {name: b, depends: a}\n", ErrCycle},
+	}
+	for _, tt := range tests {
+		_, err := FromArgo(strings.NewReader(tt.in), nil)
+		if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestArgoDepends(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"A", []string{"A"}},
+		{"A && B", []string{"A", "B"}},
+		{"A && (B.Succeeded || B.Failed) && !C.Skipped", []string{"A", "B", "C"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := argoDepends(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("argoDepends(%q): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,692 @@
+package graw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ddlToken is a token of an SQL script.
+type ddlToken struct {
+	text string
+	// quoted is set for quoted identifiers and str for string
+	// literals; neither is ever a keyword.
+	quoted, str bool
+	line        int
+}
+
+// is tells whether t is the keyword kw.
+func (t ddlToken) is(kw string) bool {
+	return !t.quoted && !t.str && strings.EqualFold(t.text, kw)
+}
+
+// FromDDL returns an ER diagram of the tables created by an SQL
+// script. It is a shorthand for NewERDiagram of the result of
+// ParseDDL.
+func FromDDL(r io.Reader) (*GraphModel, error) {
+	s, err := ParseDDL(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewERDiagram(s), nil
+}
+
+// ParseDDL reads the tables defined by an SQL script such as a
+// migration file or a schema dump. It understands the MySQL and
+// PostgreSQL forms of CREATE TABLE with column and table constraints,
+// CREATE INDEX and the ADD clauses of ALTER TABLE; other statements
+// are skipped. Schema qualifiers are dropped from table names.
+func ParseDDL(r io.Reader) (ERSchema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ERSchema{}, err
+	}
+	toks, err := lexDDL(string(data))
+	if err != nil {
+		return ERSchema{}, err
+	}
+
+	var s ERSchema
+	start := 0
+	for i := 0; i <= len(toks); i++ {
+		if i < len(toks) && !(toks[i].text == ";" && !toks[i].str && !toks[i].quoted) {
+			continue
+		}
+		if i > start {
+			p := &ddlParser{toks: toks[start:i], schema: &s}
+			if err := p.statement(); err != nil {
+				return ERSchema{}, err
+			}
+		}
+		start = i + 1
+	}
+	s.resolve()
+	return s, nil
+}
+
+// lexDDL splits an SQL script into tokens, dropping comments.
+func lexDDL(src string) ([]ddlToken, error) {
+	var toks []ddlToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(src[i:], "--") || c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("graw: ParseDDL: line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(src) {
+					return nil, fmt.Errorf("graw: ParseDDL: line %d: unterminated %c", line, c)
+				}
+				if src[j] == '\\' && c == '\'' && j+1 < len(src) {
+					b.WriteByte(src[j+1])
+					j += 2
+					continue
+				}
+				if src[j] == c {
+					// a doubled quote stands for itself
+					if j+1 < len(src) && src[j+1] == c {
+						b.WriteByte(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(src[j])
+				j++
+			}
+			toks = append(toks, ddlToken{text: b.String(), quoted: c != '\'', str: c == '\'', line: line})
+			line += strings.Count(src[i:j], "\n")
+			i = j + 1
+		case c == '$' && dollarTag(src[i:]) != "":
+			tag := dollarTag(src[i:])
+			end := strings.Index(src[i+len(tag):], tag)
+			if end < 0 {
+				return nil, fmt.Errorf("graw: ParseDDL: line %d: unterminated %s string", line, tag)
+			}
+			body := src[i+len(tag) : i+len(tag)+end]
+			toks = append(toks, ddlToken{text: body, str: true, line: line})
+			line += strings.Count(body, "\n")
+			i += 2*len(tag) + end
+		case isWordByte(c):
+			j := i
+			for j < len(src) && isWordByte(src[j]) {
+				j++
+			}
+			toks = append(toks, ddlToken{text: src[i:j], line: line})
+			i = j
+		default:
+			toks = append(toks, ddlToken{text: src[i : i+1], line: line})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// dollarTag returns the opening tag of a PostgreSQL dollar quoted
+// string at the start of s, such as $$ or $body$, or "".
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		if s[j] == '$' {
+			return s[:j+1]
+		}
+		if !isWordByte(s[j]) || j == 1 && s[j] >= '0' && s[j] <= '9' {
+			return ""
+		}
+	}
+	return ""
+}
+
+// isWordByte tells whether c may be part of an unquoted identifier,
+// keyword or number.
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '$' || c >= 0x80
+}
+
+// ddlParser parses a single statement.
+type ddlParser struct {
+	toks   []ddlToken
+	pos    int
+	schema *ERSchema
+}
+
+// done tells whether the statement is fully consumed.
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.toks)
+}
+
+// peek tells whether the next tokens are the given keywords.
+func (p *ddlParser) peek(kws ...string) bool {
+	for i, kw := range kws {
+		if p.pos+i >= len(p.toks) || !p.toks[p.pos+i].is(kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// accept consumes the given keywords if they come next.
+func (p *ddlParser) accept(kws ...string) bool {
+	if !p.peek(kws...) {
+		return false
+	}
+	p.pos += len(kws)
+	return true
+}
+
+// errorf returns a syntax error at the current token.
+func (p *ddlParser) errorf(format string, args ...interface{}) error {
+	line := 0
+	if p.done() && len(p.toks) > 0 {
+		line = p.toks[len(p.toks)-1].line
+	} else if !p.done() {
+		line = p.toks[p.pos].line
+	}
+	return fmt.Errorf("graw: ParseDDL: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// name consumes a possibly schema qualified name and returns its last
+// part.
+func (p *ddlParser) name() (string, error) {
+	if p.done() || isDDLPunct(p.toks[p.pos]) {
+		return "", p.errorf("want a name")
+	}
+	name := p.toks[p.pos].text
+	p.pos++
+	for p.pos+1 < len(p.toks) && p.toks[p.pos].text == "." && !isDDLPunct(p.toks[p.pos+1]) {
+		name = p.toks[p.pos+1].text
+		p.pos += 2
+	}
+	return name, nil
+}
+
+// group consumes a parenthesized list and returns its comma separated
+// elements.
+func (p *ddlParser) group() ([][]ddlToken, error) {
+	if p.done() || !isDDLSymbol(p.toks[p.pos], "(") {
+		return nil, p.errorf("want (")
+	}
+	var elems [][]ddlToken
+	depth := 0
+	start := p.pos + 1
+	for ; p.pos < len(p.toks); p.pos++ {
+		t := p.toks[p.pos]
+		switch {
+		case isDDLSymbol(t, "("):
+			depth++
+		case isDDLSymbol(t, ")"):
+			depth--
+			if depth == 0 {
+				if p.pos > start {
+					elems = append(elems, p.toks[start:p.pos])
+				}
+				p.pos++
+				return elems, nil
+			}
+		case isDDLSymbol(t, ",") && depth == 1:
+			elems = append(elems, p.toks[start:p.pos])
+			start = p.pos + 1
+		}
+	}
+	return nil, p.errorf("missing )")
+}
+
+// skip consumes the next token, or the whole parenthesized group it
+// opens.
+func (p *ddlParser) skip() {
+	if isDDLSymbol(p.toks[p.pos], "(") {
+		if _, err := p.group(); err == nil {
+			return
+		}
+		p.pos = len(p.toks)
+		return
+	}
+	p.pos++
+}
+
+// columnList consumes a parenthesized list of column names. Index
+// elements which are expressions are kept as written.
+func (p *ddlParser) columnList() ([]string, error) {
+	elems, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]string, 0, len(elems))
+	for _, e := range elems {
+		if len(e) == 0 {
+			return nil, p.errorf("empty column name")
+		}
+		if !isDDLPunct(e[0]) && (len(e) == 1 || e[1].is("ASC") || e[1].is("DESC") || e[1].is("COLLATE") || isPrefixLength(e[1:])) {
+			cols = append(cols, e[0].text)
+			continue
+		}
+		cols = append(cols, ddlText(e))
+	}
+	return cols, nil
+}
+
+// isPrefixLength tells whether toks start with the prefix length of
+// a MySQL index column, such as (10).
+func isPrefixLength(toks []ddlToken) bool {
+	return len(toks) >= 3 && isDDLSymbol(toks[0], "(") && isDDLSymbol(toks[2], ")") &&
+		strings.Trim(toks[1].text, "0123456789") == "" && !toks[1].quoted && !toks[1].str
+}
+
+// statement parses a statement, adding what it defines to the schema.
+func (p *ddlParser) statement() error {
+	switch {
+	case p.accept("CREATE"):
+		p.accept("OR", "REPLACE")
+		for p.accept("TEMPORARY") || p.accept("TEMP") || p.accept("UNLOGGED") || p.accept("GLOBAL") || p.accept("LOCAL") {
+		}
+		switch {
+		case p.accept("TABLE"):
+			return p.createTable()
+		case p.peek("UNIQUE") || p.peek("INDEX"):
+			return p.createIndex()
+		}
+	case p.accept("ALTER", "TABLE"):
+		return p.alterTable()
+	}
+	return nil
+}
+
+// createTable parses the rest of a CREATE TABLE statement.
+func (p *ddlParser) createTable() error {
+	p.accept("IF", "NOT", "EXISTS")
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.done() || !isDDLSymbol(p.toks[p.pos], "(") {
+		// CREATE TABLE ... AS SELECT and the like
+		return nil
+	}
+	elems, err := p.group()
+	if err != nil {
+		return err
+	}
+
+	p.schema.Tables = append(p.schema.Tables, Table{Name: name})
+	for _, e := range elems {
+		if err := p.definition(name, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// definition parses a column or constraint definition of the named
+// table.
+func (p *ddlParser) definition(table string, elem []ddlToken) error {
+	q := &ddlParser{toks: elem, schema: p.schema}
+	constraint := ""
+	if q.accept("CONSTRAINT") {
+		var err error
+		if constraint, err = q.name(); err != nil {
+			return err
+		}
+	}
+	t := p.schema.table(table)
+
+	switch {
+	case q.accept("PRIMARY", "KEY"):
+		cols, err := q.indexColumns()
+		if err != nil {
+			return err
+		}
+		if t != nil {
+			for i := range t.Columns {
+				for _, c := range cols {
+					if strings.EqualFold(t.Columns[i].Name, c) {
+						t.Columns[i].PrimaryKey = true
+					}
+				}
+			}
+		}
+	case q.peek("UNIQUE") || q.peek("FULLTEXT") || q.peek("SPATIAL") || q.isIndex():
+		unique := q.accept("UNIQUE")
+		q.accept("FULLTEXT")
+		q.accept("SPATIAL")
+		_ = q.accept("KEY") || q.accept("INDEX")
+		name := ""
+		if !q.done() && !isDDLSymbol(q.toks[q.pos], "(") {
+			name = q.toks[q.pos].text
+			q.pos++
+		}
+		cols, err := q.indexColumns()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = constraint
+		}
+		if name == "" {
+			name = table + "_" + strings.Join(cols, "_") + "_key"
+		}
+		if t != nil {
+			t.Indexes = append(t.Indexes, Index{Name: name, Columns: cols, Unique: unique})
+		}
+	case q.accept("FOREIGN", "KEY"):
+		if !q.done() && !isDDLSymbol(q.toks[q.pos], "(") {
+			q.pos++
+		}
+		cols, err := q.columnList()
+		if err != nil {
+			return err
+		}
+		return q.references(table, cols)
+	case q.peek("CHECK") || q.peek("EXCLUDE") || q.peek("LIKE") || q.peek("PERIOD"):
+	default:
+		return q.column(t)
+	}
+	return nil
+}
+
+// isIndex tells whether a MySQL KEY or INDEX definition comes next,
+// rather than a column of that name.
+func (p *ddlParser) isIndex() bool {
+	if !p.peek("KEY") && !p.peek("INDEX") {
+		return false
+	}
+	for i := p.pos + 1; i < len(p.toks) && i <= p.pos+2; i++ {
+		if isDDLSymbol(p.toks[i], "(") || p.toks[i].is("USING") {
+			return true
+		}
+	}
+	return false
+}
+
+// indexColumns consumes an optional USING clause and the column list
+// of an index.
+func (p *ddlParser) indexColumns() ([]string, error) {
+	if p.accept("USING") {
+		p.pos++
+	}
+	return p.columnList()
+}
+
+// references parses a REFERENCES clause for the columns cols of the
+// named table.
+func (p *ddlParser) references(table string, cols []string) error {
+	if !p.accept("REFERENCES") {
+		return p.errorf("want REFERENCES")
+	}
+	to, err := p.name()
+	if err != nil {
+		return err
+	}
+	var toCols []string
+	if !p.done() && isDDLSymbol(p.toks[p.pos], "(") {
+		if toCols, err = p.columnList(); err != nil {
+			return err
+		}
+	}
+	p.schema.Relations = append(p.schema.Relations, Relation{
+		FromTable: table, FromColumns: cols, ToTable: to, ToColumns: toCols,
+	})
+	return nil
+}
+
+// columnStops are the keywords ending the type of a column.
+var columnStops = []string{
+	"NOT", "NULL", "PRIMARY", "UNIQUE", "REFERENCES", "DEFAULT",
+	"AUTO_INCREMENT", "AUTOINCREMENT", "CHECK", "CONSTRAINT", "COMMENT",
+	"COLLATE", "GENERATED", "ON", "CHARSET", "KEY", "AS", "VIRTUAL", "STORED",
+}
+
+// column parses a column definition and adds it to t.
+func (p *ddlParser) column(t *Table) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	col := Column{Name: name}
+
+	start := p.pos
+typ:
+	for !p.done() {
+		if p.peek("CHARACTER", "SET") {
+			break
+		}
+		for _, kw := range columnStops {
+			if p.peek(kw) {
+				break typ
+			}
+		}
+		p.skip()
+	}
+	col.Type = ddlText(p.toks[start:p.pos])
+
+	for !p.done() {
+		switch {
+		case p.accept("NOT", "NULL"):
+			col.NotNull = true
+		case p.accept("PRIMARY", "KEY"):
+			col.PrimaryKey = true
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			col.Unique = true
+		case p.peek("REFERENCES"):
+			if t == nil {
+				return p.errorf("column %s outside of a table", name)
+			}
+			if err := p.references(t.Name, []string{name}); err != nil {
+				return err
+			}
+		case p.accept("DEFAULT"), p.accept("COMMENT"), p.accept("COLLATE"):
+			if !p.done() {
+				p.skip()
+			}
+		default:
+			p.skip()
+		}
+	}
+	if t != nil {
+		t.Columns = append(t.Columns, col)
+	}
+	return nil
+}
+
+// createIndex parses the rest of a CREATE INDEX statement.
+func (p *ddlParser) createIndex() error {
+	unique := p.accept("UNIQUE")
+	p.accept("INDEX")
+	p.accept("CONCURRENTLY")
+	p.accept("IF", "NOT", "EXISTS")
+	name := ""
+	if !p.peek("ON") {
+		var err error
+		if name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if !p.accept("ON") {
+		return p.errorf("want ON")
+	}
+	p.accept("ONLY")
+	table, err := p.name()
+	if err != nil {
+		return err
+	}
+	cols, err := p.indexColumns()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = table + "_" + strings.Join(cols, "_") + "_idx"
+	}
+	if t := p.schema.tableFold(table); t != nil {
+		t.Indexes = append(t.Indexes, Index{Name: name, Columns: cols, Unique: unique})
+	}
+	return nil
+}
+
+// alterTable parses the rest of an ALTER TABLE statement. Only the
+// ADD clauses are read.
+func (p *ddlParser) alterTable() error {
+	p.accept("IF", "EXISTS")
+	p.accept("ONLY")
+	table, err := p.name()
+	if err != nil {
+		return err
+	}
+	if t := p.schema.tableFold(table); t != nil {
+		table = t.Name
+	}
+
+	depth := 0
+	start := p.pos
+	for i := p.pos; i <= len(p.toks); i++ {
+		if i < len(p.toks) {
+			t := p.toks[i]
+			switch {
+			case isDDLSymbol(t, "("):
+				depth++
+			case isDDLSymbol(t, ")"):
+				depth--
+			}
+			if !isDDLSymbol(t, ",") || depth > 0 {
+				continue
+			}
+		}
+		action := &ddlParser{toks: p.toks[start:i], schema: p.schema}
+		start = i + 1
+		if !action.accept("ADD") {
+			continue
+		}
+		action.accept("COLUMN")
+		action.accept("IF", "NOT", "EXISTS")
+		if err := p.definition(table, action.toks[action.pos:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tableFold finds the table with the given name, ignoring case.
+func (s *ERSchema) tableFold(name string) *Table {
+	for i := range s.Tables {
+		if strings.EqualFold(s.Tables[i].Name, name) {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// resolve matches the table and column names of the relations with
+// the definitions, which may differ in case, fills in the primary key
+// of tables referenced without a column list and marks the relations
+// whose columns are unique as one to one.
+func (s *ERSchema) resolve() {
+	fold := func(t *Table, cols []string) {
+		for i, c := range cols {
+			for _, col := range t.Columns {
+				if strings.EqualFold(col.Name, c) {
+					cols[i] = col.Name
+				}
+			}
+		}
+	}
+
+	for i := range s.Relations {
+		r := &s.Relations[i]
+		if t := s.tableFold(r.ToTable); t != nil {
+			r.ToTable = t.Name
+			if len(r.ToColumns) == 0 {
+				for _, c := range t.Columns {
+					if c.PrimaryKey {
+						r.ToColumns = append(r.ToColumns, c.Name)
+					}
+				}
+			}
+			fold(t, r.ToColumns)
+		}
+
+		t := s.tableFold(r.FromTable)
+		if t == nil {
+			continue
+		}
+		r.FromTable = t.Name
+		fold(t, r.FromColumns)
+
+		var pk []string
+		for _, c := range t.Columns {
+			if c.PrimaryKey {
+				pk = append(pk, c.Name)
+			}
+			if len(r.FromColumns) == 1 && c.Name == r.FromColumns[0] && c.Unique {
+				r.OneToOne = true
+			}
+		}
+		if sameColumns(pk, r.FromColumns) {
+			r.OneToOne = true
+		}
+		for _, idx := range t.Indexes {
+			if idx.Unique && sameColumns(idx.Columns, r.FromColumns) {
+				r.OneToOne = true
+			}
+		}
+	}
+}
+
+// sameColumns tells whether a and b hold the same column names.
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) || len(a) == 0 {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, c := range a {
+		set[strings.ToLower(c)] = true
+	}
+	for _, c := range b {
+		if !set[strings.ToLower(c)] {
+			return false
+		}
+	}
+	return true
+}
+
+// isDDLPunct tells whether t is a punctuation character.
+func isDDLPunct(t ddlToken) bool {
+	return !t.quoted && !t.str && len(t.text) == 1 && !isWordByte(t.text[0])
+}
+
+// isDDLSymbol tells whether t is the punctuation character sym.
+func isDDLSymbol(t ddlToken, sym string) bool {
+	return isDDLPunct(t) && t.text == sym
+}
+
+// ddlText renders tokens back to SQL, e.g. for column types.
+func ddlText(toks []ddlToken) string {
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 && !isDDLPunct(t) && (!isDDLPunct(toks[i-1]) || isDDLSymbol(toks[i-1], ",")) {
+			b.WriteByte(' ')
+		}
+		switch {
+		case t.str:
+			b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+		default:
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
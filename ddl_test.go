@@ -0,0 +1,122 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDDL(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		sql  string
+		want ERSchema
+	}{
+		{
+			name: "mysql",
+			sql: "CREATE TABLE IF NOT EXISTS `users` (\n" +
+				"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+				"  `email` varchar(255) CHARACTER SET utf8mb4 NOT NULL DEFAULT '',\n" +
+				"  name varchar(100) COMMENT 'it''s; fine',\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  UNIQUE KEY uk_email (email(10)),\n" +
+				"  KEY idx_name (name DESC)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+			want: ERSchema{Tables: []Table{{Name: "users", Columns: []Column{
+				{Name: "id", Type: "bigint unsigned", PrimaryKey: true, NotNull: true},
+				{Name: "email", Type: "varchar(255)", NotNull: true},
+				{Name: "name", Type: "varchar(100)"},
+			}, Indexes: []Index{
+				{Name: "uk_email", Columns: []string{"email"}, Unique: true},
+				{Name: "idx_name", Columns: []string{"name"}},
+			}}}},
+		},
+		{
+			name: "postgres",
+			sql: `/* comment */ CREATE TABLE public.users (id serial PRIMARY KEY);
+CREATE TABLE profiles (
+  user_id bigint PRIMARY KEY REFERENCES Users,
+  tags text[],
+  price numeric(10, 2) CHECK (price > 0)
+);
+CREATE FUNCTION f() RETURNS trigger AS $$ BEGIN; END; $$ LANGUAGE plpgsql;`,
+			want: ERSchema{
+				Tables: []Table{
+					{Name: "users", Columns: []Column{{Name: "id", Type: "serial", PrimaryKey: true}}},
+					{Name: "profiles", Columns: []Column{
+						{Name: "user_id", Type: "bigint", PrimaryKey: true},
+						{Name: "tags", Type: "text[]"},
+						{Name: "price", Type: "numeric(10, 2)"},
+					}},
+				},
+				Relations: []Relation{{FromTable: "profiles", FromColumns: []string{"user_id"}, ToTable: "users", ToColumns: []string{"id"}, OneToOne: true}},
+			},
+		},
+		{
+			name: "constraints and alter",
+			sql: `CREATE TABLE users (id int PRIMARY KEY);
+CREATE TABLE orders (
+  id int,
+  user_id int NOT NULL,
+  CONSTRAINT pk PRIMARY KEY (id),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX CONCURRENTLY orders_user ON public.orders USING btree (user_id, id);
+ALTER TABLE ONLY public.orders ADD COLUMN note text, ADD CONSTRAINT uk UNIQUE (note), ADD UNIQUE (user_id);
+INSERT INTO users VALUES (1), ('a;b');`,
+			want: ERSchema{
+				Tables: []Table{
+					{Name: "users", Columns: []Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+					{Name: "orders", Columns: []Column{
+						{Name: "id", Type: "int", PrimaryKey: true},
+						{Name: "user_id", Type: "int", NotNull: true},
+						{Name: "note", Type: "text"},
+					}, Indexes: []Index{
+						{Name: "orders_user", Columns: []string{"user_id", "id"}, Unique: true},
+						{Name: "uk", Columns: []string{"note"}, Unique: true},
+						{Name: "orders_user_id_key", Columns: []string{"user_id"}, Unique: true},
+					}},
+				},
+				// The unique key on user_id makes the relation one
+				// to one.
+				Relations: []Relation{{FromTable: "orders", FromColumns: []string{"user_id"}, ToTable: "users", ToColumns: []string{"id"}, OneToOne: true}},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := ParseDDL(strings.NewReader(test.sql))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s, test.want) {
+				t.Errorf("got\n%+v\nwant\n%+v", s, test.want)
+			}
+		})
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	for _, test := range []struct {
+		sql, want string
+	}{
+		{"CREATE TABLE x (a int,\n b int", "line 2"},
+		{"CREATE TABLE x (a int, 'unterminated", "graw: ParseDDL"},
+	} {
+		if _, err := ParseDDL(strings.NewReader(test.sql)); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ParseDDL(%q): got error %v, want one mentioning %q", test.sql, err, test.want)
+		}
+	}
+}
+
+func TestFromDDL(t *testing.T) {
+	g, err := FromDDL(strings.NewReader("CREATE TABLE a (id int PRIMARY KEY); CREATE TABLE b (a_id int REFERENCES a (id));"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := edgeList(g), []string{"table-b-a_id->table-a-id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %v, want %v", got, want)
+	}
+	if issues := ValidateStyles(g); len(issues) > 0 {
+		t.Errorf("got style issues %v", issues)
+	}
+}
@@ -0,0 +1,132 @@
+package graw
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule is a rule of a rules engine: its outcome applies when all its
+// conditions hold. A rule without conditions always applies.
+type Rule struct {
+	Conditions []string
+	Outcome    string
+}
+
+// DecisionTable is a decision table: a rule per row, whose entries
+// constrain the inputs of the columns.
+type DecisionTable struct {
+	Inputs []string
+	Rows   []DecisionRow
+}
+
+// DecisionRow is a row of a DecisionTable. Entries holds a value or a
+// comparison, e.g. "gold" or ">= 18", per input; "" and "-" match any
+// value.
+type DecisionRow struct {
+	Entries []string
+	Outcome string
+}
+
+// Rules returns the rules of the rows of t, their conditions made of
+// the inputs and entries, e.g. "age >= 18" or "tier = gold".
+func (t DecisionTable) Rules() []Rule {
+	rules := make([]Rule, len(t.Rows))
+	for i, row := range t.Rows {
+		rules[i].Outcome = row.Outcome
+		for j, entry := range row.Entries {
+			entry = strings.TrimSpace(entry)
+			if entry == "" || entry == "-" || j >= len(t.Inputs) {
+				continue
+			}
+			if strings.IndexByte("<>=!", entry[0]) < 0 {
+				entry = "= " + entry
+			}
+			rules[i].Conditions = append(rules[i].Conditions, t.Inputs[j]+" "+entry)
+		}
+	}
+	return rules
+}
+
+// NewDecisionTree returns the flowchart deciding between rules which
+// are tried in order, the first rule whose conditions all hold giving
+// the outcome. Every condition is a diamond with a true and a false
+// branch; consecutive rules starting with the same condition share
+// its diamond. When no rule matches the flowchart ends in "no match",
+// unless the last rule has no conditions.
+func NewDecisionTree(rules []Rule) (*GraphModel, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("graw: NewDecisionTree: no rules")
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	var nodes, edges []*Cell
+	children := make(map[*Cell][]*Cell)
+	placed := make(map[*Cell]bool)
+	node := func(base, label, shape string) *Cell {
+		c := NewShape(taken.unique(base), rootCellID)
+		c.Value = label
+		c.Style = Style{Attributes: shapeStyle(shape)}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		nodes = append(nodes, c)
+		return c
+	}
+	branch := func(from, to *Cell, label string) {
+		e := newEdgeCell(taken.unique(from.ID+"-"+to.ID), rootCellID, from.ID, to.ID)
+		e.Value = label
+		edges = append(edges, e)
+		if !placed[to] {
+			// the layout tree follows the first branch to a node
+			placed[to] = true
+			children[from] = append(children[from], to)
+		}
+	}
+
+	var noMatch *Cell
+	fallback := func() *Cell {
+		if noMatch == nil {
+			noMatch = node("no-match", "no match", "ellipse")
+		}
+		return noMatch
+	}
+
+	// build returns the node deciding between rules, continuing with
+	// next when none of them matches
+	var build func(rules []Rule, next func() *Cell) *Cell
+	build = func(rules []Rule, next func() *Cell) *Cell {
+		if len(rules) == 0 {
+			return next()
+		}
+		if len(rules[0].Conditions) == 0 {
+			return node("outcome", rules[0].Outcome, "rounded")
+		}
+		cond := rules[0].Conditions[0]
+		n := 1
+		for n < len(rules) && len(rules[n].Conditions) > 0 && rules[n].Conditions[0] == cond {
+			n++
+		}
+		d := node("condition", cond, "rhombus")
+		var rest *Cell
+		restNode := func() *Cell {
+			if rest == nil {
+				rest = build(rules[n:], next)
+			}
+			return rest
+		}
+		inner := make([]Rule, n)
+		for i, r := range rules[:n] {
+			inner[i] = Rule{Conditions: r.Conditions[1:], Outcome: r.Outcome}
+		}
+		branch(d, build(inner, restNode), "true")
+		branch(d, restNode(), "false")
+		return d
+	}
+	root := build(rules, fallback)
+
+	placed[root] = true
+	layoutTree([]*Cell{root}, children, 10, 10, groupGap, 60)
+	for _, c := range append(nodes, edges...) {
+		g.Add(c)
+	}
+	return &g, nil
+}
@@ -0,0 +1,129 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecisionTableRules(t *testing.T) {
+	table := DecisionTable{
+		Inputs: []string{"age", "tier"},
+		Rows: []DecisionRow{
+			{Entries: []string{">= 18", "gold"}, Outcome: "approve"},
+			{Entries: []string{" >= 18 ", "-"}, Outcome: "review"},
+			{Entries: []string{"", "!= staff", "extra"}, Outcome: "reject"},
+			{Outcome: "default"},
+		},
+	}
+	want := []Rule{
+		{Conditions: []string{"age >= 18", "tier = gold"}, Outcome: "approve"},
+		{Conditions: []string{"age >= 18"}, Outcome: "review"},
+		{Conditions: []string{"tier != staff"}, Outcome: "reject"},
+		{Outcome: "default"},
+	}
+	if got := table.Rules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// decide follows the decision tree g from its root, taking the true
+// branch of the conditions in holds, and returns the outcome reached.
+func decide(g *GraphModel, holds map[string]bool) string {
+	out := make(map[string]map[string]string)
+	targets := make(map[string]bool)
+	for _, c := range g.Root {
+		if c.isEdge() {
+			if out[c.Source] == nil {
+				out[c.Source] = make(map[string]string)
+			}
+			out[c.Source][c.Value] = c.Target
+			targets[c.Target] = true
+		}
+	}
+	var cur *Cell
+	for _, c := range g.Root {
+		if c.isVertex() && !targets[c.ID] {
+			cur = c
+			break
+		}
+	}
+	for cur != nil && out[cur.ID] != nil {
+		branch := "false"
+		if holds[cur.Value] {
+			branch = "true"
+		}
+		cur = g.FindByID(out[cur.ID][branch])
+	}
+	if cur == nil {
+		return ""
+	}
+	return cur.Value
+}
+
+func TestNewDecisionTree(t *testing.T) {
+	rules := DecisionTable{
+		Inputs: []string{"age", "tier"},
+		Rows: []DecisionRow{
+			{Entries: []string{">= 18", "gold"}, Outcome: "approve"},
+			{Entries: []string{">= 18", "-"}, Outcome: "review"},
+			{Entries: []string{"", "staff"}, Outcome: "approve"},
+		},
+	}.Rules()
+	g, err := NewDecisionTree(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		holds map[string]bool
+		want  string
+	}{
+		{"adult gold", map[string]bool{"age >= 18": true, "tier = gold": true}, "approve"},
+		{"adult", map[string]bool{"age >= 18": true}, "review"},
+		{"adult staff", map[string]bool{"age >= 18": true, "tier = staff": true}, "review"},
+		{"minor staff", map[string]bool{"tier = staff": true}, "approve"},
+		{"minor gold", map[string]bool{"tier = gold": true}, "no match"},
+		{"minor", nil, "no match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decide(g, tt.holds); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The shared first condition is drawn once.
+	n := 0
+	for _, c := range g.Root {
+		if c.Value == "age >= 18" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("got %d diamonds for the shared condition, want 1", n)
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewDecisionTreeCatchAll(t *testing.T) {
+	g, err := NewDecisionTree([]Rule{
+		{Conditions: []string{"vip"}, Outcome: "fast lane"},
+		{Outcome: "queue"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decide(g, nil); got != "queue" {
+		t.Errorf("got %q, want %q", got, "queue")
+	}
+	if g.FindByID("no-match") != nil {
+		t.Error("got a no match node after a rule without conditions")
+	}
+	if _, err := NewDecisionTree(nil); err == nil {
+		t.Error("NewDecisionTree(nil): got no error")
+	}
+}
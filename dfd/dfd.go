@@ -0,0 +1,294 @@
+// Package dfd builds data flow diagrams for threat modeling with graw:
+// external entities, processes and data stores connected by data
+// flows, grouped into trust boundaries, in the notation of the
+// Microsoft Threat Modeling Tool and OWASP Threat Dragon.
+package dfd
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+
+	graw "github.com/fuguohong1024/draw"
+)
+
+// layer is the ID of the default layer created by graw.NewGraph.
+const layer = "1"
+
+// Size and spacing of the diagram elements.
+const (
+	elementWidth  = 120
+	elementHeight = 60
+	padding       = 20
+	header        = 30
+	gap           = 60
+)
+
+// Kind is the kind of an Element.
+type Kind int
+
+// The kinds of elements of a data flow diagram.
+const (
+	// External is an external entity, such as a user or a third
+	// party service, drawn as a rectangle.
+	External Kind = iota
+	// Process is code handling data, drawn as a circle.
+	Process
+	// DataStore is data at rest, such as a database or a file,
+	// drawn between two lines.
+	DataStore
+)
+
+// String returns the name of k.
+func (k Kind) String() string {
+	switch k {
+	case External:
+		return "external entity"
+	case Process:
+		return "process"
+	case DataStore:
+		return "data store"
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Element is an external entity, process or data store.
+type Element struct {
+	ID   string
+	Name string
+	Kind Kind
+	// Boundary is the ID of the trust boundary holding the element,
+	// empty for none.
+	Boundary string
+}
+
+// Boundary is a trust boundary: a dashed container holding the
+// elements trusted alike, and possibly nested boundaries.
+type Boundary struct {
+	ID   string
+	Name string
+	// Parent is the ID of the boundary holding this one, empty for
+	// none.
+	Parent string
+}
+
+// Flow is a data flow from an element to another.
+type Flow struct {
+	From, To string
+	Name     string
+	// Classification is the classification of the data, e.g.
+	// "Confidential" or "PII".
+	Classification string
+}
+
+// Model is a data flow diagram. Elements and boundaries are laid out
+// in the order given, the elements of a boundary before the boundaries
+// nested in it.
+type Model struct {
+	Elements   []Element
+	Boundaries []Boundary
+	Flows      []Flow
+}
+
+// ErrNoProcess is returned by Diagram for data flows not involving a
+// process: data only moves between external entities and data stores
+// through processes.
+var ErrNoProcess = errors.New("dfd: data flow without a process")
+
+// Diagram returns the data flow diagram of m. Trust boundaries are
+// dashed red containers laid out from left to right, their elements
+// stacked top to bottom; elements outside any boundary take part in
+// the same row. Flows are labeled with their name and, in italics,
+// their classification, and drawn bold when they cross a trust
+// boundary, where threats are to be looked for.
+func (m *Model) Diagram() (*graw.GraphModel, error) {
+	boundaries := make(map[string]Boundary, len(m.Boundaries))
+	for _, b := range m.Boundaries {
+		if b.ID == "" {
+			return nil, fmt.Errorf("dfd: Diagram: boundary %q has no ID", b.Name)
+		}
+		if _, ok := boundaries[b.ID]; ok {
+			return nil, fmt.Errorf("dfd: Diagram: duplicate boundary %q", b.ID)
+		}
+		boundaries[b.ID] = b
+	}
+	for _, b := range m.Boundaries {
+		seen := map[string]bool{b.ID: true}
+		for p := b.Parent; p != ""; p = boundaries[p].Parent {
+			if _, ok := boundaries[p]; !ok {
+				return nil, fmt.Errorf("dfd: Diagram: boundary %q: unknown parent %q", b.ID, p)
+			}
+			if seen[p] {
+				return nil, fmt.Errorf("dfd: Diagram: boundary %q is nested in itself", b.ID)
+			}
+			seen[p] = true
+		}
+	}
+	elements := make(map[string]Element, len(m.Elements))
+	for _, e := range m.Elements {
+		if e.ID == "" {
+			return nil, fmt.Errorf("dfd: Diagram: element %q has no ID", e.Name)
+		}
+		if _, ok := elements[e.ID]; ok {
+			return nil, fmt.Errorf("dfd: Diagram: duplicate element %q", e.ID)
+		}
+		if _, ok := boundaries[e.ID]; ok {
+			return nil, fmt.Errorf("dfd: Diagram: element %q has the ID of a boundary", e.ID)
+		}
+		if _, ok := boundaries[e.Boundary]; e.Boundary != "" && !ok {
+			return nil, fmt.Errorf("dfd: Diagram: element %q: unknown boundary %q", e.ID, e.Boundary)
+		}
+		elements[e.ID] = e
+	}
+	for _, f := range m.Flows {
+		from, ok := elements[f.From]
+		if !ok {
+			return nil, fmt.Errorf("dfd: Diagram: flow %q: unknown element %q", f.Name, f.From)
+		}
+		to, ok := elements[f.To]
+		if !ok {
+			return nil, fmt.Errorf("dfd: Diagram: flow %q: unknown element %q", f.Name, f.To)
+		}
+		if from.Kind != Process && to.Kind != Process {
+			return nil, fmt.Errorf("dfd: Diagram: flow %q from %s %q to %s %q: %w", f.Name, from.Kind, f.From, to.Kind, f.To, ErrNoProcess)
+		}
+	}
+
+	// members holds the IDs of the elements and boundaries in each
+	// boundary, "" holding the top level ones
+	members := make(map[string][]string)
+	for _, e := range m.Elements {
+		members[e.Boundary] = append(members[e.Boundary], e.ID)
+	}
+	for _, b := range m.Boundaries {
+		members[b.Parent] = append(members[b.Parent], b.ID)
+	}
+
+	g := graw.NewGraph()
+	var cells []*graw.Cell
+	var place func(id, parent string, x, y int) (int, int)
+	// place creates the cell of the element or boundary id at (x, y)
+	// within parent, followed by its members placed below each other,
+	// and returns its size
+	place = func(id, parent string, x, y int) (int, int) {
+		var c *graw.Cell
+		if e, ok := elements[id]; ok {
+			c = element(e, parent)
+			cells = append(cells, c)
+		} else {
+			c = graw.NewShape(id, parent)
+			c.Value = html.EscapeString(boundaries[id].Name)
+			c.Style = graw.Style{Attributes: map[string]string{
+				"rounded":       "1",
+				"dashed":        "1",
+				"container":     "1",
+				"collapsible":   "0",
+				"fillColor":     "none",
+				"strokeColor":   "#b85450",
+				"fontColor":     "#b85450",
+				"verticalAlign": "top",
+				"align":         "left",
+				"spacingLeft":   "8",
+				"html":          "1",
+				"whiteSpace":    "wrap",
+			}}
+			cells = append(cells, c)
+			w, h := elementWidth, header+padding
+			cy := header
+			for _, member := range members[id] {
+				mw, mh := place(member, id, padding, cy)
+				cy += mh + gap/2
+				if mw+2*padding > w {
+					w = mw + 2*padding
+				}
+				h = cy - gap/2 + padding
+			}
+			c.Geometry.Width, c.Geometry.Height = strconv.Itoa(w), strconv.Itoa(h)
+		}
+		c.Geometry.X, c.Geometry.Y = x, y
+		w, _ := strconv.Atoi(c.Geometry.Width)
+		h, _ := strconv.Atoi(c.Geometry.Height)
+		return w, h
+	}
+	x := 10
+	for _, id := range members[""] {
+		w, _ := place(id, layer, x, 10)
+		x += w + gap
+	}
+	for _, c := range cells {
+		g.Add(c)
+	}
+
+	// boundaryPath returns the boundaries holding the element id,
+	// innermost first
+	boundaryPath := func(id string) string {
+		path := ""
+		for b := elements[id].Boundary; b != ""; b = boundaries[b].Parent {
+			path += b + "\x00"
+		}
+		return path
+	}
+	ids := make(map[string]bool)
+	for _, f := range m.Flows {
+		id := "flow-" + f.From + "-" + f.To
+		for n := 2; ids[id]; n++ {
+			id = "flow-" + f.From + "-" + f.To + "-" + strconv.Itoa(n)
+		}
+		ids[id] = true
+		e := graw.NewEdge(id, layer, f.From, f.To)
+		e.Style.Attributes["rounded"] = "1"
+		e.Value = html.EscapeString(f.Name)
+		if f.Classification != "" {
+			if e.Value != "" {
+				e.Value += "<br>"
+			}
+			e.Value += "<i>" + html.EscapeString(f.Classification) + "</i>"
+		}
+		if boundaryPath(f.From) != boundaryPath(f.To) {
+			e.Style.Attributes["strokeWidth"] = "2"
+			e.Data = map[string]string{"tooltip": "crosses a trust boundary"}
+		}
+		g.Add(e)
+	}
+	return &g, nil
+}
+
+// element returns the cell of e within parent, in the shape of its
+// kind.
+func element(e Element, parent string) *graw.Cell {
+	c := graw.NewShape(e.ID, parent)
+	c.Value = html.EscapeString(e.Name)
+	if c.Value == "" {
+		c.Value = html.EscapeString(e.ID)
+	}
+	attrs := map[string]string{"html": "1", "whiteSpace": "wrap"}
+	switch e.Kind {
+	case Process:
+		attrs["ellipse"] = ""
+		attrs["perimeter"] = "ellipsePerimeter"
+		attrs["fillColor"] = "#dae8fc"
+		attrs["strokeColor"] = "#6c8ebf"
+	case DataStore:
+		attrs["shape"] = "partialRectangle"
+		attrs["left"] = "0"
+		attrs["right"] = "0"
+		attrs["fillColor"] = "#d5e8d4"
+		attrs["strokeColor"] = "#82b366"
+	default:
+		attrs["fillColor"] = "#f5f5f5"
+		attrs["strokeColor"] = "#666666"
+	}
+	c.Style = graw.Style{Attributes: attrs}
+	graw.SizeToFit(c, nil, elementWidth, elementHeight)
+	if e.Kind == Process {
+		// processes are circles
+		w, _ := strconv.Atoi(c.Geometry.Width)
+		h, _ := strconv.Atoi(c.Geometry.Height)
+		if h > w {
+			w = h
+		}
+		c.Geometry.Width, c.Geometry.Height = strconv.Itoa(w), strconv.Itoa(w)
+	}
+	return c
+}
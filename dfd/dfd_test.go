@@ -0,0 +1,139 @@
+package dfd
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fuguohong1024/draw"
+)
+
+func testModel() Model {
+	return Model{
+		Boundaries: []Boundary{
+			{ID: "dmz", Name: "DMZ"},
+			{ID: "corp", Name: "Corporate"},
+			{ID: "db", Name: "DB subnet", Parent: "corp"},
+		},
+		Elements: []Element{
+			{ID: "user", Name: "User", Kind: External},
+			{ID: "web", Name: "Web app", Kind: Process, Boundary: "dmz"},
+			{ID: "api", Name: "API", Kind: Process, Boundary: "corp"},
+			{ID: "cache", Name: "Cache", Kind: DataStore, Boundary: "corp"},
+			{ID: "store", Name: "Customers", Kind: DataStore, Boundary: "db"},
+		},
+		Flows: []Flow{
+			{From: "user", To: "web", Name: "login", Classification: "Credentials"},
+			{From: "web", To: "api", Name: "query"},
+			{From: "api", To: "cache", Name: "get"},
+			{From: "api", To: "store", Name: "SQL", Classification: "PII"},
+		},
+	}
+}
+
+func TestDiagram(t *testing.T) {
+	m := testModel()
+	g, err := m.Diagram()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cells := []struct {
+		id, parent, label, shape string
+	}{
+		{"user", "1", "User", ""},
+		{"dmz", "1", "DMZ", ""},
+		{"web", "dmz", "Web app", "ellipse"},
+		{"corp", "1", "Corporate", ""},
+		{"api", "corp", "API", "ellipse"},
+		{"cache", "corp", "Cache", "partialRectangle"},
+		{"db", "corp", "DB subnet", ""},
+		{"store", "db", "Customers", "partialRectangle"},
+	}
+	for _, tt := range cells {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Errorf("%s: missing", tt.id)
+			continue
+		}
+		if c.ParentID != tt.parent || c.Value != tt.label {
+			t.Errorf("%s: got parent %q, label %q, want %q, %q", tt.id, c.ParentID, c.Value, tt.parent, tt.label)
+		}
+		if tt.shape == "ellipse" {
+			if _, ok := c.Style.Attributes["ellipse"]; !ok {
+				t.Errorf("%s: got style %v, want an ellipse", tt.id, c.Style.Attributes)
+			}
+		} else if got := c.Style.Attributes["shape"]; got != tt.shape {
+			t.Errorf("%s: got shape %q, want %q", tt.id, got, tt.shape)
+		}
+	}
+
+	flows := []struct {
+		id, label string
+		crossing  bool
+	}{
+		{"flow-user-web", "login<br><i>Credentials</i>", true},
+		{"flow-web-api", "query", true},
+		{"flow-api-cache", "get", false},
+		{"flow-api-store", "SQL<br><i>PII</i>", true},
+	}
+	for _, tt := range flows {
+		c := g.FindByID(tt.id)
+		if c == nil || c.Edge != "1" {
+			t.Errorf("%s: missing", tt.id)
+			continue
+		}
+		if c.Value != tt.label {
+			t.Errorf("%s: got label %q, want %q", tt.id, c.Value, tt.label)
+		}
+		bold := c.Style.Attributes["strokeWidth"] == "2"
+		tip := c.Data["tooltip"] == "crosses a trust boundary"
+		if bold != tt.crossing || tip != tt.crossing {
+			t.Errorf("%s: got bold %v, tooltip %v, want %v", tt.id, bold, tip, tt.crossing)
+		}
+	}
+	if iss := graw.ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestDiagramErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *Model)
+		err    error
+	}{
+		{"boundary without ID", func(m *Model) { m.Boundaries = append(m.Boundaries, Boundary{Name: "x"}) }, nil},
+		{"duplicate boundary", func(m *Model) { m.Boundaries = append(m.Boundaries, Boundary{ID: "dmz"}) }, nil},
+		{"unknown parent", func(m *Model) { m.Boundaries[0].Parent = "x" }, nil},
+		{"nested in itself", func(m *Model) { m.Boundaries[1].Parent = "db" }, nil},
+		{"element without ID", func(m *Model) { m.Elements = append(m.Elements, Element{Name: "x"}) }, nil},
+		{"duplicate element", func(m *Model) { m.Elements = append(m.Elements, Element{ID: "web"}) }, nil},
+		{"element with a boundary ID", func(m *Model) { m.Elements = append(m.Elements, Element{ID: "dmz"}) }, nil},
+		{"unknown boundary", func(m *Model) { m.Elements[0].Boundary = "x" }, nil},
+		{"unknown source", func(m *Model) { m.Flows[0].From = "x" }, nil},
+		{"unknown target", func(m *Model) { m.Flows[0].To = "x" }, nil},
+		{"no process", func(m *Model) { m.Flows = append(m.Flows, Flow{From: "user", To: "store"}) }, ErrNoProcess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel()
+			tt.change(&m)
+			_, err := m.Diagram()
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestKindString(t *testing.T) {
+	got := []string{External.String(), Process.String(), DataStore.String(), Kind(7).String()}
+	want := []string{"external entity", "process", "data store", "Kind(7)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,198 @@
+package graw
+
+import (
+	"sort"
+	"strings"
+)
+
+// Colors of the problems found in dependency injection graphs.
+const (
+	diMissingColor   = "#ff0000"
+	diAmbiguousColor = "#ff8000"
+)
+
+// diProvider is a constructor of a dependency injection graph.
+type diProvider struct {
+	key, name, module string
+	// kind is "injector", "input", "bind" or "group" for the nodes
+	// which are not plain constructors.
+	kind     string
+	params   []string
+	optional map[string]bool
+	results  []string
+	failed   bool
+}
+
+// diGraph is a dependency injection graph: its providers, and the sets
+// of providers resolved together, an injector or application each.
+type diGraph struct {
+	providers map[string]*diProvider
+	order     []string
+	scopes    [][]string
+}
+
+// add adds p, unless a provider with the same key is known already,
+// and returns the provider stored under the key.
+func (dg *diGraph) add(p *diProvider) *diProvider {
+	if old, ok := dg.providers[p.key]; ok {
+		return old
+	}
+	if dg.providers == nil {
+		dg.providers = make(map[string]*diProvider)
+	}
+	dg.providers[p.key] = p
+	dg.order = append(dg.order, p.key)
+	return p
+}
+
+// diagram draws dg: a container per module holding its providers,
+// and an edge from every provider to the providers of its parameters,
+// labeled with the type. Types without a provider in a scope are drawn
+// as red boxes and types with several providers make their edges and
+// providers orange.
+func (dg *diGraph) diagram() *GraphModel {
+	type dep struct{ from, to, typ string }
+	var deps []dep
+	seen := make(map[dep]bool)
+	missing := make(map[string][]string)
+	var missingOrder []string
+	ambiguous := make(map[string]bool)
+
+	for _, scope := range dg.scopes {
+		providedBy := make(map[string][]string)
+		for _, key := range scope {
+			for _, t := range dg.providers[key].results {
+				providedBy[t] = append(providedBy[t], key)
+				if len(providedBy[t]) > 1 {
+					for _, f := range providedBy[t] {
+						ambiguous[f] = true
+					}
+				}
+			}
+		}
+		for _, key := range scope {
+			p := dg.providers[key]
+			for _, t := range p.params {
+				from := providedBy[t]
+				if len(from) == 0 && !p.optional[t] {
+					if _, ok := missing[t]; !ok {
+						missingOrder = append(missingOrder, t)
+					}
+					from = []string{"\x00" + t}
+					missing[t] = append(missing[t], p.name)
+				}
+				for _, f := range from {
+					d := dep{key, f, t}
+					if !seen[d] {
+						seen[d] = true
+						deps = append(deps, d)
+					}
+				}
+			}
+		}
+	}
+
+	var modules []string
+	members := make(map[string][]string)
+	moduleDeps := make(map[string][]string)
+	for _, key := range dg.order {
+		m := dg.providers[key].module
+		if _, ok := members[m]; !ok {
+			modules = append(modules, m)
+		}
+		members[m] = append(members[m], key)
+	}
+	for _, d := range deps {
+		if p, ok := dg.providers[d.to]; ok {
+			from := dg.providers[d.from].module
+			moduleDeps[from] = append(moduleDeps[from], p.module)
+		}
+	}
+	_, _, column := dependencyColumns(modules, moduleDeps)
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string, len(dg.order))
+	var columns [][]*Cell
+	var children []*Cell
+	for _, m := range modules {
+		container := newContainer(taken.unique(m), rootCellID, m)
+		var cells []*Cell
+		for _, key := range members[m] {
+			p := dg.providers[key]
+			c := NewShape(taken.unique(strings.ReplaceAll(key, "\x00", "-")), container.ID)
+			c.Value = p.name
+			shape := "rounded"
+			switch p.kind {
+			case "injector":
+				shape = "hexagon"
+			case "input":
+				shape = "ellipse"
+			case "group":
+				shape = "rhombus"
+			}
+			c.Style = Style{Attributes: shapeStyle(shape)}
+			if p.kind == "bind" {
+				c.Style.set("dashed", "1")
+			}
+			switch {
+			case p.failed:
+				c.Style.set("strokeColor", diMissingColor)
+			case ambiguous[key]:
+				c.Style.set("strokeColor", diAmbiguousColor)
+			}
+			if len(p.results) > 0 {
+				c.Data = map[string]string{"tooltip": "provides " + strings.Join(p.results, ", ")}
+			}
+			SizeToFit(c, nil, defaultWidth, 40)
+			ids[key] = c.ID
+			cells = append(cells, c)
+		}
+		w, h := layoutColumns([][]*Cell{cells}, groupPadding, groupHeader+groupPadding, groupGap)
+		container.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+		children = append(children, cells...)
+
+		for len(columns) <= column[m] {
+			columns = append(columns, nil)
+		}
+		columns[column[m]] = append(columns[column[m]], container)
+	}
+
+	var missingCells []*Cell
+	sort.Strings(missingOrder)
+	for _, t := range missingOrder {
+		c := NewShape(taken.unique("missing-"+t), rootCellID)
+		c.Value = t
+		c.Style = Style{Attributes: shapeStyle("")}
+		c.Style.set("dashed", "1")
+		c.Style.set("strokeColor", diMissingColor)
+		c.Style.set("fontColor", diMissingColor)
+		c.Data = map[string]string{"tooltip": "no provider, needed by " + strings.Join(missing[t], ", ")}
+		SizeToFit(c, nil, defaultWidth, 40)
+		ids["\x00"+t] = c.ID
+		missingCells = append(missingCells, c)
+	}
+
+	layoutColumns(append(columns, missingCells), 10, 10, 80)
+	for _, col := range append(columns, missingCells, children) {
+		for _, c := range col {
+			g.Add(c)
+		}
+	}
+	for _, d := range deps {
+		from, to := ids[d.from], ids[d.to]
+		e := newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to)
+		e.Value = d.typ
+		switch {
+		case strings.HasPrefix(d.to, "\x00"):
+			e.Style.set("strokeColor", diMissingColor)
+		case ambiguous[d.to]:
+			e.Style.set("strokeColor", diAmbiguousColor)
+		}
+		if dg.providers[d.from].optional[d.typ] {
+			e.Style.set("dashed", "1")
+		}
+		g.Add(e)
+	}
+	return &g
+}
@@ -0,0 +1,258 @@
+package graw
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotStatement is a node or edge statement of a graphviz graph, with
+// the subgraph it appears in.
+type dotStatement struct {
+	from, to string
+	attrs    map[string]string
+	subgraph string
+}
+
+// FromFx returns a dependency diagram of an uber/fx application from
+// the graphviz graph of its container, as provided by fx.DotGraph or
+// written by dig.Visualize. Constructors are grouped in a container
+// per package and point to the constructors of their parameters,
+// optional parameters dashed. Parameters provided by no constructor
+// are drawn in red, as are the constructors dig reported failures for,
+// and types provided more than once make their constructors orange.
+// Value groups are drawn as diamonds collecting their members.
+func FromFx(r io.Reader) (*GraphModel, error) {
+	stmts, labels, err := parseDOTStatements(r)
+	if err != nil {
+		return nil, fmt.Errorf("graw: FromFx: %v", err)
+	}
+
+	var dg diGraph
+	ctors := make(map[string]*diProvider)
+	failed := make(map[string]bool)
+	for _, st := range stmts {
+		if st.to != "" || st.subgraph == "" {
+			continue
+		}
+		if strings.HasPrefix(st.from, "constructor_") {
+			module := labels[st.subgraph]["label"]
+			if module == "" {
+				module = "fx"
+			}
+			p := dg.add(&diProvider{key: st.subgraph, name: st.attrs["label"], module: module, optional: make(map[string]bool)})
+			p.failed = labels[st.subgraph]["color"] != ""
+			ctors[st.from] = p
+		}
+	}
+	for _, st := range stmts {
+		switch {
+		case st.to == "" && st.subgraph != "" && !strings.HasPrefix(st.from, "constructor_"):
+			// a result of the constructor of the subgraph
+			if p, ok := dg.providers[st.subgraph]; ok {
+				p.results = append(p.results, st.from)
+			}
+		case st.to == "" && st.attrs["color"] != "":
+			failed[st.from] = true
+		case st.to == "" && st.attrs["shape"] == "diamond":
+			g := dg.add(&diProvider{key: "group\x00" + st.from, module: "value groups", kind: "group", results: []string{st.from}})
+			g.name = st.attrs["label"]
+		case st.to != "" && ctors[st.from] != nil:
+			p := ctors[st.from]
+			p.params = append(p.params, st.to)
+			if st.attrs["style"] == "dashed" {
+				p.optional[st.to] = true
+			}
+		case st.to != "":
+			// a member of a value group
+			g := dg.add(&diProvider{key: "group\x00" + st.from, module: "value groups", kind: "group", results: []string{st.from}})
+			g.params = append(g.params, st.to)
+		}
+	}
+	if len(dg.order) == 0 {
+		return nil, fmt.Errorf("graw: FromFx: no constructors found")
+	}
+	for _, key := range dg.order {
+		p := dg.providers[key]
+		if p.name == "" {
+			p.name = p.results[0]
+		}
+		for _, t := range p.results {
+			p.failed = p.failed || failed[t]
+		}
+	}
+	dg.scopes = [][]string{dg.order}
+	return dg.diagram(), nil
+}
+
+// parseDOTStatements returns the node and edge statements of a
+// graphviz graph, and the attributes set in each subgraph. Chained
+// edges a -> b -> c are split into single edges.
+func parseDOTStatements(r io.Reader) ([]dotStatement, map[string]map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := lexDOT(string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stmts []dotStatement
+	labels := make(map[string]map[string]string)
+	var stack []string
+	current := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1]
+	}
+	// attrs reads the attribute list starting at tokens[i], "[", and
+	// returns it with the index following the closing "]".
+	attrs := func(i int) (map[string]string, int) {
+		m := make(map[string]string)
+		for i++; i < len(tokens) && tokens[i] != "]"; i++ {
+			if i+2 < len(tokens) && tokens[i+1] == "=" {
+				m[dotUnquote(tokens[i])] = dotUnquote(tokens[i+2])
+				i += 2
+			}
+		}
+		return m, i + 1
+	}
+
+	for i := 0; i < len(tokens); {
+		tok := tokens[i]
+		switch {
+		case tok == "strict" || tok == "digraph" || tok == "graph" && i+1 < len(tokens) && tokens[i+1] != "[":
+			for i < len(tokens) && tokens[i] != "{" {
+				i++
+			}
+			stack = append(stack, "")
+			i++
+		case tok == "subgraph":
+			name := ""
+			if i+1 < len(tokens) && tokens[i+1] != "{" {
+				name = dotUnquote(tokens[i+1])
+				i++
+			}
+			stack = append(stack, name)
+			i += 2
+		case tok == "{":
+			stack = append(stack, current())
+			i++
+		case tok == "}":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			i++
+		case tok == ";" || tok == ",":
+			i++
+		case tok == "graph" || tok == "node" || tok == "edge":
+			_, i = attrs(i + 1)
+		case i+2 < len(tokens) && tokens[i+1] == "=":
+			if labels[current()] == nil {
+				labels[current()] = make(map[string]string)
+			}
+			labels[current()][dotUnquote(tok)] = dotUnquote(tokens[i+2])
+			i += 3
+		default:
+			ids := []string{dotUnquote(tok)}
+			i++
+			for i+1 < len(tokens) && (tokens[i] == "->" || tokens[i] == "--") {
+				ids = append(ids, dotUnquote(tokens[i+1]))
+				i += 2
+			}
+			a := map[string]string{}
+			if i < len(tokens) && tokens[i] == "[" {
+				a, i = attrs(i)
+			}
+			if len(ids) == 1 {
+				stmts = append(stmts, dotStatement{from: ids[0], attrs: a, subgraph: current()})
+			}
+			for j := 1; j < len(ids); j++ {
+				stmts = append(stmts, dotStatement{from: ids[j-1], to: ids[j], attrs: a, subgraph: current()})
+			}
+		}
+	}
+	return stmts, labels, nil
+}
+
+// lexDOT splits a graphviz graph into tokens: punctuation, edge
+// operators, identifiers, and quoted and HTML strings kept with their
+// delimiters.
+func lexDOT(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '#' || strings.HasPrefix(s[i:], "//"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case strings.HasPrefix(s[i:], "->") || strings.HasPrefix(s[i:], "--"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.IndexByte("{}[]=;,", c) >= 0:
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		case c == '<':
+			depth, j := 0, i
+			for ; j < len(s); j++ {
+				if s[j] == '<' {
+					depth++
+				} else if s[j] == '>' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated HTML string")
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && strings.IndexByte(" \t\r\n{}[]=;,\"<", s[j]) < 0 && !strings.HasPrefix(s[j:], "->") {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// dotUnquote returns the value of a graphviz identifier: quoted strings
+// are unescaped and HTML strings lose their outer brackets.
+func dotUnquote(tok string) string {
+	switch {
+	case strings.HasPrefix(tok, `"`):
+		v, _, _ := dotQuoted(tok)
+		return v
+	case strings.HasPrefix(tok, "<") && strings.HasSuffix(tok, ">"):
+		return tok[1 : len(tok)-1]
+	}
+	return tok
+}
@@ -0,0 +1,205 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const wireSrc = `//go:build wireinject
+
+package main
+
+import (
+	"database/sql"
+	"github.com/google/wire"
+	"example.com/app/store"
+)
+
+type Config struct{ DSN string }
+
+type Server struct {
+	Store  store.Store
+	Log    *Logger ` + "`wire:\"-\"`" + `
+	Config Config
+}
+
+type Logger struct{}
+
+func NewDB(c Config) (*sql.DB, func(), error) { return nil, nil, nil }
+func NewLogger() *Logger { return nil }
+func NewLogger2() *Logger { return nil }
+
+var DBSet = wire.NewSet(NewDB, store.NewSQLStore, wire.Bind(new(store.Store), new(*store.SQLStore)))
+
+var LogSet = wire.NewSet(NewLogger, NewLogger2)
+
+func InitServer(c Config) (*Server, error) {
+	wire.Build(DBSet, LogSet, wire.Struct(new(Server), "*"), NewMissing)
+	return nil, nil
+}
+`
+
+const wireStore = `package store
+
+import "database/sql"
+
+type Store interface{}
+type SQLStore struct{}
+
+func NewSQLStore(db *sql.DB, m Metrics) *SQLStore { return nil }
+`
+
+const fxDot = `digraph {
+	rankdir=RL;
+	graph [compound=true];
+		"[type=group]" [shape=diamond label=<http.Route<BR /><FONT POINT-SIZE="10">Group: routes</FONT>>];
+			"[type=group]" -> "http.Route[group=routes]0";
+			"[type=group]" -> "http.Route[group=routes]1";
+		subgraph cluster_0 {
+			label = "main";
+			constructor_0 [shape=plaintext label="NewMux"];
+			"*http.ServeMux" [label=<*http.ServeMux>];
+		}
+			constructor_0 -> "[type=group]" [ltail=cluster_0];
+			constructor_0 -> "*zap.Logger" [ltail=cluster_0 style=dashed];
+		subgraph cluster_1 {
+			label = "main";
+			constructor_1 [shape=plaintext label="NewEcho"];
+			"http.Route[group=routes]0" [label=<http.Route<BR /><FONT POINT-SIZE="10">Group: routes</FONT>>];
+		}
+			constructor_1 -> "*zap.Logger" [ltail=cluster_1];
+		subgraph cluster_2 {
+			label = "go.uber.org/fx/fxevent";
+			constructor_2 [shape=plaintext label="NewHello"];
+			"http.Route[group=routes]1" [label=<http.Route>];
+		}
+			constructor_2 -> "*zap.Logger" [ltail=cluster_2];
+			constructor_2 -> "Config" [ltail=cluster_2];
+	"Config" [color=red];
+}`
+
+func TestDependencyInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		graph   func() (*GraphModel, error)
+		parents map[string]string
+		edges   []string
+		stroke  map[string]string
+	}{
+		{
+			name: "wire",
+			graph: func() (*GraphModel, error) {
+				return FromWire(strings.NewReader(wireSrc), strings.NewReader(wireStore))
+			},
+			parents: map[string]string{
+				"main":                             "1",
+				"main.DBSet":                       "1",
+				"main.LogSet":                      "1",
+				"missing-store.Metrics":            "1",
+				"main.InitServer":                  "main",
+				"main.InitServer-main.Config":      "main",
+				"struct-main.Server":               "main",
+				"main.NewMissing":                  "main",
+				"main.NewDB":                       "main.DBSet",
+				"store.NewSQLStore":                "main.DBSet",
+				"bind-store.Store-*store.SQLStore": "main.DBSet",
+				"main.NewLogger":                   "main.LogSet",
+				"main.NewLogger2":                  "main.LogSet",
+			},
+			edges: []string{
+				"main.InitServer->struct-main.Server:*main.Server",
+				"main.NewDB->main.InitServer-main.Config:main.Config",
+				"store.NewSQLStore->main.NewDB:*sql.DB",
+				"store.NewSQLStore->missing-store.Metrics:store.Metrics",
+				"bind-store.Store-*store.SQLStore->store.NewSQLStore:*store.SQLStore",
+				"struct-main.Server->bind-store.Store-*store.SQLStore:store.Store",
+				"struct-main.Server->main.InitServer-main.Config:main.Config",
+			},
+			stroke: map[string]string{
+				"missing-store.Metrics":                   diMissingColor,
+				"store.NewSQLStore-missing-store.Metrics": diMissingColor,
+				"main.NewLogger":                          diAmbiguousColor,
+				"main.NewLogger2":                         diAmbiguousColor,
+				"main.NewDB":                              "",
+			},
+		},
+		{
+			name: "fx",
+			graph: func() (*GraphModel, error) {
+				return FromFx(strings.NewReader(fxDot))
+			},
+			parents: map[string]string{
+				"main":                   "1",
+				"value groups":           "1",
+				"go.uber.org/fx/fxevent": "1",
+				"missing-*zap.Logger":    "1",
+				"missing-Config":         "1",
+				"cluster_0":              "main",
+				"cluster_1":              "main",
+				"cluster_2":              "go.uber.org/fx/fxevent",
+				"group-[type=group]":     "value groups",
+			},
+			edges: []string{
+				"cluster_0->group-[type=group]:[type=group]",
+				"cluster_1->missing-*zap.Logger:*zap.Logger",
+				"cluster_2->missing-*zap.Logger:*zap.Logger",
+				"cluster_2->missing-Config:Config",
+				"group-[type=group]->cluster_1:http.Route[group=routes]0",
+				"group-[type=group]->cluster_2:http.Route[group=routes]1",
+			},
+			stroke: map[string]string{
+				"missing-Config":               diMissingColor,
+				"cluster_2-missing-Config":     diMissingColor,
+				"cluster_0":                    "",
+				"cluster_0-group-[type=group]": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.graph()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parents := make(map[string]string)
+			for _, c := range g.Root {
+				if c.isVertex() {
+					parents[c.ID] = c.ParentID
+				}
+			}
+			if !reflect.DeepEqual(parents, tt.parents) {
+				t.Errorf("got parents %v, want %v", parents, tt.parents)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %v, want %v", got, tt.edges)
+			}
+			for id, want := range tt.stroke {
+				if got := g.FindByID(id).Style.Attributes["strokeColor"]; got != want {
+					t.Errorf("%s: got strokeColor %q, want %q", id, got, want)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestDependencyInjectionErrors(t *testing.T) {
+	if _, err := FromWire(strings.NewReader("package main\n\nfunc main() {}\n")); err == nil {
+		t.Error("FromWire without injectors: got no error")
+	}
+	if _, err := FromWire(strings.NewReader("package main\n\nfunc main() {\n")); err == nil {
+		t.Error("FromWire with a syntax error: got no error")
+	}
+	for _, in := range []string{
+		"digraph {}",
+		`digraph { "a -> b }`,
+		"digraph { /* a -> b }",
+	} {
+		if _, err := FromFx(strings.NewReader(in)); err == nil {
+			t.Errorf("FromFx(%q): got no error", in)
+		}
+	}
+}
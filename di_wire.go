@@ -0,0 +1,364 @@
+package graw
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// wirePath is the import path of google/wire.
+const wirePath = "github.com/google/wire"
+
+// wireFile is a parsed source file with the name it imports wire as.
+type wireFile struct {
+	file *ast.File
+	pkg  string
+	wire string
+}
+
+// wireParser resolves the provider sets of google/wire source files.
+type wireParser struct {
+	files []*wireFile
+	funcs map[string]*ast.FuncDecl
+	sets  map[string]*ast.CallExpr
+	// file holds the file declaring every function, struct type and
+	// provider set.
+	file    map[string]*wireFile
+	structs map[string]*ast.StructType
+	dg      diGraph
+}
+
+// FromWire returns a dependency diagram of the google/wire provider
+// sets and injectors declared in the given Go source files. Providers
+// are grouped in a container per provider set, or per package for the
+// ones listed in wire.Build directly, and point to the providers of
+// their parameters. Every injector is checked on its own: types needed
+// by its providers but provided by none of them, nor by the injector's
+// parameters, are drawn in red and types provided more than once make
+// their providers orange.
+//
+// The sources are only parsed, not type checked, so types are matched
+// by name as written, qualified with the package name. The files of
+// all the packages providers come from must be passed for their
+// signatures to be known; providers from other packages are drawn
+// without dependencies.
+func FromWire(srcs ...io.Reader) (*GraphModel, error) {
+	wp := &wireParser{
+		funcs:   make(map[string]*ast.FuncDecl),
+		structs: make(map[string]*ast.StructType),
+		sets:    make(map[string]*ast.CallExpr),
+		file:    make(map[string]*wireFile),
+	}
+	fset := token.NewFileSet()
+	for i, r := range srcs {
+		f, err := parser.ParseFile(fset, fmt.Sprintf("source %d", i), r, 0)
+		if err != nil {
+			return nil, fmt.Errorf("graw: FromWire: %v", err)
+		}
+		wf := &wireFile{file: f, pkg: f.Name.Name}
+		for _, imp := range f.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path == wirePath {
+				wf.wire = "wire"
+				if imp.Name != nil {
+					wf.wire = imp.Name.Name
+				}
+			}
+		}
+		wp.files = append(wp.files, wf)
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					wp.funcs[wf.pkg+"."+d.Name.Name] = d
+					wp.file[wf.pkg+"."+d.Name.Name] = wf
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						if st, ok := s.Type.(*ast.StructType); ok {
+							wp.structs[wf.pkg+"."+s.Name.Name] = st
+							wp.file[wf.pkg+"."+s.Name.Name] = wf
+						}
+					case *ast.ValueSpec:
+						for i, name := range s.Names {
+							if i < len(s.Values) && wf.isWire(s.Values[i], "NewSet") {
+								wp.sets[wf.pkg+"."+name.Name] = s.Values[i].(*ast.CallExpr)
+								wp.file[wf.pkg+"."+name.Name] = wf
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, wf := range wp.files {
+		for _, decl := range wf.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok && wf.isWire(call, "Build") {
+					wp.injector(wf, fn, call)
+					return false
+				}
+				return true
+			})
+		}
+	}
+	if len(wp.dg.scopes) == 0 {
+		// no injectors: check all the sets together
+		names := make(map[string]string, len(wp.sets))
+		for name := range wp.sets {
+			names[name] = name
+		}
+		var scope []string
+		visited := make(map[string]bool)
+		for _, name := range sortedKeys(names) {
+			if !visited[name] {
+				visited[name] = true
+				scope = append(scope, wp.expand(wp.file[name], wp.sets[name].Args, name, visited)...)
+			}
+		}
+		wp.dg.scopes = append(wp.dg.scopes, scope)
+	}
+	if len(wp.dg.order) == 0 {
+		return nil, fmt.Errorf("graw: FromWire: no provider sets or injectors found")
+	}
+	return wp.dg.diagram(), nil
+}
+
+// isWire reports whether e is a call to the wire function fn.
+func (wf *wireFile) isWire(e ast.Expr, fn string) bool {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || wf.wire == "" {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == wf.wire && sel.Sel.Name == fn
+}
+
+// typeName returns the type expression e of the source file as a
+// string, its named types qualified with their package name.
+func (wf *wireFile) typeName(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil {
+			return t.Name
+		}
+		return wf.pkg + "." + t.Name
+	case *ast.StarExpr:
+		return "*" + wf.typeName(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + wf.typeName(t.Elt)
+		}
+		return "[" + types.ExprString(t.Len) + "]" + wf.typeName(t.Elt)
+	case *ast.MapType:
+		return "map[" + wf.typeName(t.Key) + "]" + wf.typeName(t.Value)
+	case *ast.ParenExpr:
+		return wf.typeName(t.X)
+	}
+	return types.ExprString(e)
+}
+
+// ref returns the key, package qualified name, of the identifier or
+// selector e.
+func (wf *wireFile) ref(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return wf.pkg + "." + x.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok {
+			return pkg.Name + "." + x.Sel.Name
+		}
+	}
+	return ""
+}
+
+// newType returns the type T of the expression new(T).
+func newType(e ast.Expr) ast.Expr {
+	call, ok := e.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+	if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "new" {
+		return nil
+	}
+	return call.Args[0]
+}
+
+// injector adds the injector fn, whose body calls wire.Build, with the
+// providers it is built from as a scope.
+func (wp *wireParser) injector(wf *wireFile, fn *ast.FuncDecl, build *ast.CallExpr) {
+	key := wf.pkg + "." + fn.Name.Name
+	inj := wp.dg.add(&diProvider{key: key, name: fn.Name.Name, module: wf.pkg, kind: "injector"})
+	if res := fn.Type.Results; res != nil && len(res.List) > 0 {
+		inj.params = append(inj.params, wf.typeName(res.List[0].Type))
+	}
+	scope := []string{key}
+	for _, field := range fn.Type.Params.List {
+		t := wf.typeName(field.Type)
+		in := wp.dg.add(&diProvider{key: key + "\x00" + t, name: t, module: wf.pkg, kind: "input", results: []string{t}})
+		scope = append(scope, in.key)
+	}
+	scope = append(scope, wp.expand(wf, build.Args, wf.pkg, make(map[string]bool))...)
+	wp.dg.scopes = append(wp.dg.scopes, scope)
+}
+
+// expand adds the providers listed in args, found in the file wf and
+// part of module, and returns their keys. Provider sets are expanded
+// recursively, each as a module of its own.
+func (wp *wireParser) expand(wf *wireFile, args []ast.Expr, module string, visited map[string]bool) []string {
+	var keys []string
+	for _, arg := range args {
+		if ref := wf.ref(arg); ref != "" {
+			if set, ok := wp.sets[ref]; ok {
+				if !visited[ref] {
+					visited[ref] = true
+					keys = append(keys, wp.expand(wp.file[ref], set.Args, ref, visited)...)
+				}
+				continue
+			}
+			keys = append(keys, wp.provider(wf, ref, module).key)
+			continue
+		}
+
+		call, ok := arg.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		switch {
+		case wf.isWire(call, "Bind") && len(call.Args) == 2:
+			iface, impl := newType(call.Args[0]), newType(call.Args[1])
+			if iface == nil || impl == nil {
+				continue
+			}
+			it, ct := wf.typeName(iface), wf.typeName(impl)
+			p := wp.dg.add(&diProvider{
+				key: "bind\x00" + it + "\x00" + ct, name: it + " ← " + ct, module: module,
+				kind: "bind", params: []string{ct}, results: []string{it},
+			})
+			keys = append(keys, p.key)
+
+		case (wf.isWire(call, "Struct") || wf.isWire(call, "FieldsOf")) && len(call.Args) > 0:
+			t := newType(call.Args[0])
+			if t == nil {
+				continue
+			}
+			tn := wf.typeName(t)
+			st := tn
+			if star, ok := t.(*ast.StarExpr); ok {
+				st = wf.typeName(star.X)
+			}
+			var fields []string
+			for _, f := range call.Args[1:] {
+				if lit, ok := f.(*ast.BasicLit); ok {
+					name, _ := strconv.Unquote(lit.Value)
+					fields = append(fields, name)
+				}
+			}
+			ft := wp.fieldTypes(st, fields)
+			p := &diProvider{module: module}
+			if wf.isWire(call, "Struct") {
+				p.key, p.name = "struct\x00"+tn, tn
+				p.params, p.results = ft, []string{st, "*" + st}
+			} else {
+				p.key, p.name = "fields\x00"+tn, "fields of "+tn
+				p.params, p.results = []string{tn}, ft
+			}
+			keys = append(keys, wp.dg.add(p).key)
+
+		case wf.isWire(call, "Value") && len(call.Args) == 1:
+			p := &diProvider{key: "value\x00" + types.ExprString(call.Args[0]), name: types.ExprString(call.Args[0]), module: module}
+			switch v := call.Args[0].(type) {
+			case *ast.CompositeLit:
+				p.results = []string{wf.typeName(v.Type)}
+			case *ast.UnaryExpr:
+				if lit, ok := v.X.(*ast.CompositeLit); ok && v.Op == token.AND {
+					p.results = []string{"*" + wf.typeName(lit.Type)}
+				}
+			}
+			keys = append(keys, wp.dg.add(p).key)
+
+		case wf.isWire(call, "InterfaceValue") && len(call.Args) == 2:
+			if t := newType(call.Args[0]); t != nil {
+				tn := wf.typeName(t)
+				p := wp.dg.add(&diProvider{key: "value\x00" + tn, name: types.ExprString(call.Args[1]), module: module, results: []string{tn}})
+				keys = append(keys, p.key)
+			}
+		}
+	}
+	return keys
+}
+
+// provider adds the provider function ref, its signature taken from
+// the parsed sources when they declare it.
+func (wp *wireParser) provider(wf *wireFile, ref, module string) *diProvider {
+	name := ref
+	if strings.HasPrefix(ref, wf.pkg+".") {
+		name = ref[len(wf.pkg)+1:]
+	}
+	p := &diProvider{key: ref, name: name, module: module}
+	fn, ok := wp.funcs[ref]
+	if !ok {
+		return wp.dg.add(p)
+	}
+	df := wp.file[ref]
+	for _, field := range fn.Type.Params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			p.params = append(p.params, df.typeName(field.Type))
+		}
+	}
+	if res := fn.Type.Results; res != nil && len(res.List) > 0 {
+		p.results = []string{df.typeName(res.List[0].Type)}
+	}
+	return wp.dg.add(p)
+}
+
+// fieldTypes returns the types of the named fields, or of all the
+// fields for "*", of the struct type t, skipping fields tagged
+// wire:"-".
+func (wp *wireParser) fieldTypes(t string, fields []string) []string {
+	st, ok := wp.structs[t]
+	if !ok {
+		return nil
+	}
+	df := wp.file[t]
+	all := len(fields) == 1 && fields[0] == "*"
+	var ft []string
+	for _, f := range st.Fields.List {
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			if reflect.StructTag(tag).Get("wire") == "-" {
+				continue
+			}
+		}
+		for _, name := range f.Names {
+			for _, want := range fields {
+				if all || want == name.Name {
+					ft = append(ft, df.typeName(f.Type))
+					break
+				}
+			}
+		}
+	}
+	return ft
+}
@@ -0,0 +1,214 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Document is a draw.io file: an mxfile element holding one or more
+// named pages, each a diagram of its own. Marshal a Document instead
+// of a GraphModel to write files draw.io opens with named pages.
+type Document struct {
+	// Host, Modified, Agent, Version and Type describe the
+	// application which wrote the file; they are kept as read.
+	Host     string
+	Modified string
+	Agent    string
+	Version  string
+	Type     string
+	// Compressed makes MarshalXML write the pages in the compressed
+	// format draw.io saves files in by default.
+	Compressed bool
+	// Stylesheet holds the named styles the file defines, e.g. when it
+	// is a template; nil if it defines none.
+	Stylesheet *Stylesheet
+
+	pages []*Page
+}
+
+// A Page is a named page of a Document.
+type Page struct {
+	// ID identifies the page in links between pages, e.g.
+	// "data:page/id,<ID>".
+	ID    string
+	Name  string
+	Model *GraphModel
+}
+
+// documentXML mirrors Document for encoding/xml.
+type documentXML struct {
+	XMLName    xml.Name    `xml:"mxfile"`
+	Host       string      `xml:"host,attr,omitempty"`
+	Modified   string      `xml:"modified,attr,omitempty"`
+	Agent      string      `xml:"agent,attr,omitempty"`
+	Version    string      `xml:"version,attr,omitempty"`
+	Type       string      `xml:"type,attr,omitempty"`
+	Stylesheet *Stylesheet `xml:"mxStylesheet"`
+	Pages      []pageXML   `xml:"diagram"`
+}
+
+// pageXML mirrors Page for encoding/xml. Content holds the text of
+// compressed pages.
+type pageXML struct {
+	ID      string      `xml:"id,attr,omitempty"`
+	Name    string      `xml:"name,attr,omitempty"`
+	Model   *GraphModel `xml:"mxGraphModel"`
+	Content string      `xml:",chardata"`
+}
+
+// NewDocument returns an empty document.
+func NewDocument() *Document {
+	return &Document{Host: "graw"}
+}
+
+// AddPage appends a page named name showing g to d and returns it.
+// The page refers to g, so later changes to g are part of the page.
+// An empty name is replaced by "Page-<n>" as in draw.io.
+func (d *Document) AddPage(name string, g *GraphModel) *Page {
+	if name == "" {
+		name = "Page-" + strconv.Itoa(len(d.pages)+1)
+	}
+	taken := make(idSet, len(d.pages))
+	for _, p := range d.pages {
+		taken[p.ID] = true
+	}
+	p := &Page{ID: taken.unique("page-" + strconv.Itoa(len(d.pages)+1)), Name: name, Model: g}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the pages of d in order.
+func (d *Document) Pages() []*Page {
+	return append([]*Page(nil), d.pages...)
+}
+
+// Page returns the first page of d named name, or nil.
+func (d *Document) Page(name string) *Page {
+	for _, p := range d.pages {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// MarshalXML encodes d as an mxfile element with its stylesheet, if
+// any, and a diagram element per page. It implements xml.Marshaler
+// interface.
+func (d *Document) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	aux := documentXML{
+		Host:       d.Host,
+		Modified:   d.Modified,
+		Agent:      d.Agent,
+		Version:    d.Version,
+		Type:       d.Type,
+		Stylesheet: d.Stylesheet,
+	}
+	for _, p := range d.pages {
+		page := pageXML{ID: p.ID, Name: p.Name, Model: p.Model}
+		if d.Compressed && p.Model != nil {
+			text, err := EncodeCompressed(p.Model)
+			if err != nil {
+				return err
+			}
+			page.Model, page.Content = nil, text
+		}
+		aux.Pages = append(aux.Pages, page)
+	}
+	return e.EncodeElement(aux, xml.StartElement{Name: xml.Name{Local: "mxfile"}})
+}
+
+// UnmarshalXML decodes an mxfile element. Compressed pages are
+// inflated within DefaultParseOptions, as xml.Unmarshal cannot pass
+// any; use ParseDocumentWithOptions to tune them. It implements
+// xml.Unmarshaler interface.
+func (d *Document) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	return d.decode(dec, start, DefaultParseOptions)
+}
+
+// decode decodes an mxfile element, inflating compressed pages within
+// o.
+func (d *Document) decode(dec *xml.Decoder, start xml.StartElement, o ParseOptions) error {
+	var aux documentXML
+	if err := dec.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	*d = Document{
+		Host:       aux.Host,
+		Modified:   aux.Modified,
+		Agent:      aux.Agent,
+		Version:    aux.Version,
+		Type:       aux.Type,
+		Stylesheet: aux.Stylesheet,
+	}
+	for i, p := range aux.Pages {
+		if p.Model == nil && strings.TrimSpace(p.Content) != "" {
+			g, err := decodeCompressed(p.Content, o)
+			if err != nil {
+				return fmt.Errorf("page %q: %w", p.Name, err)
+			}
+			p.Model = g
+		}
+		if p.Model == nil {
+			g := NewGraph()
+			p.Model = &g
+		}
+		if p.Name == "" {
+			p.Name = "Page-" + strconv.Itoa(i+1)
+		}
+		d.pages = append(d.pages, &Page{ID: p.ID, Name: p.Name, Model: p.Model})
+	}
+	return nil
+}
+
+// ParseDocument reads a draw.io file like Parse, within
+// DefaultParseOptions, returning all of its pages. A bare mxGraphModel
+// document is read as a document with a single page.
+func ParseDocument(r io.Reader) (*Document, error) {
+	return ParseDocumentWithOptions(r, DefaultParseOptions)
+}
+
+// ParseDocumentWithOptions reads a draw.io file like ParseDocument,
+// within the limits of o, which apply to the file and to each of its
+// compressed pages. Errors for documents breaking a limit wrap
+// ErrLimit, or are ErrDTD.
+func ParseDocumentWithOptions(r io.Reader, o ParseOptions) (*Document, error) {
+	data, err := o.read(r)
+	if err != nil {
+		return nil, fmt.Errorf("graw: ParseDocument: %w", err)
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("graw: ParseDocument: no mxfile element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graw: ParseDocument: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		d := NewDocument()
+		switch start.Name.Local {
+		case "mxfile":
+			if err := d.decode(dec, start, o); err != nil {
+				return nil, fmt.Errorf("graw: ParseDocument: %w", err)
+			}
+		case "mxGraphModel":
+			var g GraphModel
+			if err := dec.DecodeElement(&g, &start); err != nil {
+				return nil, fmt.Errorf("graw: ParseDocument: %v", err)
+			}
+			d.AddPage("", &g)
+		default:
+			return nil, fmt.Errorf("graw: ParseDocument: unexpected element <%s>", start.Name.Local)
+		}
+		return d, nil
+	}
+}
@@ -0,0 +1,152 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testDocument returns the XML of a document with two pages.
+func testDocument(t *testing.T, compressed bool) []byte {
+	t.Helper()
+	d := NewDocument()
+	d.Compressed = compressed
+	for _, name := range []string{"Overview", "Details"} {
+		g := NewGraph()
+		g.Add(NewShape(strings.ToLower(name), "1").Label(name))
+		d.AddPage(name, &g)
+	}
+	out, err := xml.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestParseDocument(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		d, err := ParseDocument(bytes.NewReader(testDocument(t, compressed)))
+		if err != nil {
+			t.Fatalf("compressed %v: %v", compressed, err)
+		}
+		pages := d.Pages()
+		if len(pages) != 2 || pages[0].Name != "Overview" || pages[1].Name != "Details" {
+			t.Fatalf("compressed %v: got pages %+v", compressed, pages)
+		}
+		if c := pages[1].Model.FindByID("details"); c == nil || c.Value != "Details" {
+			t.Errorf("compressed %v: page Details lost its cell", compressed)
+		}
+	}
+
+	d, err := ParseDocument(strings.NewReader(`<mxGraphModel><root><mxCell id="0"/></root></mxGraphModel>`))
+	if err != nil || len(d.Pages()) != 1 {
+		t.Errorf("bare model: got %v, %v", d, err)
+	}
+}
+
+func TestParseDocumentWithOptions(t *testing.T) {
+	plain, compressed := testDocument(t, false), testDocument(t, true)
+	tests := []struct {
+		name string
+		data []byte
+		o    ParseOptions
+		err  error
+	}{
+		{"within limits", plain, DefaultParseOptions, nil},
+		{"too large", plain, ParseOptions{MaxSize: len(plain) - 1}, ErrLimit},
+		{"just small enough", plain, ParseOptions{MaxSize: len(plain)}, nil},
+		{"too deep", plain, ParseOptions{MaxDepth: 3}, ErrLimit},
+		{"too many tokens", plain, ParseOptions{MaxTokens: 10}, ErrLimit},
+		{"page inflating too much", compressed, ParseOptions{DecodeLimits: DecodeLimits{MaxInflatedSize: 100}}, ErrLimit},
+		{"page with too many elements", compressed, ParseOptions{DecodeLimits: DecodeLimits{MaxElements: 5}}, ErrLimit},
+		{"DTD", append([]byte(`<!DOCTYPE mxfile [<!ENTITY x "x">]>`), plain...), ParseOptions{}, ErrDTD},
+		{"DTD allowed", append([]byte(`<!DOCTYPE mxfile>`), plain...), ParseOptions{AllowDTD: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDocumentWithOptions(bytes.NewReader(tt.data), tt.o)
+			if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDocumentPages(t *testing.T) {
+	d := NewDocument()
+	g := NewGraph()
+	tests := []struct {
+		name, wantName, wantID string
+	}{
+		{"Overview", "Overview", "page-1"},
+		{"", "Page-2", "page-2"},
+		{"Overview", "Overview", "page-3"},
+	}
+	for _, tt := range tests {
+		p := d.AddPage(tt.name, &g)
+		if p.Name != tt.wantName || p.ID != tt.wantID || p.Model != &g {
+			t.Errorf("AddPage(%q): got %+v, want %s %s", tt.name, p, tt.wantName, tt.wantID)
+		}
+	}
+	if p := d.Page("Overview"); p == nil || p.ID != "page-1" {
+		t.Errorf("Page: got %+v, want the first page", p)
+	}
+	if p := d.Page("none"); p != nil {
+		t.Errorf("Page: got %+v", p)
+	}
+	// Pages returns a copy
+	d.Pages()[0] = nil
+	if d.Pages()[0] == nil {
+		t.Error("Pages: got the pages of the document changed")
+	}
+
+	out, err := xml.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<mxfile host="graw">`, `<diagram id="page-1" name="Overview"><mxGraphModel`, `<diagram id="page-2" name="Page-2">`} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("%s does not contain %s", out, want)
+		}
+	}
+}
+
+func TestParseDocumentAttributes(t *testing.T) {
+	d, err := ParseDocument(strings.NewReader(`<?xml version="1.0"?>
+<mxfile host="app.diagrams.net" modified="2025-01-01T00:00:00.000Z" agent="x" version="24.0.0" type="device">
+  <diagram id="a" name="One"><mxGraphModel><root><mxCell id="0"/><mxCell id="1" parent="0"/></root></mxGraphModel></diagram>
+  <diagram id="b"></diagram>
+</mxfile>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Host != "app.diagrams.net" || d.Modified != "2025-01-01T00:00:00.000Z" || d.Agent != "x" || d.Version != "24.0.0" || d.Type != "device" {
+		t.Errorf("got %+v", d)
+	}
+	pages := d.Pages()
+	if len(pages) != 2 || pages[0].Name != "One" || pages[1].Name != "Page-2" || pages[1].ID != "b" {
+		t.Fatalf("got pages %+v", pages)
+	}
+	if len(pages[1].Model.Root) != 2 {
+		t.Errorf("got an empty page with %d cells, want a new graph", len(pages[1].Model.Root))
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"empty", ``, "graw: ParseDocument: no mxfile element"},
+		{"other element", `<svg/>`, "graw: ParseDocument: unexpected element <svg>"},
+		{"syntax", `<mxfile><diagram>`, "graw: ParseDocument: "},
+		{"bad page", `<mxfile><diagram name="p">not compressed</diagram></mxfile>`, `graw: ParseDocument: page "p": `},
+	}
+	for _, tt := range tests {
+		_, err := ParseDocument(strings.NewReader(tt.in))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+)
+
+// Size of the cells of dependency structure matrices.
+const dsmCellSize = 30
+
+// NewDSM returns the dependency structure matrix of g as a draw.io
+// table: a row and a column per vertex, the cell of row i and column j
+// counting the edges from vertex i to vertex j. Rows are numbered and
+// labeled with their vertex, columns numbered alike. The vertices are
+// ordered so that dependencies come first, leaving the marks below the
+// diagonal; marks above it are feedback, drawn in red, and vertices in
+// a cycle form a block shaded yellow. It suits graphs too dense to be
+// read as boxes and arrows.
+func NewDSM(g *GraphModel) (*GraphModel, error) {
+	var nodes []string
+	labels := make(map[string]string)
+	known := make(map[string]bool)
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.isVertex() {
+			known[c.ID] = true
+			nodes = append(nodes, c.ID)
+			labels[c.ID] = labelText(c)
+			if labels[c.ID] == "" {
+				labels[c.ID] = c.ID
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("graw: NewDSM: no vertices")
+	}
+	deps := make(map[string][]string)
+	counts := make(map[[2]string]int)
+	for i := range g.Root {
+		e := g.Root[i]
+		if !e.isEdge() || !known[e.Source] || !known[e.Target] {
+			continue
+		}
+		if counts[[2]string{e.Source, e.Target}] == 0 {
+			deps[e.Source] = append(deps[e.Source], e.Target)
+		}
+		counts[[2]string{e.Source, e.Target}]++
+	}
+
+	comp, cyclic, column := dependencyColumns(nodes, deps)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if column[a] != column[b] {
+			return column[a] > column[b]
+		}
+		return comp[a] < comp[b]
+	})
+
+	m := NewGraph()
+	taken := m.idSet()
+	labelWidth := dsmCellSize
+	for i, n := range nodes {
+		w, _ := DefaultMeasurer.Measure(strconv.Itoa(i+1)+". "+labels[n], Font{Size: defaultFontSize})
+		labelWidth = maxInt(labelWidth, int(w)+16)
+	}
+	width := labelWidth + len(nodes)*dsmCellSize
+
+	table := NewShape(taken.unique("dsm"), rootCellID)
+	table.Style = Style{Attributes: map[string]string{
+		"shape":       "table",
+		"startSize":   "0",
+		"container":   "1",
+		"collapsible": "0",
+		"childLayout": "tableLayout",
+		"html":        "1",
+	}}
+	table.Geometry.X, table.Geometry.Y = 10, 10
+	table.Geometry.setSize(width, (len(nodes)+1)*dsmCellSize)
+	m.Add(table)
+
+	// cell adds the cell of a row at x
+	cell := func(row *Cell, x, w int, value, fill string) {
+		c := NewShape(taken.unique(row.ID+"-"+strconv.Itoa(x)), row.ID)
+		c.Value = value
+		c.Style = Style{Attributes: map[string]string{
+			"shape":         "partialRectangle",
+			"html":          "1",
+			"whiteSpace":    "wrap",
+			"connectable":   "0",
+			"overflow":      "hidden",
+			"fillColor":     "none",
+			"top":           "0",
+			"left":          "0",
+			"bottom":        "0",
+			"right":         "0",
+			"pointerEvents": "1",
+		}}
+		if fill != "" {
+			c.Style.set("fillColor", fill)
+		}
+		c.Geometry.X = x
+		c.Geometry.setSize(w, dsmCellSize)
+		m.Add(c)
+	}
+	newRow := func(base string, y int) *Cell {
+		row := NewShape(taken.unique(base), table.ID)
+		row.Style = Style{Attributes: map[string]string{
+			"shape":          "tableRow",
+			"horizontal":     "0",
+			"startSize":      "0",
+			"swimlaneHead":   "0",
+			"swimlaneBody":   "0",
+			"top":            "0",
+			"left":           "0",
+			"bottom":         "0",
+			"right":          "0",
+			"collapsible":    "0",
+			"dropTarget":     "0",
+			"fillColor":      "none",
+			"points":         "[[0,0.5],[1,0.5]]",
+			"portConstraint": "eastwest",
+		}}
+		row.Geometry.Y = y
+		row.Geometry.setSize(width, dsmCellSize)
+		m.Add(row)
+		return row
+	}
+
+	header := newRow("dsm-header", 0)
+	cell(header, 0, labelWidth, "", "#f5f5f5")
+	for j := range nodes {
+		cell(header, labelWidth+j*dsmCellSize, dsmCellSize, "<b>"+strconv.Itoa(j+1)+"</b>", "#f5f5f5")
+	}
+	for i, n := range nodes {
+		row := newRow("dsm-"+n, (i+1)*dsmCellSize)
+		cell(row, 0, labelWidth, strconv.Itoa(i+1)+". "+html.EscapeString(labels[n]), "#f5f5f5")
+		for j, d := range nodes {
+			var value, fill string
+			if k := counts[[2]string{n, d}]; k > 0 {
+				value = strconv.Itoa(k)
+				fill = "#dae8fc"
+				if j > i {
+					fill = "#f8cecc"
+				}
+			} else if comp[n] == comp[d] && cyclic[comp[n]] {
+				fill = "#fff2cc"
+			}
+			if i == j {
+				value, fill = "", "#999999"
+			}
+			cell(row, labelWidth+j*dsmCellSize, dsmCellSize, value, fill)
+		}
+	}
+	return &m, nil
+}
@@ -0,0 +1,115 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+// dsmGrid returns the values and fill colors of the cells of the
+// rows of a dependency structure matrix, the header row first.
+func dsmGrid(m *GraphModel) (values, fills [][]string) {
+	rows := make(map[string]int)
+	for _, c := range m.Root {
+		switch c.Style.Attributes["shape"] {
+		case "tableRow":
+			rows[c.ID] = len(values)
+			values, fills = append(values, nil), append(fills, nil)
+		case "partialRectangle":
+			i := rows[c.ParentID]
+			values[i] = append(values[i], c.Value)
+			fills[i] = append(fills[i], c.Style.Attributes["fillColor"])
+		}
+	}
+	return values, fills
+}
+
+func TestNewDSM(t *testing.T) {
+	const (
+		no   = "none"
+		diag = "#999999"
+		head = "#f5f5f5"
+		dep  = "#dae8fc"
+		back = "#f8cecc"
+		loop = "#fff2cc"
+	)
+	tests := []struct {
+		name   string
+		edges  [][2]string
+		values [][]string
+		fills  [][]string
+	}{
+		{
+			name:  "chain",
+			edges: [][2]string{{"a", "b"}, {"b", "c"}},
+			values: [][]string{
+				{"", "<b>1</b>", "<b>2</b>", "<b>3</b>"},
+				{"1. c", "", "", ""},
+				{"2. b", "1", "", ""},
+				{"3. a", "", "1", ""},
+			},
+			fills: [][]string{
+				{head, head, head, head},
+				{head, diag, no, no},
+				{head, dep, diag, no},
+				{head, no, dep, diag},
+			},
+		},
+		{
+			name:  "cycle",
+			edges: [][2]string{{"a", "b"}, {"a", "b"}, {"b", "c"}, {"c", "b"}, {"d", "a"}},
+			values: [][]string{
+				{"", "<b>1</b>", "<b>2</b>", "<b>3</b>", "<b>4</b>"},
+				{"1. b", "", "1", "", ""},
+				{"2. c", "1", "", "", ""},
+				{"3. a", "2", "", "", ""},
+				{"4. d", "", "", "1", ""},
+			},
+			fills: [][]string{
+				{head, head, head, head, head},
+				{head, diag, back, no, no},
+				{head, dep, diag, no, no},
+				{head, dep, no, diag, no},
+				{head, no, no, dep, diag},
+			},
+		},
+		{
+			name:  "shaded block",
+			edges: [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+			values: [][]string{
+				{"", "<b>1</b>", "<b>2</b>", "<b>3</b>"},
+				{"1. a", "", "1", ""},
+				{"2. b", "", "", "1"},
+				{"3. c", "1", "", ""},
+			},
+			fills: [][]string{
+				{head, head, head, head},
+				{head, diag, back, loop},
+				{head, loop, diag, back},
+				{head, dep, loop, diag},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraph(tt.edges...)
+			m, err := NewDSM(&g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, fills := dsmGrid(m)
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("got values %q, want %q", values, tt.values)
+			}
+			if !reflect.DeepEqual(fills, tt.fills) {
+				t.Errorf("got fills %q, want %q", fills, tt.fills)
+			}
+			if iss := ValidateStyles(m); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+
+	if _, err := NewDSM(&GraphModel{}); err == nil {
+		t.Error("NewDSM without vertices: got no error")
+	}
+}
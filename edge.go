@@ -0,0 +1,83 @@
+package graw
+
+import "math"
+
+// EdgeOption configures the edges created by ConnectCells.
+type EdgeOption func(e *Cell)
+
+// WithLabel sets the label of the edge.
+func WithLabel(label string) EdgeOption {
+	return func(e *Cell) {
+		e.Value = label
+	}
+}
+
+// WithStyle sets the style attribute key of the edge to value, e.g.
+// WithStyle("dashed", "1").
+func WithStyle(key, value string) EdgeOption {
+	return func(e *Cell) {
+		e.Style.set(key, value)
+	}
+}
+
+// ConnectCells adds to g an edge from source to target, two cells
+// already added to g, and returns it. The edge is created by NewEdge
+// with an ID derived from those of its terminals, in the closest
+// container or layer holding both of them, and configured by opts in
+// order. It is not named Connect as GraphModel has a field of that
+// name, the connect attribute of the model.
+func (g *GraphModel) ConnectCells(source, target *Cell, opts ...EdgeOption) *Cell {
+	e := NewEdge(g.uniqueID(source.ID+"-"+target.ID), g.commonParent(source.ID, target.ID), source.ID, target.ID)
+	for _, opt := range opts {
+		opt(e)
+	}
+	g.Add(e)
+	return g.Root[len(g.Root)-1]
+}
+
+// commonParent returns the ID of the closest cell holding both the
+// cells a and b, or the default layer when they have none in common.
+func (g *GraphModel) commonParent(a, b string) string {
+	ancestors := make(map[string]bool)
+	for c, depth := g.cell(a), 0; c != nil && depth < 64; c, depth = g.cell(c.ParentID), depth+1 {
+		ancestors[c.ParentID] = true
+	}
+	for c, depth := g.cell(b), 0; c != nil && depth < 64; c, depth = g.cell(c.ParentID), depth+1 {
+		if c.ParentID == topCellId {
+			break
+		}
+		if ancestors[c.ParentID] {
+			return c.ParentID
+		}
+	}
+	return rootCellID
+}
+
+// AddEdgeLabel adds to g a label reading text on edge, an edge already
+// added to g, and returns it. The label is a child vertex of the edge
+// which edges cannot connect to, placed at position along the edge:
+// -1 at its source, 0 in its middle and 1 at its target. Unlike the
+// value of the edge, such labels can be moved along the edge in
+// draw.io and an edge can have several of them.
+func (g *GraphModel) AddEdgeLabel(edge *Cell, text string, position float64) *Cell {
+	l := newCell(g.uniqueID(edge.ID+"-label"), edge.ID)
+	l.Value = text
+	l.Vertex = "1"
+	l.Connectable = "0"
+	l.Style = Style{Attributes: map[string]string{
+		"edgeLabel":     "",
+		"html":          "1",
+		"align":         "center",
+		"verticalAlign": "middle",
+		"resizable":     "0",
+		"points":        "[]",
+	}}
+	l.Geometry = &Geometry{
+		Relative: "1",
+		As:       "geometry",
+		Position: math.Max(-1, math.Min(1, position)),
+		Point:    &Point{As: "offset"},
+	}
+	g.Add(l)
+	return g.Root[len(g.Root)-1]
+}
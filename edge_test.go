@@ -0,0 +1,130 @@
+package graw
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestConnectCells(t *testing.T) {
+	g := NewGraph()
+	g.Add(NewLayer("2", "other"))
+	for _, c := range []*Cell{
+		NewShape("a", "1"),
+		NewShape("b", "1"),
+		newContainer("box", "1", "box"),
+		newContainer("inner", "box", "inner"),
+		NewShape("c", "box"),
+		NewShape("d", "inner"),
+		NewShape("e", "2"),
+	} {
+		g.Add(c)
+	}
+	tests := []struct {
+		name           string
+		source, target string
+		opts           []EdgeOption
+		id, parent     string
+		value          string
+		style          map[string]string
+	}{
+		{name: "layer", source: "a", target: "b", id: "a-b", parent: "1"},
+		{name: "again", source: "a", target: "b", id: "a-b-2", parent: "1"},
+		{name: "container", source: "c", target: "d", id: "c-d", parent: "box"},
+		{name: "out of container", source: "d", target: "a", id: "d-a", parent: "1"},
+		{name: "into container", source: "box", target: "c", id: "box-c", parent: "1"},
+		{name: "layers", source: "a", target: "e", id: "a-e", parent: "1"},
+		{name: "layers reversed", source: "e", target: "d", id: "e-d", parent: "1"},
+		{name: "self", source: "c", target: "c", id: "c-c", parent: "box"},
+		{
+			name: "options", source: "b", target: "a", id: "b-a", parent: "1",
+			opts:  []EdgeOption{WithLabel("calls"), WithStyle("dashed", "1"), WithStyle("rounded", "1")},
+			value: "calls",
+			style: map[string]string{"dashed": "1", "rounded": "1", "edgeStyle": "orthogonalEdgeStyle"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := g.ConnectCells(g.FindByID(tt.source), g.FindByID(tt.target), tt.opts...)
+			if e != g.FindByID(tt.id) {
+				t.Fatalf("got edge %q, want %q added to the model", e.ID, tt.id)
+			}
+			if e.ParentID != tt.parent || e.Source != tt.source || e.Target != tt.target {
+				t.Errorf("got parent %q, %s->%s, want %q", e.ParentID, e.Source, e.Target, tt.parent)
+			}
+			if e.Value != tt.value {
+				t.Errorf("got label %q, want %q", e.Value, tt.value)
+			}
+			for k, want := range tt.style {
+				if got := e.Style.Attributes[k]; got != want {
+					t.Errorf("got %s %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+	if iss := ValidateStyles(&g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestAddEdgeLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		position float64
+		id       string
+		x        string
+	}{
+		{"middle", 0, "a-b-label", ""},
+		{"source", -1, "a-b-label-2", ` x="-1"`},
+		{"near target", 0.5, "a-b-label-3", ` x="0.5"`},
+		{"clamped", 3, "a-b-label-4", ` x="1"`},
+	}
+	g := newTestGraph([2]string{"a", "b"})
+	e := g.FindByID("a-b")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := g.AddEdgeLabel(e, "a & b", tt.position)
+			if l != g.FindByID(tt.id) {
+				t.Fatalf("got label %q, want %q added to the model", l.ID, tt.id)
+			}
+			if l.ParentID != "a-b" || !l.isVertex() || l.Connectable != "0" || l.Value != "a & b" {
+				t.Errorf("got %+v", l)
+			}
+			out, err := xml.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := `id="` + tt.id + `" value="a &amp; b" style="edgeLabel;align=center;html=1;points=[];resizable=0;verticalAlign=middle;" parent="a-b" vertex="1" connectable="0"><mxGeometry` + tt.x + ` relative="1" as="geometry"><mxPoint as="offset"></mxPoint></mxGeometry>`
+			if !strings.Contains(string(out), want) {
+				t.Errorf("%s does not contain %s", out, want)
+			}
+
+			var h GraphModel
+			if err := xml.Unmarshal(out, &h); err != nil {
+				t.Fatal(err)
+			}
+			if got := h.FindByID(tt.id).Geometry.Position; got != math.Max(-1, math.Min(1, tt.position)) {
+				t.Errorf("got position %v after a round trip", got)
+			}
+		})
+	}
+	if iss := ValidateStyles(&g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func BenchmarkConnectCells(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		g := NewGraph()
+		cells := make([]*Cell, 2000)
+		for j := range cells {
+			cells[j] = NewShape("n"+strconv.Itoa(j), "1")
+			g.Add(cells[j])
+		}
+		for j := 1; j < len(cells); j++ {
+			g.ConnectCells(cells[j-1], cells[j])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package graw
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Remove removes the cell id from g. It fails when the cell does not
+// exist or other cells still refer to it, as children or as edges
+// connecting it, so that the model never refers to missing cells; use
+// RemoveCascade to remove those as well.
+func (g *GraphModel) Remove(id string) error {
+	i := g.cellIndex(id)
+	if i < 0 {
+		return fmt.Errorf("graw: Remove: no cell %q", id)
+	}
+	for _, c := range g.Root {
+		if c.ParentID == id || c.Source == id || c.Target == id {
+			return fmt.Errorf("graw: Remove: cell %q is referred to by cell %q", id, c.ID)
+		}
+	}
+	g.Root = append(g.Root[:i], g.Root[i+1:]...)
+	g.reindex()
+	return nil
+}
+
+// RemoveCascade removes the cell id from g together with the cells
+// nested in it and the edges connected to any removed cell, including
+// their labels, and returns the IDs of the removed cells in document
+// order.
+func (g *GraphModel) RemoveCascade(id string) ([]string, error) {
+	if g.cellIndex(id) < 0 {
+		return nil, fmt.Errorf("graw: RemoveCascade: no cell %q", id)
+	}
+	removed := map[string]bool{id: true}
+	// Nested cells and edges may refer to cells found later in Root;
+	// repeat until no more cells join.
+	for changed := true; changed; {
+		changed = false
+		for _, c := range g.Root {
+			if !removed[c.ID] && (removed[c.ParentID] || removed[c.Source] || removed[c.Target]) {
+				removed[c.ID] = true
+				changed = true
+			}
+		}
+	}
+	var ids []string
+	root := g.Root[:0]
+	for _, c := range g.Root {
+		if removed[c.ID] {
+			ids = append(ids, c.ID)
+			continue
+		}
+		root = append(root, c)
+	}
+	g.Root = root
+	g.reindex()
+	return ids, nil
+}
+
+// Replace puts c in place of the cell id of g, keeping its position in
+// the document order. When c has another ID, the children and edges
+// referring to the old cell are updated to refer to c; the new ID must
+// not be in use by another cell.
+func (g *GraphModel) Replace(id string, c *Cell) error {
+	i := g.cellIndex(id)
+	if i < 0 {
+		return fmt.Errorf("graw: Replace: no cell %q", id)
+	}
+	if c.ID != id {
+		if c.ID == "" {
+			return fmt.Errorf("graw: Replace: cell without ID")
+		}
+		if g.cellIndex(c.ID) >= 0 {
+			return fmt.Errorf("graw: Replace: ID %q in use", c.ID)
+		}
+		for j := range g.Root {
+			o := g.Root[j]
+			if o.ParentID == id {
+				o.ParentID = c.ID
+			}
+			if o.Source == id {
+				o.Source = c.ID
+			}
+			if o.Target == id {
+				o.Target = c.ID
+			}
+		}
+	}
+	g.Root[i] = c
+	g.reindex()
+	return nil
+}
+
+// ErrDuplicateID is wrapped by the errors of AddChecked refusing a
+// cell whose ID is in use.
+var ErrDuplicateID = errors.New("graw: duplicate cell ID")
+
+// DuplicatePolicy tells AddChecked what to do with a cell whose ID is
+// already in use.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError refuses the cell with an error wrapping
+	// ErrDuplicateID.
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateRename adds the cell with its ID followed by the first
+	// free numeric suffix, e.g. "db-2" for "db".
+	DuplicateRename
+	// DuplicateReplace puts the cell in place of the one with the same
+	// ID, like Replace.
+	DuplicateReplace
+)
+
+// AddChecked adds c to g like Add, handling a cell whose ID is already
+// in use according to policy, as draw.io mangles diagrams holding
+// several cells with the same ID. Like Add, g holds c itself, so
+// DuplicateRename changes the ID of c. It returns c, or an error for
+// cells without ID.
+func (g *GraphModel) AddChecked(c *Cell, policy DuplicatePolicy) (*Cell, error) {
+	if c.ID == "" {
+		return nil, fmt.Errorf("graw: AddChecked: cell without ID")
+	}
+	if g.cellIndex(c.ID) >= 0 {
+		switch policy {
+		case DuplicateRename:
+			c.ID = g.uniqueID(c.ID)
+		case DuplicateReplace:
+			if err := g.Replace(c.ID, c); err != nil {
+				return nil, err
+			}
+			return c, nil
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateID, c.ID)
+		}
+	}
+	g.Add(c)
+	return c, nil
+}
+
+// DuplicateIDs returns the IDs used by more than one cell of g, e.g.
+// of a parsed file, in the order they first occur.
+func (g *GraphModel) DuplicateIDs() []string {
+	seen := make(map[string]int, len(g.Root))
+	var ids []string
+	for _, c := range g.Root {
+		seen[c.ID]++
+		if seen[c.ID] == 2 {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,288 @@
+package graw
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddChecked(t *testing.T) {
+	tests := []struct {
+		policy DuplicatePolicy
+		id     string
+		err    error
+		cells  int
+	}{
+		{DuplicateError, "", ErrDuplicateID, 3},
+		{DuplicateRename, "a-2", nil, 4},
+		{DuplicateReplace, "a", nil, 3},
+	}
+	for _, tt := range tests {
+		g := NewGraph()
+		g.Add(NewShape("a", "1"))
+		c := NewShape("a", "1")
+		got, err := g.AddChecked(c, tt.policy)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("policy %d: got error %v, want %v", tt.policy, err, tt.err)
+		}
+		if len(g.Root) != tt.cells {
+			t.Errorf("policy %d: got %d cells, want %d", tt.policy, len(g.Root), tt.cells)
+		}
+		if err != nil {
+			continue
+		}
+		if got != c || c.ID != tt.id || g.FindByID(tt.id) != c {
+			t.Errorf("policy %d: got %p with ID %q, want %p with ID %q held by g", tt.policy, got, c.ID, c, tt.id)
+		}
+	}
+}
+
+func TestAddKeepsPointer(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(g *GraphModel, c *Cell)
+	}{
+		{"Add", func(g *GraphModel, c *Cell) { g.Add(c) }},
+		{"AddChecked", func(g *GraphModel, c *Cell) { g.AddChecked(c, DuplicateError) }},
+		{"Replace", func(g *GraphModel, c *Cell) {
+			g.Add(NewShape("a", "1"))
+			g.Replace("a", c)
+		}},
+		{"AddToGroup", func(g *GraphModel, c *Cell) { g.AddToGroup(NewGroup("grp", "1"), c) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			c := NewShape("a", "1")
+			tt.add(&g, c)
+			c.Value = "changed"
+			if got := g.FindByID("a"); got != c || got.Value != "changed" {
+				t.Errorf("got %p with value %q, want %p with the changes after adding", got, got.Value, c)
+			}
+		})
+	}
+}
+
+// ids returns the IDs of the cells of g in document order.
+func ids(g *GraphModel) []string {
+	var ids []string
+	for _, c := range g.Root {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		want    []string
+		wantErr string
+	}{
+		{"edge", "a-b", []string{"0", "1", "a", "b", "c"}, ""},
+		{"missing", "x", nil, `graw: Remove: no cell "x"`},
+		{"connected", "a", nil, `graw: Remove: cell "a" is referred to by cell "a-b"`},
+		{"parent", "1", nil, `graw: Remove: cell "1" is referred to by cell "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraph([2]string{"a", "b"})
+			g.Add(NewShape("c", "1"))
+			before := ids(&g)
+			err := g.Remove(tt.id)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got error %v, want %q", err, tt.wantErr)
+				}
+				if got := ids(&g); !reflect.DeepEqual(got, before) {
+					t.Errorf("got %v after an error, want %v", got, before)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(&g); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if g.FindByID(tt.id) != nil || g.FindByID("c") == nil || g.FindByID("c").ID != "c" {
+				t.Error("got a stale index")
+			}
+		})
+	}
+}
+
+func TestRemoveCascade(t *testing.T) {
+	build := func() GraphModel {
+		g := newTestGraph([2]string{"a", "b"}, [2]string{"b", "c"})
+		grp := NewGroup("g", "1")
+		g.AddToGroup(grp, placedShape("x", 0, 0))
+		g.AddToGroup(grp, placedShape("y", 200, 0))
+		g.Add(newEdgeCell("x-y", "1", "x", "y"))
+		g.Add(newEdgeCell("y-a", "1", "y", "a"))
+		g.AddEdgeLabel(g.FindByID("a-b"), "calls", 0)
+		// An edge listed before the cell it connects.
+		g.Add(newEdgeCell("c-z", "1", "c", "z"))
+		g.Add(NewShape("z", "1"))
+		return g
+	}
+	tests := []struct {
+		id      string
+		removed []string
+		wantErr string
+	}{
+		{"a", []string{"a-b", "y-a", "a-b-label"}, ""},
+		{"g", []string{"g", "x", "y", "x-y", "y-a"}, ""},
+		{"z", []string{"c-z", "z"}, ""},
+		{"a-b", []string{"a-b", "a-b-label"}, ""},
+		{"missing", nil, `graw: RemoveCascade: no cell "missing"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			g := build()
+			if tt.id == "a" {
+				tt.removed = append([]string{"a"}, tt.removed...)
+			}
+			n := len(g.Root)
+			got, err := g.RemoveCascade(tt.id)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr || len(g.Root) != n {
+					t.Errorf("got error %v with %d cells, want %q", err, len(g.Root), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.removed) {
+				t.Errorf("got removed %v, want %v", got, tt.removed)
+			}
+			if len(g.Root) != n-len(tt.removed) {
+				t.Errorf("got %d cells, want %d", len(g.Root), n-len(tt.removed))
+			}
+			for _, c := range g.Root {
+				for _, ref := range []string{c.ParentID, c.Source, c.Target} {
+					if ref != "" && g.FindByID(ref) == nil {
+						t.Errorf("got %s referring to the removed cell %s", c.ID, ref)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		cell    *Cell
+		want    []string
+		edges   string
+		wantErr string
+	}{
+		{"same ID", "b", NewShape("b", "1").Label("B"), []string{"0", "1", "a", "b", "a-b", "b-a"}, "a->b b->a", ""},
+		{"new ID", "b", NewShape("n", "1").Label("N"), []string{"0", "1", "a", "n", "a-b", "b-a"}, "a->n n->a", ""},
+		{"layer", "1", NewLayer("L", "Layer"), []string{"0", "L", "a", "b", "a-b", "b-a"}, "a->b b->a", ""},
+		{"missing", "x", NewShape("x", "1"), nil, "", `graw: Replace: no cell "x"`},
+		{"ID in use", "b", NewShape("a", "1"), nil, "", `graw: Replace: ID "a" in use`},
+		{"no ID", "b", &Cell{Vertex: "1"}, nil, "", "graw: Replace: cell without ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraph([2]string{"a", "b"}, [2]string{"b", "a"})
+			before := ids(&g)
+			err := g.Replace(tt.id, tt.cell)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got error %v, want %q", err, tt.wantErr)
+				}
+				if got := ids(&g); !reflect.DeepEqual(got, before) {
+					t.Errorf("got %v after an error, want %v", got, before)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(&g); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := strings.Join(edgeList(&g), " "); got != tt.edges {
+				t.Errorf("got edges %q, want %q", got, tt.edges)
+			}
+			if g.FindByID(tt.cell.ID) != tt.cell {
+				t.Error("got a stale index")
+			}
+			for _, c := range g.Root {
+				if c.ParentID != "" && g.FindByID(c.ParentID) == nil {
+					t.Errorf("got %s in the missing cell %s", c.ID, c.ParentID)
+				}
+			}
+		})
+	}
+}
+
+func TestAddCheckedCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  DuplicatePolicy
+		cells   []string
+		want    []string
+		wantErr string
+	}{
+		{"free ID", DuplicateError, []string{"b"}, []string{"0", "1", "a", "b"}, ""},
+		{"error", DuplicateError, []string{"a"}, nil, `graw: duplicate cell ID: "a"`},
+		{"no ID", DuplicateRename, []string{""}, nil, "graw: AddChecked: cell without ID"},
+		{"renames", DuplicateRename, []string{"a", "a", "a-2"}, []string{"0", "1", "a", "a-2", "a-3", "a-2-2"}, ""},
+		{"replace in place", DuplicateReplace, []string{"a"}, []string{"0", "1", "a"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			g.Add(NewShape("a", "1"))
+			for _, id := range tt.cells {
+				_, err := g.AddChecked(NewShape(id, "1").Label("new"), tt.policy)
+				if tt.wantErr != "" {
+					if err == nil || err.Error() != tt.wantErr {
+						t.Errorf("got error %v, want %q", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := ids(&g); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := g.DuplicateIDs(); len(got) > 0 {
+				t.Errorf("got duplicate IDs %v", got)
+			}
+			if c := g.FindByID(tt.cells[0]); c.Value != "new" && tt.policy == DuplicateReplace {
+				t.Errorf("got %+v, want the new cell", c)
+			}
+		})
+	}
+}
+
+func TestDuplicateIDs(t *testing.T) {
+	tests := []struct {
+		ids  []string
+		want []string
+	}{
+		{[]string{"a", "b"}, nil},
+		{[]string{"b", "a", "b"}, []string{"b"}},
+		{[]string{"b", "a", "b", "a", "b"}, []string{"b", "a"}},
+		{[]string{"1"}, []string{"1"}},
+	}
+	for _, tt := range tests {
+		g := NewGraph()
+		for _, id := range tt.ids {
+			g.Add(NewShape(id, "1"))
+		}
+		if got := g.DuplicateIDs(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DuplicateIDs of %v: got %v, want %v", tt.ids, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+)
+
+// Dimensions of one-line diagrams.
+const (
+	electricalSymbolSize = 30
+	electricalBusHeight  = 6
+	electricalHGap       = 100
+	electricalVGap       = 40
+)
+
+// ElectricalKind is the kind of an electrical symbol.
+type ElectricalKind int
+
+// Electrical symbols of one-line diagrams, drawn after IEC 60617.
+const (
+	// ElectricalBus is a busbar, a thick horizontal line.
+	ElectricalBus ElectricalKind = iota
+	// ElectricalBreaker is a circuit breaker, a square.
+	ElectricalBreaker
+	// ElectricalTransformer is a two winding transformer, two
+	// overlapping circles.
+	ElectricalTransformer
+	// ElectricalGenerator is a generator, a circle marked G.
+	ElectricalGenerator
+	// ElectricalLoad is a load, an arrow pointing down.
+	ElectricalLoad
+)
+
+// String returns the name of k.
+func (k ElectricalKind) String() string {
+	switch k {
+	case ElectricalBus:
+		return "bus"
+	case ElectricalBreaker:
+		return "breaker"
+	case ElectricalTransformer:
+		return "transformer"
+	case ElectricalGenerator:
+		return "generator"
+	case ElectricalLoad:
+		return "load"
+	}
+	return fmt.Sprintf("ElectricalKind(%d)", int(k))
+}
+
+// NewElectricalSymbol returns the cells of the symbol of kind, labeled
+// name on its right, or above it for buses. The first cell is the
+// symbol connections are made to; the others, if any, are parts of it
+// drawn inside it. All of them are to be added to the graph, in order.
+func NewElectricalSymbol(id, layerId string, kind ElectricalKind, name string) []*Cell {
+	c := NewShape(id, layerId)
+	c.Value = html.EscapeString(name)
+	c.Style = Style{Attributes: shapeStyle("")}
+	c.Style.set("labelPosition", "right")
+	c.Style.set("verticalLabelPosition", "middle")
+	c.Style.set("align", "left")
+	c.Style.set("whiteSpace", "nowrap")
+	c.Style.set("strokeWidth", "2")
+	c.Geometry.setSize(electricalSymbolSize, electricalSymbolSize)
+	cells := []*Cell{c}
+	// part returns a part of the symbol
+	part := func(suffix, shape string) *Cell {
+		p := NewShape(id+"-"+suffix, id)
+		p.Style = Style{Attributes: shapeStyle(shape)}
+		p.Style.set("connectable", "0")
+		p.Style.set("strokeWidth", "2")
+		p.Style.set("fillColor", "none")
+		p.Geometry.X, p.Geometry.Y = 0, 0
+		cells = append(cells, p)
+		return p
+	}
+
+	switch kind {
+	case ElectricalBus:
+		c.Style.set("fillColor", "#000000")
+		c.Style.set("labelPosition", "center")
+		c.Style.set("verticalLabelPosition", "top")
+		c.Style.set("verticalAlign", "bottom")
+		c.Style.set("fontStyle", "1")
+		c.Geometry.setSize(4*electricalSymbolSize, electricalBusHeight)
+	case ElectricalBreaker:
+		c.Style.set("fillColor", "#ffffff")
+		c.Geometry.setSize(electricalSymbolSize*2/3, electricalSymbolSize*2/3)
+	case ElectricalTransformer:
+		c.Style.set("fillColor", "none")
+		c.Style.set("strokeColor", "none")
+		c.Geometry.setSize(electricalSymbolSize, electricalSymbolSize*5/3)
+		p := part("primary", "ellipse")
+		p.Geometry.setSize(electricalSymbolSize, electricalSymbolSize)
+		s := part("secondary", "ellipse")
+		s.Geometry.Y = electricalSymbolSize * 2 / 3
+		s.Geometry.setSize(electricalSymbolSize, electricalSymbolSize)
+	case ElectricalGenerator:
+		c.Style.set("shape", "ellipse")
+		c.Style.set("perimeter", "ellipsePerimeter")
+		c.Style.set("fillColor", "#ffffff")
+		c.Geometry.setSize(electricalSymbolSize*4/3, electricalSymbolSize*4/3)
+		p := part("mark", "text")
+		p.Value = "<b>G</b>"
+		p.Style.set("strokeColor", "none")
+		p.Style.set("fontSize", "16")
+		p.Geometry.setSize(electricalSymbolSize*4/3, electricalSymbolSize*4/3)
+	case ElectricalLoad:
+		c.Style.set("shape", "triangle")
+		c.Style.set("perimeter", "trianglePerimeter")
+		c.Style.set("direction", "south")
+		c.Style.set("fillColor", "#000000")
+		c.Geometry.setSize(electricalSymbolSize*2/3, electricalSymbolSize*2/3)
+	}
+	return cells
+}
+
+// Equipment is a piece of equipment of a one-line diagram.
+type Equipment struct {
+	ID   string
+	Name string
+	Kind ElectricalKind
+	// Upstream is the ID of the equipment feeding this one, e.g. the
+	// bus a breaker is connected to; it is empty for sources such as
+	// generators and utility feeds.
+	Upstream string
+}
+
+// NewOneLine returns a one-line diagram of equipment: the symbols are
+// laid out top down from the sources, each below the equipment feeding
+// it, and the buses are stretched to span the equipment they feed.
+// Equipment is listed in the order given, which decides the order of
+// the feeders of a bus. ErrCycle is returned when equipment feeds
+// itself.
+func NewOneLine(equipment []Equipment) (*GraphModel, error) {
+	byID := make(map[string]int, len(equipment))
+	for i, e := range equipment {
+		if _, ok := byID[e.ID]; ok {
+			return nil, fmt.Errorf("graw: NewOneLine: duplicate equipment %q", e.ID)
+		}
+		byID[e.ID] = i
+	}
+	for _, e := range equipment {
+		if _, ok := byID[e.Upstream]; e.Upstream != "" && !ok {
+			return nil, fmt.Errorf("graw: NewOneLine: equipment %q: unknown upstream %q", e.ID, e.Upstream)
+		}
+		seen := map[string]bool{e.ID: true}
+		for u := e.Upstream; u != ""; u = equipment[byID[u]].Upstream {
+			if seen[u] {
+				return nil, ErrCycle
+			}
+			seen[u] = true
+		}
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	symbols := make([][]*Cell, len(equipment))
+	var roots []*Cell
+	children := make(map[*Cell][]*Cell)
+	for i, e := range equipment {
+		symbols[i] = NewElectricalSymbol(taken.unique(e.ID), rootCellID, e.Kind, e.Name)
+		for _, p := range symbols[i][1:] {
+			taken[p.ID] = true
+		}
+	}
+	for i, e := range equipment {
+		c := symbols[i][0]
+		if e.Upstream == "" {
+			roots = append(roots, c)
+			continue
+		}
+		u := symbols[byID[e.Upstream]][0]
+		children[u] = append(children[u], c)
+	}
+	layoutTree(roots, children, 10+electricalSymbolSize, 30, electricalHGap, electricalVGap)
+
+	// buses span the centers of the equipment they feed
+	for i, e := range equipment {
+		c := symbols[i][0]
+		if e.Kind != ElectricalBus || len(children[c]) == 0 {
+			continue
+		}
+		w, h := c.Geometry.size()
+		left, right := c.Geometry.X, c.Geometry.X+w
+		for _, child := range children[c] {
+			cw, _ := child.Geometry.size()
+			left = minInt(left, child.Geometry.X+cw/2-electricalSymbolSize)
+			right = maxInt(right, child.Geometry.X+cw/2+electricalSymbolSize)
+		}
+		c.Geometry.X = left
+		c.Geometry.setSize(right-left, h)
+	}
+
+	for i := range equipment {
+		for _, c := range symbols[i] {
+			g.Add(c)
+		}
+	}
+	for i, e := range equipment {
+		if e.Upstream == "" {
+			continue
+		}
+		src, tgt := symbols[byID[e.Upstream]][0], symbols[i][0]
+		l := newEdgeCell(taken.unique(src.ID+"-"+tgt.ID), rootCellID, src.ID, tgt.ID)
+		l.Style.set("endArrow", "none")
+		l.Style.set("strokeWidth", "2")
+		l.Style.set("edgeStyle", "orthogonalEdgeStyle")
+		// connections leave buses and reach them straight down, at the
+		// center of the equipment on the other end
+		tw, _ := tgt.Geometry.size()
+		sw, _ := src.Geometry.size()
+		if equipment[byID[e.Upstream]].Kind == ElectricalBus {
+			l.Style.setFloat("exitX", float64(tgt.Geometry.X+tw/2-src.Geometry.X)/float64(sw))
+			l.Style.set("exitY", "1")
+		}
+		if e.Kind == ElectricalBus {
+			l.Style.setFloat("entryX", float64(src.Geometry.X+sw/2-tgt.Geometry.X)/float64(tw))
+			l.Style.set("entryY", "0")
+		}
+		g.Add(l)
+	}
+	return &g, nil
+}
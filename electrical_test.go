@@ -0,0 +1,144 @@
+package graw
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestElectricalKindString(t *testing.T) {
+	tests := []struct {
+		kind ElectricalKind
+		want string
+	}{
+		{ElectricalBus, "bus"},
+		{ElectricalBreaker, "breaker"},
+		{ElectricalTransformer, "transformer"},
+		{ElectricalGenerator, "generator"},
+		{ElectricalLoad, "load"},
+		{ElectricalKind(9), "ElectricalKind(9)"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNewElectricalSymbol(t *testing.T) {
+	tests := []struct {
+		kind ElectricalKind
+		ids  []string
+		w, h int
+	}{
+		{ElectricalBus, []string{"s"}, 4 * electricalSymbolSize, electricalBusHeight},
+		{ElectricalBreaker, []string{"s"}, 20, 20},
+		{ElectricalTransformer, []string{"s", "s-primary", "s-secondary"}, 30, 50},
+		{ElectricalGenerator, []string{"s", "s-mark"}, 40, 40},
+		{ElectricalLoad, []string{"s"}, 20, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			cells := NewElectricalSymbol("s", rootCellID, tt.kind, "a & b")
+			var ids []string
+			g := NewGraph()
+			for _, c := range cells {
+				ids = append(ids, c.ID)
+				g.Add(c)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("got cells %q, want %q", ids, tt.ids)
+			}
+			if cells[0].Value != "a &amp; b" {
+				t.Errorf("got label %q", cells[0].Value)
+			}
+			if w, h := cells[0].Geometry.size(); w != tt.w || h != tt.h {
+				t.Errorf("got size %dx%d, want %dx%d", w, h, tt.w, tt.h)
+			}
+			// parts are drawn inside the symbol
+			for _, p := range cells[1:] {
+				pw, ph := p.Geometry.size()
+				if p.ParentID != "s" || p.Geometry.Y+ph > tt.h || pw > tt.w {
+					t.Errorf("%s: got parent %q, %d,%d %dx%d", p.ID, p.ParentID, p.Geometry.X, p.Geometry.Y, pw, ph)
+				}
+			}
+			if iss := ValidateStyles(&g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewOneLine(t *testing.T) {
+	g, err := NewOneLine([]Equipment{
+		{ID: "grid", Name: "Utility", Kind: ElectricalGenerator},
+		{ID: "tx", Name: "T1", Kind: ElectricalTransformer, Upstream: "grid"},
+		{ID: "bus", Name: "MV bus", Kind: ElectricalBus, Upstream: "tx"},
+		{ID: "cb1", Name: "CB1", Kind: ElectricalBreaker, Upstream: "bus"},
+		{ID: "cb2", Name: "CB2", Kind: ElectricalBreaker, Upstream: "bus"},
+		{ID: "cb3", Name: "CB3", Kind: ElectricalBreaker, Upstream: "bus"},
+		{ID: "m1", Name: "Motor", Kind: ElectricalLoad, Upstream: "cb1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"grid->tx", "tx->bus", "bus->cb1", "bus->cb2", "bus->cb3", "cb1->m1"}
+	if got := edgeList(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %q, want %q", got, want)
+	}
+	// equipment is below the equipment feeding it
+	for _, e := range [][2]string{{"grid", "tx"}, {"tx", "bus"}, {"bus", "cb1"}, {"cb1", "m1"}} {
+		u, d := g.FindByID(e[0]), g.FindByID(e[1])
+		_, h := u.Geometry.size()
+		if d.Geometry.Y < u.Geometry.Y+h {
+			t.Errorf("%s: got y %d, want below %s at %d", e[1], d.Geometry.Y, e[0], u.Geometry.Y+h)
+		}
+	}
+	// the bus spans its feeders, and connections leave it above their
+	// centers
+	bus := g.FindByID("bus")
+	bw, _ := bus.Geometry.size()
+	for _, id := range []string{"cb1", "cb2", "cb3"} {
+		c := g.FindByID(id)
+		cw, _ := c.Geometry.size()
+		center := c.Geometry.X + cw/2
+		if center < bus.Geometry.X || center > bus.Geometry.X+bw {
+			t.Errorf("%s: got center %d, want within the bus %d–%d", id, center, bus.Geometry.X, bus.Geometry.X+bw)
+		}
+		l := g.FindByID("bus-" + id)
+		x, _ := l.Style.float("exitX")
+		if got := bus.Geometry.X + int(math.Round(x*float64(bw))); got != center {
+			t.Errorf("%s: got exit at %d, want %d", id, got, center)
+		}
+	}
+	// the connection reaching the bus enters it below the transformer
+	tx := g.FindByID("tx")
+	tw, _ := tx.Geometry.size()
+	x, _ := g.FindByID("tx-bus").Style.float("entryX")
+	if got := bus.Geometry.X + int(math.Round(x*float64(bw))); got != tx.Geometry.X+tw/2 {
+		t.Errorf("got entry at %d, want %d", got, tx.Geometry.X+tw/2)
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewOneLineErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		equipment []Equipment
+		want      error
+	}{
+		{"duplicate", []Equipment{{ID: "a"}, {ID: "a"}}, nil},
+		{"unknown upstream", []Equipment{{ID: "a", Upstream: "b"}}, nil},
+		{"cycle", []Equipment{{ID: "a", Upstream: "b"}, {ID: "b", Upstream: "a"}}, ErrCycle},
+		{"self", []Equipment{{ID: "a", Upstream: "a"}}, ErrCycle},
+	}
+	for _, tt := range tests {
+		_, err := NewOneLine(tt.equipment)
+		if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package graw
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// An EncodeOption configures GraphModel.Encode.
+type EncodeOption func(o *encodeOptions)
+
+// encodeOptions holds the options of GraphModel.Encode.
+type encodeOptions struct {
+	indent string
+	header bool
+	file   bool
+	page   string
+
+	// precision is the number of decimals of the written numbers
+	// plus one, so that zero means unlimited.
+	precision int
+}
+
+// EncodeIndent indents nested elements by indent, e.g. two spaces,
+// and writes each element on a line of its own.
+func EncodeIndent(indent string) EncodeOption {
+	return func(o *encodeOptions) {
+		o.indent = indent
+	}
+}
+
+// EncodeHeader writes the XML declaration <?xml version="1.0"
+// encoding="UTF-8"?> before the document when on.
+func EncodeHeader(on bool) EncodeOption {
+	return func(o *encodeOptions) {
+		o.header = on
+	}
+}
+
+// EncodeFile wraps the model in an mxfile element as the only page,
+// named page, so that the output is a complete .drawio file.
+func EncodeFile(page string) EncodeOption {
+	return func(o *encodeOptions) {
+		o.file = true
+		o.page = page
+	}
+}
+
+// EncodePrecision writes the fractional numbers of geometries, such
+// as the position of edge labels and sizes, with at most decimals
+// decimal places, keeping files small and stable across runs.
+// Trailing zeros are dropped.
+func EncodePrecision(decimals int) EncodeOption {
+	return func(o *encodeOptions) {
+		if decimals >= 0 {
+			o.precision = decimals + 1
+		}
+	}
+}
+
+// Encode writes g to w as an XML document configured by opts: by
+// default a bare mxGraphModel element on a single line, without XML
+// declaration. Numbers are always written with a dot as decimal
+// separator, whatever the locale.
+func (g *GraphModel) Encode(w io.Writer, opts ...EncodeOption) error {
+	var o encodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.header {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+	}
+	e := xml.NewEncoder(w)
+	if o.indent != "" {
+		e.Indent("", o.indent)
+	}
+	if o.precision > 0 {
+		r := g.clone()
+		roundGeometries(&r, o.precision-1)
+		g = &r
+	}
+	var v interface{} = g
+	if o.file {
+		d := NewDocument()
+		d.AddPage(o.page, g)
+		v = d
+	}
+	if err := e.Encode(v); err != nil {
+		return err
+	}
+	if o.indent != "" || o.header {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
+
+// roundGeometries rounds the fractional numbers of the geometries of g
+// to decimals decimal places.
+func roundGeometries(g *GraphModel, decimals int) {
+	round := func(v string) string {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return v
+		}
+		return formatDecimal(f, decimals)
+	}
+	for i := range g.Root {
+		geo := g.Root[i].Geometry
+		if geo == nil {
+			continue
+		}
+		if geo.Position != 0 {
+			geo.Position, _ = strconv.ParseFloat(formatDecimal(geo.Position, decimals), 64)
+		}
+		geo.Width = round(geo.Width)
+		geo.Height = round(geo.Height)
+	}
+}
+
+// formatDecimal formats v with at most decimals decimal places and no
+// trailing zeros, e.g. "1.5" for 1.5004 and two decimals.
+func formatDecimal(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', decimals, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+package graw
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	build := func() *GraphModel {
+		g := NewGraph()
+		a := NewShape("a", "1")
+		a.Geometry.Width, a.Geometry.Height = "120.3333", "60"
+		g.Add(a)
+		g.AddEdgeLabel(g.ConnectCells(a, a), "l", 0.123456)
+		return &g
+	}
+	model := `<mxGraphModel dx="640" dy="480"><root><mxCell id="0" style="html=1;"></mxCell><mxCell id="1" style="html=1;" parent="0"></mxCell>`
+	tests := []struct {
+		name     string
+		opts     []EncodeOption
+		prefix   string
+		contains []string
+		suffix   string
+	}{
+		{
+			name:     "default",
+			prefix:   model,
+			contains: []string{`width="120.3333"`, `x="0.123456"`},
+			suffix:   "</mxGraphModel>",
+		},
+		{
+			name:   "header",
+			opts:   []EncodeOption{EncodeHeader(true)},
+			prefix: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + model,
+			suffix: "</mxGraphModel>\n",
+		},
+		{
+			name:     "indent",
+			opts:     []EncodeOption{EncodeIndent("  ")},
+			prefix:   "<mxGraphModel dx=\"640\" dy=\"480\">\n  <root>\n    <mxCell id=\"0\" style=\"html=1;\"></mxCell>\n",
+			contains: []string{"\n      <mxGeometry x=\"10\" y=\"10\" width=\"120.3333\" height=\"60\" as=\"geometry\"></mxGeometry>\n"},
+			suffix:   "  </root>\n</mxGraphModel>\n",
+		},
+		{
+			name:   "file",
+			opts:   []EncodeOption{EncodeFile("Overview")},
+			prefix: `<mxfile host="graw"><diagram id="page-1" name="Overview">` + model,
+			suffix: "</mxGraphModel></diagram></mxfile>",
+		},
+		{
+			name:     "precision",
+			opts:     []EncodeOption{EncodePrecision(2)},
+			contains: []string{`width="120.33" height="60"`, `x="0.12"`},
+		},
+		{
+			name:     "no decimals",
+			opts:     []EncodeOption{EncodePrecision(0)},
+			contains: []string{`width="120" height="60"`, `<mxGeometry relative="1" as="geometry"><mxPoint as="offset">`},
+		},
+		{
+			name:     "negative precision",
+			opts:     []EncodeOption{EncodePrecision(-1)},
+			contains: []string{`width="120.3333"`, `x="0.123456"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := build()
+			var b strings.Builder
+			if err := g.Encode(&b, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			if !strings.HasPrefix(out, tt.prefix) {
+				t.Errorf("got\n%s\nwant prefix\n%s", out, tt.prefix)
+			}
+			if !strings.HasSuffix(out, tt.suffix) {
+				t.Errorf("got\n%s\nwant suffix\n%q", out, tt.suffix)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it to contain\n%s", out, s)
+				}
+			}
+			// the model itself is not rounded
+			if got := g.FindByID("a").Geometry.Width; got != "120.3333" {
+				t.Errorf("got width %q after Encode", got)
+			}
+		})
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{1.5004, 2, "1.5"},
+		{1.005, 3, "1.005"},
+		{2, 2, "2"},
+		{100, 0, "100"},
+		{-0.0001, 2, "0"},
+		{-1.25, 1, "-1.2"},
+		{0.5, 0, "0"},
+	}
+	for _, tt := range tests {
+		if got := formatDecimal(tt.v, tt.decimals); got != tt.want {
+			t.Errorf("formatDecimal(%v, %d): got %q, want %q", tt.v, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestRoundGeometries(t *testing.T) {
+	tests := []struct {
+		name        string
+		geo         *Geometry
+		decimals    int
+		width, high string
+		position    float64
+	}{
+		{"sizes", &Geometry{Width: "80.456", Height: "40.001"}, 1, "80.5", "40", 0},
+		{"position", &Geometry{Relative: "1", Position: -0.33333}, 2, "", "", -0.33},
+		{"zero position", &Geometry{Relative: "1"}, 2, "", "", 0},
+		{"not a number", &Geometry{Width: "auto", Height: "1e2"}, 0, "auto", "100", 0},
+		{"no geometry", nil, 2, "", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			c := NewShape("a", "1")
+			c.Geometry = tt.geo
+			g.Add(c)
+			roundGeometries(&g, tt.decimals)
+			geo := g.FindByID("a").Geometry
+			if geo == nil {
+				if tt.geo != nil {
+					t.Fatal("got no geometry")
+				}
+				return
+			}
+			if geo.Width != tt.width || geo.Height != tt.high || geo.Position != tt.position {
+				t.Errorf("got %q x %q at %v, want %q x %q at %v", geo.Width, geo.Height, geo.Position, tt.width, tt.high, tt.position)
+			}
+		})
+	}
+}
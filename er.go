@@ -0,0 +1,293 @@
+package graw
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ERSchema describes the tables of a database and the relations
+// between them, as drawn by NewERDiagram.
+type ERSchema struct {
+	Tables    []Table
+	Relations []Relation
+}
+
+// Table describes a database table.
+type Table struct {
+	Name    string
+	Columns []Column
+	Indexes []Index
+}
+
+// Column describes a table column.
+type Column struct {
+	Name       string
+	Type       string
+	PrimaryKey bool
+	NotNull    bool
+	Unique     bool
+}
+
+// Index describes a table index.
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// Relation is a foreign key: the columns FromColumns of FromTable
+// refer to the columns ToColumns of ToTable.
+type Relation struct {
+	FromTable   string
+	FromColumns []string
+	ToTable     string
+	ToColumns   []string
+	// OneToOne marks a relation where each row of ToTable is
+	// referred to at most once.
+	OneToOne bool
+}
+
+// Sizes of the shapes of an ER diagram.
+const (
+	erTableWidth = 220
+	erHeader     = 26
+	erRowHeight  = 26
+	erLineHeight = 8
+)
+
+// table finds the table with the given name.
+func (s *ERSchema) table(name string) *Table {
+	for i := range s.Tables {
+		if s.Tables[i].Name == name {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// addColumn adds col to the named table unless it has a column of
+// that name already.
+func (s *ERSchema) addColumn(table string, col Column) {
+	t := s.table(table)
+	if t == nil {
+		return
+	}
+	for _, c := range t.Columns {
+		if c.Name == col.Name {
+			return
+		}
+	}
+	t.Columns = append(t.Columns, col)
+}
+
+// columnType returns the type of the named column.
+func (s *ERSchema) columnType(table, column string) string {
+	if t := s.table(table); t != nil {
+		for _, c := range t.Columns {
+			if c.Name == column {
+				return c.Type
+			}
+		}
+	}
+	return ""
+}
+
+// NewERDiagram returns an entity relationship diagram of s. Every
+// table is drawn as a container listing its columns, marked PK for
+// primary keys and FK for foreign keys, followed by its indexes.
+// Relations connect the referring column to the referred one using
+// crow's foot arrows.
+func NewERDiagram(s ERSchema) *GraphModel {
+	g := NewGraph()
+	taken := g.idSet()
+
+	foreign := make(map[string]bool)
+	for _, r := range s.Relations {
+		for _, c := range r.FromColumns {
+			foreign[r.FromTable+"\x00"+c] = true
+		}
+	}
+
+	rows := make(map[string]string)
+	var tables []*Cell
+	var cells []*Cell
+	for _, t := range s.Tables {
+		tid := taken.unique("table-" + t.Name)
+		tc := newERTable(tid, t.Name)
+		tables = append(tables, tc)
+
+		y := erHeader
+		var children []*Cell
+		for _, col := range t.Columns {
+			key := ""
+			switch {
+			case col.PrimaryKey:
+				key = "PK"
+			case foreign[t.Name+"\x00"+col.Name]:
+				key = "FK"
+			}
+			label := col.Name
+			if col.Type != "" {
+				label += ": " + col.Type
+			}
+			if col.NotNull && !col.PrimaryKey {
+				label += " NOT NULL"
+			}
+			if col.Unique {
+				label += " UNIQUE"
+			}
+			if key != "" {
+				label = key + " " + label
+			}
+
+			rc := newERRow(taken.unique(tid+"-"+col.Name), tid, label, y, erRowHeight)
+			if col.PrimaryKey {
+				rc.Style.set("fontStyle", "4")
+			}
+			rows[t.Name+"\x00"+col.Name] = rc.ID
+			children = append(children, rc)
+			y += erRowHeight
+		}
+
+		if len(t.Indexes) > 0 {
+			line := newERRow(taken.unique(tid+"-indexes"), tid, "", y, erLineHeight)
+			line.Style = Style{Attributes: map[string]string{
+				"line":        "",
+				"strokeWidth": "1",
+				"fillColor":   "none",
+				"rotatable":   "0",
+				"points":      "[]",
+			}}
+			children = append(children, line)
+			y += erLineHeight
+
+			for _, idx := range t.Indexes {
+				label := idx.Name + " (" + strings.Join(idx.Columns, ", ") + ")"
+				if idx.Unique {
+					label = "UNIQUE " + label
+				}
+				rc := newERRow(taken.unique(tid+"-"+idx.Name), tid, label, y, erRowHeight)
+				rc.Style.set("fontStyle", "2")
+				children = append(children, rc)
+				y += erRowHeight
+			}
+		}
+
+		tc.Geometry.setSize(erTableWidth, y)
+		cells = append(cells, children...)
+	}
+
+	layoutGrid(tables, 10, 10, 80)
+	for _, tc := range tables {
+		g.Add(tc)
+	}
+	g.Root = append(g.Root, cells...)
+
+	for _, r := range s.Relations {
+		if len(r.FromColumns) == 0 || len(r.ToColumns) == 0 {
+			continue
+		}
+		from, ok := rows[r.FromTable+"\x00"+r.FromColumns[0]]
+		if !ok {
+			continue
+		}
+		to, ok := rows[r.ToTable+"\x00"+r.ToColumns[0]]
+		if !ok {
+			continue
+		}
+
+		e := newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to)
+		e.Style.set("edgeStyle", "entityRelationEdgeStyle")
+		e.Style.set("endArrow", "ERmandOne")
+		e.Style.set("startArrow", "ERmany")
+		if r.OneToOne {
+			e.Style.set("startArrow", "ERzeroToOne")
+		}
+		e.Style.set("startFill", "0")
+		e.Style.set("endFill", "0")
+		g.Add(e)
+	}
+
+	return &g
+}
+
+// newERTable returns the container drawing a table.
+func newERTable(id, name string) *Cell {
+	c := NewShape(id, rootCellID)
+	c.Value = name
+	c.Style = Style{Attributes: map[string]string{
+		"swimlane":        "",
+		"fontStyle":       "1",
+		"childLayout":     "stackLayout",
+		"horizontal":      "1",
+		"startSize":       strconv.Itoa(erHeader),
+		"horizontalStack": "0",
+		"resizeParent":    "1",
+		"resizeParentMax": "0",
+		"resizeLast":      "0",
+		"collapsible":     "1",
+		"marginBottom":    "0",
+		"html":            "1",
+	}}
+	return c
+}
+
+// newERRow returns a text row of a table container at offset y.
+func newERRow(id, tableID, label string, y, height int) *Cell {
+	c := NewShape(id, tableID)
+	c.Value = label
+	c.Style = Style{Attributes: map[string]string{
+		"text":           "",
+		"strokeColor":    "none",
+		"fillColor":      "none",
+		"align":          "left",
+		"verticalAlign":  "top",
+		"spacingLeft":    "4",
+		"spacingRight":   "4",
+		"overflow":       "hidden",
+		"rotatable":      "0",
+		"points":         "[[0,0.5],[1,0.5]]",
+		"portConstraint": "eastwest",
+		"html":           "1",
+	}}
+	c.Geometry.X = 0
+	c.Geometry.Y = y
+	c.Geometry.setSize(erTableWidth, height)
+	return c
+}
+
+// snakeCase converts a Go identifier such as "UserID" into
+// "user_id", the default column naming of Go ORMs.
+func snakeCase(s string) string {
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 {
+			prevLower := runes[i-1] >= 'a' && runes[i-1] <= 'z' || runes[i-1] >= '0' && runes[i-1] <= '9'
+			nextLower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z'
+			if prevLower || (nextLower && runes[i-1] != '_') {
+				b.WriteByte('_')
+			}
+		}
+		if upper {
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// plural returns the English plural of the lower case noun s, as
+// used for default table names.
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	default:
+		return s + "s"
+	}
+}
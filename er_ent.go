@@ -0,0 +1,190 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// entSchema mirrors the JSON encoding of an ent schema as stored in
+// the schema snapshot (internal/schema.go) written by entc.
+type entSchema struct {
+	Name   string `json:"name"`
+	Config struct {
+		Table string
+	} `json:"config"`
+	Edges  []*entEdge `json:"edges"`
+	Fields []struct {
+		Name string `json:"name"`
+		Info *struct {
+			Type  uint8
+			Ident string
+		} `json:"type"`
+		Unique     bool   `json:"unique"`
+		Optional   bool   `json:"optional"`
+		Nillable   bool   `json:"nillable"`
+		StorageKey string `json:"storage_key"`
+	} `json:"fields"`
+	Indexes []struct {
+		Unique     bool     `json:"unique"`
+		Edges      []string `json:"edges"`
+		Fields     []string `json:"fields"`
+		StorageKey string   `json:"storage_key"`
+	} `json:"indexes"`
+}
+
+// entEdge mirrors an ent edge.
+type entEdge struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Field    string   `json:"field"`
+	RefName  string   `json:"ref_name"`
+	Ref      *entEdge `json:"ref"`
+	Unique   bool     `json:"unique"`
+	Inverse  bool     `json:"inverse"`
+	Required bool     `json:"required"`
+}
+
+// entTypeOther is ent's field.TypeOther, a custom Go type.
+const entTypeOther = 8
+
+// entTypes names the column types of ent's field.Type values.
+var entTypes = []string{
+	"invalid", "bool", "time", "json", "uuid", "bytes", "enum", "string",
+	"other", "int8", "int16", "int32", "int", "int64", "uint8", "uint16",
+	"uint32", "uint", "uint64", "float32", "float64",
+}
+
+// FromEnt returns an ER diagram of an ent schema. It is a shorthand
+// for NewERDiagram of the result of EntSchema.
+func FromEnt(r io.Reader) (*GraphModel, error) {
+	s, err := EntSchema(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewERDiagram(s), nil
+}
+
+// EntSchema reads the tables of an ent schema from its JSON encoding:
+// either the snapshot written by entc with the schema/snapshot feature
+// (the value of the Schema constant in internal/schema.go) or a list
+// of schemas. Tables, foreign keys and join tables are named after
+// ent's defaults unless the schema sets a field or storage key. ent
+// itself is not needed.
+func EntSchema(r io.Reader) (ERSchema, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ERSchema{}, err
+	}
+	var schemas []*entSchema
+	if b := bytes.TrimSpace(data); len(b) > 0 && b[0] == '[' {
+		err = json.Unmarshal(b, &schemas)
+	} else {
+		var snapshot struct{ Schemas []*entSchema }
+		err = json.Unmarshal(b, &snapshot)
+		schemas = snapshot.Schemas
+	}
+	if err != nil {
+		return ERSchema{}, fmt.Errorf("graw: EntSchema: %v", err)
+	}
+
+	var s ERSchema
+	tables := make(map[string]*entSchema, len(schemas))
+	for _, es := range schemas {
+		tables[es.Name] = es
+	}
+	table := func(es *entSchema) string {
+		if es.Config.Table != "" {
+			return es.Config.Table
+		}
+		return plural(snakeCase(es.Name))
+	}
+
+	for _, es := range schemas {
+		t := Table{Name: table(es), Columns: []Column{{Name: "id", Type: "int", PrimaryKey: true}}}
+		columns := map[string]string{"id": "id"}
+		for _, f := range es.Fields {
+			col := Column{Name: f.Name, NotNull: !f.Optional && !f.Nillable, Unique: f.Unique}
+			if f.StorageKey != "" {
+				col.Name = f.StorageKey
+			}
+			if f.Info != nil {
+				col.Type = f.Info.Ident
+				if int(f.Info.Type) < len(entTypes) && f.Info.Type != entTypeOther {
+					col.Type = entTypes[f.Info.Type]
+				}
+			}
+			columns[f.Name] = col.Name
+			t.Columns = append(t.Columns, col)
+		}
+		for _, idx := range es.Indexes {
+			var cols []string
+			for _, f := range idx.Fields {
+				cols = append(cols, columns[f])
+			}
+			cols = append(cols, idx.Edges...)
+			name := idx.StorageKey
+			if name == "" {
+				name = snakeCase(es.Name)
+				for _, c := range cols {
+					name += "_" + c
+				}
+			}
+			t.Indexes = append(t.Indexes, Index{Name: name, Columns: cols, Unique: idx.Unique})
+		}
+		s.Tables = append(s.Tables, t)
+	}
+
+	for _, es := range schemas {
+		for _, e := range es.Edges {
+			if e.Inverse {
+				continue
+			}
+			target, ok := tables[e.Type]
+			if !ok {
+				continue
+			}
+			var inverse *entEdge
+			for _, te := range target.Edges {
+				if te.Inverse && te.RefName == e.Name && te.Type == es.Name {
+					inverse = te
+				}
+			}
+			from, to := table(es), table(target)
+			fk := snakeCase(es.Name) + "_" + e.Name
+			if inverse != nil && inverse.Field != "" {
+				fk = inverse.Field
+			} else if e.Field != "" {
+				fk = e.Field
+			}
+
+			switch {
+			case !e.Unique && inverse != nil && !inverse.Unique:
+				// many to many through a join table
+				join := snakeCase(es.Name) + "_" + e.Name
+				a, b := snakeCase(es.Name)+"_id", snakeCase(target.Name)+"_id"
+				if a == b {
+					b = snakeCase(e.Name) + "_id"
+				}
+				s.Tables = append(s.Tables, Table{Name: join, Columns: []Column{
+					{Name: a, Type: "int", PrimaryKey: true},
+					{Name: b, Type: "int", PrimaryKey: true},
+				}})
+				s.Relations = append(s.Relations,
+					Relation{FromTable: join, FromColumns: []string{a}, ToTable: from, ToColumns: []string{"id"}},
+					Relation{FromTable: join, FromColumns: []string{b}, ToTable: to, ToColumns: []string{"id"}})
+			case e.Unique && inverse != nil && !inverse.Unique:
+				// many to one: the foreign key is in this table
+				s.addColumn(from, Column{Name: fk, Type: "int"})
+				s.Relations = append(s.Relations, Relation{FromTable: from, FromColumns: []string{fk}, ToTable: to, ToColumns: []string{"id"}})
+			default:
+				// one to one or one to many: the foreign key is in the
+				// target table
+				s.addColumn(to, Column{Name: fk, Type: "int", Unique: e.Unique})
+				s.Relations = append(s.Relations, Relation{FromTable: to, FromColumns: []string{fk}, ToTable: from, ToColumns: []string{"id"}, OneToOne: e.Unique})
+			}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,343 @@
+package graw
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gormModel is a model passed to GORMSchema.
+type gormModel struct {
+	t     reflect.Type
+	table string
+	// columns maps Go field names to column names.
+	columns map[string]string
+	pk      string
+	rels    []reflect.StructField
+}
+
+// gormIndex collects the columns of an index declared on several
+// fields.
+type gormIndex struct {
+	unique  bool
+	columns []gormIndexColumn
+}
+
+// gormIndexColumn is a column of an index, ordered by priority.
+type gormIndexColumn struct {
+	name     string
+	priority int
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// FromGORM returns an ER diagram of the given GORM models. It is a
+// shorthand for NewERDiagram of the result of GORMSchema.
+func FromGORM(models ...interface{}) (*GraphModel, error) {
+	s, err := GORMSchema(models...)
+	if err != nil {
+		return nil, err
+	}
+	return NewERDiagram(s), nil
+}
+
+// GORMSchema reads the tables of the given GORM models, structs or
+// pointers to structs, using GORM's naming rules and `gorm:"..."`
+// struct tags: column, type, size, primaryKey, not null, unique,
+// index, uniqueIndex, embedded and embeddedPrefix. Belongs to, has
+// one, has many and many to many associations (foreignKey,
+// references and many2many) between the models passed in become
+// relations. GORM itself is not needed.
+func GORMSchema(models ...interface{}) (ERSchema, error) {
+	var s ERSchema
+	byType := make(map[reflect.Type]*gormModel)
+	var order []*gormModel
+
+	for i, m := range models {
+		rv := reflect.ValueOf(m)
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return ERSchema{}, fmt.Errorf("graw: GORMSchema: model %d is a %s, not a struct", i, rv.Kind())
+		}
+
+		gm := &gormModel{
+			t:       rv.Type(),
+			table:   plural(snakeCase(rv.Type().Name())),
+			columns: make(map[string]string),
+		}
+		if tn, ok := m.(interface{ TableName() string }); ok {
+			gm.table = tn.TableName()
+		}
+
+		t := Table{Name: gm.table}
+		indexes := make(map[string]*gormIndex)
+		var names []string
+		gm.collect(gm.t, "", &t, indexes, &names)
+		if gm.pk == "" {
+			for j := range t.Columns {
+				if t.Columns[j].Name == gm.columns["ID"] {
+					t.Columns[j].PrimaryKey = true
+					gm.pk = t.Columns[j].Name
+				}
+			}
+		}
+		for _, name := range names {
+			idx := indexes[name]
+			sort.SliceStable(idx.columns, func(a, b int) bool {
+				return idx.columns[a].priority < idx.columns[b].priority
+			})
+			index := Index{Name: name, Unique: idx.unique}
+			for _, c := range idx.columns {
+				index.Columns = append(index.Columns, c.name)
+			}
+			t.Indexes = append(t.Indexes, index)
+		}
+
+		s.Tables = append(s.Tables, t)
+		byType[gm.t] = gm
+		order = append(order, gm)
+	}
+
+	seen := make(map[string]int)
+	add := func(r Relation) {
+		key := r.FromTable + "\x00" + strings.Join(r.FromColumns, ",") + "\x00" + r.ToTable + "\x00" + strings.Join(r.ToColumns, ",")
+		if i, ok := seen[key]; ok {
+			s.Relations[i].OneToOne = s.Relations[i].OneToOne || r.OneToOne
+			return
+		}
+		seen[key] = len(s.Relations)
+		s.Relations = append(s.Relations, r)
+	}
+
+	for _, gm := range order {
+		for _, f := range gm.rels {
+			opts := parseGORMTag(f.Tag.Get("gorm"))
+			ft := f.Type
+			many := ft.Kind() == reflect.Slice
+			if many {
+				ft = ft.Elem()
+			}
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			target, ok := byType[ft]
+			if !ok {
+				continue
+			}
+
+			if join := opts["MANY2MANY"]; join != "" {
+				from := snakeCase(gm.t.Name()) + "_" + gm.pk
+				to := snakeCase(target.t.Name()) + "_" + target.pk
+				if k := opts["JOINFOREIGNKEY"]; k != "" {
+					from = snakeCase(k)
+				}
+				if k := opts["JOINREFERENCES"]; k != "" {
+					to = snakeCase(k)
+				}
+				if from == to {
+					to = snakeCase(strings.TrimSuffix(f.Name, "s")) + "_" + target.pk
+				}
+				if s.table(join) == nil {
+					s.Tables = append(s.Tables, Table{Name: join, Columns: []Column{
+						{Name: from, Type: s.columnType(gm.table, gm.pk), PrimaryKey: true},
+						{Name: to, Type: s.columnType(target.table, target.pk), PrimaryKey: true},
+					}})
+				}
+				add(Relation{FromTable: join, FromColumns: []string{from}, ToTable: gm.table, ToColumns: []string{gm.pk}})
+				add(Relation{FromTable: join, FromColumns: []string{to}, ToTable: target.table, ToColumns: []string{target.pk}})
+				continue
+			}
+
+			fk := opts["FOREIGNKEY"]
+			if !many {
+				// belongs to: the foreign key is a field of this model
+				if fk == "" {
+					fk = f.Name + "ID"
+				}
+				if col, ok := gm.columns[fk]; ok {
+					ref := target.pk
+					if r := opts["REFERENCES"]; r != "" {
+						ref = target.columns[r]
+					}
+					add(Relation{FromTable: gm.table, FromColumns: []string{col}, ToTable: target.table, ToColumns: []string{ref}})
+					continue
+				}
+				fk = opts["FOREIGNKEY"]
+			}
+
+			// has one or has many: the foreign key is a field of the target
+			if fk == "" {
+				fk = gm.t.Name() + "ID"
+			}
+			col, ok := target.columns[fk]
+			if !ok {
+				continue
+			}
+			ref := gm.pk
+			if r := opts["REFERENCES"]; r != "" {
+				ref = gm.columns[r]
+			}
+			add(Relation{FromTable: target.table, FromColumns: []string{col}, ToTable: gm.table, ToColumns: []string{ref}, OneToOne: !many})
+		}
+	}
+	return s, nil
+}
+
+// collect adds the columns and indexes declared by the fields of t,
+// descending into embedded structs, and records the association
+// fields of the model.
+func (gm *gormModel) collect(t reflect.Type, prefix string, table *Table, indexes map[string]*gormIndex, names *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		opts := parseGORMTag(f.Tag.Get("gorm"))
+		if _, ok := opts["-"]; ok {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		_, embedded := opts["EMBEDDED"]
+		if ft.Kind() == reflect.Struct && !isGORMValue(ft) && (f.Anonymous || embedded) {
+			gm.collect(ft, prefix+opts["EMBEDDEDPREFIX"], table, indexes, names)
+			continue
+		}
+		if ft.Kind() == reflect.Struct && !isGORMValue(ft) ||
+			ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 {
+			gm.rels = append(gm.rels, f)
+			continue
+		}
+
+		col := Column{Name: prefix + snakeCase(f.Name), Type: opts["TYPE"]}
+		if name := opts["COLUMN"]; name != "" {
+			col.Name = prefix + name
+		}
+		if col.Type == "" {
+			col.Type = gormSQLType(ft, opts["SIZE"])
+		}
+		_, pk := opts["PRIMARYKEY"]
+		_, pk2 := opts["PRIMARY_KEY"]
+		_, col.NotNull = opts["NOT NULL"]
+		_, col.Unique = opts["UNIQUE"]
+		if pk || pk2 {
+			col.PrimaryKey = true
+			if gm.pk == "" {
+				gm.pk = col.Name
+			}
+		}
+		gm.columns[f.Name] = col.Name
+		table.Columns = append(table.Columns, col)
+
+		for _, key := range []string{"INDEX", "UNIQUEINDEX"} {
+			value, ok := opts[key]
+			if !ok {
+				continue
+			}
+			parts := strings.Split(value, ",")
+			name := strings.TrimSpace(parts[0])
+			if name == "" {
+				name = "idx_" + table.Name + "_" + col.Name
+			}
+			priority := 10
+			for _, p := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(p), ":", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "priority") {
+					if n, err := strconv.Atoi(kv[1]); err == nil {
+						priority = n
+					}
+				}
+			}
+			idx, ok := indexes[name]
+			if !ok {
+				idx = &gormIndex{}
+				indexes[name] = idx
+				*names = append(*names, name)
+			}
+			idx.columns = append(idx.columns, gormIndexColumn{col.Name, priority})
+			idx.unique = idx.unique || key == "UNIQUEINDEX"
+		}
+	}
+}
+
+// isGORMValue tells whether the struct type t is stored in a single
+// column, like time.Time and the types implementing sql.Scanner.
+func isGORMValue(t reflect.Type) bool {
+	return t == timeType || reflect.PtrTo(t).Implements(scannerType)
+}
+
+// gormSQLType returns the column type GORM uses for the Go type t.
+func gormSQLType(t reflect.Type, size string) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Uint8:
+		return "tinyint"
+	case reflect.Int16, reflect.Uint16:
+		return "smallint"
+	case reflect.Int32, reflect.Uint32:
+		return "int"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "bigint"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.String:
+		if size != "" {
+			return "varchar(" + size + ")"
+		}
+		return "text"
+	case reflect.Slice:
+		return "blob"
+	case reflect.Struct:
+		if t == timeType {
+			return "datetime"
+		}
+		// sql.NullString and friends hold the value next to a
+		// Valid field.
+		if v, ok := t.FieldByName("Valid"); ok && v.Type.Kind() == reflect.Bool && t.NumField() == 2 {
+			for i := 0; i < 2; i++ {
+				if f := t.Field(i); f.Name != "Valid" {
+					return gormSQLType(f.Type, size)
+				}
+			}
+		}
+	}
+	return strings.ToLower(t.Name())
+}
+
+// parseGORMTag splits a gorm struct tag into its settings, keyed by
+// their upper case name as GORM does.
+func parseGORMTag(tag string) map[string]string {
+	opts := make(map[string]string)
+	for _, part := range strings.Split(tag, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if strings.HasPrefix(key, "-") {
+			key = "-"
+		}
+		if len(kv) == 2 {
+			opts[key] = strings.TrimSpace(kv[1])
+		} else {
+			opts[key] = ""
+		}
+	}
+	return opts
+}
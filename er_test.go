@@ -0,0 +1,224 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewERDiagram(t *testing.T) {
+	s := ERSchema{
+		Tables: []Table{
+			{Name: "users", Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true, NotNull: true},
+				{Name: "email", Type: "text", NotNull: true, Unique: true},
+				{Name: "team_id", Type: "int"},
+			}, Indexes: []Index{{Name: "idx_email", Columns: []string{"email"}, Unique: true}}},
+			{Name: "teams", Columns: []Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+		},
+		Relations: []Relation{
+			{FromTable: "users", FromColumns: []string{"team_id"}, ToTable: "teams", ToColumns: []string{"id"}},
+			{FromTable: "users", FromColumns: []string{"missing"}, ToTable: "teams", ToColumns: []string{"id"}},
+		},
+	}
+	g := NewERDiagram(s)
+	want := map[string]string{
+		"table-users":           "users",
+		"table-users-id":        "PK id: int",
+		"table-users-email":     "email: text NOT NULL UNIQUE",
+		"table-users-team_id":   "FK team_id: int",
+		"table-users-indexes":   "",
+		"table-users-idx_email": "UNIQUE idx_email (email)",
+		"table-teams":           "teams",
+		"table-teams-id":        "PK id: int",
+	}
+	if got := vertexLabels(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := edgeList(g), []string{"table-users-team_id->table-teams-id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %v, want %v", got, want)
+	}
+	if e := g.FindByID("table-users-team_id-table-teams-id"); e.Style.Attributes["startArrow"] != "ERmany" || e.Style.Attributes["endArrow"] != "ERmandOne" {
+		t.Errorf("got relation style %v", e.Style.Attributes)
+	}
+	if _, h := g.FindByID("table-users").Geometry.size(); h != erHeader+3*erRowHeight+erLineHeight+erRowHeight {
+		t.Errorf("got table height %d", h)
+	}
+	if issues := ValidateStyles(g); len(issues) > 0 {
+		t.Errorf("got style issues %v", issues)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"User", "user"},
+		{"UserID", "user_id"},
+		{"HTTPServer", "http_server"},
+		{"CreditCard2", "credit_card2"},
+		{"already_snake", "already_snake"},
+		{"ID", "id"},
+	} {
+		if got := snakeCase(test.in); got != test.want {
+			t.Errorf("snakeCase(%q): got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestPlural(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"user", "users"},
+		{"company", "companies"},
+		{"day", "days"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"match", "matches"},
+	} {
+		if got := plural(test.in); got != test.want {
+			t.Errorf("plural(%q): got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+// The GORM models are exported as GORM derives foreign keys from the
+// type names.
+type gormBase struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+}
+
+type Company struct {
+	ID   int
+	Name string `gorm:"size:100;uniqueIndex"`
+}
+
+type Customer struct {
+	gormBase
+	Name        string `gorm:"not null;index:idx_name_age,priority:2"`
+	Age         int    `gorm:"index:idx_name_age,priority:1"`
+	CompanyID   int
+	Company     Company
+	CreditCards []CreditCard
+	Languages   []Language `gorm:"many2many:customer_languages;"`
+	secret      string
+}
+
+type CreditCard struct {
+	ID         uint
+	Number     string
+	CustomerID uint
+}
+
+type Language struct {
+	ID   uint
+	Name string
+}
+
+func TestGORMSchema(t *testing.T) {
+	s, err := GORMSchema(&Customer{}, Company{}, CreditCard{}, Language{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ERSchema{
+		Tables: []Table{
+			{Name: "customers", Columns: []Column{
+				{Name: "id", Type: "bigint", PrimaryKey: true},
+				{Name: "created_at", Type: "datetime"},
+				{Name: "name", Type: "text", NotNull: true},
+				{Name: "age", Type: "bigint"},
+				{Name: "company_id", Type: "bigint"},
+			}, Indexes: []Index{{Name: "idx_name_age", Columns: []string{"age", "name"}}}},
+			{Name: "companies", Columns: []Column{
+				{Name: "id", Type: "bigint", PrimaryKey: true},
+				{Name: "name", Type: "varchar(100)"},
+			}, Indexes: []Index{{Name: "idx_companies_name", Columns: []string{"name"}, Unique: true}}},
+			{Name: "credit_cards", Columns: []Column{
+				{Name: "id", Type: "bigint", PrimaryKey: true},
+				{Name: "number", Type: "text"},
+				{Name: "customer_id", Type: "bigint"},
+			}},
+			{Name: "languages", Columns: []Column{
+				{Name: "id", Type: "bigint", PrimaryKey: true},
+				{Name: "name", Type: "text"},
+			}},
+			{Name: "customer_languages", Columns: []Column{
+				{Name: "customer_id", Type: "bigint", PrimaryKey: true},
+				{Name: "language_id", Type: "bigint", PrimaryKey: true},
+			}},
+		},
+		Relations: []Relation{
+			{FromTable: "customers", FromColumns: []string{"company_id"}, ToTable: "companies", ToColumns: []string{"id"}},
+			{FromTable: "credit_cards", FromColumns: []string{"customer_id"}, ToTable: "customers", ToColumns: []string{"id"}},
+			{FromTable: "customer_languages", FromColumns: []string{"customer_id"}, ToTable: "customers", ToColumns: []string{"id"}},
+			{FromTable: "customer_languages", FromColumns: []string{"language_id"}, ToTable: "languages", ToColumns: []string{"id"}},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got\n%+v\nwant\n%+v", s, want)
+	}
+}
+
+func TestGORMSchemaErrors(t *testing.T) {
+	for _, v := range []interface{}{3, (*Company)(nil)} {
+		if _, err := GORMSchema(v); err == nil {
+			t.Errorf("GORMSchema(%#v): got no error", v)
+		}
+	}
+}
+
+func TestEntSchema(t *testing.T) {
+	in := `{"Schema":"x/ent/schema","Package":"x/ent","Schemas":[
+{"name":"User","config":{"Table":""},"edges":[{"name":"cars","type":"Car"},{"name":"groups","type":"Group"}],
+ "fields":[{"name":"age","type":{"Type":12,"Ident":""}},{"name":"name","type":{"Type":7},"unique":true}],
+ "indexes":[{"fields":["age","name"],"unique":true}]},
+{"name":"Car","config":{"Table":""},"edges":[{"name":"owner","type":"User","ref_name":"cars","unique":true,"inverse":true}],"fields":[{"name":"model","type":{"Type":7}}]},
+{"name":"Group","config":{"Table":""},"edges":[{"name":"users","type":"User","ref_name":"groups","inverse":true}],"fields":[]}
+]}`
+	want := ERSchema{
+		Tables: []Table{
+			{Name: "users", Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "age", Type: "int", NotNull: true},
+				{Name: "name", Type: "string", NotNull: true, Unique: true},
+			}, Indexes: []Index{{Name: "user_age_name", Columns: []string{"age", "name"}, Unique: true}}},
+			{Name: "cars", Columns: []Column{
+				{Name: "id", Type: "int", PrimaryKey: true},
+				{Name: "model", Type: "string", NotNull: true},
+				{Name: "user_cars", Type: "int"},
+			}},
+			{Name: "groups", Columns: []Column{{Name: "id", Type: "int", PrimaryKey: true}}},
+			{Name: "user_groups", Columns: []Column{
+				{Name: "user_id", Type: "int", PrimaryKey: true},
+				{Name: "group_id", Type: "int", PrimaryKey: true},
+			}},
+		},
+		Relations: []Relation{
+			{FromTable: "cars", FromColumns: []string{"user_cars"}, ToTable: "users", ToColumns: []string{"id"}},
+			{FromTable: "user_groups", FromColumns: []string{"user_id"}, ToTable: "users", ToColumns: []string{"id"}},
+			{FromTable: "user_groups", FromColumns: []string{"group_id"}, ToTable: "groups", ToColumns: []string{"id"}},
+		},
+	}
+	for _, test := range []struct {
+		name, in string
+	}{
+		{"snapshot", in},
+		{"list", in[strings.Index(in, "[") : strings.LastIndex(in, "]")+1]},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := EntSchema(strings.NewReader(test.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s, want) {
+				t.Errorf("got\n%+v\nwant\n%+v", s, want)
+			}
+		})
+	}
+	if _, err := EntSchema(strings.NewReader("{")); err == nil || !strings.HasPrefix(err.Error(), "graw: EntSchema: ") {
+		t.Errorf("got error %v for malformed JSON", err)
+	}
+}
@@ -0,0 +1,44 @@
+package graw
+
+// FindByID returns the cell of g with the given id, or nil. The cell
+// is looked up in an index of g maintained by Add, Remove,
+// RemoveCascade and Replace, taking constant time, unless cells were
+// added to or removed from Root directly; lookups then scan Root until
+// the next call to Add. The returned cell points into g.Root.
+//
+// Cells renamed by setting their ID in Root directly are not found by
+// their new ID until the next call to Remove or Replace.
+func (g *GraphModel) FindByID(id string) *Cell {
+	return g.cell(id)
+}
+
+// FindByValue returns the cells of g labeled value, either verbatim or
+// as plain text, without the markup of HTML labels and with their
+// placeholders resolved, in document order. The cells point into
+// g.Root.
+func (g *GraphModel) FindByValue(value string) []*Cell {
+	var cells []*Cell
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.Value == value || labelText(c) == value {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+// FindByStyle returns the cells of g whose style match accepts, in
+// document order, e.g. all cells filled red:
+//
+//	g.FindByStyle(func(s Style) bool { return s.Attributes["fillColor"] == "#f8cecc" })
+//
+// The cells point into g.Root.
+func (g *GraphModel) FindByStyle(match func(s Style) bool) []*Cell {
+	var cells []*Cell
+	for i := range g.Root {
+		if match(g.Root[i].Style) {
+			cells = append(cells, g.Root[i])
+		}
+	}
+	return cells
+}
@@ -0,0 +1,167 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindByID(t *testing.T) {
+	tests := []struct {
+		name string
+		// edit changes the model a, b, c, d built with Add.
+		edit  func(g *GraphModel)
+		found map[string]string // ID to the label of the cell found
+		gone  []string
+	}{
+		{
+			name:  "added",
+			edit:  func(g *GraphModel) {},
+			found: map[string]string{"a": "A", "b": "B", "c": "C", "0": "", "1": ""},
+			gone:  []string{"x", ""},
+		},
+		{
+			name:  "appended to Root",
+			edit:  func(g *GraphModel) { g.Root = append(g.Root, NewShape("x", "1").Label("X")) },
+			found: map[string]string{"a": "A", "x": "X"},
+		},
+		{
+			name:  "removed from Root",
+			edit:  func(g *GraphModel) { g.Root = append(g.Root[:2], g.Root[3:]...) },
+			found: map[string]string{"b": "B", "c": "C"},
+			gone:  []string{"a"},
+		},
+		{
+			name: "removed and appended",
+			edit: func(g *GraphModel) {
+				g.Root = append(g.Root[:2], g.Root[3:]...)
+				g.Root = append(g.Root, NewShape("x", "1").Label("X"))
+			},
+			found: map[string]string{"b": "B", "c": "C", "x": "X"},
+			gone:  []string{"a"},
+		},
+		{
+			name: "swapped in Root",
+			edit: func(g *GraphModel) {
+				g.Root[2], g.Root[3] = g.Root[3], g.Root[2]
+			},
+			found: map[string]string{"a": "A", "b": "B", "c": "C"},
+		},
+		{
+			name: "added after Root changed",
+			edit: func(g *GraphModel) {
+				g.Root = g.Root[1:]
+				g.Add(NewShape("x", "1").Label("X"))
+			},
+			found: map[string]string{"a": "A", "b": "B", "x": "X", "1": ""},
+			gone:  []string{"0"},
+		},
+		{
+			name:  "renamed",
+			edit:  func(g *GraphModel) { g.Root[2].ID = "z" },
+			found: map[string]string{"b": "B"},
+			gone:  []string{"a"},
+		},
+		{
+			name:  "renamed and removed",
+			edit:  func(g *GraphModel) { g.Root[2].ID = "z"; g.Remove("c") },
+			found: map[string]string{"b": "B", "z": "A"},
+			gone:  []string{"a", "c"},
+		},
+		{
+			name:  "duplicate",
+			edit:  func(g *GraphModel) { g.Add(NewShape("a", "1").Label("A2")) },
+			found: map[string]string{"a": "A"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			for _, id := range []string{"a", "b", "c"} {
+				g.Add(NewShape(id, "1").Label(strings.ToUpper(id)))
+			}
+			tt.edit(&g)
+			for id, label := range tt.found {
+				c := g.FindByID(id)
+				if c == nil || c.ID != id || c.Value != label {
+					t.Errorf("FindByID(%q): got %+v, want the cell labeled %q", id, c, label)
+				}
+			}
+			for _, id := range tt.gone {
+				if c := g.FindByID(id); c != nil {
+					t.Errorf("FindByID(%q): got %+v, want nil", id, c)
+				}
+			}
+		})
+	}
+}
+
+func TestFindByValue(t *testing.T) {
+	g := NewGraph()
+	g.Add(NewShape("plain", "1").Label("Database"))
+	html := NewShape("html", "1").Label("<b>Database</b>")
+	html.Style.Attributes = map[string]string{"html": "1"}
+	g.Add(html)
+	g.Add(NewShape("markup", "1").Label("<b>Database</b>"))
+	g.Add(NewShape("placeholder", "1").Label("%name%").SetData("name", "Database").SetPlaceholders(true))
+	g.Add(NewShape("off", "1").Label("%name%").SetData("name", "Database"))
+	g.Add(NewShape("other", "1").Label("database"))
+
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"Database", []string{"plain", "html", "placeholder"}},
+		{"<b>Database</b>", []string{"html", "markup"}},
+		{"%name%", []string{"placeholder", "off"}},
+		{"Cache", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var got []string
+			for _, c := range g.FindByValue(tt.value) {
+				got = append(got, c.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindByStyle(t *testing.T) {
+	g := NewGraph()
+	red := NewShape("red", "1")
+	red.Style.Attributes = map[string]string{"fillColor": "#f8cecc"}
+	g.Add(red)
+	g.Add(NewShape("plain", "1"))
+	e := newEdgeCell("e", "1", "red", "plain")
+	e.Style.Attributes = map[string]string{"edgeStyle": "orthogonalEdgeStyle"}
+	g.Add(e)
+
+	tests := []struct {
+		name  string
+		match func(s Style) bool
+		want  []string
+	}{
+		{"fill", func(s Style) bool { return s.Attributes["fillColor"] == "#f8cecc" }, []string{"red"}},
+		{"edges", func(s Style) bool { _, ok := s.Attributes["edgeStyle"]; return ok }, []string{"e"}},
+		{"none", func(s Style) bool { return false }, nil},
+		{"all", func(s Style) bool { return true }, []string{"0", "1", "red", "plain", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range g.FindByStyle(tt.match) {
+				got = append(got, c.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			// The cells found are those of the model.
+			if cells := g.FindByStyle(tt.match); len(cells) > 0 && cells[0] != g.FindByID(cells[0].ID) {
+				t.Error("got a copy of the cell")
+			}
+		})
+	}
+}
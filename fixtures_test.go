@@ -0,0 +1,214 @@
+package graw_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	graw "github.com/fuguohong1024/draw"
+	"github.com/fuguohong1024/draw/grawtest"
+)
+
+// The files in testdata are synthetic: they were written by hand after
+// the format of app.diagrams.net, not exported from it, see
+// testdata/README.md. basic.drawio is saved uncompressed,
+// compressed.drawio holds the same diagram compressed, multipage.drawio
+// has two pages and model.xml is a bare model as shown by Extras >
+// Edit Diagram.
+
+// canonicalModel returns the first mxGraphModel element of data in
+// canonical form, with the differences draw.io ignores removed: style
+// entries are sorted, empty values and styles dropped and object
+// elements named UserObject.
+func canonicalModel(t *testing.T, data []byte) []byte {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 && tt.Name.Local != "mxGraphModel" {
+				continue
+			}
+			depth++
+			if tt.Name.Local == "object" {
+				tt.Name.Local = "UserObject"
+			}
+			var attrs []xml.Attr
+			for _, a := range tt.Attr {
+				switch {
+				case (a.Name.Local == "value" || a.Name.Local == "style") && a.Value == "":
+					continue
+				case a.Name.Local == "style":
+					parts := strings.Split(strings.TrimSuffix(a.Value, ";"), ";")
+					sort.Strings(parts)
+					a.Value = strings.Join(parts, ";")
+				}
+				attrs = append(attrs, a)
+			}
+			tt.Attr = attrs
+			tok = tt
+		case xml.EndElement:
+			if depth == 0 {
+				continue
+			}
+			if depth--; tt.Name.Local == "object" {
+				tt.Name.Local = "UserObject"
+			}
+			tok = tt
+		default:
+			if depth == 0 {
+				continue
+			}
+		}
+		if err := e.EncodeToken(tok); err != nil {
+			t.Fatal(err)
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := grawtest.Canonicalize(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseFixtures(t *testing.T) {
+	want := canonicalModel(t, readFixture(t, "basic.drawio"))
+	for _, name := range []string{"basic.drawio", "compressed.drawio", "model.xml"} {
+		t.Run(name, func(t *testing.T) {
+			g, err := graw.ParseFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := xml.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := canonicalModel(t, out); !bytes.Equal(got, want) {
+				t.Errorf("round trip differs:\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestParseFixtureCells(t *testing.T) {
+	g, err := graw.ParseFile(filepath.Join("testdata", "basic.drawio"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	grawtest.CellCount(t, g, 9)
+	grawtest.HasEdge(t, g, "Tq8-api", "Tq8-db")
+
+	tests := []struct {
+		id    string
+		check func(c *graw.Cell) bool
+	}{
+		{"Tq8-api", func(c *graw.Cell) bool {
+			return c.Value == "API Gateway" && c.Style.Attributes["fillColor"] == "#dae8fc" && c.Geometry.X == 120 && c.Geometry.Width == "120"
+		}},
+		{"Tq8-e1", func(c *graw.Cell) bool {
+			return len(c.Geometry.Points) == 3 && c.Geometry.Points[2] == graw.Point{XMLName: c.Geometry.Points[2].XMLName, X: 400, Y: 120}
+		}},
+		{"Tq8-l1", func(c *graw.Cell) bool {
+			return c.ParentID == "Tq8-e1" && c.Connectable == "0" && c.Geometry.Position == -0.2 && c.Geometry.Point != nil
+		}},
+		{"Tq8-e2", func(c *graw.Cell) bool {
+			return c.Source == "" && c.Geometry.SourcePoint.X == 120 && c.Geometry.TargetPoint.X == 240
+		}},
+		{"Tq8-pay", func(c *graw.Cell) bool {
+			return c.Value == "Payments" && c.Link() == "https://example.com/payments" && c.Tooltip() == "Owned by team payments" && c.HasTag("team-payments")
+		}},
+		{"Tq8-bill", func(c *graw.Cell) bool {
+			return c.Placeholders && c.ResolvedValue() == "billing (gold)"
+		}},
+		{"Tq8-grp", func(c *graw.Cell) bool {
+			b := c.Geometry.AlternateBounds
+			return c.Collapsed == "1" && b != nil && b.Width == 200 && b.Height == 200
+		}},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Errorf("no cell %q", tt.id)
+			continue
+		}
+		if !tt.check(c) {
+			t.Errorf("cell %q parsed as %+v, geometry %+v", tt.id, c, c.Geometry)
+		}
+	}
+}
+
+func TestParseFixtureDocument(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "multipage.drawio"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, err := graw.ParseDocument(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Host != "graw.test" || d.Version != "24.7.17" {
+		t.Errorf("got host %q version %q", d.Host, d.Version)
+	}
+	pages := d.Pages()
+	if len(pages) != 2 || pages[0].Name != "Overview" || pages[1].Name != "Notes" || pages[0].ID != "p1-overview" || pages[1].ID != "p2-notes" {
+		t.Fatalf("got pages %+v", pages)
+	}
+	notes := pages[1].Model
+	if notes.Background != "#FFFFFF" || notes.Page != "0" {
+		t.Errorf("page settings: background %q, page %q", notes.Background, notes.Page)
+	}
+	if c := notes.FindByID("n1"); c == nil || c.Value != "<b>Note</b><br>R&amp;D" || c.ParentID != "L2" {
+		t.Errorf("cell n1: %+v", c)
+	}
+	if c := notes.FindByID("n2"); c == nil || c.Link() != "data:page/id,"+pages[0].ID {
+		t.Errorf("cell n2: %+v", c)
+	}
+
+	// The document written back parses to the same pages.
+	out, err := xml.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := graw.ParseDocument(bytes.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range again.Pages() {
+		want, _ := xml.Marshal(pages[i].Model)
+		got, _ := xml.Marshal(p.Model)
+		if p.Name != pages[i].Name || p.ID != pages[i].ID || !bytes.Equal(got, want) {
+			t.Errorf("page %d changed:\n%s\nwant\n%s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package graw
+
+// The methods below set a single property of a cell and return the
+// cell, so that a shape can be built in one expression:
+//
+//	g.Add(NewShape("api", "1").At(40, 40).Size(200, 80).
+//		Label("API Gateway").Fill("#dae8fc").Stroke("#6c8ebf"))
+//
+// They only touch the fields they are named after, so they can be
+// mixed freely with setting the fields of Cell directly. As the model
+// holds the cells it was given, they also work on cells already added.
+
+// geometry returns the geometry of c, creating the default one if c
+// has none.
+func (c *Cell) geometry() *Geometry {
+	if c.Geometry == nil {
+		c.Geometry = newGeometry()
+	}
+	return c.Geometry
+}
+
+// At moves c to (x, y), relative to its parent.
+func (c *Cell) At(x, y int) *Cell {
+	geo := c.geometry()
+	geo.X, geo.Y = x, y
+	return c
+}
+
+// Size sets the width and height of c.
+func (c *Cell) Size(width, height int) *Cell {
+	c.geometry().setSize(width, height)
+	return c
+}
+
+// Label sets the label of c.
+func (c *Cell) Label(text string) *Cell {
+	c.Value = text
+	return c
+}
+
+// Fill sets the fill color of c, e.g. "#dae8fc" or "none".
+func (c *Cell) Fill(color string) *Cell {
+	return c.Styled("fillColor", color)
+}
+
+// Stroke sets the color of the outline of a vertex or of the line of
+// an edge.
+func (c *Cell) Stroke(color string) *Cell {
+	return c.Styled("strokeColor", color)
+}
+
+// FontColor sets the color of the label of c.
+func (c *Cell) FontColor(color string) *Cell {
+	return c.Styled("fontColor", color)
+}
+
+// Rounded rounds the corners of c, or not.
+func (c *Cell) Rounded(on bool) *Cell {
+	return c.Styled("rounded", flag(on))
+}
+
+// Dashed draws the outline or the line of c dashed, or not.
+func (c *Cell) Dashed(on bool) *Cell {
+	return c.Styled("dashed", flag(on))
+}
+
+// Styled sets the style property key of c to value, for properties
+// without a method of their own, e.g. Styled("shape", "cylinder").
+func (c *Cell) Styled(key, value string) *Cell {
+	c.Style.set(key, value)
+	return c
+}
+
+// In moves c into the layer or container with ID parentID. The
+// position of c is kept, so it is now relative to the new parent.
+func (c *Cell) In(parentID string) *Cell {
+	c.ParentID = parentID
+	return c
+}
@@ -0,0 +1,71 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFluent(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() *Cell
+		check func(c *Cell) bool
+	}{
+		{"at", func() *Cell { return NewShape("a", "1").At(40, 50) },
+			func(c *Cell) bool { return c.Geometry.X == 40 && c.Geometry.Y == 50 }},
+		{"at without geometry", func() *Cell { return newEdgeCell("e", "1", "a", "b").At(5, 6) },
+			func(c *Cell) bool { return c.Geometry.X == 5 && c.Geometry.Y == 6 && c.Geometry.As == "geometry" }},
+		{"size", func() *Cell { return NewShape("a", "1").Size(200, 80) },
+			func(c *Cell) bool { w, h := c.Geometry.size(); return w == 200 && h == 80 && c.Geometry.X == 10 }},
+		{"label", func() *Cell { return NewShape("a", "1").Label("API") }, func(c *Cell) bool { return c.Value == "API" }},
+		{"in", func() *Cell { return NewShape("a", "1").At(1, 2).In("grp") },
+			func(c *Cell) bool { return c.ParentID == "grp" && c.Geometry.X == 1 && c.Geometry.Y == 2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := tt.build(); !tt.check(c) {
+				t.Errorf("got %+v with geometry %+v", c, c.Geometry)
+			}
+		})
+	}
+}
+
+func TestFluentStyle(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(c *Cell) *Cell
+		want map[string]string
+	}{
+		{"fill", func(c *Cell) *Cell { return c.Fill("#dae8fc") }, map[string]string{"fillColor": "#dae8fc"}},
+		{"stroke", func(c *Cell) *Cell { return c.Stroke("none") }, map[string]string{"strokeColor": "none"}},
+		{"font color", func(c *Cell) *Cell { return c.FontColor("#333333") }, map[string]string{"fontColor": "#333333"}},
+		{"rounded", func(c *Cell) *Cell { return c.Rounded(true) }, map[string]string{"rounded": "1"}},
+		{"not dashed", func(c *Cell) *Cell { return c.Dashed(false) }, map[string]string{"dashed": "0"}},
+		{"styled", func(c *Cell) *Cell { return c.Styled("shape", "cylinder") }, map[string]string{"shape": "cylinder"}},
+		{"last wins", func(c *Cell) *Cell { return c.Fill("#ffffff").Fill("#000000") }, map[string]string{"fillColor": "#000000"}},
+		{"chained", func(c *Cell) *Cell { return c.Fill("#dae8fc").Stroke("#6c8ebf").Rounded(true) },
+			map[string]string{"fillColor": "#dae8fc", "strokeColor": "#6c8ebf", "rounded": "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1")
+			if got := tt.set(c); got != c {
+				t.Fatalf("got %p, want the cell %p", got, c)
+			}
+			if !reflect.DeepEqual(c.Style.Attributes, tt.want) {
+				t.Errorf("got %v, want %v", c.Style.Attributes, tt.want)
+			}
+		})
+	}
+}
+
+func TestFluentAfterAdd(t *testing.T) {
+	g := NewGraph()
+	c := NewShape("a", "1")
+	g.Add(c)
+	c.At(100, 200).Label("moved").Fill("#ff0000")
+	got := g.FindByID("a")
+	if got.Geometry.X != 100 || got.Geometry.Y != 200 || got.Value != "moved" || got.Style.Attributes["fillColor"] != "#ff0000" {
+		t.Errorf("got %+v, want the changes made after Add", got)
+	}
+}
@@ -0,0 +1,93 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+)
+
+// Dimensions of funnels and pyramids.
+const (
+	funnelWidth       = 400
+	funnelMinWidth    = 80
+	funnelStageHeight = 60
+)
+
+// FunnelStage is a stage of a funnel or a level of a pyramid.
+type FunnelStage struct {
+	Label string
+	Value float64
+}
+
+// NewFunnel returns a funnel chart: a trapezoid per stage stacked from
+// top to bottom, as wide as the value of the stage relative to the
+// largest and narrowing to the width of the next stage. Stages are
+// labeled with their value and the share of the previous stage they
+// keep.
+func NewFunnel(stages []FunnelStage) (*GraphModel, error) {
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("graw: NewFunnel: no stages")
+	}
+	max := 0.0
+	for _, s := range stages {
+		if s.Value < 0 || math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+			return nil, fmt.Errorf("graw: NewFunnel: stage %q has value %v", s.Label, s.Value)
+		}
+		max = math.Max(max, s.Value)
+	}
+	widths := make([]int, len(stages)+1)
+	for i, s := range stages {
+		widths[i] = funnelMinWidth
+		if max > 0 {
+			widths[i] += int(float64(funnelWidth-funnelMinWidth) * s.Value / max)
+		}
+	}
+	widths[len(stages)] = widths[len(stages)-1] * 3 / 5
+
+	g := NewGraph()
+	taken := g.idSet()
+	for i, s := range stages {
+		c := funnelStage(taken, i, s, widths[i], minInt(widths[i], widths[i+1]))
+		c.Style.set("flipV", "1")
+		if i > 0 && stages[i-1].Value > 0 {
+			c.Value += " (" + strconv.FormatFloat(100*s.Value/stages[i-1].Value, 'f', 1, 64) + "%)"
+		}
+		g.Add(c)
+	}
+	return &g, nil
+}
+
+// NewPyramid returns a pyramid chart: a triangle split into a
+// trapezoid per level, the first level at the top, each labeled with
+// its value. Unlike funnels, the levels are as high and sloped alike
+// whatever their values.
+func NewPyramid(levels []FunnelStage) (*GraphModel, error) {
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("graw: NewPyramid: no levels")
+	}
+	g := NewGraph()
+	taken := g.idSet()
+	n := len(levels)
+	for i, l := range levels {
+		g.Add(funnelStage(taken, i, l, funnelWidth*(i+1)/n, funnelWidth*i/n))
+	}
+	return &g, nil
+}
+
+// funnelStage returns the trapezoid of the ith stage s of a funnel or
+// pyramid, centered, its wide side width pixels and its narrow side
+// narrow pixels.
+func funnelStage(taken idSet, i int, s FunnelStage, width, narrow int) *Cell {
+	c := NewShape(taken.unique("stage-"+s.Label), rootCellID)
+	c.Value = html.EscapeString(s.Label) + "<br><b>" + strconv.FormatFloat(s.Value, 'f', -1, 64) + "</b>"
+	c.Style = Style{Attributes: shapeStyle("trapezoid")}
+	c.Style.set("perimeter", "trapezoidPerimeter")
+	c.Style.set("fixedSize", "1")
+	c.Style.setFloat("size", float64(width-narrow)/2)
+	c.Style.set("fillColor", ganttColors[i%len(ganttColors)])
+	c.Geometry.X = 10 + (funnelWidth-width)/2
+	c.Geometry.Y = 10 + i*funnelStageHeight
+	c.Geometry.setSize(width, funnelStageHeight)
+	return c
+}
@@ -0,0 +1,122 @@
+package graw
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewFunnel(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []FunnelStage
+		ids    []string
+		labels []string
+		widths []int
+		sizes  []float64
+	}{
+		{
+			name:   "halving",
+			stages: []FunnelStage{{"visits", 1000}, {"signups", 500}, {"paid", 100}},
+			ids:    []string{"stage-visits", "stage-signups", "stage-paid"},
+			labels: []string{
+				"visits<br><b>1000</b>",
+				"signups<br><b>500</b> (50.0%)",
+				"paid<br><b>100</b> (20.0%)",
+			},
+			widths: []int{400, 240, 112},
+			sizes:  []float64{80, 64, 22.5},
+		},
+		{
+			name:   "zero stage",
+			stages: []FunnelStage{{"a & b", 0}, {"a & b", 0}},
+			ids:    []string{"stage-a & b", "stage-a & b-2"},
+			labels: []string{"a &amp; b<br><b>0</b>", "a &amp; b<br><b>0</b>"},
+			widths: []int{80, 80},
+			sizes:  []float64{0, 16},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewFunnel(tt.stages)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, id := range tt.ids {
+				c := g.FindByID(id)
+				if c == nil {
+					t.Fatalf("%s: missing", id)
+				}
+				if c.Value != tt.labels[i] {
+					t.Errorf("%s: got label %q, want %q", id, c.Value, tt.labels[i])
+				}
+				if w, _ := c.Geometry.size(); w != tt.widths[i] {
+					t.Errorf("%s: got width %d, want %d", id, w, tt.widths[i])
+				}
+				if got, _ := c.Style.float("size"); got != tt.sizes[i] {
+					t.Errorf("%s: got size %v, want %v", id, got, tt.sizes[i])
+				}
+				if got := c.Geometry.X*2 + tt.widths[i]; got != 20+funnelWidth {
+					t.Errorf("%s: got %d, want the stage centered", id, got)
+				}
+				if got := c.Style.Attributes["flipV"]; got != "1" {
+					t.Errorf("%s: got flipV %q, want 1", id, got)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewPyramid(t *testing.T) {
+	g, err := NewPyramid([]FunnelStage{{"top", 1}, {"middle", 10}, {"base", 100}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range []struct {
+		id    string
+		width int
+		size  float64
+	}{
+		{"stage-top", 133, 66.5},
+		{"stage-middle", 266, 66.5},
+		{"stage-base", 400, 67},
+	} {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Fatalf("%s: missing", tt.id)
+		}
+		if w, _ := c.Geometry.size(); w != tt.width {
+			t.Errorf("%s: got width %d, want %d", tt.id, w, tt.width)
+		}
+		if got, _ := c.Style.float("size"); got != tt.size {
+			t.Errorf("%s: got size %v, want %v", tt.id, got, tt.size)
+		}
+		if c.Geometry.Y != 10+i*funnelStageHeight {
+			t.Errorf("%s: got y %d, want %d", tt.id, c.Geometry.Y, 10+i*funnelStageHeight)
+		}
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewFunnelErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []FunnelStage
+	}{
+		{"none", nil},
+		{"negative", []FunnelStage{{"a", -1}}},
+		{"nan", []FunnelStage{{"a", math.NaN()}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewFunnel(tt.stages); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+	if _, err := NewPyramid(nil); err == nil {
+		t.Error("NewPyramid: got no error")
+	}
+}
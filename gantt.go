@@ -0,0 +1,178 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
+// Dimensions of Gantt charts.
+const (
+	ganttLabelWidth = 180
+	ganttRowHeight  = 30
+	ganttBarHeight  = 20
+	ganttDayWidth   = 24
+	ganttWeekWidth  = 70
+)
+
+// ganttColors are the fill colors of the bars, one per assignee.
+var ganttColors = []string{"#dae8fc", "#d5e8d4", "#ffe6cc", "#e1d5e7", "#fff2cc", "#f8cecc", "#f5f5f5"}
+
+// Task is a task of a Gantt chart.
+type Task struct {
+	ID       string
+	Name     string
+	Assignee string
+	// Start and End are the first and last day of the task; tasks
+	// with a zero End are drawn as milestones.
+	Start, End time.Time
+	// Blocks lists the IDs of the tasks which cannot start before
+	// this one is done.
+	Blocks []string
+}
+
+// NewGantt returns a Gantt chart of tasks: a row per task, grouped by
+// assignee in the order they first appear, with a bar spanning the
+// days of the task below a time axis of days, or of weeks for charts
+// longer than two months. Tasks blocking others are connected to them
+// with arrows; links to unknown tasks are ignored.
+func NewGantt(tasks []Task) (*GraphModel, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("graw: NewGantt: no tasks")
+	}
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	first, last := day(tasks[0].Start), day(tasks[0].Start)
+	byID := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		if t.Start.IsZero() {
+			return nil, fmt.Errorf("graw: NewGantt: task %q has no start", t.Name)
+		}
+		if !t.End.IsZero() && t.End.Before(t.Start) {
+			return nil, fmt.Errorf("graw: NewGantt: task %q ends before it starts", t.Name)
+		}
+		if t.ID != "" {
+			byID[t.ID] = i
+		}
+		if s := day(t.Start); s.Before(first) {
+			first = s
+		}
+		if e := day(t.End); e.After(last) {
+			last = e
+		} else if s := day(t.Start); s.After(last) {
+			last = s
+		}
+	}
+
+	// the time axis, in days or in weeks starting on Mondays
+	unit, unitWidth, format := 1, ganttDayWidth, "2"
+	days := int(last.Sub(first).Hours()/24) + 1
+	if days > 62 {
+		unit, unitWidth, format = 7, ganttWeekWidth, "Jan 2"
+		first = first.AddDate(0, 0, -(int(first.Weekday())+6)%7)
+		days = int(last.Sub(first).Hours()/24) + 1
+	}
+	pos := func(t time.Time) int {
+		return ganttLabelWidth + int(day(t).Sub(first).Hours()/24)*unitWidth/unit
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	for d := first; !d.After(last); d = d.AddDate(0, 0, unit) {
+		c := NewShape(taken.unique("axis-"+d.Format("2006-01-02")), rootCellID)
+		c.Value = d.Format(format)
+		c.Style = Style{Attributes: shapeStyle("text")}
+		c.Style.set("align", "center")
+		c.Style.set("fontSize", "10")
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			c.Style.set("fontColor", "#999999")
+		}
+		c.Geometry.X, c.Geometry.Y = pos(d), 10
+		c.Geometry.setSize(unitWidth, ganttRowHeight)
+		g.Add(c)
+	}
+
+	var assignees []string
+	rows := make(map[string][]int)
+	for i, t := range tasks {
+		if _, ok := rows[t.Assignee]; !ok {
+			assignees = append(assignees, t.Assignee)
+		}
+		rows[t.Assignee] = append(rows[t.Assignee], i)
+	}
+
+	bars := make([]*Cell, len(tasks))
+	y := 10 + ganttRowHeight
+	width := days * unitWidth / unit
+	for n, a := range assignees {
+		if a != "" || len(assignees) > 1 {
+			lane := NewShape(taken.unique("assignee-"+a), rootCellID)
+			lane.Value = "<b>" + html.EscapeString(a) + "</b>"
+			if a == "" {
+				lane.Value = "<b>unassigned</b>"
+			}
+			lane.Style = Style{Attributes: shapeStyle("text")}
+			lane.Style.set("align", "left")
+			lane.Geometry.X, lane.Geometry.Y = 10, y
+			lane.Geometry.setSize(ganttLabelWidth-10+width, ganttRowHeight)
+			g.Add(lane)
+			y += ganttRowHeight
+		}
+		for _, i := range rows[a] {
+			t := tasks[i]
+			label := NewShape(taken.unique("label-"+t.Name), rootCellID)
+			label.Value = html.EscapeString(t.Name)
+			label.Style = Style{Attributes: shapeStyle("text")}
+			label.Style.set("align", "left")
+			label.Style.set("spacingLeft", "10")
+			label.Geometry.X, label.Geometry.Y = 10, y
+			label.Geometry.setSize(ganttLabelWidth-10, ganttRowHeight)
+			g.Add(label)
+
+			id := t.ID
+			if id == "" {
+				id = t.Name
+			}
+			bar := NewShape(taken.unique("task-"+id), rootCellID)
+			bar.Style = Style{Attributes: shapeStyle("rounded")}
+			end := t.End
+			if end.IsZero() {
+				end = t.Start
+			}
+			x := pos(t.Start)
+			w := pos(end.AddDate(0, 0, 1)) - x
+			if t.End.IsZero() {
+				// a milestone
+				bar.Style = Style{Attributes: shapeStyle("rhombus")}
+				w = ganttBarHeight
+			}
+			bar.Style.set("fillColor", ganttColors[n%len(ganttColors)])
+			bar.Geometry.X, bar.Geometry.Y = x, y+(ganttRowHeight-ganttBarHeight)/2
+			bar.Geometry.setSize(w, ganttBarHeight)
+			bar.Data = map[string]string{
+				"tooltip": fmt.Sprintf("%s: %s – %s", t.Name, t.Start.Format("2006-01-02"), end.Format("2006-01-02")),
+			}
+			bars[i] = bar
+			g.Add(bar)
+			y += ganttRowHeight
+		}
+	}
+
+	for i, t := range tasks {
+		for _, id := range t.Blocks {
+			j, ok := byID[id]
+			if !ok || j == i {
+				continue
+			}
+			e := newEdgeCell(taken.unique(bars[i].ID+"-"+bars[j].ID), rootCellID, bars[i].ID, bars[j].ID)
+			e.Style.set("edgeStyle", "orthogonalEdgeStyle")
+			e.Style.set("exitX", "1")
+			e.Style.set("exitY", "0.5")
+			e.Style.set("entryX", "0")
+			e.Style.set("entryY", "0.5")
+			g.Add(e)
+		}
+	}
+	return &g, nil
+}
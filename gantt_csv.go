@@ -0,0 +1,179 @@
+package graw
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// taskColumns maps the lower case headers of task CSV files, including
+// those of Jira issue exports, to the Task fields they hold.
+var taskColumns = map[string]string{
+	"id":                             "id",
+	"key":                            "id",
+	"issue key":                      "id",
+	"name":                           "name",
+	"task":                           "name",
+	"title":                          "name",
+	"summary":                        "name",
+	"assignee":                       "assignee",
+	"owner":                          "assignee",
+	"start":                          "start",
+	"start date":                     "start",
+	"custom field (start date)":      "start",
+	"end":                            "end",
+	"end date":                       "end",
+	"finish":                         "end",
+	"due":                            "end",
+	"due date":                       "end",
+	"blocks":                         "blocks",
+	"outward issue link (blocks)":    "blocks",
+	"blocked by":                     "blocked by",
+	"depends on":                     "blocked by",
+	"inward issue link (blocks)":     "blocked by",
+	"custom field (target end)":      "end",
+	"custom field (target start)":    "start",
+	"custom field (end date)":        "end",
+	"custom field (planned start)":   "start",
+	"custom field (planned end)":     "end",
+	"custom field (planned finish)":  "end",
+	"custom field (baseline start)":  "start",
+	"custom field (baseline finish)": "end",
+}
+
+// taskDateLayouts are the date formats accepted in task CSV files,
+// Jira's "02/Jan/06 3:04 PM" among them.
+var taskDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006/01/02",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06",
+	"2/Jan/06 3:04 PM",
+	"2/Jan/06",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+// FromTaskCSV returns the Gantt chart of the tasks of a CSV file, see
+// ParseTaskCSV and NewGantt.
+func FromTaskCSV(r io.Reader) (*GraphModel, error) {
+	tasks, err := ParseTaskCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewGantt(tasks)
+}
+
+// ParseTaskCSV returns the tasks of a CSV file whose first row names
+// the columns: id or key, name, task or summary, assignee, start, end
+// or due date, blocks and blocked by, case insensitively. Jira issue
+// exports are read as they are: their issue links of type Blocks fill
+// the dependencies, and repeated columns all count. Dates are ISO
+// 8601 or in Jira's format; tasks lacking a start date start on their
+// end date.
+func ParseTaskCSV(r io.Reader) ([]Task, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("graw: ParseTaskCSV: %v", err)
+	}
+	fields := make([]string, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		fields[i] = taskColumns[h]
+	}
+
+	var tasks []Task
+	var blockedBy [][]string
+	ids := make(map[string]int)
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graw: ParseTaskCSV: %v", err)
+		}
+
+		var t Task
+		var blockers []string
+		for i, v := range rec {
+			v = strings.TrimSpace(v)
+			if i >= len(fields) || v == "" {
+				continue
+			}
+			switch fields[i] {
+			case "id":
+				t.ID = v
+			case "name":
+				t.Name = v
+			case "assignee":
+				t.Assignee = v
+			case "start", "end":
+				d, ok := parseTaskDate(v)
+				if !ok {
+					return nil, fmt.Errorf("graw: ParseTaskCSV: line %d: bad date %q", line, v)
+				}
+				if fields[i] == "start" && t.Start.IsZero() {
+					t.Start = d
+				} else if fields[i] == "end" && t.End.IsZero() {
+					t.End = d
+				}
+			case "blocks":
+				t.Blocks = append(t.Blocks, splitTaskIDs(v)...)
+			case "blocked by":
+				blockers = append(blockers, splitTaskIDs(v)...)
+			}
+		}
+		if t.Name == "" && t.ID == "" {
+			continue
+		}
+		if t.Name == "" {
+			t.Name = t.ID
+		}
+		if t.Start.IsZero() {
+			t.Start = t.End
+		}
+		if t.Start.IsZero() {
+			return nil, fmt.Errorf("graw: ParseTaskCSV: line %d: task %q has no dates", line, t.Name)
+		}
+		if t.ID != "" {
+			ids[t.ID] = len(tasks)
+		}
+		tasks = append(tasks, t)
+		blockedBy = append(blockedBy, blockers)
+	}
+
+	for i := range tasks {
+		for _, id := range blockedBy[i] {
+			if j, ok := ids[id]; ok && tasks[i].ID != "" {
+				tasks[j].Blocks = append(tasks[j].Blocks, tasks[i].ID)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// parseTaskDate parses a date of a task CSV file.
+func parseTaskDate(s string) (time.Time, bool) {
+	for _, layout := range taskDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// splitTaskIDs splits a list of task IDs separated by commas,
+// semicolons or spaces.
+func splitTaskIDs(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' '
+	})
+}
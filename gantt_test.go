@@ -0,0 +1,163 @@
+package graw
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jiraCSV = "\ufeffSummary,Issue key,Assignee,Custom field (Start date),Due date,Outward issue link (Blocks),Outward issue link (Blocks),Inward issue link (Blocks)\n" +
+	"Design,PRJ-1,ann,01/Mar/24 9:00 AM,05/Mar/24 5:00 PM,PRJ-2,PRJ-3,\n" +
+	"Build,PRJ-2,bob,06/Mar/24,15/Mar/24,,,\n" +
+	"Test,PRJ-3,ann,,18/Mar/24,,,PRJ-4\n" +
+	"Plan,PRJ-4,,2024-02-26,2024-02-28,,,\n"
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseTaskCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []Task
+	}{
+		{
+			name: "jira",
+			in:   jiraCSV,
+			want: []Task{
+				{ID: "PRJ-1", Name: "Design", Assignee: "ann", Start: day("2024-03-01").Add(9 * time.Hour), End: day("2024-03-05").Add(17 * time.Hour), Blocks: []string{"PRJ-2", "PRJ-3"}},
+				{ID: "PRJ-2", Name: "Build", Assignee: "bob", Start: day("2024-03-06"), End: day("2024-03-15")},
+				{ID: "PRJ-3", Name: "Test", Assignee: "ann", Start: day("2024-03-18"), End: day("2024-03-18")},
+				{ID: "PRJ-4", Name: "Plan", Start: day("2024-02-26"), End: day("2024-02-28"), Blocks: []string{"PRJ-3"}},
+			},
+		},
+		{
+			name: "plain",
+			in: "id,task,start,end,blocked by\n" +
+				"a,Kickoff,2024-01-01,,\n" +
+				"b,Work,2024-01-02,2024-04-01,a\n",
+			want: []Task{
+				{ID: "a", Name: "Kickoff", Start: day("2024-01-01"), Blocks: []string{"b"}},
+				{ID: "b", Name: "Work", Start: day("2024-01-02"), End: day("2024-04-01")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaskCSV(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Formatted, as the times and empty lists may differ in
+			// representation only.
+			if g, w := fmt.Sprintf("%+v", got), fmt.Sprintf("%+v", tt.want); g != w {
+				t.Errorf("got %s, want %s", g, w)
+			}
+		})
+	}
+}
+
+func TestParseTaskCSVErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"name,start\nA,tomorrow\n",
+		"name,start,end\nA,,\n",
+	} {
+		if _, err := ParseTaskCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("ParseTaskCSV(%q): got no error", in)
+		}
+	}
+}
+
+func TestNewGantt(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []Task
+		// bars maps the IDs of the bars to their x and width.
+		bars  map[string][2]int
+		axis  []string
+		edges []string
+	}{
+		{
+			name: "days",
+			tasks: []Task{
+				{ID: "a", Name: "A", Assignee: "ann", Start: day("2024-03-01"), End: day("2024-03-02"), Blocks: []string{"b", "x"}},
+				{ID: "b", Name: "B", Assignee: "bob", Start: day("2024-03-03"), End: day("2024-03-03")},
+			},
+			bars: map[string][2]int{
+				"task-a": {ganttLabelWidth, 2 * ganttDayWidth},
+				"task-b": {ganttLabelWidth + 2*ganttDayWidth, ganttDayWidth},
+			},
+			axis:  []string{"1", "2", "3"},
+			edges: []string{"task-a->task-b"},
+		},
+		{
+			name: "weeks",
+			tasks: []Task{
+				{ID: "a", Name: "A", Start: day("2024-01-01")},
+				{ID: "b", Name: "B", Start: day("2024-01-08"), End: day("2024-03-10")},
+			},
+			bars: map[string][2]int{
+				"task-a": {ganttLabelWidth, ganttBarHeight},
+				"task-b": {ganttLabelWidth + ganttWeekWidth, 9 * ganttWeekWidth},
+			},
+			axis: []string{"Jan 1", "Jan 8", "Jan 15", "Jan 22", "Jan 29", "Feb 5", "Feb 12", "Feb 19", "Feb 26", "Mar 4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGantt(tt.tasks)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for id, want := range tt.bars {
+				c := g.FindByID(id)
+				if c == nil {
+					t.Fatalf("%s: missing", id)
+				}
+				w, _ := c.Geometry.size()
+				if got := [2]int{c.Geometry.X, w}; got != want {
+					t.Errorf("%s: got x, width %v, want %v", id, got, want)
+				}
+			}
+			var axis []string
+			for _, c := range g.Root {
+				if strings.HasPrefix(c.ID, "axis-") {
+					axis = append(axis, c.Value)
+				}
+			}
+			if !reflect.DeepEqual(axis, tt.axis) {
+				t.Errorf("got axis %q, want %q", axis, tt.axis)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %v, want %v", got, tt.edges)
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewGanttErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []Task
+	}{
+		{"no tasks", nil},
+		{"no start", []Task{{Name: "A", End: day("2024-01-01")}}},
+		{"ends before start", []Task{{Name: "A", Start: day("2024-01-02"), End: day("2024-01-01")}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewGantt(tt.tasks); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/fuguohong1024/draw
+
+go 1.18
+
+require golang.org/x/image v0.20.0
+
+require golang.org/x/text v0.18.0 // indirect
@@ -0,0 +1,358 @@
+package graw
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goroutineStateColors maps the wait reasons of goroutines, by prefix,
+// to the fill color of their groups.
+var goroutineStateColors = []struct{ prefix, color string }{
+	{"running", "#d5e8d4"},
+	{"runnable", "#d5e8d4"},
+	{"chan", "#fff2cc"},
+	{"select", "#fff2cc"},
+	{"semacquire", "#f8cecc"},
+	{"sync.", "#f8cecc"},
+	{"IO wait", "#dae8fc"},
+	{"syscall", "#dae8fc"},
+	{"sleep", "#f5f5f5"},
+}
+
+// goroutine is a goroutine of a goroutine profile, or a group of
+// identical ones for profiles printed with debug=1.
+type goroutine struct {
+	id, count int
+	state     string
+	// frames holds the function of every stack frame, innermost
+	// first, and args the pointers passed to them.
+	frames []string
+	args   [][]string
+	// creator is the function which started the goroutine and
+	// creatorID the goroutine it ran in, when known.
+	creator   string
+	creatorID int
+}
+
+// entry returns the function the goroutine was started with.
+func (gr *goroutine) entry() string {
+	for i := len(gr.frames) - 1; i >= 0; i-- {
+		if gr.frames[i] != "runtime.goexit" {
+			return gr.frames[i]
+		}
+	}
+	return "?"
+}
+
+// site returns the innermost frame outside the runtime, which is
+// where the goroutine blocks, and the pointers passed to it.
+func (gr *goroutine) site() (string, []string) {
+	for i, f := range gr.frames {
+		if !strings.HasPrefix(f, "runtime.") && !strings.HasPrefix(f, "internal/") &&
+			!strings.HasPrefix(f, "sync.") && !strings.HasPrefix(f, "time.") {
+			return f, gr.args[i]
+		}
+	}
+	return gr.entry(), nil
+}
+
+// goroutineGroup is a set of goroutines started with the same
+// function and waiting at the same place for the same reason.
+type goroutineGroup struct {
+	entry, site, state string
+	count              int
+	ids                []int
+	creators           []string
+	creatorIDs         []int
+	cell               *Cell
+}
+
+// FromGoroutines returns a diagram of the goroutines of a Go program
+// from its goroutine profile in text form, as served by
+// /debug/pprof/goroutine?debug=2 or printed by a panic or SIGQUIT. The
+// goroutines are grouped by the function they were started with, the
+// place they wait at and their state, and groups are colored after the
+// state: green for running, yellow for channel operations, red for
+// locks, blue for I/O and grey for sleeping. Dashed edges lead from the
+// groups starting goroutines to the goroutines they started.
+//
+// Channels cannot be told apart in a profile, so a channel is guessed
+// from the pointer arguments of the frames the goroutines wait at:
+// when goroutines of several groups wait at frames taking the same
+// pointer, at least one of them blocked on a channel, the pointer is
+// drawn as a channel with edges from the senders and to the receivers.
+//
+// Profiles printed with debug=1 are read too, although they lack the
+// states, creators and arguments of the goroutines, leaving a diagram
+// of groups only. Binary profiles and execution traces are not
+// supported.
+func FromGoroutines(r io.Reader) (*GraphModel, error) {
+	goroutines, err := parseGoroutines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(goroutines) == 0 {
+		return nil, fmt.Errorf("graw: FromGoroutines: no goroutines found")
+	}
+
+	var groups []*goroutineGroup
+	byKey := make(map[string]*goroutineGroup)
+	groupOf := make(map[int]*goroutineGroup)
+	sites := make(map[*goroutineGroup][]string)
+	for _, gr := range goroutines {
+		site, args := gr.site()
+		key := gr.entry() + "\x00" + site + "\x00" + gr.state
+		grp, ok := byKey[key]
+		if !ok {
+			grp = &goroutineGroup{entry: gr.entry(), site: site, state: gr.state}
+			byKey[key] = grp
+			groups = append(groups, grp)
+		}
+		grp.count += gr.count
+		if gr.id != 0 {
+			grp.ids = append(grp.ids, gr.id)
+			groupOf[gr.id] = grp
+		}
+		if gr.creator != "" {
+			grp.creators = append(grp.creators, gr.creator)
+			grp.creatorIDs = append(grp.creatorIDs, gr.creatorID)
+		}
+		sites[grp] = append(sites[grp], args...)
+	}
+
+	// the group running the function that started a goroutine, found
+	// by the creating goroutine when the profile tells it and by
+	// function otherwise
+	creatorOf := func(fn string, id int) *goroutineGroup {
+		if grp, ok := groupOf[id]; ok {
+			return grp
+		}
+		for _, grp := range groups {
+			if grp.entry == fn {
+				return grp
+			}
+		}
+		for _, gr := range goroutines {
+			for _, f := range gr.frames {
+				if f == fn && gr.id != 0 {
+					return groupOf[gr.id]
+				}
+			}
+		}
+		return nil
+	}
+	created := make(map[*goroutineGroup][]*goroutineGroup)
+	parent := make(map[*goroutineGroup]*goroutineGroup)
+	for _, grp := range groups {
+		seen := make(map[*goroutineGroup]bool)
+		for i, fn := range grp.creators {
+			from := creatorOf(fn, grp.creatorIDs[i])
+			if from == nil || seen[from] {
+				continue
+			}
+			seen[from] = true
+			created[from] = append(created[from], grp)
+			if parent[grp] == nil && from != grp {
+				parent[grp] = from
+			}
+		}
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	var columns [][]*Cell
+	var cells []*Cell
+	level := func(grp *goroutineGroup) int {
+		n := 0
+		seen := map[*goroutineGroup]bool{grp: true}
+		for p := parent[grp]; p != nil && !seen[p]; p = parent[p] {
+			seen[p] = true
+			n++
+		}
+		return n
+	}
+	for _, grp := range groups {
+		c := NewShape(taken.unique("goroutine-"+grp.entry), rootCellID)
+		lines := []string{grp.entry}
+		if grp.count > 1 {
+			lines[0] += " ×" + strconv.Itoa(grp.count)
+		}
+		if grp.state != "" {
+			lines = append(lines, grp.state)
+		}
+		if grp.site != grp.entry {
+			lines = append(lines, "at "+grp.site)
+		}
+		c.Value = htmlLines(lines)
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		for _, sc := range goroutineStateColors {
+			if strings.HasPrefix(grp.state, sc.prefix) {
+				c.Style.set("fillColor", sc.color)
+				break
+			}
+		}
+		if len(grp.ids) > 0 {
+			ids := make([]string, len(grp.ids))
+			for i, id := range grp.ids {
+				ids[i] = strconv.Itoa(id)
+			}
+			c.Data = map[string]string{"tooltip": "goroutines " + strings.Join(ids, ", ")}
+		}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		grp.cell = c
+
+		l := level(grp)
+		for len(columns) <= l {
+			columns = append(columns, nil)
+		}
+		columns[l] = append(columns[l], c)
+	}
+
+	for _, from := range groups {
+		for _, to := range created[from] {
+			e := newEdgeCell(taken.unique(from.cell.ID+"-"+to.cell.ID), rootCellID, from.cell.ID, to.cell.ID)
+			e.Value = "go"
+			e.Style.set("dashed", "1")
+			cells = append(cells, e)
+		}
+	}
+
+	// channels: pointers shared by the wait sites of several groups
+	users := make(map[string][]*goroutineGroup)
+	for _, grp := range groups {
+		seen := make(map[string]bool)
+		for _, p := range sites[grp] {
+			if !seen[p] {
+				seen[p] = true
+				users[p] = append(users[p], grp)
+			}
+		}
+	}
+	var pointers []string
+	for p, grps := range users {
+		blocked := false
+		for _, grp := range grps {
+			blocked = blocked || strings.HasPrefix(grp.state, "chan") || strings.HasPrefix(grp.state, "select")
+		}
+		if len(grps) > 1 && blocked {
+			pointers = append(pointers, p)
+		}
+	}
+	sort.Strings(pointers)
+	var channels []*Cell
+	for _, p := range pointers {
+		ch := NewShape(taken.unique("chan-"+p), rootCellID)
+		ch.Value = "chan " + p
+		ch.Style = Style{Attributes: shapeStyle("queue")}
+		SizeToFit(ch, nil, defaultWidth, 40)
+		channels = append(channels, ch)
+		for _, grp := range users[p] {
+			from, to := grp.cell.ID, ch.ID
+			e := newEdgeCell("", rootCellID, from, to)
+			switch {
+			case strings.HasPrefix(grp.state, "chan send"):
+			case strings.HasPrefix(grp.state, "chan receive"):
+				e.Source, e.Target = to, from
+			default:
+				e.Style.set("endArrow", "none")
+			}
+			e.ID = taken.unique(e.Source + "-" + e.Target)
+			cells = append(cells, e)
+		}
+	}
+
+	layoutColumns(append(columns, channels), 10, 10, 80)
+	for _, column := range append(columns, channels, cells) {
+		for _, c := range column {
+			g.Add(c)
+		}
+	}
+	return &g, nil
+}
+
+// parseGoroutines reads a goroutine profile printed with debug=1 or
+// debug=2.
+func parseGoroutines(r io.Reader) ([]*goroutine, error) {
+	var goroutines []*goroutine
+	var cur *goroutine
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	n := 0
+	for s.Scan() {
+		n++
+		line := s.Text()
+		switch {
+		case strings.HasPrefix(line, "goroutine ") && strings.HasSuffix(line, "]:"):
+			// debug=2: goroutine 18 [chan receive, 2 minutes]:
+			open := strings.Index(line, " [")
+			id, err := strconv.Atoi(line[len("goroutine "):open])
+			if err != nil {
+				return nil, fmt.Errorf("graw: FromGoroutines: line %d: bad goroutine id", n)
+			}
+			state := line[open+2 : len(line)-2]
+			if i := strings.Index(state, ","); i >= 0 {
+				state = state[:i]
+			}
+			cur = &goroutine{id: id, count: 1, state: state}
+			goroutines = append(goroutines, cur)
+
+		case strings.Contains(line, " @ ") && !strings.HasPrefix(line, "#"):
+			// debug=1: 3 @ 0x43e4d6 0x44f1bd 0x4a8f2d
+			count, err := strconv.Atoi(strings.TrimSpace(line[:strings.Index(line, " @ ")]))
+			if err != nil {
+				cur = nil
+				continue
+			}
+			cur = &goroutine{count: count}
+			goroutines = append(goroutines, cur)
+
+		case cur == nil:
+
+		case strings.HasPrefix(line, "#\t"):
+			// debug=1: #	0x4a8f2c	main.worker+0x2c	/src/main.go:12
+			fields := strings.Fields(line)
+			if len(fields) >= 3 {
+				fn := fields[2]
+				if i := strings.LastIndex(fn, "+0x"); i >= 0 {
+					fn = fn[:i]
+				}
+				cur.frames = append(cur.frames, fn)
+				cur.args = append(cur.args, nil)
+			}
+
+		case strings.HasPrefix(line, "created by "):
+			fn := strings.TrimPrefix(line, "created by ")
+			if i := strings.Index(fn, " in goroutine "); i >= 0 {
+				cur.creatorID, _ = strconv.Atoi(fn[i+len(" in goroutine "):])
+				fn = fn[:i]
+			}
+			cur.creator = fn
+
+		case line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "..."):
+
+		case strings.HasSuffix(line, ")"):
+			// debug=2: main.(*pool).worker(0xc000010000, 0x3)
+			open := strings.LastIndex(line, "(")
+			if open <= 0 {
+				continue
+			}
+			var args []string
+			for _, a := range strings.Split(line[open+1:len(line)-1], ",") {
+				a = strings.Trim(strings.TrimSpace(a), "{}?")
+				if strings.HasPrefix(a, "0xc") && len(a) > 10 {
+					args = append(args, a)
+				}
+			}
+			cur.frames = append(cur.frames, line[:open])
+			cur.args = append(cur.args, args)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return goroutines, nil
+}
@@ -0,0 +1,125 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const goroutineDump = `goroutine 1 [running]:
+main.main()
+	/src/main.go:40 +0x45
+
+goroutine 18 [chan receive, 2 minutes]:
+main.(*pool).worker(0xc000010000, 0xc000100000)
+	/src/main.go:12 +0x2c
+created by main.main in goroutine 1
+	/src/main.go:30 +0x8f
+
+goroutine 19 [chan receive]:
+main.(*pool).worker(0xc000010000, 0xc000100000)
+	/src/main.go:12 +0x2c
+created by main.main in goroutine 1
+	/src/main.go:30 +0x8f
+
+goroutine 20 [chan send]:
+main.producer(0xc000100000)
+	/src/main.go:20 +0x2c
+created by main.main
+	/src/main.go:31 +0x8f
+
+goroutine 21 [semacquire]:
+sync.runtime_SemacquireMutex(0xc000200000?, 0x0?, 0x1?)
+	/go/src/runtime/sema.go:77 +0x25
+sync.(*Mutex).lockSlow(0xc000200000)
+	/go/src/sync/mutex.go:171 +0x165
+main.locker(...)
+	/src/main.go:50
+created by main.(*pool).worker in goroutine 18
+	/src/main.go:14 +0x8f
+`
+
+const goroutineDebug1 = `goroutine profile: total 5
+3 @ 0x43e4d6 0x44f1bd 0x4a8f2d 0x46c601
+#	0x4a8f2c	main.worker+0x2c	/src/main.go:12
+
+2 @ 0x43e4d6 0x44f1bd 0x46c601
+#	0x44f1bc	runtime.gopark+0x1c	/go/src/runtime/proc.go:398
+#	0x4a8f00	main.main+0x20	/src/main.go:40
+`
+
+func TestFromGoroutines(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		labels map[string]string
+		fill   map[string]string
+		edges  []string
+	}{
+		{
+			name: "stack dump",
+			in:   goroutineDump,
+			labels: map[string]string{
+				"goroutine-main.main":           "main.main<br>running",
+				"goroutine-main.(*pool).worker": "main.(*pool).worker ×2<br>chan receive",
+				"goroutine-main.producer":       "main.producer<br>chan send",
+				"goroutine-main.locker":         "main.locker<br>semacquire",
+				"chan-0xc000100000":             "chan 0xc000100000",
+			},
+			fill: map[string]string{
+				"goroutine-main.main":           "#d5e8d4",
+				"goroutine-main.(*pool).worker": "#fff2cc",
+				"goroutine-main.locker":         "#f8cecc",
+			},
+			edges: []string{
+				"goroutine-main.main->goroutine-main.(*pool).worker:go",
+				"goroutine-main.main->goroutine-main.producer:go",
+				"goroutine-main.(*pool).worker->goroutine-main.locker:go",
+				"chan-0xc000100000->goroutine-main.(*pool).worker",
+				"goroutine-main.producer->chan-0xc000100000",
+			},
+		},
+		{
+			name: "debug=1",
+			in:   goroutineDebug1,
+			labels: map[string]string{
+				"goroutine-main.worker": "main.worker ×3",
+				"goroutine-main.main":   "main.main ×2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromGoroutines(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := vertexLabels(g); !reflect.DeepEqual(got, tt.labels) {
+				t.Errorf("got labels %v, want %v", got, tt.labels)
+			}
+			for id, want := range tt.fill {
+				if got := g.FindByID(id).Style.Attributes["fillColor"]; got != want {
+					t.Errorf("%s: got fillColor %q, want %q", id, got, want)
+				}
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %v, want %v", got, tt.edges)
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestFromGoroutinesErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"no profile here\n",
+		"goroutine x [running]:\nmain.main()\n",
+	} {
+		if _, err := FromGoroutines(strings.NewReader(in)); err == nil {
+			t.Errorf("FromGoroutines(%q): got no error", in)
+		}
+	}
+}
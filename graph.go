@@ -0,0 +1,225 @@
+package graw
+
+import "strconv"
+
+// isVertex reports whether c is a vertex cell.
+func (c *Cell) isVertex() bool {
+	return c.Vertex == "1"
+}
+
+// isEdge reports whether c is an edge cell.
+func (c *Cell) isEdge() bool {
+	return c.Edge == "1"
+}
+
+// isLayer reports whether c is a layer, i.e. a direct child of the
+// top cell.
+func (c *Cell) isLayer() bool {
+	return c.ParentID == topCellId && c.ID != topCellId
+}
+
+// cellIndex returns the position of the cell with the given id in
+// g.Root, or -1 when there is no such cell. It looks the cell up in
+// the index when it is current and scans Root otherwise. It never
+// changes g, so that models can be read concurrently.
+func (g *GraphModel) cellIndex(id string) int {
+	if g.indexCurrent() {
+		i, ok := g.index[id]
+		if !ok {
+			return -1
+		}
+		if g.Root[i].ID == id {
+			return i
+		}
+	}
+	for i := range g.Root {
+		if g.Root[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexCurrent reports whether the index of g is current, i.e. no
+// cells were added to or removed from Root bypassing it. It compares
+// the number of cells and the ID of the last one, which changes when
+// cells are appended after others were removed.
+func (g *GraphModel) indexCurrent() bool {
+	n := len(g.Root)
+	return g.index != nil && g.indexed == n && (n == 0 || g.Root[n-1].ID == g.lastID)
+}
+
+// reindex rebuilds the index of the cells of g. The first of several
+// cells with the same ID is indexed.
+func (g *GraphModel) reindex() {
+	g.index = make(map[string]int, len(g.Root))
+	for i := range g.Root {
+		if _, ok := g.index[g.Root[i].ID]; !ok {
+			g.index[g.Root[i].ID] = i
+		}
+	}
+	g.indexed, g.lastID = len(g.Root), ""
+	if len(g.Root) > 0 {
+		g.lastID = g.Root[len(g.Root)-1].ID
+	}
+}
+
+// cell returns the cell with the given id, or nil when there is no
+// such cell.
+func (g *GraphModel) cell(id string) *Cell {
+	if i := g.cellIndex(id); i >= 0 {
+		return g.Root[i]
+	}
+	return nil
+}
+
+// nestedIn reports whether c is the cell id or nested in it.
+func (g *GraphModel) nestedIn(c *Cell, id string) bool {
+	for depth := 0; c != nil && depth < 64; depth++ {
+		if c.ID == id {
+			return true
+		}
+		c = g.cell(c.ParentID)
+	}
+	return false
+}
+
+// cellMap returns the cells of g keyed by ID. The returned cells
+// point into g.Root.
+func (g *GraphModel) cellMap() map[string]*Cell {
+	m := make(map[string]*Cell, len(g.Root))
+	for i := range g.Root {
+		m[g.Root[i].ID] = g.Root[i]
+	}
+	return m
+}
+
+// uniqueID returns base if no cell uses it as ID yet, otherwise base
+// followed by the first free numeric suffix. It probes the index of g
+// instead of collecting all IDs, so that wiring large models stays
+// fast.
+func (g *GraphModel) uniqueID(base string) string {
+	id := base
+	for n := 2; g.cellIndex(id) >= 0; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	return id
+}
+
+// idSet is a set of cell IDs in use. It lets transforms reserve IDs
+// for cells they have not added to the model yet.
+type idSet map[string]bool
+
+// idSet returns the set of IDs used by the cells of g.
+func (g *GraphModel) idSet() idSet {
+	ids := make(idSet, len(g.Root))
+	for i := range g.Root {
+		ids[g.Root[i].ID] = true
+	}
+	return ids
+}
+
+// unique reserves and returns base, or base followed by the first
+// free numeric suffix when base is taken.
+func (s idSet) unique(base string) string {
+	id := base
+	for n := 2; s[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	s[id] = true
+	return id
+}
+
+// adjacency returns the IDs of all vertices in document order and,
+// for each of them, the IDs of the vertices reached by its outgoing
+// edges. Edges with a missing terminal are ignored.
+func (g *GraphModel) adjacency() ([]string, map[string][]string) {
+	var nodes []string
+	adj := make(map[string][]string)
+	for i := range g.Root {
+		if c := g.Root[i]; c.isVertex() {
+			nodes = append(nodes, c.ID)
+			adj[c.ID] = nil
+		}
+	}
+	for i := range g.Root {
+		c := g.Root[i]
+		if !c.isEdge() {
+			continue
+		}
+		if _, ok := adj[c.Source]; !ok {
+			continue
+		}
+		if _, ok := adj[c.Target]; !ok {
+			continue
+		}
+		adj[c.Source] = append(adj[c.Source], c.Target)
+	}
+	return nodes, adj
+}
+
+// outEdges returns, for each vertex ID, the edges leaving it whose
+// target is a vertex too. The returned cells point into g.Root.
+func (g *GraphModel) outEdges() map[string][]*Cell {
+	vertices := make(map[string]bool)
+	for i := range g.Root {
+		if c := g.Root[i]; c.isVertex() {
+			vertices[c.ID] = true
+		}
+	}
+	out := make(map[string][]*Cell)
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.isEdge() && vertices[c.Source] && vertices[c.Target] {
+			out[c.Source] = append(out[c.Source], c)
+		}
+	}
+	return out
+}
+
+// clone returns a deep copy of c.
+func (c *Cell) clone() *Cell {
+	cp := *c
+	if c.Style.Attributes != nil {
+		cp.Style.Attributes = make(map[string]string, len(c.Style.Attributes))
+		for k, v := range c.Style.Attributes {
+			cp.Style.Attributes[k] = v
+		}
+	}
+	if c.Data != nil {
+		cp.Data = make(map[string]string, len(c.Data))
+		for k, v := range c.Data {
+			cp.Data[k] = v
+		}
+	}
+	if c.Geometry != nil {
+		geo := *c.Geometry
+		for _, pp := range []**Point{&geo.Point, &geo.SourcePoint, &geo.TargetPoint} {
+			if *pp != nil {
+				p := **pp
+				*pp = &p
+			}
+		}
+		if geo.Points != nil {
+			geo.Points = append([]Point(nil), geo.Points...)
+		}
+		if geo.AlternateBounds != nil {
+			b := *geo.AlternateBounds
+			geo.AlternateBounds = &b
+		}
+		cp.Geometry = &geo
+	}
+	return &cp
+}
+
+// clone returns a deep copy of g.
+func (g *GraphModel) clone() GraphModel {
+	cp := *g
+	cp.tx = nil
+	cp.index = nil
+	cp.Root = make([]*Cell, len(g.Root))
+	for i := range g.Root {
+		cp.Root[i] = g.Root[i].clone()
+	}
+	return cp
+}
@@ -0,0 +1,95 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestGraph returns a graph holding a vertex for every ID named in
+// edges, in the order they appear, and an edge "from-to" for every
+// pair.
+func newTestGraph(edges ...[2]string) GraphModel {
+	g := NewGraph()
+	seen := make(map[string]bool)
+	for _, e := range edges {
+		for _, id := range e {
+			if !seen[id] {
+				seen[id] = true
+				g.Add(NewShape(id, "1"))
+			}
+		}
+	}
+	for _, e := range edges {
+		g.Add(newEdgeCell(e[0]+"-"+e[1], "1", e[0], e[1]))
+	}
+	return g
+}
+
+func TestAdjacency(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"c", "a"})
+	g.Add(newEdgeCell("dangling", "1", "a", "missing"))
+	nodes, adj := g.adjacency()
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("got nodes %v, want %v", nodes, want)
+	}
+	want := map[string][]string{"a": {"b", "c"}, "b": nil, "c": {"a"}}
+	if !reflect.DeepEqual(adj, want) {
+		t.Errorf("got adjacency %v, want %v", adj, want)
+	}
+}
+
+// edgeList returns the edges of g as "source->target" strings, with
+// ":label" appended to labeled ones, in document order.
+func edgeList(g *GraphModel) []string {
+	var edges []string
+	for _, c := range g.Root {
+		if !c.isEdge() {
+			continue
+		}
+		e := c.Source + "->" + c.Target
+		if c.Value != "" {
+			e += ":" + c.Value
+		}
+		edges = append(edges, e)
+	}
+	return edges
+}
+
+// vertexLabels returns the labels of the vertices of g by ID.
+func vertexLabels(g *GraphModel) map[string]string {
+	labels := make(map[string]string)
+	for _, c := range g.Root {
+		if c.isVertex() {
+			labels[c.ID] = c.Value
+		}
+	}
+	return labels
+}
+
+func TestUniqueID(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	g.Add(NewShape("c-2", "1"))
+	tests := []struct {
+		base, want string
+	}{
+		{"x", "x"},
+		{"a", "a-2"},
+		{"a-b", "a-b-2"},
+		{"c", "c"},
+		{"1", "1-2"},
+	}
+	for _, tt := range tests {
+		if got := g.uniqueID(tt.base); got != tt.want {
+			t.Errorf("base %q: got %q, want %q", tt.base, got, tt.want)
+		}
+	}
+	// Cells appended to Root bypassing Add are found as well.
+	g.Root = append(g.Root, NewShape("x", "1"))
+	if got := g.uniqueID("x"); got != "x-2" {
+		t.Errorf("got %q, want %q", got, "x-2")
+	}
+	g.Add(NewShape("a-2", "1"))
+	if got := g.uniqueID("a"); got != "a-3" {
+		t.Errorf("got %q, want %q", got, "a-3")
+	}
+}
@@ -2,6 +2,10 @@ package graw
 
 import (
 	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -18,7 +22,7 @@ type GraphModel struct {
 	Dx      int      `xml:"dx,attr"`
 	Dy      int      `xml:"dy,attr"`
 
-	// 属性
+	// 属性, 可用 SetGrid, SetPage, SetShadow 等方法设置
 	Grid       string `xml:"grid,attr,omitempty"`
 	GridSize   string `xml:"gridSize,attr,omitempty"`
 	Guides     string `xml:"guides,attr,omitempty"`
@@ -34,16 +38,44 @@ type GraphModel struct {
 	Math       string `xml:"math,attr,omitempty"`
 	Shadow     string `xml:"shadow,attr,omitempty"`
 
-	Root []Cell `xml:"root>mxCell"`
+	// BackgroundImage 为背景图片, JSON 格式, 如
+	// {"src":"https://...","width":800,"height":600}
+	BackgroundImage string `xml:"backgroundImage,attr,omitempty"`
+
+	// Root holds the cells in document order. Cells are held by
+	// pointer, so that a cell passed to Add can still be changed.
+	// Code written when Root held Cell values keeps reading fields as
+	// g.Root[i].ID; &g.Root[i] becomes g.Root[i], and a Cell value
+	// appended to Root is appended by address, or better added by Add.
+	Root []*Cell `xml:"root>mxCell"`
+
+	// Weights, when set, styles the cells which have a weight when g
+	// is marshalled or rendered; see SetWeight.
+	Weights *WeightMapping `xml:"-"`
+
+	// tx holds the state of the model at the start of each open
+	// transaction, the innermost last.
+	tx []*txState
+
+	// index maps the IDs of the cells to their position in Root. It
+	// is current while Root holds indexed cells, the last one with ID
+	// lastID; see cellIndex.
+	index   map[string]int
+	indexed int
+	lastID  string
+
+	// nextID is the number of the last ID handed out by NextID.
+	nextID int
 }
 
 // Cell 单元格/元素
 // Vertex=1 为顶点
 // Edge=1 为边
 // ParentID 为根单元格的ID
+// Data 和 Placeholders 不为空时, mxCell 会被包装在 UserObject 中
 type Cell struct {
 	XMLName  xml.Name `xml:"mxCell"`
-	ID       string   `xml:"id,attr"`
+	ID       string   `xml:"id,attr,omitempty"`
 	Value    string   `xml:"value,attr,omitempty"`
 	Style    Style    `xml:"style,attr,omitempty"`
 	ParentID string   `xml:"parent,attr,omitempty"`
@@ -52,6 +84,19 @@ type Cell struct {
 	Source   string   `xml:"source,attr,omitempty"`
 	Target   string   `xml:"target,attr,omitempty"`
 	Geometry *Geometry
+
+	// Connectable is "0" on vertices edges cannot connect to, such
+	// as edge labels.
+	Connectable string `xml:"connectable,attr,omitempty"`
+	// Collapsed is "1" on containers shown folded to their header;
+	// see SetCollapsed.
+	Collapsed string `xml:"collapsed,attr,omitempty"`
+	// Data holds custom properties shown in the "Edit Data" dialog
+	// of draw.io.
+	Data map[string]string `xml:"-"`
+	// Placeholders enables %key% placeholders in the label, which
+	// draw.io replaces with the matching entry of Data.
+	Placeholders bool `xml:"-"`
 }
 
 // Geometry
@@ -69,6 +114,24 @@ type Geometry struct {
 	Relative string   `xml:"relative,attr,omitempty"`
 	As       string   `xml:"as,attr"`
 	Point    *Point
+
+	// Position 为相对几何 (Relative="1") 在边上的位置, 用于边标签:
+	// -1 为起点, 0 为中点, 1 为终点. 不为 0 时代替 X 编码为 x 属性
+	Position float64 `xml:"-"`
+
+	// SourcePoint 和 TargetPoint 为边的起点和终点, 用于没有连接
+	// source 或 target 的边, 编码为 as="sourcePoint" 和
+	// as="targetPoint" 的 mxPoint
+	SourcePoint *Point `xml:"-"`
+	TargetPoint *Point `xml:"-"`
+
+	// Points 边的路径点 (waypoints)
+	// 编码为 <Array as="points"><mxPoint .../></Array>
+	Points []Point `xml:"-"`
+
+	// AlternateBounds 为容器折叠或展开后的位置和大小, 折叠时为展开
+	// 的大小, 编码为 <mxRectangle as="alternateBounds"/>
+	AlternateBounds *Rect `xml:"-"`
 }
 
 // Point
@@ -80,7 +143,207 @@ type Point struct {
 	XMLName xml.Name `xml:"mxPoint"`
 	X       int      `xml:"x,attr,omitempty"`
 	Y       int      `xml:"y,attr,omitempty"`
-	As      string   `xml:"as,attr"`
+	As      string   `xml:"as,attr,omitempty"`
+}
+
+// pointArray is the <Array as="points"> element holding the waypoints
+// of an edge geometry.
+type pointArray struct {
+	As     string  `xml:"as,attr"`
+	Points []Point `xml:"mxPoint"`
+}
+
+// rectangleXML is the <mxRectangle as="alternateBounds"> element
+// holding the alternate bounds of a geometry.
+type rectangleXML struct {
+	X      string `xml:"x,attr,omitempty"`
+	Y      string `xml:"y,attr,omitempty"`
+	Width  string `xml:"width,attr,omitempty"`
+	Height string `xml:"height,attr,omitempty"`
+	As     string `xml:"as,attr"`
+}
+
+// geometryXML mirrors Geometry with its waypoints exposed to
+// encoding/xml.
+type geometryXML struct {
+	XMLName  xml.Name      `xml:"mxGeometry"`
+	X        string        `xml:"x,attr,omitempty"`
+	Y        string        `xml:"y,attr,omitempty"`
+	Width    string        `xml:"width,attr,omitempty"`
+	Height   string        `xml:"height,attr,omitempty"`
+	Relative string        `xml:"relative,attr,omitempty"`
+	As       string        `xml:"as,attr"`
+	Point    []Point       `xml:"mxPoint"`
+	Bounds   *rectangleXML `xml:"mxRectangle"`
+	Array    *pointArray   `xml:"Array"`
+}
+
+// MarshalXML encodes geo as an mxGeometry element, writing Point,
+// SourcePoint and TargetPoint as mxPoint children in this order, the
+// alternate bounds as an mxRectangle child and the waypoints into an
+// Array child. It implements xml.Marshaler interface.
+func (geo Geometry) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	aux := geometryXML{
+		Width:    geo.Width,
+		Height:   geo.Height,
+		Relative: geo.Relative,
+		As:       geo.As,
+	}
+	if geo.Position != 0 {
+		aux.X = strconv.FormatFloat(geo.Position, 'f', -1, 64)
+	} else if geo.X != 0 {
+		aux.X = strconv.Itoa(geo.X)
+	}
+	if geo.Y != 0 {
+		aux.Y = strconv.Itoa(geo.Y)
+	}
+	if geo.Point != nil {
+		aux.Point = append(aux.Point, *geo.Point)
+	}
+	if geo.SourcePoint != nil {
+		p := *geo.SourcePoint
+		p.As = "sourcePoint"
+		aux.Point = append(aux.Point, p)
+	}
+	if geo.TargetPoint != nil {
+		p := *geo.TargetPoint
+		p.As = "targetPoint"
+		aux.Point = append(aux.Point, p)
+	}
+	if b := geo.AlternateBounds; b != nil {
+		aux.Bounds = &rectangleXML{As: "alternateBounds", Width: strconv.Itoa(b.Width), Height: strconv.Itoa(b.Height)}
+		if b.X != 0 {
+			aux.Bounds.X = strconv.Itoa(b.X)
+		}
+		if b.Y != 0 {
+			aux.Bounds.Y = strconv.Itoa(b.Y)
+		}
+	}
+	if len(geo.Points) > 0 {
+		aux.Array = &pointArray{As: "points", Points: geo.Points}
+	}
+	return e.EncodeElement(aux, start)
+}
+
+// UnmarshalXML decodes an mxGeometry element including its
+// waypoints and alternate bounds. The x attribute of a relative
+// geometry is decoded into Position too. The mxPoint children as="sourcePoint" and
+// as="targetPoint" are decoded into SourcePoint and TargetPoint, the
+// first other one into Point. It implements xml.Unmarshaler
+// interface.
+func (geo *Geometry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var aux geometryXML
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	*geo = Geometry{
+		XMLName:  aux.XMLName,
+		Width:    aux.Width,
+		Height:   aux.Height,
+		Relative: aux.Relative,
+		As:       aux.As,
+	}
+	if aux.X != "" {
+		x, err := parseCoord(aux.X)
+		if err != nil {
+			return err
+		}
+		geo.X = int(math.Round(x))
+		if geo.Relative == "1" {
+			geo.Position = x
+		}
+	}
+	if aux.Y != "" {
+		y, err := parseCoord(aux.Y)
+		if err != nil {
+			return err
+		}
+		geo.Y = int(math.Round(y))
+	}
+	for i := range aux.Point {
+		p := &aux.Point[i]
+		switch {
+		case p.As == "sourcePoint" && geo.SourcePoint == nil:
+			geo.SourcePoint = p
+		case p.As == "targetPoint" && geo.TargetPoint == nil:
+			geo.TargetPoint = p
+		case geo.Point == nil:
+			geo.Point = p
+		}
+	}
+	if b := aux.Bounds; b != nil && b.As == "alternateBounds" {
+		geo.AlternateBounds = &Rect{}
+		for _, c := range []struct {
+			v   string
+			dst *int
+		}{{b.X, &geo.AlternateBounds.X}, {b.Y, &geo.AlternateBounds.Y}, {b.Width, &geo.AlternateBounds.Width}, {b.Height, &geo.AlternateBounds.Height}} {
+			if c.v == "" {
+				continue
+			}
+			f, err := parseCoord(c.v)
+			if err != nil {
+				return err
+			}
+			*c.dst = int(math.Round(f))
+		}
+	}
+	if aux.Array != nil {
+		geo.Points = aux.Array.Points
+	}
+	return nil
+}
+
+// UnmarshalXML decodes an mxPoint element, rounding fractional
+// coordinates as written by draw.io to whole pixels. It implements
+// xml.Unmarshaler interface.
+func (p *Point) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var aux struct {
+		X  string `xml:"x,attr"`
+		Y  string `xml:"y,attr"`
+		As string `xml:"as,attr"`
+	}
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	*p = Point{XMLName: start.Name, As: aux.As}
+	for _, c := range []struct {
+		v   string
+		dst *int
+	}{{aux.X, &p.X}, {aux.Y, &p.Y}} {
+		if c.v == "" {
+			continue
+		}
+		f, err := parseCoord(c.v)
+		if err != nil {
+			return err
+		}
+		*c.dst = int(math.Round(f))
+	}
+	return nil
+}
+
+// parseCoord parses the coordinate v of a geometry or a point.
+func parseCoord(v string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, fmt.Errorf("graw: invalid coordinate %q", v)
+	}
+	return f, nil
+}
+
+// AddWaypoint appends the waypoint (x, y) to the route of an edge
+// geometry. Waypoints are relative to the parent of the edge, like
+// the position of a shape.
+func (geo *Geometry) AddWaypoint(x, y int) *Geometry {
+	geo.Points = append(geo.Points, Point{X: x, Y: y})
+	return geo
+}
+
+// ClearWaypoints removes all waypoints, letting draw.io route the
+// edge again.
+func (geo *Geometry) ClearWaypoints() *Geometry {
+	geo.Points = nil
+	return geo
 }
 
 // A Style is a map of key-value pairs to describe the style
@@ -91,38 +354,99 @@ type Style struct {
 
 // MarshalXMLAttr returns an XML attribute with the encoded value
 // of Style. It implements xml.MarshalerAttr interface.
+//
+// Named styles (keys without a value) are written first, followed
+// by the key=value pairs sorted by key, so that the same Style
+// always produces the same text. An empty Style is not written, as
+// draw.io does.
 func (a Style) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
 	var text string
 
-	for k, v := range a.Attributes {
+	for _, k := range a.keys() {
 		text += k
 
-		if v != "" {
+		if v := a.Attributes[k]; v != "" {
 			text += "="
 			text += v
 		}
 
 		text += ";"
 	}
+	if text == "" {
+		return xml.Attr{}, nil
+	}
 
 	return xml.Attr{Name: xml.Name{Local: "style"}, Value: text}, nil
 }
 
+// keys returns the style keys in marshalling order.
+func (a Style) keys() []string {
+	keys := make([]string, 0, len(a.Attributes))
+	for k := range a.Attributes {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := a.Attributes[keys[i]] == "", a.Attributes[keys[j]] == ""
+		if ni != nj {
+			return ni
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
 // UnmarshalXMLAttr decodes a single XML attribute of type Style.
 // It implements xml.UnmarshalerAttr interface.
 func (a *Style) UnmarshalXMLAttr(attr xml.Attr) error {
-	a.Attributes = make(map[string]string)
-	pairs := strings.Split(attr.Value, ";")
+	*a = parseStyle(attr.Value)
+	return nil
+}
+
+// parseStyle decodes a draw.io style string such as
+// "rounded=1;fillColor=#dae8fc;".
+func parseStyle(text string) Style {
+	a := Style{Attributes: make(map[string]string)}
+	pairs := strings.Split(text, ";")
 
 	for _, pair := range pairs {
-		kv := strings.Split(pair, "=")
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
 		if len(kv) < 2 {
 			kv = append(kv, "")
 		}
 		a.Attributes[kv[0]] = kv[1]
 	}
 
-	return nil
+	return a
+}
+
+// set sets a single style key, allocating the attribute map when
+// needed.
+func (a *Style) set(key, value string) {
+	if a.Attributes == nil {
+		a.Attributes = make(map[string]string)
+	}
+	a.Attributes[key] = value
+}
+
+// float returns the value of key parsed as a number. It reports
+// false when the key is missing or not numeric.
+func (a Style) float(key string) (float64, bool) {
+	v, ok := a.Attributes[key]
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return f, err == nil
+}
+
+// setFloat sets key to the shortest decimal representation of v.
+func (a *Style) setFloat(key string, v float64) {
+	a.set(key, strconv.FormatFloat(v, 'f', -1, 64))
 }
 
 // NewGraph returns a new graph model containing a root cell and
@@ -135,7 +459,7 @@ func NewGraph() GraphModel {
 	return GraphModel{
 		Dx: 640,
 		Dy: 480,
-		Root: []Cell{
+		Root: []*Cell{
 			{ID: topCellId, Style: rootStyle},
 			{
 				ID:       rootCellID,
@@ -147,12 +471,30 @@ func NewGraph() GraphModel {
 }
 
 // Add adds the given Cell to the root cell of the receiving
-// graph model.
+// graph model. The model holds c itself, not a copy, so changes made
+// to c after adding it, e.g. moving or restyling it, are part of the
+// model. Add a cell to one model only, once.
 func (g *GraphModel) Add(c *Cell) *GraphModel {
-	g.Root = append(g.Root, *c)
+	if !g.indexCurrent() {
+		g.reindex()
+	}
+	g.Root = append(g.Root, c)
+	if _, ok := g.index[c.ID]; !ok {
+		g.index[c.ID] = len(g.Root) - 1
+	}
+	g.indexed, g.lastID = len(g.Root), c.ID
 	return g
 }
 
+// NewLayer returns a new layer Cell with the given unique ID (id)
+// and name. Layers are children of the top cell and hold the shapes
+// and edges of the diagram; the default layer has the ID "1".
+func NewLayer(id, name string) *Cell {
+	l := newCell(id, topCellId)
+	l.Value = name
+	return l
+}
+
 // NewShape returns a new Vertex Cell, configured with the given
 // unique ID (id) and parent ID (layerId). The new cell contains
 // a default geometry which you might want to change.
@@ -163,6 +505,20 @@ func NewShape(id, layerId string) *Cell {
 	return s
 }
 
+// NewEdge returns a new Edge Cell, configured with the given unique
+// ID (id) and parent ID (layerId), connecting the cell sourceID to
+// the cell targetID. The new cell has a relative geometry and the
+// style of the default draw.io connector: an orthogonal line ending
+// in an arrow.
+func NewEdge(id, layerId, sourceID, targetID string) *Cell {
+	e := newEdgeCell(id, layerId, sourceID, targetID)
+	e.Style.set("edgeStyle", "orthogonalEdgeStyle")
+	e.Style.set("rounded", "0")
+	e.Style.set("orthogonalLoop", "1")
+	e.Style.set("jettySize", "auto")
+	return e
+}
+
 // NewImage returns a new Vertex Cell, configured as an image,
 // with given unique ID (id), parent ID (layerId) and image
 // source URL (url). The new cell contains a default geometry
@@ -199,6 +555,25 @@ func newCell(id string, layerId string) *Cell {
 
 }
 
+// newEdgeCell returns a new Edge Cell configured with id, parent ID
+// (layerId) and the IDs of the cells it connects.
+func newEdgeCell(id, layerId, sourceID, targetID string) *Cell {
+	e := newCell(id, layerId)
+	e.Edge = "1"
+	e.Source = sourceID
+	e.Target = targetID
+	e.Style = Style{
+		Attributes: map[string]string{
+			"html": "1",
+		},
+	}
+	e.Geometry = &Geometry{
+		Relative: "1",
+		As:       "geometry",
+	}
+	return e
+}
+
 // newGeometry returns a new Geometry object configured with
 // default values.
 func newGeometry() *Geometry {
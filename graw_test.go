@@ -0,0 +1,247 @@
+package graw
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestNewEdge(t *testing.T) {
+	tests := []struct {
+		name             string
+		source, target   string
+		wantSrc, wantTgt string
+	}{
+		{"connected", "a", "b", ` source="a"`, ` target="b"`},
+		{"dangling", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEdge("e", "1", tt.source, tt.target)
+			if !e.isEdge() || e.isVertex() {
+				t.Errorf("got edge %q, vertex %q", e.Edge, e.Vertex)
+			}
+			if e.ID != "e" || e.ParentID != "1" || e.Source != tt.source || e.Target != tt.target {
+				t.Errorf("got %+v", e)
+			}
+			for k, want := range map[string]string{
+				"html":           "1",
+				"edgeStyle":      "orthogonalEdgeStyle",
+				"rounded":        "0",
+				"orthogonalLoop": "1",
+				"jettySize":      "auto",
+			} {
+				if got := e.Style.Attributes[k]; got != want {
+					t.Errorf("got %s %q, want %q", k, got, want)
+				}
+			}
+			g := NewGraph()
+			g.Add(e)
+			if iss := ValidateStyles(&g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+			out, err := xml.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := `<mxCell id="e" style="edgeStyle=orthogonalEdgeStyle;html=1;jettySize=auto;orthogonalLoop=1;rounded=0;" parent="1" edge="1"` + tt.wantSrc + tt.wantTgt + `><mxGeometry relative="1" as="geometry"></mxGeometry></mxCell>`
+			if !strings.Contains(string(out), want) {
+				t.Errorf("%s does not contain %s", out, want)
+			}
+		})
+	}
+}
+
+func TestGeometryWaypoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Point
+		want   string
+	}{
+		{"none", nil, `<mxGeometry relative="1" as="geometry"></mxGeometry>`},
+		{"one", []Point{{X: 10, Y: 20}}, `<mxGeometry relative="1" as="geometry"><Array as="points"><mxPoint x="10" y="20"></mxPoint></Array></mxGeometry>`},
+		{"two", []Point{{X: 10, Y: 20}, {X: 30}}, `<mxGeometry relative="1" as="geometry"><Array as="points"><mxPoint x="10" y="20"></mxPoint><mxPoint x="30"></mxPoint></Array></mxGeometry>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEdge("e", "1", "a", "b")
+			for _, p := range tt.points {
+				e.Geometry.AddWaypoint(p.X, p.Y)
+			}
+			g := NewGraph()
+			g.Add(e)
+			out, err := xml.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("%s does not contain %s", out, tt.want)
+			}
+
+			var h GraphModel
+			if err := xml.Unmarshal(out, &h); err != nil {
+				t.Fatal(err)
+			}
+			geo := h.FindByID("e").Geometry
+			if len(geo.Points) != len(tt.points) {
+				t.Fatalf("got %d waypoints, want %d", len(geo.Points), len(tt.points))
+			}
+			for i, p := range geo.Points {
+				if p.X != tt.points[i].X || p.Y != tt.points[i].Y {
+					t.Errorf("waypoint %d: got (%d, %d), want (%d, %d)", i, p.X, p.Y, tt.points[i].X, tt.points[i].Y)
+				}
+			}
+		})
+	}
+}
+
+func TestGeometryTerminalPoints(t *testing.T) {
+	e := NewEdge("e", "1", "", "")
+	e.Geometry.SourcePoint = &Point{X: 1, Y: 2}
+	e.Geometry.TargetPoint = &Point{X: 3, Y: 4}
+	g := NewGraph()
+	g.Add(e)
+	out, err := xml.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<mxPoint x="1" y="2" as="sourcePoint">`, `<mxPoint x="3" y="4" as="targetPoint">`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("%s does not contain %s", out, want)
+		}
+	}
+}
+
+func TestGeometryTerminalPointsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		in            string
+		point, source *Point
+		target        *Point
+	}{
+		{
+			name:   "source and target",
+			in:     `<mxGeometry relative="1" as="geometry"><mxPoint x="1" y="2" as="sourcePoint"></mxPoint><mxPoint x="3" y="4" as="targetPoint"></mxPoint></mxGeometry>`,
+			source: &Point{X: 1, Y: 2, As: "sourcePoint"},
+			target: &Point{X: 3, Y: 4, As: "targetPoint"},
+		},
+		{
+			name:   "reversed",
+			in:     `<mxGeometry relative="1" as="geometry"><mxPoint x="3" y="4" as="targetPoint"></mxPoint><mxPoint x="1" y="2" as="sourcePoint"></mxPoint></mxGeometry>`,
+			source: &Point{X: 1, Y: 2, As: "sourcePoint"},
+			target: &Point{X: 3, Y: 4, As: "targetPoint"},
+		},
+		{
+			name:   "offset and target",
+			in:     `<mxGeometry relative="1" as="geometry"><mxPoint x="5" as="offset"></mxPoint><mxPoint y="4" as="targetPoint"></mxPoint></mxGeometry>`,
+			point:  &Point{X: 5, As: "offset"},
+			target: &Point{Y: 4, As: "targetPoint"},
+		},
+		{
+			name: "none",
+			in:   `<mxGeometry relative="1" as="geometry"></mxGeometry>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var geo Geometry
+			if err := xml.Unmarshal([]byte(tt.in), &geo); err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range []struct {
+				name      string
+				got, want *Point
+			}{{"point", geo.Point, tt.point}, {"source", geo.SourcePoint, tt.source}, {"target", geo.TargetPoint, tt.target}} {
+				switch {
+				case p.got == nil && p.want == nil:
+				case p.got == nil || p.want == nil || p.got.X != p.want.X || p.got.Y != p.want.Y || p.got.As != p.want.As:
+					t.Errorf("%s: got %+v, want %+v", p.name, p.got, p.want)
+				}
+			}
+
+			// clones do not share their points
+			c := NewEdge("e", "1", "", "")
+			c.Geometry = &geo
+			d := c.clone()
+			for _, p := range []*Point{d.Geometry.Point, d.Geometry.SourcePoint, d.Geometry.TargetPoint} {
+				if p != nil {
+					p.X = 99
+				}
+			}
+			for _, p := range []*Point{geo.Point, geo.SourcePoint, geo.TargetPoint} {
+				if p != nil && p.X == 99 {
+					t.Errorf("got %+v changed through a clone", p)
+				}
+			}
+		})
+	}
+}
+
+func TestGeometryAlternateBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *Rect
+		out     string
+		wantErr bool
+	}{
+		{
+			name: "size",
+			in:   `<mxGeometry x="10" y="20" width="200" height="30" as="geometry"><mxRectangle width="200" height="150" as="alternateBounds"></mxRectangle></mxGeometry>`,
+			want: &Rect{Width: 200, Height: 150},
+			out:  `<mxRectangle width="200" height="150" as="alternateBounds"></mxRectangle>`,
+		},
+		{
+			name: "position",
+			in:   `<mxGeometry as="geometry"><mxRectangle x="5" y="-6" width="80.6" height="40" as="alternateBounds"></mxRectangle></mxGeometry>`,
+			want: &Rect{5, -6, 81, 40},
+			out:  `<mxRectangle x="5" y="-6" width="81" height="40" as="alternateBounds"></mxRectangle>`,
+		},
+		{
+			name: "other rectangle",
+			in:   `<mxGeometry as="geometry"><mxRectangle width="1" height="1" as="other"></mxRectangle></mxGeometry>`,
+		},
+		{
+			name:    "invalid",
+			in:      `<mxGeometry as="geometry"><mxRectangle width="wide" as="alternateBounds"></mxRectangle></mxGeometry>`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var geo Geometry
+			err := xml.Unmarshal([]byte(tt.in), &geo)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case geo.AlternateBounds == nil && tt.want == nil:
+			case geo.AlternateBounds == nil || tt.want == nil || *geo.AlternateBounds != *tt.want:
+				t.Fatalf("got %v, want %v", geo.AlternateBounds, tt.want)
+			}
+			out, err := xml.Marshal(geo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(out), "<mxRectangle"); got != (tt.out != "") || !strings.Contains(string(out), tt.out) {
+				t.Errorf("got %s, want it to contain %q", out, tt.out)
+			}
+
+			// clones do not share their bounds
+			if tt.want != nil {
+				c := NewShape("c", "1")
+				c.Geometry = &geo
+				c.clone().Geometry.AlternateBounds.Width = 99
+				if geo.AlternateBounds.Width == 99 {
+					t.Error("got the bounds changed through a clone")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package grawtest
+
+import (
+	"strconv"
+
+	graw "github.com/fuguohong1024/draw"
+)
+
+// Size and spacing of the shapes created by the fixture builders.
+const (
+	shapeWidth  = 120
+	shapeHeight = 60
+	spacing     = 40
+)
+
+// Chain returns a model of n shapes "n0" … "n{n-1}" in a row, each
+// connected to the next one.
+func Chain(n int) *graw.GraphModel {
+	g := graw.NewGraph()
+	for i := 0; i < n; i++ {
+		g.Add(shape(i, i*(shapeWidth+spacing), 0))
+		if i > 0 {
+			g.Add(edge(i-1, i))
+		}
+	}
+	return &g
+}
+
+// Grid returns a model of rows×cols shapes laid out in a grid, each
+// connected to its right and lower neighbour. Shape IDs are numbered
+// row by row.
+func Grid(rows, cols int) *graw.GraphModel {
+	g := graw.NewGraph()
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			i := r*cols + c
+			g.Add(shape(i, c*(shapeWidth+spacing), r*(shapeHeight+spacing)))
+			if c > 0 {
+				g.Add(edge(i-1, i))
+			}
+			if r > 0 {
+				g.Add(edge(i-cols, i))
+			}
+		}
+	}
+	return &g
+}
+
+// Tree returns a model of a complete tree with the given depth and
+// fanout, with edges pointing from parents to children. The root is
+// "n0"; the other shapes are numbered level by level.
+func Tree(depth, fanout int) *graw.GraphModel {
+	g := graw.NewGraph()
+	g.Add(shape(0, 0, 0))
+	level := []int{0}
+	next := 1
+	for d := 1; d < depth; d++ {
+		var children []int
+		for _, p := range level {
+			for k := 0; k < fanout; k++ {
+				g.Add(shape(next, len(children)*(shapeWidth+spacing), d*(shapeHeight+spacing)))
+				g.Add(edge(p, next))
+				children = append(children, next)
+				next++
+			}
+		}
+		level = children
+	}
+	return &g
+}
+
+// ID returns the ID of the i-th shape created by a fixture builder.
+func ID(i int) string {
+	return "n" + strconv.Itoa(i)
+}
+
+// shape returns the i-th fixture shape at (x, y).
+func shape(i, x, y int) *graw.Cell {
+	c := graw.NewShape(ID(i), layer)
+	c.Value = ID(i)
+	c.Geometry.X = x
+	c.Geometry.Y = y
+	c.Geometry.Width = strconv.Itoa(shapeWidth)
+	c.Geometry.Height = strconv.Itoa(shapeHeight)
+	return c
+}
+
+// edge returns a fixture edge from shape i to shape j.
+func edge(i, j int) *graw.Cell {
+	return &graw.Cell{
+		ID:       "e" + strconv.Itoa(i) + "-" + strconv.Itoa(j),
+		ParentID: layer,
+		Edge:     "1",
+		Source:   ID(i),
+		Target:   ID(j),
+		Geometry: &graw.Geometry{Relative: "1", As: "geometry"},
+	}
+}
@@ -0,0 +1,190 @@
+// Package grawtest provides helpers for testing code which generates
+// diagrams with graw: golden file comparison of canonicalized XML,
+// assertions on the structure of a model and fixture builders.
+package grawtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	graw "github.com/fuguohong1024/draw"
+)
+
+// Update makes Golden rewrite the golden files with the actual output
+// instead of comparing against them. Set it with
+// "go test -grawtest.update".
+var Update = flag.Bool("grawtest.update", false, "rewrite grawtest golden files")
+
+// layer is the ID of the default layer created by graw.NewGraph.
+const layer = "1"
+
+// Golden compares the canonical XML of g with the golden file at
+// path and fails t when they differ. With -grawtest.update the file is
+// written instead.
+func Golden(t testing.TB, g *graw.GraphModel, path string) {
+	t.Helper()
+
+	raw, err := xml.Marshal(g)
+	if err != nil {
+		t.Fatalf("grawtest: marshal model: %v", err)
+	}
+	got, err := Canonicalize(raw)
+	if err != nil {
+		t.Fatalf("grawtest: canonicalize model: %v", err)
+	}
+
+	if *Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("grawtest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("grawtest: %v", err)
+		}
+		return
+	}
+
+	raw, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("grawtest: %v (run with -grawtest.update to create it)", err)
+	}
+	want, err := Canonicalize(raw)
+	if err != nil {
+		t.Fatalf("grawtest: canonicalize %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("grawtest: model differs from %s:\n%s", path, diff(string(want), string(got)))
+	}
+}
+
+// Canonicalize returns data re-indented with the attributes of every
+// element sorted by name and whitespace between elements removed, so
+// that equivalent documents compare equal byte by byte.
+func Canonicalize(data []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+	e.Indent("", "  ")
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			sort.Slice(t.Attr, func(i, j int) bool {
+				if t.Attr[i].Name.Space != t.Attr[j].Name.Space {
+					return t.Attr[i].Name.Space < t.Attr[j].Name.Space
+				}
+				return t.Attr[i].Name.Local < t.Attr[j].Name.Local
+			})
+			tok = t
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			continue
+		}
+		if err := e.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// diff returns the lines of want and got around the first line where
+// they differ.
+func diff(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	i := 0
+	for i < len(wl) && i < len(gl) && wl[i] == gl[i] {
+		i++
+	}
+	line := func(l []string) string {
+		if i < len(l) {
+			return l[i]
+		}
+		return "<EOF>"
+	}
+	return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, line(wl), line(gl))
+}
+
+// HasEdge fails t unless g contains an edge from the cell sourceID to
+// the cell targetID.
+func HasEdge(t testing.TB, g *graw.GraphModel, sourceID, targetID string) {
+	t.Helper()
+	for _, c := range g.Root {
+		if c.Edge == "1" && c.Source == sourceID && c.Target == targetID {
+			return
+		}
+	}
+	t.Errorf("grawtest: no edge from %q to %q", sourceID, targetID)
+}
+
+// CellCount fails t unless g contains want vertices and edges. The
+// root cell and the layers are not counted.
+func CellCount(t testing.TB, g *graw.GraphModel, want int) {
+	t.Helper()
+	got := 0
+	for _, c := range g.Root {
+		if c.Vertex == "1" || c.Edge == "1" {
+			got++
+		}
+	}
+	if got != want {
+		t.Errorf("grawtest: got %d cells, want %d", got, want)
+	}
+}
+
+// NoOverlaps fails t when two vertices with the same parent overlap.
+// Vertices nested in one another, such as container children, are
+// not compared.
+func NoOverlaps(t testing.TB, g *graw.GraphModel) {
+	t.Helper()
+	type box struct {
+		id string
+		graw.Rect
+	}
+	byParent := make(map[string][]box)
+	var parents []string
+	for _, c := range g.Root {
+		if c.Vertex != "1" || c.Geometry == nil || c.Geometry.Relative == "1" {
+			continue
+		}
+		w, _ := strconv.Atoi(c.Geometry.Width)
+		h, _ := strconv.Atoi(c.Geometry.Height)
+		if _, ok := byParent[c.ParentID]; !ok {
+			parents = append(parents, c.ParentID)
+		}
+		byParent[c.ParentID] = append(byParent[c.ParentID], box{c.ID, graw.Rect{X: c.Geometry.X, Y: c.Geometry.Y, Width: w, Height: h}})
+	}
+
+	for _, p := range parents {
+		boxes := byParent[p]
+		for i := range boxes {
+			for j := i + 1; j < len(boxes); j++ {
+				a, b := boxes[i], boxes[j]
+				if a.Intersects(b.Rect) {
+					t.Errorf("grawtest: cells %q and %q overlap", a.id, b.id)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package grawtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	graw "github.com/fuguohong1024/draw"
+)
+
+// recorder is a testing.TB recording the failures reported to it
+// instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestCanonicalize(t *testing.T) {
+	a := `<mxGraphModel dy="2" dx="1">
+  <!-- comment -->
+  <root><mxCell parent="0" id="1"/></root>
+</mxGraphModel>`
+	b := `<mxGraphModel dx="1" dy="2"><root><mxCell id="1" parent="0"></mxCell></root></mxGraphModel>`
+
+	ca, err := Canonicalize([]byte(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := Canonicalize([]byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ca) != string(cb) {
+		t.Errorf("canonical forms differ:\n%s\n%s", ca, cb)
+	}
+	want := `<mxGraphModel dx="1" dy="2">
+  <root>
+    <mxCell id="1" parent="0"></mxCell>
+  </root>
+</mxGraphModel>
+`
+	if string(ca) != want {
+		t.Errorf("got\n%s\nwant\n%s", ca, want)
+	}
+
+	if _, err := Canonicalize([]byte("<a><b></a>")); err == nil {
+		t.Error("malformed XML: no error")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, Chain(3), filepath.Join("testdata", "chain3.golden"))
+	if *Update {
+		return
+	}
+
+	r := &recorder{TB: t}
+	Golden(r, Chain(4), filepath.Join("testdata", "chain3.golden"))
+	if len(r.errors) != 1 {
+		t.Errorf("different model: got %d failures, want 1", len(r.errors))
+	}
+
+	r = &recorder{TB: t}
+	Golden(r, Chain(3), filepath.Join("testdata", "missing.golden"))
+	if len(r.errors) == 0 {
+		t.Error("missing golden file: no failure")
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	tests := []struct {
+		name  string
+		g     *graw.GraphModel
+		cells int
+		edges [][2]int
+	}{
+		{"chain", Chain(4), 4 + 3, [][2]int{{0, 1}, {1, 2}, {2, 3}}},
+		{"grid", Grid(2, 3), 6 + 7, [][2]int{{0, 1}, {1, 2}, {0, 3}, {4, 5}, {2, 5}}},
+		{"tree", Tree(3, 2), 7 + 6, [][2]int{{0, 1}, {0, 2}, {1, 3}, {1, 4}, {2, 5}, {2, 6}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CellCount(t, tt.g, tt.cells)
+			for _, e := range tt.edges {
+				HasEdge(t, tt.g, ID(e[0]), ID(e[1]))
+			}
+			NoOverlaps(t, tt.g)
+		})
+	}
+}
+
+func TestAssertionsFail(t *testing.T) {
+	g := Chain(2)
+	r := &recorder{TB: t}
+	HasEdge(r, g, ID(1), ID(0))
+	CellCount(r, g, 2)
+	if len(r.errors) != 2 {
+		t.Errorf("got %d failures, want 2: %q", len(r.errors), r.errors)
+	}
+
+	// Moving n1 onto n0 makes them overlap, but not the child of a
+	// different parent.
+	g.FindByID(ID(1)).Geometry.X = 10
+	child := graw.NewShape("child", ID(0))
+	g.Add(child)
+	r = &recorder{TB: t}
+	NoOverlaps(r, g)
+	if len(r.errors) != 1 {
+		t.Errorf("got %d failures, want 1: %q", len(r.errors), r.errors)
+	}
+}
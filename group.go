@@ -0,0 +1,151 @@
+package graw
+
+// NewGroup returns a new group Cell, an invisible vertex holding
+// other cells, with the given unique ID (id) and parent ID (layerId).
+// Add cells to it with GraphModel.AddToGroup, which also sizes it;
+// draw.io moves, copies and deletes a group together with its
+// children.
+func NewGroup(id, layerId string) *Cell {
+	grp := NewShape(id, layerId)
+	grp.Style = Style{Attributes: map[string]string{"group": ""}}
+	grp.Connectable = "0"
+	grp.Geometry.setSize(0, 0)
+	return grp
+}
+
+// AddToGroup makes child a child of group, adding either of them to g
+// when g has no cell with its ID yet. group may also be a container,
+// such as a swimlane.
+//
+// The geometry of a child in draw.io is relative to its parent: its
+// X and Y are measured from the top left corner of the group, and so
+// are the waypoints of edges. AddToGroup converts the coordinates of
+// child so that it stays where it is on the page; coordinates of a
+// child not yet in g are taken as relative to the layer. Groups, unlike
+// containers, then shrink or grow to the bounds of their children,
+// moving so that the children keep their place.
+func (g *GraphModel) AddToGroup(group, child *Cell) {
+	if g.cell(group.ID) == nil {
+		g.Add(group)
+	}
+	if g.cell(child.ID) == nil {
+		g.Add(child)
+	}
+	grp, c := g.cell(group.ID), g.cell(child.ID)
+
+	// Convert the coordinates from the frame of the old parent to the
+	// one of the group.
+	var fromX, fromY int
+	if old := g.cell(c.ParentID); old != nil && !old.isLayer() {
+		fromX, fromY = g.absolutePosition(old)
+	}
+	toX, toY := g.absolutePosition(grp)
+	c.ParentID = grp.ID
+	if c.Geometry != nil {
+		shiftGeometry(c, fromX-toX, fromY-toY)
+	}
+
+	g.parentFirst(grp.ID, c.ID)
+
+	if _, ok := g.cell(group.ID).Style.Attributes["group"]; ok {
+		g.fitGroup(g.cell(group.ID))
+	}
+}
+
+// parentFirst moves the cell child right after the cell parent when it
+// comes first in g.Root, as draw.io expects parents before their
+// children.
+func (g *GraphModel) parentFirst(parent, child string) {
+	if pi, ci := g.cellIndex(parent), g.cellIndex(child); ci < pi {
+		moved := g.Root[ci]
+		copy(g.Root[ci:], g.Root[ci+1:pi+1])
+		g.Root[pi] = moved
+		g.reindex()
+	}
+}
+
+// shiftGeometry moves c by (dx, dy): the position of a vertex, the
+// waypoints and loose ends of an edge.
+func shiftGeometry(c *Cell, dx, dy int) {
+	geo := c.Geometry
+	if c.isEdge() {
+		for i := range geo.Points {
+			geo.Points[i].X += dx
+			geo.Points[i].Y += dy
+		}
+		for _, p := range []*Point{geo.SourcePoint, geo.TargetPoint} {
+			if p != nil {
+				p.X += dx
+				p.Y += dy
+			}
+		}
+		return
+	}
+	if geo.Relative != "1" {
+		geo.X += dx
+		geo.Y += dy
+	}
+}
+
+// fitGroup sets the bounds of grp to those of its vertex children,
+// moving the children so that they keep their place on the page.
+func (g *GraphModel) fitGroup(grp *Cell) {
+	var bounds Rect
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.ParentID == grp.ID && c.isVertex() && c.Geometry != nil {
+			bounds = bounds.Union(GeometryRect(c.Geometry))
+		}
+	}
+	if bounds.Empty() {
+		return
+	}
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.ParentID == grp.ID && c.Geometry != nil {
+			shiftGeometry(c, -bounds.X, -bounds.Y)
+		}
+	}
+	grp.Geometry.X += bounds.X
+	grp.Geometry.Y += bounds.Y
+	grp.Geometry.setSize(bounds.Width, bounds.Height)
+}
+
+// SetCollapsed folds the container c to its header, hiding its
+// children, or unfolds it. draw.io keeps the size of the state not
+// shown in the alternate bounds of the geometry: folding stores the
+// current size there and unfolding restores it, so that a container
+// created collapsed expands to the size it had when SetCollapsed was
+// called. The position of c does not change.
+func (c *Cell) SetCollapsed(collapsed bool) *Cell {
+	if (c.Collapsed == "1") == collapsed {
+		return c
+	}
+	c.Collapsed = ""
+	if collapsed {
+		c.Collapsed = "1"
+	}
+	if c.Geometry == nil {
+		return c
+	}
+	cur := GeometryRect(c.Geometry)
+	next := cur
+	switch {
+	case c.Geometry.AlternateBounds != nil:
+		next = *c.Geometry.AlternateBounds
+	case collapsed:
+		dx, dy := swimlaneContent(c)
+		if !isShape(c.Style, "swimlane") {
+			dx, dy = 0, groupHeader
+		}
+		if dx > 0 {
+			next.Width = dx
+		} else {
+			next.Height = dy
+		}
+	}
+	next.X, next.Y = cur.X, cur.Y
+	next.Apply(c.Geometry)
+	c.Geometry.AlternateBounds = &cur
+	return c
+}
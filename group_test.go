@@ -0,0 +1,279 @@
+package graw
+
+import (
+	"strings"
+	"testing"
+)
+
+// placedShape returns a 120x60 shape with id at (x, y) on layer "1".
+func placedShape(id string, x, y int) *Cell {
+	c := NewShape(id, "1")
+	c.Geometry.X, c.Geometry.Y = x, y
+	return c
+}
+
+func TestNewGroup(t *testing.T) {
+	grp := NewGroup("g", "1")
+	if _, ok := grp.Style.Attributes["group"]; !ok || len(grp.Style.Attributes) != 1 {
+		t.Errorf("got style %v, want group", grp.Style.Attributes)
+	}
+	if grp.ParentID != "1" || !grp.isVertex() || grp.Connectable != "0" {
+		t.Errorf("got parent %q, vertex %q, connectable %q", grp.ParentID, grp.Vertex, grp.Connectable)
+	}
+	if grp.Geometry.Width != "0" || grp.Geometry.Height != "0" {
+		t.Errorf("got size %s x %s, want 0 x 0", grp.Geometry.Width, grp.Geometry.Height)
+	}
+}
+
+func TestAddToGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		// build returns the model and the cells to add to the group
+		// "g", which is added to the model unless it is already.
+		build func() (*GraphModel, *Cell, []*Cell)
+		// bounds of the group and of the cells on the layer.
+		group  Rect
+		bounds map[string]Rect
+		// rel are the positions of the cells relative to the group.
+		rel map[string][2]int
+	}{
+		{
+			name: "new cells",
+			build: func() (*GraphModel, *Cell, []*Cell) {
+				g := NewGraph()
+				return &g, NewGroup("g", "1"), []*Cell{placedShape("a", 100, 100), placedShape("b", 300, 200)}
+			},
+			group:  Rect{100, 100, 320, 160},
+			bounds: map[string]Rect{"a": {100, 100, 120, 60}, "b": {300, 200, 120, 60}},
+			rel:    map[string][2]int{"a": {0, 0}, "b": {200, 100}},
+		},
+		{
+			name: "cells in the model",
+			build: func() (*GraphModel, *Cell, []*Cell) {
+				g := NewGraph()
+				a, b := placedShape("a", 50, 40), placedShape("b", 50, 140)
+				g.Add(a)
+				g.Add(b)
+				return &g, NewGroup("g", "1"), []*Cell{a, b}
+			},
+			group:  Rect{50, 40, 120, 160},
+			bounds: map[string]Rect{"a": {50, 40, 120, 60}, "b": {50, 140, 120, 60}},
+			rel:    map[string][2]int{"a": {0, 0}, "b": {0, 100}},
+		},
+		{
+			name: "from another group",
+			build: func() (*GraphModel, *Cell, []*Cell) {
+				g := NewGraph()
+				old := NewGroup("old", "1")
+				g.AddToGroup(old, placedShape("a", 500, 500))
+				g.AddToGroup(old, placedShape("x", 700, 500))
+				return &g, NewGroup("g", "1"), []*Cell{g.FindByID("a"), placedShape("b", 400, 600)}
+			},
+			group:  Rect{400, 500, 220, 160},
+			bounds: map[string]Rect{"a": {500, 500, 120, 60}, "b": {400, 600, 120, 60}, "x": {700, 500, 120, 60}},
+			rel:    map[string][2]int{"a": {100, 0}, "b": {0, 100}},
+		},
+		{
+			name: "container keeps its bounds",
+			build: func() (*GraphModel, *Cell, []*Cell) {
+				g := NewGraph()
+				lane := NewSwimlane("g", "1", "Lane")
+				lane.Geometry.X, lane.Geometry.Y = 50, 50
+				lane.Geometry.setSize(400, 300)
+				return &g, lane, []*Cell{placedShape("a", 100, 120)}
+			},
+			group:  Rect{50, 50, 400, 300},
+			bounds: map[string]Rect{"a": {100, 120, 120, 60}},
+			rel:    map[string][2]int{"a": {50, 70}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, grp, cells := tt.build()
+			for _, c := range cells {
+				g.AddToGroup(grp, c)
+			}
+			if got := g.BoundsOf(g.FindByID("g")); got != tt.group {
+				t.Errorf("got group bounds %v, want %v", got, tt.group)
+			}
+			for id, want := range tt.bounds {
+				if got := g.BoundsOf(g.FindByID(id)); got != want {
+					t.Errorf("got bounds of %s %v, want %v", id, got, want)
+				}
+			}
+			for id, want := range tt.rel {
+				c := g.FindByID(id)
+				if c.ParentID != "g" || c.Geometry.X != want[0] || c.Geometry.Y != want[1] {
+					t.Errorf("got %s in %q at (%d, %d), want in g at %v", id, c.ParentID, c.Geometry.X, c.Geometry.Y, want)
+				}
+				if g.cellIndex(id) < g.cellIndex("g") {
+					t.Errorf("got %s before its group", id)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("got style issues %v", iss)
+			}
+		})
+	}
+}
+
+func TestAddToGroupEdge(t *testing.T) {
+	g := NewGraph()
+	grp := NewGroup("g", "1")
+	g.AddToGroup(grp, placedShape("a", 100, 100))
+	e := newEdgeCell("e", "1", "a", "")
+	e.Geometry = &Geometry{Relative: "1", Points: []Point{{X: 150, Y: 300}}, TargetPoint: &Point{X: 400, Y: 300}}
+	g.Add(e)
+	g.AddToGroup(grp, e)
+
+	// Edges do not change the bounds of the group.
+	if got, want := g.BoundsOf(g.FindByID("g")), (Rect{100, 100, 120, 60}); got != want {
+		t.Errorf("got group bounds %v, want %v", got, want)
+	}
+	if p := e.Geometry.Points[0]; p.X != 50 || p.Y != 200 {
+		t.Errorf("got waypoint (%d, %d), want (50, 200)", p.X, p.Y)
+	}
+	if p := e.Geometry.TargetPoint; p.X != 300 || p.Y != 200 {
+		t.Errorf("got target point (%d, %d), want (300, 200)", p.X, p.Y)
+	}
+}
+
+func TestParentFirst(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	g.Add(NewGroup("g", "1"))
+	g.AddToGroup(g.FindByID("g"), g.FindByID("b"))
+	var ids []string
+	for _, c := range g.Root {
+		ids = append(ids, c.ID)
+	}
+	want := "0 1 a a-b g b"
+	if got := strings.Join(ids, " "); got != want {
+		t.Errorf("got order %q, want %q", got, want)
+	}
+	if g.FindByID("b") != g.Root[5] {
+		t.Error("got stale index after reordering")
+	}
+}
+
+func TestSetCollapsed(t *testing.T) {
+	tests := []struct {
+		name      string
+		cell      func() *Cell
+		collapse  []bool
+		collapsed string
+		want      Rect
+		alternate *Rect
+	}{
+		{
+			name: "swimlane",
+			cell: func() *Cell {
+				s := NewSwimlane("c", "1", "Lane")
+				s.Geometry.setSize(480, 300)
+				return s
+			},
+			collapse:  []bool{true},
+			collapsed: "1",
+			want:      Rect{10, 10, 480, 30},
+			alternate: &Rect{10, 10, 480, 300},
+		},
+		{
+			name: "vertical swimlane",
+			cell: func() *Cell {
+				s := NewSwimlane("c", "1", "Lane")
+				s.Style.set("horizontal", "0")
+				s.Style.set("startSize", "40")
+				s.Geometry.setSize(480, 300)
+				return s
+			},
+			collapse:  []bool{true},
+			collapsed: "1",
+			want:      Rect{10, 10, 40, 300},
+			alternate: &Rect{10, 10, 480, 300},
+		},
+		{
+			name: "group",
+			cell: func() *Cell {
+				c := NewGroup("c", "1")
+				c.Geometry.setSize(200, 100)
+				return c
+			},
+			collapse:  []bool{true},
+			collapsed: "1",
+			want:      Rect{10, 10, 200, groupHeader},
+			alternate: &Rect{10, 10, 200, 100},
+		},
+		{
+			name: "expanded again",
+			cell: func() *Cell {
+				s := NewSwimlane("c", "1", "Lane")
+				s.Geometry.setSize(480, 300)
+				return s
+			},
+			collapse:  []bool{true, false},
+			want:      Rect{10, 10, 480, 300},
+			alternate: &Rect{10, 10, 480, 30},
+		},
+		{
+			name: "unchanged",
+			cell: func() *Cell {
+				s := NewSwimlane("c", "1", "Lane")
+				s.Geometry.setSize(480, 300)
+				return s
+			},
+			collapse:  []bool{true, true, false, false},
+			want:      Rect{10, 10, 480, 300},
+			alternate: &Rect{10, 10, 480, 30},
+		},
+		{
+			name: "not collapsed",
+			cell: func() *Cell {
+				s := NewSwimlane("c", "1", "Lane")
+				s.Geometry.setSize(480, 300)
+				return s
+			},
+			collapse: []bool{false},
+			want:     Rect{10, 10, 480, 300},
+		},
+		{
+			name: "created collapsed",
+			cell: func() *Cell {
+				s := NewSwimlane("c", "1", "Lane")
+				s.Geometry.setSize(480, 30)
+				s.Geometry.AlternateBounds = &Rect{0, 0, 600, 400}
+				return s
+			},
+			collapse:  []bool{true},
+			collapsed: "1",
+			want:      Rect{10, 10, 600, 400},
+			alternate: &Rect{10, 10, 480, 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.cell()
+			for _, v := range tt.collapse {
+				if got := c.SetCollapsed(v); got != c {
+					t.Fatal("does not return the cell")
+				}
+			}
+			if c.Collapsed != tt.collapsed {
+				t.Errorf("got collapsed %q, want %q", c.Collapsed, tt.collapsed)
+			}
+			if got := GeometryRect(c.Geometry); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			switch alt := c.Geometry.AlternateBounds; {
+			case alt == nil && tt.alternate == nil:
+			case alt == nil || tt.alternate == nil || *alt != *tt.alternate:
+				t.Errorf("got alternate bounds %v, want %v", alt, tt.alternate)
+			}
+			checkRoundTrip(t, &GraphModel{Root: []*Cell{c}})
+		})
+	}
+
+	// Cells without a geometry only change their flag.
+	c := (&Cell{ID: "c"}).SetCollapsed(true)
+	if c.Collapsed != "1" || c.Geometry != nil {
+		t.Errorf("got %+v", c)
+	}
+}
@@ -0,0 +1,218 @@
+package graw
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// historyTimeFormat is the time stamp in the file names of versions.
+const historyTimeFormat = "20060102T150405Z"
+
+// A History keeps the versions of a diagram which is regenerated over
+// time, e.g. by a CI job, as a lightweight audit trail: every version
+// is written to a file of its own in Dir, named after the time it was
+// recorded, and an index diagram lists the versions, newest first,
+// with a summary of their changes.
+//
+// The versions are recorded in the manifest <Name>.history.json, the
+// index is written to <Name>-index.drawio and the versions to
+// <Name>-<time>.drawio.
+type History struct {
+	// Dir is the directory the files are written to.
+	Dir string
+	// Name is the base name of the files, "diagram" if empty.
+	Name string
+	// Now returns the time of a new version, time.Now if nil.
+	Now func() time.Time
+}
+
+// HistoryEntry describes a version of a diagram kept in a History.
+type HistoryEntry struct {
+	// File is the name of the file holding the version, relative
+	// to the directory of the History.
+	File string    `json:"file"`
+	Time time.Time `json:"time"`
+	// Summary describes the version, as given to Append.
+	Summary string `json:"summary,omitempty"`
+	// Added, Removed and Changed count the cells which were added,
+	// removed and changed since the previous version.
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+	// Cells maps the IDs of the cells of the version to a hash of
+	// their content, to compare the next version with.
+	Cells map[string]string `json:"cells"`
+}
+
+// name returns the base name of the files of h.
+func (h *History) name() string {
+	if h.Name == "" {
+		return "diagram"
+	}
+	return h.Name
+}
+
+// Entries returns the versions recorded in h, oldest first.
+func (h *History) Entries() ([]HistoryEntry, error) {
+	data, err := os.ReadFile(filepath.Join(h.Dir, h.name()+".history.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("graw: History: %v", err)
+	}
+	return entries, nil
+}
+
+// Append records g as the latest version: it writes g to a new file,
+// with the time and summary of the version in the data of its root
+// cell, compares it with the previous version and rewrites the
+// manifest and the index. Append returns the new entry.
+func (h *History) Append(g *GraphModel, summary string) (HistoryEntry, error) {
+	entries, err := h.Entries()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	now := time.Now
+	if h.Now != nil {
+		now = h.Now
+	}
+	e := HistoryEntry{Time: now().UTC(), Summary: summary, Cells: cellHashes(g)}
+	if len(entries) > 0 {
+		prev := entries[len(entries)-1].Cells
+		for id, sum := range e.Cells {
+			if old, ok := prev[id]; !ok {
+				e.Added++
+			} else if old != sum {
+				e.Changed++
+			}
+		}
+		for id := range prev {
+			if _, ok := e.Cells[id]; !ok {
+				e.Removed++
+			}
+		}
+	} else {
+		e.Added = len(e.Cells)
+	}
+
+	base := h.name() + "-" + e.Time.Format(historyTimeFormat)
+	e.File = base + ".drawio"
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(h.Dir, e.File)); os.IsNotExist(err) {
+			break
+		}
+		e.File = base + "-" + strconv.Itoa(n) + ".drawio"
+	}
+
+	version := g.clone()
+	if len(version.Root) > 0 {
+		meta := make(map[string]string)
+		for k, v := range version.Root[0].Data {
+			meta[k] = v
+		}
+		meta["version"] = strconv.Itoa(len(entries) + 1)
+		meta["time"] = e.Time.Format(time.RFC3339)
+		if summary != "" {
+			meta["summary"] = summary
+		}
+		version.Root[0].Data = meta
+	}
+	if err := writeXMLFile(filepath.Join(h.Dir, e.File), &version); err != nil {
+		return HistoryEntry{}, err
+	}
+
+	entries = append(entries, e)
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if err := os.WriteFile(filepath.Join(h.Dir, h.name()+".history.json"), data, 0o644); err != nil {
+		return HistoryEntry{}, err
+	}
+	index := newHistoryIndex(h.name(), entries)
+	if err := writeXMLFile(filepath.Join(h.Dir, h.name()+"-index.drawio"), index); err != nil {
+		return HistoryEntry{}, err
+	}
+	return e, nil
+}
+
+// cellHashes returns the IDs of the cells of g mapped to a hash of
+// their XML encoding.
+func cellHashes(g *GraphModel) map[string]string {
+	sums := make(map[string]string, len(g.Root))
+	for _, c := range g.Root {
+		data, err := xml.Marshal(c)
+		if err != nil {
+			continue
+		}
+		h := fnv.New64a()
+		h.Write(data)
+		sums[c.ID] = strconv.FormatUint(h.Sum64(), 16)
+	}
+	return sums
+}
+
+// newHistoryIndex returns the index diagram of the versions entries of
+// the diagram name: a title above a list of the versions, newest
+// first, each linking to its file.
+func newHistoryIndex(name string, entries []HistoryEntry) *GraphModel {
+	g := NewGraph()
+	title := NewShape("title", rootCellID)
+	title.Value = "<b>" + html.EscapeString(name) + "</b> history"
+	title.Style = Style{Attributes: shapeStyle("text")}
+	title.Style.set("align", "left")
+	title.Style.set("fontSize", "16")
+	title.Geometry.setSize(400, 30)
+	g.Add(title)
+
+	y := title.Geometry.Y + 40
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		c := NewShape("version-"+strconv.Itoa(i+1), rootCellID)
+		label := "<b>v" + strconv.Itoa(i+1) + "</b> " + e.Time.Format("2006-01-02 15:04 MST")
+		if e.Summary != "" {
+			label += "<br>" + html.EscapeString(e.Summary)
+		}
+		label += fmt.Sprintf("<br><font color=\"#666666\">+%d −%d ~%d cells</font>", e.Added, e.Removed, e.Changed)
+		c.Value = label
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		c.Style.set("align", "left")
+		c.Style.set("spacingLeft", "10")
+		if i == len(entries)-1 {
+			c.Style.set("fillColor", "#d5e8d4")
+			c.Style.set("strokeColor", "#82b366")
+		}
+		c.Data = map[string]string{"link": e.File}
+		c.Geometry.Y = y
+		SizeToFit(c, nil, 400, 50)
+		_, h := c.Geometry.size()
+		g.Add(c)
+		y += h + 10
+	}
+	return &g
+}
+
+// writeXMLFile writes g to the file path as an indented XML document.
+func writeXMLFile(path string, g *GraphModel) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := g.Encode(f, EncodeIndent("  ")); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,114 @@
+package graw
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	h := &History{Dir: dir, Now: func() time.Time { return now }}
+
+	g1 := newTestGraph([2]string{"a", "b"})
+	g2 := newTestGraph([2]string{"a", "b"}, [2]string{"b", "c"})
+	g2.FindByID("a").Value = "A"
+	g3 := newTestGraph([2]string{"b", "c"})
+
+	tests := []struct {
+		name                    string
+		g                       *GraphModel
+		summary                 string
+		file                    string
+		added, removed, changed int
+	}{
+		{"first", &g1, "initial", "diagram-20250301T110000Z.drawio", 5, 0, 0},
+		{"same time", &g2, "add c & more", "diagram-20250301T110000Z-2.drawio", 2, 0, 1},
+		{"later", &g3, "", "diagram-20250301T110500Z.drawio", 0, 2, 0},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if i == 2 {
+				now = now.Add(5 * time.Minute)
+			}
+			e, err := h.Append(tt.g, tt.summary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.File != tt.file || e.Summary != tt.summary || !e.Time.Equal(now) || e.Time.Location() != time.UTC {
+				t.Errorf("got %s, %q at %v", e.File, e.Summary, e.Time)
+			}
+			if got := [3]int{e.Added, e.Removed, e.Changed}; got != [3]int{tt.added, tt.removed, tt.changed} {
+				t.Errorf("got +%d -%d ~%d, want +%d -%d ~%d", e.Added, e.Removed, e.Changed, tt.added, tt.removed, tt.changed)
+			}
+
+			v, err := ParseFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			meta := v.Root[0].Data
+			if meta["version"] != strconv.Itoa(i+1) || meta["time"] != e.Time.Format(time.RFC3339) || meta["summary"] != tt.summary {
+				t.Errorf("got metadata %v", meta)
+			}
+			if tt.g.Root[0].Data != nil {
+				t.Errorf("got the metadata set on the diagram: %v", tt.g.Root[0].Data)
+			}
+		})
+	}
+
+	entries, err := h.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.File)
+	}
+	if want := []string{tests[0].file, tests[1].file, tests[2].file}; !reflect.DeepEqual(files, want) {
+		t.Errorf("got entries %q, want %q", files, want)
+	}
+
+	index, err := ParseFile(filepath.Join(dir, "diagram-index.drawio"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var links, labels []string
+	for _, c := range index.Root {
+		if strings.HasPrefix(c.ID, "version-") {
+			links = append(links, c.Data["link"])
+			labels = append(labels, c.Value)
+		}
+	}
+	if want := []string{tests[2].file, tests[1].file, tests[0].file}; !reflect.DeepEqual(links, want) {
+		t.Errorf("got links %q, want %q", links, want)
+	}
+	if want := `<b>v2</b> 2025-03-01 11:00 UTC<br>add c &amp; more<br><font color="#666666">+2 −0 ~1 cells</font>`; len(labels) != 3 || labels[1] != want {
+		t.Errorf("got labels %q, want the second %q", labels, want)
+	}
+	if got := index.FindByID("version-3").Style.Attributes["fillColor"]; got != "#d5e8d4" {
+		t.Errorf("got latest fillColor %q", got)
+	}
+}
+
+func TestHistoryEntries(t *testing.T) {
+	dir := t.TempDir()
+	h := &History{Dir: dir, Name: "net"}
+	if entries, err := h.Entries(); err != nil || entries != nil {
+		t.Errorf("got %v, %v, want no entries", entries, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "net.history.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Entries(); err == nil {
+		t.Error("got no error")
+	}
+	g := NewGraph()
+	if _, err := h.Append(&g, ""); err == nil {
+		t.Error("Append: got no error")
+	}
+}
@@ -0,0 +1,298 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// An ImagePolicy restricts the images a diagram may show, so that
+// generated diagrams do not embed tracking pixels or megabytes of data
+// by accident.
+type ImagePolicy struct {
+	// Schemes lists the allowed URL schemes, "https" and "data" if
+	// nil.
+	Schemes []string
+	// Hosts lists the hosts remote images may be loaded from; an
+	// entry starting with a dot allows the subdomains of a domain,
+	// e.g. ".example.com". nil allows any host.
+	Hosts []string
+	// MaxDataSize is the number of bytes the image of a data URI may
+	// have once decoded; zero allows any size.
+	MaxDataSize int
+}
+
+// Check returns an error when p does not allow the image URL u. Data
+// URIs must hold a PNG or an SVG image without scripts or references
+// to remote resources, in the form of NewImage, "data:image/png,<base64>",
+// or of HTML, "data:image/png;base64,<base64>".
+func (p ImagePolicy) Check(u string) error {
+	schemes := p.Schemes
+	if schemes == nil {
+		schemes = []string{"https", "data"}
+	}
+	if strings.HasPrefix(u, "data:") {
+		if !containsString(schemes, "data") {
+			return fmt.Errorf("graw: image: scheme \"data\" not allowed")
+		}
+		return p.checkData(u)
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("graw: image: %v", err)
+	}
+	allowed := false
+	for _, s := range schemes {
+		allowed = allowed || strings.EqualFold(s, parsed.Scheme)
+	}
+	if !allowed {
+		return fmt.Errorf("graw: image: scheme %q not allowed", parsed.Scheme)
+	}
+	if p.Hosts == nil {
+		return nil
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, h := range p.Hosts {
+		h = strings.ToLower(h)
+		if host == h || strings.HasPrefix(h, ".") && (strings.HasSuffix(host, h) || host == h[1:]) {
+			return nil
+		}
+	}
+	return fmt.Errorf("graw: image: host %q not allowed", host)
+}
+
+// checkData checks the data URI u.
+func (p ImagePolicy) checkData(u string) error {
+	i := strings.IndexByte(u, ',')
+	if i < 0 {
+		return fmt.Errorf("graw: image: malformed data URI")
+	}
+	media := strings.ToLower(strings.TrimSuffix(u[len("data:"):i], ";base64"))
+	payload := u[i+1:]
+	if p.MaxDataSize > 0 && base64.StdEncoding.DecodedLen(len(payload)) > p.MaxDataSize+2 {
+		return fmt.Errorf("graw: image: larger than %d bytes", p.MaxDataSize)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+	}
+	if err != nil {
+		return fmt.Errorf("graw: image: data URI is not base64 encoded")
+	}
+	if p.MaxDataSize > 0 && len(data) > p.MaxDataSize {
+		return fmt.Errorf("graw: image: larger than %d bytes", p.MaxDataSize)
+	}
+	switch media {
+	case "image/png":
+		if !bytes.HasPrefix(data, pngSignature) {
+			return fmt.Errorf("graw: image: data URI is not a PNG image")
+		}
+	case "image/svg+xml":
+		return checkSVG(data)
+	default:
+		return fmt.Errorf("graw: image: media type %q not allowed", media)
+	}
+	return nil
+}
+
+// svgActiveElements are the elements of an SVG image which run
+// scripts or embed HTML, in lower case.
+var svgActiveElements = []string{"script", "foreignobject", "iframe", "embed", "object"}
+
+// svgAnimations are the elements of an SVG image which change the
+// attributes of others, in lower case.
+var svgAnimations = []string{"animate", "set", "animatemotion", "animatetransform"}
+
+// checkSVG returns an error unless data is an SVG image which runs no
+// scripts and loads no resources: it must not hold script elements,
+// event handler attributes or HTML, and its links, attribute url()
+// references and style sheets may only refer to elements of the image
+// or to data URIs. Entities and character references are resolved by
+// the XML decoder before the checks, and escapes by checkCSS.
+func checkSVG(data []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	root, style := true, 0
+	// sheet collects the text of style elements, which comments and
+	// CDATA sections may split into several tokens.
+	var sheet strings.Builder
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("graw: image: malformed SVG image: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if root && name != "svg" {
+				return fmt.Errorf("graw: image: data URI is not an SVG image")
+			}
+			root = false
+			if containsString(svgActiveElements, name) {
+				return fmt.Errorf("graw: image: SVG image with %s element", t.Name.Local)
+			}
+			if name == "style" {
+				style++
+			}
+			for _, a := range t.Attr {
+				attr := strings.ToLower(a.Name.Local)
+				switch {
+				case strings.HasPrefix(attr, "on"):
+					return fmt.Errorf("graw: image: SVG image with event handler %s", a.Name.Local)
+				case attr == "href":
+					if !localRef(a.Value) {
+						return fmt.Errorf("graw: image: SVG image with remote reference %q", a.Value)
+					}
+				case attr == "attributename" && containsString(svgAnimations, name):
+					// Animations could set a link or a handler
+					// after the checks.
+					if v := strings.ToLower(strings.TrimSpace(a.Value)); strings.HasSuffix(v, "href") || strings.HasPrefix(v, "on") {
+						return fmt.Errorf("graw: image: SVG image animating %s", a.Value)
+					}
+				}
+				if err := checkCSS(a.Value); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if strings.EqualFold(t.Name.Local, "style") && style > 0 {
+				if style--; style == 0 {
+					if err := checkCSS(sheet.String()); err != nil {
+						return err
+					}
+					sheet.Reset()
+				}
+			}
+		case xml.CharData:
+			if style > 0 {
+				sheet.Write(t)
+			}
+		case xml.ProcInst:
+			// <?xml-stylesheet?> loads a style sheet.
+			if t.Target != "xml" {
+				return fmt.Errorf("graw: image: SVG image with processing instruction %s", t.Target)
+			}
+		case xml.Directive:
+			return fmt.Errorf("graw: image: SVG image with DTD")
+		}
+	}
+	if root {
+		return fmt.Errorf("graw: image: data URI is not an SVG image")
+	}
+	return nil
+}
+
+// checkCSS returns an error when the style sheet or property value css
+// imports another style sheet or refers to a resource by url() which is
+// not local; see localRef.
+func checkCSS(css string) error {
+	css = strings.ToLower(unescapeCSS(css))
+	if strings.Contains(css, "@import") {
+		return fmt.Errorf("graw: image: SVG image importing a style sheet")
+	}
+	// image-set() and src() take URLs as plain strings too.
+	for _, f := range []string{"image-set(", "src("} {
+		if strings.Contains(css, f) {
+			return fmt.Errorf("graw: image: SVG image with remote reference %q", f)
+		}
+	}
+	for rest := css; ; {
+		i := strings.Index(rest, "url(")
+		if i < 0 {
+			return nil
+		}
+		rest = strings.TrimLeft(rest[i+len("url("):], " \t\r\n\f")
+		end := ")"
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			end, rest = rest[:1], rest[1:]
+		}
+		ref := rest
+		if j := strings.Index(rest, end); j >= 0 {
+			ref = rest[:j]
+		}
+		if !localRef(ref) {
+			return fmt.Errorf("graw: image: SVG image with remote reference %q", ref)
+		}
+	}
+}
+
+// unescapeCSS replaces the escapes of css, a backslash followed by up
+// to six hexadecimal digits and an optional space or by any other
+// character, with the characters they stand for.
+func unescapeCSS(css string) string {
+	if !strings.Contains(css, "\\") {
+		return css
+	}
+	var b strings.Builder
+	for i := 0; i < len(css); i++ {
+		if css[i] != '\\' || i+1 == len(css) {
+			b.WriteByte(css[i])
+			continue
+		}
+		j := i + 1
+		for j < len(css) && j < i+7 && strings.IndexByte("0123456789abcdefABCDEF", css[j]) >= 0 {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(css[j])
+			i = j
+			continue
+		}
+		r, _ := strconv.ParseUint(css[i+1:j], 16, 32)
+		b.WriteRune(rune(r))
+		if j < len(css) && strings.IndexByte(" \t\r\n\f", css[j]) >= 0 {
+			j++
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+// localRef reports whether the link ref of an SVG image refers to an
+// element of the image, "#id", or to a data URI. Browsers ignore
+// whitespace and control characters in URLs, so they are removed
+// before looking at the scheme; links without one, relative or
+// protocol-relative like "//host/x.png", are not local.
+func localRef(ref string) bool {
+	ref = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, ref)
+	ref = strings.ToLower(ref)
+	return strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "data:")
+}
+
+// NewImage returns a new image Cell like NewImage, or an error when p
+// does not allow url.
+func (p ImagePolicy) NewImage(id, layerId, url string) (*Cell, error) {
+	if err := p.Check(url); err != nil {
+		return nil, err
+	}
+	return NewImage(id, layerId, url), nil
+}
+
+// SetBackgroundImage draws img behind the diagram of g like
+// GraphModel.SetBackgroundImage, or returns an error and leaves g
+// alone when p does not allow the URL of img. nil removes the
+// background image.
+func (p ImagePolicy) SetBackgroundImage(g *GraphModel, img *BackgroundImage) error {
+	if img != nil {
+		if err := p.Check(img.Src); err != nil {
+			return err
+		}
+	}
+	g.SetBackgroundImage(img)
+	return nil
+}
@@ -0,0 +1,150 @@
+package graw
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// svgURI returns svg as a base64 data URI.
+func svgURI(svg string) string {
+	return "data:image/svg+xml," + base64.StdEncoding.EncodeToString([]byte(svg))
+}
+
+func TestImagePolicyCheck(t *testing.T) {
+	png := "data:image/png," + base64.StdEncoding.EncodeToString(append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 20)...))
+	p := ImagePolicy{Hosts: []string{".example.com"}}
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://cdn.example.com/a.png", true},
+		{"https://example.com/a.png", true},
+		{"https://evil.com/a.png", false},
+		{"http://cdn.example.com/a.png", false},
+		{png, true},
+		{strings.Replace(png, ",", ";base64,", 1), true},
+		{"data:image/gif," + png[len("data:image/png,"):], false},
+		{"data:image/png," + base64.StdEncoding.EncodeToString([]byte("<svg/>")), false},
+		{"data:image/png,not base64!", false},
+	}
+	for _, tt := range tests {
+		if err := p.Check(tt.url); (err == nil) != tt.ok {
+			t.Errorf("Check(%.40q) = %v, want ok %v", tt.url, err, tt.ok)
+		}
+	}
+
+	small := ImagePolicy{MaxDataSize: 10}
+	if err := small.Check(png); err == nil {
+		t.Error("image larger than MaxDataSize allowed")
+	}
+}
+
+func TestImagePolicySVG(t *testing.T) {
+	const ns = `xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"`
+	tests := []struct {
+		name string
+		svg  string
+		ok   bool
+	}{
+		{"plain", `<svg ` + ns + `><rect width="10" height="10"/></svg>`, true},
+		{"local references", `<svg ` + ns + `><defs><linearGradient id="g"/></defs><rect fill="url(#g)" style="fill: url('#g')"/><use href="#g"/><use xlink:href="#g"/></svg>`, true},
+		{"data image", `<svg ` + ns + `><image href="data:image/png;base64,iVBORw0KGgo="/></svg>`, true},
+		{"local style sheet", `<svg ` + ns + `><style>rect { fill: url(#g); }</style><rect/></svg>`, true},
+		{"xml declaration", `<?xml version="1.0"?><svg ` + ns + `/>`, true},
+
+		{"not svg", `<html/>`, false},
+		{"empty", ``, false},
+		{"malformed", `<svg ` + ns + `><rect></svg>`, false},
+		{"script", `<svg ` + ns + `><script>alert(1)</script></svg>`, false},
+		{"script upper case", `<svg ` + ns + `><SCRIPT>alert(1)</SCRIPT></svg>`, false},
+		{"html script", `<svg ` + ns + `><h:script xmlns:h="http://www.w3.org/1999/xhtml">alert(1)</h:script></svg>`, false},
+		{"foreignObject", `<svg ` + ns + `><foreignObject><div xmlns="http://www.w3.org/1999/xhtml">x</div></foreignObject></svg>`, false},
+		{"onload", `<svg ` + ns + ` onload="alert(1)"/>`, false},
+		{"onclick", `<svg ` + ns + `><rect onclick="alert(1)"/></svg>`, false},
+		{"OnMouseOver", `<svg ` + ns + `><rect OnMouseOver="alert(1)"/></svg>`, false},
+		{"href http", `<svg ` + ns + `><image href="http://track.example/x.gif"/></svg>`, false},
+		{"href javascript", `<svg ` + ns + `><a href="javascript:alert(1)"><rect/></a></svg>`, false},
+		{"href protocol relative", `<svg ` + ns + `><image href="//track.example/x.gif"/></svg>`, false},
+		{"href relative", `<svg ` + ns + `><image href="x.gif"/></svg>`, false},
+		{"xlink:href whitespace", `<svg ` + ns + `><image xlink:href="  http://track.example/x.gif"/></svg>`, false},
+		{"xlink:href tab in scheme", `<svg ` + ns + `><a xlink:href="java&#9;script:alert(1)"><rect/></a></svg>`, false},
+		{"xlink:href entities", `<svg ` + ns + `><image xlink:href="&#104;&#x74;tp://track.example/x.gif"/></svg>`, false},
+		{"url double quotes", `<svg ` + ns + `><rect style="fill: url(&quot;http://track.example/x&quot;)"/></svg>`, false},
+		{"url single quotes", `<svg ` + ns + `><rect style="fill: url('http://track.example/x')"/></svg>`, false},
+		{"url attribute", `<svg ` + ns + `><rect filter="url(https://track.example/f.svg#f)"/></svg>`, false},
+		{"url upper case", `<svg ` + ns + `><rect style="fill: URL(http://track.example/x)"/></svg>`, false},
+		{"url css escape", `<svg ` + ns + `><rect style="fill: u\72l(http://track.example/x)"/></svg>`, false},
+		{"style sheet url", `<svg ` + ns + `><style>rect { background: url("http://track.example/x") }</style></svg>`, false},
+		{"import", `<svg ` + ns + `><style>@import "http://track.example/a.css";</style></svg>`, false},
+		{"import split by cdata", `<svg ` + ns + `><style>@im<![CDATA[port]]> "http://track.example/a.css";</style></svg>`, false},
+		{"import split by comment", `<svg ` + ns + `><style>@im<!-- -->port "http://track.example/a.css";</style></svg>`, false},
+		{"import css escape", `<svg ` + ns + `><style>@\69mport "http://track.example/a.css";</style></svg>`, false},
+		{"image-set", `<svg ` + ns + `><rect style="fill: image-set('http://track.example/x' 1x)"/></svg>`, false},
+		{"xml-stylesheet", `<?xml-stylesheet href="http://track.example/a.css"?><svg ` + ns + `/>`, false},
+		{"doctype", `<!DOCTYPE svg [<!ENTITY x "http://track.example/x">]><svg ` + ns + `/>`, false},
+		{"animate href", `<svg ` + ns + `><a><set attributeName="href" to="javascript:alert(1)"/><rect/></a></svg>`, false},
+		{"animate handler", `<svg ` + ns + `><animate attributeName="onbegin" to="alert(1)"/></svg>`, false},
+	}
+	var p ImagePolicy
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Check(svgURI(tt.svg)); (err == nil) != tt.ok {
+				t.Errorf("got %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestImagePolicyNewImage(t *testing.T) {
+	p := ImagePolicy{Hosts: []string{"example.com"}}
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://example.com/logo.png", true},
+		{"https://tracker.example/p.png", false},
+		{"http://example.com/logo.png", false},
+	}
+	for _, tt := range tests {
+		c, err := p.NewImage("a", "1", tt.url)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got error %v, want ok %v", tt.url, err, tt.ok)
+		}
+		if ok := c != nil && c.Style.Attributes["image"] == tt.url; ok != tt.ok {
+			t.Errorf("%s: got cell %+v, want one only when allowed", tt.url, c)
+		}
+	}
+}
+
+func TestImagePolicySetBackgroundImage(t *testing.T) {
+	p := ImagePolicy{Hosts: []string{"example.com"}}
+	old := &BackgroundImage{Src: "https://example.com/old.png", Width: 10, Height: 10}
+	tests := []struct {
+		name string
+		img  *BackgroundImage
+		want *BackgroundImage
+		ok   bool
+	}{
+		{"allowed", &BackgroundImage{Src: "https://example.com/bg.png", Width: 100, Height: 50},
+			&BackgroundImage{Src: "https://example.com/bg.png", Width: 100, Height: 50}, true},
+		{"other host", &BackgroundImage{Src: "https://tracker.example/p.png"}, old, false},
+		{"scheme", &BackgroundImage{Src: "file:///etc/passwd"}, old, false},
+		{"relative", &BackgroundImage{Src: "bg.png"}, old, false},
+		{"removed", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			g.SetBackgroundImage(old)
+			err := p.SetBackgroundImage(&g, tt.img)
+			if (err == nil) != tt.ok {
+				t.Errorf("got error %v, want ok %v", err, tt.ok)
+			}
+			if got := g.backgroundImage(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got background %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
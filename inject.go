@@ -0,0 +1,310 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// InjectInto returns the draw.io file templateFile, e.g. a template
+// designed by hand with a logo, a title block and styles, with the
+// cells of g added to its layer named layerName. The layer is looked
+// up on all pages, the first one having it is used; when no page has
+// it, a layer of that name is added on top of the first page.
+//
+// The layers of g are dropped and the cells in them moved to the
+// layer of the template; cells whose IDs are in use on the page get
+// the first free numeric suffix, the cells referring to them
+// following. When the page has an anchor, a vertex labelled
+// "{{content}}" or tagged "content", the cells are scaled and centered
+// to fit its bounds and the anchor is removed, unless other cells refer
+// to it. When the template defines a stylesheet, the styles of the
+// cells are resolved against it, see Stylesheet.Inline, so that they
+// pick up its default vertex and edge styles. The template is
+// otherwise kept byte for byte: the cells are inserted at the end of
+// the root element of the page, unless the page is compressed, in
+// which case only its content is encoded again.
+func InjectInto(templateFile, layerName string, g *GraphModel) ([]byte, error) {
+	data, err := os.ReadFile(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ParseDocument(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(d.pages) == 0 {
+		return nil, fmt.Errorf("graw: InjectInto: %s has no page", templateFile)
+	}
+
+	page, layer := 0, ""
+	for n, p := range d.pages {
+		for i := range p.Model.Root {
+			if c := p.Model.Root[i]; c.isLayer() && c.Value == layerName {
+				page, layer = n, c.ID
+				break
+			}
+		}
+		if layer != "" {
+			break
+		}
+	}
+	model := d.pages[page].Model
+	taken := model.idSet()
+	var cells []*Cell
+	if layer == "" {
+		l := NewLayer(taken.unique("layer"), layerName)
+		layer = l.ID
+		cells = append(cells, l)
+	}
+	cells = append(cells, injectedCells(g, taken, layer)...)
+	if d.Stylesheet != nil {
+		d.Stylesheet.inline(cells)
+	}
+
+	anchor := ""
+	for i := range model.Root {
+		if c := model.Root[i]; isTemplateAnchor(c) {
+			fitCells(cells, layer, model.BoundsOf(c))
+			if id := c.ID; model.Remove(id) == nil {
+				anchor = id
+			}
+			break
+		}
+	}
+
+	sp, err := templateSpans(data, page, anchor)
+	if err != nil {
+		return nil, err
+	}
+	at, end := sp.at, sp.end
+	var insert bytes.Buffer
+	if sp.compressed {
+		model.Root = append(model.Root, cells...)
+		text, err := EncodeCompressed(model)
+		if err != nil {
+			return nil, fmt.Errorf("graw: InjectInto: %v", err)
+		}
+		insert.WriteString(text)
+	} else {
+		// Indent the cells one level deeper than the end tag of the
+		// root element when the template is indented.
+		nl := bytes.LastIndexByte(data[:at], '\n')
+		line := data[nl+1 : at]
+		indented := nl >= 0 && len(bytes.TrimLeft(line, " \t")) == 0
+		e := xml.NewEncoder(&insert)
+		for _, c := range cells {
+			if indented {
+				insert.WriteString("  ")
+			}
+			if err := e.EncodeElement(c, xml.StartElement{Name: xml.Name{Local: "mxCell"}}); err != nil {
+				return nil, fmt.Errorf("graw: InjectInto: %v", err)
+			}
+			if err := e.Flush(); err != nil {
+				return nil, fmt.Errorf("graw: InjectInto: %v", err)
+			}
+			if indented {
+				insert.WriteByte('\n')
+				insert.Write(line)
+			}
+		}
+	}
+
+	out := make([]byte, 0, len(data)+insert.Len())
+	if sp.anchorEnd > 0 {
+		out = append(out, data[:sp.anchorAt]...)
+		out = append(out, data[sp.anchorEnd:at]...)
+	} else {
+		out = append(out, data[:at]...)
+	}
+	out = append(out, insert.Bytes()...)
+	out = append(out, data[end:]...)
+	return out, nil
+}
+
+// injectedCells returns copies of the cells of g other than its top
+// cell and layers, with IDs reserved in taken and the cells of the
+// layers of g moved to layer.
+func injectedCells(g *GraphModel, taken idSet, layer string) []*Cell {
+	ids := make(map[string]string, len(g.Root))
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.ID == topCellId || c.isLayer() {
+			ids[c.ID] = layer
+			continue
+		}
+		ids[c.ID] = taken.unique(c.ID)
+	}
+
+	var cells []*Cell
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.ID == topCellId || c.isLayer() {
+			continue
+		}
+		cp := c.clone()
+		cp.ID = ids[c.ID]
+		if id, ok := ids[c.ParentID]; ok {
+			cp.ParentID = id
+		} else {
+			cp.ParentID = layer
+		}
+		if id, ok := ids[c.Source]; ok {
+			cp.Source = id
+		}
+		if id, ok := ids[c.Target]; ok {
+			cp.Target = id
+		}
+		cells = append(cells, cp)
+	}
+	return cells
+}
+
+// templateAnchor is the tag, and "{{templateAnchor}}" the label, of
+// the vertex of a template InjectInto fits the cells into.
+const templateAnchor = "content"
+
+// isTemplateAnchor reports whether c is the anchor of a template.
+func isTemplateAnchor(c *Cell) bool {
+	if !c.isVertex() || c.Geometry == nil {
+		return false
+	}
+	return strings.TrimSpace(labelText(c)) == "{{"+templateAnchor+"}}" || c.HasTag(templateAnchor)
+}
+
+// fitCells scales the cells, which are in layer or nested in its
+// cells, and centers them in r, keeping their aspect ratio. Font sizes
+// are scaled along.
+func fitCells(cells []*Cell, layer string, r Rect) {
+	var bounds Rect
+	for i := range cells {
+		c := cells[i]
+		if c.ParentID == layer && c.isVertex() && c.Geometry != nil {
+			bounds = bounds.Union(GeometryRect(c.Geometry))
+		}
+	}
+	if bounds.Empty() || r.Empty() {
+		return
+	}
+	s := math.Min(float64(r.Width)/float64(bounds.Width), float64(r.Height)/float64(bounds.Height))
+	x0 := float64(r.X) + (float64(r.Width)-float64(bounds.Width)*s)/2
+	y0 := float64(r.Y) + (float64(r.Height)-float64(bounds.Height)*s)/2
+	// Positions on the layer are moved into r, those relative to a
+	// container only scaled.
+	place := func(top bool, x, y int) (int, int) {
+		if !top {
+			return int(math.Round(float64(x) * s)), int(math.Round(float64(y) * s))
+		}
+		return int(math.Round(x0 + float64(x-bounds.X)*s)), int(math.Round(y0 + float64(y-bounds.Y)*s))
+	}
+
+	for i := range cells {
+		c := cells[i]
+		if s != 1 && (c.isVertex() || c.isEdge()) {
+			size := float64(defaultFontSize)
+			if v, ok := c.Style.float("fontSize"); ok {
+				size = v
+			}
+			c.Style.set("fontSize", formatDecimal(size*s, 1))
+		}
+		geo := c.Geometry
+		if geo == nil {
+			continue
+		}
+		top := c.ParentID == layer
+		if c.isEdge() {
+			for j := range geo.Points {
+				geo.Points[j].X, geo.Points[j].Y = place(top, geo.Points[j].X, geo.Points[j].Y)
+			}
+			for _, p := range []*Point{geo.SourcePoint, geo.TargetPoint} {
+				if p != nil {
+					p.X, p.Y = place(top, p.X, p.Y)
+				}
+			}
+			continue
+		}
+		if geo.Relative != "1" {
+			w, h := geo.size()
+			geo.X, geo.Y = place(top, geo.X, geo.Y)
+			geo.setSize(int(math.Round(float64(w)*s)), int(math.Round(float64(h)*s)))
+		}
+	}
+}
+
+// spans are the parts of a template InjectInto changes: data[at:end]
+// is the content of the diagram element of the page when it is
+// compressed, otherwise at == end is the start of the end tag of its
+// root element. data[anchorAt:anchorEnd] is the line holding the
+// anchor element, if any.
+type spans struct {
+	at, end             int
+	compressed          bool
+	anchorAt, anchorEnd int
+}
+
+// templateSpans finds the spans of data, a draw.io file, for its page
+// numbered page and the cell anchor, unless empty.
+func templateSpans(data []byte, page int, anchor string) (spans, error) {
+	var sp spans
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []string
+	diagram := -1
+	for {
+		off := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sp, fmt.Errorf("graw: InjectInto: %v", err)
+		}
+		// A bare mxGraphModel document is a single page.
+		onPage := diagram == page || (page == 0 && len(stack) > 0 && stack[0] == "mxGraphModel")
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if onPage && anchor != "" && len(stack) > 0 && stack[len(stack)-1] == "root" && hasID(t, anchor) {
+				sp.anchorAt = off
+				// Drop the indentation and line break before the
+				// element along with it.
+				if nl := bytes.LastIndexByte(data[:off], '\n'); nl >= 0 && len(bytes.TrimLeft(data[nl+1:off], " \t")) == 0 {
+					sp.anchorAt = nl
+				}
+				if err := dec.Skip(); err != nil {
+					return sp, fmt.Errorf("graw: InjectInto: %v", err)
+				}
+				sp.anchorEnd = int(dec.InputOffset())
+				continue
+			}
+			stack = append(stack, t.Name.Local)
+			if t.Name.Local == "diagram" && len(stack) == 2 && stack[0] == "mxfile" {
+				diagram++
+			}
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			if t.Name.Local == "root" && onPage {
+				sp.at, sp.end = off, off
+				return sp, nil
+			}
+		case xml.CharData:
+			if diagram == page && len(stack) == 2 && stack[1] == "diagram" && strings.TrimSpace(string(t)) != "" {
+				sp.at, sp.end, sp.compressed = off, int(dec.InputOffset()), true
+				return sp, nil
+			}
+		}
+	}
+	return sp, fmt.Errorf("graw: InjectInto: page %d has no root element", page+1)
+}
+
+// hasID reports whether the element started by t has the ID id.
+func hasID(t xml.StartElement, id string) bool {
+	for _, a := range t.Attr {
+		if a.Name.Local == "id" {
+			return a.Value == id
+		}
+	}
+	return false
+}
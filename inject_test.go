@@ -0,0 +1,169 @@
+package graw
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testTemplate = `<mxfile>
+  <diagram name="Page-1" id="p1">
+    <mxGraphModel>
+      <root>
+        <mxCell id="0"/>
+        <mxCell id="1" parent="0"/>
+        <mxCell id="a" value="Logo" style="shape=image;" vertex="1" parent="1">
+          <mxGeometry x="0" y="0" width="40" height="40" as="geometry"/>
+        </mxCell>
+        <mxCell id="2" value="Content" parent="0"/>
+      </root>
+    </mxGraphModel>
+  </diagram>
+</mxfile>
+`
+
+// inject writes template to a file and returns the result of
+// InjectInto for it.
+func inject(t *testing.T, template, layer string, g *GraphModel) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "template.drawio")
+	if err := os.WriteFile(path, []byte(template), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := InjectInto(path, layer, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// injectedModel parses out and returns the model of its first page.
+func injectedModel(t *testing.T, out string) *GraphModel {
+	t.Helper()
+	d, err := ParseDocument(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	return d.pages[0].Model
+}
+
+func TestInjectInto(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		layer string
+		// want maps the IDs of the cells of the page to their parent
+		// and, for edges, source and target.
+		want map[string][3]string
+	}{
+		{"existing layer", "Content", map[string][3]string{
+			"0": {}, "1": {"0"}, "2": {"0"}, "a": {"1"},
+			"a-2": {"2"}, "b": {"2"}, "a-b": {"2", "a-2", "b"},
+		}},
+		{"added layer", "Diagram", map[string][3]string{
+			"0": {}, "1": {"0"}, "2": {"0"}, "a": {"1"}, "layer": {"0"},
+			"a-2": {"layer"}, "b": {"layer"}, "a-b": {"layer", "a-2", "b"},
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := newTestGraph([2]string{"a", "b"})
+			out := inject(t, testTemplate, test.layer, &g)
+			if !strings.HasPrefix(out, testTemplate[:strings.Index(testTemplate, "      </root>")]) {
+				t.Errorf("template not kept:\n%s", out)
+			}
+			m := injectedModel(t, out)
+			got := make(map[string][3]string)
+			for _, c := range m.Root {
+				got[c.ID] = [3]string{c.ParentID, c.Source, c.Target}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+			if l := m.cell(got["b"][0]); l.Value != test.layer {
+				t.Errorf("got layer %q, want %q", l.Value, test.layer)
+			}
+		})
+	}
+}
+
+func TestInjectIntoCompressed(t *testing.T) {
+	page := NewGraph()
+	page.Add(NewShape("a", "1"))
+	text, err := EncodeCompressed(&page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := `<mxfile><diagram name="Page-1">` + text + `</diagram><diagram name="Page-2">` + text + `</diagram></mxfile>`
+	g := newTestGraph([2]string{"a", "b"})
+	out := inject(t, template, "Diagram", &g)
+	if !strings.HasSuffix(out, `</diagram><diagram name="Page-2">`+text+`</diagram></mxfile>`) {
+		t.Errorf("second page not kept:\n%s", out)
+	}
+	if got, want := ids(injectedModel(t, out)), []string{"0", "1", "a", "layer", "a-2", "b", "a-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestInjectIntoErrors(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"})
+	if _, err := InjectInto(filepath.Join(t.TempDir(), "missing.drawio"), "Content", &g); err == nil {
+		t.Error("got no error for a missing file")
+	}
+	path := filepath.Join(t.TempDir(), "empty.drawio")
+	if err := os.WriteFile(path, []byte("<mxfile></mxfile>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InjectInto(path, "Content", &g); err == nil {
+		t.Error("got no error for a file without pages")
+	}
+}
+
+func TestInjectIntoAnchor(t *testing.T) {
+	const geometry = `<mxGeometry x="100" y="100" width="480" height="120" as="geometry"/>`
+	for _, test := range []struct {
+		name   string
+		anchor string
+		kept   bool
+	}{
+		{"label", `<mxCell id="anchor" value="{{content}}" vertex="1" parent="1">` + geometry + `</mxCell>`, false},
+		{"tag", `<UserObject id="anchor" label="" tags="content"><mxCell vertex="1" parent="1">` + geometry + `</mxCell></UserObject>`, false},
+		{"referred to", `<mxCell id="anchor" value="{{content}}" vertex="1" parent="1">` + geometry + `</mxCell>
+        <mxCell id="e" edge="1" source="anchor" target="a" parent="1"><mxGeometry relative="1" as="geometry"/></mxCell>`, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			template := strings.Replace(testTemplate, `        <mxCell id="2"`, "        "+test.anchor+"\n"+`        <mxCell id="2"`, 1)
+			g := NewGraph()
+			for _, c := range []*Cell{NewShape("b", "1"), NewShape("c", "1")} {
+				c.Geometry.Y = 0
+				g.Add(c)
+			}
+			g.cell("b").Geometry.X = 0
+			g.cell("c").Geometry.X = 120
+
+			out := inject(t, template, "Content", &g)
+			if got := strings.Contains(out, `id="anchor"`); got != test.kept {
+				t.Errorf("got anchor kept %v, want %v", got, test.kept)
+			}
+			m := injectedModel(t, out)
+			// The 240x60 cells are doubled to fill the height of the
+			// anchor and centered across its width.
+			for _, want := range []struct {
+				id   string
+				x, y int
+			}{
+				{"b", 100, 100},
+				{"c", 340, 100},
+			} {
+				c := m.cell(want.id)
+				w, h := c.Geometry.size()
+				if c.Geometry.X != want.x || c.Geometry.Y != want.y || w != 240 || h != 120 {
+					t.Errorf("got %s at %d,%d %dx%d, want %d,%d 240x120", want.id, c.Geometry.X, c.Geometry.Y, w, h, want.x, want.y)
+				}
+				if got := c.Style.Attributes["fontSize"]; got != "24" {
+					t.Errorf("got %s fontSize %q, want %q", want.id, got, "24")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// istioService is a destination host with the subsets defined by
+// DestinationRules or used by routes.
+type istioService struct {
+	host    string
+	subsets []string
+	labels  map[string]string
+}
+
+// istioRoute is a route of a VirtualService to a destination.
+type istioRoute struct {
+	from, host, subset string
+	label              string
+	weight             int
+	mirror             bool
+}
+
+// FromIstio returns a traffic routing diagram of Istio Gateway,
+// VirtualService and DestinationRule resources. r holds the resources
+// in YAML, as in manifests, where documents are separated by "---", or
+// in JSON, as printed by kubectl get -o json: a stream of objects.
+// Either may hold Lists of objects; other kinds are skipped.
+//
+// Gateways and the mesh are connected to the VirtualServices bound to
+// them. Every route of a VirtualService becomes an edge to its
+// destination labeled with the match conditions and the weight; the
+// stroke gets wider with the weight and mirrored traffic is dashed.
+// Destinations with subsets are drawn as containers holding a box per
+// subset.
+func FromIstio(r io.Reader) (*GraphModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("graw: FromIstio: %v", err)
+	}
+	var docs []interface{}
+	if s := bytes.TrimSpace(data); bytes.HasPrefix(s, []byte("{")) {
+		// decodeYAML reads a single JSON value, kubectl may print
+		// several.
+		d := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var doc interface{}
+			if err := d.Decode(&doc); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("graw: FromIstio: %v", err)
+			}
+			docs = append(docs, doc)
+		}
+	} else if docs, err = decodeYAML(data); err != nil {
+		return nil, fmt.Errorf("graw: FromIstio: %v", err)
+	}
+	var objects []map[string]interface{}
+	for _, doc := range docs {
+		obj := jsonObject(doc)
+		if obj == nil {
+			continue
+		}
+		if items, ok := obj["items"].([]interface{}); ok {
+			for _, item := range items {
+				if m := jsonObject(item); m != nil {
+					objects = append(objects, m)
+				}
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	var gateways []string
+	gatewayLabels := make(map[string]string)
+	vsLabels := make(map[string]string)
+	var vsOrder []string
+	dests := make(map[string]*istioService)
+	var destOrder []string
+	var binds [][2]string
+	var routes []istioRoute
+
+	dest := func(host, subset string) {
+		s, ok := dests[host]
+		if !ok {
+			s = &istioService{host: host, labels: make(map[string]string)}
+			dests[host] = s
+			destOrder = append(destOrder, host)
+		}
+		if subset == "" {
+			return
+		}
+		for _, name := range s.subsets {
+			if name == subset {
+				return
+			}
+		}
+		s.subsets = append(s.subsets, subset)
+	}
+
+	for _, obj := range objects {
+		kind := jsonString(obj, "kind")
+		if !strings.HasPrefix(jsonString(obj, "apiVersion"), "networking.istio.io/") {
+			continue
+		}
+		meta := jsonObject(obj["metadata"])
+		name := jsonString(meta, "name")
+		ns := jsonString(meta, "namespace")
+		spec := jsonObject(obj["spec"])
+
+		switch kind {
+		case "Gateway":
+			var lines []string
+			for _, s := range jsonArray(spec["servers"]) {
+				server := jsonObject(s)
+				port := jsonObject(server["port"])
+				line := jsonString(port, "protocol") + " " + jsonNumber(port["number"])
+				if hosts := jsonStrings(server["hosts"]); len(hosts) > 0 {
+					line += " " + strings.Join(hosts, ", ")
+				}
+				lines = append(lines, strings.TrimSpace(line))
+			}
+			if _, ok := gatewayLabels[name]; !ok {
+				gateways = append(gateways, name)
+			}
+			gatewayLabels[name] = htmlLines(append([]string{name}, lines...))
+
+		case "VirtualService":
+			hosts := jsonStrings(spec["hosts"])
+			vsOrder = append(vsOrder, name)
+			vsLabels[name] = htmlLines(append([]string{name}, hosts...))
+
+			bound := jsonStrings(spec["gateways"])
+			if len(bound) == 0 {
+				bound = []string{"mesh"}
+			}
+			for _, gw := range bound {
+				gw = gw[strings.LastIndex(gw, "/")+1:]
+				if _, ok := gatewayLabels[gw]; !ok {
+					gateways = append(gateways, gw)
+					gatewayLabels[gw] = htmlLines([]string{gw})
+				}
+				binds = append(binds, [2]string{gw, name})
+			}
+
+			for _, kind := range []string{"http", "tls", "tcp"} {
+				for _, rt := range jsonArray(spec[kind]) {
+					route := jsonObject(rt)
+					label := istioMatch(route)
+					targets := jsonArray(route["route"])
+					for _, t := range targets {
+						target := jsonObject(t)
+						d := jsonObject(target["destination"])
+						host := istioHost(jsonString(d, "host"), ns)
+						weight := 100
+						if w, ok := target["weight"].(float64); ok {
+							weight = int(w)
+						} else if len(targets) > 1 {
+							weight = 100 / len(targets)
+						}
+						dest(host, jsonString(d, "subset"))
+						routes = append(routes, istioRoute{
+							from: name, host: host, subset: jsonString(d, "subset"),
+							label: label, weight: weight,
+						})
+					}
+					if m := jsonObject(route["mirror"]); m != nil {
+						host := istioHost(jsonString(m, "host"), ns)
+						dest(host, jsonString(m, "subset"))
+						routes = append(routes, istioRoute{
+							from: name, host: host, subset: jsonString(m, "subset"),
+							label: "mirror", mirror: true,
+						})
+					}
+				}
+			}
+
+		case "DestinationRule":
+			host := istioHost(jsonString(spec, "host"), ns)
+			dest(host, "")
+			for _, s := range jsonArray(spec["subsets"]) {
+				subset := jsonObject(s)
+				sub := jsonString(subset, "name")
+				dest(host, sub)
+				labels := jsonObject(subset["labels"])
+				var lines []string
+				for _, k := range jsonKeys(labels) {
+					v, _ := labels[k].(string)
+					lines = append(lines, k+"="+v)
+				}
+				dests[host].labels[sub] = htmlLines(append([]string{sub}, lines...))
+			}
+		}
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	var gwCells, vsCells, svcCells, children, edges []*Cell
+	ids := make(map[string]string)
+
+	for _, gw := range gateways {
+		c := NewShape(taken.unique("gateway-"+gw), rootCellID)
+		c.Value = gatewayLabels[gw]
+		shape := "hexagon"
+		if gw == "mesh" {
+			shape = "ellipse"
+		}
+		c.Style = Style{Attributes: shapeStyle(shape)}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		ids["gateway\x00"+gw] = c.ID
+		gwCells = append(gwCells, c)
+	}
+	for _, vs := range vsOrder {
+		c := NewShape(taken.unique("vs-"+vs), rootCellID)
+		c.Value = vsLabels[vs]
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		ids["vs\x00"+vs] = c.ID
+		vsCells = append(vsCells, c)
+	}
+	for _, host := range destOrder {
+		s := dests[host]
+		if len(s.subsets) == 0 {
+			c := NewShape(taken.unique("service-"+host), rootCellID)
+			c.Value = host
+			c.Style = Style{Attributes: shapeStyle("")}
+			SizeToFit(c, nil, defaultWidth, defaultHeight)
+			ids["service\x00"+host] = c.ID
+			svcCells = append(svcCells, c)
+			continue
+		}
+
+		container := newContainer(taken.unique("service-"+host), rootCellID, host)
+		ids["service\x00"+host] = container.ID
+		var subsets []*Cell
+		for _, sub := range s.subsets {
+			c := NewShape(taken.unique(container.ID+"-"+sub), container.ID)
+			c.Value = s.labels[sub]
+			if c.Value == "" {
+				c.Value = htmlLines([]string{sub})
+			}
+			c.Style = Style{Attributes: shapeStyle("rounded")}
+			SizeToFit(c, nil, defaultWidth, defaultHeight)
+			ids["subset\x00"+host+"\x00"+sub] = c.ID
+			subsets = append(subsets, c)
+		}
+		w, h := layoutColumns([][]*Cell{subsets}, groupPadding, groupHeader+groupPadding, groupPadding)
+		container.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+		svcCells = append(svcCells, container)
+		children = append(children, subsets...)
+	}
+
+	for _, b := range binds {
+		from, to := ids["gateway\x00"+b[0]], ids["vs\x00"+b[1]]
+		edges = append(edges, newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to))
+	}
+	for _, r := range routes {
+		from := ids["vs\x00"+r.from]
+		to := ids["service\x00"+r.host]
+		if r.subset != "" {
+			to = ids["subset\x00"+r.host+"\x00"+r.subset]
+		}
+		e := newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to)
+		e.Value = r.label
+		switch {
+		case r.mirror:
+			e.Style.set("dashed", "1")
+		case r.weight < 100:
+			e.Value = strings.TrimSpace(e.Value + " " + strconv.Itoa(r.weight) + "%")
+			fallthrough
+		default:
+			e.Style.set("strokeWidth", strconv.Itoa(1+r.weight*4/100))
+		}
+		edges = append(edges, e)
+	}
+
+	layoutColumns([][]*Cell{gwCells, vsCells, svcCells}, 10, 10, 120)
+	for _, column := range [][]*Cell{gwCells, vsCells, svcCells, children, edges} {
+		for _, c := range column {
+			g.Add(c)
+		}
+	}
+	return &g, nil
+}
+
+// istioMatch describes the match conditions of a route, or returns
+// its name when it has one. Routes matching all traffic get no label.
+func istioMatch(route map[string]interface{}) string {
+	if name := jsonString(route, "name"); name != "" {
+		return name
+	}
+	var conds []string
+	for _, m := range jsonArray(route["match"]) {
+		match := jsonObject(m)
+		var parts []string
+		for _, key := range []string{"uri", "authority", "scheme", "method"} {
+			sm := jsonObject(match[key])
+			for _, op := range jsonKeys(sm) {
+				v, _ := sm[op].(string)
+				parts = append(parts, key+" "+op+" "+v)
+			}
+		}
+		headers := jsonObject(match["headers"])
+		for _, h := range jsonKeys(headers) {
+			sm := jsonObject(headers[h])
+			for _, op := range jsonKeys(sm) {
+				v, _ := sm[op].(string)
+				parts = append(parts, h+" "+op+" "+v)
+			}
+		}
+		if port := jsonNumber(match["port"]); port != "" {
+			parts = append(parts, "port "+port)
+		}
+		if sni := jsonStrings(match["sniHosts"]); len(sni) > 0 {
+			parts = append(parts, "sni "+strings.Join(sni, ", "))
+		}
+		conds = append(conds, strings.Join(parts, " and "))
+	}
+	return strings.Join(conds, " or ")
+}
+
+// istioHost shortens the cluster local host names of namespace ns, so
+// that "reviews" and "reviews.default.svc.cluster.local" are drawn as
+// the same destination.
+func istioHost(host, ns string) string {
+	host = strings.TrimSuffix(host, ".svc.cluster.local")
+	host = strings.TrimSuffix(host, ".svc")
+	if ns == "" {
+		ns = "default"
+	}
+	return strings.TrimSuffix(host, "."+ns)
+}
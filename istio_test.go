@@ -0,0 +1,110 @@
+package graw
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const istioJSON = `{"apiVersion":"v1","kind":"List","items":[
+{"apiVersion":"networking.istio.io/v1beta1","kind":"VirtualService","metadata":{"name":"reviews","namespace":"default"},
+ "spec":{"hosts":["reviews"],"gateways":["public"],"http":[
+  {"route":[{"destination":{"host":"reviews","subset":"v1"},"weight":80},{"destination":{"host":"reviews","subset":"v2"},"weight":20}]}]}}]}
+{"apiVersion":"networking.istio.io/v1beta1","kind":"Gateway","metadata":{"name":"public"},"spec":{"servers":[{"port":{"number":443,"protocol":"HTTPS"},"hosts":["*.example.com"]}]}}
+{"apiVersion":"apps/v1","kind":"Deployment"}`
+
+const istioYAML = `apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: reviews
+  namespace: default
+spec:
+  hosts:
+  - reviews
+  gateways:
+  - public
+  http:
+  - route:
+    - destination:
+        host: reviews
+        subset: v1
+      weight: 80
+    - destination:
+        host: reviews
+        subset: v2
+      weight: 20
+---
+apiVersion: networking.istio.io/v1beta1
+kind: Gateway
+metadata:
+  name: public
+spec:
+  servers:
+  - port:
+      number: 443
+      protocol: HTTPS
+    hosts:
+    - "*.example.com"
+---
+apiVersion: apps/v1
+kind: Deployment
+`
+
+func TestFromIstio(t *testing.T) {
+	want, err := FromIstio(strings.NewReader(istioJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"gateway-public", "vs-reviews", "service-reviews-v1", "vs-reviews-service-reviews-v2"} {
+		if want.FindByID(id) == nil {
+			t.Errorf("got no cell %q", id)
+		}
+	}
+	if iss := ValidateStyles(want); len(iss) > 0 {
+		t.Errorf("got style issues %v", iss)
+	}
+	wantXML, _ := xml.Marshal(want)
+	for _, test := range []struct {
+		name, in string
+	}{
+		{"yaml", istioYAML},
+		{"yaml list", "apiVersion: v1\nkind: List\nitems:\n" + indentLines(strings.Replace(istioYAML, "---\n", "", -1))},
+		{"json with spaces", "\n  " + istioJSON + "\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := FromIstio(strings.NewReader(test.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := xml.Marshal(g); string(got) != string(wantXML) {
+				t.Errorf("got\n%s\nwant\n%s", got, wantXML)
+			}
+		})
+	}
+}
+
+// indentLines turns the YAML documents in into the items of a List.
+func indentLines(in string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(in, "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "apiVersion:"):
+			b.WriteString("- " + line)
+		default:
+			b.WriteString("  " + line)
+		}
+	}
+	return b.String()
+}
+
+func TestFromIstioErrors(t *testing.T) {
+	for _, in := range []string{
+		`{"kind":"Gateway"`,
+		"kind: [Gateway\n",
+	} {
+		if _, err := FromIstio(strings.NewReader(in)); err == nil || !strings.HasPrefix(err.Error(), "graw: FromIstio: ") {
+			t.Errorf("FromIstio(%q): got error %v, want a graw: FromIstio error", in, err)
+		}
+	}
+}
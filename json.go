@@ -0,0 +1,74 @@
+package graw
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonObject returns v as a JSON object, or nil.
+func jsonObject(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// jsonString returns the string member key of m.
+func jsonString(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// jsonResolve follows local references such as
+// {"$ref": "#/components/messages/UserSignedUp"} within root.
+func jsonResolve(root map[string]interface{}, v interface{}) interface{} {
+	for depth := 0; depth < 32; depth++ {
+		ref := jsonString(jsonObject(v), "$ref")
+		if !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		var cur interface{} = root
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			cur = jsonObject(cur)[part]
+		}
+		v = cur
+	}
+	return v
+}
+
+// jsonKeys returns the member names of m in sorted order.
+func jsonKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonArray returns v as a JSON array, or nil.
+func jsonArray(v interface{}) []interface{} {
+	a, _ := v.([]interface{})
+	return a
+}
+
+// jsonStrings returns the strings of the JSON array v.
+func jsonStrings(v interface{}) []string {
+	var s []string
+	for _, e := range jsonArray(v) {
+		if str, ok := e.(string); ok {
+			s = append(s, str)
+		}
+	}
+	return s
+}
+
+// jsonNumber formats the JSON number v, or returns "" when v is not
+// a number.
+func jsonNumber(v interface{}) string {
+	f, ok := v.(float64)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,149 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Dimensions of kanban boards.
+const (
+	kanbanColumnWidth = 200
+	kanbanCardHeight  = 50
+	kanbanBadgeSize   = 24
+)
+
+// KanbanCard is a card of a kanban board.
+type KanbanCard struct {
+	ID    string
+	Title string
+	// Column is the column the card is in, e.g. its status.
+	Column   string
+	Assignee string
+}
+
+// NewKanban returns a snapshot of a kanban board: a container per
+// column, titled with the number of cards it holds, with the cards
+// stacked in it in the order given. columns lists the columns from
+// left to right; columns only named by cards follow them. The
+// assignee of a card is shown as a badge of their initials, colored
+// alike for all the cards of the assignee.
+func NewKanban(columns []string, cards []KanbanCard) (*GraphModel, error) {
+	var order []string
+	byColumn := make(map[string][]KanbanCard)
+	known := make(map[string]bool)
+	for _, c := range columns {
+		if known[c] {
+			return nil, fmt.Errorf("graw: NewKanban: duplicate column %q", c)
+		}
+		known[c] = true
+		order = append(order, c)
+	}
+	for _, card := range cards {
+		if !known[card.Column] {
+			known[card.Column] = true
+			order = append(order, card.Column)
+		}
+		byColumn[card.Column] = append(byColumn[card.Column], card)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("graw: NewKanban: no columns")
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	colors := make(map[string]string)
+	var cells []*Cell
+	for i, column := range order {
+		container := newContainer(taken.unique("column-"+column), rootCellID, html.EscapeString(column)+" ("+strconv.Itoa(len(byColumn[column]))+")")
+		container.Style.set("collapsible", "0")
+		container.Style.set("fillColor", "#f5f5f5")
+		container.Geometry.X, container.Geometry.Y = 10+i*(kanbanColumnWidth+groupPadding), 10
+		cells = append(cells, container)
+
+		y := groupHeader + groupPadding/2
+		for _, card := range byColumn[column] {
+			base := card.ID
+			if base == "" {
+				base = card.Title
+			}
+			c := NewShape(taken.unique("card-"+base), container.ID)
+			c.Value = html.EscapeString(card.Title)
+			if card.ID != "" {
+				c.Value = "<b>" + html.EscapeString(card.ID) + "</b><br>" + c.Value
+			}
+			c.Style = Style{Attributes: shapeStyle("rounded")}
+			c.Style.set("fillColor", "#ffffff")
+			c.Style.set("align", "left")
+			c.Style.set("verticalAlign", "top")
+			c.Style.set("spacingLeft", "6")
+			c.Style.setFloat("spacingRight", kanbanBadgeSize+6)
+			w := kanbanColumnWidth - groupPadding
+			h := wrappedHeight(c, w-kanbanBadgeSize-16, kanbanCardHeight)
+			c.Geometry.X, c.Geometry.Y = groupPadding/2, y
+			c.Geometry.setSize(w, h)
+			cells = append(cells, c)
+
+			if card.Assignee != "" {
+				color, ok := colors[card.Assignee]
+				if !ok {
+					color = ganttColors[len(colors)%len(ganttColors)]
+					colors[card.Assignee] = color
+				}
+				b := NewShape(taken.unique(c.ID+"-assignee"), container.ID)
+				b.Value = initials(card.Assignee)
+				b.Style = Style{Attributes: shapeStyle("ellipse")}
+				b.Style.set("fillColor", color)
+				b.Style.set("fontSize", "10")
+				b.Style.set("fontStyle", "1")
+				b.Geometry.X = c.Geometry.X + w - kanbanBadgeSize - 4
+				b.Geometry.Y = y + h - kanbanBadgeSize - 4
+				b.Geometry.setSize(kanbanBadgeSize, kanbanBadgeSize)
+				b.Data = map[string]string{"tooltip": card.Assignee}
+				cells = append(cells, b)
+			}
+			y += h + groupPadding/2
+		}
+		container.Geometry.setSize(kanbanColumnWidth, maxInt(y, groupHeader+kanbanCardHeight+groupPadding))
+	}
+	for _, c := range cells {
+		g.Add(c)
+	}
+	return &g, nil
+}
+
+// wrappedHeight returns the height the label of c takes when wrapped
+// to lines of width pixels, at least minHeight.
+func wrappedHeight(c *Cell, width, minHeight int) int {
+	f := fontOf(c.Style)
+	lines := 0
+	for _, l := range strings.Split(labelText(c), "\n") {
+		w, _ := DefaultMeasurer.Measure(l, f)
+		lines += maxInt(1, int(math.Ceil(w/float64(width))))
+	}
+	return maxInt(int(float64(lines)*f.Size*lineHeight)+16, minHeight)
+}
+
+// initials returns the upper case initials of the first and last word
+// of name, or its first two letters when it is a single word such as
+// a user name.
+func initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	first := []rune(words[0])
+	if len(words) == 1 {
+		if len(first) > 2 {
+			first = first[:2]
+		}
+		return strings.ToUpper(string(first))
+	}
+	last := []rune(words[len(words)-1])
+	return strings.ToUpper(string(first[0]) + string(last[0]))
+}
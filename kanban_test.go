@@ -0,0 +1,92 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewKanban(t *testing.T) {
+	g, err := NewKanban([]string{"To do", "Doing", "Done"}, []KanbanCard{
+		{ID: "A-1", Title: "Write the parser for the thing that is very long indeed", Column: "Doing", Assignee: "Ada Lovelace"},
+		{Title: "x & y", Column: "Blocked", Assignee: "jdoe"},
+		{ID: "A-2", Title: "y", Column: "Doing", Assignee: "Ada Lovelace"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var columns []string
+	for _, c := range g.Root {
+		if c.ParentID == rootCellID {
+			columns = append(columns, c.Value)
+		}
+	}
+	want := []string{"To do (0)", "Doing (2)", "Done (0)", "Blocked (1)"}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("got columns %q, want %q", columns, want)
+	}
+
+	tests := []struct {
+		id, parent, label string
+	}{
+		{"card-A-1", "column-Doing", "<b>A-1</b><br>Write the parser for the thing that is very long indeed"},
+		{"card-A-1-assignee", "column-Doing", "AL"},
+		{"card-A-2", "column-Doing", "<b>A-2</b><br>y"},
+		{"card-A-2-assignee", "column-Doing", "AL"},
+		{"card-x & y", "column-Blocked", "x &amp; y"},
+		{"card-x & y-assignee", "column-Blocked", "JD"},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Errorf("%s: missing", tt.id)
+			continue
+		}
+		if c.ParentID != tt.parent || c.Value != tt.label {
+			t.Errorf("%s: got parent %q, label %q, want %q, %q", tt.id, c.ParentID, c.Value, tt.parent, tt.label)
+		}
+	}
+
+	// Cards are stacked, long titles making them taller, and the
+	// badges of an assignee share a color.
+	a1, a2 := g.FindByID("card-A-1"), g.FindByID("card-A-2")
+	if _, h := a1.Geometry.size(); h <= kanbanCardHeight || a2.Geometry.Y < a1.Geometry.Y+h {
+		t.Errorf("got cards at y=%d, height %d and y=%d", a1.Geometry.Y, h, a2.Geometry.Y)
+	}
+	b1, b2, bx := g.FindByID("card-A-1-assignee"), g.FindByID("card-A-2-assignee"), g.FindByID("card-x & y-assignee")
+	if b1.Style.Attributes["fillColor"] != b2.Style.Attributes["fillColor"] || b1.Style.Attributes["fillColor"] == bx.Style.Attributes["fillColor"] {
+		t.Errorf("got badge colors %q, %q, %q", b1.Style.Attributes["fillColor"], b2.Style.Attributes["fillColor"], bx.Style.Attributes["fillColor"])
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewKanbanErrors(t *testing.T) {
+	if _, err := NewKanban(nil, nil); err == nil {
+		t.Error("NewKanban without columns: got no error")
+	}
+	if _, err := NewKanban([]string{"a", "a"}, nil); err == nil {
+		t.Error("NewKanban with a duplicate column: got no error")
+	}
+}
+
+func TestInitials(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"Ada Lovelace", "AL"},
+		{"Ada King Lovelace", "AL"},
+		{"jdoe", "JD"},
+		{"j", "J"},
+		{"john.doe@example.com", "JC"},
+		{"émile zola", "ÉZ"},
+		{"", ""},
+		{"--", ""},
+	}
+	for _, tt := range tests {
+		if got := initials(tt.name); got != tt.want {
+			t.Errorf("initials(%q): got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
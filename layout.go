@@ -0,0 +1,240 @@
+package graw
+
+import (
+	"math"
+	"strconv"
+)
+
+// Size used for shapes whose geometry carries no width or height.
+const (
+	defaultWidth  = 120
+	defaultHeight = 60
+)
+
+// size returns the width and height of geo, rounded to whole pixels.
+// Missing or malformed values fall back to defaultWidth and
+// defaultHeight.
+func (geo *Geometry) size() (int, int) {
+	return sizeValue(geo.Width, defaultWidth), sizeValue(geo.Height, defaultHeight)
+}
+
+// sizeValue parses the width or height v, which draw.io may write
+// with decimals, e.g. "80.5".
+func sizeValue(v string, def int) int {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || math.Round(f) <= 0 {
+		return def
+	}
+	return int(math.Round(f))
+}
+
+// setSize sets the width and height of geo.
+func (geo *Geometry) setSize(w, h int) {
+	geo.Width = strconv.Itoa(w)
+	geo.Height = strconv.Itoa(h)
+}
+
+// layoutGrid places cells row by row in a grid of roughly square
+// shape, starting at (x, y) with gap pixels between neighbours. Each
+// column is as wide and each row as tall as its largest cell. It
+// returns the width and height of the area used.
+func layoutGrid(cells []*Cell, x, y, gap int) (int, int) {
+	if len(cells) == 0 {
+		return 0, 0
+	}
+	cols := int(math.Ceil(math.Sqrt(float64(len(cells)))))
+	rows := (len(cells) + cols - 1) / cols
+
+	widths := make([]int, cols)
+	heights := make([]int, rows)
+	for i, c := range cells {
+		if c.Geometry == nil {
+			c.Geometry = newGeometry()
+		}
+		w, h := c.Geometry.size()
+		if c.Geometry.Width == "" || c.Geometry.Height == "" {
+			c.Geometry.setSize(w, h)
+		}
+		if w > widths[i%cols] {
+			widths[i%cols] = w
+		}
+		if h > heights[i/cols] {
+			heights[i/cols] = h
+		}
+	}
+
+	cy := y
+	for r := 0; r < rows; r++ {
+		cx := x
+		for col := 0; col < cols && r*cols+col < len(cells); col++ {
+			geo := cells[r*cols+col].Geometry
+			geo.X = cx
+			geo.Y = cy
+			cx += widths[col] + gap
+		}
+		cy += heights[r] + gap
+	}
+
+	total := func(v []int) int {
+		sum := gap * (len(v) - 1)
+		for _, n := range v {
+			sum += n
+		}
+		return sum
+	}
+	return total(widths), total(heights)
+}
+
+// layoutColumns places each column of cells top to bottom, the
+// columns side by side from left to right, starting at (x, y) with
+// gap pixels between neighbours. Each column is as wide as its widest
+// cell. It returns the width and height of the area used.
+func layoutColumns(columns [][]*Cell, x, y, gap int) (int, int) {
+	cx, height := x, 0
+	for _, col := range columns {
+		if len(col) == 0 {
+			continue
+		}
+		cy, width := y, 0
+		for _, c := range col {
+			if c.Geometry == nil {
+				c.Geometry = newGeometry()
+			}
+			w, h := c.Geometry.size()
+			if c.Geometry.Width == "" || c.Geometry.Height == "" {
+				c.Geometry.setSize(w, h)
+			}
+			c.Geometry.X = cx
+			c.Geometry.Y = cy
+			cy += h + gap
+			if w > width {
+				width = w
+			}
+		}
+		if cy-gap-y > height {
+			height = cy - gap - y
+		}
+		cx += width + gap
+	}
+	if cx == x {
+		return 0, 0
+	}
+	return cx - gap - x, height
+}
+
+// absolutePosition returns the position of c relative to its layer,
+// adding up the offsets of the containers it is nested in.
+func (g *GraphModel) absolutePosition(c *Cell) (int, int) {
+	return offsetOf(c, g.cell)
+}
+
+// offsetOf is absolutePosition using lookup to find the parent of a
+// cell.
+func offsetOf(c *Cell, lookup func(id string) *Cell) (int, int) {
+	var x, y int
+	for depth := 0; c != nil && c.Geometry != nil && depth < 64; depth++ {
+		x += c.Geometry.X
+		y += c.Geometry.Y
+		c = lookup(c.ParentID)
+	}
+	return x, y
+}
+
+// rightOfDiagram returns where panels such as legends go right of the
+// diagram: the layer of its topmost vertex, the right edge of the
+// vertices of the layers and the top of the diagram, at least 10.
+func (g *GraphModel) rightOfDiagram() (layer string, right, top int) {
+	layer, top = rootCellID, -1
+	for i := range g.Root {
+		c := g.Root[i]
+		if !c.isVertex() || c.Geometry == nil {
+			continue
+		}
+		if p := g.cell(c.ParentID); p == nil || !p.isLayer() {
+			continue
+		}
+		w, _ := c.Geometry.size()
+		right = maxInt(right, c.Geometry.X+w)
+		if top < 0 || c.Geometry.Y < top {
+			top, layer = c.Geometry.Y, c.ParentID
+		}
+	}
+	return layer, right, maxInt(top, 10)
+}
+
+// layoutTree places a forest of cells top to bottom, starting at
+// (x, y): every cell is centered above its children, the trees side by
+// side with hgap pixels between neighbouring subtrees and vgap pixels
+// between levels. Each level is as tall as its tallest cell. It
+// returns the width and height of the area used.
+func layoutTree(roots []*Cell, children map[*Cell][]*Cell, x, y, hgap, vgap int) (int, int) {
+	var heights []int
+	var measure func(c *Cell, depth int)
+	measure = func(c *Cell, depth int) {
+		if c.Geometry == nil {
+			c.Geometry = newGeometry()
+		}
+		w, h := c.Geometry.size()
+		if c.Geometry.Width == "" || c.Geometry.Height == "" {
+			c.Geometry.setSize(w, h)
+		}
+		if depth == len(heights) {
+			heights = append(heights, 0)
+		}
+		if h > heights[depth] {
+			heights[depth] = h
+		}
+		for _, child := range children[c] {
+			measure(child, depth+1)
+		}
+	}
+	for _, r := range roots {
+		measure(r, 0)
+	}
+	levels := make([]int, len(heights))
+	for i := 1; i < len(heights); i++ {
+		levels[i] = levels[i-1] + heights[i-1] + vgap
+	}
+
+	// place lays out the subtree of c from left and returns its width.
+	var place func(c *Cell, left, depth int) int
+	place = func(c *Cell, left, depth int) int {
+		w, _ := c.Geometry.size()
+		span := 0
+		for i, child := range children[c] {
+			if i > 0 {
+				span += hgap
+			}
+			span += place(child, left+span, depth+1)
+		}
+		width := maxInt(w, span)
+		if span < w {
+			// children narrower than their parent: center them
+			for _, child := range children[c] {
+				shiftTree(child, children, (w-span)/2)
+			}
+		}
+		c.Geometry.X = left + (width-w)/2
+		c.Geometry.Y = y + levels[depth]
+		return width
+	}
+	cx := x
+	for i, r := range roots {
+		if i > 0 {
+			cx += hgap
+		}
+		cx += place(r, cx, 0)
+	}
+	if len(roots) == 0 {
+		return 0, 0
+	}
+	return cx - x, levels[len(levels)-1] + heights[len(heights)-1]
+}
+
+// shiftTree moves the subtree of c right by dx pixels.
+func shiftTree(c *Cell, children map[*Cell][]*Cell, dx int) {
+	c.Geometry.X += dx
+	for _, child := range children[c] {
+		shiftTree(child, children, dx)
+	}
+}
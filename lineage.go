@@ -0,0 +1,243 @@
+package graw
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// lineageNode is a dataset or a job of a lineage graph.
+type lineageNode struct {
+	key       string
+	dataset   bool
+	namespace string
+	name      string
+	state     string
+	fields    []string
+}
+
+// lineage is a lineage graph being read.
+type lineage struct {
+	nodes      []*lineageNode
+	byKey      map[string]*lineageNode
+	downstream map[string][]string
+}
+
+// lineageStateColors are the fill colors of jobs by the state of
+// their last run.
+var lineageStateColors = map[string]string{
+	"START":     "#dae8fc",
+	"RUNNING":   "#dae8fc",
+	"COMPLETE":  "#d5e8d4",
+	"COMPLETED": "#d5e8d4",
+	"FAIL":      "#f8cecc",
+	"FAILED":    "#f8cecc",
+	"ABORT":     "#f5f5f5",
+	"ABORTED":   "#f5f5f5",
+}
+
+// FromLineage returns a data lineage diagram read from OpenLineage run
+// events, as a stream or an array of JSON events or as the {"events":
+// [...]} Marquez returns for them, or from a Marquez lineage graph, as
+// GET /api/v1/lineage returns it.
+//
+// Datasets become cylinders and jobs boxes, labeled with their name
+// and namespace and laid out in columns from left to right so that
+// data always flows to the right, apart from cycles. Jobs are colored
+// by the state of their last run; the schema fields of datasets are
+// shown in their tooltip.
+func FromLineage(r io.Reader) (*GraphModel, error) {
+	l := &lineage{byKey: make(map[string]*lineageNode), downstream: make(map[string][]string)}
+	dec := json.NewDecoder(r)
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("graw: FromLineage: %v", err)
+		}
+		obj := jsonObject(doc)
+		switch {
+		case obj["graph"] != nil:
+			l.marquezGraph(jsonArray(obj["graph"]))
+		case obj["events"] != nil:
+			for _, e := range jsonArray(obj["events"]) {
+				l.event(jsonObject(e))
+			}
+		case obj != nil:
+			l.event(obj)
+		default:
+			for _, e := range jsonArray(doc) {
+				l.event(jsonObject(e))
+			}
+		}
+	}
+	if len(l.nodes) == 0 {
+		return nil, fmt.Errorf("graw: FromLineage: no datasets or jobs")
+	}
+	return l.diagram(), nil
+}
+
+// node returns the node of the dataset or job namespace/name, adding
+// it when new.
+func (l *lineage) node(dataset bool, namespace, name string) *lineageNode {
+	key := "job:" + namespace + ":" + name
+	if dataset {
+		key = "dataset:" + namespace + ":" + name
+	}
+	n, ok := l.byKey[key]
+	if !ok {
+		n = &lineageNode{key: key, dataset: dataset, namespace: namespace, name: name}
+		l.byKey[key] = n
+		l.nodes = append(l.nodes, n)
+	}
+	return n
+}
+
+// link records that data flows from the node from to the node to.
+func (l *lineage) link(from, to *lineageNode) {
+	if !containsString(l.downstream[from.key], to.key) {
+		l.downstream[from.key] = append(l.downstream[from.key], to.key)
+	}
+}
+
+// event adds the job of the OpenLineage run event e and the datasets it
+// reads and writes.
+func (l *lineage) event(e map[string]interface{}) {
+	job := jsonObject(e["job"])
+	if jsonString(job, "name") == "" {
+		return
+	}
+	j := l.node(false, jsonString(job, "namespace"), jsonString(job, "name"))
+	if state := jsonString(e, "eventType"); state != "" && state != "OTHER" {
+		j.state = state
+	}
+	for _, side := range []string{"inputs", "outputs"} {
+		for _, v := range jsonArray(e[side]) {
+			ds := jsonObject(v)
+			if jsonString(ds, "name") == "" {
+				continue
+			}
+			d := l.node(true, jsonString(ds, "namespace"), jsonString(ds, "name"))
+			if fields := lineageFields(jsonObject(jsonObject(ds["facets"])["schema"])); len(fields) > 0 {
+				d.fields = fields
+			}
+			if side == "inputs" {
+				l.link(d, j)
+			} else {
+				l.link(j, d)
+			}
+		}
+	}
+}
+
+// marquezGraph adds the nodes and edges of a Marquez lineage graph.
+func (l *lineage) marquezGraph(graph []interface{}) {
+	nodes := make(map[string]*lineageNode)
+	for _, v := range graph {
+		spec := jsonObject(v)
+		data := jsonObject(spec["data"])
+		namespace, name := jsonString(data, "namespace"), jsonString(data, "name")
+		if name == "" {
+			// ids are "dataset:namespace:name" or "job:namespace:name"
+			parts := strings.SplitN(jsonString(spec, "id"), ":", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			namespace, name = parts[1], parts[2]
+		}
+		n := l.node(jsonString(spec, "type") == "DATASET", namespace, name)
+		if run := jsonObject(data["latestRun"]); run != nil {
+			n.state = jsonString(run, "state")
+		}
+		if fields := lineageFields(data); len(fields) > 0 {
+			n.fields = fields
+		}
+		nodes[jsonString(spec, "id")] = n
+	}
+	for _, v := range graph {
+		for _, e := range jsonArray(jsonObject(v)["outEdges"]) {
+			edge := jsonObject(e)
+			from, ok1 := nodes[jsonString(edge, "origin")]
+			to, ok2 := nodes[jsonString(edge, "destination")]
+			if ok1 && ok2 {
+				l.link(from, to)
+			}
+		}
+	}
+}
+
+// lineageFields returns the names of the fields of a schema facet or
+// of a Marquez dataset.
+func lineageFields(schema map[string]interface{}) []string {
+	var fields []string
+	for _, f := range jsonArray(schema["fields"]) {
+		if name := jsonString(jsonObject(f), "name"); name != "" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// diagram returns the lineage diagram of l.
+func (l *lineage) diagram() *GraphModel {
+	keys := make([]string, len(l.nodes))
+	for i, n := range l.nodes {
+		keys[i] = n.key
+	}
+	_, _, column := dependencyColumns(keys, l.downstream)
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string, len(keys))
+	var columns [][]*Cell
+	for _, n := range l.nodes {
+		c := NewShape(taken.unique(n.name), rootCellID)
+		c.Value = "<b>" + html.EscapeString(n.name) + "</b>"
+		if n.namespace != "" {
+			c.Value += "<br><i>" + html.EscapeString(n.namespace) + "</i>"
+		}
+		var tooltip []string
+		if n.dataset {
+			c.Style = Style{Attributes: shapeStyle("cylinder")}
+			c.Style.set("fillColor", "#fff2cc")
+			if len(n.fields) > 0 {
+				tooltip = append(tooltip, "fields: "+strings.Join(n.fields, ", "))
+			}
+		} else {
+			c.Style = Style{Attributes: shapeStyle("rounded")}
+			c.Style.set("fillColor", "#f5f5f5")
+			if color, ok := lineageStateColors[strings.ToUpper(n.state)]; ok {
+				c.Style.set("fillColor", color)
+			}
+			if n.state != "" {
+				tooltip = append(tooltip, "last run: "+n.state)
+			}
+		}
+		if len(tooltip) > 0 {
+			c.Data = map[string]string{"tooltip": strings.Join(tooltip, "\n")}
+		}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		ids[n.key] = c.ID
+
+		col := column[n.key]
+		for len(columns) <= col {
+			columns = append(columns, nil)
+		}
+		columns[col] = append(columns[col], c)
+	}
+	layoutColumns(columns, 10, 10, 80)
+	for _, column := range columns {
+		for _, c := range column {
+			g.Add(c)
+		}
+	}
+	for _, n := range l.nodes {
+		for _, d := range l.downstream[n.key] {
+			g.Add(newEdgeCell(taken.unique(ids[n.key]+"-"+ids[d]), rootCellID, ids[n.key], ids[d]))
+		}
+	}
+	return &g
+}
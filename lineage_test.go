@@ -0,0 +1,134 @@
+package graw
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const lineageEvents = `{"eventType": "START", "job": {"namespace": "etl", "name": "load"},
+ "inputs": [{"namespace": "s3", "name": "raw"}],
+ "outputs": [{"namespace": "pg", "name": "orders", "facets": {"schema": {"fields": [{"name": "id"}, {"name": "total"}]}}}]}
+{"eventType": "COMPLETE", "job": {"namespace": "etl", "name": "load"},
+ "inputs": [{"namespace": "s3", "name": "raw"}], "outputs": [{"namespace": "pg", "name": "orders"}]}
+{"eventType": "FAIL", "job": {"namespace": "bi", "name": "report"},
+ "inputs": [{"namespace": "pg", "name": "orders"}, {"name": ""}]}
+{"eventType": "OTHER", "job": {"namespace": "bi", "name": "report"}}
+{"eventType": "START", "job": {}}
+`
+
+func TestFromLineage(t *testing.T) {
+	edges := []string{"load->orders", "orders->report", "raw->load"}
+	tests := []struct {
+		name     string
+		in       string
+		labels   map[string]string
+		colors   map[string]string
+		tooltips map[string]string
+	}{
+		{
+			name: "event stream",
+			in:   lineageEvents,
+			labels: map[string]string{
+				"load":   "<b>load</b><br><i>etl</i>",
+				"orders": "<b>orders</b><br><i>pg</i>",
+			},
+			colors:   map[string]string{"load": "#d5e8d4", "report": "#f8cecc", "raw": "#fff2cc"},
+			tooltips: map[string]string{"load": "last run: COMPLETE", "report": "last run: FAIL", "orders": "fields: id, total", "raw": ""},
+		},
+		{
+			name:   "event array",
+			in:     "[" + strings.Join(strings.Split(strings.TrimSpace(lineageEvents), "\n{"), ",\n{") + "]",
+			colors: map[string]string{"load": "#d5e8d4"},
+		},
+		{
+			name:   "marquez events",
+			in:     `{"events": [` + strings.Join(strings.Split(strings.TrimSpace(lineageEvents), "\n{"), ",\n{") + `], "totalCount": 5}`,
+			colors: map[string]string{"report": "#f8cecc"},
+		},
+		{
+			name: "marquez graph",
+			in: `{"graph": [
+  {"id": "dataset:s3:raw", "type": "DATASET", "data": {"namespace": "s3", "name": "raw"},
+   "outEdges": [{"origin": "dataset:s3:raw", "destination": "job:etl:load"}]},
+  {"id": "job:etl:load", "type": "JOB", "data": {"namespace": "etl", "name": "load", "latestRun": {"state": "COMPLETED"}},
+   "outEdges": [{"origin": "job:etl:load", "destination": "dataset:pg:orders"}]},
+  {"id": "dataset:pg:orders", "type": "DATASET", "data": {"fields": [{"name": "id", "type": "INTEGER"}]},
+   "outEdges": [{"origin": "dataset:pg:orders", "destination": "job:bi:report"}, {"origin": "dataset:pg:orders", "destination": "job:bi:gone"}]},
+  {"id": "job:bi:report", "type": "JOB", "data": {"namespace": "bi", "name": "report", "latestRun": {"state": "FAILED"}}}
+]}`,
+			labels:   map[string]string{"orders": "<b>orders</b><br><i>pg</i>"},
+			colors:   map[string]string{"load": "#d5e8d4", "report": "#f8cecc"},
+			tooltips: map[string]string{"load": "last run: COMPLETED", "orders": "fields: id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromLineage(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := edgeList(g)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, edges) {
+				t.Errorf("got edges %q, want %q", got, edges)
+			}
+			// data flows to the right
+			for _, e := range edges {
+				ids := strings.Split(e, "->")
+				if from, to := g.FindByID(ids[0]), g.FindByID(ids[1]); from.Geometry.X >= to.Geometry.X {
+					t.Errorf("%s: got x %d and %d", e, from.Geometry.X, to.Geometry.X)
+				}
+			}
+			for id, want := range tt.labels {
+				if got := g.FindByID(id).Value; got != want {
+					t.Errorf("%s: got label %q, want %q", id, got, want)
+				}
+			}
+			for id, want := range tt.colors {
+				if got := g.FindByID(id).Style.Attributes["fillColor"]; got != want {
+					t.Errorf("%s: got fillColor %q, want %q", id, got, want)
+				}
+			}
+			for id, want := range tt.tooltips {
+				if got := g.FindByID(id).Data["tooltip"]; got != want {
+					t.Errorf("%s: got tooltip %q, want %q", id, got, want)
+				}
+			}
+			if got, want := g.FindByID("orders").Style.Attributes["shape"], shapeStyle("cylinder")["shape"]; got != want {
+				t.Errorf("got dataset shape %q, want %q", got, want)
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestFromLineageNames(t *testing.T) {
+	// a dataset and a job may share a name
+	g, err := FromLineage(strings.NewReader(`{"job": {"name": "orders"}, "outputs": [{"name": "orders"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := edgeList(g), []string{"orders->orders-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %q, want %q", got, want)
+	}
+}
+
+func TestFromLineageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"json", `{"job": `},
+		{"empty", ``},
+		{"no jobs", `{"eventType": "START"}`},
+	}
+	for _, tt := range tests {
+		if _, err := FromLineage(strings.NewReader(tt.in)); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
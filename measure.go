@@ -0,0 +1,176 @@
+package graw
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Font values used when a style does not specify them; they match
+// the draw.io defaults.
+const (
+	defaultFontFamily = "Helvetica"
+	defaultFontSize   = 12
+	lineHeight        = 1.2
+)
+
+// Font describes the typeface of a label.
+type Font struct {
+	Family string
+	Size   float64
+	Bold   bool
+	Italic bool
+}
+
+// TextMeasurer measures the size of label text. It is used wherever
+// the library needs to know how much room a label takes, e.g. by
+// SizeToFit.
+type TextMeasurer interface {
+	// Measure returns the width and height of text set in font f.
+	// Lines are separated by "\n".
+	Measure(text string, f Font) (width, height float64)
+}
+
+// DefaultMeasurer is the TextMeasurer used when none is given. It is
+// an ApproxMeasurer, which needs no font files; build with the
+// freetype tag and use a FontMeasurer for exact results.
+var DefaultMeasurer TextMeasurer = ApproxMeasurer{}
+
+// ApproxMeasurer estimates text sizes from typical glyph widths of a
+// proportional sans-serif font, or of a monospaced font for families
+// such as Courier New. Wide characters such as CJK count as a full em.
+type ApproxMeasurer struct{}
+
+// Measure implements TextMeasurer.
+func (ApproxMeasurer) Measure(text string, f Font) (float64, float64) {
+	size := f.Size
+	if size <= 0 {
+		size = defaultFontSize
+	}
+
+	mono := isMonospace(f.Family)
+	lines := strings.Split(text, "\n")
+	var width float64
+	for _, line := range lines {
+		var w float64
+		for _, r := range line {
+			if mono && glyphWidth(r) < 1 {
+				w += 0.6
+				continue
+			}
+			w += glyphWidth(r)
+		}
+		if f.Bold {
+			w *= 1.1
+		}
+		if w > width {
+			width = w
+		}
+	}
+	return width * size, float64(len(lines)) * size * lineHeight
+}
+
+// glyphWidth returns the approximate advance of r in ems.
+func glyphWidth(r rune) float64 {
+	switch {
+	case strings.ContainsRune("iljI|!.,:;'`", r):
+		return 0.28
+	case strings.ContainsRune("ftr()[]{}\"-", r):
+		return 0.36
+	case r == ' ':
+		return 0.28
+	case strings.ContainsRune("mwMW@%", r):
+		return 0.86
+	case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) ||
+		unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) ||
+		(r >= 0xff00 && r <= 0xffef):
+		return 1
+	case unicode.IsUpper(r):
+		return 0.66
+	default:
+		return 0.55
+	}
+}
+
+// isMonospace reports whether the font family is monospaced.
+func isMonospace(family string) bool {
+	family = strings.ToLower(family)
+	for _, mono := range []string{"mono", "courier", "consolas", "menlo", "monaco"} {
+		if strings.Contains(family, mono) {
+			return true
+		}
+	}
+	return false
+}
+
+// fontOf returns the font set by style, falling back to the draw.io
+// defaults.
+func fontOf(style Style) Font {
+	f := Font{Family: defaultFontFamily, Size: defaultFontSize}
+	if v := style.Attributes["fontFamily"]; v != "" {
+		f.Family = v
+	}
+	if v, ok := style.float("fontSize"); ok && v > 0 {
+		f.Size = v
+	}
+	if v, err := strconv.Atoi(style.Attributes["fontStyle"]); err == nil {
+		f.Bold = v&1 != 0
+		f.Italic = v&2 != 0
+	}
+	return f
+}
+
+var (
+	htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(div|p|li|tr)>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// labelText returns the plain text of the label of c, resolving its
+// placeholders and removing the markup of HTML labels.
+func labelText(c *Cell) string {
+	value := c.ResolvedValue()
+	if c.Style.Attributes["html"] != "1" {
+		return value
+	}
+	s := htmlBreak.ReplaceAllString(value, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.TrimRight(html.UnescapeString(s), "\n")
+}
+
+// htmlLines escapes lines and joins them into an HTML label.
+func htmlLines(lines []string) string {
+	escaped := make([]string, len(lines))
+	for i, l := range lines {
+		escaped[i] = html.EscapeString(l)
+	}
+	return strings.Join(escaped, "<br>")
+}
+
+// SizeToFit resizes the vertex c so that its label fits, including
+// the style's spacing, using m to measure the text. A nil m uses
+// DefaultMeasurer. The size never drops below minWidth and minHeight.
+func SizeToFit(c *Cell, m TextMeasurer, minWidth, minHeight int) *Cell {
+	if m == nil {
+		m = DefaultMeasurer
+	}
+	if c.Geometry == nil {
+		c.Geometry = newGeometry()
+	}
+
+	w, h := m.Measure(labelText(c), fontOf(c.Style))
+	spacing := 2.0
+	if v, ok := c.Style.float("spacing"); ok {
+		spacing = v
+	}
+	pad := func(key string) float64 {
+		v, _ := c.Style.float(key)
+		return spacing + v
+	}
+	w += pad("spacingLeft") + pad("spacingRight") + 8
+	h += pad("spacingTop") + pad("spacingBottom") + 8
+
+	c.Geometry.setSize(maxInt(int(w+0.5), minWidth), maxInt(int(h+0.5), minHeight))
+	return c
+}
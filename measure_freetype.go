@@ -0,0 +1,110 @@
+//go:build freetype
+
+package graw
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// FontMeasurer measures text with the glyph metrics of registered
+// TrueType or OpenType fonts. Text in a font which was not registered
+// is measured by Fallback. It is only available when building with
+// the freetype tag. A FontMeasurer is safe for concurrent use.
+type FontMeasurer struct {
+	// Fallback measures text in unregistered fonts. A nil Fallback
+	// uses ApproxMeasurer.
+	Fallback TextMeasurer
+
+	mu    sync.Mutex
+	fonts map[fontKey]*opentype.Font
+	faces map[faceKey]font.Face
+}
+
+// fontKey identifies a registered font file.
+type fontKey struct {
+	family       string
+	bold, italic bool
+}
+
+// faceKey identifies a font file at a given size.
+type faceKey struct {
+	fontKey
+	size float64
+}
+
+// NewFontMeasurer returns a FontMeasurer without registered fonts.
+func NewFontMeasurer() *FontMeasurer {
+	return &FontMeasurer{
+		fonts: make(map[fontKey]*opentype.Font),
+		faces: make(map[faceKey]font.Face),
+	}
+}
+
+// Register parses the font file data and uses it for text in the
+// family, weight and slant of f. The size of f is ignored.
+func (m *FontMeasurer) Register(f Font, data []byte) error {
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fonts[fontKey{f.Family, f.Bold, f.Italic}] = parsed
+	return nil
+}
+
+// Measure implements TextMeasurer.
+func (m *FontMeasurer) Measure(text string, f Font) (float64, float64) {
+	if f.Size <= 0 {
+		f.Size = defaultFontSize
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	face, err := m.face(f)
+	if err != nil || face == nil {
+		fallback := m.Fallback
+		if fallback == nil {
+			fallback = ApproxMeasurer{}
+		}
+		return fallback.Measure(text, f)
+	}
+
+	lines := strings.Split(text, "\n")
+	var width fixed.Int26_6
+	for _, line := range lines {
+		if w := font.MeasureString(face, line); w > width {
+			width = w
+		}
+	}
+	return float64(width) / 64, float64(len(lines)) * f.Size * lineHeight
+}
+
+// face returns the cached face for f, or nil when its font is not
+// registered. m.mu must be held.
+func (m *FontMeasurer) face(f Font) (font.Face, error) {
+	key := faceKey{fontKey{f.Family, f.Bold, f.Italic}, f.Size}
+	if face, ok := m.faces[key]; ok {
+		return face, nil
+	}
+	parsed, ok := m.fonts[key.fontKey]
+	if !ok {
+		return nil, nil
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    f.Size,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.faces[key] = face
+	return face, nil
+}
@@ -0,0 +1,76 @@
+//go:build freetype
+
+package graw
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestFontMeasurer(t *testing.T) {
+	m := NewFontMeasurer()
+	if err := m.Register(Font{Family: "Go Mono"}, gomono.TTF); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register(Font{Family: "Go"}, goregular.TTF); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register(Font{Family: "Broken"}, []byte("not a font")); err == nil {
+		t.Error("got no error for a broken font")
+	}
+	// Go Mono advances 0.6 em per glyph, up to the 1/64 pixel
+	// precision of the glyph metrics.
+	tests := []struct {
+		name   string
+		text   string
+		f      Font
+		width  float64
+		height float64
+	}{
+		{"mono", "abcd", Font{Family: "Go Mono", Size: 10}, 24, 12},
+		{"default size", "ab", Font{Family: "Go Mono"}, 2 * 0.6 * defaultFontSize, defaultFontSize * lineHeight},
+		{"lines", "ab\nabcd", Font{Family: "Go Mono", Size: 10}, 24, 24},
+		{"empty", "", Font{Family: "Go Mono", Size: 10}, 0, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h := m.Measure(tt.text, tt.f)
+			if math.Abs(w-tt.width) > 0.1 || math.Abs(h-tt.height) > 0.1 {
+				t.Errorf("got %vx%v, want %vx%v", w, h, tt.width, tt.height)
+			}
+		})
+	}
+
+	// The proportional font gives the narrow i less room than the
+	// wide m.
+	narrow, _ := m.Measure("iiii", Font{Family: "Go", Size: 12})
+	wide, _ := m.Measure("mmmm", Font{Family: "Go", Size: 12})
+	if narrow >= wide {
+		t.Errorf("got %v for iiii and %v for mmmm, want mmmm wider", narrow, wide)
+	}
+}
+
+func TestFontMeasurerFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback TextMeasurer
+	}{
+		{"approx", nil},
+		{"own", ApproxMeasurer{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewFontMeasurer()
+			m.Fallback = tt.fallback
+			f := Font{Family: "Unregistered", Size: 12}
+			gw, gh := m.Measure("hello", f)
+			ww, wh := ApproxMeasurer{}.Measure("hello", f)
+			if gw != ww || gh != wh {
+				t.Errorf("got %vx%v, want the fallback %vx%v", gw, gh, ww, wh)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package graw
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApproxMeasurer(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		text string
+		font Font
+		w, h float64
+	}{
+		{"empty", "", Font{Size: 10}, 0, 12},
+		{"narrow", "il", Font{Size: 10}, 5.6, 12},
+		{"default size", "a", Font{}, 6.6, 14.4},
+		{"wide", "mW", Font{Size: 10}, 17.2, 12},
+		{"CJK", "网关", Font{Size: 10}, 20, 12},
+		{"lines", "ab\na", Font{Size: 10}, 11, 24},
+		{"bold", "aa", Font{Size: 10, Bold: true}, 12.1, 12},
+		{"monospace", "il", Font{Family: "Courier New", Size: 10}, 12, 12},
+		{"monospace CJK", "网", Font{Family: "JetBrains Mono", Size: 10}, 10, 12},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			w, h := ApproxMeasurer{}.Measure(test.text, test.font)
+			if math.Abs(w-test.w) > 1e-9 || math.Abs(h-test.h) > 1e-9 {
+				t.Errorf("got %vx%v, want %vx%v", w, h, test.w, test.h)
+			}
+		})
+	}
+}
+
+func TestFontOf(t *testing.T) {
+	for _, test := range []struct {
+		style string
+		want  Font
+	}{
+		{"", Font{Family: "Helvetica", Size: 12}},
+		{"fontFamily=Courier New;fontSize=16;", Font{Family: "Courier New", Size: 16}},
+		{"fontStyle=1;", Font{Family: "Helvetica", Size: 12, Bold: true}},
+		{"fontStyle=3;fontSize=0;", Font{Family: "Helvetica", Size: 12, Bold: true, Italic: true}},
+		{"fontStyle=4;", Font{Family: "Helvetica", Size: 12}},
+	} {
+		if got := fontOf(parseStyle(test.style)); got != test.want {
+			t.Errorf("fontOf(%q): got %+v, want %+v", test.style, got, test.want)
+		}
+	}
+}
+
+func TestLabelText(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		cell *Cell
+		want string
+	}{
+		{"plain", NewShape("a", "1").Label("a <b>"), "a <b>"},
+		{"html", NewShape("a", "1").Label("API<br>Gateway &amp; <b>Proxy</b>").Styled("html", "1"), "API\nGateway & Proxy"},
+		{"html blocks", NewShape("a", "1").Label("<div>one</div><div>two</div>").Styled("html", "1"), "one\ntwo"},
+		{"placeholders", NewShape("a", "1").Label("Host %ip%").SetData("ip", "10.0.0.1").SetPlaceholders(true), "Host 10.0.0.1"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := labelText(test.cell); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// fixedMeasurer measures every text as w by h.
+type fixedMeasurer struct{ w, h float64 }
+
+func (m fixedMeasurer) Measure(string, Font) (float64, float64) { return m.w, m.h }
+
+func TestSizeToFit(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		style      string
+		m          TextMeasurer
+		minW, minH int
+		w, h       int
+	}{
+		{"default spacing", "", fixedMeasurer{100, 20}, 0, 0, 112, 32},
+		{"spacing", "spacing=0;spacingLeft=10;", fixedMeasurer{100, 20}, 0, 0, 118, 28},
+		{"minimum", "", fixedMeasurer{10, 10}, 80, 40, 80, 40},
+		{"default measurer", "", nil, 0, 0, 33, 26},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewShape("a", "1").Label("Box")
+			c.Style = parseStyle(test.style)
+			SizeToFit(c, test.m, test.minW, test.minH)
+			if w, h := c.Geometry.size(); w != test.w || h != test.h {
+				t.Errorf("got %dx%d, want %dx%d", w, h, test.w, test.h)
+			}
+		})
+	}
+}
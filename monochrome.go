@@ -0,0 +1,110 @@
+package graw
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// monochromeDashes are the dash patterns telling apart edges of
+// different colors in Monochrome.
+var monochromeDashes = []string{"8 4", "2 3", "8 3 2 3", "12 4", "4 4 1 4 1 4", "1 6"}
+
+// Monochrome returns a copy of g fit for printing in black and white.
+// Text and outlines become black and fills shades of gray; fills of
+// different colors get different shades, ordered by their lightness,
+// so that categories told apart by color stay distinguishable. Edges
+// of different colors get different dash patterns instead, unless they
+// are dashed already. Gradients are removed and the background of the
+// page becomes white. g itself is left untouched.
+func Monochrome(g *GraphModel) GraphModel {
+	cp := g.clone()
+	if cp.Background != "" && cp.Background != "none" {
+		cp.Background = "#ffffff"
+	}
+
+	// Rank the fill colors and the edge colors in use.
+	fills, strokes := make(map[string]string), make(map[string]string)
+	for i := range cp.Root {
+		c := cp.Root[i]
+		for _, k := range []string{"fillColor", "swimlaneFillColor"} {
+			if v := monochromeColor(c.Style.Attributes[k]); v != "" && v != "#ffffff" {
+				fills[v] = ""
+			}
+		}
+		if v := monochromeColor(c.Style.Attributes["strokeColor"]); c.isEdge() && v != "" && v != "#000000" {
+			strokes[v] = ""
+		}
+	}
+	shades := grayShades(sortedKeys(fills))
+	dashes := make(map[string]string, len(strokes))
+	for i, v := range sortedKeys(strokes) {
+		dashes[v] = monochromeDashes[i%len(monochromeDashes)]
+	}
+
+	for i := range cp.Root {
+		c := cp.Root[i]
+		a := c.Style.Attributes
+		if a == nil {
+			continue
+		}
+		for _, k := range []string{"fillColor", "swimlaneFillColor"} {
+			if v := monochromeColor(a[k]); v != "" {
+				if shade, ok := shades[v]; ok {
+					a[k] = shade
+				} else {
+					a[k] = v
+				}
+			}
+		}
+		if v := monochromeColor(a["strokeColor"]); v != "" {
+			if dash, ok := dashes[v]; ok && c.isEdge() && a["dashed"] != "1" {
+				c.Style.set("dashed", "1")
+				c.Style.set("dashPattern", dash)
+			}
+			a["strokeColor"] = "#000000"
+		}
+		for _, k := range []string{"fontColor", "labelBorderColor"} {
+			if monochromeColor(a[k]) != "" {
+				a[k] = "#000000"
+			}
+		}
+		if monochromeColor(a["labelBackgroundColor"]) != "" {
+			a["labelBackgroundColor"] = "#ffffff"
+		}
+		delete(a, "gradientColor")
+		delete(a, "gradientDirection")
+	}
+	return cp
+}
+
+// monochromeColor returns the #rrggbb color v normalized, or "" for
+// colors which are not painted or not given as hex.
+func monochromeColor(v string) string {
+	v = normalizeColor(v)
+	if !strings.HasPrefix(v, "#") || len(v) != 7 {
+		return ""
+	}
+	return v
+}
+
+// grayShades maps the colors to shades of gray from light to medium,
+// the lightest color getting the lightest shade.
+func grayShades(colors []string) map[string]string {
+	luma := func(c string) float64 {
+		rgb := parseColor(c)
+		return 0.299*float64(rgb[0]) + 0.587*float64(rgb[1]) + 0.114*float64(rgb[2])
+	}
+	sort.SliceStable(colors, func(i, j int) bool { return luma(colors[i]) > luma(colors[j]) })
+
+	const lightest, darkest = 0xf0, 0x99
+	shades := make(map[string]string, len(colors))
+	for i, c := range colors {
+		v := lightest
+		if len(colors) > 1 {
+			v = lightest - i*(lightest-darkest)/(len(colors)-1)
+		}
+		shades[c] = fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+	return shades
+}
@@ -0,0 +1,139 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMonochrome(t *testing.T) {
+	tests := []struct {
+		id    string
+		edge  bool
+		style map[string]string
+		want  map[string]string
+	}{
+		{
+			id:    "yellow",
+			style: map[string]string{"fillColor": "#FFF2CC", "strokeColor": "#d6b656", "fontColor": "#333", "gradientColor": "#ffffff", "gradientDirection": "north"},
+			want:  map[string]string{"fillColor": "#f0f0f0", "strokeColor": "#000000", "fontColor": "#000000"},
+		},
+		{
+			id:    "blue",
+			style: map[string]string{"fillColor": "#dae8fc", "labelBackgroundColor": "#ffff00", "labelBorderColor": "#ff0000"},
+			want:  map[string]string{"fillColor": "#c5c5c5", "labelBackgroundColor": "#ffffff", "labelBorderColor": "#000000"},
+		},
+		{
+			id:    "red",
+			style: map[string]string{"fillColor": "#F8CECC"},
+			want:  map[string]string{"fillColor": "#999999"},
+		},
+		{
+			id:    "lane",
+			style: map[string]string{"swimlane": "", "fillColor": "#ffffff", "swimlaneFillColor": "#DAE8FC"},
+			want:  map[string]string{"swimlane": "", "fillColor": "#ffffff", "swimlaneFillColor": "#c5c5c5"},
+		},
+		{
+			id:    "unpainted",
+			style: map[string]string{"fillColor": "none", "strokeColor": "none", "fontColor": "default", "labelBackgroundColor": "none"},
+			want:  map[string]string{"fillColor": "none", "strokeColor": "none", "fontColor": "default", "labelBackgroundColor": "none"},
+		},
+		{
+			id:    "named",
+			style: map[string]string{"fillColor": "red"},
+			want:  map[string]string{"fillColor": "red"},
+		},
+		{
+			id:    "red edge",
+			edge:  true,
+			style: map[string]string{"strokeColor": "#ff0000"},
+			want:  map[string]string{"strokeColor": "#000000", "dashed": "1", "dashPattern": "8 3 2 3"},
+		},
+		{
+			id:    "red edge again",
+			edge:  true,
+			style: map[string]string{"strokeColor": "#F00"},
+			want:  map[string]string{"strokeColor": "#000000", "dashed": "1", "dashPattern": "8 3 2 3"},
+		},
+		{
+			id:    "blue edge",
+			edge:  true,
+			style: map[string]string{"strokeColor": "#0000ff", "fontColor": "#0000ff"},
+			want:  map[string]string{"strokeColor": "#000000", "dashed": "1", "dashPattern": "8 4", "fontColor": "#000000"},
+		},
+		{
+			id:    "dashed edge",
+			edge:  true,
+			style: map[string]string{"strokeColor": "#00ff00", "dashed": "1"},
+			want:  map[string]string{"strokeColor": "#000000", "dashed": "1"},
+		},
+		{
+			id:    "black edge",
+			edge:  true,
+			style: map[string]string{"strokeColor": "#000000"},
+			want:  map[string]string{"strokeColor": "#000000"},
+		},
+		{
+			id:    "plain edge",
+			edge:  true,
+			style: map[string]string{"endArrow": "block"},
+			want:  map[string]string{"endArrow": "block"},
+		},
+	}
+	g := NewGraph()
+	g.Background = "#333333"
+	for _, tt := range tests {
+		c := NewShape(tt.id, "1")
+		if tt.edge {
+			c = newEdgeCell(tt.id, "1", "", "")
+		}
+		c.Style.Attributes = make(map[string]string)
+		for k, v := range tt.style {
+			c.Style.Attributes[k] = v
+		}
+		g.Add(c)
+	}
+	mono := Monochrome(&g)
+	if mono.Background != "#ffffff" {
+		t.Errorf("got background %q, want white", mono.Background)
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := mono.FindByID(tt.id).Style.Attributes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got := g.FindByID(tt.id).Style.Attributes; !reflect.DeepEqual(got, tt.style) {
+				t.Errorf("got original %v changed, want %v", got, tt.style)
+			}
+		})
+	}
+	if g.Background != "#333333" {
+		t.Errorf("got original background %q", g.Background)
+	}
+}
+
+func TestMonochromeBackground(t *testing.T) {
+	for _, bg := range []string{"", "none"} {
+		g := NewGraph()
+		g.Background = bg
+		if got := Monochrome(&g).Background; got != bg {
+			t.Errorf("background %q: got %q", bg, got)
+		}
+	}
+}
+
+func TestGrayShades(t *testing.T) {
+	tests := []struct {
+		colors []string
+		want   map[string]string
+	}{
+		{nil, map[string]string{}},
+		{[]string{"#ff0000"}, map[string]string{"#ff0000": "#f0f0f0"}},
+		{[]string{"#000000", "#ffffff"}, map[string]string{"#ffffff": "#f0f0f0", "#000000": "#999999"}},
+		{[]string{"#0000ff", "#00ff00", "#ff0000"}, map[string]string{"#00ff00": "#f0f0f0", "#ff0000": "#c5c5c5", "#0000ff": "#999999"}},
+	}
+	for _, tt := range tests {
+		if got := grayShades(tt.colors); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("grayShades(%v): got %v, want %v", tt.colors, got, tt.want)
+		}
+	}
+}
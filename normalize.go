@@ -0,0 +1,109 @@
+package graw
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnknownStyleKey identifies a style key on a cell which draw.io does
+// not recognize.
+type UnknownStyleKey struct {
+	CellID string
+	Key    string
+}
+
+// NormalizeReport summarizes the changes made by NormalizeStyles.
+type NormalizeReport struct {
+	// Removed counts the keys dropped because they held the
+	// draw.io default value.
+	Removed int
+	// Merged counts the keys folded into their canonical spelling,
+	// e.g. "fillcolor" into "fillColor".
+	Merged int
+	// Unknown lists the keys which are neither known style keys
+	// nor named styles.
+	Unknown []UnknownStyleKey
+}
+
+// NormalizeStyles rewrites the style of every cell in g into a
+// canonical form: keys holding their default value are removed,
+// colors are lower-cased and expanded (#FFF becomes #ffffff) and keys
+// differing from a known key only by case are merged into it. Keys
+// without a value are kept as named styles; any other unknown key is
+// kept and reported.
+func NormalizeStyles(g *GraphModel) NormalizeReport {
+	var report NormalizeReport
+
+	canonical := make(map[string]string, len(styleSchema))
+	for k := range styleSchema {
+		canonical[strings.ToLower(k)] = k
+	}
+
+	for i := range g.Root {
+		c := g.Root[i]
+		if len(c.Style.Attributes) == 0 {
+			continue
+		}
+
+		attrs := make(map[string]string, len(c.Style.Attributes))
+		for _, k := range sortedKeys(c.Style.Attributes) {
+			v := c.Style.Attributes[k]
+			if k == "" {
+				continue
+			}
+
+			key := k
+			if _, ok := styleSchema[k]; !ok {
+				if known, ok := canonical[strings.ToLower(k)]; ok {
+					key = known
+					report.Merged++
+				} else if v != "" {
+					report.Unknown = append(report.Unknown, UnknownStyleKey{CellID: c.ID, Key: k})
+				}
+			}
+
+			if styleSchema[key].kind == kindColor {
+				v = normalizeColor(v)
+			}
+			// the correctly spelled key wins over a merged one
+			if _, ok := attrs[key]; ok && key != k {
+				continue
+			}
+			attrs[key] = v
+		}
+
+		for k, v := range attrs {
+			if def := styleSchema[k].def; def != "" && v == def {
+				delete(attrs, k)
+				report.Removed++
+			}
+		}
+
+		c.Style.Attributes = attrs
+	}
+
+	return report
+}
+
+// normalizeColor lower-cases a hex color and expands the short
+// #rgb form. Other values such as "none" are returned unchanged.
+func normalizeColor(v string) string {
+	if !strings.HasPrefix(v, "#") {
+		return v
+	}
+	v = strings.ToLower(v)
+	if len(v) == 4 {
+		v = "#" + strings.Repeat(v[1:2], 2) + strings.Repeat(v[2:3], 2) + strings.Repeat(v[3:4], 2)
+	}
+	return v
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
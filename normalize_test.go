@@ -0,0 +1,80 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeStyles(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		style  map[string]string
+		want   map[string]string
+		report NormalizeReport
+	}{
+		{
+			name:   "defaults removed",
+			style:  map[string]string{"rounded": "0", "dashed": "0", "opacity": "100", "strokeWidth": "2"},
+			want:   map[string]string{"strokeWidth": "2"},
+			report: NormalizeReport{Removed: 3},
+		},
+		{
+			name:  "colors",
+			style: map[string]string{"fillColor": "#ABC", "strokeColor": "#DAE8FC", "fontColor": "none"},
+			want:  map[string]string{"fillColor": "#aabbcc", "strokeColor": "#dae8fc", "fontColor": "none"},
+		},
+		{
+			name:   "case merged",
+			style:  map[string]string{"fillcolor": "#FFF", "STROKEWIDTH": "3"},
+			want:   map[string]string{"fillColor": "#ffffff", "strokeWidth": "3"},
+			report: NormalizeReport{Merged: 2},
+		},
+		{
+			name:   "correct spelling wins",
+			style:  map[string]string{"fillcolor": "#FFF", "fillColor": "#000"},
+			want:   map[string]string{"fillColor": "#000000"},
+			report: NormalizeReport{Merged: 1},
+		},
+		{
+			name:  "named styles kept",
+			style: map[string]string{"ellipse": "", "text": ""},
+			want:  map[string]string{"ellipse": "", "text": ""},
+		},
+		{
+			name:   "unknown reported",
+			style:  map[string]string{"bogus": "x", "rounded": "1"},
+			want:   map[string]string{"bogus": "x", "rounded": "1"},
+			report: NormalizeReport{Unknown: []UnknownStyleKey{{CellID: "a", Key: "bogus"}}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			c := NewShape("a", "1")
+			c.Style = Style{Attributes: test.style}
+			g.Add(c)
+			report := NormalizeStyles(&g)
+			if !reflect.DeepEqual(c.Style.Attributes, test.want) {
+				t.Errorf("got style %v, want %v", c.Style.Attributes, test.want)
+			}
+			if !reflect.DeepEqual(report, test.report) {
+				t.Errorf("got report %+v, want %+v", report, test.report)
+			}
+		})
+	}
+}
+
+func TestNormalizeColor(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"#FFF", "#ffffff"},
+		{"#a1B2c3", "#a1b2c3"},
+		{"none", "none"},
+		{"default", "default"},
+		{"", ""},
+	} {
+		if got := normalizeColor(test.in); got != test.want {
+			t.Errorf("normalizeColor(%q): got %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+)
+
+// Size of the photos of org chart employees.
+const orgPhotoSize = 40
+
+// Employee is a person of an organizational chart, typically read
+// from a directory such as LDAP or Active Directory.
+type Employee struct {
+	// ID identifies the employee, e.g. a distinguished name or an
+	// employee number.
+	ID    string
+	Name  string
+	Title string
+	// Department groups employees into containers; employees
+	// without one are drawn on their own.
+	Department string
+	// ManagerID is the ID of the employee's manager, empty for the
+	// heads of the organization.
+	ManagerID string
+	// Photo is the URL of a picture, e.g. a data URI of the
+	// thumbnailPhoto attribute, drawn left of the name.
+	Photo string
+}
+
+// orgBlock is a unit of the org chart layout: a department container
+// or an employee without a department.
+type orgBlock struct {
+	cell  *Cell
+	roots []*Cell
+}
+
+// NewOrgChart returns an organizational chart of employees. Employees
+// are drawn top down below their managers, those of a department in
+// a container of its own; the departments are laid out as a tree too,
+// each below the department of the manager its head reports to.
+// Employees are listed in the order given, which decides the order of
+// colleagues. ErrCycle is returned when employees manage each other.
+func NewOrgChart(employees []Employee) (*GraphModel, error) {
+	byID := make(map[string]int, len(employees))
+	for i, e := range employees {
+		if _, ok := byID[e.ID]; ok {
+			return nil, fmt.Errorf("graw: NewOrgChart: duplicate employee %q", e.ID)
+		}
+		byID[e.ID] = i
+	}
+	for _, e := range employees {
+		if _, ok := byID[e.ManagerID]; e.ManagerID != "" && !ok {
+			return nil, fmt.Errorf("graw: NewOrgChart: employee %q: unknown manager %q", e.ID, e.ManagerID)
+		}
+		seen := map[string]bool{e.ID: true}
+		for m := e.ManagerID; m != ""; m = employees[byID[m]].ManagerID {
+			if seen[m] {
+				return nil, ErrCycle
+			}
+			seen[m] = true
+		}
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	cells := make([]*Cell, len(employees))
+	for i, e := range employees {
+		c := NewShape(taken.unique(e.ID), rootCellID)
+		c.Value = "<b>" + html.EscapeString(e.Name) + "</b>"
+		if e.Title != "" {
+			c.Value += "<br>" + html.EscapeString(e.Title)
+		}
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		if e.Photo != "" {
+			c.Style.set("shape", "label")
+			c.Style.set("image", e.Photo)
+			c.Style.setFloat("imageWidth", orgPhotoSize)
+			c.Style.setFloat("imageHeight", orgPhotoSize)
+			c.Style.set("align", "left")
+			c.Style.setFloat("spacingLeft", orgPhotoSize+12)
+		}
+		SizeToFit(c, nil, defaultWidth, orgPhotoSize+20)
+		cells[i] = c
+	}
+
+	// children holds the employees reporting to a manager of the same
+	// department, blockChildren the blocks below each block.
+	children := make(map[*Cell][]*Cell)
+	blocks := make(map[string]*orgBlock)
+	var order []*orgBlock
+	blockOf := func(i int) *orgBlock {
+		key := "\x00" + employees[i].ID
+		if d := employees[i].Department; d != "" {
+			key = d
+		}
+		b, ok := blocks[key]
+		if !ok {
+			b = &orgBlock{cell: cells[i]}
+			if employees[i].Department != "" {
+				b.cell = newContainer(taken.unique(employees[i].Department), rootCellID, employees[i].Department)
+			}
+			blocks[key] = b
+			order = append(order, b)
+		}
+		return b
+	}
+	blockChildren := make(map[*Cell][]*Cell)
+	parent := make(map[*orgBlock]*orgBlock)
+	var members []*Cell
+	for i, e := range employees {
+		b := blockOf(i)
+		if b.cell != cells[i] {
+			cells[i].ParentID = b.cell.ID
+			members = append(members, cells[i])
+		}
+		m, ok := byID[e.ManagerID]
+		if ok && e.Department != "" && employees[m].Department == e.Department {
+			children[cells[m]] = append(children[cells[m]], cells[i])
+			continue
+		}
+		if b.cell != cells[i] {
+			b.roots = append(b.roots, cells[i])
+		}
+		if !ok || parent[b] != nil {
+			continue
+		}
+		// departments reporting to each other are laid out apart
+		mb := blockOf(m)
+		p := mb
+		for p != nil && p != b {
+			p = parent[p]
+		}
+		if p == nil {
+			parent[b] = mb
+			blockChildren[mb.cell] = append(blockChildren[mb.cell], b.cell)
+		}
+	}
+
+	var roots []*Cell
+	for _, b := range order {
+		if len(b.roots) > 0 {
+			w, h := layoutTree(b.roots, children, groupPadding, groupHeader+groupPadding, groupPadding, groupGap)
+			b.cell.Geometry.setSize(w+2*groupPadding, h+groupHeader+2*groupPadding)
+		}
+		if parent[b] == nil {
+			roots = append(roots, b.cell)
+		}
+	}
+	layoutTree(roots, blockChildren, 10, 10, groupGap, 80)
+	for _, b := range order {
+		g.Add(b.cell)
+	}
+	for _, c := range members {
+		g.Add(c)
+	}
+
+	for i, e := range employees {
+		m, ok := byID[e.ManagerID]
+		if !ok {
+			continue
+		}
+		edge := newEdgeCell(taken.unique(cells[m].ID+"-"+cells[i].ID), rootCellID, cells[m].ID, cells[i].ID)
+		edge.Style.set("edgeStyle", "elbowEdgeStyle")
+		edge.Style.set("elbow", "vertical")
+		edge.Style.set("endArrow", "none")
+		g.Add(edge)
+	}
+	return &g, nil
+}
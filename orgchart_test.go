@@ -0,0 +1,97 @@
+package graw
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewOrgChart(t *testing.T) {
+	g, err := NewOrgChart([]Employee{
+		{ID: "ceo", Name: "Ada", Title: "CEO", Photo: "data:image/png;base64,xx"},
+		{ID: "cto", Name: "Bob", Title: "CTO", Department: "Engineering", ManagerID: "ceo"},
+		{ID: "e1", Name: "Cy", Title: "Engineer", Department: "Engineering", ManagerID: "cto"},
+		{ID: "e2", Name: "Di", Title: "Engineer", Department: "Engineering", ManagerID: "cto"},
+		{ID: "cfo", Name: "Eve", Title: "CFO", Department: "Finance", ManagerID: "ceo"},
+		{ID: "f1", Name: "Fay", Department: "Finance", ManagerID: "cfo"},
+		{ID: "ea", Name: "Gus", Title: "Assistant", ManagerID: "ceo"},
+		{ID: "o1", Name: "Ops", Department: "Ops", ManagerID: "e1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id, parent, label string
+	}{
+		{"ceo", "1", "<b>Ada</b><br>CEO"},
+		{"ea", "1", "<b>Gus</b><br>Assistant"},
+		{"Engineering", "1", "Engineering"},
+		{"Finance", "1", "Finance"},
+		{"Ops", "1", "Ops"},
+		{"cto", "Engineering", "<b>Bob</b><br>CTO"},
+		{"e1", "Engineering", "<b>Cy</b><br>Engineer"},
+		{"e2", "Engineering", "<b>Di</b><br>Engineer"},
+		{"cfo", "Finance", "<b>Eve</b><br>CFO"},
+		{"f1", "Finance", "<b>Fay</b>"},
+		{"o1", "Ops", "<b>Ops</b>"},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Errorf("%s: missing", tt.id)
+			continue
+		}
+		if c.ParentID != tt.parent || c.Value != tt.label {
+			t.Errorf("%s: got parent %q, label %q, want %q, %q", tt.id, c.ParentID, c.Value, tt.parent, tt.label)
+		}
+	}
+
+	want := []string{"ceo->cto", "cto->e1", "cto->e2", "ceo->cfo", "cfo->f1", "ceo->ea", "e1->o1"}
+	if got := edgeList(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %v, want %v", got, want)
+	}
+
+	// Managers are drawn above their reports.
+	for _, e := range g.Root {
+		if !e.isEdge() {
+			continue
+		}
+		_, y0 := g.absolutePosition(g.FindByID(e.Source))
+		_, y1 := g.absolutePosition(g.FindByID(e.Target))
+		if y0 >= y1 {
+			t.Errorf("%s: got manager at y=%d, report at y=%d", e.ID, y0, y1)
+		}
+	}
+
+	ceo := g.FindByID("ceo")
+	if got := ceo.Style.Attributes["image"]; got != "data:image/png;base64,xx" {
+		t.Errorf("got image %q, want the photo", got)
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewOrgChartErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		employees []Employee
+		cycle     bool
+	}{
+		{"duplicate", []Employee{{ID: "a"}, {ID: "a"}}, false},
+		{"unknown manager", []Employee{{ID: "a", ManagerID: "b"}}, false},
+		{"cycle", []Employee{{ID: "a", ManagerID: "b"}, {ID: "b", ManagerID: "a"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOrgChart(tt.employees)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if got := errors.Is(err, ErrCycle); got != tt.cycle {
+				t.Errorf("got ErrCycle %v, want %v", got, tt.cycle)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package graw
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Dimensions of ownership badges and legends.
+const (
+	ownerBadgeHeight = 16
+	ownerLegendRow   = 24
+)
+
+// Ownership maps the paths of a repository, through the rules of a
+// CODEOWNERS file, or the entities of a service catalog to the teams
+// owning them.
+type Ownership struct {
+	rules    []ownerRule
+	entities map[string]string
+}
+
+// ownerRule is a rule of a CODEOWNERS file.
+type ownerRule struct {
+	pattern *regexp.Regexp
+	owner   string
+}
+
+// ReadCODEOWNERS reads the ownership rules of a GitHub or GitLab
+// CODEOWNERS file. The first owner of a rule is the owning team; rules
+// without owners disown the paths they match. GitLab sections are
+// ignored, their rules read alike.
+func ReadCODEOWNERS(r io.Reader) (*Ownership, error) {
+	o := &Ownership{}
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || line[0] == '#' || line[0] == '[' || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		pattern, err := codeownersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("graw: ReadCODEOWNERS: line %d: %v", n, err)
+		}
+		rule := ownerRule{pattern: pattern}
+		if len(fields) > 1 {
+			rule.owner = fields[1]
+		}
+		o.rules = append(o.rules, rule)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// codeownersPattern returns the regular expression matching the paths
+// the gitignore style pattern p matches, and the paths below them.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	dir := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid pattern %q", pattern)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	if dir {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
+
+// ReadCatalog reads the entities of a Backstage catalog from their
+// catalog-info.yaml descriptors, one or more documents. Entities are
+// known by their name and by their reference, e.g.
+// "component:default/payments"; their owner is the spec.owner of the
+// descriptor, without the "group:" kind and the default namespace.
+func ReadCatalog(r io.Reader) (*Ownership, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := decodeYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	o := &Ownership{entities: make(map[string]string)}
+	for _, d := range docs {
+		doc := jsonObject(d)
+		meta := jsonObject(doc["metadata"])
+		name := jsonString(meta, "name")
+		owner := jsonString(jsonObject(doc["spec"]), "owner")
+		if name == "" || owner == "" {
+			continue
+		}
+		owner = strings.TrimPrefix(owner, "group:")
+		owner = strings.TrimPrefix(owner, "user:")
+		owner = strings.TrimPrefix(owner, "default/")
+		ns := jsonString(meta, "namespace")
+		if ns == "" {
+			ns = "default"
+		}
+		ref := strings.ToLower(jsonString(doc, "kind") + ":" + ns + "/" + name)
+		o.entities[strings.ToLower(name)] = owner
+		o.entities[ref] = owner
+	}
+	return o, nil
+}
+
+// Owner returns the team owning key, an entity name or reference of a
+// catalog or a path of a repository, or "" when it has no owner. The
+// last CODEOWNERS rule matching a path decides its owner.
+func (o *Ownership) Owner(key string) string {
+	if owner, ok := o.entities[strings.ToLower(key)]; ok {
+		return owner
+	}
+	key = strings.TrimPrefix(key, "/")
+	for i := len(o.rules) - 1; i >= 0; i-- {
+		if o.rules[i].pattern.MatchString(key) {
+			return o.rules[i].owner
+		}
+	}
+	return ""
+}
+
+// OverlayOwnership colors the vertices of g by the team owning them,
+// badges them with the name of the team and adds a legend of the teams
+// right of the diagram. key returns the key the owner of a vertex is
+// looked up by in o, e.g. its path or the name of its service; the
+// plain text of the label is used when key is nil. Vertices without
+// owner are left alone. OverlayOwnership returns the teams in the
+// order of the legend.
+func OverlayOwnership(g *GraphModel, o *Ownership, key func(c *Cell) string) []string {
+	if key == nil {
+		key = labelText
+	}
+	taken := g.idSet()
+	colors := make(map[string]string)
+	var teams []string
+	var badges []*Cell
+	layer, right, top := g.rightOfDiagram()
+	for i := range g.Root {
+		c := g.Root[i]
+		if !c.isVertex() || c.Geometry == nil {
+			continue
+		}
+		w, _ := c.Geometry.size()
+		team := o.Owner(key(c))
+		if team == "" {
+			continue
+		}
+		color, ok := colors[team]
+		if !ok {
+			color = ganttColors[len(colors)%len(ganttColors)]
+			colors[team] = color
+			teams = append(teams, team)
+		}
+		c.Style.set("fillColor", color)
+		if c.Data == nil {
+			c.Data = make(map[string]string)
+		}
+		c.Data["owner"] = team
+
+		b := NewShape(taken.unique(c.ID+"-owner"), c.ParentID)
+		b.Value = html.EscapeString(ownerShortName(team))
+		b.Style = Style{Attributes: shapeStyle("rounded")}
+		b.Style.set("arcSize", "50")
+		b.Style.set("fillColor", mixColor(color, "#000000", 0.3))
+		b.Style.set("strokeColor", "none")
+		b.Style.set("fontColor", "#ffffff")
+		b.Style.set("fontSize", "9")
+		b.Style.set("whiteSpace", "nowrap")
+		tw, _ := DefaultMeasurer.Measure(b.Value, Font{Size: 9})
+		bw := maxInt(40, int(tw)+10)
+		b.Geometry.X = c.Geometry.X + w - bw + 6
+		b.Geometry.Y = c.Geometry.Y - ownerBadgeHeight/2
+		b.Geometry.setSize(bw, ownerBadgeHeight)
+		b.Data = map[string]string{"tooltip": "owner: " + team}
+		badges = append(badges, b)
+	}
+	if len(teams) == 0 {
+		return nil
+	}
+	for _, b := range badges {
+		g.Add(b)
+	}
+
+	legend := newContainer(taken.unique("owners"), layer, "Owners")
+	legend.Style.set("collapsible", "0")
+	legend.Geometry.X, legend.Geometry.Y = right+groupGap, top
+	width := 0
+	for _, t := range teams {
+		w, _ := DefaultMeasurer.Measure(t, Font{Size: defaultFontSize})
+		width = maxInt(width, int(w))
+	}
+	legend.Geometry.setSize(width+2*groupPadding+ownerLegendRow, groupHeader+len(teams)*ownerLegendRow+groupPadding)
+	g.Add(legend)
+	for i, t := range teams {
+		c := NewShape(taken.unique(legend.ID+"-"+t), legend.ID)
+		c.Value = html.EscapeString(t)
+		c.Style = Style{Attributes: shapeStyle("")}
+		c.Style.set("fillColor", colors[t])
+		c.Style.set("labelPosition", "right")
+		c.Style.set("align", "left")
+		c.Style.set("whiteSpace", "nowrap")
+		c.Geometry.X, c.Geometry.Y = groupPadding/2, groupHeader+groupPadding/2+i*ownerLegendRow
+		c.Geometry.setSize(ownerLegendRow-8, ownerLegendRow-8)
+		g.Add(c)
+	}
+	return teams
+}
+
+// ownerShortName returns the name of team without the organization
+// and the @ of CODEOWNERS, e.g. "payments" for "@acme/payments".
+func ownerShortName(team string) string {
+	if i := strings.LastIndexByte(team, '/'); i >= 0 {
+		team = team[i+1:]
+	}
+	return strings.TrimPrefix(team, "@")
+}
@@ -0,0 +1,174 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodeownersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		nomatch []string
+	}{
+		{"*", []string{"a", "a/b.go"}, nil},
+		{"*.go", []string{"main.go", "cmd/x/main.go"}, []string{"main.gox", "go"}},
+		{"docs/", []string{"docs/a.md", "x/docs/a/b.md"}, []string{"docs", "docsy/a.md"}},
+		{"/docs/", []string{"docs/a.md"}, []string{"x/docs/a.md"}},
+		{"/build", []string{"build", "build/out"}, []string{"x/build"}},
+		{"api/*.proto", []string{"api/a.proto"}, []string{"api/v1/a.proto", "x/api/a.proto"}},
+		{"**/logs", []string{"logs", "a/b/logs/x"}, []string{"alogs"}},
+		{"src/**/test", []string{"src/test", "src/a/b/test/x.go"}, []string{"test"}},
+		{"a?c", []string{"abc"}, []string{"a/c", "ac"}},
+		{"a.b", []string{"a.b"}, []string{"axb"}},
+	}
+	for _, tt := range tests {
+		re, err := codeownersPattern(tt.pattern)
+		if err != nil {
+			t.Errorf("%q: %v", tt.pattern, err)
+			continue
+		}
+		for _, p := range tt.match {
+			if !re.MatchString(p) {
+				t.Errorf("%q: got no match for %q", tt.pattern, p)
+			}
+		}
+		for _, p := range tt.nomatch {
+			if re.MatchString(p) {
+				t.Errorf("%q: got a match for %q", tt.pattern, p)
+			}
+		}
+	}
+	for _, p := range []string{"/", "//"} {
+		if _, err := codeownersPattern(p); err == nil {
+			t.Errorf("%q: got no error", p)
+		}
+	}
+}
+
+func TestReadCODEOWNERS(t *testing.T) {
+	o, err := ReadCODEOWNERS(strings.NewReader(`# default owners
+*                 @acme/platform
+*.md              @acme/docs @alice # docs
+/services/pay/    @acme/payments
+/services/pay/vendor/
+
+[Frontend] @acme/web
+web/              @acme/web
+^[Optional]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ key, want string }{
+		{"go.mod", "@acme/platform"},
+		{"README.md", "@acme/docs"},
+		{"services/pay/main.go", "@acme/payments"},
+		{"/services/pay/main.go", "@acme/payments"},
+		{"services/pay/README.md", "@acme/payments"},
+		{"services/pay/vendor/x.go", ""},
+		{"app/web/index.ts", "@acme/web"},
+	}
+	for _, tt := range tests {
+		if got := o.Owner(tt.key); got != tt.want {
+			t.Errorf("Owner(%q): got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestReadCatalog(t *testing.T) {
+	o, err := ReadCatalog(strings.NewReader(`apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: payments
+spec:
+  owner: group:default/payments-team
+---
+kind: API
+metadata:
+  name: Search
+  namespace: shop
+spec:
+  owner: user:alice
+---
+kind: Component
+metadata:
+  name: orphan
+spec: {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ key, want string }{
+		{"payments", "payments-team"},
+		{"component:default/payments", "payments-team"},
+		{"search", "alice"},
+		{"API:shop/Search", "alice"},
+		{"api:default/search", ""},
+		{"orphan", ""},
+	}
+	for _, tt := range tests {
+		if got := o.Owner(tt.key); got != tt.want {
+			t.Errorf("Owner(%q): got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestOverlayOwnership(t *testing.T) {
+	o, err := ReadCODEOWNERS(strings.NewReader("pay* @acme/payments\nweb @acme/web\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newTestGraph([2]string{"payments", "web"}, [2]string{"web", "misc"}, [2]string{"payouts", "web"})
+	for _, c := range g.Root {
+		if c.isVertex() && c.ID != "1" {
+			c.Value = c.ID
+		}
+	}
+	teams := OverlayOwnership(&g, o, nil)
+	if want := []string{"@acme/payments", "@acme/web"}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("got teams %q, want %q", teams, want)
+	}
+	tests := []struct {
+		id, owner, badge string
+	}{
+		{"payments", "@acme/payments", "payments"},
+		{"payouts", "@acme/payments", "payments"},
+		{"web", "@acme/web", "web"},
+		{"misc", "", ""},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if got := c.Data["owner"]; got != tt.owner {
+			t.Errorf("%s: got owner %q, want %q", tt.id, got, tt.owner)
+		}
+		b := g.FindByID(tt.id + "-owner")
+		switch {
+		case tt.badge == "" && b != nil:
+			t.Errorf("%s: got badge %q", tt.id, b.Value)
+		case tt.badge != "" && (b == nil || b.Value != tt.badge):
+			t.Errorf("%s: got badge %v, want %q", tt.id, b, tt.badge)
+		}
+	}
+	if a, b := g.FindByID("payments").Style.Attributes["fillColor"], g.FindByID("payouts").Style.Attributes["fillColor"]; a == "" || a != b {
+		t.Errorf("got colors %q and %q, want them alike", a, b)
+	}
+	if got := g.FindByID("misc").Style.Attributes["fillColor"]; got != "" {
+		t.Errorf("got fillColor %q on a vertex without owner", got)
+	}
+	legend := vertexLabels(&g)
+	if legend["owners-@acme/payments"] != "@acme/payments" || legend["owners-@acme/web"] != "@acme/web" {
+		t.Errorf("got legend %v", legend)
+	}
+	if iss := ValidateStyles(&g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+
+	// without owners, the diagram is left alone
+	h := newTestGraph([2]string{"a", "b"})
+	n := len(h.Root)
+	if teams := OverlayOwnership(&h, o, func(c *Cell) string { return c.ID }); teams != nil || len(h.Root) != n {
+		t.Errorf("got teams %q, %d cells, want none, %d", teams, len(h.Root), n)
+	}
+}
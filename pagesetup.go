@@ -0,0 +1,183 @@
+package graw
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// PageSize is the size of a page in draw.io units, 1/100 inch, in
+// portrait orientation.
+type PageSize struct {
+	Width, Height int
+}
+
+// Paper is a paper size offered by draw.io.
+type Paper int
+
+// The paper sizes offered by draw.io.
+const (
+	PaperA3 Paper = iota
+	PaperA4
+	PaperA5
+	PaperLetter
+	PaperLegal
+	PaperTabloid
+)
+
+// paperSizes holds the sizes of the papers in portrait orientation.
+var paperSizes = [...]PageSize{
+	PaperA3:      {1169, 1652},
+	PaperA4:      {827, 1169},
+	PaperA5:      {583, 827},
+	PaperLetter:  {850, 1100},
+	PaperLegal:   {850, 1400},
+	PaperTabloid: {1100, 1700},
+}
+
+// Size returns the size of p in portrait orientation, zero if p is not
+// a known paper.
+func (p Paper) Size() PageSize {
+	if p < 0 || int(p) >= len(paperSizes) {
+		return PageSize{}
+	}
+	return paperSizes[p]
+}
+
+// Orientation is the orientation of a page.
+type Orientation int
+
+const (
+	Portrait Orientation = iota
+	Landscape
+)
+
+// oriented returns s turned sideways for landscape pages, wider than
+// high, or upright for portrait ones.
+func (s PageSize) oriented(landscape bool) PageSize {
+	if landscape != (s.Width > s.Height) {
+		s.Width, s.Height = s.Height, s.Width
+	}
+	return s
+}
+
+// SetPageFormat turns on the page view of g with pages of paper p,
+// e.g. PaperA4, in orientation o, at scale 1. Unknown papers leave g
+// as it is; other sizes are set with SetPage.
+func (g *GraphModel) SetPageFormat(p Paper, o Orientation) *GraphModel {
+	size := p.Size()
+	if size == (PageSize{}) {
+		return g
+	}
+	size = size.oriented(o == Landscape)
+	return g.SetPage(size.Width, size.Height, 1)
+}
+
+// BackgroundImage is an image drawn behind a diagram, at the top left
+// corner of the page.
+type BackgroundImage struct {
+	Src    string `json:"src"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// PageSettings is the page setup of a diagram, which every page of a
+// Document has on its own, e.g. an A3 overview followed by A4 detail
+// pages. SetPageSettings leaves the settings whose fields are zero as
+// they are.
+type PageSettings struct {
+	// Size is the paper size, e.g. PaperA4.Size(), unset if zero.
+	Size PageSize
+	// Landscape turns the page sideways.
+	Landscape bool
+	// Scale is the scale the diagram is printed at, e.g. 0.5 to fit
+	// twice as much on a page.
+	Scale float64
+	// Background is the color of the page, e.g. "#1e1e1e", or "none".
+	Background string
+	// BackgroundImage is drawn behind the diagram when set.
+	BackgroundImage *BackgroundImage
+	// Grid shows the grid, GridSize pixels wide.
+	Grid     bool
+	GridSize int
+}
+
+// SetPageSettings applies the non-zero fields of s to g, so that
+// settings can be changed one at a time, e.g. the scale of a page whose
+// size is already set. Setting a size turns on the page view of
+// draw.io; the grid is hidden with SetGrid.
+func (g *GraphModel) SetPageSettings(s PageSettings) *GraphModel {
+	if s.Size != (PageSize{}) {
+		size := s.Size.oriented(s.Landscape)
+		g.Page = "1"
+		g.PageWidth, g.PageHeight = strconv.Itoa(size.Width), strconv.Itoa(size.Height)
+	}
+	if s.Scale > 0 {
+		g.PageScale = formatDecimal(s.Scale, 4)
+	}
+	if s.Background != "" {
+		g.SetBackgroundColor(s.Background)
+	}
+	if s.BackgroundImage != nil {
+		g.SetBackgroundImage(s.BackgroundImage)
+	}
+	if s.Grid {
+		g.Grid = "1"
+	}
+	if s.GridSize > 0 {
+		g.GridSize = strconv.Itoa(s.GridSize)
+	}
+	return g
+}
+
+// SetBackgroundColor sets the color of the page of g, e.g. "#1e1e1e"
+// for a dark background; empty removes it.
+func (g *GraphModel) SetBackgroundColor(color string) *GraphModel {
+	g.Background = normalizeColor(color)
+	return g
+}
+
+// SetBackgroundImage draws img behind the diagram of g, e.g. a branded
+// backdrop, at the top left corner of the page; nil removes it. The
+// image is kept in the backgroundImage attribute as JSON. Images from
+// untrusted input are set with ImagePolicy.SetBackgroundImage.
+func (g *GraphModel) SetBackgroundImage(img *BackgroundImage) *GraphModel {
+	g.BackgroundImage = ""
+	if img != nil {
+		if data, err := json.Marshal(img); err == nil {
+			g.BackgroundImage = string(data)
+		}
+	}
+	return g
+}
+
+// backgroundImage returns the background image of g, or nil if it has
+// none or its JSON is invalid.
+func (g *GraphModel) backgroundImage() *BackgroundImage {
+	if g.BackgroundImage == "" {
+		return nil
+	}
+	var img BackgroundImage
+	if json.Unmarshal([]byte(g.BackgroundImage), &img) != nil {
+		return nil
+	}
+	return &img
+}
+
+// PageSettings returns the page setup of g. Sizes are reported in
+// portrait orientation with Landscape set for wider pages; an invalid
+// background image is left out.
+func (g *GraphModel) PageSettings() PageSettings {
+	var s PageSettings
+	w, _ := strconv.Atoi(g.PageWidth)
+	h, _ := strconv.Atoi(g.PageHeight)
+	if w > 0 && h > 0 {
+		s.Landscape = w > h
+		s.Size = PageSize{w, h}.oriented(false)
+	}
+	s.Scale, _ = strconv.ParseFloat(g.PageScale, 64)
+	s.Background = g.Background
+	s.BackgroundImage = g.backgroundImage()
+	s.Grid = g.Grid == "1"
+	s.GridSize, _ = strconv.Atoi(g.GridSize)
+	return s
+}
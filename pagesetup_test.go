@@ -0,0 +1,142 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetPageFormat(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		paper         Paper
+		o             Orientation
+		width, height string
+	}{
+		{"A4 portrait", PaperA4, Portrait, "827", "1169"},
+		{"A4 landscape", PaperA4, Landscape, "1169", "827"},
+		{"A3 portrait", PaperA3, Portrait, "1169", "1652"},
+		{"Letter landscape", PaperLetter, Landscape, "1100", "850"},
+		{"Tabloid landscape", PaperTabloid, Landscape, "1700", "1100"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			g.SetPageFormat(test.paper, test.o)
+			if g.Page != "1" || g.PageWidth != test.width || g.PageHeight != test.height || g.PageScale != "1" {
+				t.Errorf("got page %q %sx%s scale %q, want page \"1\" %sx%s scale \"1\"",
+					g.Page, g.PageWidth, g.PageHeight, g.PageScale, test.width, test.height)
+			}
+			s := g.PageSettings()
+			if s.Size != test.paper.Size() || s.Landscape != (test.o == Landscape) {
+				t.Errorf("got PageSettings size %v landscape %v, want %v %v", s.Size, s.Landscape, test.paper.Size(), test.o == Landscape)
+			}
+		})
+	}
+}
+
+func TestSetPageFormatUnknownPaper(t *testing.T) {
+	for _, p := range []Paper{-1, PaperTabloid + 1} {
+		g := NewGraph()
+		want := g
+		if g.SetPageFormat(p, Landscape); !reflect.DeepEqual(g, want) {
+			t.Errorf("paper %d: got %+v, want the model unchanged", p, g)
+		}
+	}
+}
+
+func TestPageSizeOriented(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		size      PageSize
+		landscape bool
+		want      PageSize
+	}{
+		{"sideways portrait", PageSize{1169, 827}, false, PageSize{827, 1169}},
+		{"sideways landscape", PageSize{1169, 827}, true, PageSize{1169, 827}},
+		{"square landscape", PageSize{500, 500}, true, PageSize{500, 500}},
+	} {
+		if got := test.size.oriented(test.landscape); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSetPageSettings(t *testing.T) {
+	img := &BackgroundImage{Src: "https://example.com/bg.png", Width: 100, Height: 50}
+	for _, test := range []struct {
+		name string
+		s    PageSettings
+		want PageSettings
+	}{
+		{"zero", PageSettings{}, PageSettings{Size: PaperA4.Size(), Scale: 1, Background: "#ffffff", Grid: true, GridSize: 10}},
+		{"size", PageSettings{Size: PaperA3.Size()}, PageSettings{Size: PaperA3.Size(), Scale: 1, Background: "#ffffff", Grid: true, GridSize: 10}},
+		{"landscape", PageSettings{Size: PaperA4.Size(), Landscape: true}, PageSettings{Size: PaperA4.Size(), Landscape: true, Scale: 1, Background: "#ffffff", Grid: true, GridSize: 10}},
+		{"scale", PageSettings{Scale: 0.5}, PageSettings{Size: PaperA4.Size(), Scale: 0.5, Background: "#ffffff", Grid: true, GridSize: 10}},
+		{"background", PageSettings{Background: "#1E1E1E"}, PageSettings{Size: PaperA4.Size(), Scale: 1, Background: "#1e1e1e", Grid: true, GridSize: 10}},
+		{"no background", PageSettings{Background: "none"}, PageSettings{Size: PaperA4.Size(), Scale: 1, Background: "none", Grid: true, GridSize: 10}},
+		{"background image", PageSettings{BackgroundImage: img}, PageSettings{Size: PaperA4.Size(), Scale: 1, Background: "#ffffff", BackgroundImage: img, Grid: true, GridSize: 10}},
+		{"grid size", PageSettings{GridSize: 20}, PageSettings{Size: PaperA4.Size(), Scale: 1, Background: "#ffffff", Grid: true, GridSize: 20}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			g.SetPageFormat(PaperA4, Portrait).SetBackgroundColor("#ffffff").SetGrid(true, 10)
+			got := g.SetPageSettings(test.s).PageSettings()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPageSettingsRoundTrip(t *testing.T) {
+	want := PageSettings{
+		Size:            PaperLetter.Size(),
+		Landscape:       true,
+		Scale:           0.75,
+		Background:      "#1e1e1e",
+		BackgroundImage: &BackgroundImage{Src: "data:image/png,AAAA", Width: 10, Height: 10},
+		Grid:            true,
+		GridSize:        5,
+	}
+	g := NewGraph()
+	g.SetGrid(false, 10)
+	if got := g.SetPageSettings(want).PageSettings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if g.PageWidth != "1100" || g.PageHeight != "850" || g.PageScale != "0.75" {
+		t.Errorf("got page %sx%s scale %s, want 1100x850 scale 0.75", g.PageWidth, g.PageHeight, g.PageScale)
+	}
+}
+
+func TestSetBackground(t *testing.T) {
+	img := &BackgroundImage{Src: "https://example.com/bg.png", Width: 100, Height: 50}
+	tests := []struct {
+		name      string
+		set       func(g *GraphModel)
+		color     string
+		image     string
+		wantImage *BackgroundImage
+	}{
+		{"color", func(g *GraphModel) { g.SetBackgroundColor("#ABC") }, "#aabbcc", "", nil},
+		{"named color", func(g *GraphModel) { g.SetBackgroundColor("none") }, "none", "", nil},
+		{"no color", func(g *GraphModel) { g.SetBackgroundColor("#fff").SetBackgroundColor("") }, "", "", nil},
+		{"image", func(g *GraphModel) { g.SetBackgroundImage(img) }, "",
+			`{"src":"https://example.com/bg.png","width":100,"height":50}`, img},
+		{"no image", func(g *GraphModel) { g.SetBackgroundImage(img).SetBackgroundImage(nil) }, "", "", nil},
+		{"invalid image", func(g *GraphModel) { g.BackgroundImage = "{" }, "", "{", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			tt.set(&g)
+			if g.Background != tt.color {
+				t.Errorf("got background %q, want %q", g.Background, tt.color)
+			}
+			if g.BackgroundImage != tt.image {
+				t.Errorf("got backgroundImage %q, want %q", g.BackgroundImage, tt.image)
+			}
+			if got := g.backgroundImage(); !reflect.DeepEqual(got, tt.wantImage) {
+				t.Errorf("got image %+v, want %+v", got, tt.wantImage)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// Repair describes a malformation fixed by ParseTolerant.
+type Repair struct {
+	// Line is the line of the input the problem was found on, or 0
+	// when it is not known.
+	Line    int
+	Problem string
+}
+
+// String returns a human readable description of the repair.
+func (r Repair) String() string {
+	if r.Line == 0 {
+		return r.Problem
+	}
+	return fmt.Sprintf("line %d: %s", r.Line, r.Problem)
+}
+
+// decodeModel decodes an mxGraphModel document, or the first page of
+// an mxfile document holding it, compressed or not; compressed pages
+// are checked against o. A non strict decoder accepts unquoted
+// attribute values, attributes without a value and missing end tags.
+func decodeModel(data []byte, strict bool, o ParseOptions) (*GraphModel, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = strict
+	if !strict {
+		d.AutoClose = xml.HTMLAutoClose
+		d.Entity = xml.HTMLEntity
+	}
+	var content []byte
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil, errors.New("no mxGraphModel element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "mxGraphModel":
+				var g GraphModel
+				if err := d.DecodeElement(&g, &t); err != nil {
+					return nil, err
+				}
+				return &g, nil
+			case "mxfile", "diagram":
+				content = content[:0]
+			default:
+				return nil, fmt.Errorf("unexpected element <%s>", t.Name.Local)
+			}
+		case xml.CharData:
+			content = append(content, t...)
+		case xml.EndElement:
+			if t.Name.Local == "diagram" && len(bytes.TrimSpace(content)) > 0 {
+				return decodeCompressed(string(content), o)
+			}
+		}
+	}
+}
+
+// ErrDTD is returned for documents with a document type definition,
+// which diagrams never have: they are rejected as they may declare
+// entities referring to external resources or expanding without
+// bound.
+var ErrDTD = errors.New("graw: document type definitions are not allowed")
+
+// ParseOptions bounds the documents read by ParseWithOptions, which
+// may come from untrusted sources. Zero values leave the respective
+// limit off.
+type ParseOptions struct {
+	// MaxSize is the number of bytes a document may have.
+	MaxSize int
+	// MaxDepth is the depth elements may be nested to.
+	MaxDepth int
+	// MaxTokens is the number of XML tokens, such as elements and
+	// text, a document may have.
+	MaxTokens int
+	// AllowDTD accepts documents with a document type definition.
+	// Entities are never expanded either way.
+	AllowDTD bool
+	// DecodeLimits applies to the document and to the compressed
+	// pages it holds.
+	DecodeLimits
+}
+
+// DefaultParseOptions are the options of Parse.
+var DefaultParseOptions = ParseOptions{
+	MaxSize:      64 << 20,
+	MaxDepth:     128,
+	MaxTokens:    4000000,
+	DecodeLimits: DefaultDecodeLimits,
+}
+
+// Parse reads a diagram written by xml.Marshal or draw.io: an
+// mxGraphModel document or a .drawio file, of which the first page is
+// read. Cells wrapped in UserObject elements get their custom data
+// back, so that a parsed model marshals to the same document. The
+// document must stay within DefaultParseOptions; use ParseWithOptions
+// to tune them and ParseTolerant for files which are not well-formed.
+func Parse(r io.Reader) (*GraphModel, error) {
+	return ParseWithOptions(r, DefaultParseOptions)
+}
+
+// ParseWithOptions reads a diagram like Parse, within the limits of o.
+// Errors for documents breaking a limit wrap ErrLimit, or are ErrDTD.
+func ParseWithOptions(r io.Reader, o ParseOptions) (*GraphModel, error) {
+	data, err := o.read(r)
+	if err != nil {
+		return nil, fmt.Errorf("graw: Parse: %w", err)
+	}
+	g, err := decodeModel(data, true, o)
+	if err != nil {
+		return nil, fmt.Errorf("graw: Parse: %w", err)
+	}
+	return g, nil
+}
+
+// read reads the document r, which must stay within o.
+func (o ParseOptions) read(r io.Reader) ([]byte, error) {
+	if o.MaxSize > 0 {
+		r = io.LimitReader(r, int64(o.MaxSize)+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if o.MaxSize > 0 && len(data) > o.MaxSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrLimit, o.MaxSize)
+	}
+	if err := o.check(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// check scans the XML document data for breaches of o. Malformed
+// documents are left to the decoder to report.
+func (o ParseOptions) check(data []byte) error {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	depth, tokens, elements := 0, 0, 0
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return nil
+		}
+		if tokens++; o.MaxTokens > 0 && tokens > o.MaxTokens {
+			return fmt.Errorf("%w: more than %d tokens", ErrLimit, o.MaxTokens)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth++; o.MaxDepth > 0 && depth > o.MaxDepth {
+				return fmt.Errorf("%w: elements nested deeper than %d", ErrLimit, o.MaxDepth)
+			}
+			if elements++; o.MaxElements > 0 && elements > o.MaxElements {
+				return fmt.Errorf("%w: more than %d elements", ErrLimit, o.MaxElements)
+			}
+		case xml.EndElement:
+			depth--
+		case xml.Directive:
+			if !o.AllowDTD && bytes.HasPrefix(bytes.TrimSpace(t), []byte("DOCTYPE")) {
+				return ErrDTD
+			}
+		}
+	}
+}
+
+// ParseFile reads the diagram in the file path like Parse.
+func ParseFile(path string) (*GraphModel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+var (
+	bareAmpersand = regexp.MustCompile(`&([a-zA-Z][a-zA-Z0-9]*;|#[0-9]+;|#x[0-9a-fA-F]+;)?`)
+	invalidChars  = regexp.MustCompile("[\x00-\x08\x0b\x0c\x0e-\x1f]")
+)
+
+// ParseTolerant reads a diagram like a strict parser would, but
+// recovers from the malformations commonly found in files written by
+// third-party tools instead of failing: unescaped ampersands, control
+// characters, unquoted or valueless attributes and missing end tags.
+// The repairs made are returned along with the model; the error is
+// only set when the input cannot be recovered. Like Parse, the input
+// must stay within DefaultParseOptions.
+func ParseTolerant(r io.Reader) (*GraphModel, []Repair, error) {
+	return ParseTolerantWithOptions(r, DefaultParseOptions)
+}
+
+// ParseTolerantWithOptions reads a diagram like ParseTolerant, within
+// the limits of o. Documents breaking a limit are not repaired but
+// refused with an error wrapping ErrLimit, or ErrDTD.
+func ParseTolerantWithOptions(r io.Reader, o ParseOptions) (*GraphModel, []Repair, error) {
+	data, err := o.read(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("graw: ParseTolerant: %w", err)
+	}
+	if g, err := decodeModel(data, true, o); err == nil {
+		return g, nil, nil
+	} else if errors.Is(err, ErrLimit) {
+		return nil, nil, fmt.Errorf("graw: ParseTolerant: %w", err)
+	}
+
+	var repairs []Repair
+	data = replaceAll(data, bareAmpersand, func(m []byte) []byte {
+		if len(m) > 1 {
+			return m
+		}
+		return []byte("&amp;")
+	}, "escaped bare '&'", &repairs)
+	data = replaceAll(data, invalidChars, func([]byte) []byte {
+		return nil
+	}, "removed control character", &repairs)
+
+	if g, err := decodeModel(data, true, o); err == nil {
+		return g, repairs, nil
+	} else if errors.Is(err, ErrLimit) {
+		return nil, repairs, fmt.Errorf("graw: ParseTolerant: %w", err)
+	} else if syn, ok := err.(*xml.SyntaxError); ok {
+		repairs = append(repairs, Repair{Line: syn.Line, Problem: "relaxed parsing: " + syn.Msg})
+	} else {
+		repairs = append(repairs, Repair{Problem: "relaxed parsing: " + err.Error()})
+	}
+
+	g, err := decodeModel(data, false, o)
+	if err != nil {
+		return nil, repairs, fmt.Errorf("graw: ParseTolerant: %w", err)
+	}
+	return g, repairs, nil
+}
+
+// replaceAll replaces the matches of re in data by the result of fn,
+// recording a repair with the given problem for every match which was
+// changed.
+func replaceAll(data []byte, re *regexp.Regexp, fn func([]byte) []byte, problem string, repairs *[]Repair) []byte {
+	var out []byte
+	last := 0
+	for _, loc := range re.FindAllIndex(data, -1) {
+		m := data[loc[0]:loc[1]]
+		repl := fn(m)
+		if bytes.Equal(repl, m) {
+			continue
+		}
+		out = append(out, data[last:loc[0]]...)
+		out = append(out, repl...)
+		last = loc[1]
+		*repairs = append(*repairs, Repair{
+			Line:    bytes.Count(data[:loc[0]], []byte("\n")) + 1,
+			Problem: problem,
+		})
+	}
+	if out == nil {
+		return data
+	}
+	return append(out, data[last:]...)
+}
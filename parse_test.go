@@ -0,0 +1,179 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// parseSeeds are documents the fuzz targets start from.
+var parseSeeds = []string{
+	`<mxGraphModel><root><mxCell id="0"/><mxCell id="1" parent="0"/></root></mxGraphModel>`,
+	`<mxGraphModel dx="640" dy="480"><root><mxCell id="0"/><mxCell id="1" parent="0"/><mxCell id="a" value="A &amp; B" style="rounded=1;fillColor=#dae8fc;" parent="1" vertex="1"><mxGeometry x="10" y="20" width="120" height="60" as="geometry"/></mxCell><mxCell id="e" parent="1" source="a" target="a" edge="1"><mxGeometry relative="1" as="geometry"><Array as="points"><mxPoint x="5" y="5"/></Array></mxGeometry></mxCell></root></mxGraphModel>`,
+	`<mxGraphModel><root><mxCell id="0"/><UserObject label="x" link="https://example.com" id="u"><mxCell parent="0" vertex="1"/></UserObject></root></mxGraphModel>`,
+	`<mxfile><diagram name="p"><mxGraphModel><root><mxCell id="0"/></root></mxGraphModel></diagram></mxfile>`,
+	`<mxGraphModel><root><mxCell id="0" value="R&D"/><mxCell id=1 parent="0" collapsed/></root>`,
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, seed := range parseSeeds[:4] {
+		g, err := Parse(strings.NewReader(seed))
+		if err != nil {
+			t.Fatalf("Parse(%.40q): %v", seed, err)
+		}
+		checkRoundTrip(t, g)
+	}
+}
+
+func TestParseTolerant(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		repairs int
+		id      string
+		value   string
+	}{
+		{"well-formed", parseSeeds[1], 0, "a", "A & B"},
+		{"bare ampersand", `<mxGraphModel><root><mxCell id="0" value="R&D"/></root></mxGraphModel>`, 1, "0", "R&D"},
+		{"control character", "<mxGraphModel><root><mxCell id=\"0\" value=\"a\x01b\"/></root></mxGraphModel>", 1, "0", "ab"},
+		{"unquoted attribute", `<mxGraphModel><root><mxCell id=0 value=x /></root></mxGraphModel>`, 1, "0", "x"},
+		{"missing end tag", `<mxGraphModel><root><mxCell id="0" value="x"></root></mxGraphModel>`, 1, "0", "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, repairs, err := ParseTolerant(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(repairs) != tt.repairs {
+				t.Errorf("got repairs %v, want %d", repairs, tt.repairs)
+			}
+			if c := g.FindByID(tt.id); c == nil || c.Value != tt.value {
+				t.Errorf("got cell %+v, want value %q", c, tt.value)
+			}
+		})
+	}
+
+	if _, _, err := ParseTolerant(strings.NewReader("not xml")); err == nil {
+		t.Error("garbage: no error")
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	doc := parseSeeds[1]
+	tests := []struct {
+		name string
+		doc  string
+		o    ParseOptions
+		err  error
+	}{
+		{"defaults", doc, DefaultParseOptions, nil},
+		{"no limits", doc, ParseOptions{}, nil},
+		{"too large", doc, ParseOptions{MaxSize: len(doc) - 1}, ErrLimit},
+		{"just small enough", doc, ParseOptions{MaxSize: len(doc)}, nil},
+		{"too deep", doc, ParseOptions{MaxDepth: 5}, ErrLimit},
+		{"deep enough", doc, ParseOptions{MaxDepth: 6}, nil},
+		{"too many tokens", doc, ParseOptions{MaxTokens: 10}, ErrLimit},
+		{"too many elements", doc, ParseOptions{DecodeLimits: DecodeLimits{MaxElements: 9}}, ErrLimit},
+		{"enough elements", doc, ParseOptions{DecodeLimits: DecodeLimits{MaxElements: 10}}, nil},
+		{"DTD", `<!DOCTYPE mxGraphModel [<!ENTITY x "y">]>` + doc, DefaultParseOptions, ErrDTD},
+		{"DTD with space", ` <!DOCTYPE mxGraphModel>` + doc, DefaultParseOptions, ErrDTD},
+		{"DTD allowed", `<!DOCTYPE mxGraphModel>` + doc, ParseOptions{AllowDTD: true}, nil},
+		{"comment", `<!-- x -->` + doc, DefaultParseOptions, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := ParseWithOptions(strings.NewReader(tt.doc), tt.o)
+			if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("got %v, want %v", err, tt.err)
+			}
+			if err == nil && g.FindByID("a") == nil {
+				t.Error("got no cell a")
+			}
+		})
+	}
+
+	// entities are not expanded even when DTDs are allowed
+	_, err := ParseWithOptions(strings.NewReader(`<!DOCTYPE x [<!ENTITY e "boom">]><mxGraphModel><root><mxCell id="0" value="&e;"/></root></mxGraphModel>`), ParseOptions{AllowDTD: true})
+	if err == nil {
+		t.Error("got an entity expanded")
+	}
+}
+
+func TestParseTolerantLimits(t *testing.T) {
+	malformed := `<mxGraphModel><root><mxCell id="0" value="R&D"/>` + strings.Repeat("<mxCell/>", 20) + `</root></mxGraphModel>`
+	tests := []struct {
+		name string
+		doc  string
+		o    ParseOptions
+		err  error
+	}{
+		{"too large", malformed, ParseOptions{MaxSize: 100}, ErrLimit},
+		{"too many tokens", malformed, ParseOptions{MaxTokens: 10}, ErrLimit},
+		{"too many elements", malformed, ParseOptions{DecodeLimits: DecodeLimits{MaxElements: 10}}, ErrLimit},
+		{"too deep", strings.Repeat("<a>", 200), DefaultParseOptions, ErrLimit},
+		{"DTD", `<!DOCTYPE x [<!ENTITY a "b">]>` + malformed, DefaultParseOptions, ErrDTD},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseTolerantWithOptions(strings.NewReader(tt.doc), tt.o)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	big := `<mxGraphModel><root>` + strings.Repeat(`<mxCell value="&"/>`, 1<<20) + `</root></mxGraphModel>`
+	if _, _, err := ParseTolerant(strings.NewReader(big)); !errors.Is(err, ErrLimit) {
+		t.Errorf("ParseTolerant of %d bytes: got %v, want ErrLimit", len(big), err)
+	}
+}
+
+// checkRoundTrip fails t unless g marshals to a document which parses
+// back to a model marshalling to the same document.
+func checkRoundTrip(t *testing.T, g *GraphModel) {
+	t.Helper()
+	out, err := xml.Marshal(g)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	h, err := Parse(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("parse of marshalled model: %v\n%s", err, out)
+	}
+	again, err := xml.Marshal(h)
+	if err != nil {
+		t.Fatalf("marshal of parsed model: %v", err)
+	}
+	if !bytes.Equal(out, again) {
+		t.Errorf("round trip changed the model:\n%s\n%s", out, again)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, s := range parseSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		checkRoundTrip(t, g)
+	})
+}
+
+func FuzzParseTolerant(f *testing.F) {
+	for _, s := range parseSeeds {
+		f.Add([]byte(s))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		g, _, err := ParseTolerant(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		checkRoundTrip(t, g)
+	})
+}
@@ -0,0 +1,149 @@
+package graw
+
+import "strconv"
+
+// Partition splits the vertices of g into k parts of balanced sizes,
+// differing by one vertex at most, cutting as few edges as it can, e.g.
+// to show how a system would be split across k teams or regions. The
+// direction of the edges is ignored.
+//
+// Like METIS, it grows the parts greedily from seed vertices, then
+// refines them by moving and swapping vertices between parts as long
+// as fewer edges are cut. The result maps the ID of every vertex to
+// its part, from 0 to k-1, numbered in the order of their first
+// vertex; it is deterministic but not necessarily the best split.
+func Partition(g *GraphModel, k int) map[string]int {
+	nodes, adj := g.adjacency()
+	if k > len(nodes) {
+		k = len(nodes)
+	}
+	if k < 1 {
+		k = 1
+	}
+
+	// weight counts the edges between two vertices in either
+	// direction.
+	weight := make(map[string]map[string]int, len(nodes))
+	for _, n := range nodes {
+		weight[n] = make(map[string]int)
+	}
+	for _, n := range nodes {
+		for _, m := range adj[n] {
+			if m != n {
+				weight[n][m]++
+				weight[m][n]++
+			}
+		}
+	}
+
+	part := make(map[string]int, len(nodes))
+	sizes := make([]int, k)
+	minSize, maxSize := len(nodes)/k, (len(nodes)+k-1)/k
+	for p := 0; p < k; p++ {
+		want := minSize
+		if p < len(nodes)%k {
+			want++
+		}
+		// links counts the edges between each free vertex and the
+		// part; the most linked vertex joins next.
+		links := make(map[string]int)
+		for sizes[p] < want {
+			next, best := "", -1
+			for _, n := range nodes {
+				if _, ok := part[n]; !ok && links[n] > best {
+					next, best = n, links[n]
+				}
+			}
+			part[next] = p
+			sizes[p]++
+			for m, w := range weight[next] {
+				links[m] += w
+			}
+		}
+	}
+
+	// gain returns how many fewer edges are cut when n moves to part q.
+	gain := func(n string, q int) int {
+		d := 0
+		for m, w := range weight[n] {
+			switch part[m] {
+			case q:
+				d += w
+			case part[n]:
+				d -= w
+			}
+		}
+		return d
+	}
+	for pass := 0; pass < 2*len(nodes); pass++ {
+		improved := false
+		for _, n := range nodes {
+			p := part[n]
+			for q := 0; q < k; q++ {
+				if q == p {
+					continue
+				}
+				gn := gain(n, q)
+				if gn > 0 && sizes[q] < maxSize && sizes[p] > minSize {
+					part[n] = q
+					sizes[p]--
+					sizes[q]++
+					improved = true
+					break
+				}
+				swapped := false
+				for _, m := range nodes {
+					if part[m] == q && gn+gain(m, p)-2*weight[n][m] > 0 {
+						part[n], part[m] = q, p
+						improved, swapped = true, true
+						break
+					}
+				}
+				if swapped {
+					break
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	// Number the parts in the order of their first vertex.
+	order := make(map[int]int, k)
+	for _, n := range nodes {
+		if _, ok := order[part[n]]; !ok {
+			order[part[n]] = len(order)
+		}
+		part[n] = order[part[n]]
+	}
+	return part
+}
+
+// ColorPartitions fills every vertex of g listed in parts, as returned
+// by Partition or CommunityDetection, with the color of its part and
+// keeps the number of the part, from 1, as its custom data
+// "partition". Edges between vertices of different parts are dashed
+// and returned in document order, as they are the links a split would
+// cut.
+func ColorPartitions(g *GraphModel, parts map[string]int) []string {
+	for _, c := range g.Root {
+		if p, ok := parts[c.ID]; ok && c.isVertex() {
+			c.Style.set("fillColor", ganttColors[p%len(ganttColors)])
+			c.SetData("partition", strconv.Itoa(p+1))
+		}
+	}
+	var cut []string
+	for _, c := range g.Root {
+		if !c.isEdge() {
+			continue
+		}
+		ps, ok1 := parts[c.Source]
+		pt, ok2 := parts[c.Target]
+		if ok1 && ok2 && ps != pt {
+			c.Style.set("dashed", "1")
+			cut = append(cut, c.ID)
+		}
+	}
+	return cut
+}
@@ -0,0 +1,85 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+// twoTriangles returns a graph of the triangles a-b-c and d-e-f linked
+// by the edge c-d.
+func twoTriangles() GraphModel {
+	return newTestGraph(
+		[2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "a"},
+		[2]string{"d", "e"}, [2]string{"e", "f"}, [2]string{"f", "d"},
+		[2]string{"c", "d"},
+	)
+}
+
+func TestPartition(t *testing.T) {
+	tests := []struct {
+		name  string
+		g     GraphModel
+		k     int
+		want  map[string]int
+		sizes []int
+	}{
+		{"two triangles", twoTriangles(), 2, map[string]int{"a": 0, "b": 0, "c": 0, "d": 1, "e": 1, "f": 1}, nil},
+		{"one part", twoTriangles(), 1, map[string]int{"a": 0, "b": 0, "c": 0, "d": 0, "e": 0, "f": 0}, nil},
+		{"k below one", twoTriangles(), 0, map[string]int{"a": 0, "b": 0, "c": 0, "d": 0, "e": 0, "f": 0}, nil},
+		{"k above vertices", newTestGraph([2]string{"a", "b"}), 5, map[string]int{"a": 0, "b": 1}, nil},
+		{"empty", NewGraph(), 3, map[string]int{}, nil},
+		{"uneven", newTestGraph(
+			[2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "d"},
+			[2]string{"d", "e"}, [2]string{"e", "f"}, [2]string{"f", "g"},
+		), 3, nil, []int{3, 2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Partition(&tt.g, tt.k)
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.sizes != nil {
+				sizes := make([]int, len(tt.sizes))
+				for _, p := range got {
+					sizes[p]++
+				}
+				if !reflect.DeepEqual(sizes, tt.sizes) {
+					t.Errorf("got sizes %v, want %v", sizes, tt.sizes)
+				}
+			}
+		})
+	}
+}
+
+func TestColorPartitions(t *testing.T) {
+	g := twoTriangles()
+	g.Add(NewShape("x", "1"))
+	cut := ColorPartitions(&g, map[string]int{"a": 0, "b": 0, "c": 0, "d": 1, "e": 1, "f": 8})
+	if want := []string{"e-f", "f-d", "c-d"}; !reflect.DeepEqual(cut, want) {
+		t.Errorf("got cut %v, want %v", cut, want)
+	}
+	tests := []struct {
+		id, fill, data string
+	}{
+		{"a", "#dae8fc", "1"},
+		{"d", "#d5e8d4", "2"},
+		{"f", "#d5e8d4", "9"},
+		{"x", "", ""},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if got := c.Style.Attributes["fillColor"]; got != tt.fill {
+			t.Errorf("%s: got fillColor %q, want %q", tt.id, got, tt.fill)
+		}
+		if got := c.Data["partition"]; got != tt.data {
+			t.Errorf("%s: got partition %q, want %q", tt.id, got, tt.data)
+		}
+	}
+	if got := g.FindByID("a-b").Style.Attributes["dashed"]; got != "" {
+		t.Errorf("got uncut edge dashed %q, want none", got)
+	}
+	if got := g.FindByID("c-d").Style.Attributes["dashed"]; got != "1" {
+		t.Errorf("got cut edge dashed %q, want %q", got, "1")
+	}
+}
@@ -0,0 +1,253 @@
+package graw
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCycle is returned by algorithms which require an acyclic
+	// graph when the graph contains a cycle.
+	ErrCycle = errors.New("graw: graph contains a cycle")
+	// ErrNoPath is returned when no path connects two cells.
+	ErrNoPath = errors.New("graw: no path between cells")
+)
+
+// CriticalPathStyle is the style applied by HighlightCriticalPath: a
+// thick red outline and bold text.
+var CriticalPathStyle = map[string]string{
+	"strokeColor": "#ff0000",
+	"strokeWidth": "3",
+	"fontStyle":   "1",
+}
+
+// Path is a route through a graph model.
+type Path struct {
+	// Vertices lists the IDs of the visited vertices in order.
+	Vertices []string
+	// Edges lists the IDs of the edges taken, Edges[i] leading from
+	// Vertices[i] to Vertices[i+1].
+	Edges []string
+	// Length is the sum of the weights of Edges.
+	Length float64
+}
+
+// Apply merges style into the style of every vertex and edge on p.
+func (p Path) Apply(g *GraphModel, style map[string]string) {
+	on := make(map[string]bool, len(p.Vertices)+len(p.Edges))
+	for _, id := range p.Vertices {
+		on[id] = true
+	}
+	for _, id := range p.Edges {
+		on[id] = true
+	}
+	for i := range g.Root {
+		c := g.Root[i]
+		if !on[c.ID] {
+			continue
+		}
+		for k, v := range style {
+			c.Style.set(k, v)
+		}
+	}
+}
+
+// Overlay copies the vertices and edges on p onto a new layer named
+// name and applies style to the copies, leaving the original cells
+// untouched. Hiding the layer in draw.io hides the highlighted route.
+// Overlay returns the ID of the new layer.
+func (p Path) Overlay(g *GraphModel, name string, style map[string]string) string {
+	taken := g.idSet()
+	layerID := taken.unique("overlay-" + name)
+	layer := NewLayer(layerID, name)
+
+	copies := make(map[string]string, len(p.Vertices))
+	cells := []*Cell{layer}
+	for _, id := range p.Vertices {
+		orig := g.cell(id)
+		if orig == nil {
+			continue
+		}
+		c := orig.clone()
+		c.ID = taken.unique(layerID + "-" + id)
+		c.ParentID = layerID
+		if c.Geometry != nil {
+			c.Geometry.X, c.Geometry.Y = g.absolutePosition(orig)
+		}
+		copies[id] = c.ID
+		cells = append(cells, c)
+	}
+	for _, id := range p.Edges {
+		orig := g.cell(id)
+		if orig == nil {
+			continue
+		}
+		c := orig.clone()
+		c.ID = taken.unique(layerID + "-" + id)
+		c.ParentID = layerID
+		if s, ok := copies[c.Source]; ok {
+			c.Source = s
+		}
+		if t, ok := copies[c.Target]; ok {
+			c.Target = t
+		}
+		cells = append(cells, c)
+	}
+
+	for i := 1; i < len(cells); i++ {
+		for k, v := range style {
+			cells[i].Style.set(k, v)
+		}
+	}
+	g.Root = append(g.Root, cells...)
+	return layerID
+}
+
+// HighlightPath finds the shortest directed path from the vertex
+// fromID to the vertex toID, counting edges, and merges style into
+// the style of its vertices and edges. Use Path.Overlay on the result
+// to additionally draw the route on a separate layer. ErrNoPath is
+// returned when toID cannot be reached from fromID.
+func HighlightPath(g *GraphModel, fromID, toID string, style map[string]string) (Path, error) {
+	out := g.outEdges()
+	via := map[string]*Cell{fromID: nil}
+	queue := []string{fromID}
+	for len(queue) > 0 && !hasKey(via, toID) {
+		v := queue[0]
+		queue = queue[1:]
+		for _, e := range out[v] {
+			if !hasKey(via, e.Target) {
+				via[e.Target] = e
+				queue = append(queue, e.Target)
+			}
+		}
+	}
+	if !hasKey(via, toID) || g.cell(fromID) == nil {
+		return Path{}, ErrNoPath
+	}
+
+	var p Path
+	for v := toID; ; {
+		p.Vertices = append([]string{v}, p.Vertices...)
+		e := via[v]
+		if e == nil {
+			break
+		}
+		p.Edges = append([]string{e.ID}, p.Edges...)
+		p.Length++
+		v = e.Source
+	}
+	p.Apply(g, style)
+	return p, nil
+}
+
+// hasKey reports whether m contains key.
+func hasKey(m map[string]*Cell, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// EdgeValueWeight returns the label of e parsed as a number, or 1
+// when the label is not numeric. It is the default weight used by
+// FindCriticalPath.
+func EdgeValueWeight(e *Cell) float64 {
+	w, err := strconv.ParseFloat(strings.TrimSpace(e.Value), 64)
+	if err != nil {
+		return 1
+	}
+	return w
+}
+
+// FindCriticalPath returns the longest path through the directed
+// acyclic graph g, where the length of an edge is given by weight.
+// A nil weight uses EdgeValueWeight. ErrCycle is returned when g is
+// not acyclic.
+func FindCriticalPath(g *GraphModel, weight func(e *Cell) float64) (Path, error) {
+	if weight == nil {
+		weight = EdgeValueWeight
+	}
+	order, err := g.topoSort()
+	if err != nil {
+		return Path{}, err
+	}
+	if len(order) == 0 {
+		return Path{}, nil
+	}
+
+	out := g.outEdges()
+	dist := make(map[string]float64, len(order))
+	via := make(map[string]*Cell, len(order))
+	for _, v := range order {
+		for _, e := range out[v] {
+			if d := dist[v] + weight(e); via[e.Target] == nil || d > dist[e.Target] {
+				dist[e.Target] = d
+				via[e.Target] = e
+			}
+		}
+	}
+
+	end := order[0]
+	for _, v := range order {
+		if dist[v] > dist[end] {
+			end = v
+		}
+	}
+
+	p := Path{Length: dist[end]}
+	for v := end; ; {
+		p.Vertices = append([]string{v}, p.Vertices...)
+		e := via[v]
+		if e == nil {
+			break
+		}
+		p.Edges = append([]string{e.ID}, p.Edges...)
+		v = e.Source
+	}
+	return p, nil
+}
+
+// HighlightCriticalPath finds the critical path of g as
+// FindCriticalPath does and applies CriticalPathStyle to it.
+func HighlightCriticalPath(g *GraphModel, weight func(e *Cell) float64) (Path, error) {
+	p, err := FindCriticalPath(g, weight)
+	if err != nil {
+		return p, err
+	}
+	p.Apply(g, CriticalPathStyle)
+	return p, nil
+}
+
+// topoSort returns the vertex IDs of g in topological order, keeping
+// document order among independent vertices.
+func (g *GraphModel) topoSort() ([]string, error) {
+	nodes, adj := g.adjacency()
+	indegree := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		for _, m := range adj[n] {
+			indegree[m]++
+		}
+	}
+
+	var queue, order []string
+	for _, n := range nodes {
+		if indegree[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		order = append(order, n)
+		for _, m := range adj[n] {
+			if indegree[m]--; indegree[m] == 0 {
+				queue = append(queue, m)
+			}
+		}
+	}
+
+	if len(order) != len(nodes) {
+		return nil, ErrCycle
+	}
+	return order, nil
+}
@@ -0,0 +1,173 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+// weightedGraph returns newTestGraph(edges...) with the edges labeled
+// with weights.
+func weightedGraph(weights []string, edges ...[2]string) GraphModel {
+	g := newTestGraph(edges...)
+	for i, e := range edges {
+		g.FindByID(e[0] + "-" + e[1]).Value = weights[i]
+	}
+	return g
+}
+
+func TestFindCriticalPath(t *testing.T) {
+	ab, bd, ac, cd := [2]string{"a", "b"}, [2]string{"b", "d"}, [2]string{"a", "c"}, [2]string{"c", "d"}
+	for _, test := range []struct {
+		name    string
+		g       GraphModel
+		weight  func(e *Cell) float64
+		want    Path
+		wantErr error
+	}{
+		{
+			name: "edge values",
+			g:    weightedGraph([]string{"2", "1", "1", "5"}, ab, bd, ac, cd),
+			want: Path{Vertices: []string{"a", "c", "d"}, Edges: []string{"a-c", "c-d"}, Length: 6},
+		},
+		{
+			name: "non-numeric values count 1",
+			g:    weightedGraph([]string{"x", "y", "", " 3 "}, ab, bd, ac, cd),
+			want: Path{Vertices: []string{"a", "c", "d"}, Edges: []string{"a-c", "c-d"}, Length: 4},
+		},
+		{
+			name:   "custom weight",
+			g:      weightedGraph([]string{"2", "1", "1", "5"}, ab, bd, ac, cd),
+			weight: func(e *Cell) float64 { return 1 },
+			want:   Path{Vertices: []string{"a", "b", "d"}, Edges: []string{"a-b", "b-d"}, Length: 2},
+		},
+		{
+			name: "single vertex",
+			g: func() GraphModel {
+				g := NewGraph()
+				g.Add(NewShape("a", "1"))
+				return g
+			}(),
+			want: Path{Vertices: []string{"a"}},
+		},
+		{
+			name: "empty",
+			g:    NewGraph(),
+		},
+		{
+			name:    "cycle",
+			g:       newTestGraph(ab, [2]string{"b", "a"}),
+			wantErr: ErrCycle,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := FindCriticalPath(&test.g, test.weight)
+			if err != test.wantErr {
+				t.Fatalf("got error %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(p, test.want) {
+				t.Errorf("got %+v, want %+v", p, test.want)
+			}
+		})
+	}
+}
+
+func TestHighlightCriticalPath(t *testing.T) {
+	g := weightedGraph([]string{"2", "1", "1", "5"}, [2]string{"a", "b"}, [2]string{"b", "d"}, [2]string{"a", "c"}, [2]string{"c", "d"})
+	if _, err := HighlightCriticalPath(&g, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		id   string
+		want bool
+	}{
+		{"a", true}, {"c", true}, {"d", true}, {"a-c", true}, {"c-d", true},
+		{"b", false}, {"a-b", false}, {"b-d", false},
+	} {
+		c := g.FindByID(test.id)
+		if got := c.Style.Attributes["strokeColor"] == CriticalPathStyle["strokeColor"]; got != test.want {
+			t.Errorf("got %s highlighted %v, want %v", test.id, got, test.want)
+		}
+	}
+}
+
+func TestHighlightPath(t *testing.T) {
+	edges := [][2]string{{"a", "b"}, {"b", "d"}, {"a", "c"}, {"c", "e"}, {"e", "d"}}
+	for _, test := range []struct {
+		name     string
+		from, to string
+		want     Path
+		wantErr  error
+	}{
+		{"shortest", "a", "d", Path{Vertices: []string{"a", "b", "d"}, Edges: []string{"a-b", "b-d"}, Length: 2}, nil},
+		{"itself", "c", "c", Path{Vertices: []string{"c"}}, nil},
+		{"against the edges", "d", "a", Path{}, ErrNoPath},
+		{"missing vertex", "x", "a", Path{}, ErrNoPath},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := newTestGraph(edges...)
+			style := map[string]string{"strokeColor": "#0000ff"}
+			p, err := HighlightPath(&g, test.from, test.to, style)
+			if err != test.wantErr {
+				t.Fatalf("got error %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(p, test.want) {
+				t.Errorf("got %+v, want %+v", p, test.want)
+			}
+			on := make(map[string]bool)
+			for _, id := range append(p.Vertices, p.Edges...) {
+				on[id] = true
+			}
+			for _, c := range g.Root {
+				if got := c.Style.Attributes["strokeColor"] == "#0000ff"; got != on[c.ID] {
+					t.Errorf("got %s highlighted %v, want %v", c.ID, got, on[c.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestPathOverlay(t *testing.T) {
+	g := newTestGraph([2]string{"a", "b"}, [2]string{"b", "c"})
+	g.FindByID("b").At(100, 50)
+	p, err := HighlightPath(&g, "a", "b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := len(g.Root)
+	layer := p.Overlay(&g, "route", map[string]string{"opacity": "50"})
+	if layer != "overlay-route" {
+		t.Errorf("got layer %q, want overlay-route", layer)
+	}
+	if got, want := len(g.Root), before+4; got != want {
+		t.Fatalf("got %d cells, want %d", got, want)
+	}
+	if g.FindByID("b").Style.Attributes["opacity"] != "" {
+		t.Errorf("got the original cell styled")
+	}
+	for _, test := range []struct {
+		id, source, target string
+		x, y               int
+	}{
+		{id: "overlay-route-a", x: 10, y: 10},
+		{id: "overlay-route-b", x: 100, y: 50},
+		{id: "overlay-route-a-b", source: "overlay-route-a", target: "overlay-route-b"},
+	} {
+		c := g.FindByID(test.id)
+		if c == nil {
+			t.Errorf("got no cell %s", test.id)
+			continue
+		}
+		if c.ParentID != layer || c.Style.Attributes["opacity"] != "50" {
+			t.Errorf("got %s in %s with style %v", c.ID, c.ParentID, c.Style.Attributes)
+		}
+		if c.Source != test.source || c.Target != test.target {
+			t.Errorf("got %s from %q to %q, want %q to %q", c.ID, c.Source, c.Target, test.source, test.target)
+		}
+		if c.isVertex() && (c.Geometry.X != test.x || c.Geometry.Y != test.y) {
+			t.Errorf("got %s at %d,%d, want %d,%d", c.ID, c.Geometry.X, c.Geometry.Y, test.x, test.y)
+		}
+	}
+	if second := p.Overlay(&g, "route", nil); second != "overlay-route-2" {
+		t.Errorf("got second layer %q, want overlay-route-2", second)
+	}
+}
@@ -0,0 +1,460 @@
+package graw
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// qrModuleSize is the size in pixels of a module, a dark or light
+// square, of the QR codes created by NewQRCode.
+const qrModuleSize = 4
+
+// qrQuietZone is the number of light modules around a QR code.
+const qrQuietZone = 4
+
+// qrECCPerBlock and qrBlocks are the error correction codewords per
+// block and the number of blocks of each QR code version, 1 to 40, at
+// error correction level M.
+var (
+	qrECCPerBlock = [41]int{0,
+		10, 16, 26, 18, 24, 16, 18, 22, 22, 26,
+		30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+		26, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+		28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+	qrBlocks = [41]int{0,
+		1, 1, 1, 2, 2, 4, 4, 4, 5, 5,
+		5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+		17, 17, 18, 20, 21, 23, 25, 26, 28, 29,
+		31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+// NewQRCode returns an image Vertex Cell, configured with the given
+// unique ID (id) and parent ID (layerId), showing a QR code of content,
+// e.g. the URL of a dashboard or of the editable diagram. The code is
+// encoded in byte mode with error correction level M, in the smallest
+// version that fits, and embedded as an SVG data URI. An error is
+// returned when content exceeds the 2331 bytes a QR code holds.
+func NewQRCode(id, layerId, content string) (*Cell, error) {
+	modules, err := encodeQR([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("graw: NewQRCode: %v", err)
+	}
+	size := len(modules) + 2*qrQuietZone
+
+	var path strings.Builder
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 ` + strconv.Itoa(size) + " " + strconv.Itoa(size) +
+		`" shape-rendering="crispEdges"><rect width="100%" height="100%" fill="#ffffff"/><path fill="#000000" d="` +
+		path.String() + `"/></svg>`
+
+	// draw.io leaves out ";base64", ";" separating style keys
+	c := NewImage(id, layerId, "data:image/svg+xml,"+base64.StdEncoding.EncodeToString([]byte(svg)))
+	c.Style.set("imageAspect", "1")
+	c.Geometry.setSize(size*qrModuleSize, size*qrModuleSize)
+	c.Data = map[string]string{"tooltip": content}
+	return c, nil
+}
+
+// qrCode is a QR code symbol being drawn.
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// encodeQR returns the modules of the QR code holding data in byte
+// mode, row by row, true for dark modules.
+func encodeQR(data []byte) ([][]bool, error) {
+	version := 1
+	for ; version <= 40; version++ {
+		countBits := 8
+		if version >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*qrDataCodewords(version) {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, fmt.Errorf("%d bytes do not fit in a QR code", len(data))
+	}
+
+	// the data codewords: mode, length, data, terminator and padding
+	var bits []bool
+	appendBits := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v>>uint(i)&1 != 0)
+		}
+	}
+	appendBits(4, 4)
+	if version >= 10 {
+		appendBits(len(data), 16)
+	} else {
+		appendBits(len(data), 8)
+	}
+	for _, b := range data {
+		appendBits(int(b), 8)
+	}
+	capacity := 8 * qrDataCodewords(version)
+	appendBits(0, minInt(4, capacity-len(bits)))
+	appendBits(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		appendBits(pad, 8)
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i, b := range bits {
+		if b {
+			codewords[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+
+	q := newQRCode(version)
+	q.drawCodewords(qrInterleave(version, codewords))
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormat(mask)
+		if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		q.applyMask(mask)
+	}
+	q.applyMask(best)
+	q.drawFormat(best)
+	return q.modules, nil
+}
+
+// qrRawModules returns the number of modules of a QR code version
+// holding data and error correction codewords.
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// qrDataCodewords returns the number of data codewords of a QR code
+// version at error correction level M.
+func qrDataCodewords(version int) int {
+	return qrRawModules(version)/8 - qrECCPerBlock[version]*qrBlocks[version]
+}
+
+// qrInterleave splits the data codewords into blocks, adds the error
+// correction codewords of each and returns the blocks interleaved.
+func qrInterleave(version int, data []byte) []byte {
+	blocks, eccLen := qrBlocks[version], qrECCPerBlock[version]
+	raw := qrRawModules(version) / 8
+	short := blocks - raw%blocks
+	shortLen := raw / blocks
+	divisor := rsDivisor(eccLen)
+
+	var all [][]byte
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen - eccLen
+		if i >= short {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < short {
+			block = append(block, 0)
+		}
+		all = append(all, append(block, ecc...))
+	}
+	var out []byte
+	for i := range all[0] {
+		for j, block := range all {
+			// short blocks have a gap where long ones hold more data
+			if i != shortLen-eccLen || j >= short {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of degree,
+// without its leading term, highest powers first.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 2)
+	}
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of
+// data for divisor.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies x and y in GF(2^8) modulo x^8+x^4+x^3+x^2+1.
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11d
+		z ^= int(y>>uint(i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// newQRCode returns a QR code of version with its function patterns
+// drawn: finder, timing and alignment patterns, room for the format
+// and the version.
+func newQRCode(version int) *qrCode {
+	q := &qrCode{size: 4*version + 17}
+	q.modules = make([][]bool, q.size)
+	q.function = make([][]bool, q.size)
+	for i := range q.modules {
+		q.modules[i] = make([]bool, q.size)
+		q.function[i] = make([]bool, q.size)
+	}
+
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+	for _, c := range [][2]int{{3, 3}, {q.size - 4, 3}, {3, q.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x >= 0 && x < q.size && y >= 0 && y < q.size {
+					d := maxInt(absInt(dx), absInt(dy))
+					q.setFunction(x, y, d != 2 && d != 4)
+				}
+			}
+		}
+	}
+	if version > 1 {
+		align := version/7 + 2
+		step := (version*8 + align*3 + 5) / (align*4 - 4) * 2
+		pos := make([]int, align)
+		pos[0] = 6
+		for i := align - 1; i >= 1; i-- {
+			pos[i] = q.size - 7 - (align-1-i)*step
+		}
+		for i := range pos {
+			for j := range pos {
+				if i == 0 && j == 0 || i == 0 && j == align-1 || i == align-1 && j == 0 {
+					continue
+				}
+				for dy := -2; dy <= 2; dy++ {
+					for dx := -2; dx <= 2; dx++ {
+						q.setFunction(pos[i]+dx, pos[j]+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+					}
+				}
+			}
+		}
+	}
+	q.drawFormat(0)
+	if version >= 7 {
+		rem := version
+		for i := 0; i < 12; i++ {
+			rem = rem<<1 ^ (rem>>11)*0x1f25
+		}
+		bits := version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := bits>>uint(i)&1 != 0
+			a, b := q.size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+	return q
+}
+
+// setFunction sets the function module at (x, y).
+func (q *qrCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// drawFormat draws both copies of the format information for level M
+// and mask.
+func (q *qrCode) drawFormat(mask int) {
+	data := mask // level M is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>uint(i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// drawCodewords places the bits of data in the zigzag order of QR
+// codes, in pairs of columns from the bottom right corner, skipping
+// function modules.
+func (q *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y][x] && i < len(data)*8 {
+					q.modules[y][x] = data[i/8]>>uint(7-i%8)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by mask; applying a mask
+// twice undoes it.
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.function[y][x] {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty returns the penalty score of the QR code, by which the mask
+// making the code easiest to scan is chosen: runs of modules of one
+// color, blocks of one color, patterns looking like finders and an
+// imbalance of dark and light modules count against it.
+func (q *qrCode) penalty() int {
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+	finder := []bool{true, false, true, true, true, false, true}
+	score := 0
+	for _, vertical := range []bool{false, true} {
+		for y := 0; y < q.size; y++ {
+			run := 1
+			for x := 1; x <= q.size; x++ {
+				if x < q.size && at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += 3 + run - 5
+				}
+				run = 1
+			}
+			for x := 0; x+7 <= q.size; x++ {
+				match := true
+				for i, dark := range finder {
+					if at(x+i, y, vertical) != dark {
+						match = false
+						break
+					}
+				}
+				if match && (q.light(x-4, x, y, vertical) || q.light(x+7, x+11, y, vertical)) {
+					score += 40
+				}
+			}
+		}
+	}
+	dark := 0
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if q.modules[y][x+1] == c && q.modules[y+1][x] == c && q.modules[y+1][x+1] == c {
+					score += 3
+				}
+			}
+		}
+	}
+	total := q.size * q.size
+	return score + ((absInt(dark*20-total*10)+total-1)/total-1)*10
+}
+
+// light reports whether the modules from to to, exclusive, of row y,
+// or of column y if vertical, are light; modules outside the code
+// count as light.
+func (q *qrCode) light(from, to, y int, vertical bool) bool {
+	for x := from; x < to; x++ {
+		if x < 0 || x >= q.size {
+			continue
+		}
+		if vertical && q.modules[x][y] || !vertical && q.modules[y][x] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// the example of ISO/IEC 18004 annex I: "01234567" in version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestQRDataCodewords(t *testing.T) {
+	tests := []struct {
+		version, want int
+	}{
+		{1, 16},
+		{5, 86},
+		{10, 216},
+		{40, 2334},
+	}
+	for _, tt := range tests {
+		if got := qrDataCodewords(tt.version); got != tt.want {
+			t.Errorf("version %d: got %d, want %d", tt.version, got, tt.want)
+		}
+	}
+}
+
+// qrBit returns 1 for a dark module and 0 for a light one.
+func qrBit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
+
+// decodeQR reads back the content of the QR code m, checking its
+// format information, function patterns and error correction.
+func decodeQR(t *testing.T, m [][]bool) []byte {
+	t.Helper()
+	size := len(m)
+	version := (size - 17) / 4
+	q := newQRCode(version)
+	get := func(x, y int) int { return qrBit(m[y][x]) }
+
+	// the first copy of the format information
+	var format int
+	for i := 0; i <= 5; i++ {
+		format |= get(8, i) << uint(i)
+	}
+	format |= get(8, 7)<<6 | get(8, 8)<<7 | get(7, 8)<<8
+	for i := 9; i < 15; i++ {
+		format |= get(14-i, 8) << uint(i)
+	}
+	format ^= 0x5412
+	if level := format >> 13; level != 0 {
+		t.Fatalf("got error correction level %b, want M", level)
+	}
+	mask := format >> 10 & 7
+	rem := format >> 10
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	if rem != format&0x3ff {
+		t.Fatal("bad format BCH code")
+	}
+	var second int
+	for i := 0; i < 8; i++ {
+		second |= get(size-1-i, 8) << uint(i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= get(8, size-15+i) << uint(i)
+	}
+	if second^0x5412 != format {
+		t.Fatal("the copies of the format information differ")
+	}
+
+	for y := range m {
+		for x := range m {
+			if q.function[y][x] && y != 8 && x != 8 && q.modules[y][x] != m[y][x] {
+				t.Fatalf("function module %d,%d differs", x, y)
+			}
+		}
+	}
+
+	// the codewords, in the zigzag order they are drawn in
+	q.modules = make([][]bool, size)
+	for y := range m {
+		q.modules[y] = append([]bool(nil), m[y]...)
+	}
+	q.applyMask(mask)
+	var raw []byte
+	var cur byte
+	n := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = size - 1 - vert
+				}
+				if q.function[y][x] {
+					continue
+				}
+				cur = cur<<1 | byte(qrBit(q.modules[y][x]))
+				if n++; n%8 == 0 {
+					raw = append(raw, cur)
+					cur = 0
+				}
+			}
+		}
+	}
+
+	// deinterleaved into blocks, the short ones first
+	blocks, eccLen := qrBlocks[version], qrECCPerBlock[version]
+	total := qrRawModules(version) / 8
+	raw = raw[:total]
+	short, shortLen := blocks-total%blocks, total/blocks
+	bl := make([][]byte, blocks)
+	k := 0
+	for i := 0; i < shortLen+1; i++ {
+		for j := 0; j < blocks; j++ {
+			if i == shortLen-eccLen && j < short {
+				continue
+			}
+			bl[j] = append(bl[j], raw[k])
+			k++
+		}
+	}
+	var data []byte
+	for j, b := range bl {
+		dl := len(b) - eccLen
+		if !bytes.Equal(rsRemainder(b[:dl], rsDivisor(eccLen)), b[dl:]) {
+			t.Fatalf("block %d: bad error correction", j)
+		}
+		data = append(data, b[:dl]...)
+	}
+
+	read := func(pos, n int) int {
+		v := 0
+		for i := pos; i < pos+n; i++ {
+			v = v<<1 | int(data[i/8]>>uint(7-i%8))&1
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 4 {
+		t.Fatalf("got mode %d, want byte mode", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	out := make([]byte, read(4, countBits))
+	for i := range out {
+		out[i] = byte(read(4+countBits+8*i, 8))
+	}
+	return out
+}
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct {
+		n, version int
+	}{
+		{0, 1},
+		{14, 1},
+		{15, 2},
+		{84, 5},
+		{85, 6},
+		{300, 13},
+		{1000, 26},
+		{2331, 40},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			s := strings.Repeat("https://grafana.example.com/d/", 100)[:tt.n]
+			m, err := encodeQR([]byte(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := (len(m) - 17) / 4; got != tt.version {
+				t.Errorf("got version %d, want %d", got, tt.version)
+			}
+			if got := string(decodeQR(t, m)); got != s {
+				t.Errorf("got %q, want %q", got, s)
+			}
+		})
+	}
+}
+
+func TestNewQRCode(t *testing.T) {
+	const url = "https://example.com/d/abc?orgId=1"
+	c, err := NewQRCode("q", "1", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Data["tooltip"] != url {
+		t.Errorf("got tooltip %q, want %q", c.Data["tooltip"], url)
+	}
+	image := c.Style.Attributes["image"]
+	if !strings.HasPrefix(image, "data:image/svg+xml,") {
+		t.Fatalf("got image %.40q, want an SVG data URI", image)
+	}
+	svg, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(image, "data:image/svg+xml,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the dark modules are squares of the path, offset by the quiet zone
+	w, _ := c.Geometry.size()
+	size := w/qrModuleSize - 2*qrQuietZone
+	m := make([][]bool, size)
+	for y := range m {
+		m[y] = make([]bool, size)
+	}
+	for _, sm := range regexp.MustCompile(`M(\d+) (\d+)h1v1h-1z`).FindAllSubmatch(svg, -1) {
+		var x, y int
+		fmt.Sscan(string(sm[1]), &x)
+		fmt.Sscan(string(sm[2]), &y)
+		m[y-qrQuietZone][x-qrQuietZone] = true
+	}
+	if got := string(decodeQR(t, m)); got != url {
+		t.Errorf("got %q, want %q", got, url)
+	}
+
+	if _, err := NewQRCode("q", "1", strings.Repeat("a", 2332)); err == nil {
+		t.Error("NewQRCode with 2332 bytes: got no error")
+	}
+}
@@ -0,0 +1,129 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+)
+
+// Dimensions of quadrant charts.
+const (
+	quadrantSize     = 400
+	quadrantAxisSize = 30
+	quadrantDotSize  = 12
+)
+
+// QuadrantItem is an item plotted on a quadrant chart by its scores.
+type QuadrantItem struct {
+	Label string
+	X, Y  float64
+}
+
+// QuadrantOptions configures NewQuadrant.
+type QuadrantOptions struct {
+	// XAxis and YAxis name the scores, shown along the axes.
+	XAxis, YAxis string
+	// Quadrants are the labels of the top left, top right, bottom left
+	// and bottom right quadrants, e.g. "Quick wins" for low effort and
+	// high value.
+	Quadrants [4]string
+	// XMin, XMax, YMin and YMax are the ranges of the axes, which are
+	// split in the middle. Empty ranges are those of the items,
+	// widened by a tenth on both sides.
+	XMin, XMax, YMin, YMax float64
+}
+
+// NewQuadrant returns a 2×2 quadrant chart: four labeled quadrants,
+// the axes named below and left of them, and a dot per item, labeled
+// on its right, at its X and Y scores. Items outside the ranges are
+// drawn on their edges.
+func NewQuadrant(items []QuadrantItem, opts QuadrantOptions) (*GraphModel, error) {
+	for _, it := range items {
+		if math.IsNaN(it.X) || math.IsNaN(it.Y) || math.IsInf(it.X, 0) || math.IsInf(it.Y, 0) {
+			return nil, fmt.Errorf("graw: NewQuadrant: item %q has scores %v, %v", it.Label, it.X, it.Y)
+		}
+	}
+	if opts.XMin > opts.XMax || opts.YMin > opts.YMax {
+		return nil, fmt.Errorf("graw: NewQuadrant: empty range")
+	}
+	if opts.XMin == opts.XMax {
+		opts.XMin, opts.XMax = quadrantRange(items, func(it QuadrantItem) float64 { return it.X })
+	}
+	if opts.YMin == opts.YMax {
+		opts.YMin, opts.YMax = quadrantRange(items, func(it QuadrantItem) float64 { return it.Y })
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	x0, y0 := 10+quadrantAxisSize, 10
+	half := quadrantSize / 2
+	for i, label := range opts.Quadrants {
+		c := NewShape(taken.unique("quadrant-"+strconv.Itoa(i+1)), rootCellID)
+		c.Value = "<b>" + html.EscapeString(label) + "</b>"
+		c.Style = Style{Attributes: shapeStyle("")}
+		c.Style.set("fillColor", []string{"#dae8fc", "#d5e8d4", "#f5f5f5", "#fff2cc"}[i])
+		c.Style.set("strokeColor", "#666666")
+		c.Style.set("verticalAlign", "top")
+		c.Style.set("fontColor", "#666666")
+		c.Geometry.X, c.Geometry.Y = x0+i%2*half, y0+i/2*half
+		c.Geometry.setSize(half, half)
+		g.Add(c)
+	}
+
+	x := NewShape(taken.unique("x-axis"), rootCellID)
+	x.Value = html.EscapeString(opts.XAxis) + " →"
+	x.Style = Style{Attributes: shapeStyle("text")}
+	x.Geometry.X, x.Geometry.Y = x0, y0+quadrantSize
+	x.Geometry.setSize(quadrantSize, quadrantAxisSize)
+	g.Add(x)
+	// the y axis label is rotated about its center, so it is laid out
+	// horizontally centered on the left of the chart
+	y := NewShape(taken.unique("y-axis"), rootCellID)
+	y.Value = html.EscapeString(opts.YAxis) + " →"
+	y.Style = Style{Attributes: shapeStyle("text")}
+	y.Style.set("rotation", "-90")
+	y.Geometry.X, y.Geometry.Y = x0-half-quadrantAxisSize/2, y0+half-quadrantAxisSize/2
+	y.Geometry.setSize(quadrantSize, quadrantAxisSize)
+	g.Add(y)
+
+	scale := func(v, min, max float64) int {
+		t := (v - min) / (max - min)
+		return int(math.Round(math.Max(0, math.Min(1, t)) * quadrantSize))
+	}
+	for _, it := range items {
+		c := NewShape(taken.unique("item-"+it.Label), rootCellID)
+		c.Value = html.EscapeString(it.Label)
+		c.Style = Style{Attributes: shapeStyle("ellipse")}
+		c.Style.set("fillColor", "#6c8ebf")
+		c.Style.set("strokeColor", "#ffffff")
+		c.Style.set("labelPosition", "right")
+		c.Style.set("verticalLabelPosition", "middle")
+		c.Style.set("align", "left")
+		c.Style.set("verticalAlign", "middle")
+		c.Style.set("whiteSpace", "nowrap")
+		c.Geometry.X = x0 + scale(it.X, opts.XMin, opts.XMax) - quadrantDotSize/2
+		c.Geometry.Y = y0 + quadrantSize - scale(it.Y, opts.YMin, opts.YMax) - quadrantDotSize/2
+		c.Geometry.setSize(quadrantDotSize, quadrantDotSize)
+		c.Data = map[string]string{"tooltip": fmt.Sprintf("%s (%v, %v)", it.Label, it.X, it.Y)}
+		g.Add(c)
+	}
+	return &g, nil
+}
+
+// quadrantRange returns the range of the scores score returns for
+// items, widened by a tenth on both sides, or 0 to 1 without items.
+func quadrantRange(items []QuadrantItem, score func(QuadrantItem) float64) (float64, float64) {
+	if len(items) == 0 {
+		return 0, 1
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, it := range items {
+		min, max = math.Min(min, score(it)), math.Max(max, score(it))
+	}
+	pad := (max - min) / 10
+	if pad == 0 {
+		pad = math.Max(math.Abs(min)/10, 1)
+	}
+	return min - pad, max + pad
+}
@@ -0,0 +1,118 @@
+package graw
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewQuadrant(t *testing.T) {
+	x0, y0 := 10+quadrantAxisSize, 10
+	tests := []struct {
+		name    string
+		items   []QuadrantItem
+		opts    QuadrantOptions
+		centers map[string][2]int
+	}{
+		{
+			name: "ranges",
+			items: []QuadrantItem{
+				{"top left", 0, 10},
+				{"middle", 5, 5},
+				{"outside", 20, -5},
+			},
+			opts: QuadrantOptions{XMax: 10, YMax: 10},
+			centers: map[string][2]int{
+				"item-top left": {x0, y0},
+				"item-middle":   {x0 + 200, y0 + 200},
+				"item-outside":  {x0 + 400, y0 + 400},
+			},
+		},
+		{
+			name:  "items range",
+			items: []QuadrantItem{{"a", 1, 2}, {"b", 3, 2}},
+			centers: map[string][2]int{
+				"item-a": {x0 + 33, y0 + 200},
+				"item-b": {x0 + 367, y0 + 200},
+			},
+		},
+		{
+			name:    "duplicate labels",
+			items:   []QuadrantItem{{"a", 0, 0}, {"a", 1, 1}},
+			opts:    QuadrantOptions{XMax: 1, YMax: 1},
+			centers: map[string][2]int{"item-a": {x0, y0 + 400}, "item-a-2": {x0 + 400, y0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewQuadrant(tt.items, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for id, want := range tt.centers {
+				c := g.FindByID(id)
+				if c == nil {
+					t.Errorf("%s: missing", id)
+					continue
+				}
+				got := [2]int{c.Geometry.X + quadrantDotSize/2, c.Geometry.Y + quadrantDotSize/2}
+				if got != want {
+					t.Errorf("%s: got center %v, want %v", id, got, want)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewQuadrantLabels(t *testing.T) {
+	g, err := NewQuadrant([]QuadrantItem{{"x & y", 1, 2}}, QuadrantOptions{
+		XAxis:     "Effort",
+		YAxis:     "Value",
+		Quadrants: [4]string{"Quick wins", "Big bets", "Fill-ins", "Money pits"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{
+		"quadrant-1": "<b>Quick wins</b>",
+		"quadrant-2": "<b>Big bets</b>",
+		"quadrant-3": "<b>Fill-ins</b>",
+		"quadrant-4": "<b>Money pits</b>",
+		"x-axis":     "Effort →",
+		"y-axis":     "Value →",
+		"item-x & y": "x &amp; y",
+	} {
+		if c := g.FindByID(id); c == nil || c.Value != want {
+			t.Errorf("%s: got %v, want %q", id, c, want)
+		}
+	}
+	if got := g.FindByID("item-x & y").Data["tooltip"]; got != "x & y (1, 2)" {
+		t.Errorf("got tooltip %q", got)
+	}
+	// the rotated y axis label is centered on the left of the chart
+	y := g.FindByID("y-axis")
+	w, h := y.Geometry.size()
+	if cx, cy := y.Geometry.X+w/2, y.Geometry.Y+h/2; cx != 10+quadrantAxisSize/2 || cy != 10+quadrantSize/2 {
+		t.Errorf("got y axis center %d,%d", cx, cy)
+	}
+}
+
+func TestNewQuadrantErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []QuadrantItem
+		opts  QuadrantOptions
+	}{
+		{"nan", []QuadrantItem{{"a", math.NaN(), 0}}, QuadrantOptions{}},
+		{"inf", []QuadrantItem{{"a", 0, math.Inf(1)}}, QuadrantOptions{}},
+		{"x range", nil, QuadrantOptions{XMin: 1}},
+		{"y range", nil, QuadrantOptions{YMin: 1}},
+	}
+	for _, tt := range tests {
+		if _, err := NewQuadrant(tt.items, tt.opts); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package graw
+
+// Limits of the quadtree: a node splits once it holds more than
+// quadMaxItems items, unless it is quadMaxDepth levels deep.
+const (
+	quadMaxItems = 8
+	quadMaxDepth = 12
+)
+
+// quadItem is a rectangle stored in a quadtree.
+type quadItem struct {
+	id     string
+	bounds Rect
+}
+
+// quadtree indexes rectangles for fast overlap queries.
+type quadtree struct {
+	bounds   Rect
+	depth    int
+	items    []quadItem
+	children []*quadtree
+}
+
+// newQuadtree returns an empty quadtree covering bounds. Items
+// outside bounds can still be inserted; they stay at the root.
+func newQuadtree(bounds Rect) *quadtree {
+	return &quadtree{bounds: bounds}
+}
+
+// insert adds the rectangle r identified by id.
+func (q *quadtree) insert(id string, r Rect) {
+	it := quadItem{id: id, bounds: r}
+	for q.children != nil {
+		child := q.childFor(r)
+		if child == nil {
+			break
+		}
+		q = child
+	}
+	q.items = append(q.items, it)
+
+	if q.children == nil && len(q.items) > quadMaxItems && q.depth < quadMaxDepth {
+		q.split()
+	}
+}
+
+// childFor returns the child completely containing r, or nil.
+func (q *quadtree) childFor(r Rect) *quadtree {
+	for _, c := range q.children {
+		if c.bounds.Contains(r) {
+			return c
+		}
+	}
+	return nil
+}
+
+// split creates the four children of q and moves the items fitting
+// into one of them down.
+func (q *quadtree) split() {
+	hw, hh := q.bounds.Width/2, q.bounds.Height/2
+	if hw == 0 || hh == 0 {
+		return
+	}
+	b := q.bounds
+	for _, r := range []Rect{
+		{b.X, b.Y, hw, hh},
+		{b.X + hw, b.Y, b.Width - hw, hh},
+		{b.X, b.Y + hh, hw, b.Height - hh},
+		{b.X + hw, b.Y + hh, b.Width - hw, b.Height - hh},
+	} {
+		q.children = append(q.children, &quadtree{bounds: r, depth: q.depth + 1})
+	}
+
+	items := q.items
+	q.items = nil
+	for _, it := range items {
+		if child := q.childFor(it.bounds); child != nil {
+			child.insert(it.id, it.bounds)
+		} else {
+			q.items = append(q.items, it)
+		}
+	}
+}
+
+// query calls fn for every item overlapping r until fn returns
+// false. It reports whether the walk completed.
+func (q *quadtree) query(r Rect, fn func(quadItem) bool) bool {
+	for _, it := range q.items {
+		if it.bounds.Intersects(r) && !fn(it) {
+			return false
+		}
+	}
+	for _, c := range q.children {
+		if c.bounds.Intersects(r) && !c.query(r, fn) {
+			return false
+		}
+	}
+	return true
+}
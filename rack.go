@@ -0,0 +1,175 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+)
+
+// Dimensions of rack views, matching the draw.io rack cabinet shape.
+const (
+	rackWidth        = 180
+	rackUnitHeight   = 15
+	rackMarginLeft   = 33
+	rackMarginRight  = 9
+	rackMarginTop    = 21
+	rackMarginBottom = 22
+	rackBarHeight    = 20
+)
+
+// Rack is a rack cabinet.
+type Rack struct {
+	Name string
+	// Units is the height of the rack in rack units, 42 if zero.
+	Units int
+}
+
+// RackDevice is a device mounted in a rack.
+type RackDevice struct {
+	Name string
+	Rack string
+	// Position is the lowest unit the device takes, from 1 at the
+	// bottom of the rack.
+	Position int
+	// Units is the height of the device in rack units, 1 if zero.
+	Units int
+	// Kind is the kind of the device, e.g. "server" or "switch";
+	// devices are colored by kind.
+	Kind string
+}
+
+// NewRackReport returns a capacity report of racks: a draw.io rack
+// cabinet per rack with its devices mounted, and below it a bar of the
+// units used by each kind of device and the units left free, labeled
+// with the share of the rack used. Devices and their bar segments are
+// colored alike by kind.
+func NewRackReport(racks []Rack, devices []RackDevice) (*GraphModel, error) {
+	if len(racks) == 0 {
+		return nil, fmt.Errorf("graw: NewRackReport: no racks")
+	}
+	racks = append([]Rack(nil), racks...)
+	index := make(map[string]int)
+	for i, r := range racks {
+		if _, ok := index[r.Name]; ok {
+			return nil, fmt.Errorf("graw: NewRackReport: duplicate rack %q", r.Name)
+		}
+		index[r.Name] = i
+		if racks[i].Units <= 0 {
+			racks[i].Units = 42
+		}
+	}
+	byRack := make([][]RackDevice, len(racks))
+	colors := make(map[string]string)
+	var kinds []string
+	for _, d := range devices {
+		i, ok := index[d.Rack]
+		if !ok {
+			return nil, fmt.Errorf("graw: NewRackReport: device %q in unknown rack %q", d.Name, d.Rack)
+		}
+		if d.Units <= 0 {
+			d.Units = 1
+		}
+		if d.Position < 1 || d.Position+d.Units-1 > racks[i].Units {
+			return nil, fmt.Errorf("graw: NewRackReport: device %q does not fit in rack %q", d.Name, d.Rack)
+		}
+		for _, o := range byRack[i] {
+			if d.Position < o.Position+o.Units && o.Position < d.Position+d.Units {
+				return nil, fmt.Errorf("graw: NewRackReport: devices %q and %q overlap in rack %q", o.Name, d.Name, d.Rack)
+			}
+		}
+		byRack[i] = append(byRack[i], d)
+		if _, ok := colors[d.Kind]; !ok {
+			colors[d.Kind] = ganttColors[len(colors)%len(ganttColors)]
+			kinds = append(kinds, d.Kind)
+		}
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	for i, r := range racks {
+		x := 10 + i*(rackWidth+groupGap)
+		h := rackMarginTop + r.Units*rackUnitHeight + rackMarginBottom
+		rack := NewShape(taken.unique("rack-"+r.Name), rootCellID)
+		rack.Value = "<b>" + html.EscapeString(r.Name) + "</b>"
+		rack.Style = Style{Attributes: map[string]string{
+			"shape":                 "mxgraph.rackGeneral.rackCabinet3",
+			"html":                  "1",
+			"strokeColor":           "#666666",
+			"fillColor2":            "#f4f4f4",
+			"verticalLabelPosition": "bottom",
+			"verticalAlign":         "top",
+			"container":             "1",
+			"collapsible":           "0",
+			"childLayout":           "rack",
+			"allowGaps":             "1",
+			"marginLeft":            strconv.Itoa(rackMarginLeft),
+			"marginRight":           strconv.Itoa(rackMarginRight),
+			"marginTop":             strconv.Itoa(rackMarginTop),
+			"marginBottom":          strconv.Itoa(rackMarginBottom),
+			"textColor":             "#666666",
+			"numDisp":               "ascend",
+			"rackUnitSize":          strconv.Itoa(rackUnitHeight),
+		}}
+		rack.Geometry.X, rack.Geometry.Y = x, 10
+		rack.Geometry.setSize(rackWidth, h)
+		g.Add(rack)
+
+		used := make(map[string]int)
+		total := 0
+		for _, d := range byRack[i] {
+			c := NewShape(taken.unique(rack.ID+"-"+d.Name), rack.ID)
+			c.Value = html.EscapeString(d.Name)
+			c.Style = Style{Attributes: shapeStyle("")}
+			c.Style.set("fillColor", colors[d.Kind])
+			c.Style.set("fontSize", "10")
+			c.Geometry.X = rackMarginLeft
+			c.Geometry.Y = rackMarginTop + (r.Units-d.Position-d.Units+1)*rackUnitHeight
+			c.Geometry.setSize(rackWidth-rackMarginLeft-rackMarginRight, d.Units*rackUnitHeight)
+			c.Data = map[string]string{"tooltip": fmt.Sprintf("%s: U%d–U%d", d.Name, d.Position, d.Position+d.Units-1)}
+			if d.Kind != "" {
+				c.Data["tooltip"] += " (" + d.Kind + ")"
+			}
+			g.Add(c)
+			used[d.Kind] += d.Units
+			total += d.Units
+		}
+
+		// the usage bar, below the name of the rack
+		y := 10 + h + 30
+		bx := x
+		for _, k := range kinds {
+			if used[k] == 0 {
+				continue
+			}
+			s := NewShape(taken.unique(rack.ID+"-usage-"+k), rootCellID)
+			s.Style = Style{Attributes: shapeStyle("")}
+			s.Style.set("fillColor", colors[k])
+			s.Geometry.X, s.Geometry.Y = bx, y
+			w := rackWidth * used[k] / r.Units
+			s.Geometry.setSize(w, rackBarHeight)
+			s.Data = map[string]string{"tooltip": fmt.Sprintf("%s: %dU", k, used[k])}
+			if k == "" {
+				s.Data["tooltip"] = fmt.Sprintf("%dU", used[k])
+			}
+			g.Add(s)
+			bx += w
+		}
+		free := NewShape(taken.unique(rack.ID+"-usage-free"), rootCellID)
+		free.Style = Style{Attributes: shapeStyle("")}
+		free.Style.set("fillColor", "#ffffff")
+		free.Style.set("strokeColor", "#999999")
+		free.Geometry.X, free.Geometry.Y = bx, y
+		free.Geometry.setSize(x+rackWidth-bx, rackBarHeight)
+		free.Data = map[string]string{"tooltip": fmt.Sprintf("free: %dU", r.Units-total)}
+		g.Add(free)
+
+		label := NewShape(taken.unique(rack.ID+"-usage"), rootCellID)
+		label.Value = fmt.Sprintf("%d/%dU used (%d%%)", total, r.Units, 100*total/r.Units)
+		label.Style = Style{Attributes: shapeStyle("text")}
+		label.Style.set("fontSize", "10")
+		label.Geometry.X, label.Geometry.Y = x, y+rackBarHeight
+		label.Geometry.setSize(rackWidth, rackBarHeight)
+		g.Add(label)
+	}
+	return &g, nil
+}
@@ -0,0 +1,86 @@
+package graw
+
+import "testing"
+
+func TestNewRackReport(t *testing.T) {
+	g, err := NewRackReport([]Rack{{Name: "a", Units: 10}, {Name: "b"}}, []RackDevice{
+		{Name: "web", Rack: "a", Position: 1, Units: 2, Kind: "server"},
+		{Name: "sw", Rack: "a", Position: 10, Kind: "switch"},
+		{Name: "db", Rack: "b", Position: 20, Units: 4, Kind: "server"},
+		{Name: "pdu", Rack: "b", Position: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bx := 10 + rackWidth + groupGap
+	// the usage bars are below the name of their rack
+	by := 10 + rackMarginTop + 10*rackUnitHeight + rackMarginBottom + 30
+	bby := 10 + rackMarginTop + 42*rackUnitHeight + rackMarginBottom + 30
+	tests := []struct {
+		id      string
+		x, y    int
+		w, h    int
+		tooltip string
+	}{
+		{"rack-a-web", rackMarginLeft, rackMarginTop + 8*rackUnitHeight, rackWidth - rackMarginLeft - rackMarginRight, 2 * rackUnitHeight, "web: U1–U2 (server)"},
+		{"rack-a-sw", rackMarginLeft, rackMarginTop, rackWidth - rackMarginLeft - rackMarginRight, rackUnitHeight, "sw: U10–U10 (switch)"},
+		{"rack-b-db", rackMarginLeft, rackMarginTop + 19*rackUnitHeight, rackWidth - rackMarginLeft - rackMarginRight, 4 * rackUnitHeight, "db: U20–U23 (server)"},
+		{"rack-b-pdu", rackMarginLeft, rackMarginTop + 41*rackUnitHeight, rackWidth - rackMarginLeft - rackMarginRight, rackUnitHeight, "pdu: U1–U1"},
+		{"rack-a-usage-server", 10, by, 36, rackBarHeight, "server: 2U"},
+		{"rack-a-usage-switch", 46, by, 18, rackBarHeight, "switch: 1U"},
+		{"rack-a-usage-free", 64, by, 126, rackBarHeight, "free: 7U"},
+		{"rack-b-usage-server", bx, bby, 17, rackBarHeight, "server: 4U"},
+		{"rack-b-usage-", bx + 17, bby, 4, rackBarHeight, "1U"},
+		{"rack-b-usage-free", bx + 21, bby, 159, rackBarHeight, "free: 37U"},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Errorf("%s: missing", tt.id)
+			continue
+		}
+		w, h := c.Geometry.size()
+		if c.Geometry.X != tt.x || c.Geometry.Y != tt.y || w != tt.w || h != tt.h {
+			t.Errorf("%s: got %d,%d %dx%d, want %d,%d %dx%d", tt.id, c.Geometry.X, c.Geometry.Y, w, h, tt.x, tt.y, tt.w, tt.h)
+		}
+		if c.Data["tooltip"] != tt.tooltip {
+			t.Errorf("%s: got tooltip %q, want %q", tt.id, c.Data["tooltip"], tt.tooltip)
+		}
+	}
+	for id, want := range map[string]string{
+		"rack-a-usage": "3/10U used (30%)",
+		"rack-b-usage": "5/42U used (11%)",
+	} {
+		if c := g.FindByID(id); c == nil || c.Value != want {
+			t.Errorf("%s: got %v, want %q", id, c, want)
+		}
+	}
+	// devices of a kind are colored alike in every rack
+	if a, b := g.FindByID("rack-a-web"), g.FindByID("rack-b-db"); a.Style.Attributes["fillColor"] != b.Style.Attributes["fillColor"] {
+		t.Errorf("got colors %q and %q, want them alike", a.Style.Attributes["fillColor"], b.Style.Attributes["fillColor"])
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewRackReportErrors(t *testing.T) {
+	a := []Rack{{Name: "a", Units: 10}}
+	tests := []struct {
+		name    string
+		racks   []Rack
+		devices []RackDevice
+	}{
+		{"no racks", nil, nil},
+		{"duplicate rack", []Rack{{Name: "a"}, {Name: "a"}}, nil},
+		{"unknown rack", a, []RackDevice{{Name: "d", Rack: "b", Position: 1}}},
+		{"position", a, []RackDevice{{Name: "d", Rack: "a"}}},
+		{"too high", a, []RackDevice{{Name: "d", Rack: "a", Position: 9, Units: 3}}},
+		{"overlap", a, []RackDevice{{Name: "d", Rack: "a", Position: 2, Units: 2}, {Name: "e", Rack: "a", Position: 3}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewRackReport(tt.racks, tt.devices); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
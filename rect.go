@@ -0,0 +1,90 @@
+package graw
+
+// Rect is an axis aligned rectangle, e.g. the bounds of a shape.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// GeometryRect returns the rectangle of geo: its position and its size,
+// defaulting to defaultWidth and defaultHeight. The position is
+// relative to the parent of the cell; see BoundsOf for the position
+// on the layer.
+func GeometryRect(geo *Geometry) Rect {
+	w, h := geo.size()
+	return Rect{geo.X, geo.Y, w, h}
+}
+
+// BoundsOf returns the rectangle of the vertex c of g relative to its
+// layer, adding up the offsets of the containers it is nested in.
+func (g *GraphModel) BoundsOf(c *Cell) Rect {
+	if c.Geometry == nil {
+		return Rect{}
+	}
+	r := GeometryRect(c.Geometry)
+	r.X, r.Y = g.absolutePosition(c)
+	return r
+}
+
+// Apply sets the position and the size of geo to those of r.
+func (r Rect) Apply(geo *Geometry) {
+	geo.X, geo.Y = r.X, r.Y
+	geo.setSize(r.Width, r.Height)
+}
+
+// Empty reports whether r has no area.
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Center returns the center of r.
+func (r Rect) Center() (int, int) {
+	return r.X + r.Width/2, r.Y + r.Height/2
+}
+
+// Union returns the smallest rectangle containing r and o. An empty
+// rectangle adds nothing to the other one.
+func (r Rect) Union(o Rect) Rect {
+	if r.Empty() {
+		return o
+	}
+	if o.Empty() {
+		return r
+	}
+	x0, y0 := minInt(r.X, o.X), minInt(r.Y, o.Y)
+	x1, y1 := maxInt(r.X+r.Width, o.X+o.Width), maxInt(r.Y+r.Height, o.Y+o.Height)
+	return Rect{x0, y0, x1 - x0, y1 - y0}
+}
+
+// Intersect returns the largest rectangle contained in both r and o,
+// the zero Rect when they do not overlap.
+func (r Rect) Intersect(o Rect) Rect {
+	x0, y0 := maxInt(r.X, o.X), maxInt(r.Y, o.Y)
+	x1, y1 := minInt(r.X+r.Width, o.X+o.Width), minInt(r.Y+r.Height, o.Y+o.Height)
+	if x1 <= x0 || y1 <= y0 {
+		return Rect{}
+	}
+	return Rect{x0, y0, x1 - x0, y1 - y0}
+}
+
+// Inset returns r shrunk by d on every side, or grown for a negative
+// d, e.g. to add padding around shapes.
+func (r Rect) Inset(d int) Rect {
+	return Rect{r.X + d, r.Y + d, r.Width - 2*d, r.Height - 2*d}
+}
+
+// Intersects reports whether r and o overlap.
+func (r Rect) Intersects(o Rect) bool {
+	return r.X < o.X+o.Width && o.X < r.X+r.Width && r.Y < o.Y+o.Height && o.Y < r.Y+r.Height
+}
+
+// Contains reports whether o lies completely inside r.
+func (r Rect) Contains(o Rect) bool {
+	return o.X >= r.X && o.Y >= r.Y && o.X+o.Width <= r.X+r.Width && o.Y+o.Height <= r.Y+r.Height
+}
+
+// ContainsPoint reports whether (x, y) lies inside r, borders
+// included.
+func (r Rect) ContainsPoint(x, y int) bool {
+	return x >= r.X && x <= r.X+r.Width && y >= r.Y && y <= r.Y+r.Height
+}
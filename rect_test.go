@@ -0,0 +1,97 @@
+package graw
+
+import "testing"
+
+func TestRect(t *testing.T) {
+	a := Rect{0, 0, 100, 50}
+	b := Rect{50, 25, 100, 50}
+	far := Rect{200, 200, 10, 10}
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"empty", a.Empty(), false},
+		{"empty zero", Rect{}.Empty(), true},
+		{"empty negative", Rect{0, 0, -5, 10}.Empty(), true},
+		{"center", func() [2]int { x, y := b.Center(); return [2]int{x, y} }(), [2]int{100, 50}},
+		{"union", a.Union(b), Rect{0, 0, 150, 75}},
+		{"union empty", a.Union(Rect{500, 500, 0, 0}), a},
+		{"union of empty", Rect{}.Union(b), b},
+		{"intersect", a.Intersect(b), Rect{50, 25, 50, 25}},
+		{"intersect apart", a.Intersect(far), Rect{}},
+		{"intersect touching", a.Intersect(Rect{100, 0, 10, 10}), Rect{}},
+		{"inset", a.Inset(10), Rect{10, 10, 80, 30}},
+		{"outset", a.Inset(-5), Rect{-5, -5, 110, 60}},
+		{"intersects", a.Intersects(b), true},
+		{"intersects apart", a.Intersects(far), false},
+		{"intersects touching", a.Intersects(Rect{100, 0, 10, 10}), false},
+		{"contains", a.Contains(Rect{10, 10, 90, 40}), true},
+		{"contains itself", a.Contains(a), true},
+		{"contains overlapping", a.Contains(b), false},
+		{"contains point", a.ContainsPoint(100, 50), true},
+		{"contains point outside", a.ContainsPoint(101, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometryRect(t *testing.T) {
+	tests := []struct {
+		name string
+		geo  Geometry
+		want Rect
+	}{
+		{"sized", Geometry{X: 10, Y: 20, Width: "30", Height: "40"}, Rect{10, 20, 30, 40}},
+		{"default size", Geometry{X: 5, Y: 5}, Rect{5, 5, defaultWidth, defaultHeight}},
+		{"fractional", Geometry{Width: "30.6", Height: "40.2"}, Rect{0, 0, 31, 40}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GeometryRect(&tt.geo); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			geo := &Geometry{}
+			tt.want.Apply(geo)
+			if got := GeometryRect(geo); got != tt.want {
+				t.Errorf("got %v after Apply, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoundsOf(t *testing.T) {
+	g := NewGraph()
+	outer := NewShape("outer", "1")
+	outer.Geometry.X, outer.Geometry.Y = 100, 200
+	inner := NewShape("inner", "outer")
+	inner.Geometry.X, inner.Geometry.Y = 10, 20
+	leaf := NewShape("leaf", "inner")
+	leaf.Geometry.X, leaf.Geometry.Y = 1, 2
+	leaf.Geometry.Width, leaf.Geometry.Height = "30", "40"
+	layer := NewLayer("2", "Top")
+	for _, c := range []*Cell{outer, inner, leaf, layer} {
+		g.Add(c)
+	}
+	tests := []struct {
+		cell *Cell
+		want Rect
+	}{
+		{outer, Rect{100, 200, 120, 60}},
+		{inner, Rect{110, 220, 120, 60}},
+		{leaf, Rect{111, 222, 30, 40}},
+		{layer, Rect{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cell.ID, func(t *testing.T) {
+			if got := g.BoundsOf(tt.cell); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
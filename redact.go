@@ -0,0 +1,141 @@
+package graw
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A RedactRule masks sensitive text in the labels, tooltips, links,
+// custom data, styles and IDs of the cells of a diagram.
+type RedactRule struct {
+	// Pattern matches the sensitive text.
+	Pattern *regexp.Regexp
+	// Replacement replaces every match of Pattern; $1 and ${name}
+	// refer to submatches as in regexp.Regexp.Expand. "[redacted]"
+	// if empty.
+	Replacement string
+	// Strip removes the whole label or value containing a match
+	// instead of masking the match.
+	Strip bool
+}
+
+// Rules for common sensitive text.
+var (
+	// RedactIPs masks IPv4 addresses and CIDR blocks.
+	RedactIPs = RedactRule{
+		Pattern:     regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?:/\d{1,2})?\b`),
+		Replacement: "x.x.x.x",
+	}
+	// RedactAccountIDs masks 12 digit AWS account IDs, alone or in
+	// ARNs.
+	RedactAccountIDs = RedactRule{
+		Pattern:     regexp.MustCompile(`\b\d{12}\b`),
+		Replacement: "************",
+	}
+	// RedactEmails masks e-mail addresses.
+	RedactEmails = RedactRule{
+		Pattern:     regexp.MustCompile(`\b[\w.%+-]+@[\w-]+(?:\.[\w-]+)+\b`),
+		Replacement: "user@example.com",
+	}
+)
+
+// RedactHosts returns a rule masking the host names in domain and its
+// subdomains, e.g. "db1.corp.example.com" for "corp.example.com".
+func RedactHosts(domain string) RedactRule {
+	return RedactRule{
+		Pattern:     regexp.MustCompile(`(?i)\b(?:[a-z0-9-]+\.)*` + regexp.QuoteMeta(strings.TrimPrefix(domain, ".")) + `\b`),
+		Replacement: "host.example",
+	}
+}
+
+// Redact returns a copy of g fit for sharing outside: rules are
+// applied in order to the labels of its cells, to their custom data,
+// which holds their tooltips, links and metadata, and to their style
+// values, which hold the URLs of images, and to the background image.
+// Data and style values emptied by a rule are removed. g itself is
+// left untouched.
+//
+// Importers such as NewSubnetTree or NewRoutingTopology derive the IDs
+// of cells from their data, e.g. CIDR blocks or router IDs, so IDs a
+// rule matches are replaced too: by their redacted form, made unique
+// with a numeric suffix like "x.x.x.x-2", or by "redacted" for rules
+// stripping them. The parents, sources and targets referring to them
+// are updated.
+func Redact(g *GraphModel, rules []RedactRule) GraphModel {
+	cp := g.clone()
+	cp.BackgroundImage = redactText(cp.BackgroundImage, rules)
+
+	// ids maps the IDs a rule matches to their replacement.
+	ids := make(map[string]string)
+	taken := cp.idSet()
+	for _, c := range cp.Root {
+		if _, ok := ids[c.ID]; ok || c.ID == "" {
+			continue
+		}
+		if r := redactText(c.ID, rules); r != c.ID {
+			if r == "" {
+				r = "redacted"
+			}
+			ids[c.ID] = taken.unique(r)
+		}
+	}
+	rename := func(id *string) {
+		if r, ok := ids[*id]; ok {
+			*id = r
+		}
+	}
+
+	for i := range cp.Root {
+		c := cp.Root[i]
+		rename(&c.ID)
+		rename(&c.ParentID)
+		rename(&c.Source)
+		rename(&c.Target)
+		c.Value = redactText(c.Value, rules)
+		for _, k := range sortedKeys(c.Data) {
+			if v := redactText(c.Data[k], rules); v != "" {
+				c.Data[k] = v
+			} else {
+				delete(c.Data, k)
+			}
+		}
+		if len(c.Data) == 0 && !c.Placeholders {
+			c.Data = nil
+		}
+		for _, k := range sortedKeys(c.Style.Attributes) {
+			v := c.Style.Attributes[k]
+			if v == "" {
+				// A named style.
+				continue
+			}
+			if v = redactText(v, rules); v != "" {
+				c.Style.Attributes[k] = v
+			} else {
+				delete(c.Style.Attributes, k)
+			}
+		}
+	}
+	cp.reindex()
+	return cp
+}
+
+// redactText returns s with rules applied in order.
+func redactText(s string, rules []RedactRule) string {
+	for _, r := range rules {
+		if s == "" {
+			break
+		}
+		if r.Pattern == nil || !r.Pattern.MatchString(s) {
+			continue
+		}
+		if r.Strip {
+			return ""
+		}
+		repl := r.Replacement
+		if repl == "" {
+			repl = "[redacted]"
+		}
+		s = r.Pattern.ReplaceAllString(s, repl)
+	}
+	return s
+}
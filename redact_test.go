@@ -0,0 +1,131 @@
+package graw
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactText(t *testing.T) {
+	tests := []struct {
+		rules []RedactRule
+		in    string
+		want  string
+	}{
+		{[]RedactRule{RedactIPs}, "db at 10.0.1.5", "db at x.x.x.x"},
+		{[]RedactRule{RedactIPs}, "subnet 10.0.1.0/24", "subnet x.x.x.x"},
+		{[]RedactRule{RedactAccountIDs}, "arn:aws:iam::123456789012:role/x", "arn:aws:iam::************:role/x"},
+		{[]RedactRule{RedactEmails}, "owner: jane.doe@corp.com", "owner: user@example.com"},
+		{[]RedactRule{RedactHosts("corp.example.com")}, "https://db1.corp.example.com/x", "https://host.example/x"},
+		{[]RedactRule{{Pattern: regexp.MustCompile(`secret`), Strip: true}}, "a secret", ""},
+		{[]RedactRule{{Pattern: regexp.MustCompile(`k(\d)`)}}, "k1", "[redacted]"},
+		{[]RedactRule{{Pattern: regexp.MustCompile(`k(\d)`), Replacement: "n$1"}}, "k1", "n1"},
+		{[]RedactRule{RedactIPs}, "nothing", "nothing"},
+	}
+	for _, tt := range tests {
+		if got := redactText(tt.in, tt.rules); got != tt.want {
+			t.Errorf("redactText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// redactLeaks fails t when the XML of g contains any of leaks.
+func redactLeaks(t *testing.T, g *GraphModel, leaks ...string) {
+	t.Helper()
+	out, err := xml.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range leaks {
+		if strings.Contains(string(out), l) {
+			t.Errorf("redacted model contains %q:\n%s", l, out)
+		}
+	}
+}
+
+// edgeEnds returns the labels of the ends of the edges of g, so that
+// models can be compared whatever their IDs.
+func edgeEnds(g *GraphModel) []string {
+	var ends []string
+	for _, c := range g.Root {
+		if c.isEdge() {
+			ends = append(ends, g.FindByID(c.Source).Value+" -> "+g.FindByID(c.Target).Value)
+		}
+	}
+	return ends
+}
+
+func TestRedactSubnetTree(t *testing.T) {
+	g, err := NewSubnetTree([]Subnet{
+		{CIDR: "10.0.0.0/16", Name: "vpc"},
+		{CIDR: "10.0.1.0/24", Name: "a", Gateway: "10.0.1.1", Used: 3},
+		{CIDR: "10.0.2.0/24", Name: "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Redact(g, []RedactRule{RedactIPs})
+	redactLeaks(t, &r, "10.0.")
+	if errs := r.Validate(); len(errs) > 0 {
+		t.Errorf("redacted model invalid: %v", errs)
+	}
+	if len(r.Root) != len(g.Root) || len(r.DuplicateIDs()) > 0 {
+		t.Errorf("got %d cells, duplicates %v; want %d cells", len(r.Root), r.DuplicateIDs(), len(g.Root))
+	}
+	if n := len(edgeEnds(&r)); n != 2 {
+		t.Errorf("got %d edges, want 2", n)
+	}
+	if g.FindByID("10.0.1.0/24") == nil {
+		t.Error("original model changed")
+	}
+}
+
+func TestRedactRoutingTopology(t *testing.T) {
+	g, err := NewRoutingTopology([]Router{
+		{ID: "192.168.0.1", Name: "edge", Neighbors: []RoutingNeighbor{{ID: "192.168.0.2"}}},
+		{ID: "192.168.0.2", Name: "core"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Redact(g, []RedactRule{RedactIPs})
+	redactLeaks(t, &r, "192.168.")
+	if errs := r.Validate(); len(errs) > 0 {
+		t.Errorf("redacted model invalid: %v", errs)
+	}
+	want, got := edgeEnds(g), edgeEnds(&r)
+	if len(got) != len(want) {
+		t.Fatalf("got edges %q, want as many as %q", got, want)
+	}
+	for i := range got {
+		if redactText(want[i], []RedactRule{RedactIPs}) != got[i] {
+			t.Errorf("edge %d: got %q, want %q redacted", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRedactStyles(t *testing.T) {
+	g := NewGraph()
+	g.Add(NewImage("logo", "1", "https://cdn.corp.example.com/logo.png"))
+	g.Add(NewShape("a", "1").Styled("fillColor", "#dae8fc").Styled("rounded", ""))
+	g.BackgroundImage = `{"src":"https://cdn.corp.example.com/bg.png","width":10,"height":10}`
+
+	r := Redact(&g, []RedactRule{RedactHosts("corp.example.com")})
+	redactLeaks(t, &r, "corp.example.com")
+	if got := r.FindByID("logo").Style.Attributes["image"]; got != "https://host.example/logo.png" {
+		t.Errorf("image = %q", got)
+	}
+	a := r.FindByID("a").Style.Attributes
+	if a["fillColor"] != "#dae8fc" {
+		t.Errorf("fillColor = %q", a["fillColor"])
+	}
+	if _, ok := a["rounded"]; !ok {
+		t.Error("named style removed")
+	}
+
+	r = Redact(&g, []RedactRule{{Pattern: regexp.MustCompile(`corp`), Strip: true}})
+	if _, ok := r.FindByID("logo").Style.Attributes["image"]; ok {
+		t.Error("stripped style value kept")
+	}
+}
@@ -0,0 +1,766 @@
+package graw
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SVGOptions configures RenderSVG.
+type SVGOptions struct {
+	// Scale multiplies all coordinates; zero means 1.
+	Scale float64
+	// Padding is the margin around the diagram, before scaling.
+	Padding int
+	// Background fills the image, e.g. "#ffffff"; empty uses the
+	// background of the model, if any, and otherwise leaves it
+	// transparent. The background image of the model is drawn too.
+	Background string
+	// Fonts are embedded in the image, so that it looks the same on
+	// machines which do not have them installed.
+	Fonts []FontFace
+	// FontSubstitutes maps the font families of labels to the family
+	// rendered instead, e.g. "Helvetica" to "Inter" to match the fonts
+	// of a draw.io theme. The original family stays as fallback.
+	FontSubstitutes map[string]string
+
+	// Layer renders only the cells of the layer with this ID.
+	Layer string
+	// Group renders only the cell with this ID, such as a group or a
+	// container, the cells nested in it and the edges between them.
+	Group string
+	// Region renders only the part of the diagram inside it when not
+	// empty, in the coordinates of the layers. The image then has the
+	// size of Region times Scale, without Padding.
+	Region Rect
+}
+
+// FontFace is a font file embedded in rendered images.
+type FontFace struct {
+	Family string
+	Bold   bool
+	Italic bool
+	// Format is the CSS format of Data: "truetype", "opentype",
+	// "woff" or "woff2".
+	Format string
+	Data   []byte
+}
+
+// LoadFontFace reads the font file at path as a face of family,
+// deriving its format from the file extension (.ttf, .otf, .woff or
+// .woff2).
+func LoadFontFace(family, path string, bold, italic bool) (FontFace, error) {
+	formats := map[string]string{".ttf": "truetype", ".otf": "opentype", ".woff": "woff", ".woff2": "woff2"}
+	format, ok := formats[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return FontFace{}, fmt.Errorf("graw: LoadFontFace: unknown font format %q", filepath.Ext(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FontFace{}, fmt.Errorf("graw: LoadFontFace: %v", err)
+	}
+	return FontFace{Family: family, Bold: bold, Italic: italic, Format: format, Data: data}, nil
+}
+
+// fontFaceCSS returns the @font-face rules embedding fonts.
+func fontFaceCSS(fonts []FontFace) string {
+	var b strings.Builder
+	for _, f := range fonts {
+		weight, style := "normal", "normal"
+		if f.Bold {
+			weight = "bold"
+		}
+		if f.Italic {
+			style = "italic"
+		}
+		mime := "font/ttf"
+		switch f.Format {
+		case "opentype":
+			mime = "font/otf"
+		case "woff", "woff2":
+			mime = "font/" + f.Format
+		}
+		fmt.Fprintf(&b, "@font-face{font-family:%q;font-weight:%s;font-style:%s;src:url(data:%s;base64,%s) format(%q);}",
+			f.Family, weight, style, mime, base64.StdEncoding.EncodeToString(f.Data), f.Format)
+	}
+	return b.String()
+}
+
+// RenderSVG writes a preview of g to w as an SVG image. Vertices are
+// drawn as rectangles, rounded rectangles, ellipses, rhombi or images,
+// groups are invisible. Edges are drawn as straight, orthogonal or rounded
+// polylines clipped to the shapes they connect, with the arrowheads of
+// draw.io: startArrow and endArrow of classic, block, open, oval,
+// diamond, dash, cross, circle and the ER notations, in their thin
+// variants and unfilled when startFill or endFill is 0, sized by
+// startSize and endSize. Unknown arrowheads are drawn as classic ones.
+// Dashed cells honor dashPattern. Labels are drawn as plain text; HTML
+// markup is removed. The cells in collapsed containers are hidden, and
+// edges to them end at the container.
+func RenderSVG(w io.Writer, g *GraphModel, opts SVGOptions) error {
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	if g.Weights != nil {
+		weighted := g.clone()
+		g.Weights.apply(&weighted)
+		g = &weighted
+	}
+	r := &svgRenderer{g: g, opts: opts}
+	bounds := r.bounds()
+
+	fmt.Fprintf(&r.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%d %d %d %d">`,
+		svgNum(float64(bounds.Width)*scale), svgNum(float64(bounds.Height)*scale),
+		bounds.X, bounds.Y, bounds.Width, bounds.Height)
+	if len(opts.Fonts) > 0 {
+		fmt.Fprintf(&r.b, "<style>%s</style>", html.EscapeString(fontFaceCSS(opts.Fonts)))
+	}
+	background := opts.Background
+	if background == "" && g.Background != "none" {
+		background = g.Background
+	}
+	if background != "" {
+		fmt.Fprintf(&r.b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`,
+			bounds.X, bounds.Y, bounds.Width, bounds.Height, svgAttr(background))
+	}
+	if img := g.backgroundImage(); img != nil && img.Src != "" {
+		fmt.Fprintf(&r.b, `<image x="0" y="0" width="%d" height="%d" href="%s"/>`,
+			img.Width, img.Height, svgAttr(imageHref(img.Src)))
+	}
+	for i := range g.Root {
+		c := g.Root[i]
+		if !r.included(c) {
+			continue
+		}
+		switch {
+		case c.isVertex():
+			r.vertex(c)
+		case c.isEdge():
+			r.edge(c)
+		}
+	}
+	r.b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, r.b.String())
+	return err
+}
+
+// svgRenderer accumulates the elements of an SVG image of g.
+type svgRenderer struct {
+	g    *GraphModel
+	b    strings.Builder
+	opts SVGOptions
+}
+
+// bounds returns the area of the image: the bounds of the included
+// cells with padding, or the selected region.
+func (r *svgRenderer) bounds() Rect {
+	if !r.opts.Region.Empty() {
+		return r.opts.Region
+	}
+	var bounds Rect
+	for i := range r.g.Root {
+		c := r.g.Root[i]
+		if !r.included(c) {
+			continue
+		}
+		switch {
+		case c.isVertex() && c.Geometry != nil && !r.isEdgeLabel(c):
+			b := r.g.BoundsOf(c)
+			if c.Style.Attributes["verticalLabelPosition"] == "bottom" {
+				// Make room for the label below the shape.
+				if text := labelText(c); text != "" {
+					f := fontOf(c.Style)
+					b.Height += int(math.Ceil(float64(strings.Count(text, "\n")+1)*f.Size*lineHeight)) + 8
+				}
+			}
+			bounds = bounds.Union(b)
+		case c.isEdge():
+			bounds = bounds.Union(r.edgeBounds(c))
+		}
+	}
+	return bounds.Inset(-r.opts.Padding)
+}
+
+// included reports whether c is part of the image selected by the
+// Layer, Group and Region options. Edge labels follow their edge.
+func (r *svgRenderer) included(c *Cell) bool {
+	if r.isEdgeLabel(c) {
+		return r.included(r.g.cell(c.ParentID))
+	}
+	if r.folded(c) {
+		return false
+	}
+	if c.isEdge() {
+		// Edges between cells folded into the same container vanish.
+		if src, dst := r.g.cell(c.Source), r.g.cell(c.Target); src != nil && dst != nil && src != dst {
+			if v := r.visibleTerminal(src); v != src && v == r.visibleTerminal(dst) {
+				return false
+			}
+		}
+	}
+	if r.opts.Layer != "" && !r.g.nestedIn(c, r.opts.Layer) {
+		return false
+	}
+	if r.opts.Group != "" && !r.g.nestedIn(c, r.opts.Group) {
+		if !c.isEdge() || !r.g.nestedIn(r.g.cell(c.Source), r.opts.Group) || !r.g.nestedIn(r.g.cell(c.Target), r.opts.Group) {
+			return false
+		}
+	}
+	if !r.opts.Region.Empty() {
+		switch {
+		case c.isVertex() && c.Geometry != nil:
+			return r.g.BoundsOf(c).Intersects(r.opts.Region)
+		case c.isEdge():
+			return r.edgeBounds(c).Intersects(r.opts.Region)
+		}
+	}
+	return true
+}
+
+// folded reports whether c is nested in a collapsed container.
+func (r *svgRenderer) folded(c *Cell) bool {
+	for depth := 0; c != nil && depth < 64; depth++ {
+		c = r.g.cell(c.ParentID)
+		if c != nil && c.Collapsed == "1" {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleTerminal returns the cell drawn for the end of an edge at c:
+// the outermost collapsed container c is nested in, as in draw.io, or
+// c itself.
+func (r *svgRenderer) visibleTerminal(c *Cell) *Cell {
+	v := c
+	p := r.g.cell(c.ParentID)
+	for depth := 0; p != nil && depth < 64; depth++ {
+		if p.Collapsed == "1" {
+			v = p
+		}
+		p = r.g.cell(p.ParentID)
+	}
+	return v
+}
+
+// edgeBounds returns the bounding box of the route of the edge c.
+func (r *svgRenderer) edgeBounds(c *Cell) Rect {
+	var b Rect
+	for _, p := range r.edgePoints(c) {
+		b = b.Union(Rect{int(p.x), int(p.y), 1, 1})
+	}
+	return b
+}
+
+// fpoint is a point with fractional coordinates.
+type fpoint struct {
+	x, y float64
+}
+
+// vertex draws the vertex c and its label. Labels of edges, which are
+// vertices nested in an edge, are drawn at their position along it.
+func (r *svgRenderer) vertex(c *Cell) {
+	if c.Geometry == nil {
+		return
+	}
+	if r.isEdgeLabel(c) {
+		p := r.alongEdge(r.g.cell(c.ParentID), c.Geometry.Position)
+		r.label(c, Rect{int(p.x), int(p.y), 0, 0})
+		return
+	}
+	b := r.g.BoundsOf(c)
+	a := c.Style.Attributes
+	paint := svgPaint(c.Style, "#ffffff")
+	x, y, w, h := float64(b.X), float64(b.Y), float64(b.Width), float64(b.Height)
+
+	switch {
+	case isShape(c.Style, "group"):
+		// Groups are invisible.
+	case a["shape"] == "image":
+		aspect := "none"
+		if a["imageAspect"] != "0" {
+			aspect = "xMidYMid meet"
+		}
+		fmt.Fprintf(&r.b, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="%s" href="%s"/>`,
+			svgNum(x), svgNum(y), svgNum(w), svgNum(h), aspect, svgAttr(imageHref(a["image"])))
+	case isShape(c.Style, "ellipse"):
+		fmt.Fprintf(&r.b, `<ellipse cx="%s" cy="%s" rx="%s" ry="%s"%s/>`,
+			svgNum(x+w/2), svgNum(y+h/2), svgNum(w/2), svgNum(h/2), paint)
+	case isShape(c.Style, "rhombus"):
+		fmt.Fprintf(&r.b, `<path d="M%s %sL%s %sL%s %sL%s %sZ"%s/>`,
+			svgNum(x+w/2), svgNum(y), svgNum(x+w), svgNum(y+h/2),
+			svgNum(x+w/2), svgNum(y+h), svgNum(x), svgNum(y+h/2), paint)
+	default:
+		var rx float64
+		if a["rounded"] == "1" {
+			rx = math.Min(w, h) * 0.15
+			if v, ok := c.Style.float("arcSize"); ok {
+				if a["absoluteArcSize"] == "1" {
+					rx = v / 2
+				} else {
+					rx = math.Min(w, h) * v / 100
+				}
+			}
+		}
+		fmt.Fprintf(&r.b, `<rect x="%s" y="%s" width="%s" height="%s"`, svgNum(x), svgNum(y), svgNum(w), svgNum(h))
+		if rx > 0 {
+			fmt.Fprintf(&r.b, ` rx="%s"`, svgNum(rx))
+		}
+		r.b.WriteString(paint + "/>")
+	}
+	r.label(c, b)
+}
+
+// imageHref returns the URL of the image of a style for an SVG image
+// element, restoring the ";base64" draw.io drops from data URIs in
+// styles.
+func imageHref(u string) string {
+	if strings.HasPrefix(u, "data:") && !strings.Contains(u, ";base64,") {
+		if i := strings.Index(u, ","); i >= 0 {
+			return u[:i] + ";base64" + u[i:]
+		}
+	}
+	return u
+}
+
+// isEdgeLabel reports whether the vertex c is the label of an edge.
+func (r *svgRenderer) isEdgeLabel(c *Cell) bool {
+	parent := r.g.cell(c.ParentID)
+	return parent != nil && parent.isEdge()
+}
+
+// isShape reports whether style draws the named basic shape, set
+// either as a named style or as the shape key.
+func isShape(style Style, name string) bool {
+	_, named := style.Attributes[name]
+	return named || style.Attributes["shape"] == name
+}
+
+// svgPaint returns the fill and stroke attributes of a cell with
+// style, filling with fill unless the style sets a fill color.
+func svgPaint(style Style, fill string) string {
+	if v, ok := style.Attributes["fillColor"]; ok {
+		fill = v
+	}
+	return fmt.Sprintf(` fill="%s"`, svgAttr(svgColor(fill))) + svgStroke(style)
+}
+
+// svgStroke returns the stroke attributes of a cell with style.
+func svgStroke(style Style) string {
+	a := style.Attributes
+	stroke := "#000000"
+	if v, ok := a["strokeColor"]; ok {
+		stroke = v
+	}
+	width := 1.0
+	if v, ok := style.float("strokeWidth"); ok {
+		width = v
+	}
+	s := fmt.Sprintf(` stroke="%s"`, svgAttr(svgColor(stroke)))
+	if width != 1 {
+		s += fmt.Sprintf(` stroke-width="%s"`, svgNum(width))
+	}
+	if a["dashed"] == "1" {
+		pattern := "3 3"
+		if v := a["dashPattern"]; v != "" {
+			// dashPattern is relative to the stroke width.
+			var parts []string
+			for _, f := range strings.Fields(v) {
+				n, err := strconv.ParseFloat(f, 64)
+				if err != nil {
+					continue
+				}
+				parts = append(parts, svgNum(n*width))
+			}
+			pattern = strings.Join(parts, " ")
+		} else if width != 1 {
+			pattern = svgNum(3*width) + " " + svgNum(3*width)
+		}
+		s += fmt.Sprintf(` stroke-dasharray="%s"`, pattern)
+	}
+	if v, ok := style.float("opacity"); ok {
+		s += fmt.Sprintf(` opacity="%s"`, svgNum(v/100))
+	}
+	return s
+}
+
+// svgColor maps draw.io colors to SVG paints; "none" and empty
+// colors are not painted.
+func svgColor(c string) string {
+	if c == "" || c == "none" || c == "default" {
+		return "none"
+	}
+	return c
+}
+
+// label draws the label of c centered in b, or at its alignment.
+func (r *svgRenderer) label(c *Cell, b Rect) {
+	text := labelText(c)
+	if text == "" {
+		return
+	}
+	a := c.Style.Attributes
+	f := fontOf(c.Style)
+	color := "#000000"
+	if v, ok := a["fontColor"]; ok {
+		color = v
+	}
+
+	if a["verticalLabelPosition"] == "bottom" {
+		b.Y, b.Height = b.Y+b.Height, 0
+	}
+	x, anchor := float64(b.X)+float64(b.Width)/2, "middle"
+	switch a["align"] {
+	case "left":
+		x, anchor = float64(b.X)+4, "start"
+	case "right":
+		x, anchor = float64(b.X+b.Width)-4, "end"
+	}
+	family := f.Family
+	if sub, ok := r.opts.FontSubstitutes[family]; ok && sub != "" {
+		family = sub + ", " + family
+	}
+	lines := strings.Split(text, "\n")
+	height := float64(len(lines)) * f.Size * lineHeight
+	y := float64(b.Y) + (float64(b.Height)-height)/2
+	switch a["verticalAlign"] {
+	case "top":
+		y = float64(b.Y) + 4
+	case "bottom":
+		y = float64(b.Y+b.Height) - height - 4
+	}
+
+	fmt.Fprintf(&r.b, `<text x="%s" y="%s" font-family="%s" font-size="%s" fill="%s" text-anchor="%s"`,
+		svgNum(x), svgNum(y), svgAttr(family), svgNum(f.Size), svgAttr(svgColor(color)), anchor)
+	if f.Bold {
+		r.b.WriteString(` font-weight="bold"`)
+	}
+	if f.Italic {
+		r.b.WriteString(` font-style="italic"`)
+	}
+	r.b.WriteString(">")
+	for i, l := range lines {
+		// Place the baseline about 0.85 em below the top of the line.
+		dy := f.Size * 0.85
+		if i > 0 {
+			dy = f.Size * lineHeight
+		}
+		fmt.Fprintf(&r.b, `<tspan x="%s" dy="%s">%s</tspan>`, svgNum(x), svgNum(dy), html.EscapeString(l))
+	}
+	r.b.WriteString("</text>")
+}
+
+// edge draws the edge c with its arrowheads and its label.
+func (r *svgRenderer) edge(c *Cell) {
+	pts := r.edgePoints(c)
+	if len(pts) < 2 {
+		return
+	}
+	a := c.Style.Attributes
+	width := 1.0
+	if v, ok := c.Style.float("strokeWidth"); ok {
+		width = v
+	}
+	color := "#000000"
+	if v, ok := a["strokeColor"]; ok {
+		color = v
+	}
+
+	// Shorten the line so that it ends at the base of the arrowheads
+	// instead of poking through their tips.
+	var heads []string
+	end := len(pts) - 1
+	if m, back := arrowhead(c.Style, "end", "classic", pts[end], pts[end-1], width, color); m != "" {
+		heads = append(heads, m)
+		pts[end] = towards(pts[end], pts[end-1], back)
+	}
+	if m, back := arrowhead(c.Style, "start", "none", pts[0], pts[1], width, color); m != "" {
+		heads = append(heads, m)
+		pts[0] = towards(pts[0], pts[1], back)
+	}
+
+	radius := 0.0
+	if a["rounded"] == "1" {
+		radius = 10
+		if v, ok := c.Style.float("arcSize"); ok {
+			radius = v / 2
+		}
+	}
+	fmt.Fprintf(&r.b, `<path d="%s" fill="none"%s/>`, polylinePath(pts, radius), svgStroke(c.Style))
+	for _, m := range heads {
+		r.b.WriteString(m)
+	}
+	if c.Value != "" {
+		p := r.alongEdge(c, 0)
+		r.label(c, Rect{int(p.x), int(p.y), 0, 0})
+	}
+}
+
+// edgePoints returns the route of the edge c: from the perimeter of
+// its source, or its source point, along its waypoints to the
+// perimeter of its target. Edges with an orthogonal edge style and no
+// waypoints get an elbow half way.
+func (r *svgRenderer) edgePoints(c *Cell) []fpoint {
+	geo := c.Geometry
+	if geo == nil {
+		geo = &Geometry{}
+	}
+	var ox, oy int
+	if parent := r.g.cell(c.ParentID); parent != nil && parent.Geometry != nil {
+		ox, oy = r.g.absolutePosition(parent)
+	}
+
+	src, srcOK := r.terminal(c.Source, geo.SourcePoint, ox, oy)
+	dst, dstOK := r.terminal(c.Target, geo.TargetPoint, ox, oy)
+	if !srcOK || !dstOK {
+		return nil
+	}
+	var mid []fpoint
+	for _, p := range geo.Points {
+		mid = append(mid, fpoint{float64(p.X + ox), float64(p.Y + oy)})
+	}
+	sc, dc := src.center(), dst.center()
+	es := c.Style.Attributes["edgeStyle"]
+	if len(mid) == 0 && (es == "orthogonalEdgeStyle" || es == "elbowEdgeStyle") && sc.x != dc.x && sc.y != dc.y {
+		if math.Abs(dc.x-sc.x) >= math.Abs(dc.y-sc.y) {
+			mx := (sc.x + dc.x) / 2
+			mid = []fpoint{{mx, sc.y}, {mx, dc.y}}
+		} else {
+			my := (sc.y + dc.y) / 2
+			mid = []fpoint{{sc.x, my}, {dc.x, my}}
+		}
+	}
+
+	next, prev := dc, sc
+	if len(mid) > 0 {
+		next, prev = mid[0], mid[len(mid)-1]
+	}
+	pts := []fpoint{src.clip(next)}
+	pts = append(pts, mid...)
+	return append(pts, dst.clip(prev))
+}
+
+// svgTerminal is the end of an edge: a shape or a bare point.
+type svgTerminal struct {
+	r       Rect
+	ellipse bool
+}
+
+// terminal returns the end of an edge connected to the vertex id, or
+// to the collapsed container hiding it, or at p relative to (ox, oy)
+// when unconnected. It reports false when
+// the end is unknown.
+func (r *svgRenderer) terminal(id string, p *Point, ox, oy int) (svgTerminal, bool) {
+	if id != "" {
+		if c := r.g.cell(id); c != nil && c.Geometry != nil {
+			c = r.visibleTerminal(c)
+			return svgTerminal{r: r.g.BoundsOf(c), ellipse: isShape(c.Style, "ellipse")}, true
+		}
+	}
+	if p != nil {
+		return svgTerminal{r: Rect{p.X + ox, p.Y + oy, 0, 0}}, true
+	}
+	return svgTerminal{}, false
+}
+
+// center returns the center of t.
+func (t svgTerminal) center() fpoint {
+	return fpoint{float64(t.r.X) + float64(t.r.Width)/2, float64(t.r.Y) + float64(t.r.Height)/2}
+}
+
+// clip returns where the line from the center of t towards p leaves
+// the shape of t.
+func (t svgTerminal) clip(p fpoint) fpoint {
+	c := t.center()
+	dx, dy := p.x-c.x, p.y-c.y
+	hw, hh := float64(t.r.Width)/2, float64(t.r.Height)/2
+	if (dx == 0 && dy == 0) || hw == 0 || hh == 0 {
+		return c
+	}
+	var s float64
+	if t.ellipse {
+		s = 1 / math.Hypot(dx/hw, dy/hh)
+	} else {
+		s = 1 / math.Max(math.Abs(dx)/hw, math.Abs(dy)/hh)
+	}
+	return fpoint{c.x + dx*s, c.y + dy*s}
+}
+
+// alongEdge returns the point of the label of the edge c at the
+// relative position pos, from -1 at the source to 1 at the target.
+func (r *svgRenderer) alongEdge(c *Cell, pos float64) fpoint {
+	return pointAlong(r.edgePoints(c), (pos+1)/2)
+}
+
+// pointAlong returns the point at the fraction t of the length of the
+// polyline pts.
+func pointAlong(pts []fpoint, t float64) fpoint {
+	if len(pts) == 0 {
+		return fpoint{}
+	}
+	var total float64
+	for i := 1; i < len(pts); i++ {
+		total += math.Hypot(pts[i].x-pts[i-1].x, pts[i].y-pts[i-1].y)
+	}
+	want := total * math.Max(0, math.Min(1, t))
+	for i := 1; i < len(pts); i++ {
+		d := math.Hypot(pts[i].x-pts[i-1].x, pts[i].y-pts[i-1].y)
+		if want <= d && d > 0 {
+			return towards(pts[i-1], pts[i], want)
+		}
+		want -= d
+	}
+	return pts[len(pts)-1]
+}
+
+// towards returns the point d away from p in the direction of q.
+func towards(p, q fpoint, d float64) fpoint {
+	l := math.Hypot(q.x-p.x, q.y-p.y)
+	if l == 0 {
+		return p
+	}
+	return fpoint{p.x + (q.x-p.x)*d/l, p.y + (q.y-p.y)*d/l}
+}
+
+// polylinePath returns the SVG path of the polyline pts, rounding its
+// corners with radius when positive.
+func polylinePath(pts []fpoint, radius float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "M%s %s", svgNum(pts[0].x), svgNum(pts[0].y))
+	for i := 1; i < len(pts)-1; i++ {
+		if radius <= 0 {
+			fmt.Fprintf(&b, "L%s %s", svgNum(pts[i].x), svgNum(pts[i].y))
+			continue
+		}
+		in := math.Hypot(pts[i].x-pts[i-1].x, pts[i].y-pts[i-1].y)
+		out := math.Hypot(pts[i+1].x-pts[i].x, pts[i+1].y-pts[i].y)
+		rad := math.Min(radius, math.Min(in, out)/2)
+		p0 := towards(pts[i], pts[i-1], rad)
+		p1 := towards(pts[i], pts[i+1], rad)
+		fmt.Fprintf(&b, "L%s %sQ%s %s %s %s", svgNum(p0.x), svgNum(p0.y),
+			svgNum(pts[i].x), svgNum(pts[i].y), svgNum(p1.x), svgNum(p1.y))
+	}
+	last := pts[len(pts)-1]
+	fmt.Fprintf(&b, "L%s %s", svgNum(last.x), svgNum(last.y))
+	return b.String()
+}
+
+// arrowhead returns the SVG elements of the arrowhead at the prefix
+// ("start" or "end") of an edge with style, its tip at tip and pointing
+// away from from, and how far the line must stop short of the tip.
+// def is the arrowhead used when the style sets none.
+func arrowhead(style Style, prefix, def string, tip, from fpoint, width float64, color string) (string, float64) {
+	kind, ok := style.Attributes[prefix+"Arrow"]
+	if !ok {
+		kind = def
+	}
+	if kind == "" || kind == "none" {
+		return "", 0
+	}
+	size := 6.0
+	if v, ok := style.float(prefix + "Size"); ok {
+		size = v
+	}
+	fill := svgColor(color)
+	if style.Attributes[prefix+"Fill"] == "0" {
+		fill = "none"
+	}
+	stroke := fmt.Sprintf(` stroke="%s"`, svgAttr(svgColor(color)))
+	if width != 1 {
+		stroke += fmt.Sprintf(` stroke-width="%s"`, svgNum(width))
+	}
+
+	// u points from the tip back along the edge, n across it.
+	l := math.Hypot(tip.x-from.x, tip.y-from.y)
+	if l == 0 {
+		return "", 0
+	}
+	ux, uy := (from.x-tip.x)/l, (from.y-tip.y)/l
+	nx, ny := -uy, ux
+	at := func(back, across float64) fpoint {
+		return fpoint{tip.x + ux*back + nx*across, tip.y + uy*back + ny*across}
+	}
+	poly := func(fill string, closed bool, pts ...fpoint) string {
+		var b strings.Builder
+		for i, p := range pts {
+			if i == 0 {
+				b.WriteString("M")
+			} else {
+				b.WriteString("L")
+			}
+			fmt.Fprintf(&b, "%s %s", svgNum(p.x), svgNum(p.y))
+		}
+		if closed {
+			b.WriteString("Z")
+		}
+		return fmt.Sprintf(`<path d="%s" fill="%s"%s/>`, b.String(), svgAttr(fill), stroke)
+	}
+	circle := func(c fpoint, r float64, fill string) string {
+		return fmt.Sprintf(`<circle cx="%s" cy="%s" r="%s" fill="%s"%s/>`,
+			svgNum(c.x), svgNum(c.y), svgNum(r), svgAttr(fill), stroke)
+	}
+	bar := func(back float64) string {
+		return poly("none", false, at(back, -size/2-2), at(back, size/2+2))
+	}
+	crowsFoot := func() string {
+		return poly("none", false, at(0, -size/2-2), at(size+2, 0), at(0, size/2+2))
+	}
+
+	length := size + width
+	half := length / 2
+	if strings.HasSuffix(kind, "Thin") {
+		half = length / 3
+	}
+	switch strings.TrimSuffix(kind, "Thin") {
+	case "block":
+		return poly(fill, true, tip, at(length, -half), at(length, half)), length
+	case "open":
+		return poly("none", false, at(length, -half), tip, at(length, half)), width / 2
+	case "oval":
+		return circle(at(length/2, 0), length/2, fill), length
+	case "diamond":
+		if kind == "diamondThin" {
+			half = length / 4
+		}
+		return poly(fill, true, tip, at(length/2, -half), at(length, 0), at(length/2, half)), length
+	case "dash":
+		return poly("none", false, at(length/2, -half), at(-length/2, half)), 0
+	case "cross":
+		return poly("none", false, at(0, -half), at(length, half)) +
+			poly("none", false, at(length, -half), at(0, half)), 0
+	case "circle":
+		return circle(at(length/2, 0), length/2, "none"), length
+	case "ERone":
+		return bar(size + 2), 0
+	case "ERmandOne":
+		return bar(size/2+2) + bar(size+4), 0
+	case "ERmany":
+		return crowsFoot(), 0
+	case "ERoneToMany":
+		return crowsFoot() + bar(size+6), 0
+	case "ERzeroToOne":
+		return bar(size/2+2) + circle(at(size+6, 0), size/2, "#ffffff"), 0
+	case "ERzeroToMany":
+		return crowsFoot() + circle(at(size+6+size/2, 0), size/2, "#ffffff"), 0
+	default:
+		// classic: a triangle with its base notched by a quarter.
+		return poly(fill, true, tip, at(length, -half), at(length*3/4, 0), at(length, half)), length * 3 / 4
+	}
+}
+
+// svgNum formats v for SVG with at most two decimals.
+func svgNum(v float64) string {
+	return formatDecimal(v, 2)
+}
+
+// svgAttr escapes s for use in a double quoted attribute.
+func svgAttr(s string) string {
+	return html.EscapeString(s)
+}
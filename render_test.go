@@ -0,0 +1,579 @@
+package graw
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderString returns the SVG image of g rendered with opts.
+func renderString(t *testing.T, g *GraphModel, opts SVGOptions) string {
+	t.Helper()
+	var b strings.Builder
+	if err := RenderSVG(&b, g, opts); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// renderGraph returns a graph holding a 100x50 shape "a" at (0, 0) with
+// style a and, unless b is nil, a 100x50 shape "b" at (x, y) with an
+// edge "e" from a to b with style e.
+func renderGraph(a map[string]string, b map[string]string, x, y int, e map[string]string) *GraphModel {
+	g := NewGraph()
+	shape := func(id string, style map[string]string, x, y int) *Cell {
+		c := NewShape(id, "1")
+		c.Geometry.X, c.Geometry.Y = x, y
+		c.Geometry.Width, c.Geometry.Height = "100", "50"
+		c.Style.Attributes = style
+		return c
+	}
+	g.Add(shape("a", a, 0, 0))
+	if b != nil {
+		g.Add(shape("b", b, x, y))
+		edge := newEdgeCell("e", "1", "a", "b")
+		edge.Style.Attributes = e
+		g.Add(edge)
+	}
+	return &g
+}
+
+func TestRenderSVGVertices(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    map[string]string
+		value    string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "rectangle",
+			style:    map[string]string{},
+			contains: []string{`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">`, `<rect x="0" y="0" width="100" height="50" fill="#ffffff" stroke="#000000"/>`},
+		},
+		{
+			name:     "rounded",
+			style:    map[string]string{"rounded": "1"},
+			contains: []string{`height="50" rx="7.5" fill=`},
+		},
+		{
+			name:     "arc size",
+			style:    map[string]string{"rounded": "1", "arcSize": "20"},
+			contains: []string{`rx="10"`},
+		},
+		{
+			name:     "absolute arc size",
+			style:    map[string]string{"rounded": "1", "arcSize": "20", "absoluteArcSize": "1"},
+			contains: []string{`rx="10"`},
+		},
+		{
+			name:     "ellipse",
+			style:    map[string]string{"ellipse": ""},
+			contains: []string{`<ellipse cx="50" cy="25" rx="50" ry="25" fill="#ffffff" stroke="#000000"/>`},
+		},
+		{
+			name:     "rhombus",
+			style:    map[string]string{"shape": "rhombus"},
+			contains: []string{`<path d="M50 0L100 25L50 50L0 25Z" fill="#ffffff" stroke="#000000"/>`},
+		},
+		{
+			name:     "group",
+			style:    map[string]string{"group": ""},
+			excludes: []string{"<rect", "<path"},
+		},
+		{
+			name:     "image",
+			style:    map[string]string{"shape": "image", "image": "data:image/png,iVBO"},
+			contains: []string{`preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,iVBO"/>`},
+		},
+		{
+			name:     "stretched image",
+			style:    map[string]string{"shape": "image", "image": "https://example.com/a.png", "imageAspect": "0"},
+			contains: []string{`preserveAspectRatio="none" href="https://example.com/a.png"/>`},
+		},
+		{
+			name:     "colors",
+			style:    map[string]string{"fillColor": "none", "strokeColor": "#ff0000", "strokeWidth": "2", "opacity": "50"},
+			contains: []string{`fill="none" stroke="#ff0000" stroke-width="2" opacity="0.5"/>`},
+		},
+		{
+			name:     "dashed",
+			style:    map[string]string{"dashed": "1"},
+			contains: []string{`stroke-dasharray="3 3"`},
+		},
+		{
+			name:     "dash pattern",
+			style:    map[string]string{"dashed": "1", "dashPattern": "1 4", "strokeWidth": "2"},
+			contains: []string{`stroke-dasharray="2 8"`},
+		},
+		{
+			name:     "label",
+			style:    map[string]string{"html": "1", "fontColor": "#333333", "fontStyle": "3"},
+			value:    "<b>R&amp;D</b><br>Team &lt;1&gt;",
+			contains: []string{`fill="#333333" text-anchor="middle" font-weight="bold" font-style="italic">`, `>R&amp;D</tspan>`, `>Team &lt;1&gt;</tspan>`},
+		},
+		{
+			name:     "plain label",
+			style:    map[string]string{"align": "left"},
+			value:    "<b>x</b>",
+			contains: []string{`<text x="4" `, `text-anchor="start"`, `>&lt;b&gt;x&lt;/b&gt;</tspan>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := renderGraph(tt.style, nil, 0, 0, nil)
+			g.FindByID("a").Value = tt.value
+			out := renderString(t, g, SVGOptions{})
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it to contain\n%s", out, s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it not to contain\n%s", out, s)
+				}
+			}
+			if tt.value == "" && strings.Contains(out, "<text") {
+				t.Errorf("got\n%s\nwant no label", out)
+			}
+		})
+	}
+}
+
+func TestRenderSVGEdges(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   map[string]string
+		x, y     int
+		style    map[string]string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:  "classic",
+			x:     200,
+			style: map[string]string{},
+			contains: []string{
+				`<path d="M100 25L194.75 25" fill="none" stroke="#000000"/>`,
+				`<path d="M200 25L193 28.5L194.75 25L193 21.5Z" fill="#000000" stroke="#000000"/>`,
+			},
+		},
+		{
+			name:     "no arrow",
+			x:        200,
+			style:    map[string]string{"endArrow": "none"},
+			contains: []string{`<path d="M100 25L200 25" fill="none" stroke="#000000"/>`},
+		},
+		{
+			name:     "unfilled block",
+			x:        200,
+			style:    map[string]string{"endArrow": "block", "endFill": "0", "endSize": "10"},
+			contains: []string{`<path d="M100 25L189 25"`, `<path d="M200 25L189 30.5L189 19.5Z" fill="none" stroke="#000000"/>`},
+		},
+		{
+			name:     "start oval",
+			x:        200,
+			style:    map[string]string{"endArrow": "none", "startArrow": "oval"},
+			contains: []string{`<path d="M107 25L200 25"`, `<circle cx="103.5" cy="25" r="3.5" fill="#000000" stroke="#000000"/>`},
+		},
+		{
+			name:     "unknown arrow",
+			x:        200,
+			style:    map[string]string{"endArrow": "sparkle"},
+			contains: []string{`<path d="M200 25L193 28.5L194.75 25L193 21.5Z"`},
+		},
+		{
+			name:     "orthogonal",
+			x:        200,
+			y:        100,
+			style:    map[string]string{"edgeStyle": "orthogonalEdgeStyle", "endArrow": "none"},
+			contains: []string{`<path d="M100 25L150 25L150 125L200 125" fill="none"`},
+		},
+		{
+			name:     "rounded",
+			x:        200,
+			y:        100,
+			style:    map[string]string{"edgeStyle": "orthogonalEdgeStyle", "endArrow": "none", "rounded": "1"},
+			contains: []string{`<path d="M100 25L140 25Q150 25 150 35L150 115Q150 125 160 125L200 125" fill="none"`},
+		},
+		{
+			name:     "vertical elbow",
+			x:        50,
+			y:        200,
+			style:    map[string]string{"edgeStyle": "elbowEdgeStyle", "endArrow": "none"},
+			contains: []string{`<path d="M50 50L50 125L100 125L100 200" fill="none"`},
+		},
+		{
+			name:     "ellipse target",
+			target:   map[string]string{"ellipse": ""},
+			x:        0,
+			y:        100,
+			style:    map[string]string{"endArrow": "none"},
+			contains: []string{`<path d="M50 50L50 100"`},
+		},
+		{
+			name:     "dashed",
+			x:        200,
+			style:    map[string]string{"dashed": "1", "strokeWidth": "2", "strokeColor": "#0000ff"},
+			contains: []string{`fill="none" stroke="#0000ff" stroke-width="2" stroke-dasharray="6 6"/>`, `fill="#0000ff" stroke="#0000ff" stroke-width="2"/>`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.target
+			if target == nil {
+				target = map[string]string{}
+			}
+			out := renderString(t, renderGraph(map[string]string{}, target, tt.x, tt.y, tt.style), SVGOptions{})
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it to contain\n%s", out, s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it not to contain\n%s", out, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSVGEdgeLabels(t *testing.T) {
+	g := renderGraph(map[string]string{}, map[string]string{}, 200, 0, map[string]string{"endArrow": "none"})
+	e := g.FindByID("e")
+	e.Value = "calls"
+	g.AddEdgeLabel(e, "async", 1)
+	out := renderString(t, g, SVGOptions{})
+	for _, s := range []string{`<text x="150" `, `>calls</tspan>`, `<text x="200" `, `>async</tspan>`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("got\n%s\nwant it to contain\n%s", out, s)
+		}
+	}
+}
+
+func TestRenderSVGOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   SVGOptions
+		prefix string
+	}{
+		{"scale", SVGOptions{Scale: 2}, `<svg xmlns="http://www.w3.org/2000/svg" width="200" height="100" viewBox="0 0 100 50">`},
+		{"padding", SVGOptions{Padding: 10}, `<svg xmlns="http://www.w3.org/2000/svg" width="120" height="70" viewBox="-10 -10 120 70">`},
+		{"background", SVGOptions{Background: "#eeeeee"}, `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50"><rect x="0" y="0" width="100" height="50" fill="#eeeeee"/>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderString(t, renderGraph(map[string]string{}, nil, 0, 0, nil), tt.opts)
+			if !strings.HasPrefix(out, tt.prefix) {
+				t.Errorf("got\n%s\nwant prefix\n%s", out, tt.prefix)
+			}
+			if !strings.HasSuffix(out, "</svg>\n") {
+				t.Errorf("got\n%s\nwant it to end the image", out)
+			}
+		})
+	}
+}
+
+func TestRenderSVGModelBackground(t *testing.T) {
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg" width="100" height="50" viewBox="0 0 100 50">`
+	tests := []struct {
+		name   string
+		set    func(g *GraphModel)
+		opts   SVGOptions
+		prefix string
+	}{
+		{"color", func(g *GraphModel) { g.SetBackgroundColor("#1E1E1E") }, SVGOptions{},
+			svg + `<rect x="0" y="0" width="100" height="50" fill="#1e1e1e"/>`},
+		// The shape comes first.
+		{"none", func(g *GraphModel) { g.SetBackgroundColor("none") }, SVGOptions{},
+			svg + `<rect x="0" y="0" width="100" height="50" fill="#ffffff" stroke="#000000"/></svg>`},
+		{"option wins", func(g *GraphModel) { g.SetBackgroundColor("#1e1e1e") }, SVGOptions{Background: "#eeeeee"},
+			svg + `<rect x="0" y="0" width="100" height="50" fill="#eeeeee"/>`},
+		{"image", func(g *GraphModel) {
+			g.SetBackgroundImage(&BackgroundImage{Src: "https://x/bg.png", Width: 80, Height: 40})
+		}, SVGOptions{},
+			svg + `<image x="0" y="0" width="80" height="40" href="https://x/bg.png"/>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := renderGraph(map[string]string{}, nil, 0, 0, nil)
+			tt.set(g)
+			out := renderString(t, g, tt.opts)
+			if !strings.HasPrefix(out, tt.prefix) {
+				t.Errorf("got\n%s\nwant prefix\n%s", out, tt.prefix)
+			}
+		})
+	}
+}
+
+func TestArrowhead(t *testing.T) {
+	tip, from := fpoint{100, 0}, fpoint{0, 0}
+	tests := []struct {
+		kind     string
+		back     float64
+		elements string
+	}{
+		{"classic", 5.25, "<path"},
+		{"classicThin", 5.25, "<path"},
+		{"block", 7, "<path"},
+		{"open", 0.5, "<path"},
+		{"oval", 7, "<circle"},
+		{"diamond", 7, "<path"},
+		{"dash", 0, "<path"},
+		{"cross", 0, "<path<path"},
+		{"circle", 7, "<circle"},
+		{"ERone", 0, "<path"},
+		{"ERmandOne", 0, "<path<path"},
+		{"ERmany", 0, "<path"},
+		{"ERoneToMany", 0, "<path<path"},
+		{"ERzeroToOne", 0, "<path<circle"},
+		{"ERzeroToMany", 0, "<path<circle"},
+		{"none", 0, ""},
+		{"", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			style := Style{Attributes: map[string]string{"endArrow": tt.kind}}
+			m, back := arrowhead(style, "end", "classic", tip, from, 1, "#000000")
+			if back != tt.back {
+				t.Errorf("got back %v, want %v", back, tt.back)
+			}
+			var elements string
+			for _, e := range strings.Split(m, "/>") {
+				if i := strings.IndexByte(e, ' '); i > 0 {
+					elements += e[:i]
+				}
+			}
+			if elements != tt.elements {
+				t.Errorf("got elements %q, want %q in\n%s", elements, tt.elements, m)
+			}
+		})
+	}
+}
+
+func TestLoadFontFace(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file    string
+		format  string
+		wantErr string
+	}{
+		{"Inter.ttf", "truetype", ""},
+		{"Inter.otf", "opentype", ""},
+		{"Inter.woff", "woff", ""},
+		{"Inter-Bold.WOFF2", "woff2", ""},
+		{"Inter.svg", "", `unknown font format ".svg"`},
+		{"missing.ttf", "", "no such file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if tt.file != "missing.ttf" {
+				if err := os.WriteFile(path, []byte("font"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f, err := LoadFontFace("Inter", path, true, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := FontFace{Family: "Inter", Bold: true, Format: tt.format, Data: []byte("font")}
+			if f.Family != want.Family || f.Bold != want.Bold || f.Italic != want.Italic || f.Format != want.Format || string(f.Data) != string(want.Data) {
+				t.Errorf("got %+v, want %+v", f, want)
+			}
+		})
+	}
+}
+
+func TestFontFaceCSS(t *testing.T) {
+	tests := []struct {
+		name string
+		font FontFace
+		want string
+	}{
+		{"regular", FontFace{Family: "Inter", Format: "truetype", Data: []byte("ab")}, `@font-face{font-family:"Inter";font-weight:normal;font-style:normal;src:url(data:font/ttf;base64,YWI=) format("truetype");}`},
+		{"bold italic", FontFace{Family: "Inter", Bold: true, Italic: true, Format: "opentype", Data: []byte("ab")}, `@font-face{font-family:"Inter";font-weight:bold;font-style:italic;src:url(data:font/otf;base64,YWI=) format("opentype");}`},
+		{"woff2", FontFace{Family: "Fira Sans", Format: "woff2"}, `@font-face{font-family:"Fira Sans";font-weight:normal;font-style:normal;src:url(data:font/woff2;base64,) format("woff2");}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fontFaceCSS([]FontFace{tt.font}); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSVGFonts(t *testing.T) {
+	tests := []struct {
+		name     string
+		family   string
+		opts     SVGOptions
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "default",
+			contains: []string{`font-family="` + defaultFontFamily + `"`},
+			excludes: []string{"<style>"},
+		},
+		{
+			name:     "embedded",
+			opts:     SVGOptions{Fonts: []FontFace{{Family: "Inter", Format: "woff", Data: []byte("ab")}}},
+			contains: []string{`<style>@font-face{font-family:&#34;Inter&#34;;font-weight:normal;font-style:normal;src:url(data:font/woff;base64,YWI=) format(&#34;woff&#34;);}</style>`},
+		},
+		{
+			name:     "substituted",
+			family:   "Helvetica",
+			opts:     SVGOptions{FontSubstitutes: map[string]string{"Helvetica": "Inter"}},
+			contains: []string{`font-family="Inter, Helvetica"`},
+		},
+		{
+			name:     "other family",
+			family:   "Courier New",
+			opts:     SVGOptions{FontSubstitutes: map[string]string{"Helvetica": "Inter"}},
+			contains: []string{`font-family="Courier New"`},
+		},
+		{
+			name:     "empty substitute",
+			family:   "Helvetica",
+			opts:     SVGOptions{FontSubstitutes: map[string]string{"Helvetica": ""}},
+			contains: []string{`font-family="Helvetica"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			style := map[string]string{}
+			if tt.family != "" {
+				style["fontFamily"] = tt.family
+			}
+			g := renderGraph(style, nil, 0, 0, nil)
+			g.FindByID("a").Value = "label"
+			out := renderString(t, g, tt.opts)
+			for _, s := range tt.contains {
+				if !strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it to contain\n%s", out, s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(out, s) {
+					t.Errorf("got\n%s\nwant it not to contain\n%s", out, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSVGSelection(t *testing.T) {
+	g := renderGraph(map[string]string{}, map[string]string{}, 200, 0, map[string]string{"endArrow": "none"})
+	layer := NewLayer("L2", "Notes")
+	g.Add(layer)
+	c := NewShape("c", "L2")
+	c.Geometry.X, c.Geometry.Y = 0, 200
+	g.Add(c)
+	grp := NewGroup("g", "1")
+	g.AddToGroup(grp, placedShape("d", 400, 0))
+	g.AddToGroup(grp, placedShape("f", 400, 100))
+	g.Add(newEdgeCell("d-f", "1", "d", "f"))
+	g.Add(newEdgeCell("a-d", "1", "a", "d"))
+
+	shape := map[string]string{
+		"a": `<rect x="0" y="0" `,
+		"b": `<rect x="200" y="0" `,
+		"c": `<rect x="0" y="200" `,
+		"d": `<rect x="400" y="0" `,
+		"f": `<rect x="400" y="100" `,
+	}
+	tests := []struct {
+		name   string
+		opts   SVGOptions
+		prefix string
+		shapes string
+		edges  []string
+	}{
+		{"all", SVGOptions{}, `width="520" height="260" viewBox="0 0 520 260">`, "abcdf", []string{"a-b", "d-f", "a-d"}},
+		{"layer", SVGOptions{Layer: "1"}, `width="520" height="160" viewBox="0 0 520 160">`, "abdf", []string{"a-b", "d-f", "a-d"}},
+		{"other layer", SVGOptions{Layer: "L2"}, `width="120" height="60" viewBox="0 200 120 60">`, "c", nil},
+		{"group", SVGOptions{Group: "g"}, `width="120" height="160" viewBox="400 0 120 160">`, "df", []string{"d-f"}},
+		{"group padding", SVGOptions{Group: "g", Padding: 10}, `width="140" height="180" viewBox="390 -10 140 180">`, "df", []string{"d-f"}},
+		{"region", SVGOptions{Region: Rect{0, 0, 150, 60}, Padding: 10}, `width="150" height="60" viewBox="0 0 150 60">`, "a", []string{"a-b", "a-d"}},
+		{"scaled region", SVGOptions{Region: Rect{350, 50, 120, 100}, Scale: 2}, `width="240" height="200" viewBox="350 50 120 100">`, "df", []string{"d-f"}},
+		{"empty region", SVGOptions{Region: Rect{0, 0, 0, 100}}, `width="520" height="260" viewBox="0 0 520 260">`, "abcdf", []string{"a-b", "d-f", "a-d"}},
+	}
+	edge := map[string]string{
+		"a-b": `<path d="M100 25L200 25"`,
+		"d-f": `<path d="M460 60L460 94.75"`,
+		"a-d": `<path d="M100 25.61L394.75 29.2"`,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := renderString(t, g, tt.opts)
+			if !strings.HasPrefix(out, `<svg xmlns="http://www.w3.org/2000/svg" `+tt.prefix) {
+				t.Errorf("got\n%s\nwant prefix\n%s", out, tt.prefix)
+			}
+			for id, s := range shape {
+				if got, want := strings.Contains(out, s), strings.Contains(tt.shapes, id); got != want {
+					t.Errorf("got %s drawn %v, want %v in\n%s", id, got, want, out)
+				}
+			}
+			for id, s := range edge {
+				if got, want := strings.Contains(out, s), containsString(tt.edges, id); got != want {
+					t.Errorf("got %s drawn %v, want %v in\n%s", id, got, want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSVGCollapsed(t *testing.T) {
+	g := NewGraph()
+	lane := NewSwimlane("lane", "1", "Lane")
+	lane.Geometry.X, lane.Geometry.Y = 0, 0
+	lane.Geometry.setSize(300, 200)
+	g.Add(lane)
+	g.PlaceInLane(lane, placedShape("in", 0, 0), 20, 20)
+	g.PlaceInLane(lane, placedShape("deep", 0, 0), 160, 100)
+	g.Add(placedShape("out", 400, 0))
+	g.Add(newEdgeCell("in-out", "1", "in", "out"))
+	g.Add(newEdgeCell("in-deep", "1", "in", "deep"))
+
+	tests := []struct {
+		name     string
+		collapse bool
+		prefix   string
+		hidden   bool
+	}{
+		{"expanded", false, `width="520" height="210"`, false},
+		{"collapsed", true, `width="520" height="60"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lane.SetCollapsed(tt.collapse)
+			out := renderString(t, &g, SVGOptions{})
+			if !strings.HasPrefix(out, `<svg xmlns="http://www.w3.org/2000/svg" `+tt.prefix) {
+				t.Errorf("got\n%s\nwant prefix\n%s", out, tt.prefix)
+			}
+			for _, s := range []string{`<rect x="20" y="50" `, `<rect x="160" y="130" `, `<path d="M140 `, `<path d="M132.5 110L`} {
+				if strings.Contains(out, s) == tt.hidden {
+					t.Errorf("got\n%s\nwant %s hidden %v", out, s, tt.hidden)
+				}
+			}
+			if !strings.Contains(out, ">Lane</tspan>") || !strings.Contains(out, `<rect x="400" y="0" `) {
+				t.Errorf("got\n%s\nwant the lane and the outer shape", out)
+			}
+			// The edge to the outer shape starts at the collapsed lane.
+			if tt.collapse && !strings.Contains(out, `<path d="M300 22.26L`) {
+				t.Errorf("got\n%s\nwant an edge from the lane", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dimensions of roadmaps.
+const (
+	roadmapQuarterWidth = 160
+	roadmapLaneTitle    = 120
+	roadmapRowHeight    = 40
+	roadmapBarHeight    = 30
+)
+
+// Initiative is a bar of a roadmap.
+type Initiative struct {
+	Name string
+	// Lane is the team or theme the initiative belongs to.
+	Lane string
+	// Start and End are the first and last quarter of the initiative,
+	// formatted as "2025-Q1"; an empty End is Start.
+	Start, End string
+}
+
+// Milestone is a diamond of a roadmap.
+type Milestone struct {
+	Name string
+	Lane string
+	// Quarter is the quarter of the milestone, formatted as "2025-Q1".
+	Quarter string
+}
+
+// NewRoadmap returns a roadmap: a time axis of quarters from the first
+// to the last quarter used, below it a lane per team or theme, and in
+// the lanes a bar spanning the quarters of each initiative and a
+// diamond in the quarter of each milestone. Items overlapping in time
+// are stacked in rows of their lane. lanes lists the lanes from top to
+// bottom; lanes only named by items follow them.
+func NewRoadmap(lanes []string, initiatives []Initiative, milestones []Milestone) (*GraphModel, error) {
+	type item struct {
+		name, lane string
+		start, end int
+		milestone  bool
+	}
+	var items []item
+	for _, in := range initiatives {
+		start, err := parseQuarter(in.Start)
+		if err != nil {
+			return nil, fmt.Errorf("graw: NewRoadmap: initiative %q: %v", in.Name, err)
+		}
+		end := start
+		if in.End != "" {
+			if end, err = parseQuarter(in.End); err != nil {
+				return nil, fmt.Errorf("graw: NewRoadmap: initiative %q: %v", in.Name, err)
+			}
+		}
+		if end < start {
+			return nil, fmt.Errorf("graw: NewRoadmap: initiative %q ends before it starts", in.Name)
+		}
+		items = append(items, item{in.Name, in.Lane, start, end, false})
+	}
+	for _, m := range milestones {
+		q, err := parseQuarter(m.Quarter)
+		if err != nil {
+			return nil, fmt.Errorf("graw: NewRoadmap: milestone %q: %v", m.Name, err)
+		}
+		items = append(items, item{m.Name, m.Lane, q, q, true})
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("graw: NewRoadmap: no initiatives or milestones")
+	}
+
+	first, last := items[0].start, items[0].end
+	order := append([]string(nil), lanes...)
+	byLane := make(map[string][]item)
+	known := make(map[string]bool)
+	for _, l := range lanes {
+		known[l] = true
+	}
+	for _, it := range items {
+		first, last = minInt(first, it.start), maxInt(last, it.end)
+		if !known[it.lane] {
+			known[it.lane] = true
+			order = append(order, it.lane)
+		}
+		byLane[it.lane] = append(byLane[it.lane], it)
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	x0 := 10 + roadmapLaneTitle
+	for q := first; q <= last; q++ {
+		c := NewShape(taken.unique("quarter-"+formatQuarter(q)), rootCellID)
+		c.Value = "<b>Q" + strconv.Itoa(q%4+1) + "</b> " + strconv.Itoa(q/4)
+		c.Style = Style{Attributes: shapeStyle("")}
+		c.Style.set("fillColor", "#f5f5f5")
+		c.Style.set("strokeColor", "#666666")
+		c.Geometry.X, c.Geometry.Y = x0+(q-first)*roadmapQuarterWidth, 10
+		c.Geometry.setSize(roadmapQuarterWidth, roadmapRowHeight-10)
+		g.Add(c)
+	}
+
+	width := roadmapLaneTitle + (last-first+1)*roadmapQuarterWidth
+	y := 10 + roadmapRowHeight
+	for n, l := range order {
+		lane := newContainer(taken.unique("lane-"+l), rootCellID, "<b>"+html.EscapeString(l)+"</b>")
+		lane.Style.set("horizontal", "0")
+		lane.Style.set("collapsible", "0")
+		lane.Style.setFloat("startSize", roadmapLaneTitle)
+		lane.Style.set("fillColor", ganttColors[n%len(ganttColors)])
+		lane.Style.set("swimlaneFillColor", "#ffffff")
+		var cells []*Cell
+
+		// items are stacked in the first row free from their start
+		laneItems := byLane[l]
+		sort.SliceStable(laneItems, func(i, j int) bool { return laneItems[i].start < laneItems[j].start })
+		var rows []int
+		for _, it := range laneItems {
+			row := 0
+			for row < len(rows) && rows[row] >= it.start {
+				row++
+			}
+			if row == len(rows) {
+				rows = append(rows, 0)
+			}
+			rows[row] = it.end
+
+			c := NewShape(taken.unique(lane.ID+"-"+it.name), lane.ID)
+			c.Value = html.EscapeString(it.name)
+			x := roadmapLaneTitle + (it.start-first)*roadmapQuarterWidth
+			w := (it.end - it.start + 1) * roadmapQuarterWidth
+			top := 5 + row*roadmapRowHeight
+			if it.milestone {
+				c.Style = Style{Attributes: shapeStyle("rhombus")}
+				c.Style.set("fillColor", "#ffffff")
+				c.Style.set("labelPosition", "right")
+				c.Style.set("align", "left")
+				c.Style.set("whiteSpace", "nowrap")
+				c.Geometry.X, c.Geometry.Y = x+10, top+(roadmapRowHeight-roadmapBarHeight)/2
+				c.Geometry.setSize(roadmapBarHeight-10, roadmapBarHeight-10)
+				c.Data = map[string]string{"tooltip": it.name + ": " + formatQuarter(it.start)}
+			} else {
+				c.Style = Style{Attributes: shapeStyle("rounded")}
+				c.Style.set("fillColor", mixColor(ganttColors[n%len(ganttColors)], "#000000", 0.1))
+				c.Geometry.X, c.Geometry.Y = x+4, top
+				c.Geometry.setSize(w-8, roadmapBarHeight)
+				c.Data = map[string]string{"tooltip": it.name + ": " + formatQuarter(it.start) + " – " + formatQuarter(it.end)}
+			}
+			cells = append(cells, c)
+		}
+		lane.Geometry.X, lane.Geometry.Y = 10, y
+		h := maxInt(len(rows), 1)*roadmapRowHeight + 10
+		lane.Geometry.setSize(width, h)
+		g.Add(lane)
+		for _, c := range cells {
+			g.Add(c)
+		}
+		y += h
+	}
+	return &g, nil
+}
+
+// parseQuarter returns the index of the quarter s, formatted as
+// "2025-Q1" or "2025Q1": four times the year plus the quarter, from 0.
+func parseQuarter(s string) (int, error) {
+	i := strings.LastIndexAny(s, "Qq")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid quarter %q", s)
+	}
+	year, err := strconv.Atoi(strings.TrimSuffix(s[:i], "-"))
+	if err != nil || year < 0 {
+		return 0, fmt.Errorf("invalid quarter %q", s)
+	}
+	q, err := strconv.Atoi(s[i+1:])
+	if err != nil || q < 1 || q > 4 {
+		return 0, fmt.Errorf("invalid quarter %q", s)
+	}
+	return year*4 + q - 1, nil
+}
+
+// formatQuarter returns the quarter of index q formatted as "2025-Q1".
+func formatQuarter(q int) string {
+	return strconv.Itoa(q/4) + "-Q" + strconv.Itoa(q%4+1)
+}
@@ -0,0 +1,124 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuarter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		err  bool
+	}{
+		{in: "2025-Q1", want: 8100},
+		{in: "2025Q4", want: 8103},
+		{in: "2025-q2", want: 8101},
+		{in: "2025", err: true},
+		{in: "2025-Q0", err: true},
+		{in: "2025-Q5", err: true},
+		{in: "-Q1", err: true},
+		{in: "x-Q1", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseQuarter(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("parseQuarter(%q): got %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+		if err == nil && formatQuarter(got) != "2025-Q"+tt.in[len(tt.in)-1:] {
+			t.Errorf("formatQuarter(%d): got %q", got, formatQuarter(got))
+		}
+	}
+}
+
+func TestNewRoadmap(t *testing.T) {
+	g, err := NewRoadmap([]string{"Platform", "Empty"}, []Initiative{
+		{Name: "Auth", Lane: "Platform", Start: "2025-Q1", End: "2025-Q2"},
+		{Name: "Billing", Lane: "Platform", Start: "2025-Q2"},
+		{Name: "Search", Lane: "Platform", Start: "2025-Q3", End: "2025-Q4"},
+		{Name: "App", Lane: "Mobile", Start: "2025-Q2", End: "2025-Q3"},
+	}, []Milestone{{Name: "GA", Lane: "Mobile", Quarter: "2025-Q4"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var quarters, lanes []string
+	for _, c := range g.Root {
+		if c.ParentID == rootCellID && c.isVertex() {
+			switch {
+			case strings.HasPrefix(c.ID, "quarter-"):
+				quarters = append(quarters, c.Value)
+			case strings.HasPrefix(c.ID, "lane-"):
+				lanes = append(lanes, c.ID)
+			}
+		}
+	}
+	if want := []string{"<b>Q1</b> 2025", "<b>Q2</b> 2025", "<b>Q3</b> 2025", "<b>Q4</b> 2025"}; !reflect.DeepEqual(quarters, want) {
+		t.Errorf("got quarters %q, want %q", quarters, want)
+	}
+	if want := []string{"lane-Platform", "lane-Empty", "lane-Mobile"}; !reflect.DeepEqual(lanes, want) {
+		t.Errorf("got lanes %q, want %q", lanes, want)
+	}
+
+	x0 := roadmapLaneTitle
+	tests := []struct {
+		id      string
+		x, y, w int
+		tooltip string
+	}{
+		{"lane-Platform-Auth", x0 + 4, 5, 2*roadmapQuarterWidth - 8, "Auth: 2025-Q1 – 2025-Q2"},
+		{"lane-Platform-Billing", x0 + roadmapQuarterWidth + 4, 5 + roadmapRowHeight, roadmapQuarterWidth - 8, "Billing: 2025-Q2 – 2025-Q2"},
+		{"lane-Platform-Search", x0 + 2*roadmapQuarterWidth + 4, 5, 2*roadmapQuarterWidth - 8, "Search: 2025-Q3 – 2025-Q4"},
+		{"lane-Mobile-App", x0 + roadmapQuarterWidth + 4, 5, 2*roadmapQuarterWidth - 8, "App: 2025-Q2 – 2025-Q3"},
+		{"lane-Mobile-GA", x0 + 3*roadmapQuarterWidth + 10, 10, roadmapBarHeight - 10, "GA: 2025-Q4"},
+	}
+	for _, tt := range tests {
+		c := g.FindByID(tt.id)
+		if c == nil {
+			t.Errorf("%s: missing", tt.id)
+			continue
+		}
+		w, _ := c.Geometry.size()
+		if c.Geometry.X != tt.x || c.Geometry.Y != tt.y || w != tt.w {
+			t.Errorf("%s: got x %d, y %d, width %d, want %d, %d, %d", tt.id, c.Geometry.X, c.Geometry.Y, w, tt.x, tt.y, tt.w)
+		}
+		if c.Data["tooltip"] != tt.tooltip {
+			t.Errorf("%s: got tooltip %q, want %q", tt.id, c.Data["tooltip"], tt.tooltip)
+		}
+	}
+	// lanes are as high as their rows and stacked below the axis
+	y := 10 + roadmapRowHeight
+	for _, l := range []struct {
+		id   string
+		rows int
+	}{{"lane-Platform", 2}, {"lane-Empty", 1}, {"lane-Mobile", 1}} {
+		c := g.FindByID(l.id)
+		_, h := c.Geometry.size()
+		if c.Geometry.Y != y || h != l.rows*roadmapRowHeight+10 {
+			t.Errorf("%s: got y %d, height %d, want %d, %d", l.id, c.Geometry.Y, h, y, l.rows*roadmapRowHeight+10)
+		}
+		y += h
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewRoadmapErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		initiatives []Initiative
+		milestones  []Milestone
+	}{
+		{"empty", nil, nil},
+		{"start", []Initiative{{Name: "a", Start: "Q1"}}, nil},
+		{"end", []Initiative{{Name: "a", Start: "2025-Q1", End: "2025"}}, nil},
+		{"backwards", []Initiative{{Name: "a", Start: "2025-Q2", End: "2025-Q1"}}, nil},
+		{"milestone", nil, []Milestone{{Name: "m", Quarter: "2025-Q9"}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewRoadmap(nil, tt.initiatives, tt.milestones); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
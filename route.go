@@ -0,0 +1,456 @@
+package graw
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// RouteOptions configures RouteOrthogonal. Zero values select the
+// defaults.
+type RouteOptions struct {
+	// Margin is the clearance kept around shapes. Defaults to 10.
+	Margin int
+	// BendPenalty is the cost of a bend, in pixels of extra length.
+	// Defaults to 40.
+	BendPenalty int
+	// Fast tries straight, L and Z shaped routes before searching
+	// and routes along the borders of the obstacles only instead of
+	// also using the middle of the gaps between them, trading route
+	// quality for speed on large diagrams.
+	Fast bool
+}
+
+// Limits of the search done for a single edge: the number of grid
+// points expanded, how often the searched area is enlarged and its
+// initial padding around the terminals.
+const (
+	routeMaxExpansions = 200000
+	routeSearchTries   = 3
+	routeSearchPad     = 200
+)
+
+// RouteOrthogonal routes every edge connecting two vertices along
+// horizontal and vertical segments around the other shapes of g,
+// storing the bends as waypoints of the edge geometry and fixing the
+// entry point on the target. Routes are searched on a sparse grid
+// made of the obstacle borders, and obstacles are looked up in a
+// quadtree, so large diagrams route in reasonable time.
+//
+// Edges for which no route was found keep their geometry; their IDs
+// are returned.
+func RouteOrthogonal(g *GraphModel, opts RouteOptions) []string {
+	if opts.Margin <= 0 {
+		opts.Margin = 10
+	}
+	if opts.BendPenalty <= 0 {
+		opts.BendPenalty = 40
+	}
+
+	r := newRouter(g, opts)
+	var failed []string
+	for i := range g.Root {
+		e := g.Root[i]
+		if !e.isEdge() || e.Source == e.Target {
+			continue
+		}
+		src, okS := r.shapes[e.Source]
+		dst, okT := r.shapes[e.Target]
+		if !okS || !okT {
+			continue
+		}
+		if !r.route(e, src, dst) {
+			failed = append(failed, e.ID)
+		}
+	}
+	return failed
+}
+
+// router holds the obstacles of a diagram.
+type router struct {
+	opts   RouteOptions
+	shapes map[string]Rect
+	tree   *quadtree
+	lookup func(id string) *Cell
+}
+
+// newRouter indexes the leaf vertices of g, inflated by the margin,
+// as obstacles. Containers and edge labels are not obstacles.
+func newRouter(g *GraphModel, opts RouteOptions) *router {
+	containers := make(map[string]bool)
+	for i := range g.Root {
+		if c := g.Root[i]; c.isVertex() {
+			containers[c.ParentID] = true
+		}
+	}
+
+	cells := g.cellMap()
+	lookup := func(id string) *Cell { return cells[id] }
+	r := &router{opts: opts, shapes: make(map[string]Rect), lookup: lookup}
+	var bounds Rect
+	for i := range g.Root {
+		c := g.Root[i]
+		if !c.isVertex() || c.Geometry == nil {
+			continue
+		}
+		if p := cells[c.ParentID]; p != nil && p.isEdge() {
+			continue
+		}
+		shape := GeometryRect(c.Geometry)
+		shape.X, shape.Y = offsetOf(c, lookup)
+		r.shapes[c.ID] = shape
+		if containers[c.ID] {
+			continue
+		}
+
+		bounds = bounds.Union(r.obstacle(shape))
+	}
+
+	r.tree = newQuadtree(bounds)
+	for id, s := range r.shapes {
+		if !containers[id] {
+			r.tree.insert(id, r.obstacle(s))
+		}
+	}
+	return r
+}
+
+// obstacle returns the area blocked by the shape s.
+func (r *router) obstacle(s Rect) Rect {
+	return s.Inset(-r.opts.Margin)
+}
+
+// clear reports whether the segment from (x0, y0) to (x1, y1) avoids
+// all obstacles but src and dst.
+func (r *router) clear(x0, y0, x1, y1 int, src, dst string) bool {
+	seg := Rect{minInt(x0, x1), minInt(y0, y1), absInt(x1-x0) + 1, absInt(y1-y0) + 1}
+	return r.tree.query(seg, func(it quadItem) bool {
+		return it.id == src || it.id == dst
+	})
+}
+
+// route computes and stores the route of edge e from src to dst. It
+// reports whether a route was found.
+func (r *router) route(e *Cell, src, dst Rect) bool {
+	sx, sy := src.X+src.Width/2, src.Y+src.Height/2
+	tx, ty := dst.X+dst.Width/2, dst.Y+dst.Height/2
+
+	var pts []Point
+	found := false
+	if r.opts.Fast {
+		pts, found = r.simpleRoute(sx, sy, tx, ty, e.Source, e.Target)
+	}
+	if found {
+		e.ClearEntryAnchor()
+	} else {
+		var dir int
+		pts, dir, found = r.search(sx, sy, src, dst, e.Source, e.Target)
+		if !found {
+			return false
+		}
+		last := Point{X: sx, Y: sy}
+		if len(pts) > 0 {
+			last = pts[len(pts)-1]
+		}
+		e.SetEntryAnchor(entryAnchor(last, dir, dst))
+	}
+	e.ClearExitAnchor()
+
+	if e.Geometry == nil {
+		e.Geometry = &Geometry{Relative: "1", As: "geometry"}
+	}
+	ox, oy := 0, 0
+	if p := r.lookup(e.ParentID); p != nil && p.isVertex() {
+		ox, oy = offsetOf(p, r.lookup)
+	}
+	for i := range pts {
+		pts[i].X -= ox
+		pts[i].Y -= oy
+	}
+	e.Geometry.Points = pts
+	delete(e.Style.Attributes, "edgeStyle")
+	return true
+}
+
+// simpleRoute tries a straight, L shaped and Z shaped route between
+// the centers of the terminals and returns the bends of the first
+// one which is clear.
+func (r *router) simpleRoute(sx, sy, tx, ty int, src, dst string) ([]Point, bool) {
+	mx, my := (sx+tx)/2, (sy+ty)/2
+	candidates := [][]Point{
+		{{X: tx, Y: sy}},
+		{{X: sx, Y: ty}},
+		{{X: mx, Y: sy}, {X: mx, Y: ty}},
+		{{X: sx, Y: my}, {X: tx, Y: my}},
+	}
+	if sx == tx || sy == ty {
+		candidates = append([][]Point{nil}, candidates...)
+	}
+
+	for _, bends := range candidates {
+		ok := true
+		px, py := sx, sy
+		for _, p := range append(bends, Point{X: tx, Y: ty}) {
+			if !r.clear(px, py, p.X, p.Y, src, dst) {
+				ok = false
+				break
+			}
+			px, py = p.X, p.Y
+		}
+		if ok {
+			return bends, true
+		}
+	}
+	return nil, false
+}
+
+// Directions of travel on the routing grid.
+var routeDirs = [4][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+
+// routeNode is a state of the grid search: the indices of a grid
+// point and the direction it was reached from.
+type routeNode struct {
+	i, j int
+	dir  int
+}
+
+// search runs an A* search from (sx, sy) until a point inside dst is
+// reached, enlarging the searched area when no route is found. It
+// returns the bends of the route and the direction of its final
+// segment.
+func (r *router) search(sx, sy int, src, dst Rect, srcID, dstID string) ([]Point, int, bool) {
+	area := src.Union(dst)
+	pad := routeSearchPad
+	for try := 0; try < routeSearchTries; try++ {
+		limit := area.Inset(-pad)
+		if pts, dir, ok := r.astar(sx, sy, dst, srcID, dstID, limit); ok {
+			return pts, dir, true
+		}
+		pad *= 4
+	}
+	return nil, 0, false
+}
+
+// grid returns the sorted x and y coordinates of the routing grid
+// within limit: the borders of the obstacles just outside their
+// margin, the given extra coordinates and, unless routing fast, the
+// middle between neighbouring coordinates.
+func (r *router) grid(limit Rect, xs, ys []int) ([]int, []int) {
+	r.tree.query(limit, func(it quadItem) bool {
+		b := it.bounds
+		xs = append(xs, b.X-1, b.X+b.Width)
+		ys = append(ys, b.Y-1, b.Y+b.Height)
+		return true
+	})
+	return r.axis(xs, limit.X, limit.X+limit.Width), r.axis(ys, limit.Y, limit.Y+limit.Height)
+}
+
+// axis sorts and deduplicates the coordinates in vs, drops the ones
+// outside [lo, hi] and adds midpoints unless routing fast.
+func (r *router) axis(vs []int, lo, hi int) []int {
+	sort.Ints(vs)
+	out := vs[:0]
+	for _, v := range vs {
+		if v >= lo && v <= hi && (len(out) == 0 || out[len(out)-1] != v) {
+			out = append(out, v)
+		}
+	}
+	if r.opts.Fast {
+		return out
+	}
+
+	mid := make([]int, 0, 2*len(out))
+	for i, v := range out {
+		if i > 0 && v-out[i-1] > 1 {
+			mid = append(mid, (v+out[i-1])/2)
+		}
+		mid = append(mid, v)
+	}
+	return mid
+}
+
+// astar searches the grid within limit, see search.
+func (r *router) astar(sx, sy int, dst Rect, src, dstID string, limit Rect) ([]Point, int, bool) {
+	// Aim at the middle half of the target so that routes do not
+	// end on its corners.
+	goal := Rect{dst.X + dst.Width/4, dst.Y + dst.Height/4, dst.Width / 2, dst.Height / 2}
+	tx, ty := dst.X+dst.Width/2, dst.Y+dst.Height/2
+
+	xs, ys := r.grid(limit, []int{sx, tx, goal.X, goal.X + goal.Width}, []int{sy, ty, goal.Y, goal.Y + goal.Height})
+	at := func(n routeNode) (int, int) { return xs[n.i], ys[n.j] }
+	h := func(x, y, dir int) int {
+		dx := maxInt(0, goal.X-x) - maxInt(0, x-(goal.X+goal.Width))
+		dy := maxInt(0, goal.Y-y) - maxInt(0, y-(goal.Y+goal.Height))
+		return absInt(dx) + absInt(dy) + r.opts.BendPenalty*minBends(dir, dx, dy)
+	}
+
+	start := routeNode{i: sort.SearchInts(xs, sx), j: sort.SearchInts(ys, sy), dir: -1}
+	cost := map[routeNode]int{start: 0}
+	prev := make(map[routeNode]routeNode)
+	open := &routeQueue{{node: start, f: h(sx, sy, -1)}}
+
+	for expansions := 0; open.Len() > 0 && expansions < routeMaxExpansions; expansions++ {
+		cur := heap.Pop(open).(routeItem)
+		if cur.g > cost[cur.node] {
+			continue
+		}
+		cx, cy := at(cur.node)
+		if cur.node != start && goal.ContainsPoint(cx, cy) {
+			return bends(cur.node, prev, at), cur.node.dir, true
+		}
+
+		for d, dv := range routeDirs {
+			if cur.node.dir >= 0 && (d+2)%4 == cur.node.dir {
+				continue
+			}
+			next := routeNode{i: cur.node.i + dv[0], j: cur.node.j + dv[1], dir: d}
+			if next.i < 0 || next.i >= len(xs) || next.j < 0 || next.j >= len(ys) {
+				continue
+			}
+			nx, ny := at(next)
+			if !r.clear(cx, cy, nx, ny, src, dstID) {
+				continue
+			}
+			gc := cur.g + absInt(nx-cx) + absInt(ny-cy)
+			if cur.node.dir >= 0 && cur.node.dir != d {
+				gc += r.opts.BendPenalty
+			}
+			if old, ok := cost[next]; ok && old <= gc {
+				continue
+			}
+			cost[next] = gc
+			prev[next] = cur.node
+			heap.Push(open, routeItem{node: next, g: gc, f: gc + h(nx, ny, d)})
+		}
+	}
+	return nil, 0, false
+}
+
+// minBends returns the least number of bends needed to travel dx
+// horizontally and dy vertically when heading in direction dir, or
+// in any direction for a negative dir.
+func minBends(dir, dx, dy int) int {
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+	if dir < 0 {
+		if dx != 0 && dy != 0 {
+			return 1
+		}
+		return 0
+	}
+
+	// whether dir leads towards the target on either axis
+	along := (dir == 0 && dx > 0) || (dir == 2 && dx < 0) || (dir == 1 && dy > 0) || (dir == 3 && dy < 0)
+	switch {
+	case dx != 0 && dy != 0:
+		if along {
+			return 1
+		}
+		return 2
+	case along:
+		return 0
+	case (dir%2 == 0) == (dx != 0):
+		// heading away from the target on its own axis
+		return 2
+	default:
+		return 1
+	}
+}
+
+// bends walks back from end and returns the points where the route
+// changes direction, in order from the source.
+func bends(end routeNode, prev map[routeNode]routeNode, at func(routeNode) (int, int)) []Point {
+	var pts []Point
+	for n := end; ; {
+		p, ok := prev[n]
+		if !ok {
+			break
+		}
+		if p.dir >= 0 && p.dir != n.dir {
+			x, y := at(p)
+			pts = append([]Point{{X: x, Y: y}}, pts...)
+		}
+		n = p
+	}
+	return pts
+}
+
+// entryAnchor returns where a route whose last segment starts at last
+// and travels in direction dir enters dst.
+func entryAnchor(last Point, dir int, dst Rect) Anchor {
+	a := Anchor{Perimeter: true}
+	switch dir {
+	case 0, 2:
+		a.Y = clamp01(float64(last.Y-dst.Y) / float64(dst.Height))
+		if dir == 2 {
+			a.X = 1
+		}
+	default:
+		a.X = clamp01(float64(last.X-dst.X) / float64(dst.Width))
+		if dir == 3 {
+			a.Y = 1
+		}
+	}
+	return a
+}
+
+// routeItem is an entry of the A* open list.
+type routeItem struct {
+	node routeNode
+	g, f int
+}
+
+// routeQueue is a priority queue of routeItems ordered by f.
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int { return len(q) }
+func (q routeQueue) Less(i, j int) bool {
+	// prefer deeper nodes on ties, which keeps the search narrow
+	if q[i].f == q[j].f {
+		return q[i].g > q[j].g
+	}
+	return q[i].f < q[j].f
+}
+func (q routeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x interface{}) { *q = append(*q, x.(routeItem)) }
+func (q *routeQueue) Pop() interface{} {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// absInt returns the absolute value of a.
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// clamp01 limits v to the range [0, 1].
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,131 @@
+package graw
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// routeModel returns a model of n shapes in a grid and m edges between
+// shapes at most three rows and columns apart, chosen at random with a
+// fixed seed.
+func routeModel(n, m int) *GraphModel {
+	g := NewGraph()
+	cols := 1
+	for cols*cols < n {
+		cols++
+	}
+	for i := 0; i < n; i++ {
+		c := NewShape("s"+strconv.Itoa(i), "1").At(i%cols*160, i/cols*100).Size(80, 40)
+		g.Add(c)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < m; i++ {
+		s := rnd.Intn(n)
+		t := s + (rnd.Intn(7)-3)*cols + rnd.Intn(7) - 3
+		if t < 0 || t >= n || t == s {
+			t = (s + 1) % n
+		}
+		g.Add(NewEdge("e"+strconv.Itoa(i), "1", "s"+strconv.Itoa(s), "s"+strconv.Itoa(t)))
+	}
+	return &g
+}
+
+func TestRouteOrthogonalAvoidsObstacles(t *testing.T) {
+	for _, fast := range []bool{false, true} {
+		g := NewGraph()
+		g.Add(NewShape("a", "1").At(0, 0).Size(80, 40))
+		g.Add(NewShape("wall", "1").At(160, -100).Size(40, 240))
+		g.Add(NewShape("b", "1").At(280, 0).Size(80, 40))
+		g.Add(NewEdge("e", "1", "a", "b"))
+
+		if failed := RouteOrthogonal(&g, RouteOptions{Fast: fast}); len(failed) > 0 {
+			t.Fatalf("fast %v: failed %v", fast, failed)
+		}
+		pts := append([]Point{{X: 40, Y: 20}}, g.FindByID("e").Geometry.Points...)
+		if len(pts) < 3 {
+			t.Fatalf("fast %v: route %v goes through the wall", fast, pts)
+		}
+		wall := Rect{X: 160, Y: -100, Width: 40, Height: 240}
+		for i := 1; i < len(pts); i++ {
+			p, q := pts[i-1], pts[i]
+			if p.X != q.X && p.Y != q.Y {
+				t.Errorf("fast %v: segment %v-%v is not orthogonal", fast, p, q)
+			}
+			seg := Rect{X: minInt(p.X, q.X), Y: minInt(p.Y, q.Y), Width: absInt(q.X-p.X) + 1, Height: absInt(q.Y-p.Y) + 1}
+			if seg.Intersects(wall) {
+				t.Errorf("fast %v: segment %v-%v crosses the wall", fast, p, q)
+			}
+		}
+	}
+}
+
+func TestRouteOrthogonalStraight(t *testing.T) {
+	g := NewGraph()
+	g.Add(NewShape("a", "1").At(0, 0).Size(80, 40))
+	g.Add(NewShape("b", "1").At(280, 0).Size(80, 40))
+	g.Add(NewEdge("e", "1", "a", "b"))
+	if failed := RouteOrthogonal(&g, RouteOptions{Fast: true}); len(failed) > 0 {
+		t.Fatalf("failed %v", failed)
+	}
+	if pts := g.FindByID("e").Geometry.Points; len(pts) != 0 {
+		t.Errorf("got bends %v, want a straight line", pts)
+	}
+}
+
+func TestQuadtreeQuery(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	q := newQuadtree(Rect{Width: 1000, Height: 1000})
+	var all []quadItem
+	for i := 0; i < 500; i++ {
+		it := quadItem{strconv.Itoa(i), Rect{X: rnd.Intn(1100) - 50, Y: rnd.Intn(1100) - 50, Width: 1 + rnd.Intn(60), Height: 1 + rnd.Intn(60)}}
+		all = append(all, it)
+		q.insert(it.id, it.bounds)
+	}
+	for i := 0; i < 50; i++ {
+		r := Rect{X: rnd.Intn(1000), Y: rnd.Intn(1000), Width: 1 + rnd.Intn(200), Height: 1 + rnd.Intn(200)}
+		got := make(map[string]bool)
+		q.query(r, func(it quadItem) bool {
+			got[it.id] = true
+			return true
+		})
+		want := 0
+		for _, it := range all {
+			if it.bounds.Intersects(r) {
+				want++
+				if !got[it.id] {
+					t.Errorf("query %v misses %v", r, it.bounds)
+				}
+			}
+		}
+		if len(got) != want {
+			t.Errorf("query %v: got %d items, want %d", r, len(got), want)
+		}
+	}
+}
+
+// benchmarkRoute routes models of increasing size with opts,
+// reporting the edges it could not route too.
+func benchmarkRoute(b *testing.B, opts RouteOptions) {
+	for _, size := range []struct{ shapes, edges int }{{500, 1000}, {5000, 10000}} {
+		g := routeModel(size.shapes, size.edges)
+		b.Run(fmt.Sprintf("shapes=%d/edges=%d", size.shapes, size.edges), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				cp := g.clone()
+				b.StartTimer()
+				failed := RouteOrthogonal(&cp, opts)
+				b.ReportMetric(float64(len(failed)), "failed/op")
+			}
+		})
+	}
+}
+
+func BenchmarkRouteOrthogonal(b *testing.B) {
+	benchmarkRoute(b, RouteOptions{})
+}
+
+func BenchmarkRouteOrthogonalFast(b *testing.B) {
+	benchmarkRoute(b, RouteOptions{Fast: true})
+}
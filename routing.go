@@ -0,0 +1,275 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Router is a router of a routing topology with its neighbors, as
+// listed by e.g. "show ip ospf neighbor" and "show bgp summary".
+type Router struct {
+	// ID is the router ID, which neighbors refer to.
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// AS is the autonomous system of the router, 0 if unknown.
+	AS        uint32            `json:"as,omitempty"`
+	Neighbors []RoutingNeighbor `json:"neighbors,omitempty"`
+}
+
+// RoutingNeighbor is an adjacency of a Router: an OSPF neighbor when
+// it has an Area, otherwise a BGP peer.
+type RoutingNeighbor struct {
+	// ID is the router ID of the neighbor.
+	ID string `json:"id"`
+	// AS is the autonomous system of a BGP peer.
+	AS   uint32 `json:"as,omitempty"`
+	Area string `json:"area,omitempty"`
+	// Cost is the OSPF cost of the link to the neighbor, or the BGP
+	// metric.
+	Cost int `json:"cost,omitempty"`
+	// State is the state of the adjacency; neighbors neither "Full"
+	// nor "Established", which mean up, are drawn in red.
+	State string `json:"state,omitempty"`
+}
+
+// routingLink is a link between two routers, merging the adjacencies
+// both of them report.
+type routingLink struct {
+	a, b   string
+	ospf   bool
+	costs  []string
+	states []string
+}
+
+// routingKey identifies the link of a protocol between two routers,
+// whose IDs are in order.
+type routingKey struct {
+	a, b string
+	ospf bool
+}
+
+// FromRoutingJSON returns the topology of the routers of a JSON array
+// of Router objects, or of an object holding them as "routers", see
+// NewRoutingTopology.
+func FromRoutingJSON(r io.Reader) (*GraphModel, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("graw: FromRoutingJSON: %v", err)
+	}
+	var routers []Router
+	if b := bytes.TrimSpace(data); len(b) > 0 && b[0] == '[' {
+		err = json.Unmarshal(b, &routers)
+	} else {
+		var doc struct{ Routers []Router }
+		err = json.Unmarshal(b, &doc)
+		routers = doc.Routers
+	}
+	if err != nil {
+		return nil, fmt.Errorf("graw: FromRoutingJSON: %v", err)
+	}
+	return NewRoutingTopology(routers)
+}
+
+// NewRoutingTopology returns the topology of routers: each autonomous
+// system is a container holding a container per OSPF area, and every
+// router is drawn in the area of its OSPF adjacencies, area border
+// routers in the backbone. Links are labeled with their cost, both
+// costs when they differ by direction; BGP sessions are dashed, thick
+// between autonomous systems. Adjacencies which are not up are red.
+// Neighbors missing from routers are drawn from what their peers say.
+func NewRoutingTopology(routers []Router) (*GraphModel, error) {
+	byID := make(map[string]*Router, len(routers))
+	var order []*Router
+	for i := range routers {
+		r := &routers[i]
+		if r.ID == "" {
+			return nil, fmt.Errorf("graw: NewRoutingTopology: router %d has no ID", i)
+		}
+		if byID[r.ID] != nil {
+			return nil, fmt.Errorf("graw: NewRoutingTopology: duplicate router %q", r.ID)
+		}
+		byID[r.ID] = r
+		order = append(order, r)
+	}
+	for _, r := range order {
+		for _, n := range r.Neighbors {
+			if byID[n.ID] == nil {
+				peer := &Router{ID: n.ID, AS: n.AS}
+				if n.Area != "" {
+					peer.AS = r.AS
+				}
+				byID[n.ID] = peer
+				order = append(order, peer)
+			}
+		}
+	}
+
+	// the area of a router: the backbone if it has an adjacency there,
+	// else its first OSPF area
+	area := make(map[string]string)
+	links := make(map[routingKey]*routingLink)
+	var linkOrder []*routingLink
+	for _, r := range order {
+		for _, n := range r.Neighbors {
+			if n.Area != "" {
+				for _, id := range []string{r.ID, n.ID} {
+					if a, ok := area[id]; !ok || !isBackbone(a) && isBackbone(n.Area) {
+						area[id] = n.Area
+					}
+				}
+			}
+			if n.ID == r.ID {
+				continue
+			}
+			key := routingKey{r.ID, n.ID, n.Area != ""}
+			if key.a > key.b {
+				key.a, key.b = key.b, key.a
+			}
+			l, ok := links[key]
+			if !ok {
+				l = &routingLink{a: r.ID, b: n.ID, ospf: n.Area != ""}
+				links[key] = l
+				linkOrder = append(linkOrder, l)
+			}
+			if c := strconv.Itoa(n.Cost); n.Cost != 0 && !containsString(l.costs, c) {
+				l.costs = append(l.costs, c)
+			}
+			if n.State != "" && !containsString(l.states, n.State) {
+				l.states = append(l.states, n.State)
+			}
+		}
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	var containers, cells []*Cell
+	// members holds the cells of each container, top the cells at the
+	// top level, in the order they are laid out
+	members := make(map[*Cell][]*Cell)
+	var top []*Cell
+	asCells := make(map[uint32]*Cell)
+	areaCells := make(map[string]*Cell)
+	ids := make(map[string]string, len(order))
+	for _, r := range order {
+		var parent *Cell
+		if r.AS != 0 {
+			parent = asCells[r.AS]
+			if parent == nil {
+				title := "AS " + strconv.FormatUint(uint64(r.AS), 10)
+				parent = newContainer(taken.unique("as"+strconv.FormatUint(uint64(r.AS), 10)), rootCellID, title)
+				asCells[r.AS] = parent
+				containers = append(containers, parent)
+				top = append(top, parent)
+			}
+		}
+		if a, ok := area[r.ID]; ok {
+			key := strconv.FormatUint(uint64(r.AS), 10) + "\x00" + a
+			c := areaCells[key]
+			if c == nil {
+				c = newContainer(taken.unique("area-"+a), rootCellID, "Area "+html.EscapeString(a))
+				c.Style.set("dashed", "1")
+				if parent != nil {
+					c.ParentID = parent.ID
+					members[parent] = append(members[parent], c)
+				} else {
+					top = append(top, c)
+				}
+				areaCells[key] = c
+				containers = append(containers, c)
+			}
+			parent = c
+		}
+
+		c := NewShape(taken.unique(r.ID), rootCellID)
+		name := r.Name
+		if name == "" {
+			name = r.ID
+		}
+		c.Value = "<b>" + html.EscapeString(name) + "</b>"
+		if r.Name != "" {
+			c.Value += "<br>" + html.EscapeString(r.ID)
+		}
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		if area[r.ID] != "" && isBackbone(area[r.ID]) {
+			c.Style.set("fillColor", "#dae8fc")
+		}
+		SizeToFit(c, nil, defaultWidth, 40)
+		ids[r.ID] = c.ID
+		cells = append(cells, c)
+		if parent != nil {
+			c.ParentID = parent.ID
+			members[parent] = append(members[parent], c)
+		} else {
+			top = append(top, c)
+		}
+	}
+
+	// containers are laid out innermost first
+	var layout func(c *Cell)
+	layout = func(c *Cell) {
+		for _, m := range members[c] {
+			if members[m] != nil {
+				layout(m)
+			}
+		}
+		w, h := layoutGrid(members[c], groupPadding, groupHeader+groupPadding, groupGap)
+		c.Geometry.setSize(maxInt(w+2*groupPadding, defaultWidth), h+groupHeader+2*groupPadding)
+	}
+	for _, c := range top {
+		if members[c] != nil {
+			layout(c)
+		}
+	}
+	layoutGrid(top, 10, 10, groupGap)
+
+	var edges []*Cell
+	for _, l := range linkOrder {
+		e := newEdgeCell(taken.unique(ids[l.a]+"-"+ids[l.b]), rootCellID, ids[l.a], ids[l.b])
+		e.Style.set("endArrow", "none")
+		e.Value = strings.Join(l.costs, " / ")
+		if !l.ospf {
+			e.Style.set("dashed", "1")
+			if byID[l.a].AS != byID[l.b].AS {
+				e.Style.setFloat("strokeWidth", 2)
+			}
+		}
+		for _, s := range l.states {
+			if s = strings.ToLower(s); !strings.HasPrefix(s, "full") && s != "established" {
+				e.Style.set("strokeColor", "#ff0000")
+			}
+		}
+		if len(l.states) > 0 {
+			proto := "OSPF"
+			if !l.ospf {
+				proto = "BGP"
+			}
+			e.Data = map[string]string{"tooltip": proto + " " + strings.Join(l.states, " / ")}
+		}
+		edges = append(edges, e)
+	}
+
+	for _, c := range append(append(containers, cells...), edges...) {
+		g.Add(c)
+	}
+	return &g, nil
+}
+
+// isBackbone reports whether area is the OSPF backbone, area 0.
+func isBackbone(area string) bool {
+	return area == "0" || area == "0.0.0.0"
+}
+
+// containsString reports whether list holds s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
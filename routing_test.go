@@ -0,0 +1,108 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromRoutingJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		parents map[string]string
+		edges   []string
+		// styles maps cell IDs to some of their style attributes.
+		styles map[string]map[string]string
+	}{
+		{
+			name: "ospf and bgp",
+			in: `{"routers":[
+ {"id":"1.1.1.1","name":"r1","as":65001,"neighbors":[{"id":"2.2.2.2","area":"0","cost":10,"state":"Full/DR"},{"id":"9.9.9.9","as":65002,"state":"Idle"}]},
+ {"id":"2.2.2.2","as":65001,"neighbors":[{"id":"1.1.1.1","area":"0","cost":20,"state":"Full/BDR"},{"id":"3.3.3.3","area":"1","cost":5}]},
+ {"id":"3.3.3.3","as":65001,"neighbors":[{"id":"2.2.2.2","area":"1","cost":5}]}
+]}`,
+			parents: map[string]string{
+				"as65001": "1",
+				"as65002": "1",
+				"area-0":  "as65001",
+				"area-1":  "as65001",
+				"1.1.1.1": "area-0",
+				"2.2.2.2": "area-0",
+				"3.3.3.3": "area-1",
+				"9.9.9.9": "as65002",
+			},
+			edges: []string{
+				"1.1.1.1->2.2.2.2:10 / 20",
+				"1.1.1.1->9.9.9.9",
+				"2.2.2.2->3.3.3.3:5",
+			},
+			styles: map[string]map[string]string{
+				"1.1.1.1":         {"fillColor": "#dae8fc"},
+				"2.2.2.2":         {"fillColor": "#dae8fc"},
+				"3.3.3.3":         {"fillColor": ""},
+				"1.1.1.1-2.2.2.2": {"dashed": "", "strokeColor": ""},
+				"1.1.1.1-9.9.9.9": {"dashed": "1", "strokeWidth": "2", "strokeColor": "#ff0000"},
+			},
+		},
+		{
+			name: "ibgp array",
+			in: `[
+ {"id":"a","as":1,"neighbors":[{"id":"b","as":1,"state":"Established"}]},
+ {"id":"b","as":1}
+]`,
+			parents: map[string]string{
+				"as1": "1",
+				"a":   "as1",
+				"b":   "as1",
+			},
+			edges: []string{"a->b"},
+			styles: map[string]map[string]string{
+				"a-b": {"dashed": "1", "strokeWidth": "", "strokeColor": ""},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromRoutingJSON(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			parents := make(map[string]string)
+			for _, c := range g.Root {
+				if c.isVertex() {
+					parents[c.ID] = c.ParentID
+				}
+			}
+			if !reflect.DeepEqual(parents, tt.parents) {
+				t.Errorf("got parents %v, want %v", parents, tt.parents)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %q, want %q", got, tt.edges)
+			}
+			for id, attrs := range tt.styles {
+				c := g.FindByID(id)
+				for k, want := range attrs {
+					if got := c.Style.Attributes[k]; got != want {
+						t.Errorf("%s: got %s %q, want %q", id, k, got, want)
+					}
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestRoutingErrors(t *testing.T) {
+	for _, in := range []string{
+		`{"routers":`,
+		`[{"name":"r1"}]`,
+		`[{"id":"a"},{"id":"a"}]`,
+	} {
+		if _, err := FromRoutingJSON(strings.NewReader(in)); err == nil {
+			t.Errorf("FromRoutingJSON(%s): got no error", in)
+		}
+	}
+}
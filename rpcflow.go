@@ -0,0 +1,220 @@
+package graw
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RPCCall is a structured call log record: Count calls of RPC made by
+// the service Caller to the service Callee.
+type RPCCall struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+	// RPC is the full name of the method, e.g.
+	// "/helloworld.Greeter/SayHello".
+	RPC   string `json:"rpc"`
+	Count int    `json:"count"`
+}
+
+// RPCFlowOptions controls the diagrams of NewRPCFlow.
+type RPCFlowOptions struct {
+	// TopEdges keeps only the given number of caller and callee pairs
+	// with the most calls, and the services they connect. All pairs
+	// are kept when it is zero.
+	TopEdges int
+	// MinCount drops the pairs with fewer calls.
+	MinCount int
+	// TopRPCs is the number of RPCs listed in the label of a pair, by
+	// number of calls, 3 if zero; all of them are listed in its
+	// tooltip.
+	TopRPCs int
+}
+
+// rpcPair is the calls from a service to another.
+type rpcPair struct {
+	caller, callee string
+	total          int
+	rpcs           map[string]int
+}
+
+// ReadRPCCalls reads call log records from r, a stream or an array of
+// JSON objects with the fields of RPCCall; "method" is read as "rpc"
+// when there is no "rpc". Records without a count are a single call.
+func ReadRPCCalls(r io.Reader) ([]RPCCall, error) {
+	var calls []RPCCall
+	dec := json.NewDecoder(r)
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("graw: ReadRPCCalls: %v", err)
+		}
+		records := jsonArray(doc)
+		if records == nil {
+			records = []interface{}{doc}
+		}
+		for _, v := range records {
+			rec := jsonObject(v)
+			c := RPCCall{Caller: jsonString(rec, "caller"), Callee: jsonString(rec, "callee"), RPC: jsonString(rec, "rpc"), Count: 1}
+			if c.RPC == "" {
+				c.RPC = jsonString(rec, "method")
+			}
+			if n, ok := rec["count"].(float64); ok {
+				c.Count = int(n)
+			}
+			calls = append(calls, c)
+		}
+	}
+	return calls, nil
+}
+
+// NewRPCFlow returns a service interaction diagram of call logs: a box
+// per service, callers left of the services they call, and an arrow
+// per caller and callee pair, as thick as its share of the calls and
+// labeled with their number and the RPCs called most. The records of
+// the same pair and RPC are summed; records without caller or callee
+// are ignored.
+func NewRPCFlow(calls []RPCCall, opts RPCFlowOptions) (*GraphModel, error) {
+	if opts.TopRPCs <= 0 {
+		opts.TopRPCs = 3
+	}
+	var pairs []*rpcPair
+	byKey := make(map[[2]string]*rpcPair)
+	for _, c := range calls {
+		if c.Caller == "" || c.Callee == "" || c.Count <= 0 {
+			continue
+		}
+		key := [2]string{c.Caller, c.Callee}
+		p, ok := byKey[key]
+		if !ok {
+			p = &rpcPair{caller: c.Caller, callee: c.Callee, rpcs: make(map[string]int)}
+			byKey[key] = p
+			pairs = append(pairs, p)
+		}
+		p.total += c.Count
+		p.rpcs[c.RPC] += c.Count
+	}
+
+	kept := pairs[:0]
+	for _, p := range pairs {
+		if p.total >= opts.MinCount {
+			kept = append(kept, p)
+		}
+	}
+	pairs = kept
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].total > pairs[j].total })
+	if opts.TopEdges > 0 && len(pairs) > opts.TopEdges {
+		pairs = pairs[:opts.TopEdges]
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("graw: NewRPCFlow: no calls")
+	}
+
+	var services []string
+	deps := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, p := range pairs {
+		for _, s := range []string{p.caller, p.callee} {
+			if !seen[s] {
+				seen[s] = true
+				services = append(services, s)
+			}
+		}
+		if p.caller != p.callee {
+			deps[p.caller] = append(deps[p.caller], p.callee)
+		}
+	}
+	sort.Strings(services)
+	_, _, column := dependencyColumns(services, deps)
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string, len(services))
+	var columns [][]*Cell
+	for _, s := range services {
+		c := NewShape(taken.unique(s), rootCellID)
+		c.Value = "<b>" + html.EscapeString(s) + "</b>"
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		c.Style.set("fillColor", "#dae8fc")
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		ids[s] = c.ID
+		for len(columns) <= column[s] {
+			columns = append(columns, nil)
+		}
+		columns[column[s]] = append(columns[column[s]], c)
+	}
+	layoutColumns(columns, 10, 10, 120)
+	for _, col := range columns {
+		for _, c := range col {
+			g.Add(c)
+		}
+	}
+
+	max := pairs[0].total
+	for _, p := range pairs {
+		from, to := ids[p.caller], ids[p.callee]
+		e := newEdgeCell(taken.unique(from+"-"+to), rootCellID, from, to)
+		rpcs := make([]string, 0, len(p.rpcs))
+		for rpc := range p.rpcs {
+			rpcs = append(rpcs, rpc)
+		}
+		sort.Slice(rpcs, func(i, j int) bool {
+			if p.rpcs[rpcs[i]] != p.rpcs[rpcs[j]] {
+				return p.rpcs[rpcs[i]] > p.rpcs[rpcs[j]]
+			}
+			return rpcs[i] < rpcs[j]
+		})
+		lines := []string{"<b>" + strconv.Itoa(p.total) + "</b>"}
+		tooltip := make([]string, len(rpcs))
+		for i, rpc := range rpcs {
+			name := rpc
+			if name == "" {
+				name = "unknown"
+			}
+			tooltip[i] = name + ": " + strconv.Itoa(p.rpcs[rpc])
+			if i < opts.TopRPCs {
+				lines = append(lines, html.EscapeString(rpcMethod(name))+" "+strconv.Itoa(p.rpcs[rpc]))
+			}
+		}
+		if len(rpcs) > opts.TopRPCs {
+			lines = append(lines, "+"+strconv.Itoa(len(rpcs)-opts.TopRPCs)+" more")
+		}
+		e.Value = strings.Join(lines, "<br>")
+		e.Style.set("fontSize", "10")
+		e.Style.set("labelBackgroundColor", "#ffffff")
+		width := 1.0
+		if max > 1 {
+			width += 5 * math.Log(float64(p.total)) / math.Log(float64(max))
+		}
+		e.Style.setFloat("strokeWidth", math.Round(width*10)/10)
+		e.Data = map[string]string{"tooltip": strings.Join(tooltip, "\n")}
+		g.Add(e)
+	}
+	return &g, nil
+}
+
+// rpcMethod returns the short name of the method rpc, e.g.
+// "Greeter/SayHello" for "/helloworld.Greeter/SayHello".
+func rpcMethod(rpc string) string {
+	rpc = strings.TrimPrefix(rpc, "/")
+	service, method := "", rpc
+	if i := strings.LastIndexByte(rpc, '/'); i >= 0 {
+		service, method = rpc[:i], rpc[i+1:]
+	} else if i := strings.LastIndexByte(rpc, '.'); i >= 0 {
+		service, method = rpc[:i], rpc[i+1:]
+	}
+	if i := strings.LastIndexByte(service, '.'); i >= 0 {
+		service = service[i+1:]
+	}
+	if service == "" {
+		return method
+	}
+	return service + "/" + method
+}
@@ -0,0 +1,143 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRPCCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []RPCCall
+	}{
+		{
+			name: "stream",
+			in: `{"caller": "web", "callee": "users", "rpc": "/users.Users/Get", "count": 3}
+{"caller": "web", "callee": "users", "method": "users.Users.List"}`,
+			want: []RPCCall{
+				{Caller: "web", Callee: "users", RPC: "/users.Users/Get", Count: 3},
+				{Caller: "web", Callee: "users", RPC: "users.Users.List", Count: 1},
+			},
+		},
+		{
+			name: "array",
+			in:   `[{"caller": "a", "callee": "b", "rpc": "x", "method": "y"}, {"callee": "b"}]`,
+			want: []RPCCall{{Caller: "a", Callee: "b", RPC: "x", Count: 1}, {Callee: "b", Count: 1}},
+		},
+		{name: "empty", in: ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadRPCCalls(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if _, err := ReadRPCCalls(strings.NewReader(`{"caller": `)); err == nil {
+		t.Error("got no error")
+	}
+}
+
+func TestRPCMethod(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"/helloworld.Greeter/SayHello", "Greeter/SayHello"},
+		{"Greeter/SayHello", "Greeter/SayHello"},
+		{"helloworld.Greeter.SayHello", "Greeter/SayHello"},
+		{"SayHello", "SayHello"},
+		{"/SayHello", "SayHello"},
+	}
+	for _, tt := range tests {
+		if got := rpcMethod(tt.in); got != tt.want {
+			t.Errorf("rpcMethod(%q): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewRPCFlow(t *testing.T) {
+	calls := []RPCCall{
+		{Caller: "web", Callee: "users", RPC: "/users.Users/Get", Count: 60},
+		{Caller: "web", Callee: "users", RPC: "/users.Users/List", Count: 30},
+		{Caller: "web", Callee: "users", RPC: "/users.Users/Get", Count: 40},
+		{Caller: "web", Callee: "users", RPC: "/users.Users/Delete", Count: 1},
+		{Caller: "web", Callee: "users", Count: 1},
+		{Caller: "users", Callee: "db", RPC: "Query", Count: 10},
+		{Caller: "cron", Callee: "users", RPC: "/users.Users/Purge", Count: 1},
+		{Caller: "users", Callee: "users", RPC: "Self", Count: 2},
+		{Caller: "", Callee: "users", RPC: "x", Count: 5},
+		{Caller: "web", Callee: "db", RPC: "x", Count: 0},
+	}
+	tests := []struct {
+		name   string
+		opts   RPCFlowOptions
+		edges  []string
+		widths map[string]float64
+	}{
+		{
+			name: "all",
+			edges: []string{
+				"web->users:<b>132</b><br>Users/Get 100<br>Users/List 30<br>unknown 1<br>+1 more",
+				"users->db:<b>10</b><br>Query 10",
+				"users->users:<b>2</b><br>Self 2",
+				"cron->users:<b>1</b><br>Users/Purge 1",
+			},
+			widths: map[string]float64{"web-users": 6, "cron-users": 1, "users-db": 3.4},
+		},
+		{
+			name:  "top",
+			opts:  RPCFlowOptions{TopEdges: 2, TopRPCs: 1},
+			edges: []string{"web->users:<b>132</b><br>Users/Get 100<br>+3 more", "users->db:<b>10</b><br>Query 10"},
+		},
+		{
+			name:  "min count",
+			opts:  RPCFlowOptions{MinCount: 10, TopRPCs: 5},
+			edges: []string{"web->users:<b>132</b><br>Users/Get 100<br>Users/List 30<br>unknown 1<br>Users/Delete 1", "users->db:<b>10</b><br>Query 10"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewRPCFlow(calls, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %q, want %q", got, tt.edges)
+			}
+			for id, want := range tt.widths {
+				if got, _ := g.FindByID(id).Style.float("strokeWidth"); got != want {
+					t.Errorf("%s: got strokeWidth %v, want %v", id, got, want)
+				}
+			}
+			// callers are left of the services they call
+			x := func(id string) int { return g.FindByID(id).Geometry.X }
+			if !(x("web") < x("users") && x("users") < x("db")) {
+				t.Errorf("got columns %d, %d, %d", x("web"), x("users"), x("db"))
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewRPCFlowTooltip(t *testing.T) {
+	g, err := NewRPCFlow([]RPCCall{
+		{Caller: "a", Callee: "b", RPC: "/p.S/M", Count: 2},
+		{Caller: "a", Callee: "b", Count: 1},
+		{Caller: "a", Callee: "b", RPC: "/p.S/N", Count: 2},
+	}, RPCFlowOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.FindByID("a-b").Data["tooltip"], "/p.S/M: 2\n/p.S/N: 2\nunknown: 1"; got != want {
+		t.Errorf("got tooltip %q, want %q", got, want)
+	}
+	if _, err := NewRPCFlow([]RPCCall{{Caller: "a", Callee: "b", Count: 1}}, RPCFlowOptions{MinCount: 2}); err == nil {
+		t.Error("got no error")
+	}
+}
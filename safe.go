@@ -0,0 +1,226 @@
+package graw
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrLimit is wrapped by the errors of a SafeBuilder refusing a cell.
+var ErrLimit = errors.New("graw: limit exceeded")
+
+// Limits bounds the diagrams a SafeBuilder builds. Zero values leave
+// the respective limit off.
+type Limits struct {
+	// MaxCells is the number of cells a model may hold, including the
+	// root cell and the layers.
+	MaxCells int
+	// MaxLabelLength is the number of characters a label or a custom
+	// data value, such as a tooltip or a link, may have.
+	MaxLabelLength int
+	// MaxImageSize is the number of bytes an image embedded in a style
+	// as a data URI may have.
+	MaxImageSize int
+	// ImageSchemes lists the URL schemes images may use, e.g. "https"
+	// and "data", in styles and in the tags of HTML labels. Relative
+	// URLs are always allowed, unless they name a host as in
+	// "//example.com/x.png"; nil allows any scheme.
+	ImageSchemes []string
+	// LinkSchemes lists the URL schemes links may use, e.g. "https",
+	// in the link of a cell and in the tags of HTML labels. Relative
+	// URLs and links to pages of the file are always allowed;
+	// nil allows any scheme. Allowing "data" or "javascript" lets
+	// links run scripts when clicked.
+	LinkSchemes []string
+	// Images further restricts the images of the cells when set.
+	Images *ImagePolicy
+}
+
+// DefaultLimits are limits fit for diagrams generated from the data of
+// the users of a service.
+var DefaultLimits = Limits{
+	MaxCells:       10000,
+	MaxLabelLength: 10000,
+	MaxImageSize:   1 << 20,
+	ImageSchemes:   []string{"https", "http", "data"},
+	LinkSchemes:    []string{"https", "http"},
+}
+
+// A SafeBuilder adds cells to a model built from untrusted input,
+// refusing the ones which break its limits, so that the input cannot
+// make the model grow without bound or refer to unwanted resources.
+// The labels and tooltips of cells with HTML labels are checked for
+// the images and links in their tags.
+type SafeBuilder struct {
+	g      *GraphModel
+	limits Limits
+}
+
+// NewSafeBuilder returns a SafeBuilder adding cells to g within limits.
+func NewSafeBuilder(g *GraphModel, limits Limits) *SafeBuilder {
+	return &SafeBuilder{g: g, limits: limits}
+}
+
+// Add adds a copy of c to the model like GraphModel.Add, so that c
+// cannot be changed past the limits once checked, or returns an error
+// wrapping ErrLimit and leaves the model alone when c breaks a limit.
+func (b *SafeBuilder) Add(c *Cell) error {
+	if b.limits.MaxCells > 0 && len(b.g.Root) >= b.limits.MaxCells {
+		return fmt.Errorf("%w: more than %d cells", ErrLimit, b.limits.MaxCells)
+	}
+	if err := b.limits.check(c); err != nil {
+		return err
+	}
+	b.g.Add(c.clone())
+	return nil
+}
+
+// Model returns the model b adds cells to.
+func (b *SafeBuilder) Model() *GraphModel {
+	return b.g
+}
+
+// Check returns an error wrapping ErrLimit for the first cell of g
+// breaking l, e.g. to vet a model parsed from an uploaded file.
+func (l Limits) Check(g *GraphModel) error {
+	if l.MaxCells > 0 && len(g.Root) > l.MaxCells {
+		return fmt.Errorf("%w: more than %d cells", ErrLimit, l.MaxCells)
+	}
+	if img := g.backgroundImage(); img != nil && img.Src != "" {
+		if err := l.checkImage(img.Src); err != nil {
+			return fmt.Errorf("%w: background: %v", ErrLimit, err)
+		}
+	}
+	for i := range g.Root {
+		if err := l.check(g.Root[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check returns an error wrapping ErrLimit when c breaks l.
+func (l Limits) check(c *Cell) error {
+	if l.MaxLabelLength > 0 {
+		if utf8.RuneCountInString(c.Value) > l.MaxLabelLength {
+			return fmt.Errorf("%w: cell %q: label longer than %d characters", ErrLimit, c.ID, l.MaxLabelLength)
+		}
+		for _, k := range sortedKeys(c.Data) {
+			if utf8.RuneCountInString(c.Data[k]) > l.MaxLabelLength {
+				return fmt.Errorf("%w: cell %q: %s longer than %d characters", ErrLimit, c.ID, k, l.MaxLabelLength)
+			}
+		}
+	}
+	if img, ok := c.Style.Attributes["image"]; ok && img != "" {
+		if err := l.checkImage(img); err != nil {
+			return fmt.Errorf("%w: cell %q: %v", ErrLimit, c.ID, err)
+		}
+	}
+	if link := c.Data["link"]; link != "" && !strings.HasPrefix(link, "data:page/id,") {
+		if err := checkScheme(link, l.LinkSchemes); err != nil {
+			return fmt.Errorf("%w: cell %q: link: %v", ErrLimit, c.ID, err)
+		}
+	}
+	// draw.io renders the label and the tooltip of HTML labels as
+	// HTML, which loads the images and follows the links in them.
+	if c.Style.Attributes["html"] == "1" {
+		if err := l.checkHTML(c.ResolvedValue()); err != nil {
+			return fmt.Errorf("%w: cell %q: label: %v", ErrLimit, c.ID, err)
+		}
+		if err := l.checkHTML(c.Data["tooltip"]); err != nil {
+			return fmt.Errorf("%w: cell %q: tooltip: %v", ErrLimit, c.ID, err)
+		}
+	}
+	return nil
+}
+
+var (
+	htmlAttr = regexp.MustCompile(`(?i)\s([a-z][a-z0-9:-]*)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+	cssURL   = regexp.MustCompile(`(?i)url\(\s*("[^"]*"|'[^']*'|[^)]*)\s*\)`)
+)
+
+// checkHTML returns an error when an attribute of the tags of the HTML
+// text s refers to a URL breaking l: the links of href, action and
+// formaction, and the images of src, srcset, poster, background, data
+// and CSS url().
+func (l Limits) checkHTML(s string) error {
+	for _, tag := range htmlTag.FindAllString(s, -1) {
+		for _, m := range htmlAttr.FindAllStringSubmatch(tag, -1) {
+			name, value := strings.ToLower(m[1]), html.UnescapeString(strings.Trim(m[2], `"'`))
+			var links, images []string
+			switch name {
+			case "href", "xlink:href", "action", "formaction":
+				links = append(links, value)
+			case "src", "poster", "background", "data":
+				images = append(images, value)
+			case "srcset":
+				for _, candidate := range strings.Split(value, ",") {
+					if f := strings.Fields(candidate); len(f) > 0 {
+						images = append(images, f[0])
+					}
+				}
+			case "style":
+				for _, u := range cssURL.FindAllStringSubmatch(value, -1) {
+					images = append(images, strings.Trim(strings.TrimSpace(u[1]), `"'`))
+				}
+			}
+			for _, u := range links {
+				if err := checkScheme(u, l.LinkSchemes); err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+			}
+			for _, u := range images {
+				if err := l.checkImage(u); err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkImage returns an error when the image URL u breaks l.
+func (l Limits) checkImage(u string) error {
+	if err := checkScheme(u, l.ImageSchemes); err != nil {
+		return fmt.Errorf("image: %v", err)
+	}
+	if l.MaxImageSize > 0 && strings.HasPrefix(u, "data:") && len(u) > l.MaxImageSize {
+		return fmt.Errorf("image larger than %d bytes", l.MaxImageSize)
+	}
+	if l.Images != nil {
+		return l.Images.Check(u)
+	}
+	return nil
+}
+
+// checkScheme returns an error when the scheme of the URL u is not one
+// of schemes, unless schemes is nil.
+func checkScheme(u string, schemes []string) error {
+	if schemes == nil {
+		return nil
+	}
+	// data URIs in styles use "," instead of ";base64," which
+	// net/url accepts as well.
+	p, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if p.Scheme == "" {
+		// Network-path references such as "//host/x" load from
+		// another host.
+		if p.Host != "" {
+			return fmt.Errorf("host %q without scheme not allowed", p.Host)
+		}
+		return nil
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(s, p.Scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("scheme %q not allowed", p.Scheme)
+}
@@ -0,0 +1,184 @@
+package graw
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSafeBuilderDefaultLimits(t *testing.T) {
+	link := func(url string) *Cell {
+		return NewShape("a", "1").SetLink(url)
+	}
+	image := func(url string) *Cell {
+		return NewImage("a", "1", url)
+	}
+	label := func(html string) *Cell {
+		return NewShape("a", "1").Styled("html", "1").Label(html)
+	}
+	tests := []struct {
+		name string
+		cell *Cell
+		ok   bool
+	}{
+		{"label", NewShape("a", "1").Label("API"), true},
+		{"long label", NewShape("a", "1").Label(strings.Repeat("x", 10001)), false},
+		{"long data", NewShape("a", "1").SetData("note", strings.Repeat("x", 10001)), false},
+		{"https link", link("https://example.com"), true},
+		{"http link", link("http://example.com"), true},
+		{"relative link", link("/docs/api"), true},
+		{"page link", link("data:page/id,p2"), true},
+		{"data link", link("data:text/html,<script>alert(1)</script>"), false},
+		{"javascript link", link("javascript:alert(1)"), false},
+		{"javascript link upper case", link("JavaScript:alert(1)"), false},
+		{"javascript link leading space", link(" javascript:alert(1)"), false},
+		{"file link", link("file:///etc/passwd"), false},
+		{"https image", image("https://example.com/a.png"), true},
+		{"data image", image("data:image/png,iVBORw0KGgo="), true},
+		{"large data image", image("data:image/png," + strings.Repeat("A", 1<<20)), false},
+		{"file image", image("file:///etc/passwd"), false},
+		{"html label", label(`<b>API</b><br><a href="https://example.com">docs</a>`), true},
+		{"html tracking image", label(`<img src="//tracker.example/p.png">`), false},
+		{"html javascript link", label(`<a href="javascript:alert(1)">docs</a>`), false},
+		{"html tooltip javascript link", NewShape("a", "1").Styled("html", "1").SetTooltip(`<a href="javascript:alert(1)">x</a>`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			err := NewSafeBuilder(&g, DefaultLimits).Add(tt.cell)
+			if (err == nil) != tt.ok {
+				t.Errorf("got %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && !errors.Is(err, ErrLimit) {
+				t.Errorf("error %v does not wrap ErrLimit", err)
+			}
+			if err != nil && len(g.Root) != 2 {
+				t.Error("refused cell added")
+			}
+		})
+	}
+}
+
+func TestSafeBuilderMaxCells(t *testing.T) {
+	g := NewGraph()
+	b := NewSafeBuilder(&g, Limits{MaxCells: 3})
+	if err := b.Add(NewShape("a", "1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Add(NewShape("b", "1")); !errors.Is(err, ErrLimit) {
+		t.Errorf("got %v, want ErrLimit", err)
+	}
+	if err := (Limits{MaxCells: 2}).Check(b.Model()); !errors.Is(err, ErrLimit) {
+		t.Errorf("Check: got %v, want ErrLimit", err)
+	}
+}
+
+func TestSafeBuilderCopiesCell(t *testing.T) {
+	g := NewGraph()
+	c := NewShape("a", "1")
+	if err := NewSafeBuilder(&g, DefaultLimits).Add(c); err != nil {
+		t.Fatal(err)
+	}
+	c.SetLink("javascript:alert(1)")
+	if g.FindByID("a").Link() != "" {
+		t.Error("cell changed past the limits after Add")
+	}
+}
+
+func TestLimitsSchemes(t *testing.T) {
+	l := Limits{ImageSchemes: []string{"data"}, LinkSchemes: []string{"mailto"}}
+	g := NewGraph()
+	g.Add(NewShape("a", "1").SetLink("mailto:team@example.com"))
+	g.Add(NewImage("b", "1", "data:image/png,iVBORw0KGgo="))
+	if err := l.Check(&g); err != nil {
+		t.Fatal(err)
+	}
+	g.Add(NewImage("c", "1", "https://example.com/a.png"))
+	if err := l.Check(&g); !errors.Is(err, ErrLimit) {
+		t.Errorf("got %v, want ErrLimit", err)
+	}
+}
+
+func TestLimitsCheck(t *testing.T) {
+	l := Limits{MaxLabelLength: 3, ImageSchemes: []string{"https"}, LinkSchemes: []string{"https"}}
+	tests := []struct {
+		name  string
+		build func(g *GraphModel)
+		want  string
+	}{
+		{"empty", func(g *GraphModel) {}, ""},
+		{"runes", func(g *GraphModel) { g.Add(NewShape("a", "1").Label("äöü")) }, ""},
+		{"long label", func(g *GraphModel) { g.Add(NewShape("a", "1").Label("abcd")) }, `cell "a": label longer than 3 characters`},
+		{"long data", func(g *GraphModel) { g.Add(NewShape("a", "1").SetData("z", "abcd").SetData("b", "abcd")) }, `cell "a": b longer than 3 characters`},
+		{"link", func(g *GraphModel) { g.Add(NewShape("a", "1").SetLink("f:x")) }, `cell "a": link: scheme "f" not allowed`},
+		{"image", func(g *GraphModel) { g.Add(NewImage("a", "1", "http://x")) }, `cell "a": image: scheme "http" not allowed`},
+		{"background", func(g *GraphModel) { g.SetBackgroundImage(&BackgroundImage{Src: "http://x"}) }, `background: image: scheme "http" not allowed`},
+		{"https background", func(g *GraphModel) { g.SetBackgroundImage(&BackgroundImage{Src: "https://x"}) }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			tt.build(&g)
+			err := l.Check(&g)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("got %v", err)
+			case tt.want != "" && (!errors.Is(err, ErrLimit) || !strings.HasSuffix(err.Error(), tt.want)):
+				t.Errorf("got %v, want ErrLimit: %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitsCheckHTML(t *testing.T) {
+	l := Limits{ImageSchemes: []string{"https"}, LinkSchemes: []string{"https"}}
+	tests := []struct {
+		name  string
+		build func(g *GraphModel)
+		want  string
+	}{
+		{"html image", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<img src="http://tracker.example/p.png">`))
+		}, `cell "a": label: src: image: scheme "http" not allowed`},
+		{"html link", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<a href='javascript:alert(1)'>x</a>`))
+		}, `cell "a": label: href: scheme "javascript" not allowed`},
+		{"html escaped link", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<a href="&#106;avascript:alert(1)">x</a>`))
+		}, `cell "a": label: href: scheme "javascript" not allowed`},
+		{"html network path", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<img src=//tracker.example/p.png>`))
+		}, `cell "a": label: src: image: host "tracker.example" without scheme not allowed`},
+		{"html srcset", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<img srcset="https://x/a.png 1x, http://x/b.png 2x">`))
+		}, `cell "a": label: srcset: image: scheme "http" not allowed`},
+		{"html css", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<div style="background: url('http://x/p.png')">x</div>`))
+		}, `cell "a": label: style: image: scheme "http" not allowed`},
+		{"html placeholder", func(g *GraphModel) {
+			c := NewShape("a", "1").Styled("html", "1").Label("%name%").SetData("name", `<img src="ftp://x">`)
+			c.Placeholders = true
+			g.Add(c)
+		}, `cell "a": label: src: image: scheme "ftp" not allowed`},
+		{"html tooltip", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").SetTooltip(`<a href="data:text/html,x">x</a>`))
+		}, `cell "a": tooltip: href: scheme "data" not allowed`},
+		{"html allowed", func(g *GraphModel) {
+			g.Add(NewShape("a", "1").Styled("html", "1").Label(`<a href="https://x">x</a><img src="/p.png"><b>y</b>`))
+		}, ""},
+		{"plain text", func(g *GraphModel) { g.Add(NewShape("a", "1").Label(`<img src="http://x">`)) }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			tt.build(&g)
+			err := l.Check(&g)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("got %v", err)
+			case tt.want != "" && (!errors.Is(err, ErrLimit) || !strings.HasSuffix(err.Error(), tt.want)):
+				t.Errorf("got %v, want ErrLimit: %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package graw
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// licenseColors are the fill colors of the components of SBOM
+// diagrams by license category.
+var licenseColors = map[string]string{
+	"permissive":      "#d5e8d4",
+	"weak copyleft":   "#fff2cc",
+	"strong copyleft": "#f8cecc",
+	"unknown":         "#f5f5f5",
+}
+
+// licensePrefixes map the SPDX license IDs starting with a prefix to
+// their category; the longest matching prefix wins.
+var licensePrefixes = map[string]string{
+	"0BSD":         "permissive",
+	"AFL-":         "permissive",
+	"Apache-":      "permissive",
+	"Artistic-":    "permissive",
+	"BSD-":         "permissive",
+	"BSL-":         "permissive",
+	"CC-BY-":       "permissive",
+	"CC0-":         "permissive",
+	"ISC":          "permissive",
+	"MIT":          "permissive",
+	"PostgreSQL":   "permissive",
+	"PSF-":         "permissive",
+	"Python-":      "permissive",
+	"Unicode-":     "permissive",
+	"Unlicense":    "permissive",
+	"WTFPL":        "permissive",
+	"X11":          "permissive",
+	"Zlib":         "permissive",
+	"CDDL-":        "weak copyleft",
+	"CPL-":         "weak copyleft",
+	"EPL-":         "weak copyleft",
+	"LGPL-":        "weak copyleft",
+	"MPL-":         "weak copyleft",
+	"AGPL-":        "strong copyleft",
+	"CC-BY-SA-":    "strong copyleft",
+	"EUPL-":        "strong copyleft",
+	"GPL-":         "strong copyleft",
+	"OSL-":         "strong copyleft",
+	"SSPL-":        "strong copyleft",
+	"CC-BY-NC-":    "strong copyleft",
+	"CC-BY-NC-SA-": "strong copyleft",
+}
+
+// licenseRank orders license categories from the least to the most
+// restrictive.
+var licenseRank = map[string]int{"permissive": 0, "weak copyleft": 1, "strong copyleft": 2, "unknown": 3}
+
+// severityColors are the fill colors of vulnerability badges by the
+// highest severity of the vulnerabilities of a component.
+var severityColors = map[string]string{
+	"critical": "#a20025",
+	"high":     "#e51400",
+	"medium":   "#f0a30a",
+	"low":      "#60a917",
+	"":         "#647687",
+}
+
+// severityRank orders vulnerability severities.
+var severityRank = map[string]int{"": 0, "none": 0, "info": 0, "low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// sbomComponent is a component of an SBOM.
+type sbomComponent struct {
+	ref, name, version string
+	license            string
+	vulns              []string
+	severity           string
+}
+
+// sbom is the dependency graph of an SBOM document.
+type sbom struct {
+	components []*sbomComponent
+	byRef      map[string]*sbomComponent
+	deps       map[string][]string
+	root       string
+}
+
+// add adds c to s unless a component with its reference exists, and
+// returns the component of the reference.
+func (s *sbom) add(c *sbomComponent) *sbomComponent {
+	if old, ok := s.byRef[c.ref]; ok {
+		return old
+	}
+	s.byRef[c.ref] = c
+	s.components = append(s.components, c)
+	return c
+}
+
+// vulnerable records the vulnerability id of severity for the
+// component ref.
+func (s *sbom) vulnerable(ref, id, severity string) {
+	c, ok := s.byRef[ref]
+	if !ok {
+		return
+	}
+	severity = strings.ToLower(severity)
+	label := id
+	if severity != "" {
+		label += " (" + severity + ")"
+	}
+	c.vulns = append(c.vulns, label)
+	if _, ok := severityColors[severity]; !ok {
+		severity = ""
+	}
+	if severityRank[severity] >= severityRank[c.severity] {
+		c.severity = severity
+	}
+}
+
+// FromSBOM returns the dependency diagram of a software bill of
+// materials in CycloneDX or SPDX 2 JSON format. Components point to
+// the components they depend on, the described application leftmost,
+// and are colored by the category of their license: permissive, weak
+// copyleft, strong copyleft or unknown. Of license expressions the
+// most permissive choice is taken, and the most restrictive of
+// licenses that all apply. Components with known vulnerabilities,
+// from the vulnerabilities of CycloneDX or the security advisories
+// referenced by SPDX packages, wear a badge counting them, colored by
+// their highest severity.
+func FromSBOM(r io.Reader) (*GraphModel, error) {
+	var doc map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("graw: FromSBOM: %v", err)
+	}
+	s := &sbom{byRef: make(map[string]*sbomComponent), deps: make(map[string][]string)}
+	switch {
+	case jsonString(doc, "bomFormat") == "CycloneDX":
+		s.readCycloneDX(doc)
+	case jsonString(doc, "spdxVersion") != "":
+		s.readSPDX(doc)
+	default:
+		return nil, fmt.Errorf("graw: FromSBOM: neither a CycloneDX nor an SPDX document")
+	}
+	if len(s.components) == 0 {
+		return nil, fmt.Errorf("graw: FromSBOM: no components")
+	}
+	return s.diagram(), nil
+}
+
+// readCycloneDX reads the components, dependencies and vulnerabilities
+// of a CycloneDX document.
+func (s *sbom) readCycloneDX(doc map[string]interface{}) {
+	var component func(v interface{})
+	component = func(v interface{}) {
+		m := jsonObject(v)
+		c := &sbomComponent{
+			ref:     jsonString(m, "bom-ref"),
+			name:    jsonString(m, "name"),
+			version: jsonString(m, "version"),
+		}
+		if g := jsonString(m, "group"); g != "" {
+			c.name = g + "/" + c.name
+		}
+		if c.ref == "" {
+			c.ref = c.name + "@" + c.version
+		}
+		var ids []string
+		exprs := make(map[int]bool)
+		for _, l := range jsonArray(m["licenses"]) {
+			lm := jsonObject(l)
+			if e := jsonString(lm, "expression"); e != "" {
+				exprs[len(ids)] = true
+				ids = append(ids, e)
+			} else if id := jsonString(jsonObject(lm["license"]), "id"); id != "" {
+				ids = append(ids, id)
+			} else if name := jsonString(jsonObject(lm["license"]), "name"); name != "" {
+				ids = append(ids, name)
+			}
+		}
+		// several licenses are alternatives in CycloneDX
+		if len(ids) > 1 {
+			for i := range exprs {
+				ids[i] = "(" + ids[i] + ")"
+			}
+		}
+		c.license = strings.Join(ids, " OR ")
+		s.add(c)
+		for _, sub := range jsonArray(m["components"]) {
+			component(sub)
+		}
+	}
+	if root := jsonObject(jsonObject(doc["metadata"])["component"]); root != nil {
+		component(root)
+		s.root = s.components[0].ref
+	}
+	for _, v := range jsonArray(doc["components"]) {
+		component(v)
+	}
+	for _, v := range jsonArray(doc["dependencies"]) {
+		m := jsonObject(v)
+		ref := jsonString(m, "ref")
+		s.deps[ref] = append(s.deps[ref], jsonStrings(m["dependsOn"])...)
+	}
+	for _, v := range jsonArray(doc["vulnerabilities"]) {
+		m := jsonObject(v)
+		severity := ""
+		for _, rating := range jsonArray(m["ratings"]) {
+			sev := strings.ToLower(jsonString(jsonObject(rating), "severity"))
+			if severityRank[sev] > severityRank[severity] {
+				severity = sev
+			}
+		}
+		for _, a := range jsonArray(m["affects"]) {
+			s.vulnerable(jsonString(jsonObject(a), "ref"), jsonString(m, "id"), severity)
+		}
+	}
+}
+
+// readSPDX reads the packages, relationships and security references
+// of an SPDX 2 document.
+func (s *sbom) readSPDX(doc map[string]interface{}) {
+	for _, v := range jsonArray(doc["packages"]) {
+		m := jsonObject(v)
+		c := s.add(&sbomComponent{
+			ref:     jsonString(m, "SPDXID"),
+			name:    jsonString(m, "name"),
+			version: jsonString(m, "versionInfo"),
+			license: jsonString(m, "licenseConcluded"),
+		})
+		if c.license == "" || c.license == "NOASSERTION" {
+			c.license = jsonString(m, "licenseDeclared")
+		}
+		for _, ref := range jsonArray(m["externalRefs"]) {
+			rm := jsonObject(ref)
+			if jsonString(rm, "referenceCategory") == "SECURITY" && jsonString(rm, "referenceType") == "advisory" {
+				s.vulnerable(c.ref, jsonString(rm, "referenceLocator"), "")
+			}
+		}
+	}
+	described := jsonStrings(doc["documentDescribes"])
+	for _, v := range jsonArray(doc["relationships"]) {
+		m := jsonObject(v)
+		from, to := jsonString(m, "spdxElementId"), jsonString(m, "relatedSpdxElement")
+		switch jsonString(m, "relationshipType") {
+		case "DESCRIBES":
+			described = append(described, to)
+		case "DESCRIBED_BY":
+			described = append(described, from)
+		case "DEPENDS_ON", "CONTAINS", "STATIC_LINK", "DYNAMIC_LINK":
+			s.deps[from] = append(s.deps[from], to)
+		case "DEPENDENCY_OF", "CONTAINED_BY", "RUNTIME_DEPENDENCY_OF", "DEV_DEPENDENCY_OF", "BUILD_DEPENDENCY_OF":
+			s.deps[to] = append(s.deps[to], from)
+		}
+	}
+	for _, ref := range described {
+		if _, ok := s.byRef[ref]; ok {
+			s.root = ref
+			break
+		}
+	}
+}
+
+// diagram returns the dependency diagram of s.
+func (s *sbom) diagram() *GraphModel {
+	refs := make([]string, 0, len(s.components))
+	deps := make(map[string][]string, len(s.deps))
+	for _, c := range s.components {
+		refs = append(refs, c.ref)
+		for _, d := range s.deps[c.ref] {
+			if _, ok := s.byRef[d]; ok && d != c.ref {
+				deps[c.ref] = append(deps[c.ref], d)
+			}
+		}
+	}
+	_, _, column := dependencyColumns(refs, deps)
+
+	g := NewGraph()
+	taken := g.idSet()
+	ids := make(map[string]string, len(refs))
+	var columns [][]*Cell
+	var badges []*Cell
+	cells := make(map[string]*Cell, len(refs))
+	for _, c := range s.components {
+		cell := NewShape(taken.unique(c.name), rootCellID)
+		cell.Value = "<b>" + html.EscapeString(c.name) + "</b>"
+		if c.version != "" {
+			cell.Value += "<br>" + html.EscapeString(c.version)
+		}
+		category := licenseCategory(c.license)
+		if c.license != "" {
+			cell.Value += "<br><i>" + html.EscapeString(c.license) + "</i>"
+		}
+		cell.Style = Style{Attributes: shapeStyle("rounded")}
+		cell.Style.set("fillColor", licenseColors[category])
+		if c.ref == s.root {
+			cell.Style.setFloat("strokeWidth", 2)
+		}
+		cell.Data = map[string]string{"tooltip": "license: " + category}
+		SizeToFit(cell, nil, defaultWidth, 40)
+		ids[c.ref] = cell.ID
+		cells[c.ref] = cell
+
+		col := column[c.ref]
+		for len(columns) <= col {
+			columns = append(columns, nil)
+		}
+		columns[col] = append(columns[col], cell)
+	}
+	layoutColumns(columns, 10, 20, groupGap)
+
+	for _, c := range s.components {
+		if len(c.vulns) == 0 {
+			continue
+		}
+		cell := cells[c.ref]
+		w, _ := cell.Geometry.size()
+		b := NewShape(taken.unique(cell.ID+"-vulnerabilities"), rootCellID)
+		b.Value = strconv.Itoa(len(c.vulns))
+		b.Style = Style{Attributes: shapeStyle("ellipse")}
+		b.Style.set("fillColor", severityColors[c.severity])
+		b.Style.set("strokeColor", "#ffffff")
+		b.Style.set("fontColor", "#ffffff")
+		b.Style.set("fontStyle", "1")
+		b.Geometry.X, b.Geometry.Y = cell.Geometry.X+w-14, cell.Geometry.Y-10
+		b.Geometry.setSize(24, 24)
+		b.Data = map[string]string{"tooltip": strings.Join(c.vulns, "\n")}
+		badges = append(badges, b)
+	}
+
+	for _, col := range columns {
+		for _, c := range col {
+			g.Add(c)
+		}
+	}
+	for _, c := range s.components {
+		for _, d := range deps[c.ref] {
+			g.Add(newEdgeCell(taken.unique(ids[c.ref]+"-"+ids[d]), rootCellID, ids[c.ref], ids[d]))
+		}
+	}
+	for _, b := range badges {
+		g.Add(b)
+	}
+	return &g
+}
+
+// licenseCategory returns the category of an SPDX license expression:
+// the most permissive of alternatives joined by OR, and the most
+// restrictive of licenses joined by AND.
+func licenseCategory(expr string) string {
+	expr = strings.TrimSpace(expr)
+	for len(expr) > 1 && expr[0] == '(' && closingParen(expr, 0) == len(expr)-1 {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	if alts := splitLicenses(expr, "OR"); len(alts) > 1 {
+		best := "unknown"
+		for _, a := range alts {
+			if c := licenseCategory(a); licenseRank[c] < licenseRank[best] {
+				best = c
+			}
+		}
+		return best
+	}
+	if all := splitLicenses(expr, "AND"); len(all) > 1 {
+		worst := "permissive"
+		for _, a := range all {
+			if c := licenseCategory(a); licenseRank[c] > licenseRank[worst] {
+				worst = c
+			}
+		}
+		return worst
+	}
+	// "GPL-2.0-only WITH Classpath-exception-2.0" is the license itself
+	if i := strings.Index(expr, " WITH "); i >= 0 {
+		expr = expr[:i]
+	}
+	expr = strings.TrimSuffix(expr, "+")
+	category, match := "unknown", ""
+	for prefix, c := range licensePrefixes {
+		if strings.HasPrefix(expr, prefix) && len(prefix) > len(match) {
+			category, match = c, prefix
+		}
+	}
+	return category
+}
+
+// splitLicenses splits a license expression at the operator op outside
+// parentheses.
+func splitLicenses(expr, op string) []string {
+	var parts []string
+	depth, start := 0, 0
+	fields := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr))
+	for i, f := range fields {
+		switch {
+		case f == "(":
+			depth++
+		case f == ")":
+			depth--
+		case depth == 0 && strings.EqualFold(f, op):
+			parts = append(parts, strings.Join(fields[start:i], " "))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.Join(fields[start:], " "))
+}
+
+// closingParen returns the index of the parenthesis closing the one
+// at index open of s, or -1.
+func closingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,124 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLicenseCategory(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"MIT", "permissive"},
+		{"GPL-2.0 OR MIT", "permissive"},
+		{"(MIT AND LGPL-2.1-only)", "weak copyleft"},
+		{"Apache-2.0 AND (GPL-3.0+ OR MPL-2.0)", "weak copyleft"},
+		{"GPL-2.0-only WITH Classpath-exception-2.0", "strong copyleft"},
+		{"MIT and AGPL-3.0-only", "strong copyleft"},
+		{"NOASSERTION", "unknown"},
+		{"", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := licenseCategory(tt.expr); got != tt.want {
+			t.Errorf("licenseCategory(%q): got %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+const cycloneDX = `{"bomFormat":"CycloneDX","specVersion":"1.5","metadata":{"component":{"bom-ref":"app","name":"app","version":"1.0"}},
+"components":[
+ {"bom-ref":"a","name":"left-pad","version":"1.3.0","licenses":[{"license":{"id":"MIT"}}]},
+ {"bom-ref":"b","name":"gpl-thing","licenses":[{"expression":"GPL-3.0-only"}]},
+ {"bom-ref":"c","group":"org","name":"dual","licenses":[{"expression":"MIT AND GPL-3.0-only"},{"license":{"id":"Apache-2.0"}}]}],
+"dependencies":[{"ref":"app","dependsOn":["a","b","c"]},{"ref":"a","dependsOn":["b"]}],
+"vulnerabilities":[{"id":"CVE-1","ratings":[{"severity":"high"}],"affects":[{"ref":"a"}]},{"id":"CVE-2","ratings":[{"severity":"low"}],"affects":[{"ref":"a"}]}]}`
+
+const spdxJSON = `{"spdxVersion":"SPDX-2.3","documentDescribes":["SPDXRef-app"],"packages":[
+ {"SPDXID":"SPDXRef-app","name":"app"},
+ {"SPDXID":"SPDXRef-x","name":"x","versionInfo":"2","licenseConcluded":"LGPL-2.1-or-later","externalRefs":[{"referenceCategory":"SECURITY","referenceType":"advisory","referenceLocator":"https://nvd/CVE-3"}]}],
+"relationships":[{"spdxElementId":"SPDXRef-x","relationshipType":"DEPENDENCY_OF","relatedSpdxElement":"SPDXRef-app"}]}`
+
+func TestFromSBOM(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		labels   map[string]string
+		tooltips map[string]string
+		edges    []string
+	}{
+		{
+			name: "CycloneDX",
+			in:   cycloneDX,
+			labels: map[string]string{
+				"app":                      "<b>app</b><br>1.0",
+				"left-pad":                 "<b>left-pad</b><br>1.3.0<br><i>MIT</i>",
+				"gpl-thing":                "<b>gpl-thing</b><br><i>GPL-3.0-only</i>",
+				"org/dual":                 "<b>org/dual</b><br><i>(MIT AND GPL-3.0-only) OR Apache-2.0</i>",
+				"left-pad-vulnerabilities": "2",
+			},
+			tooltips: map[string]string{
+				"app":                      "license: unknown",
+				"left-pad":                 "license: permissive",
+				"gpl-thing":                "license: strong copyleft",
+				"org/dual":                 "license: permissive",
+				"left-pad-vulnerabilities": "CVE-1 (high)\nCVE-2 (low)",
+			},
+			edges: []string{
+				"app->left-pad",
+				"app->gpl-thing",
+				"app->org/dual",
+				"left-pad->gpl-thing",
+			},
+		},
+		{
+			name: "SPDX",
+			in:   spdxJSON,
+			labels: map[string]string{
+				"app":               "<b>app</b>",
+				"x":                 "<b>x</b><br>2<br><i>LGPL-2.1-or-later</i>",
+				"x-vulnerabilities": "1",
+			},
+			tooltips: map[string]string{
+				"app":               "license: unknown",
+				"x":                 "license: weak copyleft",
+				"x-vulnerabilities": "https://nvd/CVE-3",
+			},
+			edges: []string{"app->x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FromSBOM(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := vertexLabels(g); !reflect.DeepEqual(got, tt.labels) {
+				t.Errorf("got labels %q, want %q", got, tt.labels)
+			}
+			for id, want := range tt.tooltips {
+				if got := g.FindByID(id).Data["tooltip"]; got != want {
+					t.Errorf("%s: got tooltip %q, want %q", id, got, want)
+				}
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %q, want %q", got, tt.edges)
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestFromSBOMErrors(t *testing.T) {
+	for _, in := range []string{
+		`{"bomFormat":`,
+		`{"name":"not an sbom"}`,
+		`{"bomFormat":"CycloneDX","specVersion":"1.5"}`,
+	} {
+		if _, err := FromSBOM(strings.NewReader(in)); err == nil {
+			t.Errorf("FromSBOM(%s): got no error", in)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package graw
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// styleKind is the type of value held by a style key.
+type styleKind int
+
+const (
+	kindString styleKind = iota
+	kindBool
+	kindNumber
+	kindColor
+	kindEnum
+)
+
+// styleProp describes a style key understood by draw.io. A non empty
+// def is the value draw.io applies when the key is absent, so a key
+// set to that value can be dropped without changing the diagram.
+type styleProp struct {
+	kind     styleKind
+	def      string
+	min, max float64
+	values   []string
+}
+
+// strProp, boolProp, numProp, colorProp and enumProp build the
+// entries of styleSchema.
+func strProp() styleProp            { return styleProp{kind: kindString} }
+func boolProp(def string) styleProp { return styleProp{kind: kindBool, def: def} }
+func colorProp() styleProp          { return styleProp{kind: kindColor} }
+func numProp(def string, min, max float64) styleProp {
+	return styleProp{kind: kindNumber, def: def, min: min, max: max}
+}
+func enumProp(def string, values ...string) styleProp {
+	return styleProp{kind: kindEnum, def: def, values: values}
+}
+
+// inf leaves a numeric range open.
+var inf = math.Inf(1)
+
+// Values shared by several enumerations.
+var (
+	sides       = []string{"north", "south", "east", "west"}
+	hAlignments = []string{"left", "center", "right"}
+	vAlignments = []string{"top", "middle", "bottom"}
+	arrows      = []string{
+		"none", "classic", "classicThin", "block", "blockThin", "open",
+		"openThin", "oval", "diamond", "diamondThin", "dash", "halfCircle",
+		"cross", "circlePlus", "circle", "baseDash", "ERone", "ERmandOne",
+		"ERmany", "ERoneToMany", "ERzeroToOne", "ERzeroToMany", "doubleBlock",
+		"openAsync", "async", "box", "manyOptional",
+	}
+)
+
+// styleSchema lists the style keys understood by draw.io together
+// with the values they accept.
+var styleSchema = map[string]styleProp{
+	// shape and geometry
+	"shape":             strProp(),
+	"perimeter":         strProp(),
+	"rounded":           boolProp("0"),
+	"arcSize":           numProp("", 0, inf),
+	"absoluteArcSize":   boolProp("0"),
+	"rotation":          numProp("0", -360, 360),
+	"flipH":             boolProp("0"),
+	"flipV":             boolProp("0"),
+	"direction":         enumProp("", sides...),
+	"aspect":            enumProp("", "fixed", "variable"),
+	"fixedSize":         boolProp("0"),
+	"size":              numProp("", 0, inf),
+	"container":         boolProp("0"),
+	"collapsible":       boolProp(""),
+	"childLayout":       enumProp("", "stackLayout", "treeLayout", "flowLayout", "rack", "tableLayout", "rowLayout", "circleLayout", "organicLayout"),
+	"dropTarget":        boolProp(""),
+	"recursiveResize":   boolProp(""),
+	"expand":            boolProp("1"),
+	"horizontalStack":   boolProp("1"),
+	"resizeParent":      boolProp(""),
+	"resizeParentMax":   boolProp(""),
+	"resizeLast":        boolProp(""),
+	"marginTop":         numProp("", -inf, inf),
+	"marginBottom":      numProp("", -inf, inf),
+	"marginLeft":        numProp("", -inf, inf),
+	"marginRight":       numProp("", -inf, inf),
+	"fixedRows":         boolProp(""),
+	"resizable":         boolProp("1"),
+	"resizeWidth":       boolProp(""),
+	"resizeHeight":      boolProp(""),
+	"movable":           boolProp("1"),
+	"rotatable":         boolProp("1"),
+	"deletable":         boolProp("1"),
+	"editable":          boolProp("1"),
+	"cloneable":         boolProp("1"),
+	"bendable":          boolProp("1"),
+	"connectable":       boolProp(""),
+	"pointerEvents":     boolProp(""),
+	"part":              boolProp(""),
+	"portConstraint":    strProp(),
+	"snapToPoint":       boolProp(""),
+	"points":            strProp(),
+	"sketch":            boolProp("0"),
+	"comic":             boolProp("0"),
+	"glass":             boolProp("0"),
+	"shadow":            boolProp("0"),
+	"treeFolding":       boolProp("0"),
+	"treeMoving":        boolProp("0"),
+	"metaEdit":          boolProp(""),
+	"backgroundOutline": boolProp(""),
+	"boundedLbl":        boolProp(""),
+	"double":            boolProp("0"),
+	"swimlaneLine":      boolProp("1"),
+	"horizontal":        boolProp("1"),
+	"swimlaneFillColor": colorProp(),
+	"swimlaneHead":      boolProp(""),
+	"swimlaneBody":      boolProp(""),
+	// the sides drawn by partialRectangle
+	"top":    boolProp("1"),
+	"left":   boolProp("1"),
+	"bottom": boolProp("1"),
+	"right":  boolProp("1"),
+	// rack cabinets
+	"allowGaps":    boolProp(""),
+	"rackUnitSize": numProp("", 1, inf),
+	"numDisp":      enumProp("", "off", "ascend", "descend"),
+	"textColor":    colorProp(),
+	"fillColor2":   colorProp(),
+
+	// fill and stroke
+	"fillColor":          colorProp(),
+	"gradientColor":      colorProp(),
+	"gradientDirection":  enumProp("south", append([]string{"radial"}, sides...)...),
+	"fillStyle":          enumProp("", "auto", "solid", "hachure", "cross-hatch", "dots", "dashed", "zigzag", "zigzag-line"),
+	"strokeColor":        colorProp(),
+	"strokeWidth":        numProp("1", 0, inf),
+	"dashed":             boolProp("0"),
+	"dashPattern":        strProp(),
+	"fixDash":            boolProp("0"),
+	"opacity":            numProp("100", 0, 100),
+	"fillOpacity":        numProp("100", 0, 100),
+	"strokeOpacity":      numProp("100", 0, 100),
+	"textOpacity":        numProp("100", 0, 100),
+	"imageAspect":        boolProp("1"),
+	"image":              strProp(),
+	"imageWidth":         numProp("", 0, inf),
+	"imageHeight":        numProp("", 0, inf),
+	"imageAlign":         enumProp("", hAlignments...),
+	"imageVerticalAlign": enumProp("", vAlignments...),
+	"imageBackground":    colorProp(),
+	"imageBorder":        colorProp(),
+	"noLabel":            boolProp("0"),
+
+	// text
+	"html":                  boolProp(""),
+	"whiteSpace":            enumProp("", "wrap", "nowrap"),
+	"overflow":              enumProp("", "visible", "hidden", "fill", "width", "block", "scroll"),
+	"fontColor":             colorProp(),
+	"fontFamily":            strProp(),
+	"fontSize":              numProp("", 1, inf),
+	"fontStyle":             numProp("0", 0, 15),
+	"align":                 enumProp("center", hAlignments...),
+	"verticalAlign":         enumProp("middle", vAlignments...),
+	"labelPosition":         enumProp("center", hAlignments...),
+	"verticalLabelPosition": enumProp("middle", vAlignments...),
+	"labelBackgroundColor":  colorProp(),
+	"labelBorderColor":      colorProp(),
+	"labelWidth":            numProp("", 0, inf),
+	"labelPadding":          numProp("", 0, inf),
+	"textDirection":         enumProp("", "ltr", "rtl", "auto", "vertical-lr", "vertical-rl"),
+	"spacing":               numProp("", -inf, inf),
+	"spacingTop":            numProp("", -inf, inf),
+	"spacingLeft":           numProp("", -inf, inf),
+	"spacingBottom":         numProp("", -inf, inf),
+	"spacingRight":          numProp("", -inf, inf),
+	"autosize":              boolProp("0"),
+	"autosizeGrid":          boolProp(""),
+
+	// edges
+	"edgeStyle":              enumProp("", "none", "orthogonalEdgeStyle", "elbowEdgeStyle", "entityRelationEdgeStyle", "segmentEdgeStyle", "isometricEdgeStyle", "sideToSideEdgeStyle", "topToBottomEdgeStyle", "loopEdgeStyle"),
+	"elbow":                  enumProp("", "horizontal", "vertical"),
+	"curved":                 boolProp("0"),
+	"orthogonal":             boolProp(""),
+	"orthogonalLoop":         boolProp(""),
+	"jettySize":              strProp(),
+	"loopStyle":              strProp(),
+	"jumpStyle":              enumProp("", "none", "arc", "gap", "sharp", "line"),
+	"jumpSize":               numProp("", 0, inf),
+	"startArrow":             enumProp("", arrows...),
+	"endArrow":               enumProp("", arrows...),
+	"startFill":              boolProp("1"),
+	"endFill":                boolProp("1"),
+	"startSize":              numProp("", 0, inf),
+	"endSize":                numProp("", 0, inf),
+	"sourcePerimeterSpacing": numProp("", -inf, inf),
+	"targetPerimeterSpacing": numProp("", -inf, inf),
+	"perimeterSpacing":       numProp("", -inf, inf),
+	"sourcePortConstraint":   strProp(),
+	"targetPortConstraint":   strProp(),
+	"exitX":                  numProp("", 0, 1),
+	"exitY":                  numProp("", 0, 1),
+	"exitDx":                 numProp("", -inf, inf),
+	"exitDy":                 numProp("", -inf, inf),
+	"exitPerimeter":          boolProp("1"),
+	"entryX":                 numProp("", 0, 1),
+	"entryY":                 numProp("", 0, 1),
+	"entryDx":                numProp("", -inf, inf),
+	"entryDy":                numProp("", -inf, inf),
+	"entryPerimeter":         boolProp("1"),
+	"sourcePort":             strProp(),
+	"targetPort":             strProp(),
+	"segment":                numProp("", 0, inf),
+	"flowAnimation":          boolProp("0"),
+
+	// links and behaviour
+	"link":             strProp(),
+	"linkTarget":       strProp(),
+	"editableCssRules": strProp(),
+}
+
+// StyleIssue describes a style entry of a cell which draw.io does not
+// understand.
+type StyleIssue struct {
+	CellID string
+	Key    string
+	Value  string
+	// Problem explains what is wrong with the entry.
+	Problem string
+}
+
+// Error implements the error interface.
+func (i StyleIssue) Error() string {
+	return fmt.Sprintf("graw: cell %q: style %s=%s: %s", i.CellID, i.Key, i.Value, i.Problem)
+}
+
+var colorName = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|#[0-9a-fA-F]{8}|[a-zA-Z]+)$`)
+
+// ValidateStyles checks the style of every cell in g against the
+// style keys supported by draw.io and returns the unknown keys and
+// the values of the wrong type, out of range or not among the allowed
+// choices. Keys without a value are named styles and are not checked.
+func ValidateStyles(g *GraphModel) []StyleIssue {
+	var issues []StyleIssue
+	for i := range g.Root {
+		c := g.Root[i]
+		for _, k := range sortedKeys(c.Style.Attributes) {
+			v := c.Style.Attributes[k]
+			if k == "" || v == "" {
+				continue
+			}
+			if problem := checkStyleValue(k, v); problem != "" {
+				issues = append(issues, StyleIssue{CellID: c.ID, Key: k, Value: v, Problem: problem})
+			}
+		}
+	}
+	return issues
+}
+
+// checkStyleValue returns what is wrong with the style entry k=v, or
+// an empty string when it is valid.
+func checkStyleValue(k, v string) string {
+	prop, ok := styleSchema[k]
+	if !ok {
+		return "unknown key"
+	}
+
+	switch prop.kind {
+	case kindBool:
+		if v != "0" && v != "1" {
+			return "want 0 or 1"
+		}
+	case kindNumber:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "want a number"
+		}
+		if f < prop.min || f > prop.max {
+			return fmt.Sprintf("want a number between %g and %g", prop.min, prop.max)
+		}
+	case kindColor:
+		if !colorName.MatchString(v) {
+			return "want a color such as #rrggbb or none"
+		}
+	case kindEnum:
+		for _, allowed := range prop.values {
+			if v == allowed {
+				return ""
+			}
+		}
+		return "want one of " + strings.Join(prop.values, ", ")
+	}
+	return ""
+}
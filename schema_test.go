@@ -0,0 +1,55 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateStyles(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		style map[string]string
+		want  []StyleIssue
+	}{
+		{"valid", map[string]string{"rounded": "1", "fillColor": "#dae8fc", "strokeColor": "none", "opacity": "50", "align": "left", "ellipse": ""}, nil},
+		{"unknown key", map[string]string{"foo": "1"}, []StyleIssue{{CellID: "a", Key: "foo", Value: "1", Problem: "unknown key"}}},
+		{"bool", map[string]string{"rounded": "yes"}, []StyleIssue{{CellID: "a", Key: "rounded", Value: "yes", Problem: "want 0 or 1"}}},
+		{"number", map[string]string{"strokeWidth": "thick"}, []StyleIssue{{CellID: "a", Key: "strokeWidth", Value: "thick", Problem: "want a number"}}},
+		{"range", map[string]string{"opacity": "150"}, []StyleIssue{{CellID: "a", Key: "opacity", Value: "150", Problem: "want a number between 0 and 100"}}},
+		{"color", map[string]string{"fillColor": "#ggg"}, []StyleIssue{{CellID: "a", Key: "fillColor", Value: "#ggg", Problem: "want a color such as #rrggbb or none"}}},
+		{"enum", map[string]string{"align": "centre"}, []StyleIssue{{CellID: "a", Key: "align", Value: "centre", Problem: "want one of left, center, right"}}},
+		{"sorted by key", map[string]string{"zz": "1", "aa": "1"}, []StyleIssue{
+			{CellID: "a", Key: "aa", Value: "1", Problem: "unknown key"},
+			{CellID: "a", Key: "zz", Value: "1", Problem: "unknown key"},
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			c := NewShape("a", "1")
+			c.Style = Style{Attributes: test.style}
+			g.Add(c)
+			if got := ValidateStyles(&g); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStyleIssueError(t *testing.T) {
+	err := StyleIssue{CellID: "a", Key: "rounded", Value: "yes", Problem: "want 0 or 1"}.Error()
+	if want := `graw: cell "a": style rounded=yes: want 0 or 1`; err != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+}
+
+func TestConstructorStylesValid(t *testing.T) {
+	g := NewGraph()
+	g.Add(NewShape("a", "1"))
+	g.Add(NewShape("b", "1"))
+	g.Add(NewEdge("e", "1", "a", "b"))
+	g.Add(NewImage("i", "1", "https://example.com/x.png"))
+	g.Add(newContainer("c", "1", "group"))
+	if issues := ValidateStyles(&g); len(issues) > 0 {
+		t.Errorf("got %v", issues)
+	}
+}
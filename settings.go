@@ -0,0 +1,87 @@
+package graw
+
+import "strconv"
+
+// flag returns the attribute value of an mxGraphModel switch.
+func flag(on bool) string {
+	if on {
+		return "1"
+	}
+	return "0"
+}
+
+// SetGrid shows or hides the grid of g, size pixels wide, 10 if not
+// positive.
+func (g *GraphModel) SetGrid(on bool, size int) *GraphModel {
+	if size <= 0 {
+		size = 10
+	}
+	g.Grid, g.GridSize = flag(on), strconv.Itoa(size)
+	return g
+}
+
+// SetPage turns on the page view of g, with pages width by height
+// draw.io units, 1/100 inch, large, drawn at scale, 1 if not positive.
+// See SetPageSettings for paper sizes.
+func (g *GraphModel) SetPage(width, height int, scale float64) *GraphModel {
+	if scale <= 0 {
+		scale = 1
+	}
+	g.Page = "1"
+	g.PageWidth, g.PageHeight = strconv.Itoa(width), strconv.Itoa(height)
+	g.PageScale = formatDecimal(scale, 4)
+	return g
+}
+
+// SetPageView shows or hides the page breaks of g, keeping its page
+// size.
+func (g *GraphModel) SetPageView(on bool) *GraphModel {
+	g.Page = flag(on)
+	return g
+}
+
+// SetGuides turns the alignment guides shown while moving cells on or
+// off.
+func (g *GraphModel) SetGuides(on bool) *GraphModel {
+	g.Guides = flag(on)
+	return g
+}
+
+// SetTooltips turns the tooltips of the cells on or off.
+func (g *GraphModel) SetTooltips(on bool) *GraphModel {
+	g.Tooltips = flag(on)
+	return g
+}
+
+// SetConnect lets edges be drawn from the hover icons of shapes, or
+// not.
+func (g *GraphModel) SetConnect(on bool) *GraphModel {
+	g.Connect = flag(on)
+	return g
+}
+
+// SetArrows shows or hides the connection arrows around the shape
+// under the mouse.
+func (g *GraphModel) SetArrows(on bool) *GraphModel {
+	g.Arrows = flag(on)
+	return g
+}
+
+// SetFold lets containers be collapsed and expanded, or not.
+func (g *GraphModel) SetFold(on bool) *GraphModel {
+	g.Fold = flag(on)
+	return g
+}
+
+// SetMath turns the typesetting of LaTeX and AsciiMath in labels on or
+// off.
+func (g *GraphModel) SetMath(on bool) *GraphModel {
+	g.Math = flag(on)
+	return g
+}
+
+// SetShadow turns the shadows of all shapes on or off.
+func (g *GraphModel) SetShadow(on bool) *GraphModel {
+	g.Shadow = flag(on)
+	return g
+}
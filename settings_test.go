@@ -0,0 +1,49 @@
+package graw
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(g *GraphModel)
+		attr func(g *GraphModel) string
+		want string
+	}{
+		{"grid", func(g *GraphModel) { g.SetGrid(true, 20) }, func(g *GraphModel) string { return g.Grid + " " + g.GridSize }, "1 20"},
+		{"grid default size", func(g *GraphModel) { g.SetGrid(false, 0) }, func(g *GraphModel) string { return g.Grid + " " + g.GridSize }, "0 10"},
+		{"page", func(g *GraphModel) { g.SetPage(827, 1169, 1.5) },
+			func(g *GraphModel) string { return g.Page + " " + g.PageWidth + " " + g.PageHeight + " " + g.PageScale }, "1 827 1169 1.5"},
+		{"page default scale", func(g *GraphModel) { g.SetPage(850, 1100, -2) }, func(g *GraphModel) string { return g.PageScale }, "1"},
+		{"page view", func(g *GraphModel) { g.SetPageView(false) }, func(g *GraphModel) string { return g.Page }, "0"},
+		{"guides", func(g *GraphModel) { g.SetGuides(true) }, func(g *GraphModel) string { return g.Guides }, "1"},
+		{"tooltips", func(g *GraphModel) { g.SetTooltips(false) }, func(g *GraphModel) string { return g.Tooltips }, "0"},
+		{"connect", func(g *GraphModel) { g.SetConnect(true) }, func(g *GraphModel) string { return g.Connect }, "1"},
+		{"arrows", func(g *GraphModel) { g.SetArrows(false) }, func(g *GraphModel) string { return g.Arrows }, "0"},
+		{"fold", func(g *GraphModel) { g.SetFold(true) }, func(g *GraphModel) string { return g.Fold }, "1"},
+		{"math", func(g *GraphModel) { g.SetMath(true) }, func(g *GraphModel) string { return g.Math }, "1"},
+		{"shadow", func(g *GraphModel) { g.SetShadow(false) }, func(g *GraphModel) string { return g.Shadow }, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			tt.set(&g)
+			if got := tt.attr(&g); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsChain(t *testing.T) {
+	g := NewGraph()
+	g.SetGrid(true, 5).SetMath(true).SetShadow(true)
+	out := encodeString(t, &g)
+	for _, want := range []string{`grid="1"`, `gridSize="5"`, `math="1"`, `shadow="1"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("got %s, want it to contain %s", out, want)
+		}
+	}
+}
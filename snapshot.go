@@ -0,0 +1,90 @@
+package graw
+
+import (
+	"encoding/xml"
+	"sync/atomic"
+)
+
+// ReadOnlyGraph is an immutable snapshot of a GraphModel. It never
+// changes after creation, so it can be shared between goroutines,
+// e.g. ones serving render requests, while a writer keeps building
+// the next version of the model.
+type ReadOnlyGraph struct {
+	g     GraphModel
+	index map[string]int
+}
+
+// Freeze returns a snapshot of the current state of g. Later changes
+// to g do not affect the snapshot.
+func (g *GraphModel) Freeze() *ReadOnlyGraph {
+	r := &ReadOnlyGraph{
+		g:     g.clone(),
+		index: make(map[string]int, len(g.Root)),
+	}
+	for i := range r.g.Root {
+		r.index[r.g.Root[i].ID] = i
+	}
+	return r
+}
+
+// Len returns the number of cells in the snapshot, including the
+// root cell and the layers.
+func (r *ReadOnlyGraph) Len() int {
+	return len(r.g.Root)
+}
+
+// Cell returns a copy of the cell with the given id. It reports false
+// when there is no such cell.
+func (r *ReadOnlyGraph) Cell(id string) (Cell, bool) {
+	i, ok := r.index[id]
+	if !ok {
+		return Cell{}, false
+	}
+	return *r.g.Root[i].clone(), true
+}
+
+// Cells returns a copy of all cells in document order.
+func (r *ReadOnlyGraph) Cells() []Cell {
+	cells := make([]Cell, len(r.g.Root))
+	for i, c := range r.g.Root {
+		cells[i] = *c.clone()
+	}
+	return cells
+}
+
+// Thaw returns a mutable copy of the snapshot, e.g. to start the next
+// version of the model from it.
+func (r *ReadOnlyGraph) Thaw() GraphModel {
+	return r.g.clone()
+}
+
+// MarshalXML encodes the snapshot as an mxGraphModel element. It
+// implements xml.Marshaler interface.
+func (r *ReadOnlyGraph) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.Encode(r.g)
+}
+
+// AtomicGraph holds the current snapshot of a model which readers
+// load while a writer publishes new versions. The zero value holds
+// no snapshot and is ready to use.
+type AtomicGraph struct {
+	v atomic.Value
+}
+
+// Load returns the current snapshot, or nil if none was stored yet.
+func (a *AtomicGraph) Load() *ReadOnlyGraph {
+	r, _ := a.v.Load().(*ReadOnlyGraph)
+	return r
+}
+
+// Store publishes r as the current snapshot.
+func (a *AtomicGraph) Store(r *ReadOnlyGraph) {
+	a.v.Store(r)
+}
+
+// Swap publishes r as the current snapshot and returns the previous
+// one, or nil if none was stored yet.
+func (a *AtomicGraph) Swap(r *ReadOnlyGraph) *ReadOnlyGraph {
+	old, _ := a.v.Swap(r).(*ReadOnlyGraph)
+	return old
+}
@@ -0,0 +1,94 @@
+package graw
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		change func(g *GraphModel)
+	}{
+		{"add", func(g *GraphModel) { g.Add(NewShape("b", "1")) }},
+		{"label", func(g *GraphModel) { g.FindByID("a").Label("changed") }},
+		{"geometry", func(g *GraphModel) { g.FindByID("a").At(500, 500) }},
+		{"style", func(g *GraphModel) { g.FindByID("a").Fill("#ff0000") }},
+		{"data", func(g *GraphModel) { g.FindByID("a").SetData("k", "changed") }},
+		{"remove", func(g *GraphModel) { g.Remove("a") }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewGraph()
+			g.Add(NewShape("a", "1").Label("A").At(10, 20).Fill("#ffffff").SetData("k", "v"))
+			r := g.Freeze()
+			test.change(&g)
+
+			if r.Len() != 3 {
+				t.Errorf("got %d cells, want 3", r.Len())
+			}
+			c, ok := r.Cell("a")
+			if !ok {
+				t.Fatal("got no cell a")
+			}
+			if c.Value != "A" || c.Geometry.X != 10 || c.Style.Attributes["fillColor"] != "#ffffff" || c.Data["k"] != "v" {
+				t.Errorf("got %+v, want the cell as frozen", c)
+			}
+		})
+	}
+}
+
+func TestReadOnlyGraphCopies(t *testing.T) {
+	g := NewGraph()
+	g.Add(NewShape("a", "1").Label("A"))
+	r := g.Freeze()
+
+	c, _ := r.Cell("a")
+	c.Label("changed").At(99, 99)
+	cells := r.Cells()
+	cells[2].Label("changed")
+	thawed := r.Thaw()
+	thawed.FindByID("a").Label("changed")
+
+	if c, _ := r.Cell("a"); c.Value != "A" || c.Geometry.X == 99 {
+		t.Errorf("got %+v, want the snapshot unchanged", c)
+	}
+	if _, ok := r.Cell("missing"); ok {
+		t.Errorf("got a missing cell")
+	}
+}
+
+func TestAtomicGraph(t *testing.T) {
+	var a AtomicGraph
+	if a.Load() != nil {
+		t.Fatal("got a snapshot from the zero value")
+	}
+	g := NewGraph()
+	first := g.Freeze()
+	a.Store(first)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if r := a.Load(); r == nil || r.Len() < 2 {
+					t.Errorf("got snapshot %v", r)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		g.Add(NewShape(g.uniqueID("n"), "1"))
+		a.Store(g.Freeze())
+	}
+	wg.Wait()
+
+	if old := a.Swap(first); old == nil || old.Len() != 12 {
+		t.Errorf("got previous snapshot %v, want the last one stored", old)
+	}
+	if a.Load() != first {
+		t.Errorf("got %v after Swap, want the first snapshot", a.Load())
+	}
+}
@@ -0,0 +1,96 @@
+package graw
+
+import (
+	"html"
+	"strconv"
+	"time"
+)
+
+// stampLayerID is the ID of the layer holding page stamps.
+const stampLayerID = "stamps"
+
+// PageStamp configures Document.StampPages.
+type PageStamp struct {
+	// Title is the document title shown on the left, omitted if empty.
+	Title string
+	// Date is shown on the right unless zero, formatted with
+	// DateLayout, "2006-01-02" if empty.
+	Date       time.Time
+	DateLayout string
+	// Top puts the stamp above the diagram instead of below it.
+	Top bool
+	// Style holds style keys added to the texts of the stamp, e.g.
+	// fontSize or fontColor.
+	Style map[string]string
+}
+
+// StampPages stamps every page of d with a footer, or a header, group
+// spanning the diagram: the title of the document on the left, the
+// page number, "Page 2 of 5", in the middle and the date on the right.
+// Stamps are kept on a layer of their own, "stamps", which can be
+// hidden or locked as a whole; stamping again replaces them, e.g.
+// after pages were added.
+func (d *Document) StampPages(s PageStamp) {
+	layout := s.DateLayout
+	if layout == "" {
+		layout = "2006-01-02"
+	}
+	for n, p := range d.pages {
+		if p.Model == nil {
+			continue
+		}
+		g := p.Model
+		if g.cell(stampLayerID) != nil {
+			g.RemoveCascade(stampLayerID)
+		}
+
+		var bounds Rect
+		for i := range g.Root {
+			c := g.Root[i]
+			if c.isVertex() && c.Geometry != nil && g.cell(c.ParentID) != nil && g.cell(c.ParentID).isLayer() {
+				bounds = bounds.Union(g.BoundsOf(c))
+			}
+		}
+		if bounds.Empty() {
+			bounds = Rect{10, 10, defaultWidth * 4, defaultHeight}
+		}
+		bounds.Width = maxInt(bounds.Width, defaultWidth*3)
+
+		g.Add(NewLayer(stampLayerID, "Stamps"))
+		grp := NewGroup(g.uniqueID("page-stamp"), stampLayerID)
+		grp.Geometry.X, grp.Geometry.Y = bounds.X, bounds.Y+bounds.Height+groupPadding
+		if s.Top {
+			grp.Geometry.Y = bounds.Y - groupPadding - groupHeader
+		}
+		grp.Geometry.setSize(bounds.Width, groupHeader)
+		g.Add(grp)
+
+		third := bounds.Width / 3
+		texts := []struct {
+			id, text, align string
+		}{
+			{"page-stamp-title", s.Title, "left"},
+			{"page-stamp-number", "Page " + strconv.Itoa(n+1) + " of " + strconv.Itoa(len(d.pages)), "center"},
+			{"page-stamp-date", "", "right"},
+		}
+		if !s.Date.IsZero() {
+			texts[2].text = s.Date.Format(layout)
+		}
+		for i, t := range texts {
+			if t.text == "" {
+				continue
+			}
+			c := NewShape(g.uniqueID(t.id), grp.ID)
+			c.Value = html.EscapeString(t.text)
+			c.Style = Style{Attributes: shapeStyle("text")}
+			c.Style.set("align", t.align)
+			c.Style.set("fontColor", "#666666")
+			for _, k := range sortedKeys(s.Style) {
+				c.Style.set(k, s.Style[k])
+			}
+			c.Geometry.X, c.Geometry.Y = i*third, 0
+			c.Geometry.setSize(third, groupHeader)
+			g.Add(c)
+		}
+	}
+}
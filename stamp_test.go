@@ -0,0 +1,118 @@
+package graw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStampPages(t *testing.T) {
+	date := time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		stamp PageStamp
+		// texts maps the IDs of the texts of the stamp of the first
+		// page to their labels.
+		texts map[string]string
+		group Rect
+		style map[string]string
+	}{
+		{
+			name:  "footer",
+			stamp: PageStamp{Title: "R&D", Date: date},
+			texts: map[string]string{"page-stamp-title": "R&amp;D", "page-stamp-number": "Page 1 of 3", "page-stamp-date": "2024-03-09"},
+			group: Rect{10, 90, 360, 30},
+			style: map[string]string{"align": "left", "fontColor": "#666666"},
+		},
+		{
+			name:  "header",
+			stamp: PageStamp{Top: true, Date: date, DateLayout: "Jan 2, 2006"},
+			texts: map[string]string{"page-stamp-number": "Page 1 of 3", "page-stamp-date": "Mar 9, 2024"},
+			group: Rect{10, -40, 360, 30},
+		},
+		{
+			name:  "number only",
+			stamp: PageStamp{Style: map[string]string{"fontColor": "#000000", "fontSize": "9"}},
+			texts: map[string]string{"page-stamp-number": "Page 1 of 3"},
+			group: Rect{10, 90, 360, 30},
+			style: map[string]string{"align": "center", "fontColor": "#000000", "fontSize": "9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last := NewGraph(), NewGraph()
+			first.Add(NewShape("a", "1"))
+			d := NewDocument()
+			d.AddPage("One", &first)
+			d.AddPage("No model", nil)
+			d.AddPage("Three", &last)
+
+			// Stamping twice replaces the stamps.
+			d.StampPages(PageStamp{Title: "old"})
+			d.StampPages(tt.stamp)
+
+			var texts int
+			for i := range first.Root {
+				c := first.Root[i]
+				if c.ParentID == "page-stamp" {
+					texts++
+				}
+			}
+			if texts != len(tt.texts) {
+				t.Errorf("got %d texts, want %d", texts, len(tt.texts))
+			}
+			for id, want := range tt.texts {
+				c := first.FindByID(id)
+				if c == nil {
+					t.Errorf("missing %s", id)
+					continue
+				}
+				if c.Value != want {
+					t.Errorf("got %s %q, want %q", id, c.Value, want)
+				}
+				if id == "page-stamp-title" || tt.stamp.Title == "" && id == "page-stamp-number" {
+					for k, v := range tt.style {
+						if got := c.Style.Attributes[k]; got != v {
+							t.Errorf("got %s %s=%q, want %q", id, k, got, v)
+						}
+					}
+				}
+			}
+			grp := first.FindByID("page-stamp")
+			if grp == nil || grp.ParentID != stampLayerID {
+				t.Fatalf("got group %+v, want it on the stamps layer", grp)
+			}
+			if got := first.BoundsOf(grp); got != tt.group {
+				t.Errorf("got group at %v, want %v", got, tt.group)
+			}
+			if l := first.FindByID(stampLayerID); l == nil || !l.isLayer() || l.Value != "Stamps" {
+				t.Errorf("got layer %+v", l)
+			}
+			if c := last.FindByID("page-stamp-number"); c == nil || c.Value != "Page 3 of 3" {
+				t.Errorf("got number of the last page %+v", c)
+			}
+			// The empty page gets a stamp of the default width.
+			if got, want := last.BoundsOf(last.FindByID("page-stamp")).Width, defaultWidth*4; got != want {
+				t.Errorf("got width on the empty page %d, want %d", got, want)
+			}
+			if iss := ValidateStyles(&first); len(iss) > 0 {
+				t.Errorf("got style issues %v", iss)
+			}
+		})
+	}
+}
+
+func TestStampPagesWide(t *testing.T) {
+	g := NewGraph()
+	a, b := placedShape("a", 0, 0), placedShape("b", 600, 300)
+	g.Add(a)
+	g.Add(b)
+	d := NewDocument()
+	d.AddPage("", &g)
+	d.StampPages(PageStamp{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	if got, want := g.BoundsOf(g.FindByID("page-stamp")), (Rect{0, 380, 720, 30}); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := GeometryRect(g.FindByID("page-stamp-date").Geometry), (Rect{480, 0, 240, 30}); got != want {
+		t.Errorf("got date at %v, want %v", got, want)
+	}
+}
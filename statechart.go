@@ -0,0 +1,252 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Transition is a transition of a state machine, triggered by Event
+// when Guard, if any, holds.
+type Transition struct {
+	From, To     string
+	Event, Guard string
+}
+
+// StateMachine describes a state machine for NewStateDiagram.
+type StateMachine struct {
+	// Initial is the state the machine starts in.
+	Initial string
+	// States lists the states in the order they are laid out; states
+	// only named by transitions follow them.
+	States      []string
+	Transitions []Transition
+	// Final lists the states the machine ends in.
+	Final []string
+	// Parents maps substates to the composite state holding them.
+	Parents map[string]string
+	// Details holds the lines shown below the name of a state, e.g.
+	// its entry and exit actions.
+	Details map[string][]string
+}
+
+// statechart lays out the states of a StateMachine.
+type statechart struct {
+	sm       StateMachine
+	cells    map[string]*Cell
+	children map[string][]string
+	order    []*Cell
+	taken    idSet
+}
+
+// NewStateDiagram returns a statechart of sm: states are rounded boxes,
+// composite states containers holding their substates, the initial
+// state is pointed to by a black dot and final states lead to a bull's
+// eye. States are arranged left to right by the number of transitions
+// needed to reach them. Transitions between the same states share an
+// edge labeled with all their events, followed by their guard.
+func NewStateDiagram(sm StateMachine) (*GraphModel, error) {
+	var states []string
+	known := make(map[string]bool)
+	addState := func(s string) {
+		if s != "" && !known[s] {
+			known[s] = true
+			states = append(states, s)
+		}
+	}
+	addState(sm.Initial)
+	for _, s := range sm.States {
+		addState(s)
+	}
+	for _, t := range sm.Transitions {
+		addState(t.From)
+		addState(t.To)
+	}
+	for child, parent := range sm.Parents {
+		if !known[child] || !known[parent] {
+			addState(parent)
+			addState(child)
+		}
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("graw: NewStateDiagram: no states")
+	}
+	for _, s := range states {
+		seen := map[string]bool{s: true}
+		for p := sm.Parents[s]; p != ""; p = sm.Parents[p] {
+			if seen[p] {
+				return nil, ErrCycle
+			}
+			seen[p] = true
+		}
+	}
+
+	g := NewGraph()
+	sc := &statechart{
+		sm:       sm,
+		cells:    make(map[string]*Cell),
+		children: make(map[string][]string),
+		taken:    g.idSet(),
+	}
+	for _, s := range states {
+		sc.children[sm.Parents[s]] = append(sc.children[sm.Parents[s]], s)
+	}
+	top, _, _ := sc.layout("", rootCellID, 10, 10)
+
+	var edges []*Cell
+	pseudo := func(base, shape string, x, y int) *Cell {
+		c := NewShape(sc.taken.unique(base), rootCellID)
+		c.Style = Style{Attributes: shapeStyle(shape)}
+		c.Style.set("fillColor", "#000000")
+		c.Geometry.X, c.Geometry.Y = x, y
+		c.Geometry.setSize(20, 20)
+		return c
+	}
+	if init, ok := sc.cells[sm.Initial]; ok {
+		// the dot goes left of the states, which move right
+		for _, c := range top {
+			c.Geometry.X += 60
+		}
+		x, y := sc.absolute(init)
+		_, h := init.Geometry.size()
+		dot := pseudo("initial", "ellipse", x-50, y+h/2-10)
+		sc.order = append([]*Cell{dot}, sc.order...)
+		edges = append(edges, newEdgeCell(sc.taken.unique(dot.ID+"-"+init.ID), rootCellID, dot.ID, init.ID))
+	}
+	for _, f := range sm.Final {
+		c, ok := sc.cells[f]
+		if !ok {
+			continue
+		}
+		x, y := sc.absolute(c)
+		w, h := c.Geometry.size()
+		end := pseudo("final-"+c.ID, "endState", x+w/2-10, y+h+40)
+		sc.order = append(sc.order, end)
+		edges = append(edges, newEdgeCell(sc.taken.unique(c.ID+"-"+end.ID), rootCellID, c.ID, end.ID))
+	}
+
+	var pairs [][2]string
+	labels := make(map[[2]string][]string)
+	for _, t := range sm.Transitions {
+		key := [2]string{t.From, t.To}
+		if _, ok := labels[key]; !ok {
+			pairs = append(pairs, key)
+		}
+		label := t.Event
+		if t.Guard != "" {
+			label += " [" + t.Guard + "]"
+		}
+		labels[key] = append(labels[key], html.EscapeString(label))
+	}
+	for _, p := range pairs {
+		from, to := sc.cells[p[0]], sc.cells[p[1]]
+		e := newEdgeCell(sc.taken.unique(from.ID+"-"+to.ID), rootCellID, from.ID, to.ID)
+		e.Value = strings.Join(labels[p], "<br>")
+		edges = append(edges, e)
+	}
+
+	for _, c := range append(sc.order, edges...) {
+		g.Add(c)
+	}
+	return &g, nil
+}
+
+// layout creates the cells of the substates of parent, or of the top
+// level states for "", and lays them out from (x, y) in columns by
+// their distance from the initial state, or the first of them. It
+// returns the cells and the width and height of the area used.
+func (sc *statechart) layout(parent, parentID string, x, y int) ([]*Cell, int, int) {
+	members := sc.children[parent]
+	index := make(map[string]int, len(members))
+	for i, s := range members {
+		index[s] = i
+	}
+	// member returns the member state s is, or is nested in
+	member := func(s string) (int, bool) {
+		for ; s != ""; s = sc.sm.Parents[s] {
+			if i, ok := index[s]; ok {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	cells := make([]*Cell, len(members))
+	for i, s := range members {
+		if len(sc.children[s]) > 0 {
+			c := newContainer(sc.taken.unique(s), parentID, s)
+			c.Style.set("rounded", "1")
+			c.Style.set("collapsible", "0")
+			// the details go below the name in a taller header
+			header := groupHeader
+			if details := sc.sm.Details[s]; len(details) > 0 {
+				c.Value = "<b>" + html.EscapeString(s) + "</b><br>" + htmlLines(details)
+				header += int(float64(len(details)) * defaultFontSize * lineHeight)
+				c.Style.set("startSize", strconv.Itoa(header))
+			}
+			sc.cells[s] = c
+			sc.order = append(sc.order, c)
+			_, w, h := sc.layout(s, c.ID, groupPadding, header+groupPadding)
+			c.Geometry.setSize(maxInt(w+2*groupPadding, defaultWidth), h+header+2*groupPadding)
+			cells[i] = c
+			continue
+		}
+		c := NewShape(sc.taken.unique(s), parentID)
+		c.Value = "<b>" + html.EscapeString(s) + "</b>"
+		if details := sc.sm.Details[s]; len(details) > 0 {
+			c.Value += "<hr>" + htmlLines(details)
+		}
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		SizeToFit(c, nil, defaultWidth, 40)
+		sc.cells[s] = c
+		sc.order = append(sc.order, c)
+		cells[i] = c
+	}
+
+	// breadth first from the initial state, or the first member
+	start := 0
+	if i, ok := member(sc.sm.Initial); ok {
+		start = i
+	}
+	level := make([]int, len(members))
+	for i := range level {
+		level[i] = -1
+	}
+	level[start] = 0
+	queue := []int{start}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, t := range sc.sm.Transitions {
+			from, ok1 := member(t.From)
+			to, ok2 := member(t.To)
+			if ok1 && ok2 && from == i && level[to] < 0 {
+				level[to] = level[i] + 1
+				queue = append(queue, to)
+			}
+		}
+	}
+	var columns [][]*Cell
+	for i, c := range cells {
+		l := level[i]
+		if l < 0 {
+			l = len(columns)
+		}
+		for len(columns) <= l {
+			columns = append(columns, nil)
+		}
+		columns[l] = append(columns[l], c)
+	}
+	w, h := layoutColumns(columns, x, y, 80)
+	return cells, w, h
+}
+
+// absolute returns the position of c relative to the layer.
+func (sc *statechart) absolute(c *Cell) (int, int) {
+	byID := make(map[string]*Cell, len(sc.cells))
+	for _, s := range sc.cells {
+		byID[s.ID] = s
+	}
+	return offsetOf(c, func(id string) *Cell { return byID[id] })
+}
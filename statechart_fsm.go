@@ -0,0 +1,129 @@
+package graw
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// FromLooplabFSM returns the statechart of a looplab/fsm state machine
+// starting in initial, from the fsm.Events it was created with. events
+// is a slice of structs with the fields Name, Src and Dst of
+// fsm.EventDesc, so the package need not be imported; an event leads
+// from each of its Src states to Dst.
+func FromLooplabFSM(initial string, events interface{}) (*GraphModel, error) {
+	v := reflect.ValueOf(events)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("graw: FromLooplabFSM: events is a %T, not a slice", events)
+	}
+	sm := StateMachine{Initial: initial}
+	for i := 0; i < v.Len(); i++ {
+		e := reflect.Indirect(v.Index(i))
+		if e.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("graw: FromLooplabFSM: event %d is not a struct", i)
+		}
+		name, src, dst := e.FieldByName("Name"), e.FieldByName("Src"), e.FieldByName("Dst")
+		if name.Kind() != reflect.String || dst.Kind() != reflect.String ||
+			src.Kind() != reflect.Slice || src.Type().Elem().Kind() != reflect.String {
+			return nil, fmt.Errorf("graw: FromLooplabFSM: event %d lacks Name, Src or Dst", i)
+		}
+		for j := 0; j < src.Len(); j++ {
+			sm.Transitions = append(sm.Transitions, Transition{
+				From:  src.Index(j).String(),
+				To:    dst.String(),
+				Event: name.String(),
+			})
+		}
+	}
+	return NewStateDiagram(sm)
+}
+
+// FromFSMDot returns the statechart of a state machine from its
+// graphviz graph, as written by fsm.Visualize of looplab/fsm or by
+// ToGraph of qmuntal/stateless. The initial state is the one the point
+// node "init" leads to, or else the current state, which looplab/fsm
+// draws in red. The clusters of stateless become composite states,
+// and the entry and exit actions of its record labels the details of
+// the states.
+func FromFSMDot(r io.Reader) (*GraphModel, error) {
+	stmts, attrs, err := parseDOTStatements(r)
+	if err != nil {
+		return nil, fmt.Errorf("graw: FromFSMDot: %v", err)
+	}
+
+	var sm StateMachine
+	sm.Parents = make(map[string]string)
+	sm.Details = make(map[string][]string)
+	// state returns the state of a node or cluster name, recording its
+	// label
+	state := func(name, label string) string {
+		name = strings.TrimPrefix(name, "cluster")
+		if label == "" {
+			return name
+		}
+		var lines []string
+		for _, part := range strings.Split(label, "|") {
+			for _, l := range strings.FieldsFunc(part, func(r rune) bool { return r == '\n' }) {
+				if l = strings.TrimSpace(l); l != "" && strings.Trim(l, "-") != "" {
+					lines = append(lines, l)
+				}
+			}
+		}
+		if len(lines) > 1 {
+			sm.Details[name] = lines[1:]
+		}
+		return name
+	}
+	for cluster, a := range attrs {
+		if strings.HasPrefix(cluster, "cluster") {
+			state(cluster, dotLabel(a["label"]))
+		}
+	}
+
+	var current string
+	for _, st := range stmts {
+		if st.to != "" || st.from == "init" {
+			continue
+		}
+		s := state(st.from, dotLabel(st.attrs["label"]))
+		sm.States = append(sm.States, s)
+		if strings.HasPrefix(st.subgraph, "cluster") {
+			sm.Parents[s] = state(st.subgraph, "")
+		}
+		if st.attrs["color"] == "red" && current == "" {
+			current = s
+		}
+	}
+	for _, st := range stmts {
+		if st.to == "" {
+			continue
+		}
+		if st.from == "init" {
+			sm.Initial = state(st.to, "")
+			continue
+		}
+		from, to := st.from, st.to
+		if c := st.attrs["ltail"]; c != "" {
+			from = c
+		}
+		if c := st.attrs["lhead"]; c != "" {
+			to = c
+		}
+		sm.Transitions = append(sm.Transitions, Transition{
+			From:  state(from, ""),
+			To:    state(to, ""),
+			Event: dotLabel(st.attrs["label"]),
+		})
+	}
+	if sm.Initial == "" {
+		sm.Initial = current
+	}
+	return NewStateDiagram(sm)
+}
+
+// dotLabel returns a graphviz label with its escaped line breaks, \n,
+// \l and \r, turned into newlines.
+func dotLabel(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\l`, "\n", `\r`, "\n").Replace(s)
+}
@@ -0,0 +1,191 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testEventDesc struct {
+	Name string
+	Src  []string
+	Dst  string
+}
+
+const statelessDot = `digraph {
+	compound=true;
+	node [shape=Mrecord];
+	rankdir="LR";
+
+	subgraph "clusterOnHold" {
+		label="OnHold\n----------\nentry / startHold";
+		"PhoneDestroyed" [label="PhoneDestroyed"];
+	}
+	"OffHook" [label="OffHook"];
+	"Ringing" [label="Ringing|entry / ring\nexit / stop"];
+	"OffHook" -> "Ringing" [label="CallDialed"];
+	"Ringing" -> "OffHook" [label="Hang [ok]"];
+	"Ringing" -> "PhoneDestroyed" [label="Smash", lhead="clusterOnHold"];
+	init [label="", shape=point];
+	init -> "OffHook"[style = "solid"]
+}`
+
+const looplabDot = `digraph fsm {
+    "closed" -> "open" [ label = "open" ];
+    "open" -> "closed" [ label = "close" ];
+
+    "closed" [color = "red"];
+    "open";
+}`
+
+func TestStateDiagrams(t *testing.T) {
+	tests := []struct {
+		name   string
+		graph  func() (*GraphModel, error)
+		labels map[string]string
+		edges  []string
+	}{
+		{
+			name: "state machine",
+			graph: func() (*GraphModel, error) {
+				return NewStateDiagram(StateMachine{
+					Initial: "idle",
+					Transitions: []Transition{
+						{From: "idle", To: "busy", Event: "start"},
+						{From: "busy", To: "idle", Event: "stop", Guard: "a < b"},
+						{From: "busy", To: "idle", Event: "abort"},
+					},
+					Final:   []string{"idle"},
+					Details: map[string][]string{"busy": {"do / work"}},
+				})
+			},
+			labels: map[string]string{
+				"initial":    "",
+				"final-idle": "",
+				"idle":       "<b>idle</b>",
+				"busy":       "<b>busy</b><hr>do / work",
+			},
+			edges: []string{
+				"initial->idle",
+				"idle->final-idle",
+				"idle->busy:start",
+				"busy->idle:stop [a &lt; b]<br>abort",
+			},
+		},
+		{
+			name: "looplab",
+			graph: func() (*GraphModel, error) {
+				return FromLooplabFSM("closed", []testEventDesc{
+					{"open", []string{"closed"}, "open"},
+					{"close", []string{"open"}, "closed"},
+					{"lock", []string{"closed", "open"}, "locked"},
+				})
+			},
+			labels: map[string]string{
+				"initial": "",
+				"closed":  "<b>closed</b>",
+				"open":    "<b>open</b>",
+				"locked":  "<b>locked</b>",
+			},
+			edges: []string{
+				"initial->closed",
+				"closed->open:open",
+				"open->closed:close",
+				"closed->locked:lock",
+				"open->locked:lock",
+			},
+		},
+		{
+			name: "stateless dot",
+			graph: func() (*GraphModel, error) {
+				return FromFSMDot(strings.NewReader(statelessDot))
+			},
+			labels: map[string]string{
+				"initial":        "",
+				"OffHook":        "<b>OffHook</b>",
+				"Ringing":        "<b>Ringing</b><hr>entry / ring<br>exit / stop",
+				"OnHold":         "<b>OnHold</b><br>entry / startHold",
+				"PhoneDestroyed": "<b>PhoneDestroyed</b>",
+			},
+			edges: []string{
+				"initial->OffHook",
+				"OffHook->Ringing:CallDialed",
+				"Ringing->OffHook:Hang [ok]",
+				"Ringing->OnHold:Smash",
+			},
+		},
+		{
+			name: "looplab dot",
+			graph: func() (*GraphModel, error) {
+				return FromFSMDot(strings.NewReader(looplabDot))
+			},
+			labels: map[string]string{
+				"initial": "",
+				"closed":  "<b>closed</b>",
+				"open":    "<b>open</b>",
+			},
+			edges: []string{
+				"initial->closed",
+				"closed->open:open",
+				"open->closed:close",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := tt.graph()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := vertexLabels(g); !reflect.DeepEqual(got, tt.labels) {
+				t.Errorf("got labels %q, want %q", got, tt.labels)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, tt.edges) {
+				t.Errorf("got edges %q, want %q", got, tt.edges)
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestStateDiagramComposite(t *testing.T) {
+	g, err := FromFSMDot(strings.NewReader(statelessDot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hold, phone := g.FindByID("OnHold"), g.FindByID("PhoneDestroyed")
+	if phone.ParentID != hold.ID {
+		t.Errorf("got parent %q, want %q", phone.ParentID, hold.ID)
+	}
+	// The substates go below the header holding the details.
+	header, ok := hold.Style.float("startSize")
+	if !ok || header <= groupHeader {
+		t.Fatalf("got startSize %v, want more than %d", header, groupHeader)
+	}
+	if phone.Geometry.Y < int(header) {
+		t.Errorf("got substate at y=%d, within the header of %v", phone.Geometry.Y, header)
+	}
+}
+
+func TestStateDiagramErrors(t *testing.T) {
+	if _, err := NewStateDiagram(StateMachine{}); err == nil {
+		t.Error("NewStateDiagram without states: got no error")
+	}
+	if _, err := NewStateDiagram(StateMachine{Parents: map[string]string{"a": "b", "b": "a"}}); err != ErrCycle {
+		t.Errorf("got %v, want ErrCycle", err)
+	}
+	for _, events := range []interface{}{
+		3,
+		[]int{1},
+		[]struct{ Name string }{{"open"}},
+	} {
+		if _, err := FromLooplabFSM("closed", events); err == nil {
+			t.Errorf("FromLooplabFSM(%v): got no error", events)
+		}
+	}
+	if _, err := FromFSMDot(strings.NewReader(`digraph { "a -> b }`)); err == nil {
+		t.Error("FromFSMDot with an unterminated string: got no error")
+	}
+}
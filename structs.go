@@ -0,0 +1,249 @@
+package graw
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// shapeStyles holds the style of the shapes which need more than the
+// shape key to look right, e.g. a matching perimeter.
+var shapeStyles = map[string]map[string]string{
+	"ellipse":  {"ellipse": "", "perimeter": "ellipsePerimeter"},
+	"rhombus":  {"rhombus": "", "perimeter": "rhombusPerimeter"},
+	"triangle": {"triangle": "", "perimeter": "trianglePerimeter"},
+	"hexagon":  {"shape": "hexagon", "perimeter": "hexagonPerimeter2"},
+	"cylinder": {"shape": "cylinder3", "boundedLbl": "1", "backgroundOutline": "1", "size": "15"},
+	"rounded":  {"rounded": "1"},
+	"text":     {"text": "", "strokeColor": "none", "fillColor": "none"},
+
+	// messaging
+	"queue":    {"shape": "cylinder3", "direction": "south", "boundedLbl": "1", "backgroundOutline": "1", "size": "15"},
+	"topic":    {"shape": "parallelogram", "perimeter": "parallelogramPerimeter", "fixedSize": "1", "size": "20"},
+	"document": {"shape": "document", "boundedLbl": "1", "size": "0.15"},
+}
+
+// shapeStyle returns the style attributes drawing the named shape.
+func shapeStyle(name string) map[string]string {
+	attrs := map[string]string{"whiteSpace": "wrap", "html": "1"}
+	if s, ok := shapeStyles[name]; ok {
+		for k, v := range s {
+			attrs[k] = v
+		}
+	} else if name != "" {
+		attrs["shape"] = name
+	}
+	return attrs
+}
+
+// structNode is a vertex created by FromStructs.
+type structNode struct {
+	cell  *Cell
+	value reflect.Value
+	group string
+}
+
+// FromStructs returns a diagram of the given structs. Every value,
+// a struct or a pointer to one, becomes a shape; pointer fields (and
+// slices, arrays and maps of pointers) referring to other values
+// passed in become edges labeled with the field name.
+//
+// The diagram is controlled with `graw:"..."` struct tags holding a
+// comma separated list of options. On fields:
+//
+//	id       the field value is the cell ID
+//	label    the field value is the shape label (default: a Name or
+//	         Title field, else the type name)
+//	group    shapes with the same field value are put in a container
+//	ref      the field holds the id of another value, or a slice of them
+//	edge=X   label edges created from this field X
+//	-        ignore the field
+//
+// On a blank field (`_ struct{}`) the options apply to the type:
+// shape=X selects the shape (e.g. ellipse, rhombus, cylinder),
+// group=X puts all values of the type in container X and style=X
+// adds a raw draw.io style such as "fillColor=#dae8fc;rounded=1".
+func FromStructs(values ...interface{}) (*GraphModel, error) {
+	g := NewGraph()
+	taken := g.idSet()
+
+	var nodes []*structNode
+	byPtr := make(map[uintptr]*structNode)
+	byID := make(map[string]*structNode)
+
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		var ptr uintptr
+		if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			ptr = rv.Pointer()
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("graw: FromStructs: value %d is a %s, not a struct", i, rv.Kind())
+		}
+
+		n := newStructNode(rv, i)
+		n.cell.ID = taken.unique(n.cell.ID)
+		nodes = append(nodes, n)
+		byID[n.cell.ID] = n
+		if ptr != 0 {
+			byPtr[ptr] = n
+		}
+		g.Add(n.cell)
+	}
+
+	var edges []*Cell
+	for _, n := range nodes {
+		t := n.value.Type()
+		for f := 0; f < t.NumField(); f++ {
+			field := t.Field(f)
+			opts := parseTag(field.Tag.Get("graw"))
+			if _, skip := opts["-"]; skip || field.PkgPath != "" {
+				continue
+			}
+			label := field.Name
+			if l := opts["edge"]; l != "" {
+				label = l
+			}
+
+			for _, target := range fieldTargets(n.value.Field(f), opts, byPtr, byID) {
+				e := newEdgeCell(taken.unique(n.cell.ID+"-"+target.cell.ID), rootCellID, n.cell.ID, target.cell.ID)
+				e.Value = label
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	cells := make([]*Cell, len(nodes))
+	groups := make(map[string]string, len(nodes))
+	grouped := false
+	for i, n := range nodes {
+		cells[i] = n.cell
+		if n.group != "" {
+			groups[n.cell.ID] = n.group
+			grouped = true
+		}
+	}
+	layoutGrid(cells, 10, 10, groupGap)
+	for _, e := range edges {
+		g.Add(e)
+	}
+
+	if grouped {
+		AutoGroup(&g, func(c *Cell) string { return groups[c.ID] })
+	}
+	return &g, nil
+}
+
+// newStructNode returns the shape for the i-th value rv.
+func newStructNode(rv reflect.Value, i int) *structNode {
+	t := rv.Type()
+	n := &structNode{value: rv}
+	id := fmt.Sprintf("%s-%d", strings.ToLower(t.Name()), i)
+	label := t.Name()
+	labelSet := false
+	var typeOpts map[string]string
+
+	for f := 0; f < t.NumField(); f++ {
+		field := t.Field(f)
+		opts := parseTag(field.Tag.Get("graw"))
+		if field.Name == "_" {
+			typeOpts = opts
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		value := func() string { return fmt.Sprint(rv.Field(f).Interface()) }
+		if _, ok := opts["id"]; ok {
+			id = value()
+		}
+		if _, ok := opts["label"]; ok {
+			label, labelSet = value(), true
+		}
+		if _, ok := opts["group"]; ok {
+			n.group = value()
+		}
+		if !labelSet && (field.Name == "Name" || field.Name == "Title") && rv.Field(f).Kind() == reflect.String {
+			label = value()
+		}
+	}
+
+	style := shapeStyle(typeOpts["shape"])
+	if raw := typeOpts["style"]; raw != "" {
+		for k, v := range parseStyle(raw).Attributes {
+			style[k] = v
+		}
+	}
+	if n.group == "" {
+		n.group = typeOpts["group"]
+	}
+
+	n.cell = NewShape(id, rootCellID)
+	n.cell.Value = label
+	n.cell.Style = Style{Attributes: style}
+	SizeToFit(n.cell, nil, defaultWidth, defaultHeight)
+	return n
+}
+
+// fieldTargets returns the nodes referenced by the field value fv.
+func fieldTargets(fv reflect.Value, opts map[string]string, byPtr map[uintptr]*structNode, byID map[string]*structNode) []*structNode {
+	var targets []*structNode
+	_, isRef := opts["ref"]
+
+	var visit func(v reflect.Value)
+	visit = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if !v.IsNil() {
+				if n, ok := byPtr[v.Pointer()]; ok {
+					targets = append(targets, n)
+				}
+			}
+		case reflect.Interface:
+			if !v.IsNil() {
+				visit(v.Elem())
+			}
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				visit(v.Index(i))
+			}
+		case reflect.Map:
+			keys := v.MapKeys()
+			sort.Slice(keys, func(i, j int) bool {
+				return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+			})
+			for _, k := range keys {
+				visit(v.MapIndex(k))
+			}
+		default:
+			if isRef {
+				if n, ok := byID[fmt.Sprint(v.Interface())]; ok {
+					targets = append(targets, n)
+				}
+			}
+		}
+	}
+	visit(fv)
+	return targets
+}
+
+// parseTag splits a graw struct tag into its options. Options without
+// a value map to an empty string.
+func parseTag(tag string) map[string]string {
+	opts := make(map[string]string)
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 {
+			opts[kv[0]] = kv[1]
+		} else {
+			opts[kv[0]] = ""
+		}
+	}
+	return opts
+}
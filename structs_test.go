@@ -0,0 +1,119 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testUser struct {
+	_     struct{} `graw:"shape=ellipse"`
+	ID    string   `graw:"id"`
+	Name  string
+	Team  *testTeam `graw:"edge=member of"`
+	Boss  string    `graw:"ref"`
+	Peers []string  `graw:"ref,edge=peer"`
+	Skip  *testTeam `graw:"-"`
+	notes *testTeam
+}
+
+type testTeam struct {
+	_    struct{} `graw:"shape=cylinder,style=fillColor=#dae8fc"`
+	Name string
+}
+
+type testHost struct {
+	Title string
+	Rack  string `graw:"group"`
+	Code  string `graw:"label"`
+}
+
+func TestFromStructs(t *testing.T) {
+	team := &testTeam{Name: "Infra"}
+	for _, test := range []struct {
+		name   string
+		values []interface{}
+		labels map[string]string
+		edges  []string
+	}{
+		{
+			name: "pointers and refs",
+			values: []interface{}{
+				&testUser{ID: "u1", Name: "Ann", Team: team, Skip: team, notes: team},
+				testUser{ID: "u2", Name: "Bob", Team: team, Boss: "u1", Peers: []string{"u1", "missing"}},
+				team,
+			},
+			labels: map[string]string{"u1": "Ann", "u2": "Bob", "testteam-2": "Infra"},
+			edges:  []string{"u1->testteam-2:member of", "u2->testteam-2:member of", "u2->u1:Boss", "u2->u1:peer"},
+		},
+		{
+			name:   "duplicate IDs",
+			values: []interface{}{testUser{ID: "u"}, testUser{ID: "u"}},
+			labels: map[string]string{"u": "", "u-2": ""},
+		},
+		{
+			name:   "label and group",
+			values: []interface{}{testHost{Title: "web", Rack: "r1", Code: "W1"}, testHost{Title: "db", Rack: "r1", Code: "D1"}, testHost{Title: "x", Code: "X"}},
+			labels: map[string]string{"testhost-0": "W1", "testhost-1": "D1", "testhost-2": "X", "group-r1": "r1"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g, err := FromStructs(test.values...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := vertexLabels(g); !reflect.DeepEqual(got, test.labels) {
+				t.Errorf("got vertices %v, want %v", got, test.labels)
+			}
+			if got := edgeList(g); !reflect.DeepEqual(got, test.edges) {
+				t.Errorf("got edges %v, want %v", got, test.edges)
+			}
+			if issues := ValidateStyles(g); len(issues) > 0 {
+				t.Errorf("got style issues %v", issues)
+			}
+		})
+	}
+}
+
+func TestFromStructsStyles(t *testing.T) {
+	team := &testTeam{Name: "Infra"}
+	g, err := FromStructs(testUser{ID: "u"}, team)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		id, key, want string
+	}{
+		{"u", "perimeter", "ellipsePerimeter"},
+		{"testteam-1", "shape", "cylinder3"},
+		{"testteam-1", "fillColor", "#dae8fc"},
+	} {
+		if got := g.FindByID(test.id).Style.Attributes[test.key]; got != test.want {
+			t.Errorf("got %s %s=%q, want %q", test.id, test.key, got, test.want)
+		}
+	}
+}
+
+func TestFromStructsErrors(t *testing.T) {
+	for _, v := range []interface{}{3, "x", (*testTeam)(nil), []testTeam{}} {
+		if _, err := FromStructs(v); err == nil || !strings.HasPrefix(err.Error(), "graw: FromStructs: value 0 is a ") {
+			t.Errorf("FromStructs(%#v): got error %v", v, err)
+		}
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	for _, test := range []struct {
+		tag  string
+		want map[string]string
+	}{
+		{"", map[string]string{}},
+		{"id", map[string]string{"id": ""}},
+		{"ref, edge=peer of,", map[string]string{"ref": "", "edge": "peer of"}},
+		{"style=a=1;b=2", map[string]string{"style": "a=1;b=2"}},
+	} {
+		if got := parseTag(test.tag); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseTag(%q): got %v, want %v", test.tag, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package graw
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// A Stylesheet holds the named styles of an mxStylesheet element, as
+// found in draw.io templates and themes: "defaultVertex" and
+// "defaultEdge" apply to all vertices and edges, other styles to the
+// cells naming them in their style, e.g. "process;fillColor=#fff".
+type Stylesheet struct {
+	// Styles maps style names to their keys, with the styles they
+	// extend already merged in.
+	Styles map[string]Style
+
+	// order lists the names of Styles in the order of the
+	// mxStylesheet element they were read from.
+	order []string
+}
+
+// stylesheetXML mirrors the mxStylesheet element.
+type stylesheetXML struct {
+	XMLName xml.Name        `xml:"mxStylesheet"`
+	Styles  []namedStyleXML `xml:"add"`
+}
+
+// namedStyleXML is a named style of an mxStylesheet element.
+type namedStyleXML struct {
+	As     string          `xml:"as,attr"`
+	Extend string          `xml:"extend,attr,omitempty"`
+	Add    []styleEntryXML `xml:"add"`
+	Remove []styleEntryXML `xml:"remove"`
+}
+
+// styleEntryXML is a key of a named style.
+type styleEntryXML struct {
+	As    string `xml:"as,attr"`
+	Value string `xml:"value,attr,omitempty"`
+}
+
+// ParseStylesheet reads an mxStylesheet element, e.g. the default.xml
+// of a draw.io theme.
+func ParseStylesheet(r io.Reader) (*Stylesheet, error) {
+	var s Stylesheet
+	if err := xml.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("graw: ParseStylesheet: %v", err)
+	}
+	return &s, nil
+}
+
+// UnmarshalXML decodes an mxStylesheet element, merging the styles a
+// style extends into it. It implements xml.Unmarshaler interface.
+func (s *Stylesheet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var aux stylesheetXML
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	s.Styles = make(map[string]Style, len(aux.Styles))
+	s.order = nil
+	for _, ns := range aux.Styles {
+		if _, ok := s.Styles[ns.As]; !ok {
+			s.order = append(s.order, ns.As)
+		}
+		attrs := make(map[string]string)
+		if base, ok := s.Styles[ns.Extend]; ok {
+			for k, v := range base.Attributes {
+				attrs[k] = v
+			}
+		}
+		for _, e := range ns.Add {
+			attrs[e.As] = e.Value
+		}
+		for _, e := range ns.Remove {
+			delete(attrs, e.As)
+		}
+		s.Styles[ns.As] = Style{Attributes: attrs}
+	}
+	return nil
+}
+
+// MarshalXML encodes s as an mxStylesheet element, the styles in the
+// order they were declared in, see Resolve, their keys sorted. It
+// implements xml.Marshaler interface.
+func (s *Stylesheet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var aux stylesheetXML
+	for _, name := range s.declared() {
+		style := s.Styles[name]
+		ns := namedStyleXML{As: name}
+		for _, k := range sortedKeys(style.Attributes) {
+			ns.Add = append(ns.Add, styleEntryXML{As: k, Value: style.Attributes[k]})
+		}
+		aux.Styles = append(aux.Styles, ns)
+	}
+	return e.Encode(aux)
+}
+
+// styleNames returns the names of the styles of s as a set.
+func (s *Stylesheet) styleNames() map[string]string {
+	names := make(map[string]string, len(s.Styles))
+	for name := range s.Styles {
+		names[name] = ""
+	}
+	return names
+}
+
+// declared returns the names of the styles of s in the order they were
+// declared in, followed by those added to Styles since, sorted.
+func (s *Stylesheet) declared() []string {
+	names := make([]string, 0, len(s.Styles))
+	seen := make(map[string]bool, len(s.order))
+	for _, name := range s.order {
+		if _, ok := s.Styles[name]; ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, name := range sortedKeys(s.styleNames()) {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Resolve returns the effective style of c: the default style of its
+// kind, then the named styles it refers to in the order s declares
+// them, then its own keys, later ones overriding earlier ones. draw.io
+// applies named styles in the order of the style string, which Style
+// does not keep. Names which are not in s are kept.
+func (s *Stylesheet) Resolve(c *Cell) Style {
+	attrs := make(map[string]string)
+	base := "defaultVertex"
+	if c.isEdge() {
+		base = "defaultEdge"
+	}
+	for k, v := range s.Styles[base].Attributes {
+		attrs[k] = v
+	}
+	for _, name := range s.declared() {
+		if v, ok := c.Style.Attributes[name]; ok && v == "" {
+			for k, v := range s.Styles[name].Attributes {
+				attrs[k] = v
+			}
+		}
+	}
+	for k, v := range c.Style.Attributes {
+		if _, ok := s.Styles[k]; ok && v == "" {
+			continue
+		}
+		attrs[k] = v
+	}
+	return Style{Attributes: attrs}
+}
+
+// Inline replaces the styles of the vertices and edges of g by their
+// styles resolved against s, so that they look the same in draw.io
+// whatever stylesheet it uses, e.g. when generated content meant for
+// a template is merged into it; InjectInto does so for templates
+// defining a stylesheet.
+func (s *Stylesheet) Inline(g *GraphModel) {
+	s.inline(g.Root)
+}
+
+// inline resolves the styles of the vertices and edges among cells.
+func (s *Stylesheet) inline(cells []*Cell) {
+	for _, c := range cells {
+		if c.isVertex() || c.isEdge() {
+			c.Style = s.Resolve(c)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testStylesheet = `<mxStylesheet>
+  <add as="defaultVertex">
+    <add as="fillColor" value="#ffffff"/>
+    <add as="fontSize" value="12"/>
+  </add>
+  <add as="defaultEdge">
+    <add as="endArrow" value="block"/>
+  </add>
+  <add as="zeta">
+    <add as="fillColor" value="#zzzzzz"/>
+    <add as="rounded" value="1"/>
+  </add>
+  <add as="alpha" extend="zeta">
+    <add as="fillColor" value="#aaaaaa"/>
+    <remove as="rounded"/>
+  </add>
+</mxStylesheet>`
+
+func TestParseStylesheet(t *testing.T) {
+	s, err := ParseStylesheet(strings.NewReader(testStylesheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.declared(), []string{"defaultVertex", "defaultEdge", "zeta", "alpha"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %v, want %v", got, want)
+	}
+	if got, want := s.Styles["alpha"].Attributes, map[string]string{"fillColor": "#aaaaaa"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got alpha %v, want %v", got, want)
+	}
+}
+
+func TestStylesheetResolve(t *testing.T) {
+	s, err := ParseStylesheet(strings.NewReader(testStylesheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		cell *Cell
+		want map[string]string
+	}{
+		{"default vertex", NewShape("a", "1"), map[string]string{"fillColor": "#ffffff", "fontSize": "12"}},
+		{"default edge", newEdgeCell("e", "1", "a", "b"), map[string]string{"endArrow": "block"}},
+		{"own keys win", NewShape("a", "1").Styled("zeta", "").Styled("fillColor", "#123456"),
+			map[string]string{"fillColor": "#123456", "fontSize": "12", "rounded": "1"}},
+		// alpha is declared after zeta, so it wins although it sorts
+		// first.
+		{"declaration order", NewShape("a", "1").Styled("zeta", "").Styled("alpha", ""),
+			map[string]string{"fillColor": "#aaaaaa", "fontSize": "12", "rounded": "1"}},
+		{"unknown name kept", NewShape("a", "1").Styled("ellipse", ""),
+			map[string]string{"ellipse": "", "fillColor": "#ffffff", "fontSize": "12"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			delete(test.cell.Style.Attributes, "whiteSpace")
+			delete(test.cell.Style.Attributes, "html")
+			if got := s.Resolve(test.cell).Attributes; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStylesheetResolveAdded(t *testing.T) {
+	s := &Stylesheet{Styles: map[string]Style{
+		"b": {Attributes: map[string]string{"fillColor": "#bbbbbb"}},
+		"a": {Attributes: map[string]string{"fillColor": "#aaaaaa"}},
+	}}
+	c := NewShape("x", "1")
+	c.Style = Style{Attributes: map[string]string{"a": "", "b": ""}}
+	if got := s.Resolve(c).Attributes["fillColor"]; got != "#bbbbbb" {
+		t.Errorf("got fillColor %q, want #bbbbbb", got)
+	}
+}
+
+func TestInjectIntoStylesheet(t *testing.T) {
+	template := `<mxfile>` + testStylesheet + `<diagram id="p" name="Page-1"><mxGraphModel><root>
+<mxCell id="0"/>
+<mxCell id="1" value="content" parent="0"/>
+</root></mxGraphModel></diagram></mxfile>`
+	path := filepath.Join(t.TempDir(), "template.drawio")
+	if err := os.WriteFile(path, []byte(template), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewGraph()
+	g.Add(NewShape("a", "1").Styled("zeta", ""))
+	out, err := InjectInto(path, "content", &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ParseDocument(strings.NewReader(string(out)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := d.Pages()[0].Model.FindByID("a")
+	if c == nil {
+		t.Fatalf("got no cell a in\n%s", out)
+	}
+	for k, want := range map[string]string{"fillColor": "#zzzzzz", "fontSize": "12", "rounded": "1"} {
+		if got := c.Style.Attributes[k]; got != want {
+			t.Errorf("got %s=%q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestStylesheetRoundTrip(t *testing.T) {
+	// beta is declared first, so alpha wins where they overlap.
+	const text = `<mxStylesheet>
+  <add as="beta">
+    <add as="fillColor" value="#bbbbbb"/>
+    <add as="rounded" value="1"/>
+  </add>
+  <add as="defaultVertex">
+    <add as="fontSize" value="12"/>
+  </add>
+  <add as="alpha">
+    <add as="fillColor" value="#aaaaaa"/>
+  </add>
+</mxStylesheet>`
+	s, err := ParseStylesheet(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := xml.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := ParseStylesheet(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := back.declared(), []string{"beta", "defaultVertex", "alpha"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got order %v, want %v", got, want)
+	}
+	c := NewShape("a", "1").Styled("alpha", "").Styled("beta", "")
+	want := map[string]string{"fillColor": "#aaaaaa", "fontSize": "12", "rounded": "1"}
+	for _, sheet := range []*Stylesheet{s, back} {
+		got := sheet.Resolve(c).Attributes
+		delete(got, "whiteSpace")
+		delete(got, "html")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package graw
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+)
+
+// subnetColors are the fill colors of subnets by utilization: below
+// half, below 80% and above.
+var subnetColors = [3]string{"#d5e8d4", "#fff2cc", "#f8cecc"}
+
+// Subnet is a block of an IP address plan.
+type Subnet struct {
+	// CIDR is the network in CIDR notation, e.g. "10.0.1.0/24".
+	CIDR    string
+	Name    string
+	Gateway string
+	// Used is the number of addresses in use, for subnets without
+	// child subnets.
+	Used int
+}
+
+// subnetNode is a Subnet of NewSubnetTree with its parsed network.
+type subnetNode struct {
+	Subnet
+	ip       net.IP
+	ones     int
+	bits     int
+	net      *net.IPNet
+	cell     *Cell
+	children []*subnetNode
+}
+
+// size returns the number of addresses of n.
+func (n *subnetNode) size() float64 {
+	return math.Ldexp(1, n.bits-n.ones)
+}
+
+// hosts returns the number of addresses of n hosts can use: IPv4
+// networks lose their network and broadcast addresses, unless they
+// are point-to-point links or single hosts.
+func (n *subnetNode) hosts() float64 {
+	if n.bits == 32 && n.ones < 31 {
+		return n.size() - 2
+	}
+	return n.size()
+}
+
+// utilization returns the part of n in use: the addresses used by
+// hosts, or the address space allocated to child subnets.
+func (n *subnetNode) utilization() float64 {
+	if len(n.children) == 0 {
+		return math.Min(float64(n.Used)/n.hosts(), 1)
+	}
+	var allocated float64
+	for _, c := range n.children {
+		allocated += c.size()
+	}
+	return allocated / n.size()
+}
+
+// NewSubnetTree returns the hierarchy of an IP address plan: every
+// subnet is drawn below the smallest block containing it, siblings in
+// address order, labeled with its name, network, mask and gateway.
+// Subnets are colored by utilization, which is the share of their
+// hosts in use, or of their address space allocated to child subnets.
+func NewSubnetTree(subnets []Subnet) (*GraphModel, error) {
+	nodes := make([]*subnetNode, len(subnets))
+	seen := make(map[string]bool, len(subnets))
+	for i, s := range subnets {
+		ip, ipnet, err := net.ParseCIDR(s.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("graw: NewSubnetTree: %v", err)
+		}
+		if !ip.Equal(ipnet.IP) {
+			return nil, fmt.Errorf("graw: NewSubnetTree: %s has host bits set, the network is %s", s.CIDR, ipnet)
+		}
+		if seen[ipnet.String()] {
+			return nil, fmt.Errorf("graw: NewSubnetTree: duplicate subnet %s", ipnet)
+		}
+		seen[ipnet.String()] = true
+		ones, bits := ipnet.Mask.Size()
+		nodes[i] = &subnetNode{Subnet: s, ip: ipnet.IP.To16(), ones: ones, bits: bits, net: ipnet}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if c := bytes.Compare(nodes[i].ip, nodes[j].ip); c != 0 {
+			return c < 0
+		}
+		return nodes[i].ones < nodes[j].ones
+	})
+
+	// in address order a block comes right before its subnets, so a
+	// stack of the enclosing blocks finds the parent of each
+	var roots, stack []*subnetNode
+	for _, n := range nodes {
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if top.bits == n.bits && top.net.Contains(n.ip) {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, n)
+		} else {
+			top := stack[len(stack)-1]
+			top.children = append(top.children, n)
+		}
+		stack = append(stack, n)
+	}
+
+	g := NewGraph()
+	taken := g.idSet()
+	children := make(map[*Cell][]*Cell)
+	var cells, edges []*Cell
+	var add func(n *subnetNode, parent *Cell)
+	add = func(n *subnetNode, parent *Cell) {
+		c := NewShape(taken.unique(n.net.String()), rootCellID)
+		if n.Name != "" {
+			c.Value = "<b>" + html.EscapeString(n.Name) + "</b><br>" + n.net.String()
+		} else {
+			c.Value = "<b>" + n.net.String() + "</b>"
+		}
+		if n.bits == 32 {
+			c.Value += "<br>mask " + net.IP(n.net.Mask).String()
+		}
+		if n.Gateway != "" {
+			c.Value += "<br>gateway " + html.EscapeString(n.Gateway)
+		}
+		u := n.utilization()
+		c.Value += "<br>" + strconv.Itoa(int(math.Round(u*100))) + "% used"
+		c.Style = Style{Attributes: shapeStyle("rounded")}
+		switch {
+		case u < 0.5:
+			c.Style.set("fillColor", subnetColors[0])
+		case u < 0.8:
+			c.Style.set("fillColor", subnetColors[1])
+		default:
+			c.Style.set("fillColor", subnetColors[2])
+		}
+		if len(n.children) == 0 {
+			c.Data = map[string]string{
+				"tooltip": fmt.Sprintf("%d of %.0f addresses used", n.Used, n.hosts()),
+			}
+		} else {
+			subnets := "subnets"
+			if len(n.children) == 1 {
+				subnets = "subnet"
+			}
+			c.Data = map[string]string{
+				"tooltip": fmt.Sprintf("%d %s, %.0f of %.0f addresses allocated", len(n.children), subnets, u*n.size(), n.size()),
+			}
+		}
+		SizeToFit(c, nil, defaultWidth, defaultHeight)
+		n.cell = c
+		cells = append(cells, c)
+		if parent != nil {
+			children[parent] = append(children[parent], c)
+			e := newEdgeCell(taken.unique(parent.ID+"-"+c.ID), rootCellID, parent.ID, c.ID)
+			e.Style.set("edgeStyle", "elbowEdgeStyle")
+			e.Style.set("elbow", "vertical")
+			e.Style.set("endArrow", "none")
+			edges = append(edges, e)
+		}
+		for _, child := range n.children {
+			add(child, c)
+		}
+	}
+	var rootCells []*Cell
+	for _, n := range roots {
+		add(n, nil)
+		rootCells = append(rootCells, n.cell)
+	}
+	layoutTree(rootCells, children, 10, 10, 20, 60)
+	for _, c := range append(cells, edges...) {
+		g.Add(c)
+	}
+	return &g, nil
+}
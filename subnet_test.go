@@ -0,0 +1,106 @@
+package graw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewSubnetTree(t *testing.T) {
+	g, err := NewSubnetTree([]Subnet{
+		{CIDR: "10.0.1.0/24", Name: "web", Gateway: "10.0.1.1", Used: 200},
+		{CIDR: "10.0.0.0/16", Name: "vpc"},
+		{CIDR: "10.0.2.0/24", Name: "db", Used: 10},
+		{CIDR: "10.0.2.0/26", Name: "db-a", Used: 10},
+		{CIDR: "192.168.0.0/30", Used: 2},
+		{CIDR: "fd00::/64", Used: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id, label, tooltip, fill string
+	}{
+		{
+			"10.0.0.0/16",
+			"<b>vpc</b><br>10.0.0.0/16<br>mask 255.255.0.0<br>1% used",
+			"2 subnets, 512 of 65536 addresses allocated",
+			subnetColors[0],
+		},
+		{
+			"10.0.1.0/24",
+			"<b>web</b><br>10.0.1.0/24<br>mask 255.255.255.0<br>gateway 10.0.1.1<br>79% used",
+			"200 of 254 addresses used",
+			subnetColors[1],
+		},
+		{
+			"10.0.2.0/24",
+			"<b>db</b><br>10.0.2.0/24<br>mask 255.255.255.0<br>25% used",
+			"1 subnet, 64 of 256 addresses allocated",
+			subnetColors[0],
+		},
+		{
+			"10.0.2.0/26",
+			"<b>db-a</b><br>10.0.2.0/26<br>mask 255.255.255.192<br>16% used",
+			"10 of 62 addresses used",
+			subnetColors[0],
+		},
+		{
+			"192.168.0.0/30",
+			"<b>192.168.0.0/30</b><br>mask 255.255.255.252<br>100% used",
+			"2 of 2 addresses used",
+			subnetColors[2],
+		},
+		{
+			"fd00::/64",
+			"<b>fd00::/64</b><br>0% used",
+			"3 of 18446744073709551616 addresses used",
+			subnetColors[0],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			c := g.FindByID(tt.id)
+			if c == nil {
+				t.Fatal("missing")
+			}
+			if c.Value != tt.label {
+				t.Errorf("got label %q, want %q", c.Value, tt.label)
+			}
+			if got := c.Data["tooltip"]; got != tt.tooltip {
+				t.Errorf("got tooltip %q, want %q", got, tt.tooltip)
+			}
+			if got := c.Style.Attributes["fillColor"]; got != tt.fill {
+				t.Errorf("got fillColor %q, want %q", got, tt.fill)
+			}
+		})
+	}
+
+	want := []string{
+		"10.0.0.0/16->10.0.1.0/24",
+		"10.0.0.0/16->10.0.2.0/24",
+		"10.0.2.0/24->10.0.2.0/26",
+	}
+	if got := edgeList(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("got edges %v, want %v", got, want)
+	}
+	if iss := ValidateStyles(g); len(iss) > 0 {
+		t.Errorf("invalid styles: %v", iss)
+	}
+}
+
+func TestNewSubnetTreeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		subnets []Subnet
+	}{
+		{"bad CIDR", []Subnet{{CIDR: "10.0.0.0"}}},
+		{"host bits", []Subnet{{CIDR: "10.0.0.1/24"}}},
+		{"duplicate", []Subnet{{CIDR: "10.0.0.0/24"}, {CIDR: "10.0.0.0/24"}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewSubnetTree(tt.subnets); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
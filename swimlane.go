@@ -0,0 +1,104 @@
+package graw
+
+import "strconv"
+
+// Default sizes of swimlanes.
+const (
+	swimlaneHeader     = 30
+	swimlaneLaneHeight = 120
+)
+
+// NewSwimlane returns a new swimlane Cell, a container with its title
+// in a header bar, with the given unique ID (id) and parent ID
+// (layerId). title may hold HTML. Add lanes to it with
+// GraphModel.AddLane, making it a pool, and cells with
+// GraphModel.PlaceInLane.
+func NewSwimlane(id, layerId, title string) *Cell {
+	s := newContainer(id, layerId, title)
+	s.Style.set("collapsible", "0")
+	s.Style.set("startSize", strconv.Itoa(swimlaneHeader))
+	s.Geometry.setSize(defaultWidth*4, swimlaneHeader)
+	return s
+}
+
+// AddLane adds to g a lane titled title, a swimlane with its header on
+// its left, below the lanes of pool and returns it. pool is added to g
+// first when missing. Lanes span the width of the pool, which grows to
+// hold them.
+func (g *GraphModel) AddLane(pool *Cell, id, title string) *Cell {
+	if g.cell(pool.ID) == nil {
+		g.Add(pool)
+	}
+	lane := NewSwimlane(id, pool.ID, title)
+	lane.Style.set("horizontal", "0")
+	lane.Geometry.setSize(defaultWidth*4, swimlaneLaneHeight)
+	g.Add(lane)
+	g.layoutLanes(g.cell(pool.ID))
+	return g.cell(id)
+}
+
+// PlaceInLane puts c into the swimlane lane at (x, y), measured from
+// the top left corner of its content area, i.e. beside or below its
+// header of startSize pixels: the geometry of a child of a container
+// is relative to the container. c is added to g when missing. The lane
+// grows to hold c, and when it is a lane of a pool the pool and the
+// following lanes adjust.
+func (g *GraphModel) PlaceInLane(lane, c *Cell, x, y int) {
+	if g.cell(c.ID) == nil {
+		g.Add(c)
+	}
+	l, c := g.cell(lane.ID), g.cell(c.ID)
+	dx, dy := swimlaneContent(l)
+	c.ParentID = l.ID
+	if c.Geometry == nil {
+		c.Geometry = newGeometry()
+	}
+	c.Geometry.X, c.Geometry.Y = dx+x, dy+y
+	g.parentFirst(l.ID, c.ID)
+
+	l = g.cell(lane.ID)
+	w, h := l.Geometry.size()
+	r := GeometryRect(g.cell(c.ID).Geometry)
+	l.Geometry.setSize(maxInt(w, r.X+r.Width+groupPadding), maxInt(h, r.Y+r.Height+groupPadding))
+	if pool := g.cell(l.ParentID); pool != nil && isShape(pool.Style, "swimlane") {
+		g.layoutLanes(pool)
+	}
+}
+
+// swimlaneContent returns the offset of the content area of the
+// swimlane c from its corner: the size of its header, on top or, for
+// horizontal=0, on the left.
+func swimlaneContent(c *Cell) (int, int) {
+	size := swimlaneHeader
+	if v, ok := c.Style.float("startSize"); ok {
+		size = int(v)
+	}
+	if c.Style.Attributes["horizontal"] == "0" {
+		return size, 0
+	}
+	return 0, size
+}
+
+// layoutLanes stacks the lanes of pool below its header, makes them
+// as wide as the widest one and fits pool around them.
+func (g *GraphModel) layoutLanes(pool *Cell) {
+	var lanes []*Cell
+	width, _ := pool.Geometry.size()
+	dx, dy := swimlaneContent(pool)
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.ParentID == pool.ID && isShape(c.Style, "swimlane") && c.Geometry != nil {
+			lanes = append(lanes, c)
+			w, _ := c.Geometry.size()
+			width = maxInt(width, dx+w)
+		}
+	}
+	y := dy
+	for _, l := range lanes {
+		_, h := l.Geometry.size()
+		l.Geometry.X, l.Geometry.Y = dx, y
+		l.Geometry.setSize(width-dx, h)
+		y += h
+	}
+	pool.Geometry.setSize(width, y)
+}
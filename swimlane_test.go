@@ -0,0 +1,151 @@
+package graw
+
+import "testing"
+
+func TestNewSwimlane(t *testing.T) {
+	s := NewSwimlane("s", "1", "<b>Team</b>")
+	want := map[string]string{"swimlane": "", "container": "1", "collapsible": "0", "startSize": "30", "html": "1", "whiteSpace": "wrap"}
+	for k, v := range want {
+		if got, ok := s.Style.Attributes[k]; !ok || got != v {
+			t.Errorf("got %s=%q, want %q", k, got, v)
+		}
+	}
+	if r := GeometryRect(s.Geometry); r.Width != 480 || r.Height != 30 {
+		t.Errorf("got size %d x %d, want 480 x 30", r.Width, r.Height)
+	}
+	if s.Value != "<b>Team</b>" || s.ParentID != "1" {
+		t.Errorf("got value %q in %q", s.Value, s.ParentID)
+	}
+}
+
+func TestAddLane(t *testing.T) {
+	g := NewGraph()
+	pool := NewSwimlane("p", "1", "Pool")
+	l1 := g.AddLane(pool, "l1", "Sales")
+	l2 := g.AddLane(pool, "l2", "Support")
+	if g.FindByID("p") == nil || l1 != g.FindByID("l1") || l2 != g.FindByID("l2") {
+		t.Fatal("got lanes or pool missing from the model")
+	}
+	for _, tt := range []struct {
+		id   string
+		want Rect
+	}{
+		{"p", Rect{10, 10, 480, 270}},
+		{"l1", Rect{0, 30, 480, 120}},
+		{"l2", Rect{0, 150, 480, 120}},
+	} {
+		c := g.FindByID(tt.id)
+		if got := GeometryRect(c.Geometry); got != tt.want {
+			t.Errorf("got %s at %v, want %v", tt.id, got, tt.want)
+		}
+	}
+	if l1.ParentID != "p" || l1.Style.Attributes["horizontal"] != "0" || l1.Value != "Sales" {
+		t.Errorf("got lane in %q, horizontal %q, title %q", l1.ParentID, l1.Style.Attributes["horizontal"], l1.Value)
+	}
+	if iss := ValidateStyles(&g); len(iss) > 0 {
+		t.Errorf("got style issues %v", iss)
+	}
+}
+
+func TestPlaceInLane(t *testing.T) {
+	tests := []struct {
+		name   string
+		lane   string
+		x, y   int
+		cell   Rect
+		bounds map[string]Rect
+	}{
+		{
+			name:   "fits",
+			lane:   "l1",
+			x:      20,
+			y:      10,
+			cell:   Rect{50, 10, 120, 60},
+			bounds: map[string]Rect{"p": {10, 10, 480, 270}, "l1": {0, 30, 480, 120}, "l2": {0, 150, 480, 120}},
+		},
+		{
+			name:   "widens the pool",
+			lane:   "l2",
+			x:      500,
+			y:      10,
+			cell:   Rect{530, 10, 120, 60},
+			bounds: map[string]Rect{"p": {10, 10, 670, 270}, "l1": {0, 30, 670, 120}, "l2": {0, 150, 670, 120}},
+		},
+		{
+			name:   "moves the lanes below",
+			lane:   "l1",
+			x:      20,
+			y:      100,
+			cell:   Rect{50, 100, 120, 60},
+			bounds: map[string]Rect{"p": {10, 10, 480, 330}, "l1": {0, 30, 480, 180}, "l2": {0, 210, 480, 120}},
+		},
+		{
+			name:   "swimlane",
+			lane:   "s",
+			x:      10,
+			y:      10,
+			cell:   Rect{10, 40, 120, 60},
+			bounds: map[string]Rect{"s": {600, 10, 480, 120}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGraph()
+			c := NewShape("c", "1")
+			g.Add(c)
+			pool := NewSwimlane("p", "1", "Pool")
+			g.AddLane(pool, "l1", "Sales")
+			g.AddLane(pool, "l2", "Support")
+			s := NewSwimlane("s", "1", "Lane")
+			s.Geometry.X = 600
+			g.Add(s)
+
+			g.PlaceInLane(g.FindByID(tt.lane), c, tt.x, tt.y)
+			c = g.FindByID("c")
+			if c.ParentID != tt.lane {
+				t.Errorf("got parent %q, want %q", c.ParentID, tt.lane)
+			}
+			if got := GeometryRect(c.Geometry); got != tt.cell {
+				t.Errorf("got cell at %v, want %v", got, tt.cell)
+			}
+			for id, want := range tt.bounds {
+				if got := GeometryRect(g.FindByID(id).Geometry); got != want {
+					t.Errorf("got %s at %v, want %v", id, got, want)
+				}
+			}
+			if g.cellIndex("c") < g.cellIndex(tt.lane) {
+				t.Error("got the cell before its lane")
+			}
+		})
+	}
+}
+
+func TestPlaceInLaneAddsCell(t *testing.T) {
+	g := NewGraph()
+	s := NewSwimlane("s", "1", "Lane")
+	g.Add(s)
+	c := &Cell{ID: "c", Vertex: "1"}
+	g.PlaceInLane(s, c, 0, 0)
+	got := g.FindByID("c")
+	if got == nil || got.ParentID != "s" || got.Geometry == nil || got.Geometry.Y != swimlaneHeader {
+		t.Errorf("got %+v, want c added below the header of s", got)
+	}
+}
+
+func TestSwimlaneContent(t *testing.T) {
+	tests := []struct {
+		style  map[string]string
+		dx, dy int
+	}{
+		{map[string]string{"swimlane": ""}, 0, swimlaneHeader},
+		{map[string]string{"swimlane": "", "startSize": "40"}, 0, 40},
+		{map[string]string{"swimlane": "", "horizontal": "0"}, swimlaneHeader, 0},
+		{map[string]string{"swimlane": "", "horizontal": "0", "startSize": "25.5"}, 25, 0},
+	}
+	for _, tt := range tests {
+		c := &Cell{Style: Style{Attributes: tt.style}}
+		if dx, dy := swimlaneContent(c); dx != tt.dx || dy != tt.dy {
+			t.Errorf("swimlaneContent(%v): got (%d, %d), want (%d, %d)", tt.style, dx, dy, tt.dx, tt.dy)
+		}
+	}
+}
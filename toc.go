@@ -0,0 +1,90 @@
+package graw
+
+import (
+	"html"
+	"strconv"
+)
+
+// tocMarker is the custom data key marking the root cell of a table of
+// contents made by GenerateTOC.
+const tocMarker = "graw-toc"
+
+// SetDescription sets the description of p, a short text kept as the
+// custom data "description" of the root cell of its model, which
+// draw.io shows in the "Edit Data" dialog of the page.
+func (p *Page) SetDescription(text string) *Page {
+	if p.Model != nil {
+		if root := p.Model.cell(topCellId); root != nil {
+			root.SetData("description", text)
+		}
+	}
+	return p
+}
+
+// Description returns the description of p, or "" if it has none.
+func (p *Page) Description() string {
+	if p.Model == nil {
+		return ""
+	}
+	if root := p.Model.cell(topCellId); root != nil {
+		return root.Data["description"]
+	}
+	return ""
+}
+
+// GenerateTOC inserts a table of contents as the first page of d and
+// returns it: a list of the other pages, numbered and linking to them,
+// each with its description. Generating it again replaces the previous
+// table of contents.
+func GenerateTOC(d *Document) *Page {
+	if len(d.pages) > 0 && d.pages[0].Model != nil {
+		if root := d.pages[0].Model.cell(topCellId); root != nil && root.Data[tocMarker] != "" {
+			d.pages = d.pages[1:]
+		}
+	}
+
+	g := NewGraph()
+	g.cell(topCellId).SetData(tocMarker, "1")
+	title := NewShape("toc-title", rootCellID)
+	title.Value = "<b>Contents</b>"
+	title.Style = Style{Attributes: shapeStyle("text")}
+	title.Style.set("align", "left")
+	title.Style.set("fontSize", "18")
+	title.Geometry.setSize(defaultWidth*4, 40)
+	g.Add(title)
+
+	const rowHeight = 30
+	y := 10 + 40 + groupPadding
+	for n, p := range d.pages {
+		link := NewShape(g.uniqueID("toc-"+p.ID), rootCellID)
+		link.Value = strconv.Itoa(n+2) + ". " + html.EscapeString(p.Name)
+		link.Style = Style{Attributes: shapeStyle("text")}
+		link.Style.set("align", "left")
+		link.Style.set("fontColor", "#0000ee")
+		link.Style.set("fontStyle", "4")
+		link.Geometry.Y = y
+		link.Geometry.setSize(defaultWidth*2, rowHeight)
+		link.SetPageLink(p)
+		g.Add(link)
+
+		if desc := p.Description(); desc != "" {
+			c := NewShape(g.uniqueID("toc-"+p.ID+"-description"), rootCellID)
+			c.Value = html.EscapeString(desc)
+			c.Style = Style{Attributes: shapeStyle("text")}
+			c.Style.set("align", "left")
+			c.Style.set("fontColor", "#666666")
+			c.Geometry.X, c.Geometry.Y = 10+defaultWidth*2+groupPadding, y
+			c.Geometry.setSize(defaultWidth*4, rowHeight)
+			g.Add(c)
+		}
+		y += rowHeight
+	}
+
+	taken := make(idSet, len(d.pages))
+	for _, p := range d.pages {
+		taken[p.ID] = true
+	}
+	toc := &Page{ID: taken.unique("toc"), Name: "Contents", Model: &g}
+	d.pages = append([]*Page{toc}, d.pages...)
+	return toc
+}
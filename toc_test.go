@@ -0,0 +1,105 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestPageDescription(t *testing.T) {
+	tests := []struct {
+		name  string
+		model *GraphModel
+		texts []string
+		want  string
+	}{
+		{"set", &GraphModel{}, []string{"High level view"}, "High level view"},
+		{"replace", &GraphModel{}, []string{"old", "new"}, "new"},
+		{"remove", &GraphModel{}, []string{"old", ""}, ""},
+		{"no model", nil, []string{"lost"}, ""},
+		{"no root cell", &GraphModel{Root: []*Cell{NewLayer("1", "")}}, []string{"lost"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.model != nil && tt.model.Root == nil {
+				g := NewGraph()
+				tt.model = &g
+			}
+			p := &Page{ID: "p", Name: "P", Model: tt.model}
+			for _, text := range tt.texts {
+				if got := p.SetDescription(text); got != p {
+					t.Fatal("does not return the page")
+				}
+			}
+			if got := p.Description(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if tt.model != nil {
+				checkRoundTrip(t, tt.model)
+			}
+		})
+	}
+}
+
+func TestGenerateTOC(t *testing.T) {
+	overview, details := NewGraph(), NewGraph()
+	d := NewDocument()
+	d.AddPage("Overview & scope", &overview).SetDescription("High level <view>")
+	d.AddPage("Details", &details)
+	d.AddPage("Blank", nil).ID = "toc"
+
+	for _, run := range []string{"first", "again"} {
+		t.Run(run, func(t *testing.T) {
+			toc := GenerateTOC(d)
+			pages := d.Pages()
+			if len(pages) != 4 || pages[0] != toc {
+				t.Fatalf("got %d pages, want the contents first and 3 others", len(pages))
+			}
+			if toc.Name != "Contents" || toc.ID != "toc-2" {
+				t.Errorf("got contents %q with ID %q", toc.Name, toc.ID)
+			}
+			tests := []struct {
+				id, value, link string
+				y               int
+			}{
+				{"toc-title", "<b>Contents</b>", "", 10},
+				{"toc-page-1", "2. Overview &amp; scope", "data:page/id,page-1", 70},
+				{"toc-page-1-description", "High level &lt;view&gt;", "", 70},
+				{"toc-page-2", "3. Details", "data:page/id,page-2", 100},
+				{"toc-toc", "4. Blank", "data:page/id,toc", 130},
+			}
+			g := toc.Model
+			if n := len(g.Root) - 2; n != len(tests) {
+				t.Errorf("got %d cells, want %d", n, len(tests))
+			}
+			for _, tt := range tests {
+				c := g.FindByID(tt.id)
+				if c == nil {
+					t.Errorf("missing %s", tt.id)
+					continue
+				}
+				if c.Value != tt.value || c.Link() != tt.link || c.Geometry.Y != tt.y {
+					t.Errorf("got %s %q linking %q at y %d, want %q linking %q at y %d", tt.id, c.Value, c.Link(), c.Geometry.Y, tt.value, tt.link, tt.y)
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("got style issues %v", iss)
+			}
+		})
+	}
+
+	// The contents survive encoding, so that they are replaced after
+	// the document was parsed again.
+	out, err := xml.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed Document
+	if err := xml.NewDecoder(bytes.NewReader(out)).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	GenerateTOC(&parsed)
+	if n := len(parsed.Pages()); n != 4 {
+		t.Errorf("got %d pages after generating the contents of a parsed document, want 4", n)
+	}
+}
@@ -0,0 +1,66 @@
+package graw
+
+import "errors"
+
+// ErrNoTransaction is returned by Commit and Rollback when no
+// transaction is open.
+var ErrNoTransaction = errors.New("graw: no transaction")
+
+// txState is the state of a model at the start of a transaction.
+type txState struct {
+	// model holds the attributes of the model, without cells.
+	model GraphModel
+	// cells are the cells of Root, and values copies of them.
+	cells  []*Cell
+	values []Cell
+}
+
+// Begin starts a transaction on g: the changes made to g until the
+// matching Commit can be undone by Rollback, e.g. when a generation
+// step fails half way. Transactions nest; rolling back an inner one
+// only undoes its own changes.
+func (g *GraphModel) Begin() {
+	s := &txState{
+		model:  *g,
+		cells:  append([]*Cell(nil), g.Root...),
+		values: make([]Cell, len(g.Root)),
+	}
+	s.model.Root, s.model.tx, s.model.index = nil, nil, nil
+	for i, c := range g.Root {
+		s.values[i] = *c.clone()
+	}
+	g.tx = append(g.tx, s)
+}
+
+// Commit ends the innermost transaction, keeping its changes. They
+// are undone still when an enclosing transaction is rolled back.
+func (g *GraphModel) Commit() error {
+	if len(g.tx) == 0 {
+		return ErrNoTransaction
+	}
+	g.tx = g.tx[:len(g.tx)-1]
+	return nil
+}
+
+// Rollback ends the innermost transaction, restoring g to its state
+// when the transaction began. The cells g held then are restored in
+// place, so pointers to them obtained before Begin still refer to the
+// cells of g; cells added since are dropped. IDs handed out by NextID
+// since are not handed out again.
+func (g *GraphModel) Rollback() error {
+	if len(g.tx) == 0 {
+		return ErrNoTransaction
+	}
+	s := g.tx[len(g.tx)-1]
+	tx := g.tx[:len(g.tx)-1]
+	for i, c := range s.cells {
+		*c = s.values[i]
+	}
+	next := g.nextID
+	*g = s.model
+	g.Root = s.cells
+	g.tx = tx
+	g.nextID = next
+	g.reindex()
+	return nil
+}
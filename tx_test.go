@@ -0,0 +1,165 @@
+package graw
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	g := NewGraph()
+	a := NewShape("a", "1")
+	b := NewShape("b", "1")
+	g.Add(a).Add(b)
+
+	g.Begin()
+	a.Value = "changed"
+	a.Geometry.X = 500
+	a.Style.set("fillColor", "#ff0000")
+	if err := g.Remove("b"); err != nil {
+		t.Fatal(err)
+	}
+	g.Add(NewShape("c", "1"))
+	g.SetGrid(true, 20)
+	if err := g.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := g.FindByID("a"); got != a {
+		t.Fatalf("FindByID(a) = %p, want the cell added before Begin %p", got, a)
+	}
+	if got := g.FindByID("b"); got != b {
+		t.Fatalf("FindByID(b) = %p, want the removed cell %p", got, b)
+	}
+	if g.FindByID("c") != nil {
+		t.Error("cell added in the transaction kept")
+	}
+	if a.Value != "" || a.Geometry.X != 10 || a.Style.Attributes["fillColor"] != "" {
+		t.Errorf("a not restored: %+v %+v", a, a.Geometry)
+	}
+	if g.Grid != "" {
+		t.Errorf("Grid = %q, want it restored", g.Grid)
+	}
+
+	// Changes made through a after Rollback are part of g.
+	a.Value = "after"
+	if g.FindByID("a").Value != "after" {
+		t.Error("change after Rollback lost")
+	}
+}
+
+func TestNestedTransactions(t *testing.T) {
+	g := NewGraph()
+	a := NewShape("a", "1")
+	g.Add(a)
+
+	g.Begin()
+	a.Value = "outer"
+	g.Begin()
+	a.Value = "inner"
+	g.Add(NewShape("b", "1"))
+	if err := g.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if a.Value != "outer" || g.FindByID("b") != nil {
+		t.Errorf("inner rollback: value %q, b kept %v", a.Value, g.FindByID("b") != nil)
+	}
+
+	g.Begin()
+	g.Add(NewShape("c", "1"))
+	if err := g.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if a.Value != "" || g.FindByID("c") != nil || g.FindByID("a") != a {
+		t.Errorf("outer rollback: value %q, c kept %v", a.Value, g.FindByID("c") != nil)
+	}
+
+	for _, f := range []func() error{g.Commit, g.Rollback} {
+		if err := f(); !errors.Is(err, ErrNoTransaction) {
+			t.Errorf("got %v, want ErrNoTransaction", err)
+		}
+	}
+}
+
+func TestRollbackChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(t *testing.T, g *GraphModel)
+	}{
+		{"value", func(t *testing.T, g *GraphModel) { g.FindByID("a").Value = "x" }},
+		{"style", func(t *testing.T, g *GraphModel) {
+			g.FindByID("a").Style.set("fillColor", "#000000")
+			delete(g.FindByID("a").Style.Attributes, "rounded")
+		}},
+		{"data", func(t *testing.T, g *GraphModel) {
+			g.FindByID("a").Data["k"] = "changed"
+			g.FindByID("a").Data["new"] = "1"
+		}},
+		{"geometry", func(t *testing.T, g *GraphModel) {
+			geo := g.FindByID("a-b").Geometry
+			geo.Points[0].X = 99
+			geo.AddWaypoint(1, 2)
+			geo.SourcePoint.Y = 99
+		}},
+		{"remove and add", func(t *testing.T, g *GraphModel) {
+			if err := g.Remove("a-b"); err != nil {
+				t.Fatal(err)
+			}
+			g.Add(NewEdge("a-b", "1", "b", "a"))
+		}},
+		{"model", func(t *testing.T, g *GraphModel) {
+			g.SetGrid(true, 5)
+			g.Background = "#000000"
+		}},
+	}
+	build := func() *GraphModel {
+		g := newTestGraph([2]string{"a", "b"})
+		a := g.FindByID("a")
+		a.Style.set("rounded", "1")
+		a.Data = map[string]string{"k": "v"}
+		e := g.FindByID("a-b")
+		e.Geometry.AddWaypoint(10, 20)
+		e.Geometry.SourcePoint = &Point{X: 1, Y: 2}
+		return &g
+	}
+	want := encodeString(t, build())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := build()
+			g.Begin()
+			tt.change(t, g)
+			changed := encodeString(t, g)
+			if changed == want {
+				t.Fatal("got no change")
+			}
+			if err := g.Rollback(); err != nil {
+				t.Fatal(err)
+			}
+			if got := encodeString(t, g); got != want {
+				t.Errorf("got after Rollback\n%s\nwant\n%s", got, want)
+			}
+
+			g.Begin()
+			tt.change(t, g)
+			if err := g.Commit(); err != nil {
+				t.Fatal(err)
+			}
+			if got := encodeString(t, g); got != changed {
+				t.Errorf("got after Commit\n%s\nwant\n%s", got, changed)
+			}
+		})
+	}
+}
+
+// encodeString returns the XML encoding of g.
+func encodeString(t *testing.T, g *GraphModel) string {
+	t.Helper()
+	var b strings.Builder
+	if err := g.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
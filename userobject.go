@@ -0,0 +1,296 @@
+package graw
+
+import (
+	"encoding/xml"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Attributes of the UserObject element which cannot be used as
+// custom data keys.
+var reservedDataKeys = map[string]bool{
+	"id":           true,
+	"label":        true,
+	"placeholders": true,
+}
+
+// dataKeyPattern matches the custom data keys which are valid
+// attribute names of the UserObject element.
+var dataKeyPattern = regexp.MustCompile(`^[\pL_][\pL\pN_.-]*$`)
+
+// validDataKey reports whether key can be written as custom data.
+func validDataKey(key string) bool {
+	return !reservedDataKeys[key] && dataKeyPattern.MatchString(key)
+}
+
+// Placeholder returns the label placeholder referring to the custom
+// data key, e.g. "%name%" for "name". The placeholder is only
+// resolved on cells with Placeholders enabled.
+func Placeholder(key string) string {
+	return "%" + key + "%"
+}
+
+// placeholderPattern matches the %key% placeholders of a label.
+var placeholderPattern = regexp.MustCompile(`%([^%\s]+)%`)
+
+// SetPlaceholders enables or disables the resolution of %key%
+// placeholders in the label of c from its custom data, e.g. "%ip%" for
+// the data key "ip".
+func (c *Cell) SetPlaceholders(on bool) *Cell {
+	c.Placeholders = on
+	return c
+}
+
+// Stamp returns a copy of the template cell c with the given id, its
+// placeholders enabled and the custom data of data set on top of the
+// data of c, so that one template shape labeled e.g. "%name%<br>%ip%"
+// can be stamped out for many hosts. The copy is not added to a model.
+func (c *Cell) Stamp(id string, data map[string]string) *Cell {
+	cp := c.clone()
+	cp.ID = id
+	cp.Placeholders = true
+	for _, k := range sortedKeys(data) {
+		cp.SetData(k, data[k])
+	}
+	return cp
+}
+
+// ResolvedValue returns the label of c as draw.io shows it: with the
+// %key% placeholders replaced by the custom data of c when placeholders
+// are enabled. Placeholders without data are kept, as in draw.io.
+func (c *Cell) ResolvedValue() string {
+	if !c.Placeholders {
+		return c.Value
+	}
+	return placeholderPattern.ReplaceAllStringFunc(c.Value, func(p string) string {
+		if v, ok := c.Data[p[1:len(p)-1]]; ok {
+			return v
+		}
+		return p
+	})
+}
+
+// SetData sets the custom data key of c to value, or removes key when
+// value is empty; the data is shown in the "Edit Data" dialog of
+// draw.io. The keys "id", "label" and "placeholders", which name
+// attributes of the UserObject element, and the keys which are not
+// valid attribute names, e.g. with spaces, are ignored.
+func (c *Cell) SetData(key, value string) *Cell {
+	if !validDataKey(key) {
+		return c
+	}
+	if value == "" {
+		delete(c.Data, key)
+		return c
+	}
+	if c.Data == nil {
+		c.Data = make(map[string]string)
+	}
+	c.Data[key] = value
+	return c
+}
+
+// SetLink makes c a hyperlink to url, which the draw.io viewer opens
+// when c is clicked; an empty url removes the link. The label and the
+// style of c are kept.
+func (c *Cell) SetLink(url string) *Cell {
+	return c.SetData("link", url)
+}
+
+// SetPageLink makes c a hyperlink to page p of the same Document.
+func (c *Cell) SetPageLink(p *Page) *Cell {
+	return c.SetData("link", "data:page/id,"+p.ID)
+}
+
+// Link returns the hyperlink of c, or "" if it has none.
+func (c *Cell) Link() string {
+	return c.Data["link"]
+}
+
+// SetTooltip sets the text the draw.io viewer shows when the pointer
+// rests on c; an empty text removes the tooltip.
+func (c *Cell) SetTooltip(text string) *Cell {
+	return c.SetData("tooltip", text)
+}
+
+// Tooltip returns the tooltip of c, or "" if it has none.
+func (c *Cell) Tooltip() string {
+	return c.Data["tooltip"]
+}
+
+// AddTag adds the tags to c, which the draw.io viewer lets readers
+// filter the diagram by, e.g. "prod" or "team-payments". Tags are
+// kept sorted and without duplicates; tags cannot contain spaces,
+// which separate them in the file, so they are replaced by dashes.
+func (c *Cell) AddTag(tags ...string) *Cell {
+	set := make(map[string]string)
+	for _, t := range c.Tags() {
+		set[t] = ""
+	}
+	for _, t := range tags {
+		if t = strings.Join(strings.Fields(t), "-"); t != "" {
+			set[t] = ""
+		}
+	}
+	return c.SetData("tags", strings.Join(sortedKeys(set), " "))
+}
+
+// RemoveTag removes the tags from c.
+func (c *Cell) RemoveTag(tags ...string) *Cell {
+	var kept []string
+	for _, t := range c.Tags() {
+		if !containsString(tags, t) {
+			kept = append(kept, t)
+		}
+	}
+	return c.SetData("tags", strings.Join(kept, " "))
+}
+
+// Tags returns the sorted tags of c.
+func (c *Cell) Tags() []string {
+	tags := strings.Fields(c.Data["tags"])
+	sort.Strings(tags)
+	return tags
+}
+
+// HasTag reports whether c is tagged with tag.
+func (c *Cell) HasTag(tag string) bool {
+	return containsString(c.Tags(), tag)
+}
+
+// MarshalXML encodes c as an mxCell element. Cells carrying custom
+// data or using placeholders are wrapped in a UserObject element
+// holding the ID, the label and the data, as draw.io does. It
+// implements xml.Marshaler interface.
+func (c Cell) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	// cell has the fields of Cell but not its methods.
+	type cell Cell
+
+	if len(c.Data) == 0 && !c.Placeholders {
+		return e.EncodeElement(cell(c), start)
+	}
+
+	obj := xml.StartElement{Name: xml.Name{Local: "UserObject"}}
+	obj.Attr = append(obj.Attr, xml.Attr{Name: xml.Name{Local: "label"}, Value: c.Value})
+	if c.Placeholders {
+		obj.Attr = append(obj.Attr, xml.Attr{Name: xml.Name{Local: "placeholders"}, Value: "1"})
+	}
+	for _, k := range sortedKeys(c.Data) {
+		if !validDataKey(k) {
+			continue
+		}
+		obj.Attr = append(obj.Attr, xml.Attr{Name: xml.Name{Local: k}, Value: c.Data[k]})
+	}
+	obj.Attr = append(obj.Attr, xml.Attr{Name: xml.Name{Local: "id"}, Value: c.ID})
+
+	inner := cell(c)
+	inner.ID = ""
+	inner.Value = ""
+
+	if err := e.EncodeToken(obj); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(inner, xml.StartElement{Name: xml.Name{Local: "mxCell"}}); err != nil {
+		return err
+	}
+	return e.EncodeToken(obj.End())
+}
+
+// UnmarshalXML decodes an mxGraphModel element including the cells
+// wrapped in UserObject elements. It implements xml.Unmarshaler
+// interface.
+func (g *GraphModel) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// Model has the fields of GraphModel but not its methods. It is
+	// exported as encoding/xml only decodes exported embedded fields.
+	type Model GraphModel
+	var aux struct {
+		Model
+		Root cellList `xml:"root"`
+	}
+	if err := d.DecodeElement(&aux, &start); err != nil {
+		return err
+	}
+	*g = GraphModel(aux.Model)
+	g.Root = aux.Root
+	g.reindex()
+	return nil
+}
+
+// cellList is the list of cells of a model in the order of the root
+// element, decoded from mxCell elements and from the UserObject and
+// object elements draw.io wraps cells carrying custom data in.
+type cellList []*Cell
+
+// UnmarshalXML decodes the children of the root element of a model.
+// Unknown elements are skipped. It implements xml.Unmarshaler
+// interface.
+func (l *cellList) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var c Cell
+			switch t.Name.Local {
+			case "mxCell":
+				err = d.DecodeElement(&c, &t)
+			case "UserObject", "object":
+				err = decodeUserObject(d, t, &c)
+			default:
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			*l = append(*l, &c)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeUserObject decodes the UserObject element start into c: its
+// label, ID and custom data, then the mxCell element it wraps.
+func decodeUserObject(d *xml.Decoder, start xml.StartElement, c *Cell) error {
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case "label":
+			c.Value = a.Value
+		case "id":
+			c.ID = a.Value
+		case "placeholders":
+			c.Placeholders = a.Value == "1"
+		default:
+			if c.Data == nil {
+				c.Data = make(map[string]string)
+			}
+			c.Data[a.Name.Local] = a.Value
+		}
+	}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "mxCell" {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.DecodeElement(c, &t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package graw
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// marshalCell returns the XML of c, encoded as the only cell of a
+// model.
+func marshalCell(t *testing.T, c *Cell) string {
+	t.Helper()
+	g := GraphModel{Root: []*Cell{c}}
+	out, err := xml.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(out)
+	return s[strings.Index(s, "<root>")+len("<root>") : strings.LastIndex(s, "</root>")]
+}
+
+func TestCellMarshalUserObject(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		cell Cell
+		want string
+	}{
+		{
+			name: "plain",
+			cell: Cell{ID: "a", Value: "A", ParentID: "1", Vertex: "1"},
+			want: `<mxCell id="a" value="A" parent="1" vertex="1"></mxCell>`,
+		},
+		{
+			name: "data",
+			cell: Cell{ID: "a", Value: "A", ParentID: "1", Vertex: "1", Data: map[string]string{"owner": "infra & ops", "ip": "10.0.0.1"}},
+			want: `<UserObject label="A" ip="10.0.0.1" owner="infra &amp; ops" id="a"><mxCell parent="1" vertex="1"></mxCell></UserObject>`,
+		},
+		{
+			name: "placeholders",
+			cell: Cell{ID: "a", Value: "Host " + Placeholder("ip"), ParentID: "1", Vertex: "1", Placeholders: true},
+			want: `<UserObject label="Host %ip%" placeholders="1" id="a"><mxCell parent="1" vertex="1"></mxCell></UserObject>`,
+		},
+		{
+			name: "reserved keys",
+			cell: Cell{ID: "a", ParentID: "1", Vertex: "1", Data: map[string]string{"id": "b", "label": "B", "placeholders": "1", "k": "v"}},
+			want: `<UserObject label="" k="v" id="a"><mxCell parent="1" vertex="1"></mxCell></UserObject>`,
+		},
+		{
+			name: "invalid keys",
+			cell: Cell{ID: "a", ParentID: "1", Vertex: "1", Data: map[string]string{"my key": "x", "a<b": "x", "1st": "x", "": "x", "prénom": "v", "_k.1-2": "v"}},
+			want: `<UserObject label="" _k.1-2="v" prénom="v" id="a"><mxCell parent="1" vertex="1"></mxCell></UserObject>`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got := marshalCell(t, &test.cell); got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetData(t *testing.T) {
+	tests := []struct {
+		name       string
+		key, value string
+		want       map[string]string
+	}{
+		{"set", "owner", "ops", map[string]string{"k": "v", "owner": "ops"}},
+		{"replace", "k", "w", map[string]string{"k": "w"}},
+		{"remove", "k", "", map[string]string{}},
+		{"remove missing", "x", "", map[string]string{"k": "v"}},
+		{"reserved", "label", "x", map[string]string{"k": "v"}},
+		{"empty key", "", "x", map[string]string{"k": "v"}},
+		{"space", "my key", "x", map[string]string{"k": "v"}},
+		{"markup", "a\"b", "x", map[string]string{"k": "v"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1").SetData("k", "v")
+			if got := c.SetData(tt.key, tt.value); got != c {
+				t.Error("SetData does not return the cell")
+			}
+			if len(c.Data) != len(tt.want) {
+				t.Errorf("got %v, want %v", c.Data, tt.want)
+			}
+			for k, v := range tt.want {
+				if c.Data[k] != v {
+					t.Errorf("got %v, want %v", c.Data, tt.want)
+				}
+			}
+			checkRoundTrip(t, &GraphModel{Root: []*Cell{c}})
+		})
+	}
+
+	// data set on a cell without any is a new map
+	c := NewShape("a", "1").SetData("k", "v")
+	if d := NewShape("b", "1").SetData("k", "w"); c.Data["k"] != "v" || d.Data["k"] != "w" {
+		t.Errorf("got %v and %v", c.Data, d.Data)
+	}
+}
+
+func TestSetLink(t *testing.T) {
+	p := &Page{ID: "p2", Name: "Details"}
+	tests := []struct {
+		name string
+		set  func(c *Cell) *Cell
+		want string
+	}{
+		{"url", func(c *Cell) *Cell { return c.SetLink("https://example.com/a?b=1&c=2") }, "https://example.com/a?b=1&c=2"},
+		{"page", func(c *Cell) *Cell { return c.SetPageLink(p) }, "data:page/id,p2"},
+		{"replace", func(c *Cell) *Cell { return c.SetLink("https://a").SetLink("https://b") }, "https://b"},
+		{"remove", func(c *Cell) *Cell { return c.SetLink("https://a").SetLink("") }, ""},
+		{"none", func(c *Cell) *Cell { return c }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1")
+			c.Value = "Label"
+			c.Style.Attributes = map[string]string{"fillColor": "#ff0000"}
+			if got := tt.set(c); got != c {
+				t.Error("setter does not return the cell")
+			}
+			if got := c.Link(); got != tt.want {
+				t.Errorf("got link %q, want %q", got, tt.want)
+			}
+			if c.Value != "Label" || c.Style.Attributes["fillColor"] != "#ff0000" {
+				t.Errorf("got value %q and style %v, want them kept", c.Value, c.Style.Attributes)
+			}
+			checkRoundTrip(t, &GraphModel{Root: []*Cell{c}})
+		})
+	}
+}
+
+func TestSetTooltip(t *testing.T) {
+	tests := []struct {
+		name string
+		text []string
+		want string
+	}{
+		{"set", []string{"Primary database"}, "Primary database"},
+		{"markup", []string{`<b>"a" & b</b>`}, `<b>"a" & b</b>`},
+		{"multi-line", []string{"line 1\nline 2"}, "line 1\nline 2"},
+		{"replace", []string{"old", "new"}, "new"},
+		{"remove", []string{"old", ""}, ""},
+		{"none", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1").SetLink("https://example.com")
+			for _, text := range tt.text {
+				c.SetTooltip(text)
+			}
+			if got := c.Tooltip(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got := c.Link(); got != "https://example.com" {
+				t.Errorf("got link %q, want it kept", got)
+			}
+			g := &GraphModel{Root: []*Cell{c}}
+			checkRoundTrip(t, g)
+			out, err := xml.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h, err := Parse(bytes.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := h.FindByID("a").Tooltip(); got != tt.want {
+				t.Errorf("got %q after parsing, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvedValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		data  map[string]string
+		on    bool
+		want  string
+	}{
+		{"resolved", "%name%<br>%ip%", map[string]string{"name": "db", "ip": "10.0.0.1"}, true, "db<br>10.0.0.1"},
+		{"disabled", "%name%", map[string]string{"name": "db"}, false, "%name%"},
+		{"missing data", "%name% %port%", map[string]string{"name": "db"}, true, "db %port%"},
+		{"repeated", "%a%-%a%", map[string]string{"a": "x"}, true, "x-x"},
+		{"percent", "100% of %a%", map[string]string{"a": "x"}, true, "100% of x"},
+		{"spaces", "% a %", map[string]string{"a": "x"}, true, "% a %"},
+		{"no placeholders", "plain", nil, true, "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1").Label(tt.value).SetPlaceholders(tt.on)
+			for k, v := range tt.data {
+				c.SetData(k, v)
+			}
+			if got := c.ResolvedValue(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if c.Value != tt.value {
+				t.Errorf("got value %q, want it kept", c.Value)
+			}
+		})
+	}
+}
+
+func TestStamp(t *testing.T) {
+	tmpl := NewShape("host", "1").Label("%name% (%ip%)").SetData("ip", "0.0.0.0").SetData("os", "linux")
+	tmpl.Style.Attributes = map[string]string{"fillColor": "#dae8fc"}
+	tmpl.Geometry.X = 40
+	tests := []struct {
+		id   string
+		data map[string]string
+		want string
+		os   string
+	}{
+		{"web", map[string]string{"name": "web", "ip": "10.0.0.1"}, "web (10.0.0.1)", "linux"},
+		{"db", map[string]string{"name": "db"}, "db (0.0.0.0)", "linux"},
+		{"win", map[string]string{"name": "win", "os": "windows", "ip": ""}, "win (%ip%)", "windows"},
+		{"empty", nil, "%name% (0.0.0.0)", "linux"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			c := tmpl.Stamp(tt.id, tt.data)
+			if c.ID != tt.id || !c.Placeholders {
+				t.Errorf("got id %q and placeholders %v, want %q and true", c.ID, c.Placeholders, tt.id)
+			}
+			if got := c.ResolvedValue(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got := c.Data["os"]; got != tt.os {
+				t.Errorf("got os %q, want %q", got, tt.os)
+			}
+			if c.Style.Attributes["fillColor"] != "#dae8fc" || c.Geometry.X != 40 {
+				t.Errorf("got style %v at x %d, want the style and geometry of the template", c.Style.Attributes, c.Geometry.X)
+			}
+			c.Geometry.X = 0
+			c.Style.Attributes["fillColor"] = "none"
+		})
+	}
+	if tmpl.ID != "host" || tmpl.Placeholders || len(tmpl.Data) != 2 || tmpl.Data["ip"] != "0.0.0.0" || tmpl.Geometry.X != 40 || tmpl.Style.Attributes["fillColor"] != "#dae8fc" {
+		t.Errorf("got template %+v changed by Stamp", tmpl)
+	}
+}
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   func(c *Cell) *Cell
+		want   []string
+		stored string
+	}{
+		{"add", func(c *Cell) *Cell { return c.AddTag("prod", "db") }, []string{"db", "prod"}, "db prod"},
+		{"duplicates", func(c *Cell) *Cell { return c.AddTag("prod", "prod").AddTag("prod") }, []string{"prod"}, "prod"},
+		{"spaces", func(c *Cell) *Cell { return c.AddTag(" team  payments ", "", "  ") }, []string{"team-payments"}, "team-payments"},
+		{"remove", func(c *Cell) *Cell { return c.AddTag("a", "b", "c").RemoveTag("b", "x") }, []string{"a", "c"}, "a c"},
+		{"remove all", func(c *Cell) *Cell { return c.AddTag("a").RemoveTag("a") }, nil, ""},
+		{"unsorted data", func(c *Cell) *Cell { return c.SetData("tags", "z  a") }, []string{"a", "z"}, "z  a"},
+		{"add to data", func(c *Cell) *Cell { return c.SetData("tags", "z a").AddTag("m") }, []string{"a", "m", "z"}, "a m z"},
+		{"none", func(c *Cell) *Cell { return c }, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1")
+			if got := tt.edit(c); got != c {
+				t.Error("does not return the cell")
+			}
+			if got := c.Tags(); !reflect.DeepEqual(got, tt.want) && len(got)+len(tt.want) > 0 {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if got := c.Data["tags"]; got != tt.stored {
+				t.Errorf("got data %q, want %q", got, tt.stored)
+			}
+			for _, tag := range tt.want {
+				if !c.HasTag(tag) {
+					t.Errorf("HasTag(%q) is false", tag)
+				}
+			}
+			if c.HasTag("missing") || c.HasTag("") {
+				t.Error("HasTag is true for a missing tag")
+			}
+			checkRoundTrip(t, &GraphModel{Root: []*Cell{c}})
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package graw
+
+import "fmt"
+
+// ModelIssue is a structural problem of a model found by Validate.
+type ModelIssue struct {
+	CellID string
+	// Problem explains what is wrong with the cell.
+	Problem string
+}
+
+// Error implements the error interface.
+func (i ModelIssue) Error() string {
+	return fmt.Sprintf("graw: cell %q: %s", i.CellID, i.Problem)
+}
+
+// Validate checks the structure of g and returns a ModelIssue for
+// every problem found, in document order: IDs used by several cells,
+// parents which do not exist or cells nested in themselves, edges
+// whose source or target does not exist, vertices and edges without
+// geometry, and cells other than the top cell and the layers which are
+// neither vertex nor edge, or both. draw.io drops or mangles such
+// cells, so Validate catches generation bugs before a file is opened.
+func (g *GraphModel) Validate() []error {
+	var errs []error
+	report := func(c *Cell, format string, args ...interface{}) {
+		errs = append(errs, ModelIssue{CellID: c.ID, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	dup := make(map[string]bool)
+	for _, id := range g.DuplicateIDs() {
+		dup[id] = true
+	}
+	for i := range g.Root {
+		c := g.Root[i]
+		if c.ID == "" {
+			report(c, "no ID")
+		}
+		if dup[c.ID] {
+			report(c, "ID used by several cells")
+			delete(dup, c.ID)
+		}
+
+		switch {
+		case c.ID == topCellId:
+			if c.ParentID != "" {
+				report(c, "top cell has parent %q", c.ParentID)
+			}
+		case c.ParentID == "":
+			report(c, "no parent")
+		case g.cell(c.ParentID) == nil:
+			report(c, "parent %q does not exist", c.ParentID)
+		case g.parentCycle(c):
+			report(c, "nested in itself")
+		}
+
+		switch {
+		case c.isVertex() && c.isEdge():
+			report(c, "both vertex and edge")
+		case c.isVertex():
+			if c.Geometry == nil {
+				report(c, "vertex without geometry")
+			}
+		case c.isEdge():
+			if c.Geometry == nil {
+				report(c, "edge without geometry")
+			}
+			if c.Source != "" && g.cell(c.Source) == nil {
+				report(c, "source %q does not exist", c.Source)
+			}
+			if c.Target != "" && g.cell(c.Target) == nil {
+				report(c, "target %q does not exist", c.Target)
+			}
+		case c.ID != topCellId && !c.isLayer():
+			report(c, "neither vertex nor edge")
+		}
+	}
+	return errs
+}
+
+// parentCycle reports whether following the parents of c leads back
+// to c. The chain ends at a cell without parent, even should a cell
+// without ID exist.
+func (g *GraphModel) parentCycle(c *Cell) bool {
+	seen := map[string]bool{c.ID: true}
+	for id := c.ParentID; id != ""; {
+		p := g.cell(id)
+		if p == nil {
+			return false
+		}
+		if p.ID == c.ID {
+			return true
+		}
+		if seen[p.ID] {
+			// A cycle above c, reported for the cells on it.
+			return false
+		}
+		seen[p.ID] = true
+		id = p.ParentID
+	}
+	return false
+}
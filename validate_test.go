@@ -0,0 +1,108 @@
+package graw
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(g *GraphModel)
+		want []string
+	}{
+		{
+			name: "valid",
+			edit: func(g *GraphModel) {},
+		},
+		{
+			name: "duplicate ID",
+			edit: func(g *GraphModel) { g.Add(NewShape("a", "1")); g.Add(NewShape("a", "1")) },
+			want: []string{`graw: cell "a": ID used by several cells`},
+		},
+		{
+			name: "no ID",
+			edit: func(g *GraphModel) { g.Add(NewShape("", "1")) },
+			want: []string{`graw: cell "": no ID`},
+		},
+		{
+			name: "top cell with parent",
+			edit: func(g *GraphModel) { g.FindByID("0").ParentID = "1" },
+			want: []string{`graw: cell "0": top cell has parent "1"`, `graw: cell "1": nested in itself`},
+		},
+		{
+			name: "no parent",
+			edit: func(g *GraphModel) { g.Add(NewShape("a", "")) },
+			want: []string{`graw: cell "a": no parent`},
+		},
+		{
+			name: "missing parent",
+			edit: func(g *GraphModel) { g.Add(NewShape("a", "grp")) },
+			want: []string{`graw: cell "a": parent "grp" does not exist`},
+		},
+		{
+			name: "nested in itself",
+			edit: func(g *GraphModel) {
+				g.Add(NewShape("a", "b"))
+				g.Add(NewShape("b", "a"))
+				g.Add(NewShape("c", "a"))
+			},
+			want: []string{`graw: cell "a": nested in itself`, `graw: cell "b": nested in itself`},
+		},
+		{
+			name: "own parent",
+			edit: func(g *GraphModel) { g.Add(NewShape("a", "a")) },
+			want: []string{`graw: cell "a": nested in itself`},
+		},
+		{
+			name: "vertex without geometry",
+			edit: func(g *GraphModel) { a := NewShape("a", "1"); a.Geometry = nil; g.Add(a) },
+			want: []string{`graw: cell "a": vertex without geometry`},
+		},
+		{
+			name: "edge",
+			edit: func(g *GraphModel) {
+				e := NewEdge("e", "1", "x", "y")
+				e.Geometry = nil
+				g.Add(e)
+			},
+			want: []string{`graw: cell "e": edge without geometry`, `graw: cell "e": source "x" does not exist`, `graw: cell "e": target "y" does not exist`},
+		},
+		{
+			name: "loose edge",
+			edit: func(g *GraphModel) { g.Add(NewEdge("e", "1", "", "")) },
+		},
+		{
+			name: "vertex and edge",
+			edit: func(g *GraphModel) { a := NewShape("a", "1"); a.Edge = "1"; g.Add(a) },
+			want: []string{`graw: cell "a": both vertex and edge`},
+		},
+		{
+			name: "neither",
+			edit: func(g *GraphModel) { g.Add(&Cell{ID: "a", ParentID: "1"}) },
+			want: []string{`graw: cell "a": neither vertex nor edge`},
+		},
+		{
+			name: "layer",
+			edit: func(g *GraphModel) { g.Add(NewLayer("2", "Notes")); g.Add(NewShape("a", "2")) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraph([2]string{"x1", "x2"})
+			tt.edit(&g)
+			var got []string
+			for _, err := range g.Validate() {
+				var iss ModelIssue
+				if !errors.As(err, &iss) {
+					t.Errorf("got %T, want a ModelIssue", err)
+				}
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package graw
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dimensions of Venn diagrams.
+const (
+	vennRadius      = 100
+	vennDistance    = 120
+	vennLabelWidth  = 80
+	vennLabelHeight = 40
+)
+
+// vennColors are the fill and stroke colors of the sets of Venn
+// diagrams, in order.
+var vennColors = [][2]string{{"#dae8fc", "#6c8ebf"}, {"#f8cecc", "#b85450"}, {"#d5e8d4", "#82b366"}}
+
+// VennSet is a set of a Venn diagram.
+type VennSet struct {
+	Name string
+	// Color is the #rrggbb fill color of the set, by default blue, red
+	// and green for the first, second and third set.
+	Color string
+}
+
+// VennRegion is a region of a Venn diagram: the items in all the sets
+// named and in none of the others.
+type VennRegion struct {
+	Sets  []string
+	Count int
+	// Label is shown below the count, e.g. examples of the items.
+	Label string
+}
+
+// NewVenn returns a Venn diagram of two or three sets: an ellipse per
+// set, named outside of it, overlapping the others so that every
+// combination of sets has a region. The regions given are labeled
+// with their count; the others are left blank.
+func NewVenn(sets []VennSet, regions []VennRegion) (*GraphModel, error) {
+	if len(sets) < 2 || len(sets) > 3 {
+		return nil, fmt.Errorf("graw: NewVenn: %d sets, want 2 or 3", len(sets))
+	}
+	index := make(map[string]int)
+	for i, s := range sets {
+		if s.Name == "" {
+			return nil, fmt.Errorf("graw: NewVenn: set %d has no name", i)
+		}
+		if _, ok := index[s.Name]; ok {
+			return nil, fmt.Errorf("graw: NewVenn: duplicate set %q", s.Name)
+		}
+		index[s.Name] = i
+	}
+	byMask := make(map[int]VennRegion)
+	for _, r := range regions {
+		mask := 0
+		for _, s := range r.Sets {
+			i, ok := index[s]
+			if !ok {
+				return nil, fmt.Errorf("graw: NewVenn: region of unknown set %q", s)
+			}
+			mask |= 1 << i
+		}
+		if mask == 0 {
+			return nil, fmt.Errorf("graw: NewVenn: region without sets")
+		}
+		if _, ok := byMask[mask]; ok {
+			return nil, fmt.Errorf("graw: NewVenn: duplicate region %s", strings.Join(r.Sets, " & "))
+		}
+		byMask[mask] = r
+	}
+
+	// the centers form a line or an equilateral triangle around the
+	// centroid; the regions are labeled at the mean of the centers of
+	// their sets, pushed away from the centroid
+	centers := [][2]float64{{0, 0}, {vennDistance, 0}}
+	if len(sets) == 3 {
+		centers = append(centers, [2]float64{vennDistance / 2, vennDistance * math.Sqrt(3) / 2})
+	}
+	var gx, gy float64
+	for _, c := range centers {
+		gx += c[0] / float64(len(centers))
+		gy += c[1] / float64(len(centers))
+	}
+	// x0, y0 is where the centroid is drawn
+	x0, y0 := 10+vennRadius+vennLabelWidth+vennDistance/2, 10+vennRadius+vennLabelHeight+int(gy)
+
+	g := NewGraph()
+	taken := g.idSet()
+	for i, s := range sets {
+		cx, cy := centers[i][0]-gx, centers[i][1]-gy
+		c := NewShape(taken.unique("set-"+s.Name), rootCellID)
+		c.Style = Style{Attributes: shapeStyle("ellipse")}
+		c.Style.set("fillColor", vennColors[i][0])
+		c.Style.set("strokeColor", vennColors[i][1])
+		if s.Color != "" {
+			c.Style.set("fillColor", s.Color)
+			c.Style.set("strokeColor", mixColor(s.Color, "#000000", 0.4))
+		}
+		c.Style.set("fillOpacity", "50")
+		c.Geometry.X, c.Geometry.Y = x0+int(cx)-vennRadius, y0+int(cy)-vennRadius
+		c.Geometry.setSize(2*vennRadius, 2*vennRadius)
+		c.Data = map[string]string{"tooltip": s.Name}
+		g.Add(c)
+
+		d := math.Hypot(cx, cy)
+		name := NewShape(taken.unique(c.ID+"-name"), rootCellID)
+		name.Value = "<b>" + html.EscapeString(s.Name) + "</b>"
+		name.Style = Style{Attributes: shapeStyle("text")}
+		name.Style.set("fontColor", c.Style.Attributes["strokeColor"])
+		nx := float64(x0) + cx + cx/d*(vennRadius+vennLabelWidth/2)
+		ny := float64(y0) + cy + cy/d*(vennRadius+vennLabelHeight/2)
+		name.Geometry.X, name.Geometry.Y = int(nx)-vennLabelWidth/2, int(ny)-vennLabelHeight/2
+		name.Geometry.setSize(vennLabelWidth, vennLabelHeight)
+		g.Add(name)
+	}
+
+	masks := make([]int, 0, len(byMask))
+	for mask := range byMask {
+		masks = append(masks, mask)
+	}
+	sort.Ints(masks)
+	for _, mask := range masks {
+		r := byMask[mask]
+		var mx, my float64
+		var names []string
+		n := 0
+		for i := range sets {
+			if mask&(1<<i) != 0 {
+				mx += centers[i][0] - gx
+				my += centers[i][1] - gy
+				names = append(names, sets[i].Name)
+				n++
+			}
+		}
+		mx, my = mx/float64(n)*1.65, my/float64(n)*1.65
+		c := NewShape(taken.unique("region-"+strings.Join(names, "-")), rootCellID)
+		c.Value = "<b>" + strconv.Itoa(r.Count) + "</b>"
+		if r.Label != "" {
+			c.Value += "<br>" + html.EscapeString(r.Label)
+		}
+		c.Style = Style{Attributes: shapeStyle("text")}
+		c.Style.set("align", "center")
+		c.Geometry.X, c.Geometry.Y = x0+int(mx)-vennLabelWidth/2, y0+int(my)-vennLabelHeight/2
+		c.Geometry.setSize(vennLabelWidth, vennLabelHeight)
+		c.Data = map[string]string{"tooltip": strings.Join(names, " ∩ ")}
+		g.Add(c)
+	}
+	return &g, nil
+}
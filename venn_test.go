@@ -0,0 +1,130 @@
+package graw
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNewVenn(t *testing.T) {
+	tests := []struct {
+		name    string
+		sets    []VennSet
+		regions []VennRegion
+		labels  map[string]string
+	}{
+		{
+			name: "two sets",
+			sets: []VennSet{{Name: "Web"}, {Name: "iOS"}},
+			regions: []VennRegion{
+				{Sets: []string{"Web"}, Count: 10},
+				{Sets: []string{"iOS"}, Count: 7},
+				{Sets: []string{"iOS", "Web"}, Count: 3, Label: "a & b"},
+			},
+			labels: map[string]string{
+				"region-Web":     "<b>10</b>",
+				"region-iOS":     "<b>7</b>",
+				"region-Web-iOS": "<b>3</b><br>a &amp; b",
+			},
+		},
+		{
+			name: "three sets",
+			sets: []VennSet{{Name: "Web"}, {Name: "iOS"}, {Name: "Android", Color: "#ffe6cc"}},
+			regions: []VennRegion{
+				{Sets: []string{"Web"}, Count: 10},
+				{Sets: []string{"iOS"}, Count: 1},
+				{Sets: []string{"Android"}, Count: 2},
+				{Sets: []string{"Web", "iOS"}, Count: 5},
+				{Sets: []string{"Web", "Android"}, Count: 6},
+				{Sets: []string{"iOS", "Android"}, Count: 4},
+				{Sets: []string{"iOS", "Web", "Android"}, Count: 3, Label: "power"},
+			},
+			labels: map[string]string{
+				"region-Web":             "<b>10</b>",
+				"region-iOS":             "<b>1</b>",
+				"region-Android":         "<b>2</b>",
+				"region-Web-iOS":         "<b>5</b>",
+				"region-Web-Android":     "<b>6</b>",
+				"region-iOS-Android":     "<b>4</b>",
+				"region-Web-iOS-Android": "<b>3</b><br>power",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewVenn(tt.sets, tt.regions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range g.Root {
+				if c.Geometry != nil && (c.Geometry.X < 0 || c.Geometry.Y < 0) {
+					t.Errorf("%s: got position %d,%d", c.ID, c.Geometry.X, c.Geometry.Y)
+				}
+			}
+			// The center of a region label lies in the circles of its
+			// sets and in no other.
+			for id, label := range tt.labels {
+				r := g.FindByID(id)
+				if r == nil {
+					t.Errorf("%s: missing", id)
+					continue
+				}
+				if r.Value != label {
+					t.Errorf("%s: got label %q, want %q", id, r.Value, label)
+				}
+				x := float64(r.Geometry.X) + vennLabelWidth/2
+				y := float64(r.Geometry.Y) + vennLabelHeight/2
+				in := strings.Split(strings.TrimPrefix(id, "region-"), "-")
+				for _, s := range tt.sets {
+					c := g.FindByID("set-" + s.Name)
+					d := math.Hypot(x-float64(c.Geometry.X+vennRadius), y-float64(c.Geometry.Y+vennRadius))
+					if want := containsString(in, s.Name); (d < vennRadius) != want {
+						t.Errorf("%s: got distance %.0f to the center of %s, want inside %v", id, d, s.Name, want)
+					}
+				}
+			}
+			if iss := ValidateStyles(g); len(iss) > 0 {
+				t.Errorf("invalid styles: %v", iss)
+			}
+		})
+	}
+}
+
+func TestNewVennColors(t *testing.T) {
+	g, err := NewVenn([]VennSet{{Name: "a"}, {Name: "b", Color: "#ffe6cc"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := g.FindByID("set-a"), g.FindByID("set-b")
+	if got := a.Style.Attributes["fillColor"]; got != vennColors[0][0] {
+		t.Errorf("got fillColor %q, want %q", got, vennColors[0][0])
+	}
+	if got, want := b.Style.Attributes["strokeColor"], mixColor("#ffe6cc", "#000000", 0.4); got != want {
+		t.Errorf("got strokeColor %q, want %q", got, want)
+	}
+	if got := g.FindByID("set-b-name").Style.Attributes["fontColor"]; got != b.Style.Attributes["strokeColor"] {
+		t.Errorf("got name color %q, want the stroke color", got)
+	}
+}
+
+func TestNewVennErrors(t *testing.T) {
+	ab := []VennSet{{Name: "a"}, {Name: "b"}}
+	tests := []struct {
+		name    string
+		sets    []VennSet
+		regions []VennRegion
+	}{
+		{"one set", []VennSet{{Name: "a"}}, nil},
+		{"four sets", []VennSet{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}, nil},
+		{"no name", []VennSet{{Name: "a"}, {}}, nil},
+		{"duplicate set", []VennSet{{Name: "a"}, {Name: "a"}}, nil},
+		{"unknown set", ab, []VennRegion{{Sets: []string{"c"}}}},
+		{"no sets", ab, []VennRegion{{Count: 1}}},
+		{"duplicate region", ab, []VennRegion{{Sets: []string{"a", "b"}}, {Sets: []string{"b", "a"}}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewVenn(tt.sets, tt.regions); err == nil {
+			t.Errorf("%s: got no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package graw
+
+import (
+	"encoding/xml"
+	"math"
+	"strconv"
+)
+
+// SetWeight sets the weight of c, a number such as the traffic of a
+// link or the cost of a service, which GraphModel.Weights maps onto
+// its look. It is kept as the custom data "weight".
+func (c *Cell) SetWeight(w float64) *Cell {
+	return c.SetData("weight", strconv.FormatFloat(w, 'g', -1, 64))
+}
+
+// Weight returns the weight of c. It reports false when c has no
+// weight or it is not a number.
+func (c *Cell) Weight() (float64, bool) {
+	v, ok := c.Data["weight"]
+	if !ok {
+		return 0, false
+	}
+	w, err := strconv.ParseFloat(v, 64)
+	return w, err == nil
+}
+
+// WeightRange is a range of values weights are mapped onto, From for
+// the lowest weight and To for the highest one.
+type WeightRange struct {
+	From, To float64
+}
+
+// at returns the value of r at t, between 0 and 1.
+func (r WeightRange) at(t float64) float64 {
+	return r.From + (r.To-r.From)*t
+}
+
+// isZero reports whether r is unset.
+func (r WeightRange) isZero() bool {
+	return r == WeightRange{}
+}
+
+// WeightMapping maps the weights of the cells of a model onto their
+// look, so that a metric is shown the same way across a diagram
+// instead of by style computations in every caller. Properties whose
+// range is zero are left alone.
+type WeightMapping struct {
+	// Min and Max are the weights mapped onto the ends of the ranges,
+	// weights beyond them are clamped. When both are zero, the lowest
+	// and highest weights of the model are used.
+	Min, Max float64
+	// Scale multiplies the size of vertices, which keep their center.
+	Scale WeightRange
+	// StrokeWidth is the width of edges.
+	StrokeWidth WeightRange
+	// Opacity is the opacity of vertices and edges, from 0 to 100.
+	Opacity WeightRange
+	// LowColor and HighColor are the #rrggbb colors of the lowest and
+	// highest weights, interpolated in between: the fill of vertices
+	// and the stroke of edges. Both must be set.
+	LowColor  string
+	HighColor string
+}
+
+// apply styles the vertices and edges of g which have a weight.
+func (m *WeightMapping) apply(g *GraphModel) {
+	lo, hi := m.Min, m.Max
+	if lo == 0 && hi == 0 {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for _, c := range g.Root {
+			if w, ok := c.Weight(); ok && (c.isVertex() || c.isEdge()) {
+				lo, hi = math.Min(lo, w), math.Max(hi, w)
+			}
+		}
+	}
+
+	for _, c := range g.Root {
+		w, ok := c.Weight()
+		if !ok || !c.isVertex() && !c.isEdge() {
+			continue
+		}
+		// Equal weights are mapped onto the middle of the ranges.
+		t := 0.5
+		if hi > lo {
+			t = math.Min(math.Max((w-lo)/(hi-lo), 0), 1)
+		}
+
+		if c.isVertex() && !m.Scale.isZero() && c.Geometry != nil {
+			f := m.Scale.at(t)
+			w, h := c.Geometry.size()
+			nw, nh := int(math.Round(float64(w)*f)), int(math.Round(float64(h)*f))
+			c.Geometry.X -= (nw - w) / 2
+			c.Geometry.Y -= (nh - h) / 2
+			c.Geometry.setSize(nw, nh)
+		}
+		if c.isEdge() && !m.StrokeWidth.isZero() {
+			c.Style.set("strokeWidth", formatDecimal(m.StrokeWidth.at(t), 1))
+		}
+		if !m.Opacity.isZero() {
+			c.Style.set("opacity", formatDecimal(m.Opacity.at(t), 0))
+		}
+		if m.LowColor != "" && m.HighColor != "" {
+			key := "fillColor"
+			if c.isEdge() {
+				key = "strokeColor"
+			}
+			c.Style.set(key, mixColor(m.LowColor, m.HighColor, t))
+		}
+	}
+}
+
+// MarshalXML encodes g as an mxGraphModel element, with the styles of
+// its weighted cells set by g.Weights, if any; g itself is left alone.
+// It implements xml.Marshaler interface.
+func (g GraphModel) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	// model has the fields of GraphModel but not its methods.
+	type model GraphModel
+	if g.Weights != nil {
+		r := g.clone()
+		g.Weights.apply(&r)
+		g = r
+	}
+	// The XMLName tag of GraphModel is not used for the start element
+	// of a Marshaler.
+	start.Name = xml.Name{Local: "mxGraphModel"}
+	return e.EncodeElement(model(g), start)
+}
@@ -0,0 +1,132 @@
+package graw
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestWeight(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want float64
+		ok   bool
+	}{
+		{"integer", "3", 3, true},
+		{"decimal", "0.25", 0.25, true},
+		{"negative", "-2", -2, true},
+		{"not a number", "high", 0, false},
+		{"none", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewShape("a", "1")
+			if tt.data != "" {
+				c.SetData("weight", tt.data)
+			}
+			if got, ok := c.Weight(); got != tt.want || ok != tt.ok {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+	if got := NewShape("a", "1").SetWeight(1.5).Data["weight"]; got != "1.5" {
+		t.Errorf("got weight data %q, want %q", got, "1.5")
+	}
+}
+
+// weighedGraph returns a graph of the shapes "a" and "b", 120x60 at
+// 10,10, weighing 0 and 10, the edge "a-b" weighing 5 and the shape "c"
+// without weight.
+func weighedGraph() GraphModel {
+	g := newTestGraph([2]string{"a", "b"})
+	g.FindByID("a").SetWeight(0)
+	g.FindByID("b").SetWeight(10)
+	g.FindByID("a-b").SetWeight(5)
+	g.Add(NewShape("c", "1"))
+	return g
+}
+
+func TestWeightMapping(t *testing.T) {
+	type look struct {
+		geometry string
+		style    map[string]string
+	}
+	tests := []struct {
+		name string
+		m    WeightMapping
+		want map[string]look
+	}{
+		{"scale", WeightMapping{Scale: WeightRange{1, 2}}, map[string]look{
+			"a": {"10,10 120x60", nil},
+			"b": {"-50,-20 240x120", nil},
+			"c": {"10,10 120x60", nil},
+		}},
+		{"stroke width", WeightMapping{StrokeWidth: WeightRange{1, 5}}, map[string]look{
+			"a":   {"10,10 120x60", nil},
+			"a-b": {"", map[string]string{"strokeWidth": "3"}},
+		}},
+		{"opacity", WeightMapping{Opacity: WeightRange{20, 100}}, map[string]look{
+			"a":   {"10,10 120x60", map[string]string{"opacity": "20"}},
+			"b":   {"10,10 120x60", map[string]string{"opacity": "100"}},
+			"a-b": {"", map[string]string{"opacity": "60"}},
+			"c":   {"10,10 120x60", map[string]string{"opacity": ""}},
+		}},
+		{"colors", WeightMapping{LowColor: "#000000", HighColor: "#ffffff"}, map[string]look{
+			"a":   {"10,10 120x60", map[string]string{"fillColor": "#000000"}},
+			"b":   {"10,10 120x60", map[string]string{"fillColor": "#ffffff"}},
+			"a-b": {"", map[string]string{"strokeColor": "#808080"}},
+		}},
+		{"one color", WeightMapping{LowColor: "#000000"}, map[string]look{
+			"a": {"10,10 120x60", map[string]string{"fillColor": ""}},
+		}},
+		{"clamped", WeightMapping{Min: 5, Max: 6, Opacity: WeightRange{0, 100}}, map[string]look{
+			"a":   {"10,10 120x60", map[string]string{"opacity": "0"}},
+			"b":   {"10,10 120x60", map[string]string{"opacity": "100"}},
+			"a-b": {"", map[string]string{"opacity": "0"}},
+		}},
+		{"equal weights", WeightMapping{Min: 3, Max: 3, Opacity: WeightRange{0, 100}}, map[string]look{
+			"a": {"10,10 120x60", map[string]string{"opacity": "50"}},
+			"b": {"10,10 120x60", map[string]string{"opacity": "50"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := weighedGraph()
+			m := tt.m
+			m.apply(&g)
+			for id, want := range tt.want {
+				c := g.FindByID(id)
+				if want.geometry != "" {
+					w, h := c.Geometry.size()
+					if got := fmt.Sprintf("%d,%d %dx%d", c.Geometry.X, c.Geometry.Y, w, h); got != want.geometry {
+						t.Errorf("%s: got geometry %s, want %s", id, got, want.geometry)
+					}
+				}
+				for k, v := range want.style {
+					if got := c.Style.Attributes[k]; got != v {
+						t.Errorf("%s: got %s %q, want %q", id, k, got, v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWeightsMarshal(t *testing.T) {
+	g := weighedGraph()
+	g.Weights = &WeightMapping{Opacity: WeightRange{0, 100}}
+	if out := encodeString(t, &g); !strings.Contains(out, "opacity=100") {
+		t.Errorf("got %s, want the weighted style", out)
+	}
+	if got := g.FindByID("b").Style.Attributes["opacity"]; got != "" {
+		t.Errorf("got opacity %q on the model, want it left alone", got)
+	}
+	plain := weighedGraph()
+	if g.Hash() == plain.Hash() {
+		t.Error("got equal hashes with and without weights")
+	}
+	if renderString(t, &g, SVGOptions{}) == renderString(t, &plain, SVGOptions{}) {
+		t.Error("got equal images with and without weights")
+	}
+}
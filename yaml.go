@@ -0,0 +1,644 @@
+package graw
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// yamlLine is a line of a YAML document.
+type yamlLine struct {
+	indent int
+	// text is the content after the indentation with comments
+	// removed, or "" for blank lines.
+	text string
+	raw  string
+	num  int
+}
+
+// yamlParser decodes the block YAML commonly found in configuration
+// files into the values encoding/json produces: maps with string keys,
+// slices, strings, float64, bool and nil. It supports block and flow
+// collections, quoted, plain and block scalars, multiple documents,
+// anchors, aliases and merge keys; tags are ignored.
+type yamlParser struct {
+	lines   []yamlLine
+	pos     int
+	anchors map[string]interface{}
+}
+
+// decodeYAML returns the documents of the YAML stream data. Input
+// starting with { or [ is decoded as JSON.
+func decodeYAML(data []byte) ([]interface{}, error) {
+	if s := strings.TrimSpace(string(data)); strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			return []interface{}{v}, nil
+		}
+	}
+
+	var docs []interface{}
+	var lines []yamlLine
+	flush := func() error {
+		p := &yamlParser{lines: lines, anchors: make(map[string]interface{})}
+		v, err := p.block(0)
+		if err != nil {
+			return err
+		}
+		p.skipBlank()
+		if p.pos < len(p.lines) {
+			return p.errorf("unexpected %q", p.lines[p.pos].text)
+		}
+		if v != nil || len(docs) == 0 && len(lines) > 0 {
+			docs = append(docs, v)
+		}
+		lines = nil
+		return nil
+	}
+
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		if raw == "---" || strings.HasPrefix(raw, "--- ") || raw == "..." {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			if rest := strings.TrimSpace(strings.TrimPrefix(raw, "---")); rest != "" && rest != "..." {
+				lines = append(lines, yamlLine{text: rest, raw: rest, num: i + 1})
+			}
+			continue
+		}
+		if strings.HasPrefix(raw, "%") {
+			continue // directive
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		lines = append(lines, yamlLine{
+			indent: indent,
+			text:   stripYAMLComment(raw[indent:]),
+			raw:    raw,
+			num:    i + 1,
+		})
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// stripYAMLComment removes a trailing comment from s, leaving quoted
+// scalars alone.
+func stripYAMLComment(s string) string {
+	var quote byte
+	prev := byte(' ')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+					i++
+				} else {
+					quote = 0
+				}
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '#' && (prev == ' ' || prev == '\t'):
+			return strings.TrimRight(s[:i], " \t")
+		case (c == '"' || c == '\'') && strings.IndexByte(" :-[{,", prev) >= 0:
+			quote = c
+		}
+		prev = c
+	}
+	return strings.TrimRight(s, " \t")
+}
+
+// errorf returns an error at the current line.
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	return p.errorAt(p.pos, format, args...)
+}
+
+// errorAt returns an error at the i-th line.
+func (p *yamlParser) errorAt(i int, format string, args ...interface{}) error {
+	line := 0
+	if i >= 0 && i < len(p.lines) {
+		line = p.lines[i].num
+	} else if len(p.lines) > 0 {
+		line = p.lines[len(p.lines)-1].num
+	}
+	return fmt.Errorf("graw: yaml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skipBlank moves past blank and comment lines.
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+// block parses the node starting at the next line, which must be
+// indented by at least min spaces. It returns nil when there is none.
+func (p *yamlParser) block(min int) (interface{}, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent < min {
+		return nil, nil
+	}
+	l := p.lines[p.pos]
+	switch {
+	case isYAMLSeqItem(l.text):
+		return p.sequence(l.indent)
+	case yamlKeyEnd(l.text) >= 0:
+		return p.mapping(l.indent)
+	}
+	p.pos++
+	return p.value(l.text, l.indent-1)
+}
+
+// isYAMLSeqItem tells whether text starts a block sequence item.
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlKeyEnd returns the index of the colon ending the mapping key
+// text starts with, or -1.
+func yamlKeyEnd(text string) int {
+	if text == "" || text[0] == '[' || text[0] == '{' || text[0] == '#' || isYAMLSeqItem(text) {
+		return -1
+	}
+	i := 0
+	if text[0] == '"' || text[0] == '\'' {
+		end := yamlQuoteEnd(text, 0)
+		if end < 0 {
+			return -1
+		}
+		i = end + 1
+	}
+	for ; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// yamlQuoteEnd returns the index of the quote closing the scalar
+// which starts at s[start], or -1.
+func yamlQuoteEnd(s string, start int) int {
+	q := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && q == '"':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i
+		}
+	}
+	return -1
+}
+
+// mapping parses a block mapping whose keys are indented by indent.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := make(map[string]interface{})
+	var merged []map[string]interface{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent < indent {
+			break
+		}
+		l := p.lines[p.pos]
+		if l.indent > indent {
+			return nil, p.errorf("bad indentation")
+		}
+		end := yamlKeyEnd(l.text)
+		if end < 0 {
+			if isYAMLSeqItem(l.text) {
+				break
+			}
+			return nil, p.errorf("want a mapping key")
+		}
+		key := l.text[:end]
+		if key != "" && (key[0] == '"' || key[0] == '\'') {
+			key = yamlQuoted(key)
+		}
+		p.pos++
+
+		v, err := p.value(strings.TrimSpace(l.text[end+1:]), indent)
+		if err != nil {
+			return nil, err
+		}
+		if key == "<<" {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				merged = append(merged, v)
+			case []interface{}:
+				for _, e := range v {
+					if em, ok := e.(map[string]interface{}); ok {
+						merged = append(merged, em)
+					}
+				}
+			}
+			continue
+		}
+		m[key] = v
+	}
+	for _, mm := range merged {
+		for k, v := range mm {
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+	}
+	return m, nil
+}
+
+// sequence parses a block sequence whose dashes are indented by
+// indent.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	s := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent != indent || !isYAMLSeqItem(p.lines[p.pos].text) {
+			break
+		}
+		l := p.lines[p.pos]
+		rest := strings.TrimLeft(l.text[1:], " ")
+		inner := indent + len(l.text) - len(rest)
+
+		if isYAMLSeqItem(rest) || yamlKeyEnd(rest) >= 0 && !strings.HasPrefix(rest, "&") && !strings.HasPrefix(rest, "!") {
+			// a collection starting on the line of the dash: parse
+			// the rest of the line as if it was on a line of its own
+			p.lines[p.pos] = yamlLine{indent: inner, text: rest, raw: l.raw, num: l.num}
+			v, err := p.block(inner)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+			continue
+		}
+
+		p.pos++
+		var v interface{}
+		var err error
+		if rest == "" {
+			v, err = p.block(indent + 1)
+		} else {
+			v, err = p.value(rest, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+	return s, nil
+}
+
+// value parses the value following a mapping key or sequence dash on
+// a line indented by indent: an inline scalar or flow collection, a
+// block scalar or a nested block on the following lines.
+func (p *yamlParser) value(text string, indent int) (interface{}, error) {
+	anchor := ""
+	for {
+		if strings.HasPrefix(text, "&") {
+			i := strings.IndexAny(text, " \t")
+			if i < 0 {
+				i = len(text)
+			}
+			anchor, text = text[1:i], strings.TrimSpace(text[i:])
+			continue
+		}
+		if strings.HasPrefix(text, "!") {
+			i := strings.IndexAny(text, " \t")
+			if i < 0 {
+				i = len(text)
+			}
+			text = strings.TrimSpace(text[i:])
+			continue
+		}
+		break
+	}
+
+	v, err := p.inline(text, indent)
+	if err != nil {
+		return nil, err
+	}
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// inline parses text, the start of a value without anchor or tag,
+// found on the line before the current one.
+func (p *yamlParser) inline(text string, indent int) (interface{}, error) {
+	at := p.pos - 1
+	switch {
+	case text == "":
+		p.skipBlank()
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSeqItem(p.lines[p.pos].text) {
+			// a sequence may be indented like the key it belongs to
+			return p.sequence(indent)
+		}
+		return p.block(indent + 1)
+
+	case text[0] == '*':
+		v, ok := p.anchors[text[1:]]
+		if !ok {
+			return nil, p.errorAt(at, "unknown alias %s", text)
+		}
+		return v, nil
+
+	case text[0] == '|' || text[0] == '>':
+		return p.blockScalar(text, indent), nil
+
+	case text[0] == '[' || text[0] == '{':
+		for depth := yamlFlowDepth(text); depth > 0 && p.pos < len(p.lines); depth = yamlFlowDepth(text) {
+			text += " " + p.lines[p.pos].text
+			p.pos++
+		}
+		v, rest, err := yamlFlow(text)
+		if err != nil {
+			return nil, p.errorAt(at, "%v", err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, p.errorAt(at, "unexpected %q after flow collection", rest)
+		}
+		return v, nil
+
+	case text[0] == '"' || text[0] == '\'':
+		for yamlQuoteEnd(text, 0) < 0 && p.pos < len(p.lines) {
+			next := strings.TrimSpace(p.lines[p.pos].raw)
+			if next == "" {
+				text += "\n"
+			} else if strings.HasSuffix(text, "\n") {
+				text += next
+			} else {
+				text += " " + next
+			}
+			p.pos++
+		}
+		end := yamlQuoteEnd(text, 0)
+		if end < 0 {
+			return nil, p.errorAt(at, "unterminated quoted scalar")
+		}
+		return yamlQuoted(text[:end+1]), nil
+	}
+
+	// a plain scalar may continue on more indented lines
+	for {
+		save := p.pos
+		p.skipBlank()
+		if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent ||
+			yamlKeyEnd(p.lines[p.pos].text) >= 0 || isYAMLSeqItem(p.lines[p.pos].text) {
+			p.pos = save
+			break
+		}
+		text += " " + p.lines[p.pos].text
+		p.pos++
+	}
+	return yamlPlain(text), nil
+}
+
+// blockScalar parses a literal (|) or folded (>) block scalar whose
+// header is header, with content lines indented by more than indent.
+func (p *yamlParser) blockScalar(header string, indent int) string {
+	folded := header[0] == '>'
+	chomp := byte(0)
+	width := 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			width = int(c - '0')
+		}
+	}
+
+	var lines []string
+	content := -1
+	if width > 0 {
+		content = indent + width
+		if indent < 0 {
+			content = width
+		}
+	}
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if content < 0 {
+			if l.indent <= indent {
+				break
+			}
+			content = l.indent
+		}
+		if l.indent < content {
+			break
+		}
+		lines = append(lines, l.raw[content:])
+		p.pos++
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	var s string
+	if folded {
+		var b strings.Builder
+		for i, l := range lines {
+			switch {
+			case i == 0:
+			case l == "" || lines[i-1] == "" || strings.HasPrefix(l, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(l)
+		}
+		s = b.String()
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case len(lines) == 0:
+		return ""
+	case chomp == '-':
+		return s
+	case chomp == '+':
+		return s + strings.Repeat("\n", trailing+1)
+	}
+	return s + "\n"
+}
+
+// yamlFlowDepth returns how many flow collections text leaves open.
+func yamlFlowDepth(text string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case '"', '\'':
+			if end := yamlQuoteEnd(text, i); end >= 0 {
+				i = end
+			}
+		}
+	}
+	return depth
+}
+
+// yamlFlow parses the flow collection or scalar at the start of s and
+// returns the rest of s.
+func yamlFlow(s string) (interface{}, string, error) {
+	s = strings.TrimLeft(s, " \t")
+	if s == "" {
+		return nil, "", fmt.Errorf("unexpected end of flow collection")
+	}
+	switch s[0] {
+	case '[':
+		list := []interface{}{}
+		s = strings.TrimLeft(s[1:], " \t")
+		for !strings.HasPrefix(s, "]") {
+			v, rest, err := yamlFlow(s)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, v)
+			if s = strings.TrimLeft(rest, " \t"); strings.HasPrefix(s, ",") {
+				s = strings.TrimLeft(s[1:], " \t")
+			} else if !strings.HasPrefix(s, "]") {
+				return nil, "", fmt.Errorf("want , or ] in flow sequence")
+			}
+		}
+		return list, s[1:], nil
+
+	case '{':
+		m := make(map[string]interface{})
+		s = strings.TrimLeft(s[1:], " \t")
+		for !strings.HasPrefix(s, "}") {
+			k, rest, err := yamlFlow(s)
+			if err != nil {
+				return nil, "", err
+			}
+			var v interface{}
+			if s = strings.TrimLeft(rest, " \t"); strings.HasPrefix(s, ":") {
+				if v, rest, err = yamlFlow(s[1:]); err != nil {
+					return nil, "", err
+				}
+				s = strings.TrimLeft(rest, " \t")
+			}
+			m[fmt.Sprint(k)] = v
+			if strings.HasPrefix(s, ",") {
+				s = strings.TrimLeft(s[1:], " \t")
+			} else if !strings.HasPrefix(s, "}") {
+				return nil, "", fmt.Errorf("want , or } in flow mapping")
+			}
+		}
+		return m, s[1:], nil
+
+	case '"', '\'':
+		end := yamlQuoteEnd(s, 0)
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated quoted scalar")
+		}
+		return yamlQuoted(s[:end+1]), s[end+1:], nil
+	}
+
+	end := len(s)
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == ',' || c == ']' || c == '}' || c == ':' && (i+1 == len(s) || strings.IndexByte(" \t,]}", s[i+1]) >= 0) {
+			end = i
+			break
+		}
+	}
+	return yamlPlain(strings.TrimSpace(s[:end])), s[end:], nil
+}
+
+var yamlNumber = regexp.MustCompile(`^[-+]?(0|[1-9][0-9_]*)(\.[0-9]*)?([eE][-+]?[0-9]+)?$|^[-+]?\.[0-9]+$|^0x[0-9a-fA-F]+$|^0o[0-7]+$`)
+
+// yamlPlain resolves a plain scalar to nil, a bool, a float64 or a
+// string following the YAML 1.2 core schema.
+func yamlPlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf":
+		return inf
+	case "-.inf", "-.Inf", "-.INF":
+		return -inf
+	}
+	if yamlNumber.MatchString(s) {
+		switch {
+		case strings.HasPrefix(s, "0x"):
+			if n, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+				return float64(n)
+			}
+		case strings.HasPrefix(s, "0o"):
+			if n, err := strconv.ParseInt(s[2:], 8, 64); err == nil {
+				return float64(n)
+			}
+		default:
+			if f, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64); err == nil {
+				return f
+			}
+		}
+	}
+	return s
+}
+
+// yamlQuoted returns the value of a single or double quoted scalar,
+// quotes included.
+func yamlQuoted(s string) string {
+	body := s[1 : len(s)-1]
+	if s[0] == '\'' {
+		return strings.ReplaceAll(body, "''", "'")
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' || i+1 == len(body) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := body[i]; e {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '0':
+			b.WriteByte(0)
+		case 'x', 'u', 'U':
+			n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+			if i+n < len(body) {
+				if r, err := strconv.ParseUint(body[i+1:i+1+n], 16, 32); err == nil {
+					var buf [utf8.UTFMax]byte
+					b.Write(buf[:utf8.EncodeRune(buf[:], rune(r))])
+					i += n
+					continue
+				}
+			}
+			b.WriteByte(e)
+		default:
+			b.WriteByte(e)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,66 @@
+package graw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// m and l shorten the expected values of the YAML tests.
+type (
+	m = map[string]interface{}
+	l = []interface{}
+)
+
+func TestDecodeYAML(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		in   string
+		want []interface{}
+	}{
+		{"scalars", "s: text\nn: 1.5\ni: 0x10\nt: true\nz: ~\ne:\n", l{m{"s": "text", "n": 1.5, "i": 16.0, "t": true, "z": nil, "e": nil}}},
+		{"quoted", `a: "x, y # z"` + "\nb: 'it''s'\nc: \"tab\\tnew\\n\"\n", l{m{"a": "x, y # z", "b": "it's", "c": "tab\tnew\n"}}},
+		{"comments", "# head\na: 1 # trailing\nb: x#y\n", l{m{"a": 1.0, "b": "x#y"}}},
+		{"block sequence", "a:\n- 1\n- x\nb:\n  - c: 1\n    d: 2\n", l{m{"a": l{1.0, "x"}, "b": l{m{"c": 1.0, "d": 2.0}}}}},
+		{"nested sequence", "- - a\n  - b\n- c\n", l{l{l{"a", "b"}, "c"}}},
+		{"flow", "a: [1, 'x', {b: c, d: [e]}]\nf: {}\n", l{m{"a": l{1.0, "x", m{"b": "c", "d": l{"e"}}}, "f": m{}}}},
+		{"literal block", "a: |\n  one\n   two\n\nb: x\n", l{m{"a": "one\n two\n", "b": "x"}}},
+		{"folded block", "a: >-\n  one\n  two\n", l{m{"a": "one two"}}},
+		{"multi-line plain", "a: this is\n  continued\n", l{m{"a": "this is continued"}}},
+		{"multi-line quoted", "a: \"x\n  y\"\n", l{m{"a": "x y"}}},
+		{"anchors and merge", "base: &b\n  x: 1\n  y: 2\nc:\n  <<: *b\n  y: 3\nd: *b\n", l{m{"base": m{"x": 1.0, "y": 2.0}, "c": m{"x": 1.0, "y": 3.0}, "d": m{"x": 1.0, "y": 2.0}}}},
+		{"documents", "---\na: 1\n---\nb: 2\n...\n", l{m{"a": 1.0}, m{"b": 2.0}}},
+		{"empty", "", l{nil}},
+		{"multi-line flow", "a: [1,\n  2]\nb: 3\n", l{m{"a": l{1.0, 2.0}, "b": 3.0}}},
+		{"json", `{"a": [1, "x"]}`, l{m{"a": l{1.0, "x"}}}},
+		{"template expression", "if: ${{ matrix.os == 'x' }}\n", l{m{"if": "${{ matrix.os == 'x' }}"}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			docs, err := decodeYAML([]byte(test.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(docs, test.want) {
+				t.Errorf("got %#v, want %#v", docs, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"a: [1, 2\n", "line 1"},
+		{"a: 1\n  b: 2\n", "line 2"},
+		{"a: *missing\n", "line 1"},
+		{"a: 'open\n", "line 1"},
+		{"a:\n  b: 1\n  c: [1,\n\n", "line 3"},
+		{"- a\n- *x\n", "line 2"},
+	} {
+		_, err := decodeYAML([]byte(test.in))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("decodeYAML(%q): got error %v, want one mentioning %q", test.in, err, test.want)
+		}
+	}
+}